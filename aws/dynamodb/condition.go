@@ -0,0 +1,79 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package dynamodb
+
+import "github.com/aws/aws-sdk-go-v2/aws"
+
+// Condition is a condition expression that must hold for a write to succeed.
+// The attribute names and values placeholders of the expression are set in Names and Values,
+// e.g. "#version = :version". The values are marshaled like the item attributes.
+type Condition struct {
+	Expression string
+	Names      map[string]string
+	Values     map[string]any
+}
+
+// AttributeNotExists succeeds when the item does not have the attribute.
+// On the partition key it makes a put create the item only when it does not exist yet.
+func AttributeNotExists(attribute string) *Condition {
+	return &Condition{
+		Expression: "attribute_not_exists(#attribute)",
+		Names:      map[string]string{"#attribute": attribute},
+	}
+}
+
+// AttributeExists succeeds when the item has the attribute.
+// On the partition key it makes a write apply only to an existing item.
+func AttributeExists(attribute string) *Condition {
+	return &Condition{
+		Expression: "attribute_exists(#attribute)",
+		Names:      map[string]string{"#attribute": attribute},
+	}
+}
+
+// AttributeEquals succeeds when the attribute of the item equals the value, e.g. an optimistic lock version
+func AttributeEquals(attribute string, value any) *Condition {
+	return &Condition{
+		Expression: "#attribute = :value",
+		Names:      map[string]string{"#attribute": attribute},
+		Values:     map[string]any{":value": value},
+	}
+}
+
+// build returns the expression, the attribute names and the marshaled attribute values of the condition
+func (c *Condition) build() (*string, map[string]string, Item, error) {
+	if c == nil {
+		return nil, nil, nil, nil
+	}
+
+	var values Item
+	if len(c.Values) > 0 {
+		var err error
+		if values, err = MarshalItem(c.Values); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	return aws.String(c.Expression), c.Names, values, nil
+}
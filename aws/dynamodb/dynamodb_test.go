@@ -0,0 +1,331 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package dynamodb
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsdynamodb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/cenkalti/backoff/v4"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type account struct {
+	ID      string            `json:"id"`
+	Owner   string            `json:"owner"`
+	Balance float64           `json:"balance"`
+	Version int64             `json:"version"`
+	Active  bool              `json:"active"`
+	Tags    []string          `json:"tags,omitempty"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	Closed  *string           `json:"closed"`
+}
+
+// fakeDynamoDB is an in-memory table keyed by the id attribute
+type fakeDynamoDB struct {
+	mu    sync.Mutex
+	items map[string]Item
+	// unprocessed is the number of batch calls leaving their last request unprocessed
+	unprocessed int
+	batchGets   int
+	batchWrites int
+}
+
+func newFakeDynamoDB() *fakeDynamoDB {
+	return &fakeDynamoDB{items: make(map[string]Item)}
+}
+
+func (f *fakeDynamoDB) id(key Item) string {
+	return key["id"].(*types.AttributeValueMemberS).Value
+}
+
+func (f *fakeDynamoDB) GetItem(_ context.Context, params *awsdynamodb.GetItemInput, _ ...func(*awsdynamodb.Options)) (*awsdynamodb.GetItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return &awsdynamodb.GetItemOutput{Item: f.items[f.id(params.Key)]}, nil
+}
+
+func (f *fakeDynamoDB) PutItem(_ context.Context, params *awsdynamodb.PutItemInput, _ ...func(*awsdynamodb.Options)) (*awsdynamodb.PutItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	id := f.id(params.Item)
+	if err := f.check(f.items[id], params.ConditionExpression, params.ExpressionAttributeValues); err != nil {
+		return nil, err
+	}
+	f.items[id] = params.Item
+	return &awsdynamodb.PutItemOutput{}, nil
+}
+
+func (f *fakeDynamoDB) DeleteItem(_ context.Context, params *awsdynamodb.DeleteItemInput, _ ...func(*awsdynamodb.Options)) (*awsdynamodb.DeleteItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	id := f.id(params.Key)
+	if err := f.check(f.items[id], params.ConditionExpression, params.ExpressionAttributeValues); err != nil {
+		return nil, err
+	}
+	delete(f.items, id)
+	return &awsdynamodb.DeleteItemOutput{}, nil
+}
+
+// check evaluates the conditions built by the Condition helpers
+func (f *fakeDynamoDB) check(existing Item, expression *string, values Item) error {
+	holds := true
+	switch aws.ToString(expression) {
+	case "attribute_not_exists(#attribute)":
+		holds = existing == nil
+	case "attribute_exists(#attribute)":
+		holds = existing != nil
+	case "#attribute = :value":
+		holds = existing != nil && existing["version"].(*types.AttributeValueMemberN).Value == values[":value"].(*types.AttributeValueMemberN).Value
+	}
+
+	if !holds {
+		return &types.ConditionalCheckFailedException{Message: aws.String("The conditional request failed")}
+	}
+	return nil
+}
+
+func (f *fakeDynamoDB) BatchGetItem(_ context.Context, params *awsdynamodb.BatchGetItemInput, _ ...func(*awsdynamodb.Options)) (*awsdynamodb.BatchGetItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.batchGets++
+
+	output := &awsdynamodb.BatchGetItemOutput{
+		Responses:       make(map[string][]Item),
+		UnprocessedKeys: make(map[string]types.KeysAndAttributes),
+	}
+	for table, request := range params.RequestItems {
+		keys := request.Keys
+		if f.unprocessed > 0 {
+			f.unprocessed--
+			output.UnprocessedKeys[table] = types.KeysAndAttributes{Keys: keys[len(keys)-1:]}
+			keys = keys[:len(keys)-1]
+		}
+		for _, key := range keys {
+			if item, ok := f.items[f.id(key)]; ok {
+				output.Responses[table] = append(output.Responses[table], item)
+			}
+		}
+	}
+	return output, nil
+}
+
+func (f *fakeDynamoDB) BatchWriteItem(_ context.Context, params *awsdynamodb.BatchWriteItemInput, _ ...func(*awsdynamodb.Options)) (*awsdynamodb.BatchWriteItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.batchWrites++
+
+	output := &awsdynamodb.BatchWriteItemOutput{UnprocessedItems: make(map[string][]types.WriteRequest)}
+	for table, requests := range params.RequestItems {
+		if len(requests) > maxBatchWriteSize {
+			return nil, errors.New("too many items requested for the BatchWriteItem call")
+		}
+		if f.unprocessed > 0 {
+			f.unprocessed--
+			output.UnprocessedItems[table] = requests[len(requests)-1:]
+			requests = requests[:len(requests)-1]
+		}
+		for _, request := range requests {
+			if request.PutRequest != nil {
+				f.items[f.id(request.PutRequest.Item)] = request.PutRequest.Item
+			}
+			if request.DeleteRequest != nil {
+				delete(f.items, f.id(request.DeleteRequest.Key))
+			}
+		}
+	}
+	return output, nil
+}
+
+// noDelay retries the unprocessed items without waiting up to the given number of times
+func noDelay(retries uint64) Option {
+	return WithBatchBackOff(func() backoff.BackOff {
+		return backoff.WithMaxRetries(&backoff.ZeroBackOff{}, retries)
+	})
+}
+
+func TestMarshalItem(t *testing.T) {
+	t.Run("with round trip", func(t *testing.T) {
+		closed := "2024-01-01"
+		item := account{
+			ID:      "account-1",
+			Owner:   "john",
+			Balance: 10.5,
+			Version: 9007199254740993,
+			Active:  true,
+			Tags:    []string{"gold"},
+			Labels:  map[string]string{"region": "eu"},
+			Closed:  &closed,
+		}
+
+		attributes, err := MarshalItem(item)
+		require.NoError(t, err)
+		assert.Equal(t, &types.AttributeValueMemberS{Value: "account-1"}, attributes["id"])
+		assert.Equal(t, &types.AttributeValueMemberN{Value: "10.5"}, attributes["balance"])
+		// the numbers keep their precision
+		assert.Equal(t, &types.AttributeValueMemberN{Value: "9007199254740993"}, attributes["version"])
+		assert.Equal(t, &types.AttributeValueMemberBOOL{Value: true}, attributes["active"])
+		assert.Equal(t, &types.AttributeValueMemberL{Value: []types.AttributeValue{&types.AttributeValueMemberS{Value: "gold"}}}, attributes["tags"])
+		assert.Equal(t, &types.AttributeValueMemberM{Value: Item{"region": &types.AttributeValueMemberS{Value: "eu"}}}, attributes["labels"])
+
+		actual, err := UnmarshalItem[account](attributes)
+		require.NoError(t, err)
+		assert.Equal(t, item, *actual)
+	})
+	t.Run("with null attribute", func(t *testing.T) {
+		attributes, err := MarshalItem(account{ID: "account-1"})
+		require.NoError(t, err)
+		assert.Equal(t, &types.AttributeValueMemberNULL{Value: true}, attributes["closed"])
+	})
+	t.Run("with sets", func(t *testing.T) {
+		type tagged struct {
+			Tags   []string `json:"tags"`
+			Scores []int    `json:"scores"`
+		}
+		actual, err := UnmarshalItem[tagged](Item{
+			"tags":   &types.AttributeValueMemberSS{Value: []string{"a", "b"}},
+			"scores": &types.AttributeValueMemberNS{Value: []string{"1", "2"}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, tagged{Tags: []string{"a", "b"}, Scores: []int{1, 2}}, *actual)
+	})
+	t.Run("with non object item", func(t *testing.T) {
+		_, err := MarshalItem("account-1")
+		assert.EqualError(t, err, "the item must marshal to a JSON object")
+	})
+}
+
+func TestTable(t *testing.T) {
+	ctx := context.TODO()
+
+	t.Run("with put, get and delete", func(t *testing.T) {
+		table := NewTable[account](newFakeDynamoDB(), "accounts")
+		assert.Equal(t, "accounts", table.Name())
+
+		require.NoError(t, table.Put(ctx, account{ID: "account-1", Owner: "john"}))
+		actual, err := table.Get(ctx, Key{"id": "account-1"})
+		require.NoError(t, err)
+		assert.Equal(t, "john", actual.Owner)
+
+		require.NoError(t, table.Delete(ctx, Key{"id": "account-1"}))
+		_, err = table.Get(ctx, Key{"id": "account-1"})
+		assert.ErrorIs(t, err, ErrItemNotFound)
+
+		_, err = table.Get(ctx, nil)
+		assert.EqualError(t, err, "the item key is required")
+	})
+	t.Run("with conditional writes", func(t *testing.T) {
+		table := NewTable[account](newFakeDynamoDB(), "accounts")
+
+		require.NoError(t, table.PutIf(ctx, account{ID: "account-1", Version: 1}, AttributeNotExists("id")))
+		assert.ErrorIs(t, table.PutIf(ctx, account{ID: "account-1", Version: 1}, AttributeNotExists("id")), ErrConditionFailed)
+
+		// optimistic locking on the version
+		require.NoError(t, table.PutIf(ctx, account{ID: "account-1", Version: 2}, AttributeEquals("version", 1)))
+		assert.ErrorIs(t, table.PutIf(ctx, account{ID: "account-1", Version: 3}, AttributeEquals("version", 1)), ErrConditionFailed)
+
+		assert.ErrorIs(t, table.DeleteIf(ctx, Key{"id": "account-2"}, AttributeExists("id")), ErrConditionFailed)
+		require.NoError(t, table.DeleteIf(ctx, Key{"id": "account-1"}, AttributeExists("id")))
+	})
+	t.Run("with batches retrying the unprocessed items", func(t *testing.T) {
+		client := newFakeDynamoDB()
+		table := NewTable[account](client, "accounts", noDelay(3))
+
+		items := make([]account, 30)
+		keys := make([]Key, 30)
+		for i := range items {
+			id := "account-" + string(rune('a'+i))
+			items[i] = account{ID: id}
+			keys[i] = Key{"id": id}
+		}
+
+		client.unprocessed = 2
+		require.NoError(t, table.BatchWrite(ctx, items, nil))
+		assert.Len(t, client.items, 30)
+		// two chunks and two retries
+		assert.Equal(t, 4, client.batchWrites)
+
+		client.unprocessed = 1
+		actual, err := table.BatchGet(ctx, append(keys, Key{"id": "missing"})...)
+		require.NoError(t, err)
+		assert.Len(t, actual, 30)
+		assert.Equal(t, 2, client.batchGets)
+
+		require.NoError(t, table.BatchWrite(ctx, nil, keys[:10]))
+		assert.Len(t, client.items, 20)
+	})
+	t.Run("with unprocessed items once the retries are exhausted", func(t *testing.T) {
+		client := newFakeDynamoDB()
+		table := NewTable[account](client, "accounts", noDelay(1))
+
+		client.unprocessed = 2
+		err := table.BatchWrite(ctx, []account{{ID: "account-1"}, {ID: "account-2"}}, nil)
+		assert.ErrorIs(t, err, ErrUnprocessedItems)
+		assert.Len(t, client.items, 1)
+
+		client.unprocessed = 2
+		_, err = table.BatchGet(ctx, Key{"id": "account-1"})
+		assert.ErrorIs(t, err, ErrUnprocessedItems)
+	})
+	t.Run("with context cancelled while waiting for a retry", func(t *testing.T) {
+		client := newFakeDynamoDB()
+		table := NewTable[account](client, "accounts")
+
+		cancelCtx, cancel := context.WithCancel(ctx)
+		cancel()
+		client.unprocessed = 1
+		err := table.BatchWrite(cancelCtx, []account{{ID: "account-1"}}, nil)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+	t.Run("with spans", func(t *testing.T) {
+		recorder := tracetest.NewSpanRecorder()
+		provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+		defer func() { _ = provider.Shutdown(ctx) }()
+		previous := otel.GetTracerProvider()
+		otel.SetTracerProvider(provider)
+		defer otel.SetTracerProvider(previous)
+
+		table := NewTable[account](newFakeDynamoDB(), "accounts")
+		require.NoError(t, table.Put(ctx, account{ID: "account-1"}))
+		require.ErrorIs(t, table.PutIf(ctx, account{ID: "account-1"}, AttributeNotExists("id")), ErrConditionFailed)
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 2)
+		assert.Equal(t, "PutItem", spans[0].Name())
+		assert.Contains(t, spans[0].Attributes(), attribute.String("db.system", "dynamodb"))
+		assert.Contains(t, spans[0].Attributes(), attribute.StringSlice("aws.dynamodb.table_names", []string{"accounts"}))
+		assert.Equal(t, "Error", spans[1].Status().Code.String())
+	})
+}
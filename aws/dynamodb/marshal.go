@@ -0,0 +1,164 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package dynamodb
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/pkg/errors"
+)
+
+// Item is the attribute values of a DynamoDB item
+type Item = map[string]types.AttributeValue
+
+// Key is the primary key of an item, by attribute name. The values are marshaled like the item attributes.
+type Key map[string]any
+
+// MarshalItem marshals the item into DynamoDB attribute values using its JSON encoding.
+// The item fields are named after their json tags: strings, numbers, booleans, nulls, slices and
+// nested structs or maps are stored as S, N, BOOL, NULL, L and M attributes.
+// A []byte field is stored as its base64 encoded string.
+func MarshalItem[T any](item T) (Item, error) {
+	value, err := marshalJSON(item)
+	if err != nil {
+		return nil, err
+	}
+
+	attribute, ok := value.(*types.AttributeValueMemberM)
+	if !ok {
+		return nil, errors.New("the item must marshal to a JSON object")
+	}
+	return attribute.Value, nil
+}
+
+// UnmarshalItem unmarshals the DynamoDB attribute values into an item of type T using its JSON decoding
+func UnmarshalItem[T any](item Item) (*T, error) {
+	payload, err := json.Marshal(unmarshalMap(item))
+	if err != nil {
+		return nil, err
+	}
+
+	value := new(T)
+	if err := json.Unmarshal(payload, value); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal the item")
+	}
+	return value, nil
+}
+
+// marshalKey marshals the primary key into DynamoDB attribute values
+func marshalKey(key Key) (Item, error) {
+	if len(key) == 0 {
+		return nil, errors.New("the item key is required")
+	}
+	return MarshalItem(map[string]any(key))
+}
+
+// marshalJSON marshals the value into a DynamoDB attribute value using its JSON encoding
+func marshalJSON(value any) (types.AttributeValue, error) {
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal the item")
+	}
+
+	// keep the numbers as written to not lose any precision
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	var decoded any
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, errors.Wrap(err, "failed to marshal the item")
+	}
+	return marshalValue(decoded), nil
+}
+
+// marshalValue converts a decoded JSON value into a DynamoDB attribute value
+func marshalValue(value any) types.AttributeValue {
+	switch v := value.(type) {
+	case string:
+		return &types.AttributeValueMemberS{Value: v}
+	case json.Number:
+		return &types.AttributeValueMemberN{Value: v.String()}
+	case bool:
+		return &types.AttributeValueMemberBOOL{Value: v}
+	case []any:
+		list := make([]types.AttributeValue, len(v))
+		for i, element := range v {
+			list[i] = marshalValue(element)
+		}
+		return &types.AttributeValueMemberL{Value: list}
+	case map[string]any:
+		attributes := make(Item, len(v))
+		for name, element := range v {
+			attributes[name] = marshalValue(element)
+		}
+		return &types.AttributeValueMemberM{Value: attributes}
+	default:
+		return &types.AttributeValueMemberNULL{Value: true}
+	}
+}
+
+// unmarshalMap converts DynamoDB attribute values into values encoded by encoding/json
+func unmarshalMap(item Item) map[string]any {
+	values := make(map[string]any, len(item))
+	for name, attribute := range item {
+		values[name] = unmarshalValue(attribute)
+	}
+	return values
+}
+
+// unmarshalValue converts a DynamoDB attribute value into a value encoded by encoding/json
+func unmarshalValue(attribute types.AttributeValue) any {
+	switch v := attribute.(type) {
+	case *types.AttributeValueMemberS:
+		return v.Value
+	case *types.AttributeValueMemberN:
+		return json.Number(v.Value)
+	case *types.AttributeValueMemberBOOL:
+		return v.Value
+	case *types.AttributeValueMemberB:
+		return v.Value
+	case *types.AttributeValueMemberL:
+		list := make([]any, len(v.Value))
+		for i, element := range v.Value {
+			list[i] = unmarshalValue(element)
+		}
+		return list
+	case *types.AttributeValueMemberM:
+		return unmarshalMap(v.Value)
+	case *types.AttributeValueMemberSS:
+		return v.Value
+	case *types.AttributeValueMemberNS:
+		numbers := make([]json.Number, len(v.Value))
+		for i, number := range v.Value {
+			numbers[i] = json.Number(number)
+		}
+		return numbers
+	case *types.AttributeValueMemberBS:
+		return v.Value
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,83 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package dynamodb
+
+import (
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+)
+
+const (
+	// DefaultBatchRetries is the default number of retries of the unprocessed items of a batch
+	DefaultBatchRetries = 5
+	// defaultInitialInterval is the first delay before retrying the unprocessed items of a batch
+	defaultInitialInterval = 50 * time.Millisecond
+	// defaultMaxInterval caps the delay between the retries of the unprocessed items of a batch
+	defaultMaxInterval = 2 * time.Second
+)
+
+// config holds the Table settings
+type config struct {
+	newBackOff func() backoff.BackOff
+}
+
+// Option is the interface that applies a configuration option.
+type Option interface {
+	// Apply sets the Option value of a config.
+	Apply(*config)
+}
+
+var _ Option = OptionFunc(nil)
+
+// OptionFunc implements the Option interface.
+type OptionFunc func(*config)
+
+// Apply applies the option
+func (f OptionFunc) Apply(c *config) {
+	f(c)
+}
+
+// WithBatchBackOff sets the strategy delaying the retries of the unprocessed items of a batch.
+// The batch fails with ErrUnprocessedItems once the backoff returns backoff.Stop. The default strategy
+// is an exponential backoff retrying DefaultBatchRetries times.
+func WithBatchBackOff(newBackOff func() backoff.BackOff) Option {
+	return OptionFunc(func(c *config) {
+		c.newBackOff = newBackOff
+	})
+}
+
+// newConfig returns the default settings
+func newConfig() *config {
+	return &config{
+		newBackOff: func() backoff.BackOff {
+			bo := backoff.NewExponentialBackOff()
+			bo.InitialInterval = defaultInitialInterval
+			bo.MaxInterval = defaultMaxInterval
+			bo.MaxElapsedTime = 0
+			return backoff.WithMaxRetries(bo, DefaultBatchRetries)
+		},
+	}
+}
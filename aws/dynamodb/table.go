@@ -0,0 +1,330 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+// Package dynamodb reads and writes typed items in DynamoDB tables with conditional writes,
+// batches retrying their unprocessed items and OpenTelemetry spans. It ships with a localstack
+// TestContainer to run the tests against a local DynamoDB.
+package dynamodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsdynamodb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/cenkalti/backoff/v4"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// instrumentationName is the name of the tracer
+	instrumentationName = "github.com/tochemey/gopack/aws/dynamodb"
+	// maxBatchGetSize is the maximum number of keys read by a BatchGetItem request
+	maxBatchGetSize = 100
+	// maxBatchWriteSize is the maximum number of writes of a BatchWriteItem request
+	maxBatchWriteSize = 25
+)
+
+var (
+	// ErrItemNotFound is returned when the item does not exist
+	ErrItemNotFound = errors.New("dynamodb item not found")
+	// ErrConditionFailed is returned when the condition of a write does not hold
+	ErrConditionFailed = errors.New("dynamodb condition failed")
+	// ErrUnprocessedItems is returned when the items of a batch remain unprocessed once the retries are exhausted
+	ErrUnprocessedItems = errors.New("dynamodb batch items left unprocessed")
+)
+
+// Client is the subset of the DynamoDB client used by the Table
+type Client interface {
+	GetItem(ctx context.Context, params *awsdynamodb.GetItemInput, optFns ...func(*awsdynamodb.Options)) (*awsdynamodb.GetItemOutput, error)
+	PutItem(ctx context.Context, params *awsdynamodb.PutItemInput, optFns ...func(*awsdynamodb.Options)) (*awsdynamodb.PutItemOutput, error)
+	DeleteItem(ctx context.Context, params *awsdynamodb.DeleteItemInput, optFns ...func(*awsdynamodb.Options)) (*awsdynamodb.DeleteItemOutput, error)
+	BatchGetItem(ctx context.Context, params *awsdynamodb.BatchGetItemInput, optFns ...func(*awsdynamodb.Options)) (*awsdynamodb.BatchGetItemOutput, error)
+	BatchWriteItem(ctx context.Context, params *awsdynamodb.BatchWriteItemInput, optFns ...func(*awsdynamodb.Options)) (*awsdynamodb.BatchWriteItemOutput, error)
+}
+
+// Table reads and writes the items of type T of a DynamoDB table.
+// The items are marshaled with MarshalItem and unmarshaled with UnmarshalItem.
+type Table[T any] struct {
+	client Client
+	name   string
+	config *config
+}
+
+// NewTable creates an instance of Table for the given table name
+func NewTable[T any](client Client, name string, opts ...Option) *Table[T] {
+	cfg := newConfig()
+	for _, opt := range opts {
+		opt.Apply(cfg)
+	}
+
+	return &Table[T]{
+		client: client,
+		name:   name,
+		config: cfg,
+	}
+}
+
+// Name returns the table name
+func (t *Table[T]) Name() string {
+	return t.name
+}
+
+// Get returns the item of the given primary key or ErrItemNotFound
+func (t *Table[T]) Get(ctx context.Context, key Key) (*T, error) {
+	spanCtx, span := t.startSpan(ctx, "GetItem")
+	defer span.End()
+
+	attributes, err := marshalKey(key)
+	if err != nil {
+		return nil, t.fail(span, err)
+	}
+
+	output, err := t.client.GetItem(spanCtx, &awsdynamodb.GetItemInput{
+		TableName:      aws.String(t.name),
+		Key:            attributes,
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, t.fail(span, errors.Wrapf(err, "failed to get the item from (%s)", t.name))
+	}
+
+	if len(output.Item) == 0 {
+		return nil, ErrItemNotFound
+	}
+
+	item, err := UnmarshalItem[T](output.Item)
+	if err != nil {
+		return nil, t.fail(span, err)
+	}
+	return item, nil
+}
+
+// Put creates or replaces the item
+func (t *Table[T]) Put(ctx context.Context, item T) error {
+	return t.PutIf(ctx, item, nil)
+}
+
+// PutIf creates or replaces the item when the condition holds. It returns ErrConditionFailed otherwise.
+func (t *Table[T]) PutIf(ctx context.Context, item T, condition *Condition) error {
+	spanCtx, span := t.startSpan(ctx, "PutItem")
+	defer span.End()
+
+	attributes, err := MarshalItem(item)
+	if err != nil {
+		return t.fail(span, err)
+	}
+
+	expression, names, values, err := condition.build()
+	if err != nil {
+		return t.fail(span, err)
+	}
+
+	if _, err := t.client.PutItem(spanCtx, &awsdynamodb.PutItemInput{
+		TableName:                 aws.String(t.name),
+		Item:                      attributes,
+		ConditionExpression:       expression,
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+	}); err != nil {
+		return t.fail(span, t.writeError(err, "failed to put the item into (%s)"))
+	}
+	return nil
+}
+
+// Delete removes the item of the given primary key. Deleting a missing item is not an error.
+func (t *Table[T]) Delete(ctx context.Context, key Key) error {
+	return t.DeleteIf(ctx, key, nil)
+}
+
+// DeleteIf removes the item of the given primary key when the condition holds. It returns ErrConditionFailed otherwise.
+func (t *Table[T]) DeleteIf(ctx context.Context, key Key, condition *Condition) error {
+	spanCtx, span := t.startSpan(ctx, "DeleteItem")
+	defer span.End()
+
+	attributes, err := marshalKey(key)
+	if err != nil {
+		return t.fail(span, err)
+	}
+
+	expression, names, values, err := condition.build()
+	if err != nil {
+		return t.fail(span, err)
+	}
+
+	if _, err := t.client.DeleteItem(spanCtx, &awsdynamodb.DeleteItemInput{
+		TableName:                 aws.String(t.name),
+		Key:                       attributes,
+		ConditionExpression:       expression,
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+	}); err != nil {
+		return t.fail(span, t.writeError(err, "failed to delete the item from (%s)"))
+	}
+	return nil
+}
+
+// BatchGet returns the existing items of the given primary keys, in no particular order.
+// The keys are read by batches of 100 and the unprocessed keys are retried with the batch backoff.
+func (t *Table[T]) BatchGet(ctx context.Context, keys ...Key) ([]*T, error) {
+	spanCtx, span := t.startSpan(ctx, "BatchGetItem")
+	defer span.End()
+
+	items := make([]*T, 0, len(keys))
+	for start := 0; start < len(keys); start += maxBatchGetSize {
+		chunk := keys[start:min(start+maxBatchGetSize, len(keys))]
+		pending := make([]Item, len(chunk))
+		for i, key := range chunk {
+			attributes, err := marshalKey(key)
+			if err != nil {
+				return nil, t.fail(span, err)
+			}
+			pending[i] = attributes
+		}
+
+		bo := t.config.newBackOff()
+		for len(pending) > 0 {
+			output, err := t.client.BatchGetItem(spanCtx, &awsdynamodb.BatchGetItemInput{
+				RequestItems: map[string]types.KeysAndAttributes{
+					t.name: {Keys: pending, ConsistentRead: aws.Bool(true)},
+				},
+			})
+			if err != nil {
+				return nil, t.fail(span, errors.Wrapf(err, "failed to get the items from (%s)", t.name))
+			}
+
+			for _, attributes := range output.Responses[t.name] {
+				item, err := UnmarshalItem[T](attributes)
+				if err != nil {
+					return nil, t.fail(span, err)
+				}
+				items = append(items, item)
+			}
+
+			pending = output.UnprocessedKeys[t.name].Keys
+			if len(pending) > 0 {
+				if err := wait(spanCtx, bo); err != nil {
+					return nil, t.fail(span, err)
+				}
+			}
+		}
+	}
+	return items, nil
+}
+
+// BatchWrite puts the given items and deletes the items of the given primary keys. The writes are sent by
+// batches of 25 and the unprocessed writes are retried with the batch backoff. The writes are not atomic:
+// when an error is returned, some of them may have been applied.
+func (t *Table[T]) BatchWrite(ctx context.Context, puts []T, deletes []Key) error {
+	spanCtx, span := t.startSpan(ctx, "BatchWriteItem")
+	defer span.End()
+
+	requests := make([]types.WriteRequest, 0, len(puts)+len(deletes))
+	for _, item := range puts {
+		attributes, err := MarshalItem(item)
+		if err != nil {
+			return t.fail(span, err)
+		}
+		requests = append(requests, types.WriteRequest{PutRequest: &types.PutRequest{Item: attributes}})
+	}
+
+	for _, key := range deletes {
+		attributes, err := marshalKey(key)
+		if err != nil {
+			return t.fail(span, err)
+		}
+		requests = append(requests, types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: attributes}})
+	}
+
+	for start := 0; start < len(requests); start += maxBatchWriteSize {
+		pending := requests[start:min(start+maxBatchWriteSize, len(requests))]
+		bo := t.config.newBackOff()
+		for len(pending) > 0 {
+			output, err := t.client.BatchWriteItem(spanCtx, &awsdynamodb.BatchWriteItemInput{
+				RequestItems: map[string][]types.WriteRequest{t.name: pending},
+			})
+			if err != nil {
+				return t.fail(span, errors.Wrapf(err, "failed to write the items into (%s)", t.name))
+			}
+
+			pending = output.UnprocessedItems[t.name]
+			if len(pending) > 0 {
+				if err := wait(spanCtx, bo); err != nil {
+					return t.fail(span, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// startSpan starts the client span of the given operation
+func (t *Table[T]) startSpan(ctx context.Context, operation string) (context.Context, trace.Span) {
+	tracer := otel.GetTracerProvider()
+	return tracer.Tracer(instrumentationName).Start(ctx, operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemDynamoDB,
+			semconv.DBOperationName(operation),
+			semconv.AWSDynamoDBTableNames(t.name),
+		))
+}
+
+// fail records the error on the span and returns it
+func (t *Table[T]) fail(span trace.Span, err error) error {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+	return err
+}
+
+// writeError returns ErrConditionFailed when the condition of the write does not hold
+func (t *Table[T]) writeError(err error, format string) error {
+	var conditionErr *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionErr) {
+		return ErrConditionFailed
+	}
+	return errors.Wrapf(err, format, t.name)
+}
+
+// wait waits for the next backoff delay before retrying the unprocessed items of a batch
+func wait(ctx context.Context, bo backoff.BackOff) error {
+	delay := bo.NextBackOff()
+	if delay == backoff.Stop {
+		return ErrUnprocessedItems
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
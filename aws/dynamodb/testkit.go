@@ -0,0 +1,158 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsdynamodb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/ory/dockertest"
+	"github.com/ory/dockertest/docker"
+)
+
+const (
+	// testRegion is the region of the localstack DynamoDB
+	testRegion = "us-east-1"
+	// testCredential is the access key and the secret key accepted by localstack
+	testCredential = "test"
+)
+
+// TestContainer helps creates a localstack docker container serving DynamoDB to
+// run unit tests
+type TestContainer struct {
+	endpoint string
+	client   *awsdynamodb.Client
+
+	resource *dockertest.Resource
+	pool     *dockertest.Pool
+}
+
+// NewTestContainer create a localstack DynamoDB test container useful for unit and integration tests
+// This function will exit when there is an error.Call this function inside your SetupSuite to create the container before the tests.
+func NewTestContainer() *TestContainer {
+	// create the docker pool
+	pool, err := dockertest.NewPool("")
+	if err != nil {
+		log.Fatalf("Could not connect to docker: %s", err)
+	}
+	// pulls an image, creates a container based on it and runs it
+	resource, err := pool.RunWithOptions(&dockertest.RunOptions{
+		Repository: "localstack/localstack",
+		Tag:        "3.8",
+		Env: []string{
+			"SERVICES=dynamodb",
+			fmt.Sprintf("AWS_DEFAULT_REGION=%s", testRegion),
+		},
+	}, func(config *docker.HostConfig) {
+		// set AutoRemove to true so that stopped container goes away by itself
+		config.AutoRemove = true
+		config.RestartPolicy = docker.RestartPolicy{Name: "no"}
+	})
+	// handle the error
+	if err != nil {
+		log.Fatalf("Could not start resource: %s", err)
+	}
+	// get the endpoint of the localstack gateway
+	endpoint := fmt.Sprintf("http://%s", resource.GetHostPort("4566/tcp"))
+	log.Println("Connecting to DynamoDB on url: ", endpoint)
+	// Tell docker to hard kill the container
+	_ = resource.Expire(120)
+
+	client := awsdynamodb.New(awsdynamodb.Options{
+		Region:       testRegion,
+		BaseEndpoint: aws.String(endpoint),
+		Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: testCredential, SecretAccessKey: testCredential}, nil
+		}),
+	})
+
+	// exponential backoff-retry, because the application in the container might not be ready to accept connections yet
+	pool.MaxWait = 120 * time.Second
+	if err = pool.Retry(func() error {
+		_, err := client.ListTables(context.Background(), &awsdynamodb.ListTablesInput{})
+		return err
+	}); err != nil {
+		log.Fatalf("Could not connect to docker: %s", err)
+	}
+
+	return &TestContainer{
+		endpoint: endpoint,
+		client:   client,
+		resource: resource,
+		pool:     pool,
+	}
+}
+
+// Endpoint returns the URL of the localstack DynamoDB
+func (c TestContainer) Endpoint() string {
+	return c.endpoint
+}
+
+// Client returns a DynamoDB client connected to the test container
+func (c TestContainer) Client() *awsdynamodb.Client {
+	return c.client
+}
+
+// CreateTable creates an on-demand table whose string partition key and optional string sort key
+// are the given attributes, and waits for the table to be active
+func (c TestContainer) CreateTable(ctx context.Context, name, partitionKey, sortKey string) error {
+	attributes := []types.AttributeDefinition{{AttributeName: aws.String(partitionKey), AttributeType: types.ScalarAttributeTypeS}}
+	schema := []types.KeySchemaElement{{AttributeName: aws.String(partitionKey), KeyType: types.KeyTypeHash}}
+	if sortKey != "" {
+		attributes = append(attributes, types.AttributeDefinition{AttributeName: aws.String(sortKey), AttributeType: types.ScalarAttributeTypeS})
+		schema = append(schema, types.KeySchemaElement{AttributeName: aws.String(sortKey), KeyType: types.KeyTypeRange})
+	}
+
+	if _, err := c.client.CreateTable(ctx, &awsdynamodb.CreateTableInput{
+		TableName:            aws.String(name),
+		AttributeDefinitions: attributes,
+		KeySchema:            schema,
+		BillingMode:          types.BillingModePayPerRequest,
+	}); err != nil {
+		return err
+	}
+
+	waiter := awsdynamodb.NewTableExistsWaiter(c.client)
+	return waiter.Wait(ctx, &awsdynamodb.DescribeTableInput{TableName: aws.String(name)}, time.Minute)
+}
+
+// DeleteTable deletes the table
+func (c TestContainer) DeleteTable(ctx context.Context, name string) error {
+	_, err := c.client.DeleteTable(ctx, &awsdynamodb.DeleteTableInput{TableName: aws.String(name)})
+	return err
+}
+
+// Cleanup frees the resource by removing a container and linked volumes from docker.
+// Call this function inside your TearDownSuite to clean-up resources after each test.
+func (c TestContainer) Cleanup() {
+	if err := c.pool.Purge(c.resource); err != nil {
+		log.Fatalf("Could not purge resource: %s", err)
+	}
+}
@@ -0,0 +1,84 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package dynamodb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type testkitSuite struct {
+	suite.Suite
+	container *TestContainer
+}
+
+// SetupSuite starts the localstack DynamoDB
+func (s *testkitSuite) SetupSuite() {
+	s.container = NewTestContainer()
+}
+
+func (s *testkitSuite) TearDownSuite() {
+	s.container.Cleanup()
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestTestKitSuite(t *testing.T) {
+	suite.Run(t, new(testkitSuite))
+}
+
+func (s *testkitSuite) TestTable() {
+	ctx := context.TODO()
+	s.Require().NoError(s.container.CreateTable(ctx, "accounts", "id", ""))
+	defer func() {
+		s.Assert().NoError(s.container.DeleteTable(ctx, "accounts"))
+	}()
+
+	table := NewTable[account](s.container.Client(), "accounts")
+	s.Require().NoError(table.PutIf(ctx, account{ID: "account-1", Owner: "john", Version: 1}, AttributeNotExists("id")))
+	s.Assert().ErrorIs(table.PutIf(ctx, account{ID: "account-1"}, AttributeNotExists("id")), ErrConditionFailed)
+	s.Assert().ErrorIs(table.PutIf(ctx, account{ID: "account-1", Version: 3}, AttributeEquals("version", 2)), ErrConditionFailed)
+
+	actual, err := table.Get(ctx, Key{"id": "account-1"})
+	s.Require().NoError(err)
+	s.Assert().Equal("john", actual.Owner)
+
+	items := make([]account, 60)
+	keys := make([]Key, 60)
+	for i := range items {
+		items[i] = account{ID: string(rune('A' + i))}
+		keys[i] = Key{"id": items[i].ID}
+	}
+	s.Require().NoError(table.BatchWrite(ctx, items, []Key{{"id": "account-1"}}))
+
+	fetched, err := table.BatchGet(ctx, keys...)
+	s.Require().NoError(err)
+	s.Assert().Len(fetched, 60)
+
+	_, err = table.Get(ctx, Key{"id": "account-1"})
+	s.Assert().ErrorIs(err, ErrItemNotFound)
+}
@@ -0,0 +1,87 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package messaging
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+)
+
+const (
+	// DefaultConsumersCount is the default number of workers handling the messages
+	DefaultConsumersCount = 1
+	// DefaultMaxMessages is the default number of messages received per poll. It is also the SQS maximum.
+	DefaultMaxMessages = 10
+	// DefaultWaitTime is the default long polling duration. It is also the SQS maximum.
+	DefaultWaitTime = 20 * time.Second
+)
+
+// SubscriberConfig defines the Subscriber settings
+type SubscriberConfig struct {
+	QueueURL          string        // QueueURL is the URL of the queue to consume
+	ConsumersCount    int           // ConsumersCount is the number of workers handling the messages. It defaults to DefaultConsumersCount
+	MaxMessages       int32         // MaxMessages is the number of messages received per poll, between 1 and 10. It defaults to DefaultMaxMessages
+	WaitTime          time.Duration // WaitTime is the long polling duration, up to 20 seconds. It defaults to DefaultWaitTime
+	VisibilityTimeout time.Duration // VisibilityTimeout overrides the queue visibility timeout of the received messages when set
+}
+
+// Validate checks the config and sets the default values
+func (c *SubscriberConfig) Validate() error {
+	var err error
+	if c.QueueURL == "" {
+		err = multierr.Append(err, errors.New("the queue URL is required"))
+	}
+
+	if c.ConsumersCount < 0 || c.VisibilityTimeout < 0 {
+		err = multierr.Append(err, errors.New("the consumers count and visibility timeout must not be negative"))
+	}
+
+	if c.MaxMessages < 0 || c.MaxMessages > DefaultMaxMessages {
+		err = multierr.Append(err, errors.Errorf("the max messages must be between 1 and %d", DefaultMaxMessages))
+	}
+
+	if c.WaitTime < 0 || c.WaitTime > DefaultWaitTime {
+		err = multierr.Append(err, errors.Errorf("the wait time must not exceed %s", DefaultWaitTime))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if c.ConsumersCount == 0 {
+		c.ConsumersCount = DefaultConsumersCount
+	}
+
+	if c.MaxMessages == 0 {
+		c.MaxMessages = DefaultMaxMessages
+	}
+
+	if c.WaitTime == 0 {
+		c.WaitTime = DefaultWaitTime
+	}
+	return nil
+}
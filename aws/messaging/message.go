@@ -0,0 +1,154 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package messaging
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	snstypes "github.com/aws/aws-sdk-go-v2/service/sns/types"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+
+	"github.com/tochemey/gopack/broker"
+)
+
+const (
+	// KeyAttribute is the message attribute carrying the ordering key
+	KeyAttribute = "Gopack-Ordering-Key"
+	// IDAttribute is the message attribute carrying the message identifier
+	IDAttribute = "Gopack-Message-Id"
+
+	// stringDataType is the data type of the message attributes
+	stringDataType = "String"
+	// fifoSuffix ends the names of the FIFO topics and queues
+	fifoSuffix = ".fifo"
+)
+
+// Message is a message published to a SNS topic or a SQS queue and received from a SQS queue.
+// The ID is the deduplication ID of the messages sent to a FIFO topic or queue: a message with an ID
+// already seen within the deduplication interval is dropped. The Key is the message group ID of the
+// messages sent to a FIFO topic or queue and is then required. The Payload must be valid UTF-8.
+type Message = broker.Message
+
+// SubscriptionHandler handles the messages received by the Subscriber.
+// The message is deleted from the queue when the handler returns nil and redelivered otherwise.
+type SubscriptionHandler = broker.SubscriptionHandler
+
+// isFIFO returns true when the topic ARN or queue URL refers to a FIFO topic or queue
+func isFIFO(destination string) bool {
+	return strings.HasSuffix(destination, fifoSuffix)
+}
+
+// snsAttributes returns the SNS attributes of the message
+func snsAttributes(m *Message) map[string]snstypes.MessageAttributeValue {
+	attributes := make(map[string]snstypes.MessageAttributeValue, len(m.Attributes)+2)
+	for name, value := range allAttributes(m) {
+		attributes[name] = snstypes.MessageAttributeValue{
+			DataType:    aws.String(stringDataType),
+			StringValue: aws.String(value),
+		}
+	}
+	return attributes
+}
+
+// sqsAttributes returns the SQS attributes of the message
+func sqsAttributes(m *Message) map[string]sqstypes.MessageAttributeValue {
+	attributes := make(map[string]sqstypes.MessageAttributeValue, len(m.Attributes)+2)
+	for name, value := range allAttributes(m) {
+		attributes[name] = sqstypes.MessageAttributeValue{
+			DataType:    aws.String(stringDataType),
+			StringValue: aws.String(value),
+		}
+	}
+	return attributes
+}
+
+// allAttributes returns the message attributes along with the ID and Key attributes
+func allAttributes(m *Message) map[string]string {
+	attributes := make(map[string]string, len(m.Attributes)+2)
+	for name, value := range m.Attributes {
+		attributes[name] = value
+	}
+
+	if m.ID != "" {
+		attributes[IDAttribute] = m.ID
+	}
+
+	if m.Key != "" {
+		attributes[KeyAttribute] = m.Key
+	}
+	return attributes
+}
+
+// snsNotification is the SQS message body of a SNS notification delivered without raw message delivery
+type snsNotification struct {
+	Type              string
+	TopicArn          string
+	Message           string
+	MessageAttributes map[string]struct {
+		Type  string
+		Value string
+	}
+}
+
+// fromSQSMessage converts the received SQS message into a Message. The SNS notifications
+// delivered without raw message delivery are unwrapped.
+func fromSQSMessage(msg sqstypes.Message) *Message {
+	body := aws.ToString(msg.Body)
+	attributes := make(map[string]string, len(msg.MessageAttributes))
+	for name, value := range msg.MessageAttributes {
+		attributes[name] = aws.ToString(value.StringValue)
+	}
+
+	notification := new(snsNotification)
+	if err := json.Unmarshal([]byte(body), notification); err == nil && notification.Type == "Notification" && notification.TopicArn != "" {
+		body = notification.Message
+		for name, value := range notification.MessageAttributes {
+			attributes[name] = value.Value
+		}
+	}
+
+	message := &Message{
+		ID:      attributes[IDAttribute],
+		Key:     attributes[KeyAttribute],
+		Payload: []byte(body),
+	}
+	delete(attributes, IDAttribute)
+	delete(attributes, KeyAttribute)
+
+	if message.ID == "" {
+		message.ID = msg.Attributes[string(sqstypes.MessageSystemAttributeNameMessageDeduplicationId)]
+	}
+
+	if message.Key == "" {
+		message.Key = msg.Attributes[string(sqstypes.MessageSystemAttributeNameMessageGroupId)]
+	}
+
+	if len(attributes) > 0 {
+		message.Attributes = attributes
+	}
+	return message
+}
@@ -0,0 +1,292 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package messaging
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSNS records the published messages and created resources
+type fakeSNS struct {
+	published     []*sns.PublishInput
+	topics        []*sns.CreateTopicInput
+	subscriptions []*sns.SubscribeInput
+}
+
+func (f *fakeSNS) Publish(_ context.Context, params *sns.PublishInput, _ ...func(*sns.Options)) (*sns.PublishOutput, error) {
+	f.published = append(f.published, params)
+	return &sns.PublishOutput{}, nil
+}
+
+func (f *fakeSNS) CreateTopic(_ context.Context, params *sns.CreateTopicInput, _ ...func(*sns.Options)) (*sns.CreateTopicOutput, error) {
+	f.topics = append(f.topics, params)
+	return &sns.CreateTopicOutput{TopicArn: aws.String("arn:aws:sns:us-east-1:000000000000:" + aws.ToString(params.Name))}, nil
+}
+
+func (f *fakeSNS) Subscribe(_ context.Context, params *sns.SubscribeInput, _ ...func(*sns.Options)) (*sns.SubscribeOutput, error) {
+	f.subscriptions = append(f.subscriptions, params)
+	return &sns.SubscribeOutput{SubscriptionArn: aws.String(aws.ToString(params.TopicArn) + ":subscription")}, nil
+}
+
+// fakeSQS is an in-memory queue
+type fakeSQS struct {
+	mu         sync.Mutex
+	sent       []*sqs.SendMessageInput
+	pending    []sqstypes.Message
+	deleted    []string
+	created    []*sqs.CreateQueueInput
+	attributes []*sqs.SetQueueAttributesInput
+}
+
+func (f *fakeSQS) SendMessage(_ context.Context, params *sqs.SendMessageInput, _ ...func(*sqs.Options)) (*sqs.SendMessageOutput, error) {
+	f.sent = append(f.sent, params)
+	return &sqs.SendMessageOutput{}, nil
+}
+
+func (f *fakeSQS) ReceiveMessage(ctx context.Context, params *sqs.ReceiveMessageInput, _ ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+	f.mu.Lock()
+	count := min(int(params.MaxNumberOfMessages), len(f.pending))
+	messages := f.pending[:count]
+	f.pending = f.pending[count:]
+	f.mu.Unlock()
+
+	if count == 0 {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	return &sqs.ReceiveMessageOutput{Messages: messages}, nil
+}
+
+func (f *fakeSQS) DeleteMessage(_ context.Context, params *sqs.DeleteMessageInput, _ ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.deleted = append(f.deleted, aws.ToString(params.ReceiptHandle))
+	return &sqs.DeleteMessageOutput{}, nil
+}
+
+func (f *fakeSQS) CreateQueue(_ context.Context, params *sqs.CreateQueueInput, _ ...func(*sqs.Options)) (*sqs.CreateQueueOutput, error) {
+	f.created = append(f.created, params)
+	return &sqs.CreateQueueOutput{QueueUrl: aws.String("https://sqs.us-east-1.amazonaws.com/000000000000/" + aws.ToString(params.QueueName))}, nil
+}
+
+func (f *fakeSQS) GetQueueAttributes(_ context.Context, _ *sqs.GetQueueAttributesInput, _ ...func(*sqs.Options)) (*sqs.GetQueueAttributesOutput, error) {
+	return &sqs.GetQueueAttributesOutput{Attributes: map[string]string{"QueueArn": "arn:aws:sqs:us-east-1:000000000000:orders"}}, nil
+}
+
+func (f *fakeSQS) SetQueueAttributes(_ context.Context, params *sqs.SetQueueAttributesInput, _ ...func(*sqs.Options)) (*sqs.SetQueueAttributesOutput, error) {
+	f.attributes = append(f.attributes, params)
+	return &sqs.SetQueueAttributesOutput{}, nil
+}
+
+func (f *fakeSQS) deletedCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.deleted)
+}
+
+func TestPublisher(t *testing.T) {
+	ctx := context.TODO()
+
+	t.Run("to a FIFO topic", func(t *testing.T) {
+		client := new(fakeSNS)
+		publisher := NewTopicPublisher(client)
+		err := publisher.Publish(ctx, "arn:aws:sns:us-east-1:000000000000:orders.fifo",
+			&Message{ID: "order-1", Key: "customer-1", Payload: []byte("created"), Attributes: map[string]string{"source": "test"}})
+		require.NoError(t, err)
+		require.Len(t, client.published, 1)
+
+		input := client.published[0]
+		assert.Equal(t, "created", aws.ToString(input.Message))
+		assert.Equal(t, "customer-1", aws.ToString(input.MessageGroupId))
+		assert.Equal(t, "order-1", aws.ToString(input.MessageDeduplicationId))
+		assert.Equal(t, "test", aws.ToString(input.MessageAttributes["source"].StringValue))
+		assert.Equal(t, "order-1", aws.ToString(input.MessageAttributes[IDAttribute].StringValue))
+		assert.Equal(t, "customer-1", aws.ToString(input.MessageAttributes[KeyAttribute].StringValue))
+	})
+
+	t.Run("to a FIFO queue without key", func(t *testing.T) {
+		publisher := NewQueuePublisher(new(fakeSQS))
+		err := publisher.Publish(ctx, "https://sqs.us-east-1.amazonaws.com/000000000000/orders.fifo", &Message{Payload: []byte("created")})
+		assert.EqualError(t, err, "the message key is required by the FIFO destination (https://sqs.us-east-1.amazonaws.com/000000000000/orders.fifo)")
+	})
+
+	t.Run("to a standard queue", func(t *testing.T) {
+		client := new(fakeSQS)
+		publisher := NewQueuePublisher(client)
+		require.NoError(t, publisher.Publish(ctx, "https://sqs.us-east-1.amazonaws.com/000000000000/orders", &Message{Payload: []byte("created")}))
+		require.Len(t, client.sent, 1)
+		assert.Equal(t, "created", aws.ToString(client.sent[0].MessageBody))
+		assert.Nil(t, client.sent[0].MessageGroupId)
+		assert.Empty(t, client.sent[0].MessageAttributes)
+	})
+
+	t.Run("with invalid payload", func(t *testing.T) {
+		publisher := NewQueuePublisher(new(fakeSQS))
+		err := publisher.Publish(ctx, "https://sqs.us-east-1.amazonaws.com/000000000000/orders", &Message{Payload: []byte{0xff}})
+		assert.EqualError(t, err, "the message payload must be valid UTF-8")
+	})
+}
+
+func TestFromSQSMessage(t *testing.T) {
+	t.Run("with raw message", func(t *testing.T) {
+		message := fromSQSMessage(sqstypes.Message{
+			Body: aws.String("created"),
+			MessageAttributes: map[string]sqstypes.MessageAttributeValue{
+				IDAttribute: {StringValue: aws.String("order-1")},
+				"source":    {StringValue: aws.String("test")},
+			},
+			Attributes: map[string]string{"MessageGroupId": "customer-1"},
+		})
+		assert.Equal(t, &Message{
+			ID:         "order-1",
+			Key:        "customer-1",
+			Payload:    []byte("created"),
+			Attributes: map[string]string{"source": "test"},
+		}, message)
+	})
+
+	t.Run("with SNS notification", func(t *testing.T) {
+		message := fromSQSMessage(sqstypes.Message{
+			Body: aws.String(`{"Type":"Notification","TopicArn":"arn:aws:sns:us-east-1:000000000000:orders","Message":"created",` +
+				`"MessageAttributes":{"Gopack-Ordering-Key":{"Type":"String","Value":"customer-1"}}}`),
+		})
+		assert.Equal(t, &Message{Key: "customer-1", Payload: []byte("created")}, message)
+	})
+}
+
+func TestSubscriber(t *testing.T) {
+	t.Run("with invalid config", func(t *testing.T) {
+		_, err := NewSubscriber(new(fakeSQS), &SubscriberConfig{MaxMessages: 11})
+		assert.EqualError(t, err, "invalid subscriber config: the queue URL is required; the max messages must be between 1 and 10")
+	})
+
+	t.Run("with messages", func(t *testing.T) {
+		client := new(fakeSQS)
+		for i, key := range []string{"customer-1", "customer-2", "customer-1", "customer-1"} {
+			client.pending = append(client.pending, sqstypes.Message{
+				Body:              aws.String(string(rune('a' + i))),
+				ReceiptHandle:     aws.String(string(rune('a' + i))),
+				MessageAttributes: map[string]sqstypes.MessageAttributeValue{KeyAttribute: {StringValue: aws.String(key)}},
+			})
+		}
+
+		subscriber, err := NewSubscriber(client, &SubscriberConfig{
+			QueueURL:       "https://sqs.us-east-1.amazonaws.com/000000000000/orders",
+			ConsumersCount: 2,
+			MaxMessages:    2,
+		})
+		require.NoError(t, err)
+
+		mu := sync.Mutex{}
+		handled := make(map[string][]string)
+		ctx, cancel := context.WithCancel(context.TODO())
+		done := make(chan error, 1)
+		go func() {
+			done <- subscriber.Consume(ctx, func(_ context.Context, message *Message) error {
+				mu.Lock()
+				defer mu.Unlock()
+				handled[message.Key] = append(handled[message.Key], string(message.Payload))
+				if string(message.Payload) == "b" {
+					return errors.New("unavailable")
+				}
+				return nil
+			})
+		}()
+
+		// the failed message is not deleted
+		assert.Eventually(t, func() bool { return client.deletedCount() == 3 }, 5*time.Second, 10*time.Millisecond)
+		cancel()
+		require.NoError(t, <-done)
+
+		assert.Equal(t, []string{"a", "c", "d"}, handled["customer-1"])
+		assert.Equal(t, []string{"b"}, handled["customer-2"])
+	})
+}
+
+func TestTooling(t *testing.T) {
+	ctx := context.TODO()
+
+	t.Run("create FIFO topic", func(t *testing.T) {
+		client := new(fakeSNS)
+		arn, err := NewTooling(client, new(fakeSQS)).CreateTopic(ctx, "orders.fifo")
+		require.NoError(t, err)
+		assert.Equal(t, "arn:aws:sns:us-east-1:000000000000:orders.fifo", arn)
+		assert.Equal(t, map[string]string{"FifoTopic": "true"}, client.topics[0].Attributes)
+	})
+
+	t.Run("create queue with dead-letter queue", func(t *testing.T) {
+		client := new(fakeSQS)
+		url, arn, err := NewTooling(new(fakeSNS), client).CreateQueue(ctx, "orders.fifo", &QueueConfig{
+			VisibilityTimeout:         time.Minute,
+			DeadLetterQueueARN:        "arn:aws:sqs:us-east-1:000000000000:orders-dlq.fifo",
+			MaxReceiveCount:           5,
+			ContentBasedDeduplication: true,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "https://sqs.us-east-1.amazonaws.com/000000000000/orders.fifo", url)
+		assert.Equal(t, "arn:aws:sqs:us-east-1:000000000000:orders", arn)
+		assert.Equal(t, map[string]string{
+			"FifoQueue":                 "true",
+			"ContentBasedDeduplication": "true",
+			"VisibilityTimeout":         "60",
+			"RedrivePolicy":             `{"deadLetterTargetArn":"arn:aws:sqs:us-east-1:000000000000:orders-dlq.fifo","maxReceiveCount":"5"}`,
+		}, client.created[0].Attributes)
+	})
+
+	t.Run("create queue with invalid dead-letter queue", func(t *testing.T) {
+		tooling := NewTooling(new(fakeSNS), new(fakeSQS))
+		_, _, err := tooling.CreateQueue(ctx, "orders", &QueueConfig{DeadLetterQueueARN: "arn:aws:sqs:us-east-1:000000000000:orders-dlq"})
+		assert.EqualError(t, err, "the max receive count is required with a dead-letter queue")
+
+		_, _, err = tooling.CreateQueue(ctx, "orders", &QueueConfig{DeadLetterQueueARN: "arn:aws:sqs:us-east-1:000000000000:orders-dlq.fifo", MaxReceiveCount: 3})
+		assert.EqualError(t, err, "the queue (orders) and its dead-letter queue must both be standard or FIFO queues")
+	})
+
+	t.Run("subscribe queue to topic", func(t *testing.T) {
+		snsClient := new(fakeSNS)
+		sqsClient := new(fakeSQS)
+		subscription, err := NewTooling(snsClient, sqsClient).Subscribe(ctx,
+			"arn:aws:sns:us-east-1:000000000000:orders", "https://sqs.us-east-1.amazonaws.com/000000000000/orders")
+		require.NoError(t, err)
+		assert.Equal(t, "arn:aws:sns:us-east-1:000000000000:orders:subscription", subscription)
+		assert.Equal(t, "arn:aws:sqs:us-east-1:000000000000:orders", aws.ToString(snsClient.subscriptions[0].Endpoint))
+		assert.Equal(t, map[string]string{"RawMessageDelivery": "true"}, snsClient.subscriptions[0].Attributes)
+		assert.Contains(t, sqsClient.attributes[0].Attributes["Policy"], `"aws:SourceArn":"arn:aws:sns:us-east-1:000000000000:orders"`)
+	})
+}
@@ -0,0 +1,164 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package messaging
+
+import (
+	"context"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+
+	"github.com/tochemey/gopack/broker"
+)
+
+// instrumentationName is the name of the tracer
+const instrumentationName = "github.com/tochemey/gopack/aws/messaging"
+
+// Publisher publishes messages to a SNS topic or a SQS queue
+type Publisher interface {
+	// Publish publishes the messages in the given order to the destination. It returns once every
+	// message has been accepted or at the first failure.
+	Publish(ctx context.Context, destination string, messages ...*Message) error
+}
+
+// SNSPublisher is the subset of the SNS client used by the topic Publisher
+type SNSPublisher interface {
+	Publish(ctx context.Context, params *sns.PublishInput, optFns ...func(*sns.Options)) (*sns.PublishOutput, error)
+}
+
+// SQSSender is the subset of the SQS client used by the queue Publisher
+type SQSSender interface {
+	SendMessage(ctx context.Context, params *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error)
+}
+
+// topicPublisher publishes the messages to SNS topics
+type topicPublisher struct {
+	client SNSPublisher
+}
+
+// queuePublisher publishes the messages to SQS queues
+type queuePublisher struct {
+	client SQSSender
+}
+
+// enforce compilation error
+var (
+	_ Publisher        = (*topicPublisher)(nil)
+	_ Publisher        = (*queuePublisher)(nil)
+	_ broker.Publisher = (*topicPublisher)(nil)
+	_ broker.Publisher = (*queuePublisher)(nil)
+)
+
+// NewTopicPublisher creates an instance of Publisher whose destinations are SNS topic ARNs
+func NewTopicPublisher(client SNSPublisher) Publisher {
+	return &topicPublisher{client: client}
+}
+
+// NewQueuePublisher creates an instance of Publisher whose destinations are SQS queue URLs
+func NewQueuePublisher(client SQSSender) Publisher {
+	return &queuePublisher{client: client}
+}
+
+// Publish publishes the messages to the topic
+func (p *topicPublisher) Publish(ctx context.Context, topicARN string, messages ...*Message) error {
+	// Create a span
+	tracer := otel.GetTracerProvider()
+	spanCtx, span := tracer.Tracer(instrumentationName).Start(ctx, "Publish")
+	defer span.End()
+
+	for _, message := range messages {
+		if err := validateMessage(topicARN, message); err != nil {
+			return err
+		}
+
+		input := &sns.PublishInput{
+			TopicArn:          aws.String(topicARN),
+			Message:           aws.String(string(message.Payload)),
+			MessageAttributes: snsAttributes(message),
+		}
+
+		if isFIFO(topicARN) {
+			input.MessageGroupId = aws.String(message.Key)
+			if message.ID != "" {
+				input.MessageDeduplicationId = aws.String(message.ID)
+			}
+		}
+
+		if _, err := p.client.Publish(spanCtx, input); err != nil {
+			span.RecordError(err)
+			return errors.Wrapf(err, "failed to publish the message to (%s)", topicARN)
+		}
+	}
+	return nil
+}
+
+// Publish sends the messages to the queue
+func (p *queuePublisher) Publish(ctx context.Context, queueURL string, messages ...*Message) error {
+	// Create a span
+	tracer := otel.GetTracerProvider()
+	spanCtx, span := tracer.Tracer(instrumentationName).Start(ctx, "Publish")
+	defer span.End()
+
+	for _, message := range messages {
+		if err := validateMessage(queueURL, message); err != nil {
+			return err
+		}
+
+		input := &sqs.SendMessageInput{
+			QueueUrl:          aws.String(queueURL),
+			MessageBody:       aws.String(string(message.Payload)),
+			MessageAttributes: sqsAttributes(message),
+		}
+
+		if isFIFO(queueURL) {
+			input.MessageGroupId = aws.String(message.Key)
+			if message.ID != "" {
+				input.MessageDeduplicationId = aws.String(message.ID)
+			}
+		}
+
+		if _, err := p.client.SendMessage(spanCtx, input); err != nil {
+			span.RecordError(err)
+			return errors.Wrapf(err, "failed to send the message to (%s)", queueURL)
+		}
+	}
+	return nil
+}
+
+// validateMessage checks the message can be sent to the destination
+func validateMessage(destination string, message *Message) error {
+	if !utf8.Valid(message.Payload) {
+		return errors.New("the message payload must be valid UTF-8")
+	}
+
+	if isFIFO(destination) && message.Key == "" {
+		return errors.Errorf("the message key is required by the FIFO destination (%s)", destination)
+	}
+	return nil
+}
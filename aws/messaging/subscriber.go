@@ -0,0 +1,169 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package messaging
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+
+	"github.com/tochemey/gopack/broker"
+)
+
+// SQSReceiver is the subset of the SQS client used by the Subscriber
+type SQSReceiver interface {
+	ReceiveMessage(ctx context.Context, params *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error)
+	DeleteMessage(ctx context.Context, params *sqs.DeleteMessageInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error)
+}
+
+// Subscriber consumes the messages of a SQS queue
+type Subscriber interface {
+	// Consume handles the messages of the queue with the given handler until the context is done or
+	// receiving the messages fails. The messages sharing a Key are handled sequentially by the same worker
+	// while the other messages are handled in parallel by ConsumersCount workers.
+	// A failed message is redelivered once its visibility timeout expires. Configure a redrive policy on
+	// the queue, see QueueConfig, to move the messages failing repeatedly to a dead-letter queue.
+	Consume(ctx context.Context, handler SubscriptionHandler) error
+}
+
+// subscriber implements Subscriber
+type subscriber struct {
+	client SQSReceiver
+	config *SubscriberConfig
+}
+
+// enforce compilation error
+var (
+	_ Subscriber        = (*subscriber)(nil)
+	_ broker.Subscriber = (*subscriber)(nil)
+)
+
+// NewSubscriber creates an instance of Subscriber
+func NewSubscriber(client SQSReceiver, config *SubscriberConfig) (Subscriber, error) {
+	if err := config.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid subscriber config")
+	}
+	return &subscriber{client: client, config: config}, nil
+}
+
+// Consume handles the messages of the queue until the context is done
+func (s *subscriber) Consume(ctx context.Context, handler SubscriptionHandler) error {
+	// the in-flight messages are handled before returning
+	handlerCtx := context.WithoutCancel(ctx)
+	workers := make([]chan sqstypes.Message, s.config.ConsumersCount)
+	wg := sync.WaitGroup{}
+	for i := range workers {
+		workers[i] = make(chan sqstypes.Message)
+		wg.Add(1)
+		go func(messages <-chan sqstypes.Message) {
+			defer wg.Done()
+			for msg := range messages {
+				s.handle(handlerCtx, handler, msg)
+			}
+		}(workers[i])
+	}
+
+	defer func() {
+		for _, messages := range workers {
+			close(messages)
+		}
+		wg.Wait()
+	}()
+
+	input := &sqs.ReceiveMessageInput{
+		QueueUrl:              aws.String(s.config.QueueURL),
+		MaxNumberOfMessages:   s.config.MaxMessages,
+		WaitTimeSeconds:       int32(s.config.WaitTime.Seconds()),
+		MessageAttributeNames: []string{"All"},
+		MessageSystemAttributeNames: []sqstypes.MessageSystemAttributeName{
+			sqstypes.MessageSystemAttributeNameMessageGroupId,
+			sqstypes.MessageSystemAttributeNameMessageDeduplicationId,
+		},
+	}
+
+	if s.config.VisibilityTimeout > 0 {
+		input.VisibilityTimeout = int32(s.config.VisibilityTimeout.Seconds())
+	}
+
+	next := 0
+	for ctx.Err() == nil {
+		output, err := s.client.ReceiveMessage(ctx, input)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return errors.Wrapf(err, "failed to receive the messages of (%s)", s.config.QueueURL)
+		}
+
+		for _, msg := range output.Messages {
+			worker := next % len(workers)
+			if key := fromSQSMessage(msg).Key; key != "" {
+				hash := fnv.New32a()
+				_, _ = hash.Write([]byte(key))
+				worker = int(hash.Sum32() % uint32(len(workers)))
+			} else {
+				next++
+			}
+			workers[worker] <- msg
+		}
+	}
+	return nil
+}
+
+// handle runs the handler and deletes the message when it succeeds
+func (s *subscriber) handle(ctx context.Context, handler SubscriptionHandler, msg sqstypes.Message) {
+	// Create a span
+	tracer := otel.GetTracerProvider()
+	spanCtx, span := tracer.Tracer(instrumentationName).Start(ctx, "Handle")
+	defer span.End()
+
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("handler panicked: %v", r)
+			}
+		}()
+		return handler(spanCtx, fromSQSMessage(msg))
+	}()
+
+	if err != nil {
+		span.RecordError(err)
+		return
+	}
+
+	if _, err := s.client.DeleteMessage(spanCtx, &sqs.DeleteMessageInput{
+		QueueUrl:      aws.String(s.config.QueueURL),
+		ReceiptHandle: msg.ReceiptHandle,
+	}); err != nil {
+		span.RecordError(err)
+	}
+}
@@ -0,0 +1,211 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/pkg/errors"
+)
+
+// SNSAdmin is the subset of the SNS client used by the Tooling
+type SNSAdmin interface {
+	CreateTopic(ctx context.Context, params *sns.CreateTopicInput, optFns ...func(*sns.Options)) (*sns.CreateTopicOutput, error)
+	Subscribe(ctx context.Context, params *sns.SubscribeInput, optFns ...func(*sns.Options)) (*sns.SubscribeOutput, error)
+}
+
+// SQSAdmin is the subset of the SQS client used by the Tooling
+type SQSAdmin interface {
+	CreateQueue(ctx context.Context, params *sqs.CreateQueueInput, optFns ...func(*sqs.Options)) (*sqs.CreateQueueOutput, error)
+	GetQueueAttributes(ctx context.Context, params *sqs.GetQueueAttributesInput, optFns ...func(*sqs.Options)) (*sqs.GetQueueAttributesOutput, error)
+	SetQueueAttributes(ctx context.Context, params *sqs.SetQueueAttributesInput, optFns ...func(*sqs.Options)) (*sqs.SetQueueAttributesOutput, error)
+}
+
+// QueueConfig defines the settings of a queue created by the Tooling
+type QueueConfig struct {
+	VisibilityTimeout         time.Duration // VisibilityTimeout is the duration a received message is hidden from the other consumers. The SQS default is 30 seconds.
+	DeadLetterQueueARN        string        // DeadLetterQueueARN is the ARN of the queue receiving the messages failing MaxReceiveCount times
+	MaxReceiveCount           int           // MaxReceiveCount is the number of receives before a message is moved to the dead-letter queue. It is required with DeadLetterQueueARN.
+	ContentBasedDeduplication bool          // ContentBasedDeduplication deduplicates the messages of a FIFO queue sent without ID using their payload
+}
+
+// Tooling helps create the SNS topics and SQS queues and wire them together
+type Tooling struct {
+	sns SNSAdmin
+	sqs SQSAdmin
+}
+
+// NewTooling creates an instance of Tooling
+func NewTooling(snsClient SNSAdmin, sqsClient SQSAdmin) *Tooling {
+	return &Tooling{
+		sns: snsClient,
+		sqs: sqsClient,
+	}
+}
+
+// CreateTopic creates the topic and returns its ARN. A FIFO topic is created when the name ends with .fifo.
+// Creating an existing topic with the same settings returns its ARN.
+func (t *Tooling) CreateTopic(ctx context.Context, name string) (string, error) {
+	input := &sns.CreateTopicInput{Name: aws.String(name)}
+	if isFIFO(name) {
+		input.Attributes = map[string]string{"FifoTopic": "true"}
+	}
+
+	output, err := t.sns.CreateTopic(ctx, input)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to create the topic (%s)", name)
+	}
+	return aws.ToString(output.TopicArn), nil
+}
+
+// CreateQueue creates the queue and returns its URL and ARN. A FIFO queue is created when the name ends with .fifo.
+// Creating an existing queue with the same settings returns its URL and ARN.
+func (t *Tooling) CreateQueue(ctx context.Context, name string, config *QueueConfig) (string, string, error) {
+	attributes, err := queueAttributes(name, config)
+	if err != nil {
+		return "", "", err
+	}
+
+	output, err := t.sqs.CreateQueue(ctx, &sqs.CreateQueueInput{
+		QueueName:  aws.String(name),
+		Attributes: attributes,
+	})
+	if err != nil {
+		return "", "", errors.Wrapf(err, "failed to create the queue (%s)", name)
+	}
+
+	queueURL := aws.ToString(output.QueueUrl)
+	queueARN, err := t.queueARN(ctx, queueURL)
+	if err != nil {
+		return "", "", err
+	}
+	return queueURL, queueARN, nil
+}
+
+// Subscribe subscribes the queue to the topic with raw message delivery and returns the subscription ARN.
+// The queue policy is replaced by a policy allowing the topic to send messages to the queue.
+func (t *Tooling) Subscribe(ctx context.Context, topicARN, queueURL string) (string, error) {
+	queueARN, err := t.queueARN(ctx, queueURL)
+	if err != nil {
+		return "", err
+	}
+
+	policy, err := json.Marshal(map[string]any{
+		"Version": "2012-10-17",
+		"Statement": []map[string]any{{
+			"Effect":    "Allow",
+			"Principal": map[string]string{"Service": "sns.amazonaws.com"},
+			"Action":    "sqs:SendMessage",
+			"Resource":  queueARN,
+			"Condition": map[string]any{"ArnEquals": map[string]string{"aws:SourceArn": topicARN}},
+		}},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := t.sqs.SetQueueAttributes(ctx, &sqs.SetQueueAttributesInput{
+		QueueUrl:   aws.String(queueURL),
+		Attributes: map[string]string{string(sqstypes.QueueAttributeNamePolicy): string(policy)},
+	}); err != nil {
+		return "", errors.Wrapf(err, "failed to set the policy of the queue (%s)", queueURL)
+	}
+
+	output, err := t.sns.Subscribe(ctx, &sns.SubscribeInput{
+		TopicArn:              aws.String(topicARN),
+		Protocol:              aws.String("sqs"),
+		Endpoint:              aws.String(queueARN),
+		Attributes:            map[string]string{"RawMessageDelivery": "true"},
+		ReturnSubscriptionArn: true,
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to subscribe the queue (%s) to the topic (%s)", queueURL, topicARN)
+	}
+	return aws.ToString(output.SubscriptionArn), nil
+}
+
+// queueARN fetches the ARN of the queue
+func (t *Tooling) queueARN(ctx context.Context, queueURL string) (string, error) {
+	output, err := t.sqs.GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
+		QueueUrl:       aws.String(queueURL),
+		AttributeNames: []sqstypes.QueueAttributeName{sqstypes.QueueAttributeNameQueueArn},
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to fetch the ARN of the queue (%s)", queueURL)
+	}
+	return output.Attributes[string(sqstypes.QueueAttributeNameQueueArn)], nil
+}
+
+// queueAttributes builds the attributes of the queue to create
+func queueAttributes(name string, config *QueueConfig) (map[string]string, error) {
+	attributes := make(map[string]string)
+	if isFIFO(name) {
+		attributes[string(sqstypes.QueueAttributeNameFifoQueue)] = "true"
+	}
+
+	if config == nil {
+		return attributes, nil
+	}
+
+	if config.ContentBasedDeduplication {
+		if !isFIFO(name) {
+			return nil, errors.Errorf("content based deduplication requires a FIFO queue (%s)", name)
+		}
+		attributes[string(sqstypes.QueueAttributeNameContentBasedDeduplication)] = "true"
+	}
+
+	if config.VisibilityTimeout > 0 {
+		attributes[string(sqstypes.QueueAttributeNameVisibilityTimeout)] = strconv.Itoa(int(config.VisibilityTimeout.Seconds()))
+	}
+
+	if config.DeadLetterQueueARN != "" {
+		if config.MaxReceiveCount <= 0 {
+			return nil, errors.New("the max receive count is required with a dead-letter queue")
+		}
+
+		// the dead-letter queue of a FIFO queue must be a FIFO queue
+		if isFIFO(name) != strings.HasSuffix(config.DeadLetterQueueARN, fifoSuffix) {
+			return nil, errors.Errorf("the queue (%s) and its dead-letter queue must both be standard or FIFO queues", name)
+		}
+
+		policy, err := json.Marshal(map[string]string{
+			"deadLetterTargetArn": config.DeadLetterQueueARN,
+			"maxReceiveCount":     strconv.Itoa(config.MaxReceiveCount),
+		})
+		if err != nil {
+			return nil, err
+		}
+		attributes[string(sqstypes.QueueAttributeNameRedrivePolicy)] = string(policy)
+	}
+	return attributes, nil
+}
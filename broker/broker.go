@@ -0,0 +1,59 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+// Package broker defines the messaging abstractions implemented by the messaging backends
+// such as the nats and aws/messaging packages, so that the application code does not depend
+// on a given backend. The MemoryBroker implements them in memory for unit tests.
+package broker
+
+import "context"
+
+// Message is the envelope of the messages exchanged through a broker
+type Message struct {
+	// ID is the optional message identifier. The backends supporting it use it to drop the duplicates.
+	ID string
+	// Key is the optional ordering key. The messages sharing a key are handled sequentially.
+	Key string
+	// Payload is the message content
+	Payload []byte
+	// Attributes are the optional message metadata
+	Attributes map[string]string
+}
+
+// SubscriptionHandler handles the messages received by a Subscriber.
+// The message is acknowledged when the handler returns nil and redelivered otherwise.
+type SubscriptionHandler func(ctx context.Context, message *Message) error
+
+// Publisher publishes messages to a topic. The topic is the backend destination
+// such as a NATS subject, a SNS topic ARN or a SQS queue URL.
+type Publisher interface {
+	// Publish publishes the messages in the given order to the topic
+	Publish(ctx context.Context, topic string, messages ...*Message) error
+}
+
+// Subscriber consumes the messages of a subscription
+type Subscriber interface {
+	// Consume handles the messages with the given handler until the context is done
+	Consume(ctx context.Context, handler SubscriptionHandler) error
+}
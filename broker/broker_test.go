@@ -0,0 +1,130 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package broker
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// consume runs the subscriber in the background and returns the function stopping it
+func consume(t *testing.T, subscriber Subscriber, handler SubscriptionHandler) func() {
+	ctx, cancel := context.WithCancel(context.TODO())
+	done := make(chan error, 1)
+	go func() {
+		done <- subscriber.Consume(ctx, handler)
+	}()
+	return func() {
+		cancel()
+		require.NoError(t, <-done)
+	}
+}
+
+func TestMemoryBroker(t *testing.T) {
+	ctx := context.TODO()
+
+	t.Run("with subscriptions", func(t *testing.T) {
+		broker := NewMemoryBroker()
+		// the messages published before the subscription are not delivered
+		require.NoError(t, broker.Publish(ctx, "orders", &Message{ID: "order-0"}))
+
+		first := broker.Subscribe("orders")
+		second := broker.Subscribe("orders")
+		other := broker.Subscribe("payments")
+		require.NoError(t, broker.Publish(ctx, "orders", &Message{ID: "order-1"}, &Message{ID: "order-2"}))
+
+		mu := sync.Mutex{}
+		received := make(map[string][]string)
+		handler := func(name string) SubscriptionHandler {
+			return func(_ context.Context, message *Message) error {
+				mu.Lock()
+				defer mu.Unlock()
+				received[name] = append(received[name], message.ID)
+				return nil
+			}
+		}
+
+		stopFirst := consume(t, first, handler("first"))
+		stopSecond := consume(t, second, handler("second"))
+		stopOther := consume(t, other, handler("other"))
+		assert.Eventually(t, func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(received["first"]) == 2 && len(received["second"]) == 2
+		}, time.Second, 10*time.Millisecond)
+		stopFirst()
+		stopSecond()
+		stopOther()
+
+		assert.Equal(t, []string{"order-1", "order-2"}, received["first"])
+		assert.Equal(t, []string{"order-1", "order-2"}, received["second"])
+		assert.Empty(t, received["other"])
+		assert.Len(t, broker.Published("orders"), 3)
+	})
+
+	t.Run("with failed messages", func(t *testing.T) {
+		broker := NewMemoryBroker(WithMaxDeliveries(2))
+		subscriber := broker.Subscribe("orders")
+		require.NoError(t, broker.Publish(ctx, "orders", &Message{ID: "order-1"}, &Message{ID: "order-2"}))
+
+		mu := sync.Mutex{}
+		var deliveries []string
+		stop := consume(t, subscriber, func(_ context.Context, message *Message) error {
+			mu.Lock()
+			defer mu.Unlock()
+			deliveries = append(deliveries, message.ID)
+			switch message.ID {
+			case "order-1":
+				return errors.New("unavailable")
+			case "order-2":
+				if len(deliveries) == 2 {
+					panic("boom")
+				}
+			}
+			return nil
+		})
+		assert.Eventually(t, func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(deliveries) == 4
+		}, time.Second, 10*time.Millisecond)
+		stop()
+
+		// the failed messages are redelivered after the pending ones and dropped after two deliveries
+		assert.Equal(t, []string{"order-1", "order-2", "order-1", "order-2"}, deliveries)
+	})
+
+	t.Run("with done context", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		assert.ErrorIs(t, NewMemoryBroker().Publish(cancelled, "orders", &Message{}), context.Canceled)
+	})
+}
@@ -0,0 +1,171 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package broker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// MemoryBroker is an in-memory broker for unit tests. The messages published to a topic are
+// delivered to every subscription created on that topic with Subscribe.
+type MemoryBroker struct {
+	mu            sync.Mutex
+	subscriptions map[string][]*memorySubscription
+	published     map[string][]*Message
+	maxDeliveries int
+}
+
+// enforce compilation error
+var _ Publisher = (*MemoryBroker)(nil)
+
+// NewMemoryBroker creates an instance of MemoryBroker
+func NewMemoryBroker(opts ...Option) *MemoryBroker {
+	broker := &MemoryBroker{
+		subscriptions: make(map[string][]*memorySubscription),
+		published:     make(map[string][]*Message),
+		maxDeliveries: DefaultMaxDeliveries,
+	}
+
+	// apply the options
+	for _, opt := range opts {
+		opt.Apply(broker)
+	}
+	return broker
+}
+
+// Publish delivers the messages to the subscriptions of the topic
+func (b *MemoryBroker) Publish(ctx context.Context, topic string, messages ...*Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, message := range messages {
+		b.published[topic] = append(b.published[topic], message)
+		for _, subscription := range b.subscriptions[topic] {
+			// each subscription gets its own copy
+			clone := *message
+			subscription.push(&delivery{message: &clone})
+		}
+	}
+	return nil
+}
+
+// Subscribe creates a subscription to the topic and returns its Subscriber. The subscription receives
+// the messages published from now on. The concurrent consumers of a Subscriber share its messages.
+func (b *MemoryBroker) Subscribe(topic string) Subscriber {
+	subscription := &memorySubscription{
+		notify:        make(chan struct{}, 1),
+		maxDeliveries: b.maxDeliveries,
+	}
+
+	b.mu.Lock()
+	b.subscriptions[topic] = append(b.subscriptions[topic], subscription)
+	b.mu.Unlock()
+	return subscription
+}
+
+// Published returns the messages published to the topic in the order they were published
+func (b *MemoryBroker) Published(topic string) []*Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]*Message(nil), b.published[topic]...)
+}
+
+// delivery is a message pending in a subscription
+type delivery struct {
+	message    *Message
+	deliveries int
+}
+
+// memorySubscription implements Subscriber
+type memorySubscription struct {
+	mu            sync.Mutex
+	queue         []*delivery
+	notify        chan struct{}
+	maxDeliveries int
+}
+
+// enforce compilation error
+var _ Subscriber = (*memorySubscription)(nil)
+
+// Consume handles the messages of the subscription until the context is done. A failed message is
+// redelivered after the pending messages and dropped after the maximum number of deliveries.
+func (s *memorySubscription) Consume(ctx context.Context, handler SubscriptionHandler) error {
+	for {
+		next, ok := s.pop()
+		if !ok {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-s.notify:
+				continue
+			}
+		}
+
+		next.deliveries++
+		if err := handle(ctx, handler, next.message); err != nil && next.deliveries < s.maxDeliveries {
+			s.push(next)
+		}
+	}
+}
+
+// push queues the delivery and wakes up a waiting consumer
+func (s *memorySubscription) push(next *delivery) {
+	s.mu.Lock()
+	s.queue = append(s.queue, next)
+	s.mu.Unlock()
+
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+// pop dequeues the next delivery
+func (s *memorySubscription) pop() (*delivery, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.queue) == 0 {
+		return nil, false
+	}
+
+	next := s.queue[0]
+	s.queue = s.queue[1:]
+	return next, true
+}
+
+// handle runs the handler and turns its panic into an error
+func handle(ctx context.Context, handler SubscriptionHandler, message *Message) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panicked: %v", r)
+		}
+	}()
+	return handler(ctx, message)
+}
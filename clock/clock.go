@@ -0,0 +1,137 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+// Package clock abstracts the time functions used across gopack so that
+// time-dependent code can be tested with a controllable Fake clock
+// instead of relying on real sleeps.
+package clock
+
+import "time"
+
+// Clock defines the time functions used by time-dependent components
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+	// Since returns the time elapsed since t
+	Since(t time.Time) time.Duration
+	// After waits for the duration to elapse and then sends the current time on the returned channel
+	After(d time.Duration) <-chan time.Time
+	// Sleep pauses the current go-routine for at least the duration d
+	Sleep(d time.Duration)
+	// NewTimer creates a new Timer that will send the current time on its channel after at least duration d
+	NewTimer(d time.Duration) Timer
+	// NewTicker returns a new Ticker that sends the current time on its channel every period d
+	NewTicker(d time.Duration) Ticker
+	// AfterFunc waits for the duration to elapse and then calls f in its own go-routine
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer represents a single event
+type Timer interface {
+	// C returns the channel on which the time is delivered.
+	// The channel is nil for timers created with AfterFunc
+	C() <-chan time.Time
+	// Stop prevents the Timer from firing.
+	// It returns true if the call stops the timer, false if the timer has already expired or been stopped
+	Stop() bool
+	// Reset changes the timer to expire after duration d.
+	// It returns true if the timer had been active, false if the timer had expired or been stopped
+	Reset(d time.Duration) bool
+}
+
+// Ticker holds a channel that delivers ticks of a clock at intervals
+type Ticker interface {
+	// C returns the channel on which the ticks are delivered
+	C() <-chan time.Time
+	// Stop turns off the ticker
+	Stop()
+	// Reset stops the ticker and resets its period to the specified duration
+	Reset(d time.Duration)
+}
+
+// realClock implements Clock using the standard time package
+type realClock struct{}
+
+// enforce compilation error
+var _ Clock = realClock{}
+
+// New returns a Clock backed by the standard time package
+func New() Clock {
+	return realClock{}
+}
+
+// Now returns the current time
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// Since returns the time elapsed since t
+func (realClock) Since(t time.Time) time.Duration {
+	return time.Since(t)
+}
+
+// After waits for the duration to elapse and then sends the current time on the returned channel
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// Sleep pauses the current go-routine for at least the duration d
+func (realClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+// NewTimer creates a new Timer
+func (realClock) NewTimer(d time.Duration) Timer {
+	return &realTimer{time.NewTimer(d)}
+}
+
+// NewTicker creates a new Ticker
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return &realTicker{time.NewTicker(d)}
+}
+
+// AfterFunc calls f after the duration d
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return &realTimer{time.AfterFunc(d, f)}
+}
+
+// realTimer wraps a time.Timer
+type realTimer struct {
+	*time.Timer
+}
+
+// C returns the timer channel
+func (t *realTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+// realTicker wraps a time.Ticker
+type realTicker struct {
+	*time.Ticker
+}
+
+// C returns the ticker channel
+func (t *realTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
@@ -0,0 +1,137 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRealClock(t *testing.T) {
+	clock := New()
+	start := clock.Now()
+	<-clock.After(10 * time.Millisecond)
+	assert.GreaterOrEqual(t, clock.Since(start), 10*time.Millisecond)
+
+	timer := clock.NewTimer(time.Hour)
+	assert.True(t, timer.Stop())
+
+	fired := make(chan struct{})
+	clock.AfterFunc(time.Millisecond, func() { close(fired) })
+	select {
+	case <-fired:
+	case <-time.After(time.Second):
+		t.Fatal("expected AfterFunc to fire")
+	}
+}
+
+func TestFake(t *testing.T) {
+	epoch := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("with now and advance", func(t *testing.T) {
+		clock := NewFake(epoch)
+		assert.Equal(t, epoch, clock.Now())
+		clock.Advance(time.Minute)
+		assert.Equal(t, epoch.Add(time.Minute), clock.Now())
+		assert.Equal(t, time.Minute, clock.Since(epoch))
+	})
+	t.Run("with timer", func(t *testing.T) {
+		clock := NewFake(epoch)
+		timer := clock.NewTimer(time.Second)
+		clock.Advance(500 * time.Millisecond)
+		select {
+		case <-timer.C():
+			t.Fatal("timer fired too early")
+		default:
+		}
+
+		clock.Advance(500 * time.Millisecond)
+		select {
+		case fired := <-timer.C():
+			assert.Equal(t, epoch.Add(time.Second), fired)
+		default:
+			t.Fatal("expected timer to fire")
+		}
+		assert.False(t, timer.Stop())
+	})
+	t.Run("with stopped and reset timer", func(t *testing.T) {
+		clock := NewFake(epoch)
+		timer := clock.NewTimer(time.Second)
+		assert.True(t, timer.Stop())
+		assert.Zero(t, clock.Waiters())
+
+		assert.False(t, timer.Reset(2*time.Second))
+		clock.Advance(2 * time.Second)
+		select {
+		case <-timer.C():
+		default:
+			t.Fatal("expected timer to fire")
+		}
+	})
+	t.Run("with ticker", func(t *testing.T) {
+		clock := NewFake(epoch)
+		ticker := clock.NewTicker(time.Second)
+		defer ticker.Stop()
+		for i := 1; i <= 3; i++ {
+			clock.Advance(time.Second)
+			tick := <-ticker.C()
+			assert.Equal(t, epoch.Add(time.Duration(i)*time.Second), tick)
+		}
+	})
+	t.Run("with after func", func(t *testing.T) {
+		clock := NewFake(epoch)
+		fired := make(chan struct{})
+		clock.AfterFunc(time.Hour, func() { close(fired) })
+		clock.Advance(time.Hour)
+		select {
+		case <-fired:
+		case <-time.After(time.Second):
+			t.Fatal("expected AfterFunc to fire")
+		}
+	})
+	t.Run("with sleep and block until", func(t *testing.T) {
+		clock := NewFake(epoch)
+		done := make(chan struct{})
+		go func() {
+			clock.Sleep(time.Minute)
+			close(done)
+		}()
+		clock.BlockUntil(1)
+		clock.Advance(time.Minute)
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("expected sleep to return")
+		}
+	})
+	t.Run("with set in the past", func(t *testing.T) {
+		clock := NewFake(epoch)
+		clock.Set(epoch.Add(-time.Hour))
+		require.Equal(t, epoch, clock.Now())
+	})
+}
@@ -0,0 +1,270 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Fake is a Clock whose time only moves when Advance or Set is called.
+// Timers, tickers and sleeps registered on the Fake fire synchronously
+// as the time is moved forward.
+type Fake struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []*fakeWaiter
+}
+
+// enforce compilation error
+var _ Clock = (*Fake)(nil)
+
+// NewFake creates a Fake clock set at the given time
+func NewFake(now time.Time) *Fake {
+	f := &Fake{now: now}
+	f.cond = sync.NewCond(&f.mu)
+	return f
+}
+
+// Now returns the fake current time
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Since returns the fake time elapsed since t
+func (f *Fake) Since(t time.Time) time.Duration {
+	return f.Now().Sub(t)
+}
+
+// After returns a channel that receives the fake time once it has been advanced by d
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	return f.NewTimer(d).C()
+}
+
+// Sleep blocks until the fake time has been advanced by d
+func (f *Fake) Sleep(d time.Duration) {
+	<-f.After(d)
+}
+
+// NewTimer creates a Timer that fires once the fake time has been advanced by d
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	w := &fakeWaiter{clock: f, ch: make(chan time.Time, 1)}
+	f.schedule(w, d)
+	return &fakeTimer{w}
+}
+
+// AfterFunc calls fn in its own go-routine once the fake time has been advanced by d
+func (f *Fake) AfterFunc(d time.Duration, fn func()) Timer {
+	w := &fakeWaiter{clock: f, fn: fn}
+	f.schedule(w, d)
+	return &fakeTimer{w}
+}
+
+// NewTicker creates a Ticker that ticks every time the fake time is advanced by d
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+	w := &fakeWaiter{clock: f, ch: make(chan time.Time, 1), period: d}
+	f.schedule(w, d)
+	return &fakeTicker{w}
+}
+
+// Advance moves the fake time forward by d and fires every timer and ticker due in the meantime
+func (f *Fake) Advance(d time.Duration) {
+	f.Set(f.Now().Add(d))
+}
+
+// Set moves the fake time to t and fires every timer and ticker due in the meantime.
+// Setting a time in the past does not fire anything.
+func (f *Fake) Set(t time.Time) {
+	for {
+		f.mu.Lock()
+		if len(f.waiters) == 0 || f.waiters[0].deadline.After(t) {
+			if t.After(f.now) {
+				f.now = t
+			}
+			f.mu.Unlock()
+			return
+		}
+
+		w := f.waiters[0]
+		f.waiters = f.waiters[1:]
+		if w.deadline.After(f.now) {
+			f.now = w.deadline
+		}
+		now := f.now
+		if w.period > 0 {
+			w.deadline = w.deadline.Add(w.period)
+			f.insert(w)
+		} else {
+			w.active = false
+		}
+		f.mu.Unlock()
+
+		w.fire(now)
+	}
+}
+
+// Waiters returns the number of pending timers, tickers and sleeps
+func (f *Fake) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.waiters)
+}
+
+// BlockUntil blocks until at least n timers, tickers or sleeps are pending on the Fake.
+// This helps synchronize a test with the go-routines under test before advancing the time.
+func (f *Fake) BlockUntil(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for len(f.waiters) < n {
+		f.cond.Wait()
+	}
+}
+
+// schedule registers the waiter to fire after d
+func (f *Fake) schedule(w *fakeWaiter, d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.deadline = f.now.Add(d)
+	w.active = true
+	f.insert(w)
+}
+
+// insert adds the waiter to the sorted list of waiters. The lock must be held
+func (f *Fake) insert(w *fakeWaiter) {
+	i := sort.Search(len(f.waiters), func(i int) bool {
+		return f.waiters[i].deadline.After(w.deadline)
+	})
+	f.waiters = append(f.waiters, nil)
+	copy(f.waiters[i+1:], f.waiters[i:])
+	f.waiters[i] = w
+	f.cond.Broadcast()
+}
+
+// remove removes the waiter from the list of waiters. The lock must be held
+func (f *Fake) remove(w *fakeWaiter) bool {
+	for i, waiter := range f.waiters {
+		if waiter == w {
+			f.waiters = append(f.waiters[:i], f.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// fakeWaiter is a pending timer or ticker
+type fakeWaiter struct {
+	clock    *Fake
+	deadline time.Time
+	period   time.Duration
+	ch       chan time.Time
+	fn       func()
+	active   bool
+}
+
+// fire delivers the tick
+func (w *fakeWaiter) fire(now time.Time) {
+	if w.fn != nil {
+		go w.fn()
+		return
+	}
+	// like the standard library drop the tick when the receiver is too slow
+	select {
+	case w.ch <- now:
+	default:
+	}
+}
+
+// stop deactivates the waiter
+func (w *fakeWaiter) stop() bool {
+	w.clock.mu.Lock()
+	defer w.clock.mu.Unlock()
+	wasActive := w.active
+	w.active = false
+	w.clock.remove(w)
+	return wasActive
+}
+
+// reset re-schedules the waiter
+func (w *fakeWaiter) reset(d time.Duration) bool {
+	f := w.clock
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	wasActive := w.active
+	f.remove(w)
+	if w.period > 0 {
+		w.period = d
+	}
+	w.deadline = f.now.Add(d)
+	w.active = true
+	f.insert(w)
+	return wasActive
+}
+
+// fakeTimer implements Timer
+type fakeTimer struct {
+	*fakeWaiter
+}
+
+// C returns the timer channel
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.ch
+}
+
+// Stop prevents the timer from firing
+func (t *fakeTimer) Stop() bool {
+	return t.stop()
+}
+
+// Reset changes the timer to expire after duration d
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	return t.reset(d)
+}
+
+// fakeTicker implements Ticker
+type fakeTicker struct {
+	*fakeWaiter
+}
+
+// C returns the ticker channel
+func (t *fakeTicker) C() <-chan time.Time {
+	return t.ch
+}
+
+// Stop turns off the ticker
+func (t *fakeTicker) Stop() {
+	t.stop()
+}
+
+// Reset stops the ticker and resets its period to d
+func (t *fakeTicker) Reset(d time.Duration) {
+	t.reset(d)
+}
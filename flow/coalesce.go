@@ -0,0 +1,111 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package flow
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/tochemey/gopack/clock"
+)
+
+// Coalescer merges the values triggered within a window into a single value
+// that is delivered at the end of the window. The window starts with the first
+// trigger following a delivery.
+type Coalescer[T any] struct {
+	mu      sync.Mutex
+	window  time.Duration
+	merge   func(acc, value T) T
+	fn      func(context.Context, T)
+	ctx     context.Context
+	clock   clock.Clock
+	timer   clock.Timer
+	pending bool
+	acc     T
+	stopped bool
+}
+
+// NewCoalescer creates an instance of Coalescer. The merge function folds every
+// triggered value into the accumulated one and fn is called with the result at the end of the window.
+// Pending invocations are dropped when the given context is done.
+func NewCoalescer[T any](ctx context.Context, window time.Duration, merge func(acc, value T) T, fn func(context.Context, T), opts ...Option) *Coalescer[T] {
+	c := &Coalescer[T]{
+		window: window,
+		merge:  merge,
+		fn:     fn,
+		ctx:    ctx,
+		clock:  newConfig(opts...).clock,
+	}
+
+	// drop pending invocations once the context is done
+	context.AfterFunc(ctx, c.Stop)
+	return c
+}
+
+// Trigger merges the value into the current window
+func (c *Coalescer[T]) Trigger(value T) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stopped {
+		return
+	}
+
+	if !c.pending {
+		c.acc = value
+		c.pending = true
+		c.timer = c.clock.AfterFunc(c.window, c.fire)
+		return
+	}
+	c.acc = c.merge(c.acc, value)
+}
+
+// Stop cancels any pending invocation. Subsequent triggers are ignored.
+func (c *Coalescer[T]) Stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stopped = true
+	c.pending = false
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+}
+
+// fire delivers the accumulated value
+func (c *Coalescer[T]) fire() {
+	c.mu.Lock()
+	if !c.pending || c.stopped {
+		c.mu.Unlock()
+		return
+	}
+	value := c.acc
+	var zero T
+	c.acc = zero
+	c.pending = false
+	c.mu.Unlock()
+
+	c.fn(c.ctx, value)
+}
@@ -0,0 +1,117 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+// Package flow provides generic helpers to smooth bursty event streams:
+// Debounce, Throttle and Coalesce.
+package flow
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/tochemey/gopack/clock"
+)
+
+// Debouncer delays the invocation of a function until a quiet period has elapsed
+// since the last trigger. Only the latest triggered value is delivered.
+type Debouncer[T any] struct {
+	mu      sync.Mutex
+	wait    time.Duration
+	fn      func(context.Context, T)
+	ctx     context.Context
+	clock   clock.Clock
+	timer   clock.Timer
+	pending bool
+	value   T
+	stopped bool
+}
+
+// NewDebouncer creates an instance of Debouncer that calls fn with the latest
+// triggered value once wait has elapsed without any new trigger.
+// Pending invocations are dropped when the given context is done.
+func NewDebouncer[T any](ctx context.Context, wait time.Duration, fn func(context.Context, T), opts ...Option) *Debouncer[T] {
+	d := &Debouncer[T]{
+		wait:  wait,
+		fn:    fn,
+		ctx:   ctx,
+		clock: newConfig(opts...).clock,
+	}
+
+	// drop pending invocations once the context is done
+	context.AfterFunc(ctx, d.Stop)
+	return d
+}
+
+// Trigger records the value and (re)starts the quiet period
+func (d *Debouncer[T]) Trigger(value T) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.stopped {
+		return
+	}
+
+	d.value = value
+	d.pending = true
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	d.timer = d.clock.AfterFunc(d.wait, d.fire)
+}
+
+// Flush immediately invokes the function with the pending value, if any
+func (d *Debouncer[T]) Flush() {
+	d.mu.Lock()
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	d.mu.Unlock()
+	d.fire()
+}
+
+// Stop cancels any pending invocation. Subsequent triggers are ignored.
+func (d *Debouncer[T]) Stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.stopped = true
+	d.pending = false
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+}
+
+// fire invokes the function with the pending value
+func (d *Debouncer[T]) fire() {
+	d.mu.Lock()
+	if !d.pending || d.stopped {
+		d.mu.Unlock()
+		return
+	}
+	value := d.value
+	d.pending = false
+	d.mu.Unlock()
+
+	d.fn(d.ctx, value)
+}
@@ -0,0 +1,152 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package flow
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tochemey/gopack/clock"
+)
+
+// recorder records the delivered values
+type recorder[T any] struct {
+	mu     sync.Mutex
+	values []T
+}
+
+func (r *recorder[T]) record(_ context.Context, value T) {
+	r.mu.Lock()
+	r.values = append(r.values, value)
+	r.mu.Unlock()
+}
+
+func (r *recorder[T]) get() []T {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]T, len(r.values))
+	copy(out, r.values)
+	return out
+}
+
+func TestDebouncer(t *testing.T) {
+	t.Run("with burst of triggers", func(t *testing.T) {
+		rec := new(recorder[int])
+		debouncer := NewDebouncer(context.Background(), 50*time.Millisecond, rec.record)
+		for i := 1; i <= 5; i++ {
+			debouncer.Trigger(i)
+		}
+		require.Eventually(t, func() bool { return len(rec.get()) == 1 }, time.Second, 10*time.Millisecond)
+		assert.Equal(t, []int{5}, rec.get())
+	})
+	t.Run("with flush", func(t *testing.T) {
+		rec := new(recorder[string])
+		debouncer := NewDebouncer(context.Background(), time.Minute, rec.record)
+		debouncer.Trigger("reload")
+		debouncer.Flush()
+		assert.Equal(t, []string{"reload"}, rec.get())
+		// nothing is pending anymore
+		debouncer.Flush()
+		assert.Len(t, rec.get(), 1)
+	})
+	t.Run("with fake clock", func(t *testing.T) {
+		fake := clock.NewFake(time.Now())
+		rec := new(recorder[int])
+		debouncer := NewDebouncer(context.Background(), time.Hour, rec.record, WithClock(fake))
+		debouncer.Trigger(1)
+		fake.Advance(30 * time.Minute)
+		debouncer.Trigger(2)
+		fake.Advance(30 * time.Minute)
+		assert.Empty(t, rec.get())
+		fake.Advance(30 * time.Minute)
+		require.Eventually(t, func() bool { return len(rec.get()) == 1 }, time.Second, time.Millisecond)
+		assert.Equal(t, []int{2}, rec.get())
+	})
+	t.Run("with context canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		rec := new(recorder[int])
+		debouncer := NewDebouncer(ctx, 50*time.Millisecond, rec.record)
+		debouncer.Trigger(1)
+		cancel()
+		time.Sleep(100 * time.Millisecond)
+		debouncer.Trigger(2)
+		assert.Empty(t, rec.get())
+	})
+}
+
+func TestThrottler(t *testing.T) {
+	t.Run("with leading and trailing invocations", func(t *testing.T) {
+		rec := new(recorder[int])
+		throttler := NewThrottler(context.Background(), 100*time.Millisecond, rec.record)
+		for i := 1; i <= 5; i++ {
+			throttler.Trigger(i)
+		}
+		// the leading value is delivered immediately
+		assert.Equal(t, []int{1}, rec.get())
+		// the latest value is delivered at the end of the interval
+		require.Eventually(t, func() bool { return len(rec.get()) == 2 }, time.Second, 10*time.Millisecond)
+		assert.Equal(t, []int{1, 5}, rec.get())
+	})
+	t.Run("with fake clock", func(t *testing.T) {
+		fake := clock.NewFake(time.Now())
+		rec := new(recorder[int])
+		throttler := NewThrottler(context.Background(), time.Minute, rec.record, WithClock(fake))
+		throttler.Trigger(1)
+		fake.Advance(time.Minute)
+		// the interval has elapsed hence the value is delivered immediately
+		throttler.Trigger(2)
+		assert.Equal(t, []int{1, 2}, rec.get())
+	})
+	t.Run("with stop", func(t *testing.T) {
+		rec := new(recorder[int])
+		throttler := NewThrottler(context.Background(), 50*time.Millisecond, rec.record)
+		throttler.Trigger(1)
+		throttler.Trigger(2)
+		throttler.Stop()
+		time.Sleep(100 * time.Millisecond)
+		assert.Equal(t, []int{1}, rec.get())
+	})
+}
+
+func TestCoalescer(t *testing.T) {
+	rec := new(recorder[[]string])
+	merge := func(acc, value []string) []string { return append(acc, value...) }
+	coalescer := NewCoalescer(context.Background(), 50*time.Millisecond, merge, rec.record)
+	coalescer.Trigger([]string{"a"})
+	coalescer.Trigger([]string{"b"})
+	coalescer.Trigger([]string{"c"})
+	require.Eventually(t, func() bool { return len(rec.get()) == 1 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, [][]string{{"a", "b", "c"}}, rec.get())
+
+	// a new window starts after the delivery
+	coalescer.Trigger([]string{"d"})
+	require.Eventually(t, func() bool { return len(rec.get()) == 2 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"d"}, rec.get()[1])
+}
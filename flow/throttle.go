@@ -0,0 +1,117 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package flow
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/tochemey/gopack/clock"
+)
+
+// Throttler limits the invocation of a function to at most once per interval.
+// The first trigger is delivered immediately. Triggers received during the interval
+// are collapsed and the latest value is delivered at the end of the interval.
+type Throttler[T any] struct {
+	mu       sync.Mutex
+	interval time.Duration
+	fn       func(context.Context, T)
+	ctx      context.Context
+	clock    clock.Clock
+	timer    clock.Timer
+	last     time.Time
+	pending  bool
+	value    T
+	stopped  bool
+}
+
+// NewThrottler creates an instance of Throttler that calls fn at most once per interval.
+// Pending invocations are dropped when the given context is done.
+func NewThrottler[T any](ctx context.Context, interval time.Duration, fn func(context.Context, T), opts ...Option) *Throttler[T] {
+	t := &Throttler[T]{
+		interval: interval,
+		fn:       fn,
+		ctx:      ctx,
+		clock:    newConfig(opts...).clock,
+	}
+
+	// drop pending invocations once the context is done
+	context.AfterFunc(ctx, t.Stop)
+	return t
+}
+
+// Trigger invokes the function immediately when the interval has elapsed since the last
+// invocation. Otherwise, the value is kept and delivered at the end of the interval.
+func (t *Throttler[T]) Trigger(value T) {
+	t.mu.Lock()
+	if t.stopped {
+		t.mu.Unlock()
+		return
+	}
+
+	elapsed := t.clock.Since(t.last)
+	if t.timer == nil && elapsed >= t.interval {
+		t.last = t.clock.Now()
+		t.mu.Unlock()
+		t.fn(t.ctx, value)
+		return
+	}
+
+	t.value = value
+	t.pending = true
+	if t.timer == nil {
+		t.timer = t.clock.AfterFunc(t.interval-elapsed, t.fire)
+	}
+	t.mu.Unlock()
+}
+
+// Stop cancels any pending invocation. Subsequent triggers are ignored.
+func (t *Throttler[T]) Stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stopped = true
+	t.pending = false
+	if t.timer != nil {
+		t.timer.Stop()
+		t.timer = nil
+	}
+}
+
+// fire delivers the trailing value at the end of the interval
+func (t *Throttler[T]) fire() {
+	t.mu.Lock()
+	t.timer = nil
+	if !t.pending || t.stopped {
+		t.mu.Unlock()
+		return
+	}
+	value := t.value
+	t.pending = false
+	t.last = t.clock.Now()
+	t.mu.Unlock()
+
+	t.fn(t.ctx, value)
+}
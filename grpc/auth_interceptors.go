@@ -0,0 +1,154 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package grpc
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// authorizationMetadataKey is the metadata key carrying the bearer token
+const authorizationMetadataKey = "authorization"
+
+// Claims are the claims of a validated token
+type Claims map[string]any
+
+// TokenValidator validates a bearer token and returns its claims
+type TokenValidator interface {
+	Validate(ctx context.Context, token string) (Claims, error)
+}
+
+// claimsKey is the context key of the token claims
+type claimsKey struct{}
+
+// ContextWithClaims returns a copy of the context carrying the given claims
+func ContextWithClaims(ctx context.Context, claims Claims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// ClaimsFromContext returns the claims injected in the context by the auth interceptors
+func ClaimsFromContext(ctx context.Context) (Claims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(Claims)
+	return claims, ok
+}
+
+// authConfig holds the auth interceptors settings
+type authConfig struct {
+	skipMethods []string
+}
+
+// AuthOption configures the auth interceptors
+type AuthOption func(*authConfig)
+
+// WithAuthSkipMethods sets the full methods that do not require authentication, e.g. the health checks
+func WithAuthSkipMethods(methods ...string) AuthOption {
+	return func(c *authConfig) {
+		c.skipMethods = append(c.skipMethods, methods...)
+	}
+}
+
+// NewAuthUnaryServerInterceptor returns a unary server interceptor that validates the bearer token
+// of the incoming metadata and injects its claims into the context. See ClaimsFromContext.
+// Requests with a missing or invalid token are rejected with codes.Unauthenticated.
+func NewAuthUnaryServerInterceptor(validator TokenValidator, opts ...AuthOption) grpc.UnaryServerInterceptor {
+	config := newAuthConfig(opts...)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if slices.Contains(config.skipMethods, info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		ctx, err := authenticate(ctx, validator)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// NewAuthStreamServerInterceptor returns a stream server interceptor that validates the bearer token
+// of the incoming metadata and injects its claims into the stream context. See ClaimsFromContext.
+// Requests with a missing or invalid token are rejected with codes.Unauthenticated.
+func NewAuthStreamServerInterceptor(validator TokenValidator, opts ...AuthOption) grpc.StreamServerInterceptor {
+	config := newAuthConfig(opts...)
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if slices.Contains(config.skipMethods, info.FullMethod) {
+			return handler(srv, ss)
+		}
+
+		ctx, err := authenticate(ss.Context(), validator)
+		if err != nil {
+			return err
+		}
+		return handler(srv, newServerStreamWithContext(ctx, ss))
+	}
+}
+
+// newAuthConfig creates the auth config and applies the given options
+func newAuthConfig(opts ...AuthOption) *authConfig {
+	config := new(authConfig)
+	for _, opt := range opts {
+		opt(config)
+	}
+	return config
+}
+
+// authenticate validates the bearer token and returns the context carrying its claims
+func authenticate(ctx context.Context, validator TokenValidator) (context.Context, error) {
+	token, err := bearerToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := validator.Validate(ctx, token)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid token: %v", err)
+	}
+	return ContextWithClaims(ctx, claims), nil
+}
+
+// bearerToken returns the bearer token of the incoming metadata
+func bearerToken(ctx context.Context) (string, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", status.Error(codes.Unauthenticated, "missing metadata")
+	}
+
+	values := md.Get(authorizationMetadataKey)
+	if len(values) == 0 {
+		return "", status.Error(codes.Unauthenticated, "missing authorization token")
+	}
+
+	scheme, token, found := strings.Cut(values[0], " ")
+	if !found || !strings.EqualFold(scheme, "bearer") || strings.TrimSpace(token) == "" {
+		return "", status.Error(codes.Unauthenticated, "invalid authorization scheme")
+	}
+	return strings.TrimSpace(token), nil
+}
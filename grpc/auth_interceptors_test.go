@@ -0,0 +1,287 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package grpc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/tochemey/gopack/clock"
+)
+
+type mockValidator struct{}
+
+func (mockValidator) Validate(_ context.Context, token string) (Claims, error) {
+	if token != "valid" {
+		return nil, errors.New("invalid")
+	}
+	return Claims{"sub": "user"}, nil
+}
+
+// withAuthorization returns an incoming context carrying the given authorization header
+func withAuthorization(value string) context.Context {
+	return metadata.NewIncomingContext(context.TODO(), metadata.Pairs(authorizationMetadataKey, value))
+}
+
+func TestNewAuthUnaryServerInterceptor(t *testing.T) {
+	interceptor := NewAuthUnaryServerInterceptor(mockValidator{}, WithAuthSkipMethods("/grpc.health.v1.Health/Check"))
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.v1.Greeter/SayHello"}
+	handler := func(ctx context.Context, _ any) (any, error) {
+		claims, ok := ClaimsFromContext(ctx)
+		if !ok {
+			return nil, errors.New("missing claims")
+		}
+		return claims["sub"], nil
+	}
+
+	t.Run("with valid token", func(t *testing.T) {
+		resp, err := interceptor(withAuthorization("Bearer valid"), nil, info, handler)
+		require.NoError(t, err)
+		assert.Equal(t, "user", resp)
+	})
+	t.Run("with invalid token", func(t *testing.T) {
+		_, err := interceptor(withAuthorization("Bearer invalid"), nil, info, handler)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+	t.Run("with invalid scheme", func(t *testing.T) {
+		_, err := interceptor(withAuthorization("Basic valid"), nil, info, handler)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+	t.Run("without token", func(t *testing.T) {
+		_, err := interceptor(context.TODO(), nil, info, handler)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+	t.Run("with skipped method", func(t *testing.T) {
+		resp, err := interceptor(context.TODO(), nil, &grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"},
+			func(context.Context, any) (any, error) { return "ok", nil })
+		require.NoError(t, err)
+		assert.Equal(t, "ok", resp)
+	})
+}
+
+func TestNewAuthStreamServerInterceptor(t *testing.T) {
+	interceptor := NewAuthStreamServerInterceptor(mockValidator{})
+	info := &grpc.StreamServerInfo{FullMethod: "/test.v1.Greeter/SayHello"}
+
+	t.Run("with valid token", func(t *testing.T) {
+		stream := &testServerStream{ctx: withAuthorization("bearer valid")}
+		err := interceptor(nil, stream, info, func(_ any, stream grpc.ServerStream) error {
+			claims, ok := ClaimsFromContext(stream.Context())
+			require.True(t, ok)
+			assert.Equal(t, "user", claims["sub"])
+			return nil
+		})
+		assert.NoError(t, err)
+	})
+	t.Run("with invalid token", func(t *testing.T) {
+		stream := &testServerStream{ctx: withAuthorization("Bearer invalid")}
+		err := interceptor(nil, stream, info, func(any, grpc.ServerStream) error { return nil })
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+func TestJWTValidator(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	var fetches atomic.Int32
+	var failing atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fetches.Add(1)
+		if failing.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]string{
+				{"kty": "RSA", "kid": "rsa", "n": encodeBigInt(rsaKey.N), "e": encodeBigInt(big.NewInt(int64(rsaKey.E)))},
+				{"kty": "EC", "kid": "ec", "crv": "P-256", "x": encodeBigInt(ecKey.X), "y": encodeBigInt(ecKey.Y)},
+				{"kty": "oct", "kid": "unsupported"},
+			},
+		})
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	sign := func(method jwt.SigningMethod, kid string, key any, claims jwt.MapClaims) string {
+		token := jwt.NewWithClaims(method, claims)
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(key)
+		require.NoError(t, err)
+		return signed
+	}
+	claims := jwt.MapClaims{"sub": "user", "iss": "gopack", "exp": now.Add(time.Hour).Unix()}
+
+	t.Run("with RSA and EC signed tokens", func(t *testing.T) {
+		fetches.Store(0)
+		validator := NewJWKSValidator(NewJWKS(server.URL), WithJWTIssuer("gopack"))
+
+		validated, err := validator.Validate(context.TODO(), sign(jwt.SigningMethodRS256, "rsa", rsaKey, claims))
+		require.NoError(t, err)
+		assert.Equal(t, "user", validated["sub"])
+
+		_, err = validator.Validate(context.TODO(), sign(jwt.SigningMethodES256, "ec", ecKey, claims))
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, fetches.Load())
+	})
+	t.Run("with unknown key ID", func(t *testing.T) {
+		fetches.Store(0)
+		validator := NewJWKSValidator(NewJWKS(server.URL))
+		_, err := validator.Validate(context.TODO(), sign(jwt.SigningMethodRS256, "rsa", rsaKey, claims))
+		require.NoError(t, err)
+
+		_, err = validator.Validate(context.TODO(), sign(jwt.SigningMethodRS256, "unknown", rsaKey, claims))
+		assert.Error(t, err)
+		// the unknown key ID does not trigger a fetch within the minimum refresh interval
+		assert.EqualValues(t, 1, fetches.Load())
+	})
+	t.Run("with keys refresh", func(t *testing.T) {
+		fetches.Store(0)
+		fake := clock.NewFake(now)
+		validator := NewJWKSValidator(NewJWKS(server.URL, WithJWKSClock(fake), WithJWKSRefreshInterval(time.Minute)),
+			WithJWTClock(fake))
+		token := sign(jwt.SigningMethodRS256, "rsa", rsaKey, claims)
+
+		_, err := validator.Validate(context.TODO(), token)
+		require.NoError(t, err)
+		fake.Advance(time.Minute)
+		_, err = validator.Validate(context.TODO(), token)
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, fetches.Load())
+	})
+	t.Run("with expired token", func(t *testing.T) {
+		fake := clock.NewFake(now.Add(2 * time.Hour))
+		validator := NewJWKSValidator(NewJWKS(server.URL), WithJWTClock(fake))
+		_, err := validator.Validate(context.TODO(), sign(jwt.SigningMethodRS256, "rsa", rsaKey, claims))
+		assert.ErrorIs(t, err, jwt.ErrTokenExpired)
+	})
+	t.Run("with invalid issuer", func(t *testing.T) {
+		validator := NewJWKSValidator(NewJWKS(server.URL), WithJWTIssuer("other"))
+		_, err := validator.Validate(context.TODO(), sign(jwt.SigningMethodRS256, "rsa", rsaKey, claims))
+		assert.ErrorIs(t, err, jwt.ErrTokenInvalidIssuer)
+	})
+	t.Run("with invalid method", func(t *testing.T) {
+		validator := NewJWKSValidator(NewJWKS(server.URL), WithJWTMethods("RS256"))
+		_, err := validator.Validate(context.TODO(), sign(jwt.SigningMethodES256, "ec", ecKey, claims))
+		assert.Error(t, err)
+	})
+	t.Run("with unreachable JWKS", func(t *testing.T) {
+		validator := NewJWKSValidator(NewJWKS("http://127.0.0.1:0"))
+		_, err := validator.Validate(context.TODO(), sign(jwt.SigningMethodRS256, "rsa", rsaKey, claims))
+		assert.Error(t, err)
+	})
+	t.Run("with failing JWKS refresh", func(t *testing.T) {
+		fetches.Store(0)
+		defer failing.Store(false)
+		fake := clock.NewFake(now)
+		validator := NewJWKSValidator(NewJWKS(server.URL, WithJWKSClock(fake), WithJWKSRefreshInterval(time.Minute)),
+			WithJWTClock(fake))
+		token := sign(jwt.SigningMethodRS256, "rsa", rsaKey, claims)
+
+		_, err := validator.Validate(context.TODO(), token)
+		require.NoError(t, err)
+
+		// the cached keys are still served when the refresh fails
+		failing.Store(true)
+		fake.Advance(time.Minute)
+		_, err = validator.Validate(context.TODO(), token)
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, fetches.Load())
+
+		// the failing endpoint is not fetched again within the minimum refresh interval
+		_, err = validator.Validate(context.TODO(), token)
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, fetches.Load())
+
+		_, err = validator.Validate(context.TODO(), sign(jwt.SigningMethodES256, "unknown", ecKey, claims))
+		assert.Error(t, err)
+	})
+	t.Run("with cancelled context", func(t *testing.T) {
+		hanging := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		}))
+		defer hanging.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		validator := NewJWKSValidator(NewJWKS(hanging.URL, WithJWKSFetchTimeout(200*time.Millisecond)))
+		_, err := validator.Validate(ctx, sign(jwt.SigningMethodRS256, "rsa", rsaKey, claims))
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+	t.Run("with first caller cancelled", func(t *testing.T) {
+		release := make(chan struct{})
+		slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+			server.Config.Handler.ServeHTTP(w, r)
+		}))
+		defer slow.Close()
+
+		validator := NewJWKSValidator(NewJWKS(slow.URL))
+		token := sign(jwt.SigningMethodRS256, "rsa", rsaKey, claims)
+
+		// the first caller gives up while the keys are being fetched
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := validator.Validate(ctx, token)
+		require.ErrorIs(t, err, context.Canceled)
+
+		// the fetch carries on and serves the next callers
+		close(release)
+		_, err = validator.Validate(context.TODO(), token)
+		require.NoError(t, err)
+	})
+	t.Run("with key function", func(t *testing.T) {
+		validator := NewJWTValidator(NewJWKS(server.URL).Keyfunc)
+		_, err := validator.Validate(context.TODO(), sign(jwt.SigningMethodRS256, "rsa", rsaKey, claims))
+		require.NoError(t, err)
+	})
+}
+
+// encodeBigInt encodes a big integer in base64url
+func encodeBigInt(value *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(value.Bytes())
+}
@@ -0,0 +1,297 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package grpc
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/tochemey/gopack/clock"
+)
+
+// globalCircuit is the name of the circuit shared by all the methods in the global mode
+const globalCircuit = "*"
+
+// CircuitState defines the state of a circuit
+type CircuitState int
+
+const (
+	// CircuitClosed means the calls go through
+	CircuitClosed CircuitState = iota
+	// CircuitOpen means the calls are rejected
+	CircuitOpen
+	// CircuitHalfOpen means a single probe call is let through to check whether the server has recovered
+	CircuitHalfOpen
+)
+
+// String returns the string representation of the circuit state
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreakerMode defines how the calls are grouped into circuits
+type CircuitBreakerMode int
+
+const (
+	// CircuitBreakerGlobal uses a single circuit for all the methods
+	CircuitBreakerGlobal CircuitBreakerMode = iota
+	// CircuitBreakerPerMethod uses a circuit per method
+	CircuitBreakerPerMethod
+)
+
+// CircuitStateChangeFunc is called when a circuit changes state.
+// name is the method name in the per-method mode and "*" in the global mode.
+type CircuitStateChangeFunc func(name string, from, to CircuitState)
+
+// CircuitBreaker trips a circuit after a number of consecutive failures and rejects
+// the calls until a cool-down period has elapsed. It then half-opens the circuit and
+// lets a single probe call through: the circuit is closed when the probe succeeds
+// and opened again otherwise.
+type CircuitBreaker struct {
+	failureThreshold int
+	coolDown         time.Duration
+	mode             CircuitBreakerMode
+	failureCodes     []codes.Code
+	onStateChange    CircuitStateChangeFunc
+	clock            clock.Clock
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+// circuit holds the state of a circuit
+type circuit struct {
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// CircuitBreakerOption configures the CircuitBreaker
+type CircuitBreakerOption func(*CircuitBreaker)
+
+// WithCircuitBreakerMode sets the circuit breaker mode. The default mode is CircuitBreakerGlobal
+func WithCircuitBreakerMode(mode CircuitBreakerMode) CircuitBreakerOption {
+	return func(cb *CircuitBreaker) {
+		cb.mode = mode
+	}
+}
+
+// WithCircuitBreakerFailureCodes sets the status codes counted as failures.
+// The default codes are codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted,
+// codes.Internal and codes.Unknown
+func WithCircuitBreakerFailureCodes(failureCodes ...codes.Code) CircuitBreakerOption {
+	return func(cb *CircuitBreaker) {
+		cb.failureCodes = failureCodes
+	}
+}
+
+// WithCircuitBreakerStateChange sets the callback called when a circuit changes state.
+// This is useful to log or record metrics about the transitions.
+func WithCircuitBreakerStateChange(fn CircuitStateChangeFunc) CircuitBreakerOption {
+	return func(cb *CircuitBreaker) {
+		cb.onStateChange = fn
+	}
+}
+
+// WithCircuitBreakerClock sets the clock used to compute the cool-down period.
+// This is mainly useful in tests with a clock.Fake
+func WithCircuitBreakerClock(clock clock.Clock) CircuitBreakerOption {
+	return func(cb *CircuitBreaker) {
+		cb.clock = clock
+	}
+}
+
+// NewCircuitBreaker creates a CircuitBreaker that opens a circuit after failureThreshold consecutive failures
+// and half-opens it after the coolDown period.
+func NewCircuitBreaker(failureThreshold int, coolDown time.Duration, opts ...CircuitBreakerOption) *CircuitBreaker {
+	cb := &CircuitBreaker{
+		failureThreshold: max(failureThreshold, 1),
+		coolDown:         coolDown,
+		mode:             CircuitBreakerGlobal,
+		failureCodes: []codes.Code{
+			codes.Unavailable,
+			codes.DeadlineExceeded,
+			codes.ResourceExhausted,
+			codes.Internal,
+			codes.Unknown,
+		},
+		clock:    clock.New(),
+		circuits: make(map[string]*circuit),
+	}
+
+	for _, opt := range opts {
+		opt(cb)
+	}
+
+	return cb
+}
+
+// State returns the state of the circuit of the given method
+func (cb *CircuitBreaker) State(method string) CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if c, ok := cb.circuits[cb.circuitName(method)]; ok {
+		return c.state
+	}
+	return CircuitClosed
+}
+
+// allow checks whether a call to the given method can go through
+func (cb *CircuitBreaker) allow(method string) bool {
+	name := cb.circuitName(method)
+	cb.mu.Lock()
+	c := cb.getCircuit(name)
+	from := c.state
+	allowed := false
+	switch c.state {
+	case CircuitClosed:
+		allowed = true
+	case CircuitOpen:
+		if cb.clock.Since(c.openedAt) >= cb.coolDown {
+			c.state = CircuitHalfOpen
+			c.probing = true
+			allowed = true
+		}
+	case CircuitHalfOpen:
+		if !c.probing {
+			c.probing = true
+			allowed = true
+		}
+	}
+	to := c.state
+	cb.mu.Unlock()
+
+	cb.notify(name, from, to)
+	return allowed
+}
+
+// record records the outcome of a call to the given method
+func (cb *CircuitBreaker) record(method string, err error) {
+	name := cb.circuitName(method)
+	failed := err != nil && slices.Contains(cb.failureCodes, status.Code(err))
+
+	cb.mu.Lock()
+	c := cb.getCircuit(name)
+	from := c.state
+	switch c.state {
+	case CircuitClosed:
+		if !failed {
+			c.failures = 0
+			break
+		}
+		c.failures++
+		if c.failures >= cb.failureThreshold {
+			cb.open(c)
+		}
+	case CircuitHalfOpen:
+		c.probing = false
+		if failed {
+			cb.open(c)
+			break
+		}
+		c.state = CircuitClosed
+		c.failures = 0
+	case CircuitOpen:
+		// the outcome of a call started before the circuit opened
+	}
+	to := c.state
+	cb.mu.Unlock()
+
+	cb.notify(name, from, to)
+}
+
+// open trips the circuit
+func (cb *CircuitBreaker) open(c *circuit) {
+	c.state = CircuitOpen
+	c.openedAt = cb.clock.Now()
+	c.failures = 0
+}
+
+// getCircuit returns the circuit of the given name and creates it when it does not exist
+func (cb *CircuitBreaker) getCircuit(name string) *circuit {
+	c, ok := cb.circuits[name]
+	if !ok {
+		c = &circuit{state: CircuitClosed}
+		cb.circuits[name] = c
+	}
+	return c
+}
+
+// circuitName returns the name of the circuit of the given method
+func (cb *CircuitBreaker) circuitName(method string) string {
+	if cb.mode == CircuitBreakerPerMethod {
+		return method
+	}
+	return globalCircuit
+}
+
+// notify calls the state change callback when the state has changed
+func (cb *CircuitBreaker) notify(name string, from, to CircuitState) {
+	if from != to && cb.onStateChange != nil {
+		cb.onStateChange(name, from, to)
+	}
+}
+
+// NewCircuitBreakerUnaryClientInterceptor returns a client unary interceptor that rejects the calls
+// with codes.Unavailable while the circuit is open.
+func NewCircuitBreakerUnaryClientInterceptor(cb *CircuitBreaker) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if !cb.allow(method) {
+			return status.Errorf(codes.Unavailable, "%s have been rejected by the circuit breaker.", method)
+		}
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		cb.record(method, err)
+		return err
+	}
+}
+
+// NewCircuitBreakerStreamClientInterceptor returns a client stream interceptor that rejects the creation
+// of streams with codes.Unavailable while the circuit is open. Only the stream creation outcome is recorded.
+func NewCircuitBreakerStreamClientInterceptor(cb *CircuitBreaker) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if !cb.allow(method) {
+			return nil, status.Errorf(codes.Unavailable, "%s have been rejected by the circuit breaker.", method)
+		}
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		cb.record(method, err)
+		return stream, err
+	}
+}
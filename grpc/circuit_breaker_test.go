@@ -0,0 +1,142 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/tochemey/gopack/clock"
+)
+
+// invokerReturning returns an invoker returning the given error
+func invokerReturning(err error) grpc.UnaryInvoker {
+	return func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+		return err
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	failure := status.Error(codes.Unavailable, "failure")
+
+	t.Run("with consecutive failures", func(t *testing.T) {
+		fake := clock.NewFake(time.Now())
+		var transitions []string
+		cb := NewCircuitBreaker(2, time.Minute,
+			WithCircuitBreakerClock(fake),
+			WithCircuitBreakerStateChange(func(name string, from, to CircuitState) {
+				transitions = append(transitions, fmt.Sprintf("%s:%s->%s", name, from, to))
+			}))
+		interceptor := NewCircuitBreakerUnaryClientInterceptor(cb)
+
+		for i := 0; i < 2; i++ {
+			err := interceptor(context.TODO(), "/test", nil, nil, nil, invokerReturning(failure))
+			assert.ErrorIs(t, err, failure)
+		}
+		assert.Equal(t, CircuitOpen, cb.State("/test"))
+
+		// the calls are rejected while the circuit is open
+		calls := 0
+		err := interceptor(context.TODO(), "/test", nil, nil, nil, failingInvoker(codes.OK, 0, &calls))
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+		assert.Zero(t, calls)
+
+		// the probe call closes the circuit
+		fake.Advance(time.Minute)
+		err = interceptor(context.TODO(), "/test", nil, nil, nil, failingInvoker(codes.OK, 0, &calls))
+		require.NoError(t, err)
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, CircuitClosed, cb.State("/test"))
+		assert.Equal(t, []string{"*:closed->open", "*:open->half-open", "*:half-open->closed"}, transitions)
+	})
+	t.Run("with failed probe", func(t *testing.T) {
+		fake := clock.NewFake(time.Now())
+		cb := NewCircuitBreaker(1, time.Minute, WithCircuitBreakerClock(fake))
+		interceptor := NewCircuitBreakerUnaryClientInterceptor(cb)
+
+		_ = interceptor(context.TODO(), "/test", nil, nil, nil, invokerReturning(failure))
+		fake.Advance(time.Minute)
+		_ = interceptor(context.TODO(), "/test", nil, nil, nil, invokerReturning(failure))
+		assert.Equal(t, CircuitOpen, cb.State("/test"))
+	})
+	t.Run("with a single probe in half-open", func(t *testing.T) {
+		fake := clock.NewFake(time.Now())
+		cb := NewCircuitBreaker(1, time.Minute, WithCircuitBreakerClock(fake))
+		cb.record("/test", failure)
+		fake.Advance(time.Minute)
+
+		assert.True(t, cb.allow("/test"))
+		assert.Equal(t, CircuitHalfOpen, cb.State("/test"))
+		assert.False(t, cb.allow("/test"))
+	})
+	t.Run("with non failure codes", func(t *testing.T) {
+		cb := NewCircuitBreaker(1, time.Minute)
+		interceptor := NewCircuitBreakerUnaryClientInterceptor(cb)
+		_ = interceptor(context.TODO(), "/test", nil, nil, nil, invokerReturning(status.Error(codes.NotFound, "not found")))
+		assert.Equal(t, CircuitClosed, cb.State("/test"))
+	})
+	t.Run("with success resetting the failures", func(t *testing.T) {
+		cb := NewCircuitBreaker(2, time.Minute)
+		cb.record("/test", failure)
+		cb.record("/test", nil)
+		cb.record("/test", failure)
+		assert.Equal(t, CircuitClosed, cb.State("/test"))
+	})
+	t.Run("with per-method mode", func(t *testing.T) {
+		cb := NewCircuitBreaker(1, time.Minute, WithCircuitBreakerMode(CircuitBreakerPerMethod))
+		interceptor := NewCircuitBreakerUnaryClientInterceptor(cb)
+		_ = interceptor(context.TODO(), "/first", nil, nil, nil, invokerReturning(failure))
+		assert.Equal(t, CircuitOpen, cb.State("/first"))
+		assert.Equal(t, CircuitClosed, cb.State("/second"))
+		assert.NoError(t, interceptor(context.TODO(), "/second", nil, nil, nil, invokerReturning(nil)))
+	})
+	t.Run("with global mode", func(t *testing.T) {
+		cb := NewCircuitBreaker(1, time.Minute)
+		interceptor := NewCircuitBreakerUnaryClientInterceptor(cb)
+		_ = interceptor(context.TODO(), "/first", nil, nil, nil, invokerReturning(failure))
+		err := interceptor(context.TODO(), "/second", nil, nil, nil, invokerReturning(nil))
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+	})
+	t.Run("with stream interceptor", func(t *testing.T) {
+		cb := NewCircuitBreaker(1, time.Minute)
+		interceptor := NewCircuitBreakerStreamClientInterceptor(cb)
+		streamer := func(context.Context, *grpc.StreamDesc, *grpc.ClientConn, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+			return nil, failure
+		}
+		_, err := interceptor(context.TODO(), &grpc.StreamDesc{}, nil, "/test", streamer)
+		assert.ErrorIs(t, err, failure)
+		_, err = interceptor(context.TODO(), &grpc.StreamDesc{}, nil, "/test", streamer)
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+		assert.NotErrorIs(t, err, failure)
+	})
+}
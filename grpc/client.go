@@ -28,15 +28,24 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"slices"
 	"time"
 
+	"github.com/cenkalti/backoff/v4"
 	"github.com/pkg/errors"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding/gzip"
 	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
 )
 
+// roundRobinServiceConfig is the service config enabling the round_robin load balancing policy
+const roundRobinServiceConfig = `{"loadBalancingConfig":[{"round_robin":{}}]}`
+
 // ConnectionBuilder is a builder to create GRPC connection to the GRPC Server
 type ConnectionBuilder interface {
 	WithOptions(opts ...grpc.DialOption)
@@ -52,6 +61,8 @@ type ConnectionBuilder interface {
 type ClientBuilder struct {
 	options              []grpc.DialOption
 	transportCredentials credentials.TransportCredentials
+	maxRecvMsgSize       int
+	maxSendMsgSize       int
 }
 
 // NewClientBuilder creates an instance of ClientBuilder
@@ -131,12 +142,92 @@ func (b *ClientBuilder) WithDefaultStreamInterceptors() *ClientBuilder {
 	)
 }
 
+// WithRetry retries the failed calls with a retryable status code.
+// Call it after WithDefaultUnaryInterceptors and WithDefaultStreamInterceptors
+// so that all the attempts of a call share the same request ID.
+func (b *ClientBuilder) WithRetry(opts ...RetryOption) *ClientBuilder {
+	b.WithUnaryInterceptors(NewRetryUnaryClientInterceptor(opts...))
+	return b.WithStreamInterceptors(NewRetryStreamClientInterceptor(opts...))
+}
+
+// WithServiceConfigJSON sets the default service config of the connection.
+// It is used unless the name resolver provides a service config.
+// See https://github.com/grpc/grpc/blob/master/doc/service_config.md
+func (b *ClientBuilder) WithServiceConfigJSON(serviceConfig string) *ClientBuilder {
+	b.options = append(b.options, grpc.WithDefaultServiceConfig(serviceConfig))
+	return b
+}
+
+// WithRoundRobin balances the calls across all the addresses returned by the name resolver,
+// e.g. a static address list set with WithResolver or a "dns:///host:port" target.
+// Without it every call is sent to the first reachable address.
+func (b *ClientBuilder) WithRoundRobin() *ClientBuilder {
+	return b.WithServiceConfigJSON(roundRobinServiceConfig)
+}
+
+// WithResolver registers a name resolver for the given scheme that resolves to the given static addresses.
+// The connection must then target the scheme, e.g. ClientConn("scheme:///service").
+func (b *ClientBuilder) WithResolver(scheme string, addresses []string) *ClientBuilder {
+	state := resolver.State{Addresses: make([]resolver.Address, 0, len(addresses))}
+	for _, address := range addresses {
+		state.Addresses = append(state.Addresses, resolver.Address{Addr: address})
+	}
+
+	builder := manual.NewBuilderWithScheme(scheme)
+	builder.InitialState(state)
+	b.options = append(b.options, grpc.WithResolvers(builder))
+	return b
+}
+
+// WithMaxRecvMsgSize sets the maximum size in bytes of the messages the client can receive.
+// The grpc default is 4MB.
+func (b *ClientBuilder) WithMaxRecvMsgSize(size int) *ClientBuilder {
+	b.maxRecvMsgSize = size
+	return b
+}
+
+// WithMaxSendMsgSize sets the maximum size in bytes of the messages the client can send.
+// The grpc default is math.MaxInt32.
+func (b *ClientBuilder) WithMaxSendMsgSize(size int) *ClientBuilder {
+	b.maxSendMsgSize = size
+	return b
+}
+
+// WithGzip compresses the requests with gzip
+func (b *ClientBuilder) WithGzip() *ClientBuilder {
+	b.options = append(b.options, grpc.WithDefaultCallOptions(grpc.UseCompressor(gzip.Name)))
+	return b
+}
+
+// dialOptions validates the message sizes and returns the connection dial options
+func (b *ClientBuilder) dialOptions() ([]grpc.DialOption, error) {
+	if err := validateMessageSize("max receive message size", b.maxRecvMsgSize); err != nil {
+		return nil, err
+	}
+	if err := validateMessageSize("max send message size", b.maxSendMsgSize); err != nil {
+		return nil, err
+	}
+
+	options := slices.Clone(b.options)
+	if b.maxRecvMsgSize > 0 {
+		options = append(options, grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(b.maxRecvMsgSize)))
+	}
+	if b.maxSendMsgSize > 0 {
+		options = append(options, grpc.WithDefaultCallOptions(grpc.MaxCallSendMsgSize(b.maxSendMsgSize)))
+	}
+	return options, nil
+}
+
 // ClientConn returns the client connection to the server
 func (b *ClientBuilder) ClientConn(addr string) (*grpc.ClientConn, error) {
 	if addr == "" {
 		return nil, fmt.Errorf("target connection parameter missing. address = %s", addr)
 	}
-	cc, err := grpc.NewClient(addr, b.options...)
+	options, err := b.dialOptions()
+	if err != nil {
+		return nil, err
+	}
+	cc, err := grpc.NewClient(addr, options...)
 	if err != nil {
 		return nil, fmt.Errorf("unable to connect to client. address = %s. error = %+v", addr, err)
 	}
@@ -149,7 +240,11 @@ func (b *ClientBuilder) TLSClientConn(addr string) (*grpc.ClientConn, error) {
 	if addr == "" {
 		return nil, fmt.Errorf("target connection parameter missing. address = %s", addr)
 	}
-	cc, err := grpc.NewClient(addr, b.options...)
+	options, err := b.dialOptions()
+	if err != nil {
+		return nil, err
+	}
+	cc, err := grpc.NewClient(addr, options...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get tls conn. Unable to connect to client. address = %s: %w", addr, err)
 	}
@@ -158,8 +253,19 @@ func (b *ClientBuilder) TLSClientConn(addr string) (*grpc.ClientConn, error) {
 
 // DefaultClientConn return a grpc client connection
 func DefaultClientConn(addr string) (*grpc.ClientConn, error) {
-	// create the client builder
-	clientBuilder := NewClientBuilder().
+	// get the gRPC client connection
+	conn, err := defaultClientBuilder().ClientConn(addr)
+	// handle the connection error
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create grpc service client")
+	}
+	// return the client connection created
+	return conn, nil
+}
+
+// defaultClientBuilder creates the client builder used by DefaultClientConn
+func defaultClientBuilder() *ClientBuilder {
+	return NewClientBuilder().
 		WithDefaultUnaryInterceptors().
 		WithDefaultStreamInterceptors().
 		WithInsecure().
@@ -167,12 +273,88 @@ func DefaultClientConn(addr string) (*grpc.ClientConn, error) {
 			Time:                1200 * time.Second,
 			PermitWithoutStream: true,
 		})
-	// get the gRPC client connection
-	conn, err := clientBuilder.ClientConn(addr)
-	// handle the connection error
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to create grpc service client")
+}
+
+// DialRetryPolicy defines how DialWithRetry retries to connect to the server
+type DialRetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, the first one included.
+	// Zero means the attempts go on until the context is done.
+	MaxAttempts int
+	// AttemptTimeout is the maximum duration an attempt waits for the connection to be ready
+	AttemptTimeout time.Duration
+	// BackOff computes the delay between the attempts
+	BackOff backoff.BackOff
+}
+
+// DefaultDialRetryPolicy returns a policy making 5 attempts of 5 seconds each,
+// separated by an exponential backoff with jitter
+func DefaultDialRetryPolicy() DialRetryPolicy {
+	return DialRetryPolicy{
+		MaxAttempts:    5,
+		AttemptTimeout: 5 * time.Second,
+		BackOff:        NewRetryExponentialBackOff(DefaultRetryInitialInterval, DefaultRetryMaxInterval, DefaultRetryJitter),
+	}
+}
+
+// DialWithRetry creates a client connection to the given address and waits for it to be ready.
+// When the connection is not ready within the attempt timeout, the connection is closed and a new attempt
+// is made after the policy backoff. It returns an error once the policy is exhausted or the context is done.
+// When no dial options are given, the options of DefaultClientConn are used.
+func DialWithRetry(ctx context.Context, addr string, policy DialRetryPolicy, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	if len(opts) == 0 {
+		opts = defaultClientBuilder().options
+	}
+
+	bo := policy.BackOff
+	if bo == nil {
+		bo = DefaultDialRetryPolicy().BackOff
+	}
+	if policy.MaxAttempts > 0 {
+		bo = backoff.WithMaxRetries(bo, uint64(policy.MaxAttempts-1))
+	}
+
+	var conn *grpc.ClientConn
+	operation := func() error {
+		cc, err := grpc.NewClient(addr, opts...)
+		if err != nil {
+			// an invalid target or invalid options will not recover
+			return backoff.Permanent(err)
+		}
+
+		if err := waitForReady(ctx, cc, policy.AttemptTimeout); err != nil {
+			_ = cc.Close()
+			return err
+		}
+		conn = cc
+		return nil
+	}
+
+	if err := backoff.Retry(operation, backoff.WithContext(bo, ctx)); err != nil {
+		return nil, fmt.Errorf("failed to dial %s: %w", addr, err)
 	}
-	// return the client connection created
 	return conn, nil
 }
+
+// waitForReady connects the given client connection and blocks until it is ready or the timeout elapses
+func waitForReady(ctx context.Context, conn *grpc.ClientConn, timeout time.Duration) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	conn.Connect()
+	for {
+		state := conn.GetState()
+		switch state {
+		case connectivity.Ready:
+			return nil
+		case connectivity.Shutdown:
+			return errors.New("connection is shut down")
+		}
+
+		if !conn.WaitForStateChange(ctx, state) {
+			return fmt.Errorf("connection is not ready (%s): %w", state, ctx.Err())
+		}
+	}
+}
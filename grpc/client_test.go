@@ -26,10 +26,21 @@ package grpc
 
 import (
 	"context"
+	"fmt"
+	"net"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/cenkalti/backoff/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+	"github.com/travisjeffery/go-dynaport"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/status"
 
 	testpb "github.com/tochemey/gopack/test/data/test/v1"
 )
@@ -90,3 +101,157 @@ func (s *ClientTestSuite) TestSayHello() {
 		s.Assert().Equal(resp.Message, "This is a mocked service test")
 	})
 }
+
+func (s *ClientTestSuite) TestSayHelloWithRetry() {
+	ctx := context.Background()
+	service := new(flakyService)
+	service.failures.Store(2)
+	server := NewInProcessServerBuilder().Build()
+	server.RegisterService(func(srv *grpc.Server) {
+		testpb.RegisterGreeterServer(srv, service)
+	})
+	s.Require().NoError(server.Start())
+	defer server.Cleanup()
+
+	var err error
+	s.clientConn, err = NewClientBuilder().
+		WithInsecure().
+		WithDefaultStreamInterceptors().
+		WithDefaultUnaryInterceptors().
+		WithRetry(WithRetryMaxAttempts(3), WithRetryBackOff(noBackOff)).
+		WithOptions(grpc.WithContextDialer(GetBufDialer(server.GetListener()))).
+		ClientConn("localhost:50051")
+	s.Require().NoError(err)
+
+	client := testpb.NewGreeterClient(s.clientConn)
+	resp, err := client.SayHello(ctx, &testpb.HelloRequest{Name: "test"})
+	s.Require().NoError(err)
+	s.Assert().Equal("hello after 3 attempts", resp.GetMessage())
+	s.Assert().EqualValues(3, service.attempts.Load())
+
+	// the call fails once the attempts are exhausted
+	service.attempts.Store(0)
+	service.failures.Store(3)
+	_, err = client.SayHello(ctx, &testpb.HelloRequest{Name: "test"})
+	s.Assert().Equal(codes.Unavailable, status.Code(err))
+	s.Assert().EqualValues(3, service.attempts.Load())
+}
+
+// flakyService fails with Unavailable for the given number of attempts before replying
+type flakyService struct {
+	testpb.UnimplementedGreeterServer
+	failures atomic.Int64
+	attempts atomic.Int64
+}
+
+func (s *flakyService) SayHello(context.Context, *testpb.HelloRequest) (*testpb.HelloReply, error) {
+	attempt := s.attempts.Add(1)
+	if attempt <= s.failures.Load() {
+		return nil, status.Error(codes.Unavailable, "unavailable")
+	}
+	return &testpb.HelloReply{Message: fmt.Sprintf("hello after %d attempts", attempt)}, nil
+}
+
+func (s *ClientTestSuite) TestLoadBalancing() {
+	ctx := context.Background()
+	var addresses []string
+	for i := range 2 {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		s.Require().NoError(err)
+		srv, err := NewServerBuilder().
+			WithListener(listener).
+			WithService(&namedService{name: fmt.Sprintf("server-%d", i)}).
+			Build()
+		s.Require().NoError(err)
+		s.Require().NoError(srv.Start(ctx))
+		s.T().Cleanup(func() { _ = srv.Stop(ctx) })
+		addresses = append(addresses, listener.Addr().String())
+	}
+
+	var err error
+	s.clientConn, err = NewClientBuilder().
+		WithInsecure().
+		WithResolver("static", addresses).
+		WithRoundRobin().
+		ClientConn("static:///greeter")
+	s.Require().NoError(err)
+
+	client := testpb.NewGreeterClient(s.clientConn)
+	servers := make(map[string]int)
+	for range 10 {
+		resp, err := client.SayHello(ctx, &testpb.HelloRequest{Name: "test"}, grpc.WaitForReady(true))
+		s.Require().NoError(err)
+		servers[resp.GetMessage()]++
+	}
+	s.Assert().Len(servers, 2)
+}
+
+// namedService replies with its name
+type namedService struct {
+	testpb.UnimplementedGreeterServer
+	name string
+}
+
+func (s *namedService) SayHello(context.Context, *testpb.HelloRequest) (*testpb.HelloReply, error) {
+	return &testpb.HelloReply{Message: s.name}, nil
+}
+
+func (s *namedService) RegisterService(server *grpc.Server) {
+	testpb.RegisterGreeterServer(server, s)
+}
+
+func TestDialWithRetry(t *testing.T) {
+	t.Run("with server started late", func(t *testing.T) {
+		ctx := context.Background()
+		addr := fmt.Sprintf("127.0.0.1:%d", dynaport.Get(1)[0])
+
+		started := make(chan Server, 1)
+		go func() {
+			time.Sleep(300 * time.Millisecond)
+			listener, err := net.Listen("tcp", addr)
+			if err != nil {
+				close(started)
+				return
+			}
+			srv, _ := NewServerBuilder().WithListener(listener).WithService(&MockedService{}).Build()
+			_ = srv.Start(ctx)
+			started <- srv
+		}()
+
+		conn, err := DialWithRetry(ctx, addr, DialRetryPolicy{
+			MaxAttempts:    20,
+			AttemptTimeout: 100 * time.Millisecond,
+			BackOff:        backoff.NewConstantBackOff(50 * time.Millisecond),
+		})
+		require.NoError(t, err)
+		defer conn.Close()
+		assert.Equal(t, connectivity.Ready, conn.GetState())
+
+		srv, ok := <-started
+		require.True(t, ok)
+		require.NoError(t, srv.Stop(ctx))
+	})
+	t.Run("with policy exhausted", func(t *testing.T) {
+		addr := fmt.Sprintf("127.0.0.1:%d", dynaport.Get(1)[0])
+		start := time.Now()
+		_, err := DialWithRetry(context.Background(), addr, DialRetryPolicy{
+			MaxAttempts:    2,
+			AttemptTimeout: 50 * time.Millisecond,
+			BackOff:        backoff.NewConstantBackOff(10 * time.Millisecond),
+		})
+		require.Error(t, err)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+	t.Run("with context cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		addr := fmt.Sprintf("127.0.0.1:%d", dynaport.Get(1)[0])
+		_, err := DialWithRetry(ctx, addr, DialRetryPolicy{AttemptTimeout: 20 * time.Millisecond})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+	t.Run("with invalid options", func(t *testing.T) {
+		// no transport credentials is a permanent error
+		_, err := DialWithRetry(context.Background(), "127.0.0.1:1", DefaultDialRetryPolicy(), grpc.WithUserAgent("test"))
+		assert.Error(t, err)
+	})
+}
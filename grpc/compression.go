@@ -0,0 +1,64 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding/gzip"
+)
+
+// MaxMessageSize is the largest message size that can be configured
+const MaxMessageSize = math.MaxInt32
+
+// validateMessageSize checks that the given message size is within bounds.
+// A zero size means the grpc default is used.
+func validateMessageSize(name string, size int) error {
+	if size < 0 || size > MaxMessageSize {
+		return fmt.Errorf("invalid %s (%d): must be between 1 and %d bytes", name, size, MaxMessageSize)
+	}
+	return nil
+}
+
+// newGzipUnaryServerInterceptor compresses the responses with gzip when the client supports it
+func newGzipUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		// the client does not support gzip when this fails
+		_ = grpc.SetSendCompressor(ctx, gzip.Name)
+		return handler(ctx, req)
+	}
+}
+
+// newGzipStreamServerInterceptor compresses the stream messages with gzip when the client supports it
+func newGzipStreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		// the client does not support gzip when this fails
+		_ = grpc.SetSendCompressor(ss.Context(), gzip.Name)
+		return handler(srv, ss)
+	}
+}
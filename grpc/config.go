@@ -24,6 +24,15 @@
 
 package grpc
 
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
 // Config represent the grpc option
 type Config struct {
 	ServiceName      string // ServiceName is the name given that will show in the traces
@@ -34,4 +43,113 @@ type Config struct {
 	EnableReflection bool   // EnableReflection this is useful or local dev testing
 	MetricsEnabled   bool   // MetricsEnabled checks whether metrics should be enabled or not
 	MetricsPort      int
+	TLSCertFile      string // TLSCertFile is the PEM encoded server certificate file used for mutual TLS
+	TLSKeyFile       string // TLSKeyFile is the PEM encoded server private key file used for mutual TLS
+	TLSClientCAFile  string // TLSClientCAFile is the PEM encoded certificate authorities file used to verify the clients
+}
+
+// the environment variables read by ConfigFromEnv
+const (
+	EnvServiceName      = "GRPC_SERVICE_NAME"
+	EnvGrpcHost         = "GRPC_HOST"
+	EnvGrpcPort         = "GRPC_PORT"
+	EnvTraceEnabled     = "GRPC_TRACE_ENABLED"
+	EnvTraceURL         = "GRPC_TRACE_URL"
+	EnvEnableReflection = "GRPC_ENABLE_REFLECTION"
+	EnvMetricsEnabled   = "GRPC_METRICS_ENABLED"
+	EnvMetricsPort      = "GRPC_METRICS_PORT"
+	EnvTLSCertFile      = "GRPC_TLS_CERT_FILE"
+	EnvTLSKeyFile       = "GRPC_TLS_KEY_FILE"
+	EnvTLSClientCAFile  = "GRPC_TLS_CLIENT_CA_FILE"
+)
+
+// defaultMetricsPort is the metrics port used when GRPC_METRICS_PORT is not set
+const defaultMetricsPort = 9102
+
+// ConfigFromEnv creates a Config from the environment variables.
+// Unset variables fallback to the defaults: the gRPC port is 50051, the metrics port is 9102
+// and the boolean settings are disabled. All the invalid variables are reported in the returned error.
+func ConfigFromEnv() (*Config, error) {
+	parser := new(envParser)
+	cfg := &Config{
+		ServiceName:      parser.string(EnvServiceName),
+		GrpcHost:         parser.string(EnvGrpcHost),
+		GrpcPort:         int32(parser.port(EnvGrpcPort, defaultGrpcPort)),
+		TraceEnabled:     parser.bool(EnvTraceEnabled),
+		TraceURL:         parser.string(EnvTraceURL),
+		EnableReflection: parser.bool(EnvEnableReflection),
+		MetricsEnabled:   parser.bool(EnvMetricsEnabled),
+		MetricsPort:      parser.port(EnvMetricsPort, defaultMetricsPort),
+		TLSCertFile:      parser.string(EnvTLSCertFile),
+		TLSKeyFile:       parser.string(EnvTLSKeyFile),
+		TLSClientCAFile:  parser.string(EnvTLSClientCAFile),
+	}
+
+	if cfg.TraceEnabled {
+		if cfg.TraceURL == "" {
+			parser.errs = append(parser.errs, fmt.Errorf("%s is required when %s is enabled", EnvTraceURL, EnvTraceEnabled))
+		}
+		if cfg.ServiceName == "" {
+			parser.errs = append(parser.errs, fmt.Errorf("%s is required when %s is enabled", EnvServiceName, EnvTraceEnabled))
+		}
+	}
+
+	// mutual TLS requires all the files
+	tlsFiles := []string{cfg.TLSCertFile, cfg.TLSKeyFile, cfg.TLSClientCAFile}
+	if slices.Contains(tlsFiles, "") && slices.ContainsFunc(tlsFiles, func(file string) bool { return file != "" }) {
+		parser.errs = append(parser.errs, fmt.Errorf("%s, %s and %s must be set together", EnvTLSCertFile, EnvTLSKeyFile, EnvTLSClientCAFile))
+	}
+
+	if err := errors.Join(parser.errs...); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// MustConfigFromEnv is like ConfigFromEnv but panics when the environment is invalid
+func MustConfigFromEnv() *Config {
+	cfg, err := ConfigFromEnv()
+	if err != nil {
+		panic(err)
+	}
+	return cfg
+}
+
+// envParser reads the environment variables and accumulates the parsing errors
+type envParser struct {
+	errs []error
+}
+
+// string returns the trimmed value of the given environment variable
+func (p *envParser) string(key string) string {
+	return strings.TrimSpace(os.Getenv(key))
+}
+
+// bool parses the given environment variable as a boolean. It is false when not set
+func (p *envParser) bool(key string) bool {
+	value := p.string(key)
+	if value == "" {
+		return false
+	}
+
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		p.errs = append(p.errs, fmt.Errorf("invalid %s (%s): must be a boolean", key, value))
+	}
+	return enabled
+}
+
+// port parses the given environment variable as a TCP port. It is the fallback when not set
+func (p *envParser) port(key string, fallback int) int {
+	value := p.string(key)
+	if value == "" {
+		return fallback
+	}
+
+	port, err := strconv.Atoi(value)
+	if err != nil || port <= 0 || port > 65535 {
+		p.errs = append(p.errs, fmt.Errorf("invalid %s (%s): must be a port between 1 and 65535", key, value))
+		return fallback
+	}
+	return port
 }
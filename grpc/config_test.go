@@ -0,0 +1,89 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package grpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Run("with defaults", func(t *testing.T) {
+		cfg, err := ConfigFromEnv()
+		require.NoError(t, err)
+		assert.EqualValues(t, defaultGrpcPort, cfg.GrpcPort)
+		assert.Equal(t, defaultMetricsPort, cfg.MetricsPort)
+		assert.False(t, cfg.TraceEnabled)
+		assert.False(t, cfg.EnableReflection)
+	})
+	t.Run("with all variables set", func(t *testing.T) {
+		t.Setenv(EnvServiceName, "accounts")
+		t.Setenv(EnvGrpcHost, "127.0.0.1")
+		t.Setenv(EnvGrpcPort, "9000")
+		t.Setenv(EnvTraceEnabled, "true")
+		t.Setenv(EnvTraceURL, "otel-collector:4317")
+		t.Setenv(EnvEnableReflection, "1")
+		t.Setenv(EnvMetricsEnabled, "true")
+		t.Setenv(EnvMetricsPort, "9100")
+		t.Setenv(EnvTLSCertFile, "server.crt")
+		t.Setenv(EnvTLSKeyFile, "server.key")
+		t.Setenv(EnvTLSClientCAFile, "ca.crt")
+
+		cfg, err := ConfigFromEnv()
+		require.NoError(t, err)
+		assert.Equal(t, &Config{
+			ServiceName:      "accounts",
+			GrpcHost:         "127.0.0.1",
+			GrpcPort:         9000,
+			TraceEnabled:     true,
+			TraceURL:         "otel-collector:4317",
+			EnableReflection: true,
+			MetricsEnabled:   true,
+			MetricsPort:      9100,
+			TLSCertFile:      "server.crt",
+			TLSKeyFile:       "server.key",
+			TLSClientCAFile:  "ca.crt",
+		}, cfg)
+		assert.NotPanics(t, func() { MustConfigFromEnv() })
+	})
+	t.Run("with invalid variables", func(t *testing.T) {
+		t.Setenv(EnvGrpcPort, "70000")
+		t.Setenv(EnvEnableReflection, "maybe")
+		t.Setenv(EnvTraceEnabled, "true")
+		t.Setenv(EnvTLSCertFile, "server.crt")
+
+		cfg, err := ConfigFromEnv()
+		require.Error(t, err)
+		assert.Nil(t, cfg)
+		assert.ErrorContains(t, err, "invalid GRPC_PORT (70000)")
+		assert.ErrorContains(t, err, "invalid GRPC_ENABLE_REFLECTION (maybe)")
+		assert.ErrorContains(t, err, "GRPC_TRACE_URL is required")
+		assert.ErrorContains(t, err, "GRPC_SERVICE_NAME is required")
+		assert.ErrorContains(t, err, "must be set together")
+		assert.Panics(t, func() { MustConfigFromEnv() })
+	})
+}
@@ -0,0 +1,134 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/tochemey/gopack/otel/trace"
+)
+
+// gatewayReadHeaderTimeout is the amount of time allowed to read the gateway requests headers
+const gatewayReadHeaderTimeout = 10 * time.Second
+
+// GatewayRegistration registers the REST/JSON handlers of a service on the gateway mux.
+// The RegisterXXXHandler functions generated by protoc-gen-grpc-gateway have this signature.
+type GatewayRegistration func(ctx context.Context, mux *runtime.ServeMux, conn *grpc.ClientConn) error
+
+// gateway is the grpc-gateway HTTP server transcoding REST/JSON requests into gRPC calls
+type gateway struct {
+	port          int
+	addr          string
+	registrations []GatewayRegistration
+	muxOptions    []runtime.ServeMuxOption
+	dialOptions   []grpc.DialOption
+	healthCheck   bool
+	serviceName   string
+	tracing       bool
+
+	conn     *grpc.ClientConn
+	server   *http.Server
+	listener net.Listener
+}
+
+// start connects the gateway to the gRPC server and starts serving the HTTP requests
+func (g *gateway) start(ctx context.Context, grpcAddr string) error {
+	dialOptions := append([]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, g.dialOptions...)
+	if g.tracing {
+		dialOptions = append(dialOptions,
+			grpc.WithChainUnaryInterceptor(NewRequestIDUnaryClientInterceptor(), NewTracingClientUnaryInterceptor()),
+			grpc.WithChainStreamInterceptor(NewRequestIDStreamClientInterceptor(), NewTracingClientStreamInterceptor()),
+		)
+	}
+
+	conn, err := grpc.NewClient(grpcAddr, dialOptions...)
+	if err != nil {
+		return fmt.Errorf("failed to connect the gateway to the grpc server: %w", err)
+	}
+
+	muxOptions := g.muxOptions
+	if g.healthCheck {
+		muxOptions = append(muxOptions, runtime.WithHealthzEndpoint(grpc_health_v1.NewHealthClient(conn)))
+	}
+
+	mux := runtime.NewServeMux(muxOptions...)
+	for _, register := range g.registrations {
+		if err := register(ctx, mux, conn); err != nil {
+			_ = conn.Close()
+			return fmt.Errorf("failed to register the gateway handlers: %w", err)
+		}
+	}
+
+	var handler http.Handler = mux
+	if g.tracing {
+		handler = trace.Middleware(g.serviceName)(mux)
+	}
+
+	listener, err := net.Listen("tcp", g.addr)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+
+	g.conn = conn
+	g.listener = listener
+	g.server = &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: gatewayReadHeaderTimeout,
+	}
+
+	go g.serv()
+	return nil
+}
+
+// serv makes the gateway listener ready to accept connections
+func (g *gateway) serv() {
+	if err := g.server.Serve(g.listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		panic(err)
+	}
+}
+
+// stop gracefully shuts down the gateway and closes its connection to the gRPC server.
+// The in-flight requests are aborted when they do not complete before the context is done.
+func (g *gateway) stop(ctx context.Context) error {
+	if g.server == nil {
+		return nil
+	}
+
+	if err := g.server.Shutdown(ctx); err != nil {
+		_ = g.server.Close()
+	}
+	return g.conn.Close()
+}
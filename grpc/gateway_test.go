@@ -0,0 +1,121 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/suite"
+	"github.com/travisjeffery/go-dynaport"
+	"google.golang.org/grpc"
+
+	testv1 "github.com/tochemey/gopack/test/data/test/v1"
+)
+
+type gatewayTestSuite struct {
+	suite.Suite
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestGatewayTestSuite(t *testing.T) {
+	suite.Run(t, new(gatewayTestSuite))
+}
+
+// registerGreeterGateway is a hand-written equivalent of a generated gateway registration
+func registerGreeterGateway(_ context.Context, mux *runtime.ServeMux, conn *grpc.ClientConn) error {
+	client := testv1.NewGreeterClient(conn)
+	return mux.HandlePath(http.MethodGet, "/v1/hello/{name}", func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+		resp, err := client.SayHello(r.Context(), &testv1.HelloRequest{Name: params["name"]})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"message": resp.GetMessage()})
+	})
+}
+
+func (s *gatewayTestSuite) TestGateway() {
+	s.Run("with REST request and health check", func() {
+		ctx := context.TODO()
+		ports := dynaport.Get(2)
+		srv, err := NewServerBuilder().
+			WithHost("127.0.0.1").
+			WithPort(ports[0]).
+			WithHealthCheck(true).
+			WithService(&MockedService{}).
+			WithGateway(ports[1], registerGreeterGateway).
+			Build()
+		s.Require().NoError(err)
+		s.Require().NoError(srv.Start(ctx))
+
+		baseURL := fmt.Sprintf("http://127.0.0.1:%d", ports[1])
+		body, status := s.get(baseURL + "/v1/hello/gopack")
+		s.Assert().Equal(http.StatusOK, status)
+		s.Assert().JSONEq(`{"message":"This is a mocked service gopack"}`, body)
+
+		_, status = s.get(baseURL + "/healthz")
+		s.Assert().Equal(http.StatusOK, status)
+
+		s.Require().NoError(srv.Stop(ctx))
+		_, err = http.Get(baseURL + "/healthz") //nolint
+		s.Assert().Error(err)
+	})
+	s.Run("with failing registration", func() {
+		ports := dynaport.Get(2)
+		srv, err := NewServerBuilder().
+			WithHost("127.0.0.1").
+			WithPort(ports[0]).
+			WithGateway(ports[1], func(context.Context, *runtime.ServeMux, *grpc.ClientConn) error {
+				return fmt.Errorf("registration failure")
+			}).
+			Build()
+		s.Require().NoError(err)
+		s.Assert().ErrorContains(srv.Start(context.TODO()), "registration failure")
+	})
+	s.Run("without gateway port", func() {
+		srv, err := NewServerBuilder().
+			WithGatewayMuxOptions(runtime.WithHealthzEndpoint(nil)).
+			Build()
+		s.Assert().EqualError(err, "gateway port is not defined")
+		s.Assert().Nil(srv)
+	})
+}
+
+// get fetches the given url and returns the body and the status code
+func (s *gatewayTestSuite) get(url string) (string, int) {
+	resp, err := http.Get(url) //nolint
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	s.Require().NoError(err)
+	return string(body), resp.StatusCode
+}
@@ -0,0 +1,395 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package grpc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/tochemey/gopack/clock"
+)
+
+const (
+	// DefaultJWKSRefreshInterval is the default interval after which the JWKS keys are fetched again
+	DefaultJWKSRefreshInterval = time.Hour
+	// DefaultJWKSMinRefreshInterval is the default minimum interval between two JWKS fetches
+	// triggered by an unknown key ID or following a failed fetch
+	DefaultJWKSMinRefreshInterval = time.Minute
+	// DefaultJWKSFetchTimeout is the default timeout of a JWKS fetch
+	DefaultJWKSFetchTimeout = 10 * time.Second
+)
+
+// JWTValidator is a TokenValidator validating signed JWT
+type JWTValidator struct {
+	keyFunc func(ctx context.Context) jwt.Keyfunc
+	options []jwt.ParserOption
+	clock   clock.Clock
+}
+
+// enforce compilation error
+var _ TokenValidator = (*JWTValidator)(nil)
+
+// JWTOption configures the JWTValidator
+type JWTOption func(*JWTValidator)
+
+// WithJWTIssuer requires the tokens to be issued by the given issuer
+func WithJWTIssuer(issuer string) JWTOption {
+	return func(v *JWTValidator) {
+		v.options = append(v.options, jwt.WithIssuer(issuer))
+	}
+}
+
+// WithJWTAudience requires the tokens to be issued for the given audience
+func WithJWTAudience(audience string) JWTOption {
+	return func(v *JWTValidator) {
+		v.options = append(v.options, jwt.WithAudience(audience))
+	}
+}
+
+// WithJWTMethods sets the accepted signing algorithms, e.g. RS256
+func WithJWTMethods(methods ...string) JWTOption {
+	return func(v *JWTValidator) {
+		v.options = append(v.options, jwt.WithValidMethods(methods))
+	}
+}
+
+// WithJWTLeeway sets the leeway applied when validating the time based claims
+func WithJWTLeeway(leeway time.Duration) JWTOption {
+	return func(v *JWTValidator) {
+		v.options = append(v.options, jwt.WithLeeway(leeway))
+	}
+}
+
+// WithJWTClock sets the clock used to validate the time based claims.
+// This is mainly useful in tests with a clock.Fake
+func WithJWTClock(clock clock.Clock) JWTOption {
+	return func(v *JWTValidator) {
+		v.clock = clock
+	}
+}
+
+// NewJWTValidator creates a JWTValidator verifying the tokens signature with the key returned by keyFunc.
+// Use NewJWKSValidator to verify the tokens against a remote JSON Web Key Set.
+func NewJWTValidator(keyFunc jwt.Keyfunc, opts ...JWTOption) *JWTValidator {
+	return newJWTValidator(func(context.Context) jwt.Keyfunc { return keyFunc }, opts...)
+}
+
+// NewJWKSValidator creates a JWTValidator verifying the tokens signature with the keys of the JWKS.
+// The keys are fetched within the context of the validated call.
+func NewJWKSValidator(jwks *JWKS, opts ...JWTOption) *JWTValidator {
+	return newJWTValidator(jwks.KeyfuncWithContext, opts...)
+}
+
+// newJWTValidator creates a JWTValidator building the key function of every validated call
+func newJWTValidator(keyFunc func(ctx context.Context) jwt.Keyfunc, opts ...JWTOption) *JWTValidator {
+	validator := &JWTValidator{
+		keyFunc: keyFunc,
+		clock:   clock.New(),
+	}
+	for _, opt := range opts {
+		opt(validator)
+	}
+	return validator
+}
+
+// Validate parses and validates the token and returns its claims
+func (v *JWTValidator) Validate(ctx context.Context, token string) (Claims, error) {
+	options := append([]jwt.ParserOption{jwt.WithTimeFunc(v.clock.Now)}, v.options...)
+	claims := jwt.MapClaims{}
+	if _, err := jwt.ParseWithClaims(token, claims, v.keyFunc(ctx), options...); err != nil {
+		return nil, err
+	}
+	return Claims(claims), nil
+}
+
+// JWKS fetches and caches the public keys of a JSON Web Key Set endpoint.
+// The keys are fetched again after the refresh interval or when a token references an unknown key ID.
+// The cached keys are still served when a fetch fails.
+type JWKS struct {
+	url                string
+	httpClient         *http.Client
+	refreshInterval    time.Duration
+	minRefreshInterval time.Duration
+	fetchTimeout       time.Duration
+	clock              clock.Clock
+
+	mu        sync.Mutex
+	keys      map[string]any
+	fetchedAt time.Time
+	// attemptedAt is the time of the last fetch, successful or not
+	attemptedAt time.Time
+	// fetch is the fetch in progress, if any
+	fetch *jwksFetch
+}
+
+// jwksFetch is a fetch of the keys shared by the concurrent callers
+type jwksFetch struct {
+	done chan struct{}
+	err  error
+}
+
+// JWKSOption configures the JWKS
+type JWKSOption func(*JWKS)
+
+// WithJWKSHTTPClient sets the HTTP client used to fetch the keys
+func WithJWKSHTTPClient(client *http.Client) JWKSOption {
+	return func(j *JWKS) {
+		j.httpClient = client
+	}
+}
+
+// WithJWKSFetchTimeout sets the timeout of a keys fetch. The default is DefaultJWKSFetchTimeout.
+func WithJWKSFetchTimeout(timeout time.Duration) JWKSOption {
+	return func(j *JWKS) {
+		j.fetchTimeout = timeout
+	}
+}
+
+// WithJWKSRefreshInterval sets the interval after which the keys are fetched again
+func WithJWKSRefreshInterval(interval time.Duration) JWKSOption {
+	return func(j *JWKS) {
+		j.refreshInterval = interval
+	}
+}
+
+// WithJWKSClock sets the clock used to expire the cached keys.
+// This is mainly useful in tests with a clock.Fake
+func WithJWKSClock(clock clock.Clock) JWKSOption {
+	return func(j *JWKS) {
+		j.clock = clock
+	}
+}
+
+// NewJWKS creates a JWKS fetching the keys from the given URL
+func NewJWKS(url string, opts ...JWKSOption) *JWKS {
+	jwks := &JWKS{
+		url:                url,
+		httpClient:         http.DefaultClient,
+		refreshInterval:    DefaultJWKSRefreshInterval,
+		minRefreshInterval: DefaultJWKSMinRefreshInterval,
+		fetchTimeout:       DefaultJWKSFetchTimeout,
+		clock:              clock.New(),
+		keys:               make(map[string]any),
+	}
+	for _, opt := range opts {
+		opt(jwks)
+	}
+	return jwks
+}
+
+// Keyfunc returns the public key referenced by the token key ID. It implements jwt.Keyfunc.
+// Prefer KeyfuncWithContext to bound the wait for the keys by the context of the call.
+func (j *JWKS) Keyfunc(token *jwt.Token) (any, error) {
+	return j.KeyfuncWithContext(context.Background())(token)
+}
+
+// KeyfuncWithContext returns a jwt.Keyfunc waiting for the keys fetch, when needed, within the given context.
+// The fetch itself is not cancelled with the context so that one cancelled call does not fail the others.
+func (j *JWKS) KeyfuncWithContext(ctx context.Context) jwt.Keyfunc {
+	return func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			return nil, errors.New("token key ID is missing")
+		}
+		return j.key(ctx, kid)
+	}
+}
+
+// key returns the public key of the given key ID
+func (j *JWKS) key(ctx context.Context, kid string) (any, error) {
+	j.mu.Lock()
+	key, ok := j.keys[kid]
+	switch {
+	case ok && j.clock.Since(j.fetchedAt) < j.refreshInterval:
+		j.mu.Unlock()
+		return key, nil
+	case !j.attemptedAt.IsZero() && j.clock.Since(j.attemptedAt) < j.minRefreshInterval:
+		// avoid hammering the endpoint with unknown key IDs or while it is failing
+		j.mu.Unlock()
+		if ok {
+			return key, nil
+		}
+		return nil, fmt.Errorf("unknown key ID (%s)", kid)
+	}
+
+	// join the fetch in progress or start a new one
+	fetch := j.fetch
+	if fetch == nil {
+		fetch = &jwksFetch{done: make(chan struct{})}
+		j.fetch = fetch
+		go j.refresh(ctx, fetch)
+	}
+	j.mu.Unlock()
+
+	select {
+	case <-fetch.done:
+	case <-ctx.Done():
+		if ok {
+			return key, nil
+		}
+		return nil, ctx.Err()
+	}
+
+	j.mu.Lock()
+	key, ok = j.keys[kid]
+	j.mu.Unlock()
+	switch {
+	case ok:
+		// a failed fetch keeps serving the cached keys
+		return key, nil
+	case fetch.err != nil:
+		return nil, fetch.err
+	default:
+		return nil, fmt.Errorf("unknown key ID (%s)", kid)
+	}
+}
+
+// refresh fetches the keys outside the lock and records the outcome of the fetch.
+// The fetch is bounded by the fetch timeout and not by the cancellation of the caller's ctx.
+func (j *JWKS) refresh(ctx context.Context, fetch *jwksFetch) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), j.fetchTimeout)
+	defer cancel()
+	keys, err := j.fetchKeys(ctx)
+
+	j.mu.Lock()
+	now := j.clock.Now()
+	j.attemptedAt = now
+	if err == nil {
+		j.keys = keys
+		j.fetchedAt = now
+	}
+	j.fetch = nil
+	j.mu.Unlock()
+
+	fetch.err = err
+	close(fetch.done)
+}
+
+// jsonWebKey is a JSON Web Key as defined by RFC 7517
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// fetchKeys fetches the keys from the JWKS endpoint
+func (j *JWKS) fetchKeys(ctx context.Context) (map[string]any, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, j.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := j.httpClient.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the JWKS: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch the JWKS: unexpected status code %d", response.StatusCode)
+	}
+
+	var keySet struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&keySet); err != nil {
+		return nil, fmt.Errorf("failed to decode the JWKS: %w", err)
+	}
+
+	keys := make(map[string]any, len(keySet.Keys))
+	for _, jwk := range keySet.Keys {
+		key, err := jwk.publicKey()
+		if err != nil {
+			// skip the keys that are not supported
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	return keys, nil
+}
+
+// publicKey decodes the RSA or EC public key
+func (k jsonWebKey) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBase64BigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBase64BigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve (%s)", k.Crv)
+		}
+		x, err := decodeBase64BigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBase64BigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type (%s)", k.Kty)
+	}
+}
+
+// decodeBase64BigInt decodes a base64url encoded big integer
+func decodeBase64BigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(data), nil
+}
@@ -0,0 +1,172 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package grpc
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"github.com/tochemey/gopack/clock"
+)
+
+// defaultKeyedLimiterTTL is the default idle duration after which a client key is evicted
+const defaultKeyedLimiterTTL = 10 * time.Minute
+
+// KeyFunc extracts the client key of a request. It returns false when the request has no key.
+type KeyFunc func(ctx context.Context) (string, bool)
+
+// MetadataKeyFunc returns a KeyFunc using the value of the given incoming metadata, e.g. "x-api-key"
+func MetadataKeyFunc(name string) KeyFunc {
+	return func(ctx context.Context) (string, bool) {
+		values := metadata.ValueFromIncomingContext(ctx, name)
+		if len(values) == 0 || values[0] == "" {
+			return "", false
+		}
+		return values[0], true
+	}
+}
+
+// PeerIPKeyFunc returns a KeyFunc using the IP address of the client
+func PeerIPKeyFunc() KeyFunc {
+	return func(ctx context.Context) (string, bool) {
+		p, ok := peer.FromContext(ctx)
+		if !ok || p.Addr == nil {
+			return "", false
+		}
+		host, _, err := net.SplitHostPort(p.Addr.String())
+		if err != nil {
+			return p.Addr.String(), true
+		}
+		return host, true
+	}
+}
+
+// KeyedLimiter implements the Limiter interface with an independent rate limit per client key
+// so that one noisy client cannot exhaust the limit shared by the others.
+// The requests without a key share the same limit. The limit of a key that has not been
+// seen for the TTL is evicted.
+type KeyedLimiter struct {
+	keyFunc      KeyFunc
+	requestCount int
+	limitPeriod  time.Duration
+	ttl          time.Duration
+	clock        clock.Clock
+
+	mu        sync.Mutex
+	limiters  map[string]*keyedRateLimiter
+	lastSweep time.Time
+}
+
+// keyedRateLimiter is the rate limiter of a client key
+type keyedRateLimiter struct {
+	limiter  *RateLimiter
+	lastSeen time.Time
+}
+
+// enforce compilation error
+var _ Limiter = (*KeyedLimiter)(nil)
+
+// KeyedLimiterOption configures the KeyedLimiter
+type KeyedLimiterOption func(*KeyedLimiter)
+
+// WithKeyedLimiterTTL sets the idle duration after which the limit of a key is evicted.
+// The default TTL is 10 minutes.
+func WithKeyedLimiterTTL(ttl time.Duration) KeyedLimiterOption {
+	return func(l *KeyedLimiter) {
+		if ttl > 0 {
+			l.ttl = ttl
+		}
+	}
+}
+
+// WithKeyedLimiterClock sets the clock used to compute the delays and the keys idle duration.
+// This is mainly useful in tests with a clock.Fake
+func WithKeyedLimiterClock(clock clock.Clock) KeyedLimiterOption {
+	return func(l *KeyedLimiter) {
+		l.clock = clock
+	}
+}
+
+// NewKeyedLimiter creates a KeyedLimiter allowing requestCount requests per limitPeriod for every client key
+// extracted with keyFunc. See MetadataKeyFunc and PeerIPKeyFunc.
+func NewKeyedLimiter(keyFunc KeyFunc, requestCount int, limitPeriod time.Duration, opts ...KeyedLimiterOption) *KeyedLimiter {
+	limiter := &KeyedLimiter{
+		keyFunc:      keyFunc,
+		requestCount: requestCount,
+		limitPeriod:  limitPeriod,
+		ttl:          defaultKeyedLimiterTTL,
+		clock:        clock.New(),
+		limiters:     make(map[string]*keyedRateLimiter),
+	}
+	for _, opt := range opts {
+		opt(limiter)
+	}
+	limiter.lastSweep = limiter.clock.Now()
+	return limiter
+}
+
+// Check applies the rate limit of the client key of the given context
+func (l *KeyedLimiter) Check(ctx context.Context) bool {
+	key, _ := l.keyFunc(ctx)
+	return l.limiter(key).Check(ctx)
+}
+
+// Len returns the number of client keys being tracked
+func (l *KeyedLimiter) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.limiters)
+}
+
+// limiter returns the rate limiter of the given key and evicts the idle keys
+func (l *KeyedLimiter) limiter(key string) *RateLimiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.clock.Now()
+	if now.Sub(l.lastSweep) >= l.ttl {
+		for k, limiter := range l.limiters {
+			if now.Sub(limiter.lastSeen) >= l.ttl {
+				delete(l.limiters, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	limiter, ok := l.limiters[key]
+	if !ok {
+		limiter = &keyedRateLimiter{
+			limiter: NewRateLimiter(l.requestCount, l.limitPeriod, WithRateLimiterClock(l.clock)),
+		}
+		l.limiters[key] = limiter
+	}
+	limiter.lastSeen = now
+	return limiter.limiter
+}
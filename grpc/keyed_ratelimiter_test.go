@@ -0,0 +1,104 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package grpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"github.com/tochemey/gopack/clock"
+)
+
+// apiKeyContext returns an incoming context carrying the given api key
+func apiKeyContext(t *testing.T, apiKey string) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	t.Cleanup(cancel)
+	if apiKey == "" {
+		return ctx
+	}
+	return metadata.NewIncomingContext(ctx, metadata.Pairs("x-api-key", apiKey))
+}
+
+func TestKeyedLimiter(t *testing.T) {
+	t.Run("with independent keys", func(t *testing.T) {
+		fake := clock.NewFake(time.Now())
+		limiter := NewKeyedLimiter(MetadataKeyFunc("x-api-key"), 1, time.Hour, WithKeyedLimiterClock(fake))
+
+		assert.False(t, limiter.Check(apiKeyContext(t, "noisy")))
+		assert.True(t, limiter.Check(apiKeyContext(t, "noisy")))
+		assert.False(t, limiter.Check(apiKeyContext(t, "quiet")))
+
+		// the requests without a key share the same limit
+		assert.False(t, limiter.Check(apiKeyContext(t, "")))
+		assert.True(t, limiter.Check(apiKeyContext(t, "")))
+		assert.Equal(t, 3, limiter.Len())
+	})
+	t.Run("with idle keys evicted", func(t *testing.T) {
+		fake := clock.NewFake(time.Now())
+		limiter := NewKeyedLimiter(MetadataKeyFunc("x-api-key"), 1, time.Hour,
+			WithKeyedLimiterClock(fake),
+			WithKeyedLimiterTTL(time.Minute))
+
+		assert.False(t, limiter.Check(apiKeyContext(t, "first")))
+		fake.Advance(30 * time.Second)
+		assert.False(t, limiter.Check(apiKeyContext(t, "second")))
+		assert.Equal(t, 2, limiter.Len())
+
+		fake.Advance(45 * time.Second)
+		assert.False(t, limiter.Check(apiKeyContext(t, "third")))
+		assert.Equal(t, 2, limiter.Len())
+
+		// the evicted key starts with a fresh limit
+		assert.False(t, limiter.Check(apiKeyContext(t, "first")))
+	})
+	t.Run("with rate limit interceptor", func(t *testing.T) {
+		fake := clock.NewFake(time.Now())
+		interceptor := NewRateLimitUnaryServerInterceptor(
+			NewKeyedLimiter(MetadataKeyFunc("x-api-key"), 1, time.Hour, WithKeyedLimiterClock(fake)))
+		handler := func(context.Context, any) (any, error) { return "output", nil }
+
+		_, err := interceptor(apiKeyContext(t, "client"), nil, unaryInfo, handler)
+		require.NoError(t, err)
+		_, err = interceptor(apiKeyContext(t, "client"), nil, unaryInfo, handler)
+		assert.Error(t, err)
+	})
+}
+
+func TestPeerIPKeyFunc(t *testing.T) {
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 5000}})
+	key, ok := PeerIPKeyFunc()(ctx)
+	require.True(t, ok)
+	assert.Equal(t, "10.0.0.1", key)
+
+	_, ok = PeerIPKeyFunc()(context.Background())
+	assert.False(t, ok)
+}
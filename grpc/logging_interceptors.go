@@ -0,0 +1,204 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package grpc
+
+import (
+	"context"
+	"math/rand/v2"
+	"slices"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/tochemey/gopack/log"
+	"github.com/tochemey/gopack/requestid"
+)
+
+// the access log fields names
+const (
+	LogFieldMethod       = "method"
+	LogFieldPeer         = "peer"
+	LogFieldRequestID    = "request_id"
+	LogFieldCode         = "code"
+	LogFieldDuration     = "duration"
+	LogFieldRequestSize  = "request_size"
+	LogFieldResponseSize = "response_size"
+	LogFieldError        = "error"
+)
+
+// loggingConfig holds the logging interceptors settings
+type loggingConfig struct {
+	redactFields []string
+	sampleRate   float64
+}
+
+// LoggingOption configures the logging interceptors
+type LoggingOption func(*loggingConfig)
+
+// WithLoggingRedactFields sets the access log fields whose value is replaced by [REDACTED], e.g. LogFieldPeer
+func WithLoggingRedactFields(fields ...string) LoggingOption {
+	return func(c *loggingConfig) {
+		c.redactFields = append(c.redactFields, fields...)
+	}
+}
+
+// WithLoggingSampleRate sets the fraction, between 0 and 1, of successful calls that are logged.
+// Failed calls are always logged. The default rate is 1 which logs every call.
+func WithLoggingSampleRate(rate float64) LoggingOption {
+	return func(c *loggingConfig) {
+		c.sampleRate = min(max(rate, 0), 1)
+	}
+}
+
+// newLoggingConfig creates the logging config and applies the given options
+func newLoggingConfig(opts ...LoggingOption) *loggingConfig {
+	config := &loggingConfig{sampleRate: 1}
+	for _, opt := range opts {
+		opt(config)
+	}
+	return config
+}
+
+// NewLoggingUnaryServerInterceptor returns a unary server interceptor that writes an access log entry
+// for every call with the method, peer, request id, status code, duration and payload sizes.
+// It should be placed after the request id interceptor to log the request id.
+func NewLoggingUnaryServerInterceptor(logger log.Logger, opts ...LoggingOption) grpc.UnaryServerInterceptor {
+	config := newLoggingConfig(opts...)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		config.log(ctx, logger, info.FullMethod, time.Since(start), payloadSize(req), payloadSize(resp), err)
+		return resp, err
+	}
+}
+
+// NewLoggingStreamServerInterceptor returns a stream server interceptor that writes an access log entry
+// for every stream with the method, peer, request id, status code, duration and the total size of
+// the messages received and sent.
+func NewLoggingStreamServerInterceptor(logger log.Logger, opts ...LoggingOption) grpc.StreamServerInterceptor {
+	config := newLoggingConfig(opts...)
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		stream := &sizedServerStream{ServerStream: ss}
+		err := handler(srv, stream)
+		config.log(ss.Context(), logger, info.FullMethod, time.Since(start), stream.received, stream.sent, err)
+		return err
+	}
+}
+
+// log writes the access log entry of a call
+func (c *loggingConfig) log(ctx context.Context, logger log.Logger, method string, duration time.Duration, requestSize, responseSize int, err error) {
+	code := status.Code(err)
+	if code == codes.OK && c.sampleRate < 1 && rand.Float64() >= c.sampleRate {
+		return
+	}
+
+	remote := ""
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		remote = p.Addr.String()
+	}
+
+	fields := []log.Field{
+		log.String(LogFieldMethod, method),
+		log.String(LogFieldPeer, remote),
+		log.String(LogFieldRequestID, requestid.FromContext(ctx)),
+		log.String(LogFieldCode, code.String()),
+		log.Duration(LogFieldDuration, duration),
+		log.Int(LogFieldRequestSize, requestSize),
+		log.Int(LogFieldResponseSize, responseSize),
+	}
+	if err != nil {
+		fields = append(fields, log.String(LogFieldError, status.Convert(err).Message()))
+	}
+
+	keysAndValues := make([]any, 0, 2*len(fields))
+	for _, field := range fields {
+		value := field.Value
+		if slices.Contains(c.redactFields, field.Key) {
+			value = log.Redacted
+		}
+		keysAndValues = append(keysAndValues, field.Key, value)
+	}
+
+	switch logLevel(code) {
+	case log.InfoLevel:
+		logger.Infow("grpc access", keysAndValues...)
+	case log.WarningLevel:
+		logger.Warnw("grpc access", keysAndValues...)
+	default:
+		logger.Errorw("grpc access", keysAndValues...)
+	}
+}
+
+// logLevel returns the access log level of a status code.
+// The codes caused by the client are not logged as errors.
+func logLevel(code codes.Code) log.Level {
+	switch code {
+	case codes.OK, codes.Canceled, codes.InvalidArgument, codes.NotFound, codes.AlreadyExists, codes.Unauthenticated:
+		return log.InfoLevel
+	case codes.DeadlineExceeded, codes.PermissionDenied, codes.ResourceExhausted, codes.FailedPrecondition,
+		codes.Aborted, codes.OutOfRange:
+		return log.WarningLevel
+	default:
+		return log.ErrorLevel
+	}
+}
+
+// payloadSize returns the wire size of the given message
+func payloadSize(msg interface{}) int {
+	if message, ok := msg.(proto.Message); ok {
+		return proto.Size(message)
+	}
+	return 0
+}
+
+// sizedServerStream wraps a server stream and accumulates the size of the messages received and sent
+type sizedServerStream struct {
+	grpc.ServerStream
+	received int
+	sent     int
+}
+
+// SendMsg sends the message and records its size
+func (s *sizedServerStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.sent += payloadSize(m)
+	}
+	return err
+}
+
+// RecvMsg receives the message and records its size
+func (s *sizedServerStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.received += payloadSize(m)
+	}
+	return err
+}
@@ -0,0 +1,292 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/tochemey/gopack/log"
+	"github.com/tochemey/gopack/requestid"
+	testv1 "github.com/tochemey/gopack/test/data/test/v1"
+)
+
+// recordingLogger records the info, warning and error entries
+type recordingLogger struct {
+	log.Logger
+	mu     sync.Mutex
+	infos  []string
+	warns  []string
+	errors []string
+	fields []map[string]any
+}
+
+func (l *recordingLogger) Infow(msg string, keysAndValues ...any) {
+	l.Info(msg)
+	l.record(keysAndValues)
+}
+
+func (l *recordingLogger) Warnw(msg string, keysAndValues ...any) {
+	l.mu.Lock()
+	l.warns = append(l.warns, msg)
+	l.mu.Unlock()
+	l.record(keysAndValues)
+}
+
+func (l *recordingLogger) Errorw(msg string, keysAndValues ...any) {
+	l.Error(msg)
+	l.record(keysAndValues)
+}
+
+func (l *recordingLogger) record(keysAndValues []any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fields := make(map[string]any, len(keysAndValues)/2)
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		fields[keysAndValues[i].(string)] = keysAndValues[i+1]
+	}
+	l.fields = append(l.fields, fields)
+}
+
+func (l *recordingLogger) entryFields() []map[string]any {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.fields
+}
+
+func (l *recordingLogger) Info(v ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.infos = append(l.infos, v[0].(string))
+}
+
+func (l *recordingLogger) Error(v ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.errors = append(l.errors, v[0].(string))
+}
+
+func (l *recordingLogger) Errorf(format string, v ...any) {
+	l.Error(fmt.Sprintf(format, v...))
+}
+
+func (l *recordingLogger) entries() (infos, errs []string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.infos, l.errors
+}
+
+func (l *recordingLogger) warnings() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.warns
+}
+
+func TestLoggingUnaryServerInterceptor(t *testing.T) {
+	request := &testv1.HelloRequest{Name: "gopack"}
+	reply := &testv1.HelloReply{Message: "hello gopack"}
+	ctx := context.WithValue(context.Background(), requestid.XRequestIDKey{}, "request-1")
+	ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 5000}})
+
+	t.Run("with successful call", func(t *testing.T) {
+		logger := new(recordingLogger)
+		interceptor := NewLoggingUnaryServerInterceptor(logger)
+		_, err := interceptor(ctx, request, unaryInfo, func(context.Context, any) (any, error) {
+			return reply, nil
+		})
+		require.NoError(t, err)
+
+		infos, errs := logger.entries()
+		require.Len(t, infos, 1)
+		assert.Empty(t, errs)
+		assert.Equal(t, "grpc access", infos[0])
+		fields := logger.entryFields()[0]
+		assert.Equal(t, "TestService.UnaryMethod", fields[LogFieldMethod])
+		assert.Equal(t, "10.0.0.1:5000", fields[LogFieldPeer])
+		assert.Equal(t, "request-1", fields[LogFieldRequestID])
+		assert.Equal(t, "OK", fields[LogFieldCode])
+		assert.IsType(t, time.Duration(0), fields[LogFieldDuration])
+		assert.Equal(t, proto.Size(request), fields[LogFieldRequestSize])
+		assert.Equal(t, proto.Size(reply), fields[LogFieldResponseSize])
+	})
+	t.Run("with failed call", func(t *testing.T) {
+		logger := new(recordingLogger)
+		interceptor := NewLoggingUnaryServerInterceptor(logger, WithLoggingSampleRate(0))
+		_, err := interceptor(ctx, request, unaryInfo, func(context.Context, any) (any, error) {
+			return nil, status.Error(codes.Internal, "internal failure")
+		})
+		require.Error(t, err)
+
+		infos, errs := logger.entries()
+		assert.Empty(t, infos)
+		require.Len(t, errs, 1)
+		fields := logger.entryFields()[0]
+		assert.Equal(t, "Internal", fields[LogFieldCode])
+		assert.Equal(t, "internal failure", fields[LogFieldError])
+	})
+	t.Run("with client side failures", func(t *testing.T) {
+		logger := new(recordingLogger)
+		interceptor := NewLoggingUnaryServerInterceptor(logger, WithLoggingSampleRate(0))
+		for _, code := range []codes.Code{codes.NotFound, codes.InvalidArgument, codes.Canceled, codes.DeadlineExceeded} {
+			_, err := interceptor(ctx, request, unaryInfo, func(context.Context, any) (any, error) {
+				return nil, status.Error(code, code.String())
+			})
+			require.Error(t, err)
+		}
+
+		infos, errs := logger.entries()
+		// the failed calls are logged whatever the sample rate
+		assert.Len(t, infos, 3)
+		assert.Len(t, logger.warnings(), 1)
+		assert.Empty(t, errs)
+	})
+	t.Run("with sampling", func(t *testing.T) {
+		logger := new(recordingLogger)
+		interceptor := NewLoggingUnaryServerInterceptor(logger, WithLoggingSampleRate(0))
+		for range 10 {
+			_, err := interceptor(ctx, request, unaryInfo, func(context.Context, any) (any, error) {
+				return reply, nil
+			})
+			require.NoError(t, err)
+		}
+
+		infos, _ := logger.entries()
+		assert.Empty(t, infos)
+	})
+	t.Run("with redacted fields", func(t *testing.T) {
+		logger := new(recordingLogger)
+		interceptor := NewLoggingUnaryServerInterceptor(logger, WithLoggingRedactFields(LogFieldPeer, LogFieldRequestID))
+		_, err := interceptor(ctx, request, unaryInfo, func(context.Context, any) (any, error) {
+			return reply, nil
+		})
+		require.NoError(t, err)
+
+		infos, _ := logger.entries()
+		require.Len(t, infos, 1)
+		fields := logger.entryFields()[0]
+		assert.Equal(t, log.Redacted, fields[LogFieldPeer])
+		assert.Equal(t, log.Redacted, fields[LogFieldRequestID])
+	})
+}
+
+func TestLoggingStreamServerInterceptor(t *testing.T) {
+	logger := new(recordingLogger)
+	interceptor := NewLoggingStreamServerInterceptor(logger)
+	reply := &testv1.HelloReply{Message: "hello gopack"}
+	stream := &testServerStream{ctx: context.Background()}
+
+	err := interceptor(nil, stream, streamInfo, func(_ any, stream grpc.ServerStream) error {
+		if err := stream.SendMsg(reply); err != nil {
+			return err
+		}
+		if err := stream.SendMsg(reply); err != nil {
+			return err
+		}
+		return errors.New("stream failure")
+	})
+	require.Error(t, err)
+
+	infos, errs := logger.entries()
+	assert.Empty(t, infos)
+	require.Len(t, errs, 1)
+	fields := logger.entryFields()[0]
+	assert.Equal(t, "TestService.StreamMethod", fields[LogFieldMethod])
+	assert.Equal(t, "Unknown", fields[LogFieldCode])
+	assert.Equal(t, 2*proto.Size(reply), fields[LogFieldResponseSize])
+}
+
+func TestServerBuilderWithAccessLogging(t *testing.T) {
+	ctx := context.TODO()
+	logger := new(recordingLogger)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv, err := NewServerBuilder().
+		WithListener(listener).
+		WithAccessLogging(logger).
+		WithDefaultUnaryInterceptors().
+		WithService(&MockedService{}).
+		Build()
+	require.NoError(t, err)
+	require.NoError(t, srv.Start(ctx))
+
+	conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	callCtx := metadata.AppendToOutgoingContext(ctx, requestid.XRequestIDMetadataKey, "request-2")
+	_, err = testv1.NewGreeterClient(conn).SayHello(callCtx, &testv1.HelloRequest{Name: "test"})
+	require.NoError(t, err)
+	require.NoError(t, srv.Stop(ctx))
+
+	infos, _ := logger.entries()
+	require.Len(t, infos, 1)
+	fields := logger.entryFields()[0]
+	assert.Equal(t, testv1.Greeter_SayHello_FullMethodName, fields[LogFieldMethod])
+	assert.Equal(t, "request-2", fields[LogFieldRequestID])
+}
+
+func TestServerBuilderFromConfigWithAccessLogging(t *testing.T) {
+	ctx := context.TODO()
+	logger := new(recordingLogger)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	// the access logging is set after the default interceptors installed from the config
+	srv, err := NewServerBuilderFromConfig(&Config{ServiceName: "test"}).
+		WithListener(listener).
+		WithAccessLogging(logger).
+		WithService(&MockedService{}).
+		Build()
+	require.NoError(t, err)
+	require.NoError(t, srv.Start(ctx))
+
+	conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = testv1.NewGreeterClient(conn).SayHello(ctx, &testv1.HelloRequest{Name: "test"})
+	require.NoError(t, err)
+	require.NoError(t, srv.Stop(ctx))
+
+	infos, _ := logger.entries()
+	require.Len(t, infos, 1)
+	assert.Equal(t, testv1.Greeter_SayHello_FullMethodName, logger.entryFields()[0][LogFieldMethod])
+}
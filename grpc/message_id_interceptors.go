@@ -0,0 +1,179 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package grpc
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+)
+
+// MessageIDPropagator reads and writes the correlation id carried by the messages of a stream.
+// This allows every message exchanged on a long-lived stream to be traced independently
+// while the request id identifies the whole stream.
+type MessageIDPropagator interface {
+	// Extract returns the correlation id of the given message, if any
+	Extract(msg any) (string, bool)
+	// Inject sets the correlation id of the given message
+	Inject(msg any, id string)
+}
+
+// messageIDFuncs implements MessageIDPropagator for the messages of type T
+type messageIDFuncs[T any] struct {
+	get func(T) string
+	set func(T, string)
+}
+
+// NewMessageIDPropagator creates a MessageIDPropagator from the getter and setter of the correlation id
+// of the messages of type T, usually a pointer to a generated protobuf message.
+// The messages of any other type are ignored.
+func NewMessageIDPropagator[T any](get func(T) string, set func(T, string)) MessageIDPropagator {
+	return &messageIDFuncs[T]{get: get, set: set}
+}
+
+// Extract returns the correlation id of the given message
+func (f *messageIDFuncs[T]) Extract(msg any) (string, bool) {
+	typed, ok := msg.(T)
+	if !ok {
+		return "", false
+	}
+	id := f.get(typed)
+	return id, id != ""
+}
+
+// Inject sets the correlation id of the given message
+func (f *messageIDFuncs[T]) Inject(msg any, id string) {
+	if typed, ok := msg.(T); ok {
+		f.set(typed, id)
+	}
+}
+
+// messageIDKey is the context key of the current message id
+type messageIDKey struct{}
+
+// currentMessageID holds the correlation id of the last message received on a stream
+type currentMessageID struct {
+	mu sync.RWMutex
+	id string
+}
+
+func (c *currentMessageID) get() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.id
+}
+
+func (c *currentMessageID) set(id string) {
+	c.mu.Lock()
+	c.id = id
+	c.mu.Unlock()
+}
+
+// MessageIDFromContext returns the correlation id of the last message received on the stream
+// of the given context. The stream must be intercepted by NewMessageIDStreamServerInterceptor.
+func MessageIDFromContext(ctx context.Context) string {
+	current, ok := ctx.Value(messageIDKey{}).(*currentMessageID)
+	if !ok {
+		return ""
+	}
+	return current.get()
+}
+
+// NewMessageIDStreamServerInterceptor creates a stream server interceptor that propagates a correlation id per message.
+// Every received message without a correlation id is assigned a new one, and the id of the last received message
+// is available to the handler via MessageIDFromContext. Every sent message without a correlation id is assigned
+// the id of the last received message so that the replies can be correlated with their request.
+func NewMessageIDStreamServerInterceptor(propagator MessageIDPropagator) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		current := new(currentMessageID)
+		ctx := context.WithValue(ss.Context(), messageIDKey{}, current)
+		stream := &messageIDServerStream{
+			ServerStream: newServerStreamWithContext(ctx, ss),
+			propagator:   propagator,
+			current:      current,
+		}
+		return handler(srv, stream)
+	}
+}
+
+// NewMessageIDStreamClientInterceptor creates a stream client interceptor that assigns
+// a new correlation id to every sent message without one.
+func NewMessageIDStreamClientInterceptor(propagator MessageIDPropagator) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return &messageIDClientStream{ClientStream: stream, propagator: propagator}, nil
+	}
+}
+
+// messageIDServerStream propagates the correlation id of the messages of a server stream
+type messageIDServerStream struct {
+	grpc.ServerStream
+	propagator MessageIDPropagator
+	current    *currentMessageID
+}
+
+// RecvMsg receives a message and records its correlation id
+func (s *messageIDServerStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	id, ok := s.propagator.Extract(m)
+	if !ok {
+		id = uuid.NewString()
+		s.propagator.Inject(m, id)
+	}
+	s.current.set(id)
+	return nil
+}
+
+// SendMsg sets the correlation id of the message before sending it
+func (s *messageIDServerStream) SendMsg(m interface{}) error {
+	if _, ok := s.propagator.Extract(m); !ok {
+		if id := s.current.get(); id != "" {
+			s.propagator.Inject(m, id)
+		}
+	}
+	return s.ServerStream.SendMsg(m)
+}
+
+// messageIDClientStream sets the correlation id of the messages of a client stream
+type messageIDClientStream struct {
+	grpc.ClientStream
+	propagator MessageIDPropagator
+}
+
+// SendMsg sets the correlation id of the message before sending it
+func (s *messageIDClientStream) SendMsg(m interface{}) error {
+	if _, ok := s.propagator.Extract(m); !ok {
+		s.propagator.Inject(m, uuid.NewString())
+	}
+	return s.ClientStream.SendMsg(m)
+}
@@ -0,0 +1,130 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+// envelope is a stream message carrying a correlation id
+type envelope struct {
+	ID   string
+	Body string
+}
+
+var envelopePropagator = NewMessageIDPropagator(
+	func(e *envelope) string { return e.ID },
+	func(e *envelope, id string) { e.ID = id },
+)
+
+// scriptedServerStream replays the given messages and records the sent ones
+type scriptedServerStream struct {
+	testServerStream
+	received []*envelope
+	sent     []*envelope
+}
+
+func (s *scriptedServerStream) RecvMsg(m interface{}) error {
+	next := s.received[0]
+	s.received = s.received[1:]
+	*m.(*envelope) = *next
+	return nil
+}
+
+func (s *scriptedServerStream) SendMsg(m interface{}) error {
+	s.sent = append(s.sent, m.(*envelope))
+	return nil
+}
+
+// recordingClientStream records the sent messages
+type recordingClientStream struct {
+	grpc.ClientStream
+	sent []any
+}
+
+func (s *recordingClientStream) SendMsg(m interface{}) error {
+	s.sent = append(s.sent, m)
+	return nil
+}
+
+func TestMessageIDStreamServerInterceptor(t *testing.T) {
+	stream := &scriptedServerStream{
+		testServerStream: testServerStream{ctx: context.Background()},
+		received:         []*envelope{{ID: "message-1", Body: "first"}, {Body: "second"}},
+	}
+
+	var ids []string
+	interceptor := NewMessageIDStreamServerInterceptor(envelopePropagator)
+	err := interceptor(nil, stream, streamInfo, func(_ any, stream grpc.ServerStream) error {
+		for range 2 {
+			in := new(envelope)
+			if err := stream.RecvMsg(in); err != nil {
+				return err
+			}
+			ids = append(ids, MessageIDFromContext(stream.Context()))
+			if err := stream.SendMsg(&envelope{Body: "reply to " + in.Body}); err != nil {
+				return err
+			}
+		}
+		return stream.SendMsg(&envelope{ID: "explicit", Body: "done"})
+	})
+	require.NoError(t, err)
+
+	require.Len(t, ids, 2)
+	assert.Equal(t, "message-1", ids[0])
+	assert.NotEmpty(t, ids[1])
+	assert.NotEqual(t, ids[0], ids[1])
+
+	require.Len(t, stream.sent, 3)
+	assert.Equal(t, "message-1", stream.sent[0].ID)
+	assert.Equal(t, ids[1], stream.sent[1].ID)
+	assert.Equal(t, "explicit", stream.sent[2].ID)
+	assert.Empty(t, MessageIDFromContext(context.Background()))
+}
+
+func TestMessageIDStreamClientInterceptor(t *testing.T) {
+	clientStream := new(recordingClientStream)
+	interceptor := NewMessageIDStreamClientInterceptor(envelopePropagator)
+	stream, err := interceptor(context.Background(), &grpc.StreamDesc{}, nil, "/test.v1.Chat/Talk",
+		func(context.Context, *grpc.StreamDesc, *grpc.ClientConn, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+			return clientStream, nil
+		})
+	require.NoError(t, err)
+
+	require.NoError(t, stream.SendMsg(&envelope{Body: "first"}))
+	require.NoError(t, stream.SendMsg(&envelope{ID: "explicit", Body: "second"}))
+	require.NoError(t, stream.SendMsg("not an envelope"))
+
+	require.Len(t, clientStream.sent, 3)
+	first := clientStream.sent[0].(*envelope)
+	assert.NotEmpty(t, first.ID)
+	assert.Equal(t, "explicit", clientStream.sent[1].(*envelope).ID)
+	assert.Equal(t, "not an envelope", clientStream.sent[2])
+}
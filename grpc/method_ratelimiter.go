@@ -0,0 +1,128 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package grpc
+
+import (
+	"context"
+	"path"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+)
+
+// MethodRateLimiter implements the Limiter interface with an independent rate limit per method.
+// The limits are keyed by full method patterns such as "/pkg.Service/Method" or "/pkg.Service/*"
+// using the path.Match syntax. An exact match takes precedence over the patterns which are evaluated
+// in the order they have been set. Methods matching no pattern use the default limit.
+// The limits can be updated at runtime.
+type MethodRateLimiter struct {
+	mu             sync.RWMutex
+	limiters       map[string]*RateLimiter
+	patterns       []string
+	defaultLimiter *RateLimiter
+	opts           []RateLimiterOption
+}
+
+// enforce compilation error
+var _ Limiter = (*MethodRateLimiter)(nil)
+
+// NewMethodRateLimiter creates a MethodRateLimiter with the given default limit.
+// A requestCount less than or equal to zero means the methods matching no pattern are not limited.
+// The options are applied to every method rate limiter.
+func NewMethodRateLimiter(requestCount int, limitPeriod time.Duration, opts ...RateLimiterOption) *MethodRateLimiter {
+	limiter := &MethodRateLimiter{
+		limiters: make(map[string]*RateLimiter),
+		opts:     opts,
+	}
+	if requestCount > 0 {
+		limiter.defaultLimiter = NewRateLimiter(requestCount, limitPeriod, opts...)
+	}
+	return limiter
+}
+
+// SetLimit sets the limit of the methods matching the given pattern.
+// When the pattern already has a limit, it is updated in place.
+func (l *MethodRateLimiter) SetLimit(pattern string, requestCount int, limitPeriod time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if limiter, ok := l.limiters[pattern]; ok {
+		now := limiter.clock.Now()
+		limiter.ratelimiter.SetLimitAt(now, rate.Every(limitPeriod))
+		limiter.ratelimiter.SetBurstAt(now, requestCount)
+		return
+	}
+
+	l.limiters[pattern] = NewRateLimiter(requestCount, limitPeriod, l.opts...)
+	l.patterns = append(l.patterns, pattern)
+}
+
+// RemoveLimit removes the limit of the given pattern
+func (l *MethodRateLimiter) RemoveLimit(pattern string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.limiters[pattern]; !ok {
+		return
+	}
+
+	delete(l.limiters, pattern)
+	for i, p := range l.patterns {
+		if p == pattern {
+			l.patterns = append(l.patterns[:i], l.patterns[i+1:]...)
+			break
+		}
+	}
+}
+
+// Check applies the rate limit of the method of the given context.
+// The method is retrieved from the server context using grpc.Method.
+func (l *MethodRateLimiter) Check(ctx context.Context) bool {
+	method, _ := grpc.Method(ctx)
+	limiter := l.limiter(method)
+	if limiter == nil {
+		return false
+	}
+	return limiter.Check(ctx)
+}
+
+// limiter returns the rate limiter of the given method
+func (l *MethodRateLimiter) limiter(method string) *RateLimiter {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if limiter, ok := l.limiters[method]; ok {
+		return limiter
+	}
+
+	for _, pattern := range l.patterns {
+		if matched, _ := path.Match(pattern, method); matched {
+			return l.limiters[pattern]
+		}
+	}
+	return l.defaultLimiter
+}
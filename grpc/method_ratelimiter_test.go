@@ -0,0 +1,119 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// testTransportStream is used for unit test.
+// it implements the grpc.ServerTransportStream interface
+type testTransportStream struct {
+	method string
+}
+
+func (s *testTransportStream) Method() string               { return s.method }
+func (s *testTransportStream) SetHeader(metadata.MD) error  { return nil }
+func (s *testTransportStream) SendHeader(metadata.MD) error { return nil }
+func (s *testTransportStream) SetTrailer(metadata.MD) error { return nil }
+
+// methodContext returns a server context of the given method with a short deadline
+func methodContext(t *testing.T, method string) context.Context {
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	t.Cleanup(cancel)
+	return grpc.NewContextWithServerTransportStream(ctx, &testTransportStream{method: method})
+}
+
+func TestMethodRateLimiter(t *testing.T) {
+	t.Run("with per method limits", func(t *testing.T) {
+		limiter := NewMethodRateLimiter(0, time.Hour)
+		limiter.SetLimit("/test.v1.Greeter/SayHello", 1, time.Hour)
+
+		assert.False(t, limiter.Check(methodContext(t, "/test.v1.Greeter/SayHello")))
+		assert.True(t, limiter.Check(methodContext(t, "/test.v1.Greeter/SayHello")))
+		// the other methods are not limited
+		for i := 0; i < 3; i++ {
+			assert.False(t, limiter.Check(methodContext(t, "/test.v1.Greeter/SayBye")))
+		}
+	})
+	t.Run("with pattern", func(t *testing.T) {
+		limiter := NewMethodRateLimiter(0, time.Hour)
+		limiter.SetLimit("/test.v1.Greeter/*", 1, time.Hour)
+		limiter.SetLimit("/test.v1.Greeter/SayBye", 2, time.Hour)
+
+		assert.False(t, limiter.Check(methodContext(t, "/test.v1.Greeter/SayHello")))
+		assert.True(t, limiter.Check(methodContext(t, "/test.v1.Greeter/SayHi")))
+		// the exact match takes precedence over the pattern
+		assert.False(t, limiter.Check(methodContext(t, "/test.v1.Greeter/SayBye")))
+		assert.False(t, limiter.Check(methodContext(t, "/test.v1.Greeter/SayBye")))
+	})
+	t.Run("with default limit", func(t *testing.T) {
+		limiter := NewMethodRateLimiter(1, time.Hour)
+		assert.False(t, limiter.Check(methodContext(t, "/test.v1.Greeter/SayHello")))
+		assert.True(t, limiter.Check(methodContext(t, "/test.v1.Greeter/SayBye")))
+		// a context without method uses the default limit
+		assert.True(t, limiter.Check(methodContext(t, "")))
+	})
+	t.Run("with updated limit", func(t *testing.T) {
+		limiter := NewMethodRateLimiter(0, time.Hour)
+		limiter.SetLimit("/test.v1.Greeter/SayHello", 1, time.Hour)
+		assert.False(t, limiter.Check(methodContext(t, "/test.v1.Greeter/SayHello")))
+		assert.True(t, limiter.Check(methodContext(t, "/test.v1.Greeter/SayHello")))
+
+		limiter.SetLimit("/test.v1.Greeter/SayHello", 10, time.Millisecond)
+		assert.Eventually(t, func() bool {
+			return !limiter.Check(methodContext(t, "/test.v1.Greeter/SayHello"))
+		}, time.Second, 5*time.Millisecond)
+	})
+	t.Run("with removed limit", func(t *testing.T) {
+		limiter := NewMethodRateLimiter(0, time.Hour)
+		limiter.SetLimit("/test.v1.Greeter/*", 1, time.Hour)
+		limiter.RemoveLimit("/test.v1.Greeter/*")
+		limiter.RemoveLimit("/unknown")
+		for i := 0; i < 3; i++ {
+			assert.False(t, limiter.Check(methodContext(t, "/test.v1.Greeter/SayHello")))
+		}
+	})
+	t.Run("with unary server interceptor", func(t *testing.T) {
+		limiter := NewMethodRateLimiter(0, time.Hour)
+		limiter.SetLimit("/test.v1.Greeter/SayHello", 1, time.Hour)
+		interceptor := NewRateLimitUnaryServerInterceptor(limiter)
+		info := &grpc.UnaryServerInfo{FullMethod: "/test.v1.Greeter/SayHello"}
+		handler := func(context.Context, any) (any, error) { return "ok", nil }
+
+		_, err := interceptor(methodContext(t, info.FullMethod), nil, info, handler)
+		assert.NoError(t, err)
+		_, err = interceptor(methodContext(t, info.FullMethod), nil, info, handler)
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	})
+}
@@ -32,6 +32,8 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+
+	"github.com/tochemey/gopack/clock"
 )
 
 // Limiter defines the interface to perform request rate limiting.
@@ -44,23 +46,65 @@ type Limiter interface {
 // RateLimiter implements Limiter interface.
 type RateLimiter struct {
 	ratelimiter *rate.Limiter // nolint
+	clock       clock.Clock
+}
+
+// RateLimiterOption configures the RateLimiter
+type RateLimiterOption func(*RateLimiter)
+
+// WithRateLimiterClock sets the clock used by the RateLimiter to compute the delays.
+// This is mainly useful in tests with a clock.Fake
+func WithRateLimiterClock(clock clock.Clock) RateLimiterOption {
+	return func(l *RateLimiter) {
+		l.clock = clock
+	}
 }
 
 // Check applies the rate limit
 func (l *RateLimiter) Check(ctx context.Context) bool {
 	// This is a blocking call. Honors the rate limit
-	if err := l.ratelimiter.Wait(ctx); err != nil {
+	now := l.clock.Now()
+	reservation := l.ratelimiter.ReserveN(now, 1)
+	if !reservation.OK() {
 		// rate limit reached
 		return true
 	}
-	return false
+
+	delay := reservation.DelayFrom(now)
+	if delay == 0 {
+		return false
+	}
+
+	// the request cannot be served before the context deadline
+	if deadline, ok := ctx.Deadline(); ok && deadline.Sub(now) < delay {
+		reservation.CancelAt(now)
+		return true
+	}
+
+	timer := l.clock.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C():
+		return false
+	case <-ctx.Done():
+		// rate limit reached
+		reservation.CancelAt(l.clock.Now())
+		return true
+	}
 }
 
 // NewRateLimiter return new go-grpc Limiter, specified the number of requests you want to limit as well as the limit period.
-func NewRateLimiter(requestCount int, limitPeriod time.Duration) *RateLimiter {
-	return &RateLimiter{
+func NewRateLimiter(requestCount int, limitPeriod time.Duration, opts ...RateLimiterOption) *RateLimiter {
+	limiter := &RateLimiter{
 		ratelimiter: rate.NewLimiter(rate.Every(limitPeriod), requestCount),
+		clock:       clock.New(),
+	}
+
+	for _, opt := range opts {
+		opt(limiter)
 	}
+
+	return limiter
 }
 
 // NewRateLimitUnaryServerInterceptor returns a new unary server interceptors that performs request rate limiting.
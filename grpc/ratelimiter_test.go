@@ -35,6 +35,7 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/status"
 
+	"github.com/tochemey/gopack/clock"
 	testpb "github.com/tochemey/gopack/test/data/test/v1"
 )
 
@@ -250,3 +251,27 @@ func TestNewRateLimiter(t *testing.T) {
 	_, ok := iface.(Limiter)
 	assert.True(t, ok)
 }
+
+func TestRateLimiterCheck(t *testing.T) {
+	t.Run("with fake clock", func(t *testing.T) {
+		fake := clock.NewFake(time.Now())
+		limiter := NewRateLimiter(1, time.Minute, WithRateLimiterClock(fake))
+		ctx := context.Background()
+		// the first request consumes the burst
+		assert.False(t, limiter.Check(ctx))
+
+		// the second request waits for the next token
+		done := make(chan bool, 1)
+		go func() { done <- limiter.Check(ctx) }()
+		fake.BlockUntil(1)
+		fake.Advance(time.Minute)
+		assert.False(t, <-done)
+	})
+	t.Run("with context deadline shorter than the delay", func(t *testing.T) {
+		limiter := NewRateLimiter(1, time.Hour)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		assert.False(t, limiter.Check(ctx))
+		assert.True(t, limiter.Check(ctx))
+	})
+}
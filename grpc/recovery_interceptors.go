@@ -25,40 +25,104 @@
 package grpc
 
 import (
+	"context"
+	"runtime/debug"
+
 	grpcRecovery "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/recovery"
+	"github.com/prometheus/client_golang/prometheus"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+
+	"github.com/tochemey/gopack/log"
 )
 
-// NewRecoveryUnaryInterceptor recovers from an unexpected panic
-// Recovery handlers should typically be last in the chain so that other middleware
-// (e.g. logging) can operate on the recovered state instead of being directly affected by any panic
-func NewRecoveryUnaryInterceptor() grpc.UnaryServerInterceptor {
-	// Define custom func to handle panic
-	customFunc := func(p interface{}) (err error) {
-		return status.Errorf(codes.Unknown, "panic triggered: %v", p)
+// RecoveryHandler converts a recovered panic into the error returned to the client
+type RecoveryHandler func(ctx context.Context, p any) error
+
+// recoveryConfig holds the recovery interceptors settings
+type recoveryConfig struct {
+	handler    RecoveryHandler
+	counter    prometheus.Counter
+	logger     log.Logger
+	stackTrace bool
+}
+
+// RecoveryOption configures the recovery interceptors
+type RecoveryOption func(*recoveryConfig)
+
+// WithRecoveryHandler sets the handler converting a recovered panic into the error returned to the client.
+// By default, the client receives a codes.Unknown status.
+func WithRecoveryHandler(handler RecoveryHandler) RecoveryOption {
+	return func(c *recoveryConfig) {
+		c.handler = handler
 	}
+}
 
-	opts := []grpcRecovery.Option{
-		grpcRecovery.WithRecoveryHandler(customFunc),
+// WithRecoveryCounter sets the counter incremented on every recovered panic
+func WithRecoveryCounter(counter prometheus.Counter) RecoveryOption {
+	return func(c *recoveryConfig) {
+		c.counter = counter
 	}
+}
 
-	return grpcRecovery.UnaryServerInterceptor(opts...)
+// WithRecoveryLogger sets the logger used to log the recovered panics
+func WithRecoveryLogger(logger log.Logger) RecoveryOption {
+	return func(c *recoveryConfig) {
+		c.logger = logger
+	}
 }
 
-// NewRecoveryStreamInterceptor recovers from an unexpected panic
-// Recovery handlers should typically be last in the chain so that other middleware
-// (e.g. logging) can operate on the recovered state instead of being directly affected by any panic
-func NewRecoveryStreamInterceptor() grpc.StreamServerInterceptor {
-	// Define custom func to handle panic
-	customFunc := func(p interface{}) (err error) {
-		return status.Errorf(codes.Unknown, "panic triggered: %v", p)
+// WithRecoveryStackTrace includes the stack trace in the panics logs.
+// The stack trace is never sent to the client.
+func WithRecoveryStackTrace(enabled bool) RecoveryOption {
+	return func(c *recoveryConfig) {
+		c.stackTrace = enabled
 	}
+}
 
-	opts := []grpcRecovery.Option{
-		grpcRecovery.WithRecoveryHandler(customFunc),
+// newRecoveryConfig creates the recovery config and applies the given options
+func newRecoveryConfig(opts ...RecoveryOption) *recoveryConfig {
+	config := &recoveryConfig{
+		handler: func(_ context.Context, p any) error {
+			return status.Errorf(codes.Unknown, "panic triggered: %v", p)
+		},
+	}
+	for _, opt := range opts {
+		opt(config)
+	}
+	return config
+}
+
+// recover records the recovered panic and returns the error sent to the client
+func (c *recoveryConfig) recover(ctx context.Context, p any) error {
+	if c.counter != nil {
+		c.counter.Inc()
 	}
 
-	return grpcRecovery.StreamServerInterceptor(opts...)
+	if c.logger != nil {
+		method, _ := grpc.Method(ctx)
+		if c.stackTrace {
+			c.logger.Errorf("panic recovered: method=%s panic=%v\n%s", method, p, debug.Stack())
+		} else {
+			c.logger.Errorf("panic recovered: method=%s panic=%v", method, p)
+		}
+	}
+	return c.handler(ctx, p)
+}
+
+// NewRecoveryUnaryInterceptor recovers from an unexpected panic
+// Recovery handlers should typically be last in the chain so that other middleware
+// (e.g. logging) can operate on the recovered state instead of being directly affected by any panic
+func NewRecoveryUnaryInterceptor(opts ...RecoveryOption) grpc.UnaryServerInterceptor {
+	config := newRecoveryConfig(opts...)
+	return grpcRecovery.UnaryServerInterceptor(grpcRecovery.WithRecoveryHandlerContext(config.recover))
+}
+
+// NewRecoveryStreamInterceptor recovers from an unexpected panic
+// Recovery handlers should typically be last in the chain so that other middleware
+// (e.g. logging) can operate on the recovered state instead of being directly affected by any panic
+func NewRecoveryStreamInterceptor(opts ...RecoveryOption) grpc.StreamServerInterceptor {
+	config := newRecoveryConfig(opts...)
+	return grpcRecovery.StreamServerInterceptor(grpcRecovery.WithRecoveryHandlerContext(config.recover))
 }
@@ -0,0 +1,96 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func panicHandler(context.Context, any) (any, error) {
+	panic("boom")
+}
+
+func TestRecoveryUnaryInterceptor(t *testing.T) {
+	t.Run("with default handler", func(t *testing.T) {
+		_, err := NewRecoveryUnaryInterceptor()(context.Background(), nil, unaryInfo, panicHandler)
+		assert.Equal(t, codes.Unknown, status.Code(err))
+		assert.Equal(t, "panic triggered: boom", status.Convert(err).Message())
+	})
+	t.Run("with custom handler", func(t *testing.T) {
+		interceptor := NewRecoveryUnaryInterceptor(WithRecoveryHandler(func(_ context.Context, p any) error {
+			return status.Error(codes.Internal, "internal error")
+		}))
+		_, err := interceptor(context.Background(), nil, unaryInfo, panicHandler)
+		assert.Equal(t, codes.Internal, status.Code(err))
+		assert.Equal(t, "internal error", status.Convert(err).Message())
+	})
+	t.Run("with counter and logger", func(t *testing.T) {
+		counter := prometheus.NewCounter(prometheus.CounterOpts{Name: "grpc_server_panics_total"})
+		logger := new(recordingLogger)
+		interceptor := NewRecoveryUnaryInterceptor(
+			WithRecoveryCounter(counter),
+			WithRecoveryLogger(logger),
+			WithRecoveryStackTrace(true))
+
+		_, err := interceptor(context.Background(), nil, unaryInfo, panicHandler)
+		require.Error(t, err)
+		assert.NotContains(t, status.Convert(err).Message(), "goroutine")
+		assert.EqualValues(t, 1, testutil.ToFloat64(counter))
+
+		_, errs := logger.entries()
+		require.Len(t, errs, 1)
+		assert.Contains(t, errs[0], "panic recovered:")
+		assert.Contains(t, errs[0], "panic=boom")
+		assert.Contains(t, errs[0], "goroutine")
+	})
+	t.Run("without stack trace", func(t *testing.T) {
+		logger := new(recordingLogger)
+		_, err := NewRecoveryUnaryInterceptor(WithRecoveryLogger(logger))(context.Background(), nil, unaryInfo, panicHandler)
+		require.Error(t, err)
+
+		_, errs := logger.entries()
+		require.Len(t, errs, 1)
+		assert.NotContains(t, errs[0], "goroutine")
+	})
+}
+
+func TestRecoveryStreamInterceptor(t *testing.T) {
+	counter := prometheus.NewCounter(prometheus.CounterOpts{Name: "grpc_server_panics_total"})
+	interceptor := NewRecoveryStreamInterceptor(WithRecoveryCounter(counter))
+	err := interceptor(nil, &testServerStream{ctx: context.Background()}, streamInfo, func(any, grpc.ServerStream) error {
+		panic("boom")
+	})
+	assert.Equal(t, codes.Unknown, status.Code(err))
+	assert.EqualValues(t, 1, testutil.ToFloat64(counter))
+}
@@ -0,0 +1,174 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package grpc
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/tochemey/gopack/clock"
+)
+
+const (
+	// DefaultRetryMaxAttempts is the default number of attempts, the first call included
+	DefaultRetryMaxAttempts = 3
+	// DefaultRetryInitialInterval is the default delay before the first retry
+	DefaultRetryInitialInterval = 100 * time.Millisecond
+	// DefaultRetryMaxInterval is the default maximum delay between two retries
+	DefaultRetryMaxInterval = 5 * time.Second
+	// DefaultRetryJitter is the default randomization factor applied to the retry delays
+	DefaultRetryJitter = 0.5
+)
+
+// retryConfig holds the retry interceptors settings
+type retryConfig struct {
+	maxAttempts int
+	codes       []codes.Code
+	newBackOff  func() backoff.BackOff
+	clock       clock.Clock
+}
+
+// RetryOption configures the retry interceptors
+type RetryOption func(*retryConfig)
+
+// WithRetryMaxAttempts sets the maximum number of attempts, the first call included
+func WithRetryMaxAttempts(maxAttempts int) RetryOption {
+	return func(c *retryConfig) {
+		c.maxAttempts = maxAttempts
+	}
+}
+
+// WithRetryCodes sets the status codes on which a call is retried.
+// The default codes are codes.Unavailable and codes.ResourceExhausted
+func WithRetryCodes(retryCodes ...codes.Code) RetryOption {
+	return func(c *retryConfig) {
+		c.codes = retryCodes
+	}
+}
+
+// WithRetryBackOff sets the backoff strategy computing the delay between the attempts.
+// newBackOff is called for every call because a backoff is stateful. A backoff returning
+// backoff.Stop ends the retries. The default strategy is an exponential backoff with jitter,
+// see NewRetryExponentialBackOff
+func WithRetryBackOff(newBackOff func() backoff.BackOff) RetryOption {
+	return func(c *retryConfig) {
+		c.newBackOff = newBackOff
+	}
+}
+
+// WithRetryClock sets the clock used to wait between the attempts.
+// This is mainly useful in tests with a clock.Fake
+func WithRetryClock(clock clock.Clock) RetryOption {
+	return func(c *retryConfig) {
+		c.clock = clock
+	}
+}
+
+// NewRetryExponentialBackOff creates an exponential backoff. jitter is the randomization factor
+// applied to every delay, zero meaning no jitter.
+func NewRetryExponentialBackOff(initialInterval, maxInterval time.Duration, jitter float64) backoff.BackOff {
+	bo := backoff.NewExponentialBackOff()
+	bo.InitialInterval = initialInterval
+	bo.MaxInterval = maxInterval
+	bo.RandomizationFactor = jitter
+	bo.MaxElapsedTime = 0
+	bo.Reset()
+	return bo
+}
+
+// newRetryConfig creates the default retry config and applies the given options
+func newRetryConfig(opts ...RetryOption) *retryConfig {
+	config := &retryConfig{
+		maxAttempts: DefaultRetryMaxAttempts,
+		codes:       []codes.Code{codes.Unavailable, codes.ResourceExhausted},
+		newBackOff: func() backoff.BackOff {
+			return NewRetryExponentialBackOff(DefaultRetryInitialInterval, DefaultRetryMaxInterval, DefaultRetryJitter)
+		},
+		clock: clock.New(),
+	}
+	for _, opt := range opts {
+		opt(config)
+	}
+	return config
+}
+
+// NewRetryUnaryClientInterceptor returns a client unary interceptor that retries the failed calls
+// with a retryable status code. Add it after WithDefaultUnaryInterceptors so that all the attempts
+// share the same request ID.
+func NewRetryUnaryClientInterceptor(opts ...RetryOption) grpc.UnaryClientInterceptor {
+	config := newRetryConfig(opts...)
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return config.retry(ctx, func() error {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		})
+	}
+}
+
+// NewRetryStreamClientInterceptor returns a client stream interceptor that retries the creation
+// of the stream when it fails with a retryable status code. Errors occurring once the stream
+// is established are not retried.
+func NewRetryStreamClientInterceptor(opts ...RetryOption) grpc.StreamClientInterceptor {
+	config := newRetryConfig(opts...)
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		var stream grpc.ClientStream
+		err := config.retry(ctx, func() error {
+			var err error
+			stream, err = streamer(ctx, desc, cc, method, opts...)
+			return err
+		})
+		return stream, err
+	}
+}
+
+// retry runs the call until it succeeds, fails with a non-retryable error
+// or the maximum number of attempts is reached
+func (c *retryConfig) retry(ctx context.Context, call func() error) error {
+	bo := c.newBackOff()
+	for attempt := 1; ; attempt++ {
+		err := call()
+		if err == nil || attempt >= c.maxAttempts || !slices.Contains(c.codes, status.Code(err)) {
+			return err
+		}
+
+		delay := bo.NextBackOff()
+		if delay == backoff.Stop {
+			return err
+		}
+
+		timer := c.clock.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C():
+		}
+	}
+}
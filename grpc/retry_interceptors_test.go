@@ -0,0 +1,153 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/tochemey/gopack/clock"
+)
+
+// noBackOff retries immediately
+func noBackOff() backoff.BackOff {
+	return &backoff.ZeroBackOff{}
+}
+
+// failingInvoker returns an invoker failing with the given code for the given number of calls
+func failingInvoker(code codes.Code, failures int, calls *int) grpc.UnaryInvoker {
+	return func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+		*calls++
+		if *calls <= failures {
+			return status.Error(code, "failure")
+		}
+		return nil
+	}
+}
+
+func TestNewRetryUnaryClientInterceptor(t *testing.T) {
+	t.Run("with retryable error", func(t *testing.T) {
+		interceptor := NewRetryUnaryClientInterceptor(WithRetryBackOff(noBackOff))
+		calls := 0
+		err := interceptor(context.TODO(), "/test", nil, nil, nil, failingInvoker(codes.Unavailable, 2, &calls))
+		assert.NoError(t, err)
+		assert.Equal(t, 3, calls)
+	})
+	t.Run("with max attempts reached", func(t *testing.T) {
+		interceptor := NewRetryUnaryClientInterceptor(WithRetryBackOff(noBackOff), WithRetryMaxAttempts(2))
+		calls := 0
+		err := interceptor(context.TODO(), "/test", nil, nil, nil, failingInvoker(codes.ResourceExhausted, 5, &calls))
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+		assert.Equal(t, 2, calls)
+	})
+	t.Run("with non retryable error", func(t *testing.T) {
+		interceptor := NewRetryUnaryClientInterceptor(WithRetryBackOff(noBackOff))
+		calls := 0
+		err := interceptor(context.TODO(), "/test", nil, nil, nil, failingInvoker(codes.InvalidArgument, 5, &calls))
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Equal(t, 1, calls)
+	})
+	t.Run("with custom retry codes", func(t *testing.T) {
+		interceptor := NewRetryUnaryClientInterceptor(WithRetryBackOff(noBackOff), WithRetryCodes(codes.Aborted))
+		calls := 0
+		err := interceptor(context.TODO(), "/test", nil, nil, nil, failingInvoker(codes.Aborted, 1, &calls))
+		assert.NoError(t, err)
+		assert.Equal(t, 2, calls)
+	})
+	t.Run("with backoff delay", func(t *testing.T) {
+		fake := clock.NewFake(time.Now())
+		interceptor := NewRetryUnaryClientInterceptor(
+			WithRetryClock(fake),
+			WithRetryBackOff(func() backoff.BackOff { return backoff.NewConstantBackOff(time.Second) }))
+
+		calls := 0
+		done := make(chan error, 1)
+		go func() {
+			done <- interceptor(context.TODO(), "/test", nil, nil, nil, failingInvoker(codes.Unavailable, 1, &calls))
+		}()
+
+		// the second attempt waits for the backoff delay
+		fake.BlockUntil(1)
+		fake.Advance(time.Second)
+		require.NoError(t, <-done)
+		assert.Equal(t, 2, calls)
+	})
+	t.Run("with context cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.TODO())
+		interceptor := NewRetryUnaryClientInterceptor(
+			WithRetryBackOff(func() backoff.BackOff { return backoff.NewConstantBackOff(time.Hour) }))
+
+		calls := 0
+		invoker := func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+			calls++
+			cancel()
+			return status.Error(codes.Unavailable, "failure")
+		}
+		err := interceptor(ctx, "/test", nil, nil, nil, invoker)
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+		assert.Equal(t, 1, calls)
+	})
+	t.Run("with backoff stop", func(t *testing.T) {
+		interceptor := NewRetryUnaryClientInterceptor(
+			WithRetryBackOff(func() backoff.BackOff { return &backoff.StopBackOff{} }))
+		calls := 0
+		err := interceptor(context.TODO(), "/test", nil, nil, nil, failingInvoker(codes.Unavailable, 5, &calls))
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestNewRetryStreamClientInterceptor(t *testing.T) {
+	interceptor := NewRetryStreamClientInterceptor(WithRetryBackOff(noBackOff))
+	calls := 0
+	streamer := func(context.Context, *grpc.StreamDesc, *grpc.ClientConn, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+		calls++
+		if calls == 1 {
+			return nil, status.Error(codes.Unavailable, "failure")
+		}
+		return nil, nil
+	}
+
+	_, err := interceptor(context.TODO(), &grpc.StreamDesc{}, nil, "/test", streamer)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestNewRetryExponentialBackOff(t *testing.T) {
+	bo := NewRetryExponentialBackOff(10*time.Millisecond, 40*time.Millisecond, 0)
+	assert.Equal(t, 10*time.Millisecond, bo.NextBackOff())
+	assert.Equal(t, 15*time.Millisecond, bo.NextBackOff())
+	assert.Equal(t, 22500*time.Microsecond, bo.NextBackOff())
+	assert.Equal(t, 33750*time.Microsecond, bo.NextBackOff())
+	assert.Equal(t, 40*time.Millisecond, bo.NextBackOff())
+}
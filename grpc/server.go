@@ -26,24 +26,37 @@ package grpc
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net"
 	"os"
 	"os/signal"
 	"runtime"
 	"syscall"
+	"time"
 
 	grpcPrometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
 
 	"github.com/tochemey/gopack/errorschain"
-	"github.com/tochemey/gopack/otel/metric"
-	"github.com/tochemey/gopack/otel/trace"
+	"github.com/tochemey/gopack/otel"
+	"github.com/tochemey/gopack/otel/metricserver"
 )
 
+// ErrForcedShutdown is returned by Stop when the pending RPCs have not completed within the
+// shutdown timeout and the server has been forcibly stopped
+var ErrForcedShutdown = errors.New("grpc server has been forcibly stopped after the shutdown timeout")
+
 // ShutdownHook is used to perform some cleaning before stopping
 // the long-running grpcServer
 type ShutdownHook func(ctx context.Context) error
 
+// ReadinessCheck reports whether the application is ready to serve requests,
+// e.g. whether its dependencies are reachable. It returns an error when not ready.
+type ReadinessCheck func(ctx context.Context) error
+
 // Server will be implemented by the grpcServer
 type Server interface {
 	Start(ctx context.Context) error
@@ -51,6 +64,9 @@ type Server interface {
 	AwaitTermination(ctx context.Context)
 	GetListener() net.Listener
 	GetServer() *grpc.Server
+	// SetServingStatus sets the health status of the given service. The empty service
+	// is the overall server health. It is a no-op when the health check is not enabled.
+	SetServingStatus(service string, status grpc_health_v1.HealthCheckResponse_ServingStatus)
 }
 
 // serviceRegistry.RegisterService will be implemented by any grpc service
@@ -60,13 +76,22 @@ type serviceRegistry interface {
 
 type grpcServer struct {
 	addr     string
+	network  string
 	server   *grpc.Server
 	listener net.Listener
 
-	traceProvider  *trace.Provider
-	metricProvider *metric.Provider
+	telemetry         *otel.Config
+	shutdownTelemetry otel.Shutdown
+	metricServer      *metricserver.Server
+
+	shutdownHook    ShutdownHook
+	shutdownTimeout time.Duration
+	gateway         *gateway
 
-	shutdownHook ShutdownHook
+	healthServer      *health.Server
+	readinessCheck    ReadinessCheck
+	readinessInterval time.Duration
+	stopReadiness     context.CancelFunc
 }
 
 var _ Server = (*grpcServer)(nil)
@@ -78,47 +103,82 @@ func (s *grpcServer) GetServer() *grpc.Server {
 	return s.server
 }
 
-// GetListener returns the underlying tcp listener
+// GetListener returns the underlying listener
 func (s *grpcServer) GetListener() net.Listener {
 	return s.listener
 }
 
+// SetServingStatus sets the health status of the given service. The empty service
+// is the overall server health. It is a no-op when the health check is not enabled.
+func (s *grpcServer) SetServingStatus(service string, status grpc_health_v1.HealthCheckResponse_ServingStatus) {
+	if s.healthServer != nil {
+		s.healthServer.SetServingStatus(service, status)
+	}
+}
+
 // Start the GRPC server and listen to incoming connections.
 func (s *grpcServer) Start(ctx context.Context) error {
-	// start the metrics
-	if s.metricProvider != nil {
-		// let us register the metrics
-		grpcPrometheus.Register(s.GetServer())
-		// starts the metrics exporter
-		if err := s.metricProvider.Start(ctx); err != nil {
+	// start the traces and metrics exporters
+	if s.telemetry != nil {
+		if s.telemetry.MetricEndpoint != "" {
+			// let us register the metrics
+			grpcPrometheus.Register(s.GetServer())
+		}
+
+		shutdown, err := otel.Setup(ctx, s.telemetry)
+		if err != nil {
 			return err
 		}
+		s.shutdownTelemetry = shutdown
 	}
 
-	// let us register the tracer
-	if s.traceProvider != nil {
-		err := s.traceProvider.Start(ctx)
+	// listen unless a listener has been provided
+	if s.listener == nil {
+		listener, err := listen(s.network, s.addr)
 		if err != nil {
 			return err
 		}
+		s.listener = listener
 	}
 
-	var err error
-	s.listener, err = net.Listen("tcp", s.addr)
-	if err != nil {
-		return err
+	go s.serv()
+
+	// start the gateway
+	if s.gateway != nil {
+		if err := s.gateway.start(ctx, dialTarget(s.listener.Addr())); err != nil {
+			s.server.Stop()
+			return err
+		}
 	}
 
-	go s.serv()
+	// start the metrics endpoint
+	if s.metricServer != nil {
+		grpcPrometheus.Register(s.GetServer())
+		if err := s.metricServer.Start(ctx); err != nil {
+			s.server.Stop()
+			return err
+		}
+	}
+
+	// start the readiness check
+	if s.healthServer != nil && s.readinessCheck != nil {
+		readinessCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		s.stopReadiness = cancel
+		s.checkReadiness(readinessCtx)
+		go s.watchReadiness(readinessCtx)
+	}
 	return nil
 }
 
 // Stop will shut down gracefully the running service.
 // This is very useful when one wants to control the shutdown
 // without waiting for an OS signal. For a long-running process, kindly use
-// AwaitTermination after Start
+// AwaitTermination after Start.
+// It returns ErrForcedShutdown when the pending RPCs did not complete within the shutdown timeout
+// or before the given context is done and the server has been forcibly stopped.
 func (s *grpcServer) Stop(ctx context.Context) error {
-	if err := s.cleanup(ctx); err != nil {
+	forced, err := s.drain(ctx)
+	if err != nil {
 		return err
 	}
 	if s.shutdownHook != nil {
@@ -127,6 +187,9 @@ func (s *grpcServer) Stop(ctx context.Context) error {
 			return err
 		}
 	}
+	if forced {
+		return ErrForcedShutdown
+	}
 	return nil
 }
 
@@ -167,6 +230,51 @@ func (s *grpcServer) AwaitTermination(ctx context.Context) {
 	}
 }
 
+// listen announces on the given network address.
+// A stale unix socket file left by a previous run is removed before listening.
+func listen(network, addr string) (net.Listener, error) {
+	if network == "unix" {
+		if info, err := os.Stat(addr); err == nil && info.Mode()&os.ModeSocket != 0 {
+			if err := os.Remove(addr); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return net.Listen(network, addr)
+}
+
+// dialTarget returns the gRPC dial target of the given listener address
+func dialTarget(addr net.Addr) string {
+	if addr.Network() == "unix" {
+		return "unix:" + addr.String()
+	}
+	return addr.String()
+}
+
+// watchReadiness runs the readiness check periodically until the given context is done
+func (s *grpcServer) watchReadiness(ctx context.Context) {
+	ticker := time.NewTicker(s.readinessInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.checkReadiness(ctx)
+		}
+	}
+}
+
+// checkReadiness sets the overall server health according to the readiness check
+func (s *grpcServer) checkReadiness(ctx context.Context) {
+	status := grpc_health_v1.HealthCheckResponse_SERVING
+	if err := s.readinessCheck(ctx); err != nil {
+		status = grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	}
+	// the health server ignores the updates once shut down
+	s.healthServer.SetServingStatus("", status)
+}
+
 // serv makes the grpc listener ready to accept connections
 func (s *grpcServer) serv() {
 	if err := s.server.Serve(s.listener); err != nil {
@@ -178,20 +286,93 @@ func (s *grpcServer) serv() {
 // It stops the server from accepting new connections and RPCs and blocks until all the pending RPCs are
 // finished and closes the underlying listener.
 func (s *grpcServer) cleanup(ctx context.Context) error {
-	// stop the metrics grpcServer
-	if s.metricProvider != nil {
-		if err := s.metricProvider.Stop(ctx); err != nil {
-			return err
+	_, err := s.drain(ctx)
+	return err
+}
+
+// drain sets the health status to NOT_SERVING, stops the OTLP tracer and the metrics server and gracefully shutdowns the grpc server.
+// The server is forcibly stopped when the pending RPCs have not completed within the shutdown timeout
+// or before the context is done. It returns true when the server has been forcibly stopped.
+func (s *grpcServer) drain(ctx context.Context) (bool, error) {
+	// report the services as not serving so that the clients stop sending new requests
+	if s.healthServer != nil {
+		if s.stopReadiness != nil {
+			s.stopReadiness()
 		}
+		s.healthServer.Shutdown()
 	}
 
-	// stop the tracing service
-	if s.traceProvider != nil {
-		err := s.traceProvider.Stop(ctx)
+	// stop the traces and metrics exporters
+	if s.shutdownTelemetry != nil {
+		if err := s.shutdownTelemetry(ctx); err != nil {
+			return false, err
+		}
+	}
+
+	// stop the gateway before the grpc server to drain the in-flight REST requests
+	if s.gateway != nil {
+		gatewayCtx, cancel := ctx, context.CancelFunc(func() {})
+		if s.shutdownTimeout > 0 {
+			gatewayCtx, cancel = context.WithTimeout(ctx, s.shutdownTimeout)
+		}
+		err := s.gateway.stop(gatewayCtx)
+		cancel()
 		if err != nil {
-			return err
+			return false, err
 		}
 	}
-	s.server.GracefulStop()
+
+	done := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(done)
+	}()
+
+	// a nil channel blocks forever which disables the shutdown timeout
+	var timeout <-chan time.Time
+	if s.shutdownTimeout > 0 {
+		timer := time.NewTimer(s.shutdownTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	forced := false
+	select {
+	case <-done:
+	case <-timeout:
+		forced = true
+	case <-ctx.Done():
+		forced = true
+	}
+
+	if forced {
+		// the pending RPCs are cancelled
+		s.server.Stop()
+		<-done
+	}
+
+	// stop the metrics endpoint last so that the metrics can be scraped while draining
+	if s.metricServer != nil {
+		if err := s.metricServer.Stop(ctx); err != nil {
+			return forced, err
+		}
+	}
+	return forced, nil
+}
+
+// healthz reports whether the server is serving according to its health service.
+// The server is always healthy when the health check is not enabled.
+func (s *grpcServer) healthz(ctx context.Context) error {
+	if s.healthServer == nil {
+		return nil
+	}
+
+	resp, err := s.healthServer.Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		return err
+	}
+	if resp.GetStatus() != grpc_health_v1.HealthCheckResponse_SERVING {
+		return fmt.Errorf("grpc server is %s", resp.GetStatus())
+	}
 	return nil
 }
@@ -26,9 +26,10 @@ package grpc
 
 import (
 	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
-	"log"
+	"net"
 	"sync"
 	"time"
 
@@ -39,7 +40,11 @@ import (
 	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/reflection"
 
-	"github.com/tochemey/gopack/otel/trace"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+
+	"github.com/tochemey/gopack/log"
+	"github.com/tochemey/gopack/otel"
+	"github.com/tochemey/gopack/otel/metricserver"
 )
 
 const (
@@ -54,12 +59,15 @@ const (
 
 	// default grpc port
 	defaultGrpcPort = 50051
+	// defaultReadinessInterval is the interval between the readiness checks when not set
+	defaultReadinessInterval = 10 * time.Second
 )
 
 var (
 	errMissingTraceURL         = errors.New("trace URL is not defined")
 	errMissingServiceName      = errors.New("service name is not defined")
 	errMsgCannotUseSameBuilder = errors.New("cannot use the same builder to build more than once")
+	errMissingGatewayPort      = errors.New("gateway port is not defined")
 )
 
 // ServerBuilder helps build a grpc grpcServer
@@ -69,19 +77,39 @@ type ServerBuilder struct {
 	enableReflection  bool
 	enableHealthCheck bool
 	metricsEnabled    bool
+	metricsPort       int
+	maxRecvMsgSize    int
+	maxSendMsgSize    int
 	tracingEnabled    bool
 	serviceName       string
 	grpcPort          int
 	grpcHost          string
 	traceURL          string
+	telemetry         *otel.Config
 	logger            log.Logger
-
-	shutdownHook ShutdownHook
-	isBuilt      bool
+	loggingOptions    []LoggingOption
+	mutualTLSFiles    *mutualTLSFiles
+	gateway           *gateway
+	listener          net.Listener
+	unixSocket        string
+
+	shutdownHook      ShutdownHook
+	shutdownTimeout   time.Duration
+	readinessCheck    ReadinessCheck
+	readinessInterval time.Duration
+	isBuilt           bool
 
 	rwMutex *sync.RWMutex
 }
 
+// mutualTLSFiles holds the PEM files used to build the mutual TLS credentials
+type mutualTLSFiles struct {
+	certFile     string
+	keyFile      string
+	clientCAFile string
+	options      []TLSOption
+}
+
 // NewServerBuilder creates an instance of ServerBuilder
 func NewServerBuilder() *ServerBuilder {
 	return &ServerBuilder{
@@ -94,7 +122,7 @@ func NewServerBuilder() *ServerBuilder {
 // NewServerBuilderFromConfig returns a grpcserver.ServerBuilder given a grpc config
 func NewServerBuilderFromConfig(cfg *Config) *ServerBuilder {
 	// build the grpc server
-	return NewServerBuilder().
+	builder := NewServerBuilder().
 		WithReflection(cfg.EnableReflection).
 		WithDefaultUnaryInterceptors().
 		WithDefaultStreamInterceptors().
@@ -103,6 +131,17 @@ func NewServerBuilderFromConfig(cfg *Config) *ServerBuilder {
 		WithServiceName(cfg.ServiceName).
 		WithPort(int(cfg.GrpcPort)).
 		WithHost(cfg.GrpcHost)
+
+	// serve the metrics when enabled
+	if cfg.MetricsEnabled && cfg.MetricsPort > 0 {
+		builder.WithMetricsPort(cfg.MetricsPort)
+	}
+
+	// enable mutual TLS when the certificates are provided
+	if cfg.TLSCertFile != "" && cfg.TLSKeyFile != "" && cfg.TLSClientCAFile != "" {
+		builder.WithMutualTLSFiles(cfg.TLSCertFile, cfg.TLSKeyFile, cfg.TLSClientCAFile)
+	}
+	return builder
 }
 
 // WithShutdownHook sets the shutdown hook
@@ -111,6 +150,14 @@ func (sb *ServerBuilder) WithShutdownHook(fn ShutdownHook) *ServerBuilder {
 	return sb
 }
 
+// WithShutdownTimeout sets the maximum duration to wait for the pending RPCs to complete on shutdown.
+// Once elapsed the pending RPCs are cancelled and the server is forcibly stopped.
+// A zero timeout, the default, waits for the pending RPCs indefinitely.
+func (sb *ServerBuilder) WithShutdownTimeout(timeout time.Duration) *ServerBuilder {
+	sb.shutdownTimeout = timeout
+	return sb
+}
+
 // WithPort sets the grpc service port
 func (sb *ServerBuilder) WithPort(port int) *ServerBuilder {
 	sb.grpcPort = port
@@ -123,12 +170,33 @@ func (sb *ServerBuilder) WithHost(host string) *ServerBuilder {
 	return sb
 }
 
+// WithListener sets a pre-created listener the grpcServer serves on, e.g. a listener inherited
+// from systemd socket activation. The host and port settings are then ignored.
+func (sb *ServerBuilder) WithListener(listener net.Listener) *ServerBuilder {
+	sb.listener = listener
+	return sb
+}
+
+// WithUnixSocket makes the grpcServer listen on the unix domain socket at the given path
+// instead of host:port. A stale socket file is removed when the grpcServer starts.
+func (sb *ServerBuilder) WithUnixSocket(path string) *ServerBuilder {
+	sb.unixSocket = path
+	return sb
+}
+
 // WithMetricsEnabled enable grpc metrics
 func (sb *ServerBuilder) WithMetricsEnabled(enabled bool) *ServerBuilder {
 	sb.metricsEnabled = enabled
 	return sb
 }
 
+// WithMetricsPort serves the Prometheus metrics on /metrics and the server health on /healthz
+// on the given port. The HTTP listener is started and stopped with the grpc server.
+func (sb *ServerBuilder) WithMetricsPort(port int) *ServerBuilder {
+	sb.metricsPort = port
+	return sb
+}
+
 // WithTracingEnabled enables tracing
 func (sb *ServerBuilder) WithTracingEnabled(enabled bool) *ServerBuilder {
 	sb.tracingEnabled = enabled
@@ -141,6 +209,13 @@ func (sb *ServerBuilder) WithTraceURL(traceURL string) *ServerBuilder {
 	return sb
 }
 
+// WithTelemetry sets the traces and metrics exporters started and stopped with the grpc server.
+// Tracing enabled with WithTracingEnabled and WithTraceURL overrides its trace endpoint.
+func (sb *ServerBuilder) WithTelemetry(config *otel.Config) *ServerBuilder {
+	sb.telemetry = config
+	return sb
+}
+
 // WithOption adds a grpc service option
 func (sb *ServerBuilder) WithOption(o grpc.ServerOption) *ServerBuilder {
 	sb.options = append(sb.options, o)
@@ -175,6 +250,19 @@ func (sb *ServerBuilder) WithHealthCheck(enabled bool) *ServerBuilder {
 	return sb
 }
 
+// WithReadinessCheck runs the given check at start and then every interval, and sets the overall
+// health status to SERVING or NOT_SERVING accordingly. It enables the health check service.
+// On shutdown the health status is set to NOT_SERVING before draining the pending RPCs.
+func (sb *ServerBuilder) WithReadinessCheck(interval time.Duration, check ReadinessCheck) *ServerBuilder {
+	if interval <= 0 {
+		interval = defaultReadinessInterval
+	}
+	sb.enableHealthCheck = true
+	sb.readinessCheck = check
+	sb.readinessInterval = interval
+	return sb
+}
+
 // WithKeepAlive is used to set keepalive and max-age parameters on the grpcServer-side.
 func (sb *ServerBuilder) WithKeepAlive(serverParams keepalive.ServerParameters) *ServerBuilder {
 	keepAlive := grpc.KeepaliveParams(serverParams)
@@ -193,6 +281,27 @@ func (sb *ServerBuilder) WithDefaultKeepAlive() *ServerBuilder {
 	})
 }
 
+// WithMaxRecvMsgSize sets the maximum size in bytes of the messages the server can receive.
+// The grpc default is 4MB.
+func (sb *ServerBuilder) WithMaxRecvMsgSize(size int) *ServerBuilder {
+	sb.maxRecvMsgSize = size
+	return sb
+}
+
+// WithMaxSendMsgSize sets the maximum size in bytes of the messages the server can send.
+// The grpc default is math.MaxInt32.
+func (sb *ServerBuilder) WithMaxSendMsgSize(size int) *ServerBuilder {
+	sb.maxSendMsgSize = size
+	return sb
+}
+
+// WithGzip compresses the responses with gzip when the client supports it.
+// The gzip compressed requests are always accepted.
+func (sb *ServerBuilder) WithGzip() *ServerBuilder {
+	sb.WithUnaryInterceptors(newGzipUnaryServerInterceptor())
+	return sb.WithStreamInterceptors(newGzipStreamServerInterceptor())
+}
+
 // WithStreamInterceptors set a list of interceptors to the Grpc grpcServer for stream connection
 // By default, gRPC doesn't allow one to have more than one interceptor either on the client nor on the grpcServer side.
 // By using `grpcMiddleware` we are able to provides convenient method to add a list of interceptors
@@ -217,24 +326,129 @@ func (sb *ServerBuilder) WithTLSCert(cert *tls.Certificate) *ServerBuilder {
 	return sb
 }
 
-// WithDefaultUnaryInterceptors sets the default unary interceptors for the grpc grpcServer
+// WithMutualTLS sets mutual TLS credentials for grpcServer connections.
+// The clients must present a certificate signed by one of the given certificate authorities.
+// The TLS configuration can be customized with TLSOption such as WithTLSMinVersion.
+func (sb *ServerBuilder) WithMutualTLS(cert *tls.Certificate, clientCAs *x509.CertPool, opts ...TLSOption) *ServerBuilder {
+	sb.WithOption(grpc.Creds(credentials.NewTLS(NewMutualTLSConfig(cert, clientCAs, opts...))))
+	return sb
+}
+
+// WithMutualTLSFiles sets mutual TLS credentials for grpcServer connections from PEM encoded files.
+// The files are loaded when the grpcServer is built.
+func (sb *ServerBuilder) WithMutualTLSFiles(certFile, keyFile, clientCAFile string, opts ...TLSOption) *ServerBuilder {
+	sb.mutualTLSFiles = &mutualTLSFiles{
+		certFile:     certFile,
+		keyFile:      keyFile,
+		clientCAFile: clientCAFile,
+		options:      opts,
+	}
+	return sb
+}
+
+// WithGateway spins up a grpc-gateway listener on the given port next to the grpcServer.
+// The gateway transcodes the REST/JSON requests into gRPC calls to the registered services and shares
+// the grpcServer lifecycle. It exposes the /healthz endpoint when the health check is enabled and
+// traces the requests when tracing is enabled.
+func (sb *ServerBuilder) WithGateway(port int, registrations ...GatewayRegistration) *ServerBuilder {
+	sb.getGateway().port = port
+	sb.gateway.registrations = append(sb.gateway.registrations, registrations...)
+	return sb
+}
+
+// WithGatewayMuxOptions sets the grpc-gateway mux options such as the marshalers or the error handler
+func (sb *ServerBuilder) WithGatewayMuxOptions(opts ...runtime.ServeMuxOption) *ServerBuilder {
+	sb.getGateway().muxOptions = append(sb.gateway.muxOptions, opts...)
+	return sb
+}
+
+// WithGatewayDialOptions sets the options used by the gateway to connect to the grpcServer.
+// The gateway connects without transport security by default, set the transport credentials
+// when the grpcServer uses TLS.
+func (sb *ServerBuilder) WithGatewayDialOptions(opts ...grpc.DialOption) *ServerBuilder {
+	sb.getGateway().dialOptions = append(sb.gateway.dialOptions, opts...)
+	return sb
+}
+
+// getGateway returns the gateway settings and creates them when not set
+func (sb *ServerBuilder) getGateway() *gateway {
+	if sb.gateway == nil {
+		sb.gateway = new(gateway)
+	}
+	return sb.gateway
+}
+
+// WithAccessLogging enables the access logging interceptors added by WithDefaultUnaryInterceptors
+// and WithDefaultStreamInterceptors. It can be called before or after them.
+func (sb *ServerBuilder) WithAccessLogging(logger log.Logger, opts ...LoggingOption) *ServerBuilder {
+	sb.logger = logger
+	sb.loggingOptions = opts
+	return sb
+}
+
+// WithDefaultUnaryInterceptors sets the default unary interceptors for the grpc grpcServer.
+// The interceptors chain is assembled when the grpcServer is built.
 func (sb *ServerBuilder) WithDefaultUnaryInterceptors() *ServerBuilder {
-	return sb.WithUnaryInterceptors(
+	return sb.WithOption(defaultInterceptorsOption{})
+}
+
+// WithDefaultStreamInterceptors sets the default stream interceptors for the grpc grpcServer.
+// The interceptors chain is assembled when the grpcServer is built.
+func (sb *ServerBuilder) WithDefaultStreamInterceptors() *ServerBuilder {
+	return sb.WithOption(defaultInterceptorsOption{stream: true})
+}
+
+// defaultInterceptorsOption marks the position of the default interceptors chain in the server options.
+// It is replaced by the chain when the server is built so that the access logging settings apply
+// whatever the order the builder methods are called in.
+type defaultInterceptorsOption struct {
+	grpc.EmptyServerOption
+	stream bool
+}
+
+// withDefaultInterceptors replaces the default interceptors markers by their chain
+func withDefaultInterceptors(options []grpc.ServerOption, logger log.Logger, loggingOptions []LoggingOption) []grpc.ServerOption {
+	resolved := make([]grpc.ServerOption, 0, len(options))
+	for _, option := range options {
+		marker, ok := option.(defaultInterceptorsOption)
+		switch {
+		case !ok:
+			resolved = append(resolved, option)
+		case marker.stream:
+			resolved = append(resolved, grpc.ChainStreamInterceptor(defaultStreamInterceptors(logger, loggingOptions)...))
+		default:
+			resolved = append(resolved, grpc.ChainUnaryInterceptor(defaultUnaryInterceptors(logger, loggingOptions)...))
+		}
+	}
+	return resolved
+}
+
+// defaultUnaryInterceptors returns the default unary server interceptors chain.
+// The access logging interceptor is only added when the logger is set.
+func defaultUnaryInterceptors(logger log.Logger, loggingOptions []LoggingOption) []grpc.UnaryServerInterceptor {
+	interceptors := []grpc.UnaryServerInterceptor{
 		NewRequestIDUnaryServerInterceptor(),
 		NewTracingUnaryInterceptor(),
 		NewMetricUnaryInterceptor(),
-		NewRecoveryUnaryInterceptor(),
-	)
+	}
+	if logger != nil {
+		interceptors = append(interceptors, NewLoggingUnaryServerInterceptor(logger, loggingOptions...))
+	}
+	return append(interceptors, NewRecoveryUnaryInterceptor())
 }
 
-// WithDefaultStreamInterceptors sets the default stream interceptors for the grpc grpcServer
-func (sb *ServerBuilder) WithDefaultStreamInterceptors() *ServerBuilder {
-	return sb.WithStreamInterceptors(
+// defaultStreamInterceptors returns the default stream server interceptors chain.
+// The access logging interceptor is only added when the logger is set.
+func defaultStreamInterceptors(logger log.Logger, loggingOptions []LoggingOption) []grpc.StreamServerInterceptor {
+	interceptors := []grpc.StreamServerInterceptor{
 		NewRequestIDStreamServerInterceptor(),
 		NewTracingStreamInterceptor(),
 		NewMetricStreamInterceptor(),
-		NewRecoveryStreamInterceptor(),
-	)
+	}
+	if logger != nil {
+		interceptors = append(interceptors, NewLoggingStreamServerInterceptor(logger, loggingOptions...))
+	}
+	return append(interceptors, NewRecoveryStreamInterceptor())
 }
 
 // Build is responsible for building a GRPC grpcServer
@@ -246,15 +460,47 @@ func (sb *ServerBuilder) Build() (Server, error) {
 		return nil, errMsgCannotUseSameBuilder
 	}
 
+	// validate the message sizes
+	options := withDefaultInterceptors(sb.options, sb.logger, sb.loggingOptions)
+	if err := validateMessageSize("max receive message size", sb.maxRecvMsgSize); err != nil {
+		return nil, err
+	}
+	if err := validateMessageSize("max send message size", sb.maxSendMsgSize); err != nil {
+		return nil, err
+	}
+	if sb.maxRecvMsgSize > 0 {
+		options = append(options, grpc.MaxRecvMsgSize(sb.maxRecvMsgSize))
+	}
+	if sb.maxSendMsgSize > 0 {
+		options = append(options, grpc.MaxSendMsgSize(sb.maxSendMsgSize))
+	}
+
+	// load the mutual TLS credentials
+	if sb.mutualTLSFiles != nil {
+		files := sb.mutualTLSFiles
+		config, err := LoadMutualTLSConfig(files.certFile, files.keyFile, files.clientCAFile, files.options...)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, grpc.Creds(credentials.NewTLS(config)))
+	}
+
 	// create the grpc server
-	srv := grpc.NewServer(sb.options...)
+	srv := grpc.NewServer(options...)
 
 	// create the grpc server
-	addr := fmt.Sprintf("%s:%d", sb.grpcHost, sb.grpcPort)
+	network, addr := "tcp", fmt.Sprintf("%s:%d", sb.grpcHost, sb.grpcPort)
+	if sb.unixSocket != "" {
+		network, addr = "unix", sb.unixSocket
+	}
+
 	grpcServer := &grpcServer{
-		addr:         addr,
-		server:       srv,
-		shutdownHook: sb.shutdownHook,
+		addr:            addr,
+		network:         network,
+		listener:        sb.listener,
+		server:          srv,
+		shutdownHook:    sb.shutdownHook,
+		shutdownTimeout: sb.shutdownTimeout,
 	}
 
 	// register services
@@ -269,7 +515,10 @@ func (sb *ServerBuilder) Build() (Server, error) {
 
 	// register health check if enabled
 	if sb.enableHealthCheck {
-		grpc_health_v1.RegisterHealthServer(srv, health.NewServer())
+		grpcServer.healthServer = health.NewServer()
+		grpcServer.readinessCheck = sb.readinessCheck
+		grpcServer.readinessInterval = sb.readinessInterval
+		grpc_health_v1.RegisterHealthServer(srv, grpcServer.healthServer)
 	}
 
 	// register tracing if enabled
@@ -281,7 +530,39 @@ func (sb *ServerBuilder) Build() (Server, error) {
 		if sb.serviceName == "" {
 			return nil, errMissingServiceName
 		}
-		grpcServer.traceProvider = trace.NewProvider(sb.traceURL, sb.serviceName)
+
+		telemetry := &otel.Config{ServiceName: sb.serviceName}
+		if sb.telemetry != nil {
+			// copy the telemetry settings to not alter the caller's ones
+			config := *sb.telemetry
+			telemetry = &config
+			if telemetry.ServiceName == "" {
+				telemetry.ServiceName = sb.serviceName
+			}
+		}
+		telemetry.TraceEndpoint = sb.traceURL
+		sb.telemetry = telemetry
+	}
+	grpcServer.telemetry = sb.telemetry
+
+	// serve the metrics when the port is set
+	if sb.metricsPort > 0 {
+		grpcServer.metricServer = metricserver.New(
+			fmt.Sprintf("%s:%d", sb.grpcHost, sb.metricsPort),
+			metricserver.WithPprof(false),
+			metricserver.WithHealthCheck(grpcServer.healthz))
+	}
+
+	// set the gateway when enabled
+	if sb.gateway != nil {
+		if sb.gateway.port <= 0 {
+			return nil, errMissingGatewayPort
+		}
+		sb.gateway.addr = fmt.Sprintf("%s:%d", sb.grpcHost, sb.gateway.port)
+		sb.gateway.healthCheck = sb.enableHealthCheck
+		sb.gateway.tracing = sb.tracingEnabled
+		sb.gateway.serviceName = sb.serviceName
+		grpcServer.gateway = sb.gateway
 	}
 
 	// set isBuild
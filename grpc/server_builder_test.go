@@ -30,6 +30,8 @@ import (
 
 	"github.com/stretchr/testify/suite"
 	"github.com/travisjeffery/go-dynaport"
+
+	"github.com/tochemey/gopack/otel"
 )
 
 type builderTestSuite struct {
@@ -101,3 +103,34 @@ func (s *builderTestSuite) TestBuild() {
 		s.Assert().Nil(srv)
 	})
 }
+
+func (s *builderTestSuite) TestWithTelemetry() {
+	s.Run("with tracing enabled", func() {
+		telemetry := &otel.Config{MetricEndpoint: "127.0.0.1:4317", Environment: "test"}
+		srv, err := NewServerBuilder().
+			WithPort(dynaport.Get(1)[0]).
+			WithService(&MockedService{}).
+			WithServiceName("hello").
+			WithTelemetry(telemetry).
+			WithTracingEnabled(true).
+			WithTraceURL("127.0.0.1:4318").
+			Build()
+		s.Require().NoError(err)
+
+		config := srv.(*grpcServer).telemetry
+		s.Assert().Equal("hello", config.ServiceName)
+		s.Assert().Equal("127.0.0.1:4318", config.TraceEndpoint)
+		s.Assert().Equal("127.0.0.1:4317", config.MetricEndpoint)
+		s.Assert().Equal("test", config.Environment)
+		// the caller's settings are left untouched
+		s.Assert().Empty(telemetry.TraceEndpoint)
+	})
+	s.Run("with tracing disabled", func() {
+		srv, err := NewServerBuilder().
+			WithPort(dynaport.Get(1)[0]).
+			WithService(&MockedService{}).
+			Build()
+		s.Require().NoError(err)
+		s.Assert().Nil(srv.(*grpcServer).telemetry)
+	})
+}
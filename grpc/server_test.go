@@ -26,15 +26,27 @@ package grpc
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/suite"
+	"github.com/travisjeffery/go-dynaport"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
 
 	"github.com/tochemey/gopack/otel/testkit"
+	testv1 "github.com/tochemey/gopack/test/data/test/v1"
 )
 
 type serverTestSuite struct {
@@ -94,3 +106,275 @@ func (s *serverTestSuite) TestStartAndStop() {
 	s.Assert().Error(err)
 	s.Assert().Nil(conn)
 }
+
+func (s *serverTestSuite) TestShutdownTimeout() {
+	s.Run("with pending RPC exceeding the timeout", func() {
+		ctx := context.TODO()
+		service := &blockingService{started: make(chan struct{}), release: make(chan struct{})}
+		defer close(service.release)
+
+		addr, srv := s.startBlockingServer(service, 100*time.Millisecond)
+		go s.sayHello(addr)
+		<-service.started
+
+		err := srv.Stop(ctx)
+		s.Assert().ErrorIs(err, ErrForcedShutdown)
+	})
+	s.Run("with context done before the pending RPC completes", func() {
+		service := &blockingService{started: make(chan struct{}), release: make(chan struct{})}
+		defer close(service.release)
+
+		addr, srv := s.startBlockingServer(service, 0)
+		go s.sayHello(addr)
+		<-service.started
+
+		ctx, cancel := context.WithTimeout(context.TODO(), 100*time.Millisecond)
+		defer cancel()
+		s.Assert().ErrorIs(srv.Stop(ctx), ErrForcedShutdown)
+	})
+	s.Run("with pending RPC completing within the timeout", func() {
+		ctx := context.TODO()
+		service := &blockingService{started: make(chan struct{}), release: make(chan struct{})}
+
+		addr, srv := s.startBlockingServer(service, 5*time.Second)
+		go s.sayHello(addr)
+		<-service.started
+
+		time.AfterFunc(50*time.Millisecond, func() { close(service.release) })
+		s.Assert().NoError(srv.Stop(ctx))
+	})
+}
+
+// startBlockingServer starts a server with the given shutdown timeout and returns its address
+func (s *serverTestSuite) startBlockingServer(service *blockingService, timeout time.Duration) (string, Server) {
+	port := dynaport.Get(1)[0]
+	srv, err := NewServerBuilder().
+		WithHost("127.0.0.1").
+		WithPort(port).
+		WithService(service).
+		WithShutdownTimeout(timeout).
+		Build()
+	s.Require().NoError(err)
+	s.Require().NoError(srv.Start(context.TODO()))
+	return net.JoinHostPort("127.0.0.1", fmt.Sprint(port)), srv
+}
+
+// sayHello calls the greeter service of the given address
+func (s *serverTestSuite) sayHello(addr string) {
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	_, _ = testv1.NewGreeterClient(conn).SayHello(context.TODO(), &testv1.HelloRequest{Name: "test"})
+}
+
+// blockingService is a greeter service blocking until released
+type blockingService struct {
+	testv1.UnimplementedGreeterServer
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingService) SayHello(ctx context.Context, in *testv1.HelloRequest) (*testv1.HelloReply, error) {
+	close(s.started)
+	select {
+	case <-s.release:
+	case <-ctx.Done():
+	}
+	return &testv1.HelloReply{Message: in.GetName()}, nil
+}
+
+func (s *blockingService) RegisterService(server *grpc.Server) {
+	testv1.RegisterGreeterServer(server, s)
+}
+
+func (s *serverTestSuite) TestListeners() {
+	s.Run("with unix socket", func() {
+		ctx := context.TODO()
+		socket := filepath.Join(s.T().TempDir(), "grpc.sock")
+
+		// leave a stale socket file behind
+		stale, err := net.Listen("unix", socket)
+		s.Require().NoError(err)
+		stale.(*net.UnixListener).SetUnlinkOnClose(false)
+		s.Require().NoError(stale.Close())
+
+		srv, err := NewServerBuilder().
+			WithUnixSocket(socket).
+			WithService(&MockedService{}).
+			Build()
+		s.Require().NoError(err)
+		s.Require().NoError(srv.Start(ctx))
+		s.Assert().Equal("unix", srv.GetListener().Addr().Network())
+
+		s.assertSayHello("unix:" + socket)
+		s.Assert().NoError(srv.Stop(ctx))
+	})
+	s.Run("with pre-created listener", func() {
+		ctx := context.TODO()
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		s.Require().NoError(err)
+
+		srv, err := NewServerBuilder().
+			WithListener(listener).
+			WithService(&MockedService{}).
+			Build()
+		s.Require().NoError(err)
+		s.Require().NoError(srv.Start(ctx))
+		s.Assert().Equal(listener, srv.GetListener())
+
+		s.assertSayHello(listener.Addr().String())
+		s.Assert().NoError(srv.Stop(ctx))
+	})
+}
+
+// assertSayHello calls the mocked service at the given target
+func (s *serverTestSuite) assertSayHello(target string) {
+	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	s.Require().NoError(err)
+	defer conn.Close()
+
+	resp, err := testv1.NewGreeterClient(conn).SayHello(context.TODO(), &testv1.HelloRequest{Name: "test"})
+	s.Require().NoError(err)
+	s.Assert().Equal("This is a mocked service test", resp.GetMessage())
+}
+
+func (s *serverTestSuite) TestHealthStatus() {
+	ctx := context.TODO()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	s.Require().NoError(err)
+
+	var ready atomic.Bool
+	ready.Store(true)
+	srv, err := NewServerBuilder().
+		WithListener(listener).
+		WithService(&MockedService{}).
+		WithReadinessCheck(10*time.Millisecond, func(context.Context) error {
+			if !ready.Load() {
+				return errors.New("database unreachable")
+			}
+			return nil
+		}).
+		Build()
+	s.Require().NoError(err)
+	s.Require().NoError(srv.Start(ctx))
+
+	conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	s.Require().NoError(err)
+	defer conn.Close()
+	client := grpc_health_v1.NewHealthClient(conn)
+	status := func(service string) grpc_health_v1.HealthCheckResponse_ServingStatus {
+		resp, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: service})
+		s.Require().NoError(err)
+		return resp.GetStatus()
+	}
+
+	s.Assert().Equal(grpc_health_v1.HealthCheckResponse_SERVING, status(""))
+
+	srv.SetServingStatus(testv1.Greeter_ServiceDesc.ServiceName, grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	s.Assert().Equal(grpc_health_v1.HealthCheckResponse_NOT_SERVING, status(testv1.Greeter_ServiceDesc.ServiceName))
+
+	ready.Store(false)
+	s.Eventually(func() bool {
+		return status("") == grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	}, time.Second, 10*time.Millisecond)
+
+	ready.Store(true)
+	s.Eventually(func() bool {
+		return status("") == grpc_health_v1.HealthCheckResponse_SERVING
+	}, time.Second, 10*time.Millisecond)
+
+	s.Require().NoError(srv.Stop(ctx))
+	resp, err := srv.(*grpcServer).healthServer.Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	s.Require().NoError(err)
+	s.Assert().Equal(grpc_health_v1.HealthCheckResponse_NOT_SERVING, resp.GetStatus())
+}
+
+func (s *serverTestSuite) TestMetricsPort() {
+	ctx := context.TODO()
+	ports := dynaport.Get(2)
+	srv, err := NewServerBuilder().
+		WithHost("127.0.0.1").
+		WithPort(ports[0]).
+		WithMetricsPort(ports[1]).
+		WithHealthCheck(true).
+		WithDefaultUnaryInterceptors().
+		WithService(&MockedService{}).
+		Build()
+	s.Require().NoError(err)
+	s.Require().NoError(srv.Start(ctx))
+
+	s.assertSayHello(fmt.Sprintf("127.0.0.1:%d", ports[0]))
+
+	baseURL := fmt.Sprintf("http://127.0.0.1:%d", ports[1])
+	body, status := s.get(baseURL + "/metrics")
+	s.Assert().Equal(http.StatusOK, status)
+	s.Assert().Contains(body, "grpc_server_handled_total")
+
+	_, status = s.get(baseURL + "/healthz")
+	s.Assert().Equal(http.StatusOK, status)
+
+	srv.SetServingStatus("", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	_, status = s.get(baseURL + "/healthz")
+	s.Assert().Equal(http.StatusServiceUnavailable, status)
+
+	s.Require().NoError(srv.Stop(ctx))
+	_, err = http.Get(baseURL + "/metrics") //nolint
+	s.Assert().Error(err)
+}
+
+// get fetches the given url and returns the body and the status code
+func (s *serverTestSuite) get(url string) (string, int) {
+	resp, err := http.Get(url) //nolint
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	s.Require().NoError(err)
+	return string(body), resp.StatusCode
+}
+
+func (s *serverTestSuite) TestMessageSizes() {
+	s.Run("with invalid sizes", func() {
+		_, err := NewServerBuilder().WithMaxRecvMsgSize(-1).Build()
+		s.Assert().ErrorContains(err, "invalid max receive message size")
+
+		_, err = NewServerBuilder().WithMaxSendMsgSize(MaxMessageSize + 1).Build()
+		s.Assert().ErrorContains(err, "invalid max send message size")
+
+		_, err = NewClientBuilder().WithInsecure().WithMaxRecvMsgSize(-1).ClientConn("127.0.0.1:50051")
+		s.Assert().ErrorContains(err, "invalid max receive message size")
+	})
+	s.Run("with gzip and max sizes", func() {
+		ctx := context.TODO()
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		s.Require().NoError(err)
+
+		srv, err := NewServerBuilder().
+			WithListener(listener).
+			WithMaxRecvMsgSize(64).
+			WithGzip().
+			WithService(&MockedService{}).
+			Build()
+		s.Require().NoError(err)
+		s.Require().NoError(srv.Start(ctx))
+
+		conn, err := NewClientBuilder().
+			WithInsecure().
+			WithGzip().
+			WithMaxRecvMsgSize(1024).
+			ClientConn(listener.Addr().String())
+		s.Require().NoError(err)
+		defer conn.Close()
+
+		client := testv1.NewGreeterClient(conn)
+		resp, err := client.SayHello(ctx, &testv1.HelloRequest{Name: "test"})
+		s.Require().NoError(err)
+		s.Assert().Equal("This is a mocked service test", resp.GetMessage())
+
+		// a gzip compressed request larger than the limit once decompressed
+		_, err = client.SayHello(ctx, &testv1.HelloRequest{Name: strings.Repeat("a", 128)})
+		s.Assert().Equal(codes.ResourceExhausted, status.Code(err))
+		s.Require().NoError(srv.Stop(ctx))
+	})
+}
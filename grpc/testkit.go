@@ -33,7 +33,12 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
 	"google.golang.org/grpc/test/bufconn"
+
+	gopacklog "github.com/tochemey/gopack/log"
 )
 
 // TestClientConn creates an in-process grpc client
@@ -72,7 +77,12 @@ type InProcessServer interface {
 
 // InProcessServerBuilder in-processing grpc server builder
 type InProcessServerBuilder struct {
-	options []grpc.ServerOption
+	options           []grpc.ServerOption
+	enableReflection  bool
+	enableHealthCheck bool
+	shutdownHook      ShutdownHook
+	logger            gopacklog.Logger
+	loggingOptions    []LoggingOption
 }
 
 // NewInProcessServerBuilder creates an instance of InProcessServerBuilder
@@ -110,15 +120,67 @@ func (sb *InProcessServerBuilder) WithTLSCert(cert *tls.Certificate) *InProcessS
 	return sb
 }
 
+// WithReflection enables the reflection service
+func (sb *InProcessServerBuilder) WithReflection(enabled bool) *InProcessServerBuilder {
+	sb.enableReflection = enabled
+	return sb
+}
+
+// WithHealthCheck enables the default health check service
+func (sb *InProcessServerBuilder) WithHealthCheck(enabled bool) *InProcessServerBuilder {
+	sb.enableHealthCheck = enabled
+	return sb
+}
+
+// WithShutdownHook sets the shutdown hook called on Cleanup
+func (sb *InProcessServerBuilder) WithShutdownHook(fn ShutdownHook) *InProcessServerBuilder {
+	sb.shutdownHook = fn
+	return sb
+}
+
+// WithAccessLogging enables the access logging interceptors added by WithDefaultUnaryInterceptors
+// and WithDefaultStreamInterceptors. It can be called before or after them.
+func (sb *InProcessServerBuilder) WithAccessLogging(logger gopacklog.Logger, opts ...LoggingOption) *InProcessServerBuilder {
+	sb.logger = logger
+	sb.loggingOptions = opts
+	return sb
+}
+
+// WithDefaultUnaryInterceptors sets the same default unary interceptors as the ServerBuilder
+func (sb *InProcessServerBuilder) WithDefaultUnaryInterceptors() *InProcessServerBuilder {
+	return sb.WithOption(defaultInterceptorsOption{})
+}
+
+// WithDefaultStreamInterceptors sets the same default stream interceptors as the ServerBuilder
+func (sb *InProcessServerBuilder) WithDefaultStreamInterceptors() *InProcessServerBuilder {
+	return sb.WithOption(defaultInterceptorsOption{stream: true})
+}
+
 // Build is responsible for building a Fiji GRPC server
 func (sb *InProcessServerBuilder) Build() InProcessServer {
-	server, listener := TestServer(sb.options)
-	return &testServer{server, listener}
+	server, listener := TestServer(withDefaultInterceptors(sb.options, sb.logger, sb.loggingOptions))
+
+	// set reflection when enable
+	if sb.enableReflection {
+		reflection.Register(server)
+	}
+
+	// register health check if enabled
+	if sb.enableHealthCheck {
+		grpc_health_v1.RegisterHealthServer(server, health.NewServer())
+	}
+
+	return &testServer{
+		server:       server,
+		listener:     listener,
+		shutdownHook: sb.shutdownHook,
+	}
 }
 
 type testServer struct {
-	server   *grpc.Server
-	listener *bufconn.Listener
+	server       *grpc.Server
+	listener     *bufconn.Listener
+	shutdownHook ShutdownHook
 }
 
 // GetListener register the services to the server
@@ -138,10 +200,15 @@ func (s *testServer) Start() error {
 	return nil
 }
 
-// Cleanup stops the server and close the tcp listener
+// Cleanup stops the server, close the tcp listener and runs the shutdown hook
 func (s *testServer) Cleanup() {
 	s.server.Stop()
 	_ = s.listener.Close()
+	if s.shutdownHook != nil {
+		if err := s.shutdownHook(context.Background()); err != nil {
+			log.Printf("shutdown hook failed: %v", err)
+		}
+	}
 	log.Println("Server stopped")
 }
 
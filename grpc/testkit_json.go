@@ -0,0 +1,165 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// InvokeJSON invokes the given unary method with a JSON encoded request and returns the JSON encoded response.
+// The method is either "/pkg.Service/Method", "pkg.Service/Method" or "pkg.Service.Method".
+// The request and response types are discovered using the server reflection, which must be enabled,
+// e.g. with ServerBuilder.WithReflection. This is useful for black-box integration tests.
+func InvokeJSON(ctx context.Context, conn grpc.ClientConnInterface, method string, jsonBody []byte) ([]byte, error) {
+	serviceName, methodName, err := splitMethod(method)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := fetchFiles(ctx, conn, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	descriptor, err := files.FindDescriptorByName(protoreflect.FullName(serviceName))
+	if err != nil {
+		return nil, fmt.Errorf("service (%s) not found: %w", serviceName, err)
+	}
+
+	service, ok := descriptor.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a service", serviceName)
+	}
+
+	methodDescriptor := service.Methods().ByName(protoreflect.Name(methodName))
+	if methodDescriptor == nil {
+		return nil, fmt.Errorf("method (%s) not found in service (%s)", methodName, serviceName)
+	}
+
+	if methodDescriptor.IsStreamingClient() || methodDescriptor.IsStreamingServer() {
+		return nil, fmt.Errorf("method (%s) is a streaming method", method)
+	}
+
+	types := dynamicpb.NewTypes(files)
+	request := dynamicpb.NewMessage(methodDescriptor.Input())
+	if len(jsonBody) > 0 {
+		if err := (protojson.UnmarshalOptions{Resolver: types}).Unmarshal(jsonBody, request); err != nil {
+			return nil, fmt.Errorf("invalid request: %w", err)
+		}
+	}
+
+	response := dynamicpb.NewMessage(methodDescriptor.Output())
+	if err := conn.Invoke(ctx, fmt.Sprintf("/%s/%s", serviceName, methodName), request, response); err != nil {
+		return nil, err
+	}
+	return protojson.MarshalOptions{Resolver: types}.Marshal(response)
+}
+
+// splitMethod returns the full service name and the method name of the given method
+func splitMethod(method string) (string, string, error) {
+	method = strings.TrimPrefix(method, "/")
+	index := strings.LastIndexAny(method, "/.")
+	if index <= 0 || index == len(method)-1 {
+		return "", "", fmt.Errorf("invalid method (%s)", method)
+	}
+	return method[:index], method[index+1:], nil
+}
+
+// fetchFiles fetches the file descriptors defining the given symbol and their dependencies using the server reflection
+func fetchFiles(ctx context.Context, conn grpc.ClientConnInterface, symbol string) (*protoregistry.Files, error) {
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = stream.CloseSend()
+	}()
+
+	files := make(map[string]*descriptorpb.FileDescriptorProto)
+	requested := make(map[string]bool)
+	pending := []*reflectionpb.ServerReflectionRequest{{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: symbol},
+	}}
+
+	for len(pending) > 0 {
+		request := pending[0]
+		pending = pending[1:]
+		// the file may have been sent along with another one
+		if _, ok := files[request.GetFileByFilename()]; ok {
+			continue
+		}
+
+		if err := stream.Send(request); err != nil {
+			return nil, err
+		}
+		response, err := stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+		if errResponse := response.GetErrorResponse(); errResponse != nil {
+			return nil, status.Error(codes.Code(errResponse.GetErrorCode()), errResponse.GetErrorMessage())
+		}
+
+		for _, raw := range response.GetFileDescriptorResponse().GetFileDescriptorProto() {
+			file := new(descriptorpb.FileDescriptorProto)
+			if err := proto.Unmarshal(raw, file); err != nil {
+				return nil, err
+			}
+			files[file.GetName()] = file
+		}
+
+		for _, file := range files {
+			for _, dependency := range file.GetDependency() {
+				if _, ok := files[dependency]; ok || requested[dependency] {
+					continue
+				}
+				requested[dependency] = true
+				pending = append(pending, &reflectionpb.ServerReflectionRequest{
+					MessageRequest: &reflectionpb.ServerReflectionRequest_FileByFilename{FileByFilename: dependency},
+				})
+			}
+		}
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+	for _, file := range files {
+		set.File = append(set.File, file)
+	}
+	return protodesc.NewFiles(set)
+}
@@ -0,0 +1,133 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/tochemey/gopack/requestid"
+	testv1 "github.com/tochemey/gopack/test/data/test/v1"
+)
+
+func TestInProcessServerBuilder(t *testing.T) {
+	ctx := context.Background()
+	logger := new(recordingLogger)
+	hookCalled := false
+
+	server := NewInProcessServerBuilder().
+		WithAccessLogging(logger).
+		WithDefaultUnaryInterceptors().
+		WithDefaultStreamInterceptors().
+		WithHealthCheck(true).
+		WithReflection(true).
+		WithShutdownHook(func(context.Context) error {
+			hookCalled = true
+			return nil
+		}).
+		Build()
+	server.RegisterService(func(server *grpc.Server) {
+		testv1.RegisterGreeterServer(server, &MockedService{})
+	})
+	require.NoError(t, server.Start())
+
+	services := server.(*testServer).server.GetServiceInfo()
+	assert.Contains(t, services, grpc_health_v1.Health_ServiceDesc.ServiceName)
+	assert.Contains(t, services, "grpc.reflection.v1.ServerReflection")
+
+	conn, err := TestClientConn(ctx, server.GetListener(), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	resp, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, resp.GetStatus())
+
+	callCtx := metadata.AppendToOutgoingContext(ctx, requestid.XRequestIDMetadataKey, "request-1")
+	_, err = testv1.NewGreeterClient(conn).SayHello(callCtx, &testv1.HelloRequest{Name: "test"})
+	require.NoError(t, err)
+
+	// both calls go through the default interceptors chain
+	infos, _ := logger.entries()
+	require.Len(t, infos, 2)
+	assert.Equal(t, "request-1", logger.entryFields()[1][LogFieldRequestID])
+
+	server.Cleanup()
+	assert.True(t, hookCalled)
+}
+
+func TestInvokeJSON(t *testing.T) {
+	ctx := context.Background()
+	newConn := func(reflection bool) *grpc.ClientConn {
+		server := NewInProcessServerBuilder().WithReflection(reflection).Build()
+		server.RegisterService(func(server *grpc.Server) {
+			testv1.RegisterGreeterServer(server, &MockedService{})
+		})
+		require.NoError(t, server.Start())
+		conn, err := TestClientConn(ctx, server.GetListener(), nil)
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_ = conn.Close()
+			server.Cleanup()
+		})
+		return conn
+	}
+
+	t.Run("with valid method", func(t *testing.T) {
+		conn := newConn(true)
+		for _, method := range []string{"/test.v1.Greeter/SayHello", "test.v1.Greeter/SayHello", "test.v1.Greeter.SayHello"} {
+			resp, err := InvokeJSON(ctx, conn, method, []byte(`{"name":"test"}`))
+			require.NoError(t, err)
+			assert.JSONEq(t, `{"message":"This is a mocked service test"}`, string(resp))
+		}
+	})
+	t.Run("with invalid requests", func(t *testing.T) {
+		conn := newConn(true)
+		_, err := InvokeJSON(ctx, conn, "test.v1.Greeter/Unknown", nil)
+		assert.ErrorContains(t, err, "method (Unknown) not found")
+
+		_, err = InvokeJSON(ctx, conn, "test.v1.Unknown/SayHello", nil)
+		assert.Error(t, err)
+
+		_, err = InvokeJSON(ctx, conn, "SayHello", nil)
+		assert.ErrorContains(t, err, "invalid method")
+
+		_, err = InvokeJSON(ctx, conn, "test.v1.Greeter/SayHello", []byte(`{"unknown":true}`))
+		assert.ErrorContains(t, err, "invalid request")
+	})
+	t.Run("without reflection", func(t *testing.T) {
+		conn := newConn(false)
+		_, err := InvokeJSON(ctx, conn, "test.v1.Greeter/SayHello", []byte(`{"name":"test"}`))
+		assert.Equal(t, codes.Unimplemented, status.Code(err))
+	})
+}
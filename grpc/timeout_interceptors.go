@@ -0,0 +1,138 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package grpc
+
+import (
+	"context"
+	"errors"
+	"path"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// methodTimeout is the timeout of the methods matching a pattern
+type methodTimeout struct {
+	pattern string
+	timeout time.Duration
+}
+
+// timeoutConfig holds the timeout interceptors settings
+type timeoutConfig struct {
+	defaultTimeout time.Duration
+	methods        []methodTimeout
+}
+
+// TimeoutOption configures the timeout interceptors
+type TimeoutOption func(*timeoutConfig)
+
+// WithMethodTimeout sets the maximum deadline of the methods matching the given full method pattern
+// such as "/pkg.Service/Method" or "/pkg.Service/*" using the path.Match syntax. An exact match takes
+// precedence over the patterns which are evaluated in the order they have been set.
+// A timeout less than or equal to zero disables the enforcement for the matching methods.
+func WithMethodTimeout(pattern string, timeout time.Duration) TimeoutOption {
+	return func(c *timeoutConfig) {
+		c.methods = append(c.methods, methodTimeout{pattern: pattern, timeout: timeout})
+	}
+}
+
+// timeout returns the maximum deadline of the given method
+func (c *timeoutConfig) timeout(method string) time.Duration {
+	for _, m := range c.methods {
+		if m.pattern == method {
+			return m.timeout
+		}
+	}
+
+	for _, m := range c.methods {
+		if matched, _ := path.Match(m.pattern, method); matched {
+			return m.timeout
+		}
+	}
+	return c.defaultTimeout
+}
+
+// newTimeoutConfig creates the timeout config and applies the given options
+func newTimeoutConfig(timeout time.Duration, opts ...TimeoutOption) *timeoutConfig {
+	config := &timeoutConfig{defaultTimeout: timeout}
+	for _, opt := range opts {
+		opt(config)
+	}
+	return config
+}
+
+// NewTimeoutUnaryServerInterceptor returns a unary server interceptor that enforces a maximum deadline
+// on every call. The shortest of the client deadline and the given timeout applies. A timeout less than or
+// equal to zero means the methods matching no pattern are not limited. The calls exceeding their deadline
+// fail with codes.DeadlineExceeded.
+func NewTimeoutUnaryServerInterceptor(timeout time.Duration, opts ...TimeoutOption) grpc.UnaryServerInterceptor {
+	config := newTimeoutConfig(timeout, opts...)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		timeout := config.timeout(info.FullMethod)
+		if timeout <= 0 {
+			return handler(ctx, req)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		resp, err := handler(ctx, req)
+		if deadlineExceeded(ctx, err) {
+			return nil, status.Errorf(codes.DeadlineExceeded, "%s exceeded its deadline", info.FullMethod)
+		}
+		return resp, err
+	}
+}
+
+// NewTimeoutStreamServerInterceptor returns a stream server interceptor that enforces a maximum deadline
+// on every stream. See NewTimeoutUnaryServerInterceptor.
+func NewTimeoutStreamServerInterceptor(timeout time.Duration, opts ...TimeoutOption) grpc.StreamServerInterceptor {
+	config := newTimeoutConfig(timeout, opts...)
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		timeout := config.timeout(info.FullMethod)
+		if timeout <= 0 {
+			return handler(srv, ss)
+		}
+
+		ctx, cancel := context.WithTimeout(ss.Context(), timeout)
+		defer cancel()
+
+		err := handler(srv, newServerStreamWithContext(ctx, ss))
+		if deadlineExceeded(ctx, err) {
+			return status.Errorf(codes.DeadlineExceeded, "%s exceeded its deadline", info.FullMethod)
+		}
+		return err
+	}
+}
+
+// deadlineExceeded checks whether the call has failed because its deadline has been exceeded
+func deadlineExceeded(ctx context.Context, err error) bool {
+	if err == nil {
+		return false
+	}
+	return errors.Is(ctx.Err(), context.DeadlineExceeded) || errors.Is(err, context.DeadlineExceeded)
+}
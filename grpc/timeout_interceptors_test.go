@@ -0,0 +1,100 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// waitHandler blocks until its context is done
+func waitHandler(ctx context.Context, _ any) (any, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestTimeoutUnaryServerInterceptor(t *testing.T) {
+	t.Run("with deadline exceeded", func(t *testing.T) {
+		interceptor := NewTimeoutUnaryServerInterceptor(20 * time.Millisecond)
+		_, err := interceptor(context.Background(), nil, unaryInfo, waitHandler)
+		assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	})
+	t.Run("with shorter client deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		interceptor := NewTimeoutUnaryServerInterceptor(time.Hour)
+		_, err := interceptor(ctx, nil, unaryInfo, func(ctx context.Context, req any) (any, error) {
+			deadline, ok := ctx.Deadline()
+			require.True(t, ok)
+			assert.WithinDuration(t, time.Now().Add(20*time.Millisecond), deadline, 20*time.Millisecond)
+			return waitHandler(ctx, req)
+		})
+		assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	})
+	t.Run("with method timeout", func(t *testing.T) {
+		interceptor := NewTimeoutUnaryServerInterceptor(time.Hour,
+			WithMethodTimeout("TestService.*", time.Minute),
+			WithMethodTimeout(unaryInfo.FullMethod, 20*time.Millisecond))
+		start := time.Now()
+		_, err := interceptor(context.Background(), nil, unaryInfo, waitHandler)
+		assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+		assert.Less(t, time.Since(start), time.Minute)
+	})
+	t.Run("with timeout disabled", func(t *testing.T) {
+		interceptor := NewTimeoutUnaryServerInterceptor(time.Hour, WithMethodTimeout("TestService.*", 0))
+		resp, err := interceptor(context.Background(), nil, unaryInfo, func(ctx context.Context, _ any) (any, error) {
+			_, ok := ctx.Deadline()
+			assert.False(t, ok)
+			return "output", nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "output", resp)
+	})
+	t.Run("with handler error", func(t *testing.T) {
+		interceptor := NewTimeoutUnaryServerInterceptor(time.Hour)
+		_, err := interceptor(context.Background(), nil, unaryInfo, func(context.Context, any) (any, error) {
+			return nil, status.Error(codes.NotFound, "not found")
+		})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+}
+
+func TestTimeoutStreamServerInterceptor(t *testing.T) {
+	interceptor := NewTimeoutStreamServerInterceptor(20 * time.Millisecond)
+	stream := &testServerStream{ctx: context.Background()}
+	err := interceptor(nil, stream, streamInfo, func(_ any, stream grpc.ServerStream) error {
+		<-stream.Context().Done()
+		return stream.Context().Err()
+	})
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+}
@@ -0,0 +1,87 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package grpc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// TLSOption customizes the server TLS configuration
+type TLSOption func(*tls.Config)
+
+// WithTLSMinVersion sets the minimum TLS version accepted by the server.
+// The default minimum version is TLS 1.2
+func WithTLSMinVersion(version uint16) TLSOption {
+	return func(config *tls.Config) {
+		config.MinVersion = version
+	}
+}
+
+// WithTLSCipherSuites sets the cipher suites accepted by the server for TLS 1.2 and below.
+// TLS 1.3 cipher suites are not configurable.
+func WithTLSCipherSuites(cipherSuites ...uint16) TLSOption {
+	return func(config *tls.Config) {
+		config.CipherSuites = cipherSuites
+	}
+}
+
+// NewMutualTLSConfig creates a server TLS configuration that requires the clients
+// to present a certificate signed by one of the given certificate authorities.
+func NewMutualTLSConfig(cert *tls.Certificate, clientCAs *x509.CertPool, opts ...TLSOption) *tls.Config {
+	config := &tls.Config{
+		Certificates: []tls.Certificate{*cert},
+		ClientCAs:    clientCAs,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	}
+	for _, opt := range opts {
+		opt(config)
+	}
+	return config
+}
+
+// LoadMutualTLSConfig creates a server mutual TLS configuration from PEM encoded files.
+// certFile and keyFile are the server certificate and private key, clientCAFile contains
+// the certificate authorities used to verify the clients certificates.
+func LoadMutualTLSConfig(certFile, keyFile, clientCAFile string, opts ...TLSOption) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the server certificate: %w", err)
+	}
+
+	pem, err := os.ReadFile(clientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the client CA file: %w", err)
+	}
+
+	clientCAs := x509.NewCertPool()
+	if !clientCAs.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no valid certificate found in the client CA file (%s)", clientCAFile)
+	}
+	return NewMutualTLSConfig(&cert, clientCAs, opts...), nil
+}
@@ -0,0 +1,202 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package grpc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/travisjeffery/go-dynaport"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	testv1 "github.com/tochemey/gopack/test/data/test/v1"
+)
+
+type tlsTestSuite struct {
+	suite.Suite
+	ca         *x509.Certificate
+	caKey      *ecdsa.PrivateKey
+	caPool     *x509.CertPool
+	serverCert tls.Certificate
+	clientCert tls.Certificate
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestTLSTestSuite(t *testing.T) {
+	suite.Run(t, new(tlsTestSuite))
+}
+
+func (s *tlsTestSuite) SetupSuite() {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	s.Require().NoError(err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	s.Require().NoError(err)
+	s.ca, err = x509.ParseCertificate(der)
+	s.Require().NoError(err)
+
+	s.caKey = key
+	s.caPool = x509.NewCertPool()
+	s.caPool.AddCert(s.ca)
+	s.serverCert = s.issue(2, x509.ExtKeyUsageServerAuth)
+	s.clientCert = s.issue(3, x509.ExtKeyUsageClientAuth)
+}
+
+func (s *tlsTestSuite) TestWithMutualTLS() {
+	s.Run("with client certificate", func() {
+		addr := s.start(NewServerBuilder().WithMutualTLS(&s.serverCert, s.caPool, WithTLSMinVersion(tls.VersionTLS13)))
+		_, err := s.sayHello(addr, &s.clientCert)
+		s.Assert().NoError(err)
+	})
+	s.Run("without client certificate", func() {
+		addr := s.start(NewServerBuilder().WithMutualTLS(&s.serverCert, s.caPool))
+		_, err := s.sayHello(addr, nil)
+		s.Assert().Error(err)
+	})
+}
+
+func (s *tlsTestSuite) TestWithMutualTLSFiles() {
+	s.Run("with valid files", func() {
+		dir := s.T().TempDir()
+		certFile, keyFile := s.writeKeyPair(dir, s.serverCert)
+		caFile := filepath.Join(dir, "ca.pem")
+		s.Require().NoError(os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: s.ca.Raw}), 0o600))
+
+		builder := NewServerBuilderFromConfig(&Config{
+			ServiceName:     "hello",
+			GrpcHost:        "127.0.0.1",
+			TLSCertFile:     certFile,
+			TLSKeyFile:      keyFile,
+			TLSClientCAFile: caFile,
+		})
+		addr := s.start(builder)
+		_, err := s.sayHello(addr, &s.clientCert)
+		s.Assert().NoError(err)
+	})
+	s.Run("with invalid files", func() {
+		dir := s.T().TempDir()
+		builder := NewServerBuilder().WithMutualTLSFiles(filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem"), filepath.Join(dir, "ca.pem"))
+		srv, err := builder.Build()
+		s.Assert().Error(err)
+		s.Assert().Nil(srv)
+	})
+}
+
+func (s *tlsTestSuite) TestNewMutualTLSConfig() {
+	config := NewMutualTLSConfig(&s.serverCert, s.caPool, WithTLSCipherSuites(tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256))
+	s.Assert().Equal(tls.RequireAndVerifyClientCert, config.ClientAuth)
+	s.Assert().EqualValues(tls.VersionTLS12, config.MinVersion)
+	s.Assert().Equal([]uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256}, config.CipherSuites)
+}
+
+// start builds and starts the server and returns its address
+func (s *tlsTestSuite) start(builder *ServerBuilder) string {
+	port := dynaport.Get(1)[0]
+	srv, err := builder.
+		WithHost("127.0.0.1").
+		WithPort(port).
+		WithService(&MockedService{}).
+		Build()
+	s.Require().NoError(err)
+	s.Require().NoError(srv.Start(context.TODO()))
+	s.T().Cleanup(func() { _ = srv.Stop(context.TODO()) })
+	return net.JoinHostPort("127.0.0.1", fmt.Sprint(port))
+}
+
+// sayHello calls the mocked service using the given client certificate
+func (s *tlsTestSuite) sayHello(addr string, clientCert *tls.Certificate) (*testv1.HelloReply, error) {
+	config := &tls.Config{
+		RootCAs:    s.caPool,
+		ServerName: "localhost",
+		MinVersion: tls.VersionTLS12,
+	}
+	if clientCert != nil {
+		config.Certificates = []tls.Certificate{*clientCert}
+	}
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(credentials.NewTLS(config)))
+	s.Require().NoError(err)
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+	return testv1.NewGreeterClient(conn).SayHello(ctx, &testv1.HelloRequest{Name: "test"})
+}
+
+// issue creates a certificate signed by the test certificate authority
+func (s *tlsTestSuite) issue(serial int64, usage x509.ExtKeyUsage) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	s.Require().NoError(err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, s.ca, &key.PublicKey, s.caKey)
+	s.Require().NoError(err)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+// writeKeyPair writes the certificate and its private key as PEM files
+func (s *tlsTestSuite) writeKeyPair(dir string, cert tls.Certificate) (string, string) {
+	keyDER, err := x509.MarshalECPrivateKey(cert.PrivateKey.(*ecdsa.PrivateKey))
+	s.Require().NoError(err)
+
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	s.Require().NoError(os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]}), 0o600))
+	s.Require().NoError(os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile
+}
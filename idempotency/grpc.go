@@ -0,0 +1,126 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package idempotency
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// NewUnaryServerInterceptor returns a unary server interceptor that short-circuits duplicate requests.
+// Requests carrying an idempotency key in their metadata are processed once, subsequent requests
+// with the same key and method receive the recorded response. A request arriving while the first one is
+// still being processed is rejected with codes.Aborted. When the handler fails or panics the key is released
+// so that the request can be retried.
+func NewUnaryServerInterceptor(store Store, opts ...Option) grpc.UnaryServerInterceptor {
+	cfg := newConfig(opts...)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		idempotencyKey := keyFromMetadata(ctx, cfg.metadataKey)
+		if idempotencyKey == "" {
+			return handler(ctx, req)
+		}
+
+		// scope the key to the method
+		key := info.FullMethod + ":" + idempotencyKey
+		record, reserved, err := store.Reserve(ctx, key, cfg.lease)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to reserve the idempotency key: %v", err)
+		}
+
+		if !reserved {
+			return replayGrpcResponse(record)
+		}
+
+		// release the key unless the response is recorded, including when the handler panics
+		completed := false
+		defer func() {
+			if !completed {
+				release(ctx, store, key)
+			}
+		}()
+
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		message, ok := resp.(proto.Message)
+		if !ok {
+			return resp, nil
+		}
+
+		// the request has been processed hence we do not fail it when the response cannot be recorded
+		if payload, err := marshalGrpcResponse(message); err == nil {
+			completed = complete(ctx, store, key, payload, cfg.ttl) == nil
+		}
+		return resp, nil
+	}
+}
+
+// keyFromMetadata returns the idempotency key set in the incoming metadata
+func keyFromMetadata(ctx context.Context, metadataKey string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	values := md.Get(metadataKey)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// marshalGrpcResponse encodes the response with its type information
+func marshalGrpcResponse(message proto.Message) ([]byte, error) {
+	packed, err := anypb.New(message)
+	if err != nil {
+		return nil, err
+	}
+	return proto.Marshal(packed)
+}
+
+// replayGrpcResponse returns the recorded response of a duplicate request
+func replayGrpcResponse(record *Record) (interface{}, error) {
+	if record.Status != StatusCompleted {
+		return nil, status.Error(codes.Aborted, "a request with the same idempotency key is in progress")
+	}
+
+	packed := new(anypb.Any)
+	if err := proto.Unmarshal(record.Response, packed); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to decode the recorded response: %v", err)
+	}
+
+	message, err := packed.UnmarshalNew()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to decode the recorded response: %v", err)
+	}
+	return message, nil
+}
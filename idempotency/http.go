@@ -0,0 +1,144 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package idempotency
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+)
+
+// ReplayedHeader is set on the responses replayed from the store
+const ReplayedHeader = "Idempotent-Replayed"
+
+// httpResponse is the recorded HTTP response
+type httpResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+}
+
+// Middleware returns an HTTP middleware that short-circuits duplicate requests.
+// Requests carrying an idempotency key header are processed once, subsequent requests with the same key,
+// method and path receive the recorded response. A request arriving while the first one is still being
+// processed is rejected with http.StatusConflict. Server errors and panics are not recorded so that the request
+// can be retried.
+func Middleware(store Store, opts ...Option) func(next http.Handler) http.Handler {
+	cfg := newConfig(opts...)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			idempotencyKey := r.Header.Get(cfg.headerKey)
+			if idempotencyKey == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := r.Context()
+			// scope the key to the route
+			key := r.Method + ":" + r.URL.Path + ":" + idempotencyKey
+			record, reserved, err := store.Reserve(ctx, key, cfg.lease)
+			if err != nil {
+				http.Error(w, "failed to reserve the idempotency key", http.StatusInternalServerError)
+				return
+			}
+
+			if !reserved {
+				replayHTTPResponse(w, record)
+				return
+			}
+
+			// release the key unless the response is recorded, including when the handler panics
+			completed := false
+			defer func() {
+				if !completed {
+					release(ctx, store, key)
+				}
+			}()
+
+			recorder := &responseRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(recorder, r)
+
+			if recorder.statusCode >= http.StatusInternalServerError {
+				return
+			}
+
+			payload, err := json.Marshal(&httpResponse{
+				StatusCode: recorder.statusCode,
+				Header:     w.Header().Clone(),
+				Body:       recorder.body.Bytes(),
+			})
+			if err == nil {
+				completed = complete(ctx, store, key, payload, cfg.ttl) == nil
+			}
+		})
+	}
+}
+
+// replayHTTPResponse writes the recorded response of a duplicate request
+func replayHTTPResponse(w http.ResponseWriter, record *Record) {
+	if record.Status != StatusCompleted {
+		http.Error(w, "a request with the same idempotency key is in progress", http.StatusConflict)
+		return
+	}
+
+	resp := new(httpResponse)
+	if err := json.Unmarshal(record.Response, resp); err != nil {
+		http.Error(w, "failed to decode the recorded response", http.StatusInternalServerError)
+		return
+	}
+
+	for name, values := range resp.Header {
+		for _, value := range values {
+			w.Header().Add(name, value)
+		}
+	}
+	w.Header().Set(ReplayedHeader, "true")
+	w.WriteHeader(resp.StatusCode)
+	_, _ = w.Write(resp.Body)
+}
+
+// responseRecorder captures the status code and body written by the handler
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+// WriteHeader records the status code
+func (r *responseRecorder) WriteHeader(statusCode int) {
+	if !r.wroteHeader {
+		r.statusCode = statusCode
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write records the body
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,358 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/tochemey/gopack/clock"
+	testpb "github.com/tochemey/gopack/test/data/test/v1"
+)
+
+func TestMemoryStore(t *testing.T) {
+	t.Run("with reserve and complete", func(t *testing.T) {
+		ctx := context.TODO()
+		store := NewMemoryStore()
+
+		record, reserved, err := store.Reserve(ctx, "key", time.Minute)
+		require.NoError(t, err)
+		assert.True(t, reserved)
+		assert.Equal(t, StatusInProgress, record.Status)
+
+		record, reserved, err = store.Reserve(ctx, "key", time.Minute)
+		require.NoError(t, err)
+		assert.False(t, reserved)
+		assert.Equal(t, StatusInProgress, record.Status)
+
+		require.NoError(t, store.Complete(ctx, "key", []byte("response"), time.Hour))
+		record, err = store.Get(ctx, "key")
+		require.NoError(t, err)
+		assert.Equal(t, StatusCompleted, record.Status)
+		assert.Equal(t, []byte("response"), record.Response)
+	})
+	t.Run("with release", func(t *testing.T) {
+		ctx := context.TODO()
+		store := NewMemoryStore()
+
+		_, reserved, err := store.Reserve(ctx, "key", time.Minute)
+		require.NoError(t, err)
+		require.True(t, reserved)
+		require.NoError(t, store.Release(ctx, "key"))
+
+		_, err = store.Get(ctx, "key")
+		assert.ErrorIs(t, err, ErrRecordNotFound)
+		_, reserved, err = store.Reserve(ctx, "key", time.Minute)
+		require.NoError(t, err)
+		assert.True(t, reserved)
+	})
+	t.Run("with expired record", func(t *testing.T) {
+		ctx := context.TODO()
+		fake := clock.NewFake(time.Now())
+		store := NewMemoryStoreWithClock(fake)
+
+		_, reserved, err := store.Reserve(ctx, "key", time.Minute)
+		require.NoError(t, err)
+		require.True(t, reserved)
+
+		fake.Advance(time.Minute)
+		_, err = store.Get(ctx, "key")
+		assert.ErrorIs(t, err, ErrRecordNotFound)
+		_, reserved, err = store.Reserve(ctx, "key", time.Minute)
+		require.NoError(t, err)
+		assert.True(t, reserved)
+	})
+	t.Run("with lease", func(t *testing.T) {
+		ctx := context.TODO()
+		fake := clock.NewFake(time.Now())
+		store := NewMemoryStoreWithClock(fake)
+
+		_, reserved, err := store.Reserve(ctx, "key", time.Minute)
+		require.NoError(t, err)
+		require.True(t, reserved)
+		require.NoError(t, store.Complete(ctx, "key", []byte("response"), time.Hour))
+
+		// the completed record expires after its ttl rather than the lease
+		fake.Advance(time.Minute)
+		record, err := store.Get(ctx, "key")
+		require.NoError(t, err)
+		assert.Equal(t, StatusCompleted, record.Status)
+		fake.Advance(time.Hour)
+		_, err = store.Get(ctx, "key")
+		assert.ErrorIs(t, err, ErrRecordNotFound)
+	})
+	t.Run("with complete of unknown key", func(t *testing.T) {
+		store := NewMemoryStore()
+		assert.ErrorIs(t, store.Complete(context.TODO(), "key", nil, time.Hour), ErrRecordNotFound)
+	})
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.v1.Greeter/SayHello"}
+	newContext := func(key string) context.Context {
+		return metadata.NewIncomingContext(context.TODO(), metadata.Pairs(DefaultMetadataKey, key))
+	}
+
+	t.Run("with duplicate request", func(t *testing.T) {
+		interceptor := NewUnaryServerInterceptor(NewMemoryStore())
+		var calls atomic.Int32
+		handler := func(_ context.Context, req any) (any, error) {
+			n := calls.Add(1)
+			return &testpb.HelloReply{Message: fmt.Sprintf("hello %s %d", req.(*testpb.HelloRequest).GetName(), n)}, nil
+		}
+
+		req := &testpb.HelloRequest{Name: "gopack"}
+		first, err := interceptor(newContext("key"), req, info, handler)
+		require.NoError(t, err)
+		second, err := interceptor(newContext("key"), req, info, handler)
+		require.NoError(t, err)
+
+		assert.EqualValues(t, 1, calls.Load())
+		assert.True(t, proto.Equal(first.(proto.Message), second.(proto.Message)))
+		assert.Equal(t, "hello gopack 1", second.(*testpb.HelloReply).GetMessage())
+	})
+	t.Run("with request in progress", func(t *testing.T) {
+		store := NewMemoryStore()
+		interceptor := NewUnaryServerInterceptor(store)
+		_, _, err := store.Reserve(context.TODO(), info.FullMethod+":key", time.Minute)
+		require.NoError(t, err)
+
+		_, err = interceptor(newContext("key"), &testpb.HelloRequest{}, info, func(context.Context, any) (any, error) {
+			return &testpb.HelloReply{}, nil
+		})
+		assert.Equal(t, codes.Aborted, status.Code(err))
+	})
+	t.Run("with handler error", func(t *testing.T) {
+		interceptor := NewUnaryServerInterceptor(NewMemoryStore())
+		failure := status.Error(codes.Unavailable, "unavailable")
+		var calls atomic.Int32
+		handler := func(context.Context, any) (any, error) {
+			if calls.Add(1) == 1 {
+				return nil, failure
+			}
+			return &testpb.HelloReply{Message: "hello"}, nil
+		}
+
+		_, err := interceptor(newContext("key"), &testpb.HelloRequest{}, info, handler)
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+		resp, err := interceptor(newContext("key"), &testpb.HelloRequest{}, info, handler)
+		require.NoError(t, err)
+		assert.Equal(t, "hello", resp.(*testpb.HelloReply).GetMessage())
+		assert.EqualValues(t, 2, calls.Load())
+	})
+	t.Run("with cancelled request", func(t *testing.T) {
+		store := &contextStore{NewMemoryStore()}
+		interceptor := NewUnaryServerInterceptor(store)
+		ctx, cancel := context.WithCancel(newContext("key"))
+		_, err := interceptor(ctx, &testpb.HelloRequest{}, info, func(context.Context, any) (any, error) {
+			cancel()
+			return nil, status.Error(codes.Canceled, "canceled")
+		})
+		assert.Equal(t, codes.Canceled, status.Code(err))
+
+		// the key is released despite the cancelled request
+		_, err = store.Get(context.TODO(), info.FullMethod+":key")
+		assert.ErrorIs(t, err, ErrRecordNotFound)
+	})
+	t.Run("with handler panic", func(t *testing.T) {
+		store := NewMemoryStore()
+		interceptor := NewUnaryServerInterceptor(store)
+		assert.Panics(t, func() {
+			_, _ = interceptor(newContext("key"), &testpb.HelloRequest{}, info, func(context.Context, any) (any, error) {
+				panic("handler failure")
+			})
+		})
+
+		_, err := store.Get(context.TODO(), info.FullMethod+":key")
+		assert.ErrorIs(t, err, ErrRecordNotFound)
+	})
+	t.Run("with reservation lease", func(t *testing.T) {
+		fake := clock.NewFake(time.Now())
+		store := NewMemoryStoreWithClock(fake)
+		interceptor := NewUnaryServerInterceptor(store, WithLease(time.Second), WithTTL(time.Hour))
+		_, err := interceptor(newContext("key"), &testpb.HelloRequest{}, info, func(context.Context, any) (any, error) {
+			record, err := store.Get(context.TODO(), info.FullMethod+":key")
+			require.NoError(t, err)
+			assert.Equal(t, fake.Now().Add(time.Second), record.ExpiresAt)
+			return &testpb.HelloReply{}, nil
+		})
+		require.NoError(t, err)
+
+		record, err := store.Get(context.TODO(), info.FullMethod+":key")
+		require.NoError(t, err)
+		assert.Equal(t, fake.Now().Add(time.Hour), record.ExpiresAt)
+	})
+	t.Run("without idempotency key", func(t *testing.T) {
+		interceptor := NewUnaryServerInterceptor(NewMemoryStore())
+		var calls atomic.Int32
+		handler := func(context.Context, any) (any, error) {
+			calls.Add(1)
+			return &testpb.HelloReply{}, nil
+		}
+
+		for i := 0; i < 2; i++ {
+			_, err := interceptor(context.TODO(), &testpb.HelloRequest{}, info, handler)
+			require.NoError(t, err)
+		}
+		assert.EqualValues(t, 2, calls.Load())
+	})
+}
+
+func TestMiddleware(t *testing.T) {
+	newRequest := func(key string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/orders", nil)
+		if key != "" {
+			req.Header.Set(DefaultHeaderKey, key)
+		}
+		return req
+	}
+
+	t.Run("with duplicate request", func(t *testing.T) {
+		var calls atomic.Int32
+		handler := Middleware(NewMemoryStore())(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			n := calls.Add(1)
+			w.Header().Set("Content-Type", "text/plain")
+			w.WriteHeader(http.StatusCreated)
+			_, _ = fmt.Fprintf(w, "order %d", n)
+		}))
+
+		first := httptest.NewRecorder()
+		handler.ServeHTTP(first, newRequest("key"))
+		second := httptest.NewRecorder()
+		handler.ServeHTTP(second, newRequest("key"))
+
+		assert.EqualValues(t, 1, calls.Load())
+		assert.Equal(t, http.StatusCreated, second.Code)
+		assert.Equal(t, "order 1", second.Body.String())
+		assert.Equal(t, "text/plain", second.Header().Get("Content-Type"))
+		assert.Equal(t, "true", second.Header().Get(ReplayedHeader))
+		assert.Empty(t, first.Header().Get(ReplayedHeader))
+	})
+	t.Run("with request in progress", func(t *testing.T) {
+		store := NewMemoryStore()
+		_, _, err := store.Reserve(context.TODO(), "POST:/orders:key", time.Minute)
+		require.NoError(t, err)
+
+		handler := Middleware(store)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+		}))
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, newRequest("key"))
+		assert.Equal(t, http.StatusConflict, recorder.Code)
+	})
+	t.Run("with server error", func(t *testing.T) {
+		var calls atomic.Int32
+		handler := Middleware(NewMemoryStore())(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			if calls.Add(1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+		}))
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, newRequest("key"))
+		assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+
+		recorder = httptest.NewRecorder()
+		handler.ServeHTTP(recorder, newRequest("key"))
+		assert.Equal(t, http.StatusCreated, recorder.Code)
+		assert.EqualValues(t, 2, calls.Load())
+	})
+	t.Run("with cancelled request", func(t *testing.T) {
+		store := &contextStore{NewMemoryStore()}
+		ctx, cancel := context.WithCancel(context.TODO())
+		handler := Middleware(store)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			cancel()
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		handler.ServeHTTP(httptest.NewRecorder(), newRequest("key").WithContext(ctx))
+
+		_, err := store.Get(context.TODO(), "POST:/orders:key")
+		assert.ErrorIs(t, err, ErrRecordNotFound)
+	})
+	t.Run("with handler panic", func(t *testing.T) {
+		store := NewMemoryStore()
+		handler := Middleware(store)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+			panic("handler failure")
+		}))
+		assert.Panics(t, func() {
+			handler.ServeHTTP(httptest.NewRecorder(), newRequest("key"))
+		})
+
+		_, err := store.Get(context.TODO(), "POST:/orders:key")
+		assert.ErrorIs(t, err, ErrRecordNotFound)
+	})
+	t.Run("with store error", func(t *testing.T) {
+		handler := Middleware(&failingStore{})(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+		}))
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, newRequest("key"))
+		assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	})
+}
+
+// failingStore is a Store that always fails
+type failingStore struct {
+	*MemoryStore
+}
+
+func (*failingStore) Reserve(context.Context, string, time.Duration) (*Record, bool, error) {
+	return nil, false, errors.New("store failure")
+}
+
+// contextStore is a Store failing the calls made with a cancelled context like the remote stores
+type contextStore struct {
+	*MemoryStore
+}
+
+func (s *contextStore) Complete(ctx context.Context, key string, response []byte, ttl time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.MemoryStore.Complete(ctx, key, response, ttl)
+}
+
+func (s *contextStore) Release(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.MemoryStore.Release(ctx, key)
+}
@@ -0,0 +1,118 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/tochemey/gopack/clock"
+)
+
+// MemoryStore is an in-memory Store. It is suitable for tests and single instance services.
+type MemoryStore struct {
+	mu      sync.Mutex
+	records map[string]*Record
+	clock   clock.Clock
+}
+
+// enforce compilation error
+var _ Store = (*MemoryStore)(nil)
+
+// NewMemoryStore creates an instance of MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return NewMemoryStoreWithClock(clock.New())
+}
+
+// NewMemoryStoreWithClock creates an instance of MemoryStore using the given clock to expire the records
+func NewMemoryStoreWithClock(clock clock.Clock) *MemoryStore {
+	return &MemoryStore{
+		records: make(map[string]*Record),
+		clock:   clock,
+	}
+}
+
+// Reserve atomically creates an in-progress record for the given key
+func (s *MemoryStore) Reserve(_ context.Context, key string, lease time.Duration) (*Record, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	if record, ok := s.records[key]; ok && now.Before(record.ExpiresAt) {
+		return copyRecord(record), false, nil
+	}
+
+	record := &Record{
+		Key:       key,
+		Status:    StatusInProgress,
+		ExpiresAt: now.Add(lease),
+	}
+	s.records[key] = record
+	return copyRecord(record), true, nil
+}
+
+// Complete marks the record as completed and stores the response payload
+func (s *MemoryStore) Complete(_ context.Context, key string, response []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.records[key]
+	if !ok {
+		return ErrRecordNotFound
+	}
+
+	record.Status = StatusCompleted
+	record.Response = append([]byte(nil), response...)
+	record.ExpiresAt = s.clock.Now().Add(ttl)
+	return nil
+}
+
+// Release removes the record
+func (s *MemoryStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, key)
+	return nil
+}
+
+// Get returns the record of the given key
+func (s *MemoryStore) Get(_ context.Context, key string) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.records[key]
+	if !ok || !s.clock.Now().Before(record.ExpiresAt) {
+		return nil, ErrRecordNotFound
+	}
+	return copyRecord(record), nil
+}
+
+// copyRecord returns a copy of the record
+func copyRecord(record *Record) *Record {
+	clone := *record
+	clone.Response = append([]byte(nil), record.Response...)
+	return &clone
+}
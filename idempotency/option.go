@@ -0,0 +1,106 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package idempotency
+
+import "time"
+
+const (
+	// DefaultMetadataKey is the gRPC metadata key carrying the idempotency key
+	DefaultMetadataKey = "idempotency-key"
+	// DefaultHeaderKey is the HTTP header carrying the idempotency key
+	DefaultHeaderKey = "Idempotency-Key"
+	// DefaultTTL is the default time to live of a completed idempotency record
+	DefaultTTL = 24 * time.Hour
+	// DefaultLease is the default time to live of an in-progress idempotency record
+	DefaultLease = time.Minute
+)
+
+// config holds the interceptor and middleware settings
+type config struct {
+	metadataKey string
+	headerKey   string
+	ttl         time.Duration
+	lease       time.Duration
+}
+
+// newConfig creates the default config and applies the given options
+func newConfig(opts ...Option) *config {
+	cfg := &config{
+		metadataKey: DefaultMetadataKey,
+		headerKey:   DefaultHeaderKey,
+		ttl:         DefaultTTL,
+		lease:       DefaultLease,
+	}
+	for _, opt := range opts {
+		opt.Apply(cfg)
+	}
+	return cfg
+}
+
+// Option is the interface that applies a configuration option.
+type Option interface {
+	// Apply sets the Option value of a config.
+	Apply(*config)
+}
+
+var _ Option = OptionFunc(nil)
+
+// OptionFunc implements the Option interface.
+type OptionFunc func(*config)
+
+// Apply applies the option
+func (f OptionFunc) Apply(c *config) {
+	f(c)
+}
+
+// WithMetadataKey sets the gRPC metadata key carrying the idempotency key
+func WithMetadataKey(key string) Option {
+	return OptionFunc(func(c *config) {
+		c.metadataKey = key
+	})
+}
+
+// WithHeaderKey sets the HTTP header carrying the idempotency key
+func WithHeaderKey(key string) Option {
+	return OptionFunc(func(c *config) {
+		c.headerKey = key
+	})
+}
+
+// WithTTL sets the time to live of the completed idempotency records
+func WithTTL(ttl time.Duration) Option {
+	return OptionFunc(func(c *config) {
+		c.ttl = ttl
+	})
+}
+
+// WithLease sets the time to live of the in-progress idempotency records.
+// A request whose processing outlives its lease can be processed again by a duplicate request,
+// hence the lease should exceed the processing time of the requests.
+func WithLease(lease time.Duration) Option {
+	return OptionFunc(func(c *config) {
+		c.lease = lease
+	})
+}
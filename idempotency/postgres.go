@@ -0,0 +1,163 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/tochemey/gopack/postgres"
+)
+
+// PostgresTableSchema is the statement creating the table used by the PostgresStore.
+// The %s placeholder is the table name.
+const PostgresTableSchema = `CREATE TABLE IF NOT EXISTS %s (
+	idempotency_key TEXT PRIMARY KEY,
+	status SMALLINT NOT NULL,
+	response BYTEA,
+	expires_at TIMESTAMPTZ NOT NULL
+);`
+
+// PostgresStore is a Store backed by a postgres table
+type PostgresStore struct {
+	db        postgres.Postgres
+	tableName string
+}
+
+// enforce compilation error
+var _ Store = (*PostgresStore)(nil)
+
+// postgresRecord maps a row of the idempotency table
+type postgresRecord struct {
+	IdempotencyKey string
+	Status         int
+	Response       []byte
+	ExpiresAt      time.Time
+}
+
+// NewPostgresStore creates an instance of PostgresStore using the given connected database
+// and table name. The table can be created with CreateTable.
+func NewPostgresStore(db postgres.Postgres, tableName string) *PostgresStore {
+	return &PostgresStore{
+		db:        db,
+		tableName: tableName,
+	}
+}
+
+// CreateTable creates the idempotency table when it does not exist
+func (s *PostgresStore) CreateTable(ctx context.Context) error {
+	_, err := s.db.Exec(ctx, fmt.Sprintf(PostgresTableSchema, s.tableName))
+	return err
+}
+
+// Reserve atomically creates an in-progress record for the given key.
+// An expired record is taken over. The expiry is computed by the database
+// so that the records expire with a single clock.
+func (s *PostgresStore) Reserve(ctx context.Context, key string, lease time.Duration) (*Record, bool, error) {
+	statement := fmt.Sprintf(`INSERT INTO %[1]s (idempotency_key, status, response, expires_at)
+	VALUES ($1, $2, NULL, NOW() + make_interval(secs => $3))
+	ON CONFLICT (idempotency_key) DO UPDATE
+	SET status = EXCLUDED.status, response = NULL, expires_at = EXCLUDED.expires_at
+	WHERE %[1]s.expires_at <= NOW()
+	RETURNING idempotency_key, status, response, expires_at`, s.tableName)
+
+	for attempt := 1; ; attempt++ {
+		row := new(postgresRecord)
+		if err := s.db.Select(ctx, row, statement, key, int(StatusInProgress), lease.Seconds()); err != nil {
+			return nil, false, err
+		}
+
+		// no row is returned when the key is held by an unexpired record
+		if row.IdempotencyKey != "" {
+			return &Record{
+				Key:       key,
+				Status:    StatusInProgress,
+				ExpiresAt: row.ExpiresAt,
+			}, true, nil
+		}
+
+		record, err := s.Get(ctx, key)
+		if err != nil {
+			// the conflicting record has expired or has been released in the meantime
+			if errors.Is(err, ErrRecordNotFound) && attempt < maxReserveAttempts {
+				continue
+			}
+			return nil, false, err
+		}
+		return record, false, nil
+	}
+}
+
+// Complete marks the record as completed and stores the response payload
+func (s *PostgresStore) Complete(ctx context.Context, key string, response []byte, ttl time.Duration) error {
+	statement := fmt.Sprintf(`UPDATE %s SET status = $1, response = $2, expires_at = NOW() + make_interval(secs => $3)
+	WHERE idempotency_key = $4`, s.tableName)
+	result, err := s.db.Exec(ctx, statement, int(StatusCompleted), response, ttl.Seconds(), key)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+// Release removes the record
+func (s *PostgresStore) Release(ctx context.Context, key string) error {
+	statement := fmt.Sprintf("DELETE FROM %s WHERE idempotency_key = $1", s.tableName)
+	_, err := s.db.Exec(ctx, statement, key)
+	return err
+}
+
+// Get returns the record of the given key
+func (s *PostgresStore) Get(ctx context.Context, key string) (*Record, error) {
+	statement := fmt.Sprintf(`SELECT idempotency_key, status, response, expires_at
+	FROM %s WHERE idempotency_key = $1 AND expires_at > NOW()`, s.tableName)
+
+	row := new(postgresRecord)
+	if err := s.db.Select(ctx, row, statement, key); err != nil {
+		return nil, err
+	}
+
+	// Select does not return an error when there is no row
+	if row.IdempotencyKey == "" {
+		return nil, ErrRecordNotFound
+	}
+
+	return &Record{
+		Key:       row.IdempotencyKey,
+		Status:    Status(row.Status),
+		Response:  row.Response,
+		ExpiresAt: row.ExpiresAt,
+	}, nil
+}
@@ -0,0 +1,175 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package idempotency
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tochemey/gopack/postgres"
+)
+
+// expiringDB deletes the record before its first read to mimic a record
+// expiring between the reservation attempt and its follow-up read
+type expiringDB struct {
+	postgres.Postgres
+	tableName string
+	expired   bool
+}
+
+func (d *expiringDB) Select(ctx context.Context, dst any, query string, args ...any) error {
+	// the reservation is an INSERT returning the reserved row
+	if !d.expired && strings.HasPrefix(strings.TrimSpace(query), "SELECT") {
+		d.expired = true
+		if _, err := d.Postgres.Exec(ctx, fmt.Sprintf("DELETE FROM %s", d.tableName)); err != nil {
+			return err
+		}
+	}
+	return d.Postgres.Select(ctx, dst, query, args...)
+}
+
+type postgresStoreSuite struct {
+	suite.Suite
+	container *postgres.TestContainer
+}
+
+// SetupSuite starts the Postgres database engine and set the container
+// host and port to use in the tests
+func (s *postgresStoreSuite) SetupSuite() {
+	s.container = postgres.NewTestContainer("testdb", "test", "test")
+}
+
+func (s *postgresStoreSuite) TearDownSuite() {
+	s.container.Cleanup()
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestPostgresStoreSuite(t *testing.T) {
+	suite.Run(t, new(postgresStoreSuite))
+}
+
+// newStore connects to the test database and creates the idempotency table
+func (s *postgresStoreSuite) newStore(ctx context.Context) (*PostgresStore, *postgres.TestDB) {
+	db := s.container.GetTestDB()
+	s.Require().NoError(db.Connect(ctx))
+	store := NewPostgresStore(db, "idempotency_keys")
+	s.Require().NoError(store.CreateTable(ctx))
+	return store, db
+}
+
+// dropStore drops the idempotency table and disconnects from the test database
+func (s *postgresStoreSuite) dropStore(ctx context.Context, db *postgres.TestDB) {
+	s.Assert().NoError(db.DropTable(ctx, "idempotency_keys"))
+	s.Assert().NoError(db.Disconnect(ctx))
+}
+
+func (s *postgresStoreSuite) TestReserveConflict() {
+	ctx := context.TODO()
+	store, db := s.newStore(ctx)
+	defer s.dropStore(ctx, db)
+
+	record, reserved, err := store.Reserve(ctx, "key", time.Minute)
+	s.Require().NoError(err)
+	s.Assert().True(reserved)
+	s.Assert().Equal(StatusInProgress, record.Status)
+	// the expiry is set by the database clock
+	s.Assert().WithinDuration(time.Now().Add(time.Minute), record.ExpiresAt, 10*time.Second)
+
+	record, reserved, err = store.Reserve(ctx, "key", time.Minute)
+	s.Require().NoError(err)
+	s.Assert().False(reserved)
+	s.Assert().Equal("key", record.Key)
+	s.Assert().Equal(StatusInProgress, record.Status)
+}
+
+func (s *postgresStoreSuite) TestCompleteAndGet() {
+	ctx := context.TODO()
+	store, db := s.newStore(ctx)
+	defer s.dropStore(ctx, db)
+
+	_, reserved, err := store.Reserve(ctx, "key", time.Minute)
+	s.Require().NoError(err)
+	s.Require().True(reserved)
+	s.Require().NoError(store.Complete(ctx, "key", []byte("response"), time.Hour))
+
+	record, err := store.Get(ctx, "key")
+	s.Require().NoError(err)
+	s.Assert().Equal(StatusCompleted, record.Status)
+	s.Assert().Equal([]byte("response"), record.Response)
+	// the completed record outlives the lease of the reservation
+	s.Assert().WithinDuration(time.Now().Add(time.Hour), record.ExpiresAt, 10*time.Second)
+
+	record, reserved, err = store.Reserve(ctx, "key", time.Minute)
+	s.Require().NoError(err)
+	s.Assert().False(reserved)
+	s.Assert().Equal([]byte("response"), record.Response)
+
+	s.Require().NoError(store.Release(ctx, "key"))
+	_, err = store.Get(ctx, "key")
+	s.Assert().ErrorIs(err, ErrRecordNotFound)
+	s.Assert().ErrorIs(store.Complete(ctx, "key", nil, time.Hour), ErrRecordNotFound)
+}
+
+func (s *postgresStoreSuite) TestExpiry() {
+	ctx := context.TODO()
+	store, db := s.newStore(ctx)
+	defer s.dropStore(ctx, db)
+
+	_, reserved, err := store.Reserve(ctx, "key", 100*time.Millisecond)
+	s.Require().NoError(err)
+	s.Require().True(reserved)
+
+	time.Sleep(200 * time.Millisecond)
+	_, err = store.Get(ctx, "key")
+	s.Assert().ErrorIs(err, ErrRecordNotFound)
+
+	// the expired record is taken over
+	record, reserved, err := store.Reserve(ctx, "key", time.Minute)
+	s.Require().NoError(err)
+	s.Assert().True(reserved)
+	s.Assert().Equal(StatusInProgress, record.Status)
+}
+
+func (s *postgresStoreSuite) TestReserveWithRecordExpiredBeforeItsRead() {
+	ctx := context.TODO()
+	store, db := s.newStore(ctx)
+	defer s.dropStore(ctx, db)
+
+	_, reserved, err := store.Reserve(ctx, "key", time.Minute)
+	s.Require().NoError(err)
+	s.Require().True(reserved)
+
+	racing := NewPostgresStore(&expiringDB{Postgres: db, tableName: "idempotency_keys"}, "idempotency_keys")
+	record, reserved, err := racing.Reserve(ctx, "key", time.Minute)
+	s.Require().NoError(err)
+	s.Assert().True(reserved)
+	s.Assert().Equal(StatusInProgress, record.Status)
+}
@@ -0,0 +1,154 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStore is a Store backed by Redis. Records expire using the Redis key TTL.
+type RedisStore struct {
+	client    redis.UniversalClient
+	keyPrefix string
+}
+
+// enforce compilation error
+var _ Store = (*RedisStore)(nil)
+
+// redisRecord is the JSON representation of a record stored in Redis
+type redisRecord struct {
+	Status    Status    `json:"status"`
+	Response  []byte    `json:"response,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// NewRedisStore creates an instance of RedisStore. Every key is prefixed with the given prefix.
+func NewRedisStore(client redis.UniversalClient, keyPrefix string) *RedisStore {
+	return &RedisStore{
+		client:    client,
+		keyPrefix: keyPrefix,
+	}
+}
+
+// Reserve atomically creates an in-progress record for the given key
+func (s *RedisStore) Reserve(ctx context.Context, key string, lease time.Duration) (*Record, bool, error) {
+	for attempt := 1; ; attempt++ {
+		record := &Record{
+			Key:       key,
+			Status:    StatusInProgress,
+			ExpiresAt: time.Now().Add(lease),
+		}
+
+		payload, err := encodeRedisRecord(record)
+		if err != nil {
+			return nil, false, err
+		}
+
+		reserved, err := s.client.SetNX(ctx, s.redisKey(key), payload, lease).Result()
+		if err != nil {
+			return nil, false, err
+		}
+
+		if reserved {
+			return record, true, nil
+		}
+
+		existing, err := s.Get(ctx, key)
+		if err != nil {
+			// the conflicting record has expired or has been released in the meantime
+			if errors.Is(err, ErrRecordNotFound) && attempt < maxReserveAttempts {
+				continue
+			}
+			return nil, false, err
+		}
+		return existing, false, nil
+	}
+}
+
+// Complete marks the record as completed and stores the response payload
+func (s *RedisStore) Complete(ctx context.Context, key string, response []byte, ttl time.Duration) error {
+	record, err := s.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	record.Status = StatusCompleted
+	record.Response = response
+	record.ExpiresAt = time.Now().Add(ttl)
+	payload, err := encodeRedisRecord(record)
+	if err != nil {
+		return err
+	}
+
+	// only update the existing key
+	return s.client.SetArgs(ctx, s.redisKey(key), payload, redis.SetArgs{Mode: "XX", TTL: ttl}).Err()
+}
+
+// Release removes the record
+func (s *RedisStore) Release(ctx context.Context, key string) error {
+	return s.client.Del(ctx, s.redisKey(key)).Err()
+}
+
+// Get returns the record of the given key
+func (s *RedisStore) Get(ctx context.Context, key string) (*Record, error) {
+	payload, err := s.client.Get(ctx, s.redisKey(key)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	stored := new(redisRecord)
+	if err := json.Unmarshal(payload, stored); err != nil {
+		return nil, err
+	}
+
+	return &Record{
+		Key:       key,
+		Status:    stored.Status,
+		Response:  stored.Response,
+		ExpiresAt: stored.ExpiresAt,
+	}, nil
+}
+
+// redisKey returns the prefixed Redis key
+func (s *RedisStore) redisKey(key string) string {
+	return s.keyPrefix + key
+}
+
+// encodeRedisRecord encodes the record into JSON
+func encodeRedisRecord(record *Record) ([]byte, error) {
+	return json.Marshal(&redisRecord{
+		Status:    record.Status,
+		Response:  record.Response,
+		ExpiresAt: record.ExpiresAt,
+	})
+}
@@ -0,0 +1,127 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package idempotency
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// expiringClient deletes the key before its first read to mimic a record
+// expiring between the reservation attempt and its follow-up read
+type expiringClient struct {
+	redis.UniversalClient
+	expired bool
+}
+
+func (c *expiringClient) Get(ctx context.Context, key string) *redis.StringCmd {
+	if !c.expired {
+		c.expired = true
+		c.UniversalClient.Del(ctx, key)
+	}
+	return c.UniversalClient.Get(ctx, key)
+}
+
+func TestRedisStore(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	t.Run("with reserve conflict", func(t *testing.T) {
+		ctx := context.TODO()
+		store := NewRedisStore(client, "conflict:")
+
+		record, reserved, err := store.Reserve(ctx, "key", time.Minute)
+		require.NoError(t, err)
+		assert.True(t, reserved)
+		assert.Equal(t, StatusInProgress, record.Status)
+		assert.True(t, server.Exists("conflict:key"))
+
+		record, reserved, err = store.Reserve(ctx, "key", time.Minute)
+		require.NoError(t, err)
+		assert.False(t, reserved)
+		assert.Equal(t, StatusInProgress, record.Status)
+	})
+	t.Run("with complete and get", func(t *testing.T) {
+		ctx := context.TODO()
+		store := NewRedisStore(client, "complete:")
+
+		_, reserved, err := store.Reserve(ctx, "key", time.Minute)
+		require.NoError(t, err)
+		require.True(t, reserved)
+		require.NoError(t, store.Complete(ctx, "key", []byte("response"), time.Hour))
+
+		record, err := store.Get(ctx, "key")
+		require.NoError(t, err)
+		assert.Equal(t, StatusCompleted, record.Status)
+		assert.Equal(t, []byte("response"), record.Response)
+		// the completed record outlives the lease of the reservation
+		assert.Equal(t, time.Hour, server.TTL("complete:key"))
+
+		record, reserved, err = store.Reserve(ctx, "key", time.Minute)
+		require.NoError(t, err)
+		assert.False(t, reserved)
+		assert.Equal(t, []byte("response"), record.Response)
+
+		require.NoError(t, store.Release(ctx, "key"))
+		_, err = store.Get(ctx, "key")
+		assert.ErrorIs(t, err, ErrRecordNotFound)
+		assert.ErrorIs(t, store.Complete(ctx, "key", nil, time.Hour), ErrRecordNotFound)
+	})
+	t.Run("with expiry", func(t *testing.T) {
+		ctx := context.TODO()
+		store := NewRedisStore(client, "expiry:")
+
+		_, reserved, err := store.Reserve(ctx, "key", time.Minute)
+		require.NoError(t, err)
+		require.True(t, reserved)
+
+		server.FastForward(time.Minute)
+		_, err = store.Get(ctx, "key")
+		assert.ErrorIs(t, err, ErrRecordNotFound)
+
+		_, reserved, err = store.Reserve(ctx, "key", time.Minute)
+		require.NoError(t, err)
+		assert.True(t, reserved)
+	})
+	t.Run("with conflicting record expired before its read", func(t *testing.T) {
+		ctx := context.TODO()
+		store := NewRedisStore(&expiringClient{UniversalClient: client}, "race:")
+		_, reserved, err := NewRedisStore(client, "race:").Reserve(ctx, "key", time.Minute)
+		require.NoError(t, err)
+		require.True(t, reserved)
+
+		record, reserved, err := store.Reserve(ctx, "key", time.Minute)
+		require.NoError(t, err)
+		assert.True(t, reserved)
+		assert.Equal(t, StatusInProgress, record.Status)
+	})
+}
@@ -0,0 +1,111 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+// Package idempotency records idempotency keys along with the response of the
+// request they identify so that duplicate requests can be short-circuited with
+// the stored response. It ships with postgres, Redis and in-memory stores, a gRPC
+// server interceptor and an HTTP middleware.
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrRecordNotFound is returned when the idempotency key is not known by the store
+var ErrRecordNotFound = errors.New("idempotency record not found")
+
+// maxReserveAttempts bounds the reservation attempts of the stores when the conflicting
+// record expires or is released before it can be read
+const maxReserveAttempts = 3
+
+// storeTimeout bounds the store calls made once the request has been processed
+const storeTimeout = 5 * time.Second
+
+// Status defines the state of an idempotency record
+type Status int
+
+const (
+	// StatusInProgress means the request identified by the key is being processed
+	StatusInProgress Status = iota
+	// StatusCompleted means the request identified by the key has completed and its response is recorded
+	StatusCompleted
+)
+
+// String returns the string representation of the status
+func (s Status) String() string {
+	switch s {
+	case StatusInProgress:
+		return "in-progress"
+	case StatusCompleted:
+		return "completed"
+	default:
+		return "unknown"
+	}
+}
+
+// Record is the state stored for an idempotency key
+type Record struct {
+	// Key is the idempotency key
+	Key string
+	// Status is the record status
+	Status Status
+	// Response is the recorded response payload. It is only set when the record is completed
+	Response []byte
+	// ExpiresAt is the time after which the record can be discarded
+	ExpiresAt time.Time
+}
+
+// Store will be implemented by the idempotency records storage backends
+type Store interface {
+	// Reserve atomically creates an in-progress record for the given key that expires after the lease.
+	// It returns true when the key has been reserved. When the key is already known
+	// the existing record is returned along with false.
+	Reserve(ctx context.Context, key string, lease time.Duration) (*Record, bool, error)
+	// Complete marks the record as completed, stores the response payload and
+	// sets the record to expire after the ttl
+	Complete(ctx context.Context, key string, response []byte, ttl time.Duration) error
+	// Release removes the record so that the request can be retried.
+	// This is used when the request processing has failed.
+	Release(ctx context.Context, key string) error
+	// Get returns the record of the given key or ErrRecordNotFound
+	Get(ctx context.Context, key string) (*Record, error)
+}
+
+// complete records the response of the processed request. The request context is detached
+// so that the response is recorded even when the request has been cancelled in the meantime.
+func complete(ctx context.Context, store Store, key string, response []byte, ttl time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), storeTimeout)
+	defer cancel()
+	return store.Complete(ctx, key, response, ttl)
+}
+
+// release removes the reservation of a failed request so that it can be retried.
+// The request context is detached since the failure is often its cancellation.
+func release(ctx context.Context, store Store, key string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), storeTimeout)
+	defer cancel()
+	_ = store.Release(ctx, key)
+}
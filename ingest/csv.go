@@ -0,0 +1,192 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package ingest
+
+import (
+	"encoding"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// csvBinding binds a CSV column to a struct field
+type csvBinding struct {
+	column int
+	name   string
+	field  int
+}
+
+// NewCSVReader creates a Reader decoding the CSV records of r into values of type T.
+// T must be a struct. The first CSV record is the header and the columns are bound
+// to the exported fields using the `csv` struct tag or, when missing, the field name.
+// The matching is case-insensitive, unknown columns are ignored and fields tagged
+// with `csv:"-"` are skipped. Supported field types are strings, booleans, integers,
+// floats, time.Duration, time.Time in RFC3339 format, pointers to those and types
+// implementing encoding.TextUnmarshaler. An empty value leaves the field to its zero value.
+func NewCSVReader[T any](r io.Reader, opts ...Option) *Reader[T] {
+	cfg := newConfig(opts...)
+	counter := &countingReader{reader: r}
+	csvReader := csv.NewReader(counter)
+	csvReader.Comma = cfg.comma
+	csvReader.ReuseRecord = true
+
+	var bindings []csvBinding
+	decode := func() (T, error) {
+		var record T
+		if bindings == nil {
+			header, err := csvReader.Read()
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					return record, io.EOF
+				}
+				return record, fmt.Errorf("failed to read the CSV header: %w", err)
+			}
+
+			bindings, err = bindCSVHeader(reflect.TypeOf(record), header)
+			if err != nil {
+				return record, err
+			}
+		}
+
+		fields, err := csvReader.Read()
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				return record, &RecordError{Line: parseErr.Line, Err: parseErr.Err}
+			}
+			return record, err
+		}
+
+		line, _ := csvReader.FieldPos(0)
+		value := reflect.ValueOf(&record).Elem()
+		for _, binding := range bindings {
+			if err := setField(value.Field(binding.field), fields[binding.column]); err != nil {
+				return record, &RecordError{Line: line, Err: fmt.Errorf("column %s: %w", binding.name, err)}
+			}
+		}
+		return record, nil
+	}
+
+	return newReader(counter, cfg, decode)
+}
+
+// bindCSVHeader binds the header columns to the struct fields
+func bindCSVHeader(recordType reflect.Type, header []string) ([]csvBinding, error) {
+	if recordType == nil || recordType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("CSV records can only be decoded into a struct, got %v", recordType)
+	}
+
+	bindings := make([]csvBinding, 0, len(header))
+	for column, name := range header {
+		name = strings.TrimSpace(name)
+		for i := 0; i < recordType.NumField(); i++ {
+			field := recordType.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			fieldName := field.Name
+			if tag, ok := field.Tag.Lookup("csv"); ok {
+				if tag == "-" {
+					continue
+				}
+				fieldName = tag
+			}
+
+			if strings.EqualFold(fieldName, name) {
+				bindings = append(bindings, csvBinding{column: column, name: name, field: i})
+				break
+			}
+		}
+	}
+	return bindings, nil
+}
+
+// setField parses the raw value into the given field
+func setField(field reflect.Value, raw string) error {
+	if raw == "" {
+		return nil
+	}
+
+	if field.Kind() == reflect.Pointer {
+		value := reflect.New(field.Type().Elem())
+		if err := setField(value.Elem(), raw); err != nil {
+			return err
+		}
+		field.Set(value)
+		return nil
+	}
+
+	if unmarshaler, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return unmarshaler.UnmarshalText([]byte(raw))
+	}
+
+	switch field.Interface().(type) {
+	case time.Duration:
+		duration, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(duration))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Bool:
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		field.SetBool(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value, err := strconv.ParseInt(raw, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(value)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value, err := strconv.ParseUint(raw, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(value)
+	case reflect.Float32, reflect.Float64:
+		value, err := strconv.ParseFloat(raw, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(value)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package ingest
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type order struct {
+	ID        string        `csv:"order_id" json:"order_id"`
+	Quantity  int           `csv:"quantity" json:"quantity"`
+	Price     float64       `csv:"price" json:"price"`
+	Paid      bool          `csv:"paid" json:"paid"`
+	Note      *string       `csv:"note" json:"note"`
+	CreatedAt time.Time     `csv:"created_at" json:"created_at"`
+	Timeout   time.Duration `csv:"timeout" json:"-"`
+	Ignored   string        `csv:"-" json:"-"`
+}
+
+func TestCSVReader(t *testing.T) {
+	t.Run("with valid records", func(t *testing.T) {
+		input := "order_id,quantity,price,paid,note,created_at,timeout,unknown\n" +
+			"o-1,2,9.99,true,fragile,2025-01-02T15:04:05Z,1m,x\n" +
+			"o-2,1,5,false,,2025-01-03T15:04:05Z,,y\n"
+		reader := NewCSVReader[order](strings.NewReader(input))
+		records := slices.Collect(reader.All(context.TODO()))
+		require.NoError(t, reader.Err())
+		require.Len(t, records, 2)
+
+		assert.Equal(t, "o-1", records[0].ID)
+		assert.Equal(t, 2, records[0].Quantity)
+		assert.Equal(t, 9.99, records[0].Price)
+		assert.True(t, records[0].Paid)
+		require.NotNil(t, records[0].Note)
+		assert.Equal(t, "fragile", *records[0].Note)
+		assert.Equal(t, time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC), records[0].CreatedAt)
+		assert.Equal(t, time.Minute, records[0].Timeout)
+		assert.Nil(t, records[1].Note)
+		assert.Empty(t, reader.Errors())
+
+		progress := reader.Progress()
+		assert.EqualValues(t, 2, progress.Records)
+		assert.EqualValues(t, len(input), progress.Bytes)
+	})
+	t.Run("with invalid records", func(t *testing.T) {
+		input := "order_id,quantity\n" +
+			"o-1,two\n" +
+			"o-2,2\n" +
+			"o-3,3,extra\n"
+		reader := NewCSVReader[order](strings.NewReader(input))
+		records := slices.Collect(reader.All(context.TODO()))
+		require.NoError(t, reader.Err())
+		require.Len(t, records, 1)
+		assert.Equal(t, "o-2", records[0].ID)
+
+		errs := reader.Errors()
+		require.Len(t, errs, 2)
+		assert.Equal(t, 2, errs[0].Line)
+		assert.Contains(t, errs[0].Error(), "column quantity")
+		assert.Equal(t, 4, errs[1].Line)
+		assert.EqualValues(t, 2, reader.Progress().Failed)
+	})
+	t.Run("with max errors exceeded", func(t *testing.T) {
+		input := "order_id,quantity\no-1,a\no-2,b\no-3,3\n"
+		reader := NewCSVReader[order](strings.NewReader(input), WithMaxErrors(1))
+		records := slices.Collect(reader.All(context.TODO()))
+		assert.Empty(t, records)
+		assert.ErrorIs(t, reader.Err(), ErrTooManyErrors)
+	})
+	t.Run("with a non struct record", func(t *testing.T) {
+		reader := NewCSVReader[int](strings.NewReader("id\n1\n"))
+		records := slices.Collect(reader.All(context.TODO()))
+		assert.Empty(t, records)
+		assert.Error(t, reader.Err())
+	})
+	t.Run("with custom delimiter", func(t *testing.T) {
+		reader := NewCSVReader[order](strings.NewReader("order_id;quantity\no-1;4\n"), WithComma(';'))
+		records := slices.Collect(reader.All(context.TODO()))
+		require.NoError(t, reader.Err())
+		require.Len(t, records, 1)
+		assert.Equal(t, 4, records[0].Quantity)
+	})
+	t.Run("with empty input", func(t *testing.T) {
+		reader := NewCSVReader[order](strings.NewReader(""))
+		assert.Empty(t, slices.Collect(reader.All(context.TODO())))
+		assert.NoError(t, reader.Err())
+	})
+}
+
+func TestNDJSONReader(t *testing.T) {
+	t.Run("with valid and invalid records", func(t *testing.T) {
+		input := `{"order_id":"o-1","quantity":2}` + "\n\n" +
+			`{"order_id":` + "\n" +
+			`{"order_id":"o-2","quantity":3}` + "\n"
+		reader := NewNDJSONReader[order](strings.NewReader(input))
+		records := slices.Collect(reader.All(context.TODO()))
+		require.NoError(t, reader.Err())
+		require.Len(t, records, 2)
+		assert.Equal(t, "o-1", records[0].ID)
+		assert.Equal(t, 3, records[1].Quantity)
+
+		errs := reader.Errors()
+		require.Len(t, errs, 1)
+		assert.Equal(t, 3, errs[0].Line)
+	})
+	t.Run("with line too long", func(t *testing.T) {
+		input := `{"order_id":"o-1"}` + "\n"
+		reader := NewNDJSONReader[order](strings.NewReader(input), WithMaxLineSize(4))
+		assert.Empty(t, slices.Collect(reader.All(context.TODO())))
+		assert.Error(t, reader.Err())
+	})
+	t.Run("with cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.TODO())
+		cancel()
+		reader := NewNDJSONReader[order](strings.NewReader(`{"order_id":"o-1"}`))
+		assert.Empty(t, slices.Collect(reader.All(ctx)))
+		assert.ErrorIs(t, reader.Err(), context.Canceled)
+	})
+}
+
+func TestProgress(t *testing.T) {
+	var builder strings.Builder
+	for i := 0; i < 5; i++ {
+		builder.WriteString(`{"order_id":"o"}` + "\n")
+	}
+
+	var reports []Progress
+	reader := NewNDJSONReader[order](strings.NewReader(builder.String()), WithProgress(2, func(progress Progress) {
+		reports = append(reports, progress)
+	}))
+	assert.Len(t, slices.Collect(reader.All(context.TODO())), 5)
+	require.NoError(t, reader.Err())
+
+	// every two records and once at the end
+	require.Len(t, reports, 3)
+	assert.EqualValues(t, 2, reports[0].Records)
+	assert.EqualValues(t, 4, reports[1].Records)
+	assert.EqualValues(t, 5, reports[2].Records)
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("with batches", func(t *testing.T) {
+		input := "order_id\no-1\no-2\no-3\no-4\no-5\n"
+		reader := NewCSVReader[order](strings.NewReader(input))
+
+		var batches [][]string
+		err := Load(context.TODO(), reader, 2, func(_ context.Context, batch []order) error {
+			ids := make([]string, 0, len(batch))
+			for _, record := range batch {
+				ids = append(ids, record.ID)
+			}
+			batches = append(batches, ids)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{{"o-1", "o-2"}, {"o-3", "o-4"}, {"o-5"}}, batches)
+	})
+	t.Run("with sink error", func(t *testing.T) {
+		reader := NewCSVReader[order](strings.NewReader("order_id\no-1\no-2\n"))
+		failure := errors.New("sink failure")
+		err := Load(context.TODO(), reader, 1, func(context.Context, []order) error {
+			return failure
+		})
+		assert.ErrorIs(t, err, failure)
+	})
+	t.Run("with reader error", func(t *testing.T) {
+		reader := NewCSVReader[order](strings.NewReader("order_id,quantity\no-1,a\no-2,b\n"), WithMaxErrors(1))
+		err := Load(context.TODO(), reader, 10, func(context.Context, []order) error {
+			return nil
+		})
+		assert.ErrorIs(t, err, ErrTooManyErrors)
+	})
+}
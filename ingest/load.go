@@ -0,0 +1,65 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package ingest
+
+import (
+	"context"
+
+	"github.com/tochemey/gopack/pipeline"
+	"github.com/tochemey/gopack/postgres"
+)
+
+// Sink receives the records in batches
+type Sink[T any] func(ctx context.Context, batch []T) error
+
+// Load streams the valid records of the reader into the sink in batches of at most batchSize records.
+// It stops at the first sink failure or when the reader stops on an error. The invalid records
+// are collected by the reader, see Reader.Errors.
+func Load[T any](ctx context.Context, reader *Reader[T], batchSize int, sink Sink[T]) error {
+	p := pipeline.New(ctx)
+	records := pipeline.FromSeq(p, reader.All(p.Context()))
+	batches := pipeline.Batch(p, records, batchSize, 0)
+	pipeline.ForEach(p, batches, func(ctx context.Context, batch []T) error {
+		return sink(ctx, batch)
+	})
+
+	if err := p.Wait(); err != nil {
+		return err
+	}
+	return reader.Err()
+}
+
+// PostgresSink creates a Sink bulk loading the batches into the given Postgres table using the COPY protocol.
+// toRow returns the values of the given columns for a record in the same order.
+func PostgresSink[T any](db postgres.Postgres, table string, columns []string, toRow func(record T) []any) Sink[T] {
+	return func(ctx context.Context, batch []T) error {
+		rows := make([][]any, 0, len(batch))
+		for _, record := range batch {
+			rows = append(rows, toRow(record))
+		}
+		_, err := postgres.CopyFrom(ctx, db, table, columns, rows)
+		return err
+	}
+}
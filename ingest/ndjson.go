@@ -0,0 +1,65 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package ingest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// NewNDJSONReader creates a Reader decoding the newline delimited JSON records of r into values of type T.
+// Blank lines are skipped. A line that cannot be decoded into T is collected as a RecordError.
+func NewNDJSONReader[T any](r io.Reader, opts ...Option) *Reader[T] {
+	cfg := newConfig(opts...)
+	counter := &countingReader{reader: r}
+	scanner := bufio.NewScanner(counter)
+	scanner.Buffer(make([]byte, 0, min(bufio.MaxScanTokenSize, cfg.maxLineSize)), cfg.maxLineSize)
+
+	line := 0
+	decode := func() (T, error) {
+		var record T
+		for scanner.Scan() {
+			line++
+			data := bytes.TrimSpace(scanner.Bytes())
+			if len(data) == 0 {
+				continue
+			}
+
+			if err := json.Unmarshal(data, &record); err != nil {
+				return record, &RecordError{Line: line, Err: err}
+			}
+			return record, nil
+		}
+
+		if err := scanner.Err(); err != nil {
+			return record, err
+		}
+		return record, io.EOF
+	}
+
+	return newReader(counter, cfg, decode)
+}
@@ -0,0 +1,98 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package ingest
+
+const (
+	// DefaultMaxLineSize is the default maximum size of a NDJSON line
+	DefaultMaxLineSize = 1024 * 1024
+)
+
+// config holds the readers settings
+type config struct {
+	maxErrors     int
+	progressEvery int64
+	onProgress    func(Progress)
+	comma         rune
+	maxLineSize   int
+}
+
+// newConfig creates the default config and applies the given options
+func newConfig(opts ...Option) *config {
+	cfg := &config{
+		comma:       ',',
+		maxLineSize: DefaultMaxLineSize,
+	}
+	for _, opt := range opts {
+		opt.Apply(cfg)
+	}
+	return cfg
+}
+
+// Option is the interface that applies a configuration option.
+type Option interface {
+	// Apply sets the Option value of a config.
+	Apply(*config)
+}
+
+var _ Option = OptionFunc(nil)
+
+// OptionFunc implements the Option interface.
+type OptionFunc func(*config)
+
+// Apply applies the option
+func (f OptionFunc) Apply(c *config) {
+	f(c)
+}
+
+// WithMaxErrors sets the maximum number of invalid records tolerated before the ingestion is aborted.
+// Zero, the default, means the invalid records are collected without limit.
+func WithMaxErrors(maxErrors int) Option {
+	return OptionFunc(func(c *config) {
+		c.maxErrors = maxErrors
+	})
+}
+
+// WithProgress sets the callback receiving the ingestion progress every given number of records
+// and once the ingestion is over.
+func WithProgress(every int64, onProgress func(Progress)) Option {
+	return OptionFunc(func(c *config) {
+		c.progressEvery = every
+		c.onProgress = onProgress
+	})
+}
+
+// WithComma sets the CSV field delimiter. The default is ','
+func WithComma(comma rune) Option {
+	return OptionFunc(func(c *config) {
+		c.comma = comma
+	})
+}
+
+// WithMaxLineSize sets the maximum size in bytes of a NDJSON line
+func WithMaxLineSize(size int) Option {
+	return OptionFunc(func(c *config) {
+		c.maxLineSize = size
+	})
+}
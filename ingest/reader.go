@@ -0,0 +1,179 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+// Package ingest provides streaming readers decoding large CSV and NDJSON files
+// into typed records, and helpers to load those records in batches into a sink
+// such as a Postgres table.
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+)
+
+// ErrTooManyErrors is returned when the number of invalid records exceeds the configured maximum
+var ErrTooManyErrors = errors.New("too many invalid records")
+
+// RecordError is an error that occurred while decoding a single record.
+// Such errors are collected and do not stop the ingestion.
+type RecordError struct {
+	// Line is the line number of the record in the input
+	Line int
+	// Err is the decoding error
+	Err error
+}
+
+// Error implements the error interface
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+// Unwrap returns the decoding error
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// Progress is a point-in-time view of an ingestion
+type Progress struct {
+	// Records is the number of records successfully decoded
+	Records int64
+	// Failed is the number of invalid records
+	Failed int64
+	// Bytes is the number of bytes read from the input
+	Bytes int64
+}
+
+// decodeFunc decodes the next record of the input.
+// It returns io.EOF at the end of the input and a *RecordError for an invalid record.
+type decodeFunc[T any] func() (T, error)
+
+// Reader streams typed records out of an input
+type Reader[T any] struct {
+	config   *config
+	decode   decodeFunc[T]
+	counter  *countingReader
+	progress Progress
+	errs     []*RecordError
+	err      error
+}
+
+// newReader creates an instance of Reader
+func newReader[T any](counter *countingReader, config *config, decode decodeFunc[T]) *Reader[T] {
+	return &Reader[T]{
+		config:  config,
+		decode:  decode,
+		counter: counter,
+	}
+}
+
+// All returns an iterator over the valid records of the input.
+// Invalid records are skipped and collected, see Errors. The iteration stops at the end
+// of the input, on a read failure, when the context is done or when the maximum
+// number of invalid records is exceeded. Check Err once the iteration is over.
+func (r *Reader[T]) All(ctx context.Context) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		defer r.report()
+		for {
+			if err := ctx.Err(); err != nil {
+				r.err = err
+				return
+			}
+
+			record, err := r.decode()
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					return
+				}
+
+				var recordErr *RecordError
+				if !errors.As(err, &recordErr) {
+					r.err = err
+					return
+				}
+
+				r.errs = append(r.errs, recordErr)
+				r.progress.Failed++
+				if r.config.maxErrors > 0 && len(r.errs) > r.config.maxErrors {
+					r.err = fmt.Errorf("%w: %d", ErrTooManyErrors, len(r.errs))
+					return
+				}
+				r.tick()
+				continue
+			}
+
+			r.progress.Records++
+			r.tick()
+			if !yield(record) {
+				return
+			}
+		}
+	}
+}
+
+// Err returns the error that stopped the iteration, if any
+func (r *Reader[T]) Err() error {
+	return r.err
+}
+
+// Errors returns the invalid records errors collected so far
+func (r *Reader[T]) Errors() []*RecordError {
+	return r.errs
+}
+
+// Progress returns the current ingestion progress
+func (r *Reader[T]) Progress() Progress {
+	progress := r.progress
+	progress.Bytes = r.counter.count
+	return progress
+}
+
+// tick reports the progress every configured number of records
+func (r *Reader[T]) tick() {
+	if r.config.progressEvery > 0 && (r.progress.Records+r.progress.Failed)%r.config.progressEvery == 0 {
+		r.report()
+	}
+}
+
+// report hands over the current progress to the progress callback
+func (r *Reader[T]) report() {
+	if r.config.onProgress != nil {
+		r.config.onProgress(r.Progress())
+	}
+}
+
+// countingReader counts the bytes read from the underlying reader
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+// Read implements io.Reader
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.reader.Read(p)
+	c.count += int64(n)
+	return n, err
+}
@@ -0,0 +1,311 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+// Package anthropic implements the llm.Client interface with the Anthropic Claude messages API
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/tochemey/gopack/clock"
+	"github.com/tochemey/gopack/llm"
+	"github.com/tochemey/gopack/llm/internal/transport"
+)
+
+// apiVersion is the version of the Anthropic API
+const apiVersion = "2023-06-01"
+
+// jsonInstruction asks the model for a JSON answer since the API has no JSON mode
+const jsonInstruction = "Respond only with a valid JSON object, without any surrounding text."
+
+type client struct {
+	config      *Config
+	baseURL     string
+	maxTokens   int
+	temperature *float32
+	httpClient  *http.Client
+	clock       clock.Clock
+}
+
+// enforce compilation error
+var _ llm.Client = (*client)(nil)
+
+// NewClient creates an instance of the Anthropic client
+func NewClient(config *Config, opts ...Option) llm.Client {
+	c := &client{
+		config:     config,
+		baseURL:    DefaultBaseURL,
+		maxTokens:  DefaultMaxTokens,
+		httpClient: http.DefaultClient,
+		clock:      clock.New(),
+	}
+
+	if config.BaseURL != "" {
+		c.baseURL = strings.TrimSuffix(config.BaseURL, "/")
+	}
+
+	if config.MaxTokens > 0 {
+		c.maxTokens = config.MaxTokens
+	}
+
+	// apply the options
+	for _, opt := range opts {
+		opt.Apply(c)
+	}
+	return c
+}
+
+// Query sends the messages to Claude and returns its response
+func (c *client) Query(ctx context.Context, requests []*llm.Request, responseType llm.ResponseType) ([]*llm.Response, error) {
+	req, err := c.messagesRequest(requests, responseType)
+	if err != nil {
+		return nil, err
+	}
+	return c.send(ctx, req)
+}
+
+// QueryStream sends the messages to Claude and streams the response as it is generated.
+// Only the opening of the stream is retried. The Timeout of the Config does not apply to the stream
+// which is bounded by ctx.
+func (c *client) QueryStream(ctx context.Context, requests []*llm.Request, responseType llm.ResponseType) (<-chan *llm.StreamDelta, error) {
+	req, err := c.messagesRequest(requests, responseType)
+	if err != nil {
+		return nil, err
+	}
+	req.Stream = true
+
+	var response *http.Response
+	operation := func() error {
+		var err error
+		response, err = transport.Post(ctx, c.httpClient, c.baseURL+"/v1/messages", c.headers(), req)
+		return err
+	}
+
+	if err := transport.Retry(c.clock, c.config.MaxRetries, operation); err != nil {
+		return nil, err
+	}
+
+	out := make(chan *llm.StreamDelta, 16)
+	go func() {
+		defer close(out)
+		defer response.Body.Close()
+		pump(ctx, transport.NewEventReader(response.Body), out)
+	}()
+	return out, nil
+}
+
+// VisionQuery sends the text and image messages to Claude as a single user message and returns its response
+func (c *client) VisionQuery(ctx context.Context, requests ...*llm.VisionRequest) ([]*llm.Response, error) {
+	blocks := make([]contentBlock, 0, len(requests))
+	for _, request := range requests {
+		switch {
+		case request.ImageURL != "":
+			blocks = append(blocks, contentBlock{
+				Type:   "image",
+				Source: &imageSource{Type: "url", URL: request.ImageURL},
+			})
+		case request.HasImage():
+			mediaType, data, err := transport.EncodeImage(request)
+			if err != nil {
+				return nil, err
+			}
+
+			blocks = append(blocks, contentBlock{
+				Type: "image",
+				Source: &imageSource{
+					Type:      "base64",
+					MediaType: mediaType,
+					Data:      data,
+				},
+			})
+		default:
+			blocks = append(blocks, contentBlock{Type: "text", Text: request.Content})
+		}
+	}
+
+	return c.send(ctx, &messagesRequest{
+		Model:       c.config.Model,
+		MaxTokens:   c.maxTokens,
+		Messages:    []message{{Role: "user", Content: blocks}},
+		Temperature: c.temperature,
+	})
+}
+
+// Embeddings is not supported by the Anthropic API
+func (c *client) Embeddings(context.Context, []string) ([][]float32, error) {
+	return nil, llm.ErrNotSupported
+}
+
+// Embed is not supported by the Anthropic API
+func (c *client) Embed(context.Context, []string, string) ([][]float32, error) {
+	return nil, llm.ErrNotSupported
+}
+
+// send sends the messages request and converts its response
+func (c *client) send(ctx context.Context, req *messagesRequest) ([]*llm.Response, error) {
+	var resp messagesResponse
+	operation := func() error {
+		ctx := ctx
+		if c.config.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, c.config.Timeout)
+			defer cancel()
+		}
+		return transport.PostJSON(ctx, c.httpClient, c.baseURL+"/v1/messages", c.headers(), req, &resp)
+	}
+
+	if err := transport.Retry(c.clock, c.config.MaxRetries, operation); err != nil {
+		return nil, err
+	}
+
+	var content strings.Builder
+	for _, block := range resp.Content {
+		if block.Type == "text" {
+			content.WriteString(block.Text)
+		}
+	}
+
+	return []*llm.Response{
+		{
+			Content:          content.String(),
+			PromptTokens:     resp.Usage.InputTokens,
+			CompletionTokens: resp.Usage.OutputTokens,
+			TotalTokens:      resp.Usage.InputTokens + resp.Usage.OutputTokens,
+		},
+	}, nil
+}
+
+// headers returns the headers of the API requests
+func (c *client) headers() map[string]string {
+	return map[string]string{
+		"x-api-key":         c.config.Token,
+		"anthropic-version": apiVersion,
+	}
+}
+
+// messagesRequest converts the messages to a messages request.
+// The system messages are gathered in the system prompt.
+func (c *client) messagesRequest(requests []*llm.Request, responseType llm.ResponseType) (*messagesRequest, error) {
+	var system []string
+	messages := make([]message, 0, len(requests))
+	for _, request := range requests {
+		switch request.Type {
+		case llm.SystemMessage:
+			system = append(system, request.Content)
+		case llm.UserMessage:
+			messages = append(messages, message{Role: "user", Content: []contentBlock{{Type: "text", Text: request.Content}}})
+		case llm.AssistantMessage:
+			messages = append(messages, message{Role: "assistant", Content: []contentBlock{{Type: "text", Text: request.Content}}})
+		default:
+			return nil, fmt.Errorf("unsupported request type: %d", request.Type)
+		}
+	}
+
+	if responseType == llm.JSONResponseType {
+		system = append(system, jsonInstruction)
+	}
+
+	return &messagesRequest{
+		Model:       c.config.Model,
+		MaxTokens:   c.maxTokens,
+		System:      strings.Join(system, "\n\n"),
+		Messages:    messages,
+		Temperature: c.temperature,
+	}, nil
+}
+
+// pump forwards the events read from the stream to out until the stream is over or ctx is done.
+// The last delta sent holds the token usage or the error that ended the stream.
+func pump(ctx context.Context, reader *transport.EventReader, out chan<- *llm.StreamDelta) {
+	send := func(delta *llm.StreamDelta) bool {
+		select {
+		case out <- delta:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	fail := func(err error) {
+		// report the cancellation rather than the transport error it caused
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		send(&llm.StreamDelta{Err: err})
+	}
+
+	usage := new(llm.Usage)
+	for {
+		event, err := reader.Next()
+		if err != nil {
+			// the stream ends with a message_stop event
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			fail(err)
+			return
+		}
+
+		var payload streamEvent
+		if err := json.Unmarshal([]byte(event.Data), &payload); err != nil {
+			fail(err)
+			return
+		}
+
+		switch payload.Type {
+		case "message_start":
+			if payload.Message != nil {
+				usage.PromptTokens = payload.Message.Usage.InputTokens
+			}
+		case "content_block_delta":
+			if payload.Delta != nil && payload.Delta.Text != "" && !send(&llm.StreamDelta{Content: payload.Delta.Text}) {
+				return
+			}
+		case "message_delta":
+			if payload.Usage != nil {
+				usage.CompletionTokens = payload.Usage.OutputTokens
+			}
+			if payload.Delta != nil && payload.Delta.StopReason != "" && !send(&llm.StreamDelta{FinishReason: payload.Delta.StopReason}) {
+				return
+			}
+		case "message_stop":
+			usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+			send(&llm.StreamDelta{Usage: usage})
+			return
+		case "error":
+			message := "stream failed"
+			if payload.Error != nil {
+				message = fmt.Sprintf("%s: %s", payload.Error.Type, payload.Error.Message)
+			}
+			fail(errors.New(message))
+			return
+		}
+	}
+}
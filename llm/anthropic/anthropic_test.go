@@ -0,0 +1,213 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"image"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tochemey/gopack/llm"
+	"github.com/tochemey/gopack/llm/internal/transport"
+)
+
+// newServer creates a test server checking the messages requests and answering with the handler
+func newServer(t *testing.T, handler func(w http.ResponseWriter, req messagesRequest)) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/messages", r.URL.Path)
+		assert.Equal(t, "secret", r.Header.Get("x-api-key"))
+		assert.Equal(t, apiVersion, r.Header.Get("anthropic-version"))
+
+		var req messagesRequest
+		body, _ := io.ReadAll(r.Body)
+		require.NoError(t, json.Unmarshal(body, &req))
+		handler(w, req)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestQuery(t *testing.T) {
+	t.Run("with success", func(t *testing.T) {
+		server := newServer(t, func(w http.ResponseWriter, req messagesRequest) {
+			assert.Equal(t, "claude", req.Model)
+			assert.Equal(t, DefaultMaxTokens, req.MaxTokens)
+			assert.Equal(t, "be brief\n\n"+jsonInstruction, req.System)
+			require.Len(t, req.Messages, 2)
+			assert.Equal(t, "user", req.Messages[0].Role)
+			assert.Equal(t, "hello", req.Messages[0].Content[0].Text)
+			assert.Equal(t, "assistant", req.Messages[1].Role)
+
+			_, _ = w.Write([]byte(`{"content":[{"type":"text","text":"{\"answer\":"},{"type":"text","text":"42}"}],"usage":{"input_tokens":10,"output_tokens":5}}`))
+		})
+
+		client := NewClient(&Config{Token: "secret", Model: "claude", BaseURL: server.URL})
+		responses, err := client.Query(context.Background(), []*llm.Request{
+			{Type: llm.SystemMessage, Content: "be brief"},
+			{Type: llm.UserMessage, Content: "hello"},
+			{Type: llm.AssistantMessage, Content: "hi"},
+		}, llm.JSONResponseType)
+		require.NoError(t, err)
+		require.Len(t, responses, 1)
+		assert.Equal(t, &llm.Response{Content: `{"answer":42}`, PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15}, responses[0])
+	})
+	t.Run("with error status", func(t *testing.T) {
+		server := newServer(t, func(w http.ResponseWriter, _ messagesRequest) {
+			http.Error(w, `{"type":"error"}`, http.StatusBadRequest)
+		})
+
+		client := NewClient(&Config{Token: "secret", Model: "claude", BaseURL: server.URL, MaxRetries: 3})
+		_, err := client.Query(context.Background(), []*llm.Request{{Type: llm.UserMessage, Content: "hello"}}, llm.TextResponseType)
+		require.Error(t, err)
+
+		var statusErr *transport.StatusError
+		require.True(t, errors.As(err, &statusErr))
+		assert.Equal(t, http.StatusBadRequest, statusErr.StatusCode)
+	})
+	t.Run("with tool message", func(t *testing.T) {
+		client := NewClient(&Config{Token: "secret", Model: "claude"})
+		_, err := client.Query(context.Background(), []*llm.Request{{Type: llm.ToolMessage, Content: "{}"}}, llm.TextResponseType)
+		assert.Error(t, err)
+	})
+}
+
+func TestQueryStream(t *testing.T) {
+	t.Run("with completed stream", func(t *testing.T) {
+		server := newServer(t, func(w http.ResponseWriter, req messagesRequest) {
+			assert.True(t, req.Stream)
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = w.Write([]byte("event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"usage\":{\"input_tokens\":10}}}\n\n" +
+				"event: ping\ndata: {\"type\":\"ping\"}\n\n" +
+				"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"Hel\"}}\n\n" +
+				"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"lo\"}}\n\n" +
+				"event: message_delta\ndata: {\"type\":\"message_delta\",\"delta\":{\"stop_reason\":\"end_turn\"},\"usage\":{\"output_tokens\":2}}\n\n" +
+				"event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n"))
+		})
+
+		client := NewClient(&Config{Token: "secret", Model: "claude", BaseURL: server.URL})
+		deltas, err := client.QueryStream(context.Background(), []*llm.Request{{Type: llm.UserMessage, Content: "hello"}}, llm.TextResponseType)
+		require.NoError(t, err)
+
+		var received []*llm.StreamDelta
+		for delta := range deltas {
+			received = append(received, delta)
+		}
+
+		assert.Equal(t, []*llm.StreamDelta{
+			{Content: "Hel"},
+			{Content: "lo"},
+			{FinishReason: "end_turn"},
+			{Usage: &llm.Usage{PromptTokens: 10, CompletionTokens: 2, TotalTokens: 12}},
+		}, received)
+	})
+	t.Run("with error event", func(t *testing.T) {
+		server := newServer(t, func(w http.ResponseWriter, _ messagesRequest) {
+			_, _ = w.Write([]byte("event: error\ndata: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}\n\n"))
+		})
+
+		client := NewClient(&Config{Token: "secret", Model: "claude", BaseURL: server.URL})
+		deltas, err := client.QueryStream(context.Background(), []*llm.Request{{Type: llm.UserMessage, Content: "hello"}}, llm.TextResponseType)
+		require.NoError(t, err)
+
+		delta := <-deltas
+		require.Error(t, delta.Err)
+		assert.Equal(t, "overloaded_error: Overloaded", delta.Err.Error())
+	})
+	t.Run("with truncated stream", func(t *testing.T) {
+		server := newServer(t, func(w http.ResponseWriter, _ messagesRequest) {
+			_, _ = w.Write([]byte("event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"text\":\"Hel\"}}\n\n"))
+		})
+
+		client := NewClient(&Config{Token: "secret", Model: "claude", BaseURL: server.URL})
+		deltas, err := client.QueryStream(context.Background(), []*llm.Request{{Type: llm.UserMessage, Content: "hello"}}, llm.TextResponseType)
+		require.NoError(t, err)
+
+		assert.Equal(t, "Hel", (<-deltas).Content)
+		assert.ErrorIs(t, (<-deltas).Err, io.ErrUnexpectedEOF)
+	})
+}
+
+func TestVisionQuery(t *testing.T) {
+	server := newServer(t, func(w http.ResponseWriter, req messagesRequest) {
+		require.Len(t, req.Messages, 1)
+		blocks := req.Messages[0].Content
+		require.Len(t, blocks, 2)
+		assert.Equal(t, "text", blocks[0].Type)
+		assert.Equal(t, "image", blocks[1].Type)
+		require.NotNil(t, blocks[1].Source)
+		assert.Equal(t, transport.JPEGMediaType, blocks[1].Source.MediaType)
+		assert.NotEmpty(t, blocks[1].Source.Data)
+
+		_, _ = w.Write([]byte(`{"content":[{"type":"text","text":"a black square"}],"usage":{"input_tokens":100,"output_tokens":4}}`))
+	})
+
+	client := NewClient(&Config{Token: "secret", Model: "claude", BaseURL: server.URL})
+	responses, err := client.VisionQuery(context.Background(),
+		&llm.VisionRequest{Content: "describe the image"},
+		&llm.VisionRequest{Image: image.NewRGBA(image.Rect(0, 0, 4, 4))},
+	)
+	require.NoError(t, err)
+	require.Len(t, responses, 1)
+	assert.Equal(t, "a black square", responses[0].Content)
+
+	t.Run("with image urls and encoded images", func(t *testing.T) {
+		server := newServer(t, func(w http.ResponseWriter, req messagesRequest) {
+			blocks := req.Messages[0].Content
+			require.Len(t, blocks, 4)
+			assert.Equal(t, &imageSource{Type: "url", URL: "https://example.com/cat.png"}, blocks[1].Source)
+			assert.Equal(t, "text", blocks[2].Type)
+			assert.Equal(t, &imageSource{Type: "base64", MediaType: "image/webp", Data: "UklGRg=="}, blocks[3].Source)
+
+			_, _ = w.Write([]byte(`{"content":[{"type":"text","text":"two cats"}]}`))
+		})
+
+		client := NewClient(&Config{Token: "secret", Model: "claude", BaseURL: server.URL})
+		responses, err := client.VisionQuery(context.Background(),
+			&llm.VisionRequest{Content: "compare"},
+			&llm.VisionRequest{ImageURL: "https://example.com/cat.png"},
+			&llm.VisionRequest{Content: "with"},
+			&llm.VisionRequest{ImageData: []byte("RIFF"), MediaType: "image/webp"},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, "two cats", responses[0].Content)
+	})
+}
+
+func TestEmbeddings(t *testing.T) {
+	client := NewClient(&Config{Token: "secret", Model: "claude"})
+	_, err := client.Embeddings(context.Background(), []string{"hello"})
+	assert.ErrorIs(t, err, llm.ErrNotSupported)
+
+	_, err = client.Embed(context.Background(), []string{"hello"}, "embedder")
+	assert.ErrorIs(t, err, llm.ErrNotSupported)
+}
@@ -0,0 +1,52 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package llm
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotSupported is returned when the provider does not support the operation
+var ErrNotSupported = errors.New("operation not supported by the provider")
+
+// Client defines the operations common to the LLM providers
+type Client interface {
+	// Query sends the messages to the model and returns its responses.
+	// The responseType sets the expected format of the responses.
+	Query(ctx context.Context, requests []*Request, responseType ResponseType) (responses []*Response, err error)
+	// QueryStream sends the messages to the model and streams the response as it is generated.
+	// The returned channel receives the chunks of content followed by a final delta holding either the token
+	// usage of the query or the error that ended the stream. The channel is closed once the stream is over.
+	QueryStream(ctx context.Context, requests []*Request, responseType ResponseType) (deltas <-chan *StreamDelta, err error)
+	// VisionQuery sends the text and image messages to the model and returns its responses
+	VisionQuery(ctx context.Context, requests ...*VisionRequest) (responses []*Response, err error)
+	// Embeddings returns the embedding vector of every input, in the order of the inputs.
+	// The vectors are computed by the embedding model of the provider configuration.
+	Embeddings(ctx context.Context, inputs []string) (embeddings [][]float32, err error)
+	// Embed returns the embedding vector of every input, in the order of the inputs, computed by the given model.
+	// The inputs are sent in batches. When model is empty the embedding model of the provider configuration is used.
+	Embed(ctx context.Context, inputs []string, model string) (embeddings [][]float32, err error)
+}
@@ -0,0 +1,344 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultContextWindow is the default number of tokens of a conversation context
+	DefaultContextWindow = 8192
+	// DefaultReservedTokens is the default number of tokens of the context window kept for the response
+	DefaultReservedTokens = 1024
+	// summaryPrefix introduces the summary of the earlier turns of a conversation
+	summaryPrefix = "Summary of the earlier conversation:\n"
+	// summaryPrompt asks the model to summarize the earlier turns of a conversation
+	summaryPrompt = "Summarize the conversation above in a few sentences. Keep the facts, decisions and open questions needed to carry on the conversation."
+)
+
+// ErrContextWindowExceeded is returned when the latest turn of a conversation does not fit in the context window
+var ErrContextWindowExceeded = errors.New("the conversation does not fit in the context window")
+
+// TokenCounter counts the tokens of the messages as they are sent to the model
+type TokenCounter interface {
+	// CountTokens returns the number of tokens of the messages
+	CountTokens(requests []*Request) (int, error)
+}
+
+var _ TokenCounter = TokenCounterFunc(nil)
+
+// TokenCounterFunc implements the TokenCounter interface.
+type TokenCounterFunc func(requests []*Request) (int, error)
+
+func (f TokenCounterFunc) CountTokens(requests []*Request) (int, error) {
+	return f(requests)
+}
+
+// ApproximateTokens estimates the tokens of the messages at four characters per token.
+// It is meant for the providers without a tokenizer at hand.
+var ApproximateTokens = TokenCounterFunc(func(requests []*Request) (int, error) {
+	tokens := 0
+	for _, request := range requests {
+		size := len(request.Content)
+		for _, call := range request.ToolCalls {
+			size += len(call.Name) + len(call.Arguments)
+		}
+		// every message costs a few tokens of framing
+		tokens += size/4 + 4
+	}
+	return tokens, nil
+})
+
+// Summarizer condenses the earlier turns of a conversation
+type Summarizer interface {
+	// Summarize returns a summary of the messages
+	Summarize(ctx context.Context, requests []*Request) (string, error)
+}
+
+var _ Summarizer = SummarizerFunc(nil)
+
+// SummarizerFunc implements the Summarizer interface.
+type SummarizerFunc func(ctx context.Context, requests []*Request) (string, error)
+
+func (f SummarizerFunc) Summarize(ctx context.Context, requests []*Request) (string, error) {
+	return f(ctx, requests)
+}
+
+// QuerySummarizer returns a Summarizer asking the querier to summarize the messages
+func QuerySummarizer(querier Querier) Summarizer {
+	return SummarizerFunc(func(ctx context.Context, requests []*Request) (string, error) {
+		messages := make([]*Request, 0, len(requests)+1)
+		messages = append(messages, requests...)
+		messages = append(messages, &Request{Type: UserMessage, Content: summaryPrompt})
+
+		responses, err := querier.Query(ctx, messages, TextResponseType)
+		if err != nil {
+			return "", fmt.Errorf("failed to summarize the conversation: %w", err)
+		}
+
+		if len(responses) == 0 {
+			return "", errors.New("failed to summarize the conversation: no response")
+		}
+		return strings.TrimSpace(responses[0].Content), nil
+	})
+}
+
+// ConversationOption is the interface that applies a conversation option.
+type ConversationOption interface {
+	// Apply sets the ConversationOption value of a conversation.
+	Apply(*Conversation)
+}
+
+var _ ConversationOption = ConversationOptionFunc(nil)
+
+// ConversationOptionFunc implements the ConversationOption interface.
+type ConversationOptionFunc func(*Conversation)
+
+func (f ConversationOptionFunc) Apply(c *Conversation) {
+	f(c)
+}
+
+// WithContextWindow sets the number of tokens of the context window
+func WithContextWindow(tokens int) ConversationOption {
+	return ConversationOptionFunc(func(c *Conversation) {
+		c.contextWindow = tokens
+	})
+}
+
+// WithReservedTokens sets the number of tokens of the context window kept for the response
+func WithReservedTokens(tokens int) ConversationOption {
+	return ConversationOptionFunc(func(c *Conversation) {
+		c.reservedTokens = tokens
+	})
+}
+
+// WithSummarizer sets the summarizer condensing the turns dropped from the context window.
+// Without a summarizer the dropped turns are discarded.
+func WithSummarizer(summarizer Summarizer) ConversationOption {
+	return ConversationOptionFunc(func(c *Conversation) {
+		c.summarizer = summarizer
+	})
+}
+
+// Conversation keeps the message history of a conversation with a model and makes it fit in the context window.
+//
+// The history is made of turns: a turn starts with a user message and holds the messages following it, such as the
+// assistant replies, tool calls and tool results, so that a tool call is never separated from its result.
+// The system messages added before the first turn are always kept. Before each query the oldest turns are dropped,
+// or folded into a summary when a Summarizer is set, until the history fits in the context window minus the
+// reserved tokens. The latest turn is never dropped.
+//
+// A Conversation is safe for concurrent use. The queries are serialized to keep the history consistent.
+type Conversation struct {
+	mu sync.Mutex
+
+	counter        TokenCounter
+	summarizer     Summarizer
+	contextWindow  int
+	reservedTokens int
+
+	system  []*Request
+	summary string
+	turns   [][]*Request
+}
+
+// NewConversation creates a conversation counting its tokens with the given counter
+func NewConversation(counter TokenCounter, opts ...ConversationOption) *Conversation {
+	conversation := &Conversation{
+		counter:        counter,
+		contextWindow:  DefaultContextWindow,
+		reservedTokens: DefaultReservedTokens,
+	}
+
+	for _, opt := range opts {
+		opt.Apply(conversation)
+	}
+	return conversation
+}
+
+// Add appends the messages to the history
+func (c *Conversation) Add(requests ...*Request) {
+	c.mu.Lock()
+	c.add(requests...)
+	c.mu.Unlock()
+}
+
+// Messages returns the history as it is sent to the model: the system messages, the summary of the dropped
+// turns, when any, and the kept turns
+func (c *Conversation) Messages() []*Request {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.messages(c.turns)
+}
+
+// Tokens returns the number of tokens of the history
+func (c *Conversation) Tokens() (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.counter.CountTokens(c.messages(c.turns))
+}
+
+// Reset clears the history, including the system messages
+func (c *Conversation) Reset() {
+	c.mu.Lock()
+	c.system = nil
+	c.summary = ""
+	c.turns = nil
+	c.mu.Unlock()
+}
+
+// Fit drops or summarizes the oldest turns until the history fits in the context window and returns it.
+// It returns ErrContextWindowExceeded when the system messages and the latest turn alone do not fit.
+func (c *Conversation) Fit(ctx context.Context) ([]*Request, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.fit(ctx)
+}
+
+// Query appends the messages to the history, fits the history in the context window and sends it to the querier.
+// The first response is appended to the history as an assistant message.
+func (c *Conversation) Query(ctx context.Context, querier Querier, responseType ResponseType, requests ...*Request) ([]*Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.add(requests...)
+	messages, err := c.fit(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	responses, err := querier.Query(ctx, messages, responseType)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(responses) > 0 {
+		c.add(&Request{
+			Type:      AssistantMessage,
+			Content:   responses[0].Content,
+			ToolCalls: responses[0].ToolCalls,
+		})
+	}
+	return responses, nil
+}
+
+// add appends the messages to the history. A user message starts a new turn.
+func (c *Conversation) add(requests ...*Request) {
+	for _, request := range requests {
+		switch {
+		case request.Type == SystemMessage && len(c.turns) == 0:
+			c.system = append(c.system, request)
+		case request.Type == UserMessage || len(c.turns) == 0:
+			c.turns = append(c.turns, []*Request{request})
+		default:
+			last := len(c.turns) - 1
+			c.turns[last] = append(c.turns[last], request)
+		}
+	}
+}
+
+// messages returns the system messages, the summary and the given turns
+func (c *Conversation) messages(turns [][]*Request) []*Request {
+	messages := make([]*Request, 0, len(c.system)+1+len(turns))
+	messages = append(messages, c.system...)
+	if c.summary != "" {
+		messages = append(messages, &Request{Type: SystemMessage, Content: summaryPrefix + c.summary})
+	}
+
+	for _, turn := range turns {
+		messages = append(messages, turn...)
+	}
+	return messages
+}
+
+// fits returns true when the messages fit in the context window minus the reserved tokens
+func (c *Conversation) fits(messages []*Request) (bool, error) {
+	tokens, err := c.counter.CountTokens(messages)
+	if err != nil {
+		return false, err
+	}
+	return tokens <= c.contextWindow-c.reservedTokens, nil
+}
+
+// fit drops the oldest turns until the history fits and folds them into the summary when a summarizer is set.
+// A new summary can push the history over the limit again, in which case the next oldest turn is folded as well.
+func (c *Conversation) fit(ctx context.Context) ([]*Request, error) {
+	for {
+		messages := c.messages(c.turns)
+		ok, err := c.fits(messages)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			return messages, nil
+		}
+
+		if len(c.turns) <= 1 {
+			// the summary is the last thing to give up
+			if c.summary != "" {
+				c.summary = ""
+				continue
+			}
+			return nil, ErrContextWindowExceeded
+		}
+
+		// drop the oldest turns until the others fit without the summary
+		dropped := 1
+		for ; dropped < len(c.turns)-1; dropped++ {
+			previous := c.summary
+			c.summary = ""
+			ok, err := c.fits(c.messages(c.turns[dropped:]))
+			c.summary = previous
+			if err != nil {
+				return nil, err
+			}
+
+			if ok {
+				break
+			}
+		}
+
+		if c.summarizer == nil {
+			c.turns = c.turns[dropped:]
+			continue
+		}
+
+		// the previous summary is summarized along with the dropped turns
+		folded := c.messages(c.turns[:dropped])[len(c.system):]
+		summary, err := c.summarizer.Summarize(ctx, folded)
+		if err != nil {
+			return nil, err
+		}
+
+		c.summary = summary
+		c.turns = c.turns[dropped:]
+	}
+}
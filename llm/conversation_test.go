@@ -0,0 +1,196 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// messageCounter counts ten tokens per message
+var messageCounter = TokenCounterFunc(func(requests []*Request) (int, error) {
+	return 10 * len(requests), nil
+})
+
+// contents returns the contents of the messages
+func contents(requests []*Request) []string {
+	out := make([]string, len(requests))
+	for i, request := range requests {
+		out[i] = request.Content
+	}
+	return out
+}
+
+// newHistory adds a system message and user and assistant turns to the conversation
+func newHistory(conversation *Conversation, turns int) {
+	conversation.Add(&Request{Type: SystemMessage, Content: "system"})
+	for i := 1; i <= turns; i++ {
+		conversation.Add(
+			&Request{Type: UserMessage, Content: "user " + string(rune('0'+i))},
+			&Request{Type: AssistantMessage, Content: "assistant " + string(rune('0'+i))},
+		)
+	}
+}
+
+func TestConversation(t *testing.T) {
+	t.Run("with the history fitting", func(t *testing.T) {
+		conversation := NewConversation(messageCounter)
+		newHistory(conversation, 3)
+
+		messages, err := conversation.Fit(context.Background())
+		require.NoError(t, err)
+		assert.Len(t, messages, 7)
+
+		tokens, err := conversation.Tokens()
+		require.NoError(t, err)
+		assert.Equal(t, 70, tokens)
+	})
+	t.Run("with truncation", func(t *testing.T) {
+		conversation := NewConversation(messageCounter, WithContextWindow(60), WithReservedTokens(10))
+		newHistory(conversation, 3)
+
+		messages, err := conversation.Fit(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"system", "user 2", "assistant 2", "user 3", "assistant 3"}, contents(messages))
+		assert.Equal(t, messages, conversation.Messages())
+	})
+	t.Run("with tool calls kept in their turn", func(t *testing.T) {
+		conversation := NewConversation(messageCounter, WithContextWindow(50), WithReservedTokens(0))
+		newHistory(conversation, 1)
+		conversation.Add(
+			&Request{Type: UserMessage, Content: "user 2"},
+			&Request{Type: AssistantMessage, ToolCalls: []*ToolCall{{ID: "call", Name: "weather"}}},
+			&Request{Type: ToolMessage, ToolCallID: "call", Content: "sunny"},
+			&Request{Type: AssistantMessage, Content: "assistant 2"},
+		)
+
+		messages, err := conversation.Fit(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"system", "user 2", "", "sunny", "assistant 2"}, contents(messages))
+	})
+	t.Run("with summarization", func(t *testing.T) {
+		var summarized [][]string
+		summarizer := SummarizerFunc(func(_ context.Context, requests []*Request) (string, error) {
+			summarized = append(summarized, contents(requests))
+			return "summary", nil
+		})
+
+		conversation := NewConversation(messageCounter, WithContextWindow(50), WithReservedTokens(0), WithSummarizer(summarizer))
+		newHistory(conversation, 3)
+
+		messages, err := conversation.Fit(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"system", summaryPrefix + "summary", "user 3", "assistant 3"}, contents(messages))
+		assert.Equal(t, SystemMessage, messages[1].Type)
+
+		// the summary pushed the history over the limit, so it was summarized again with the next turn
+		assert.Equal(t, [][]string{
+			{"user 1", "assistant 1"},
+			{summaryPrefix + "summary", "user 2", "assistant 2"},
+		}, summarized)
+	})
+	t.Run("with a summarizer failure", func(t *testing.T) {
+		conversation := NewConversation(messageCounter, WithContextWindow(50), WithReservedTokens(0),
+			WithSummarizer(SummarizerFunc(func(context.Context, []*Request) (string, error) {
+				return "", errors.New("boom")
+			})))
+		newHistory(conversation, 3)
+
+		_, err := conversation.Fit(context.Background())
+		assert.EqualError(t, err, "boom")
+	})
+	t.Run("with the latest turn exceeding the context window", func(t *testing.T) {
+		conversation := NewConversation(messageCounter, WithContextWindow(30), WithReservedTokens(0))
+		conversation.Add(&Request{Type: SystemMessage, Content: "system"})
+		conversation.Add(
+			&Request{Type: UserMessage, Content: "user"},
+			&Request{Type: AssistantMessage, Content: "assistant"},
+			&Request{Type: UserMessage, Content: "user"},
+			&Request{Type: AssistantMessage, ToolCalls: []*ToolCall{{ID: "call", Name: "weather"}}},
+			&Request{Type: ToolMessage, ToolCallID: "call", Content: "sunny"},
+		)
+
+		_, err := conversation.Fit(context.Background())
+		assert.ErrorIs(t, err, ErrContextWindowExceeded)
+	})
+	t.Run("with queries", func(t *testing.T) {
+		var sent []string
+		querier := QuerierFunc(func(_ context.Context, requests []*Request, _ ResponseType) ([]*Response, error) {
+			sent = contents(requests)
+			return []*Response{{Content: "re " + requests[len(requests)-1].Content}}, nil
+		})
+
+		conversation := NewConversation(messageCounter, WithContextWindow(40), WithReservedTokens(0))
+		conversation.Add(&Request{Type: SystemMessage, Content: "system"})
+		for _, content := range []string{"one", "two", "three"} {
+			responses, err := conversation.Query(context.Background(), querier, TextResponseType,
+				&Request{Type: UserMessage, Content: content})
+			require.NoError(t, err)
+			assert.Equal(t, "re "+content, responses[0].Content)
+		}
+
+		assert.Equal(t, []string{"system", "two", "re two", "three"}, sent)
+		messages := conversation.Messages()
+		assert.Equal(t, []string{"system", "two", "re two", "three", "re three"}, contents(messages))
+		assert.Equal(t, AssistantMessage, messages[4].Type)
+
+		conversation.Reset()
+		assert.Empty(t, conversation.Messages())
+	})
+}
+
+func TestQuerySummarizer(t *testing.T) {
+	var received []*Request
+	querier := QuerierFunc(func(_ context.Context, requests []*Request, _ ResponseType) ([]*Response, error) {
+		received = requests
+		return []*Response{{Content: " the user said hello \n"}}, nil
+	})
+
+	summary, err := QuerySummarizer(querier).Summarize(context.Background(), []*Request{{Type: UserMessage, Content: "hello"}})
+	require.NoError(t, err)
+	assert.Equal(t, "the user said hello", summary)
+	require.Len(t, received, 2)
+	assert.Equal(t, summaryPrompt, received[1].Content)
+
+	failing := QuerierFunc(func(context.Context, []*Request, ResponseType) ([]*Response, error) {
+		return nil, errors.New("boom")
+	})
+	_, err = QuerySummarizer(failing).Summarize(context.Background(), received)
+	assert.ErrorContains(t, err, "boom")
+}
+
+func TestApproximateTokens(t *testing.T) {
+	tokens, err := ApproximateTokens.CountTokens([]*Request{
+		{Type: UserMessage, Content: strings.Repeat("a", 40)},
+		{Type: AssistantMessage, ToolCalls: []*ToolCall{{Name: "weather", Arguments: `{"city":"x"}`}}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 14+8, tokens)
+}
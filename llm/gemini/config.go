@@ -0,0 +1,56 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package gemini
+
+import "time"
+
+const (
+	// DefaultBaseURL is the default url of the Gemini API
+	DefaultBaseURL = "https://generativelanguage.googleapis.com/v1beta"
+	// DefaultEmbeddingModel is the default model computing the embeddings
+	DefaultEmbeddingModel = "text-embedding-004"
+
+	// maxEmbeddingBatchSize is the maximum number of inputs of a batchEmbedContents request
+	maxEmbeddingBatchSize = 100
+)
+
+// Config defines the Gemini configuration
+type Config struct {
+	// Token defines the Gemini API key
+	Token string
+	// Model defines the Gemini model
+	Model string
+	// EmbeddingModel defines the model computing the embeddings.
+	// It defaults to DefaultEmbeddingModel.
+	EmbeddingModel string
+	// Timeout defines the timeout used
+	// when calling the Gemini apis
+	Timeout time.Duration
+	// MaxRetries defines the maximum of retries when
+	// calling the Gemini apis
+	MaxRetries int
+	// BaseURL defines the url of the Gemini API. It defaults to DefaultBaseURL.
+	BaseURL string
+}
@@ -0,0 +1,339 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+// Package gemini implements the llm.Client interface with the Google Gemini API
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/tochemey/gopack/clock"
+	"github.com/tochemey/gopack/llm"
+	"github.com/tochemey/gopack/llm/internal/transport"
+)
+
+type client struct {
+	config      *Config
+	baseURL     string
+	temperature *float32
+	httpClient  *http.Client
+	clock       clock.Clock
+}
+
+// enforce compilation error
+var _ llm.Client = (*client)(nil)
+
+// NewClient creates an instance of the Gemini client
+func NewClient(config *Config, opts ...Option) llm.Client {
+	c := &client{
+		config:     config,
+		baseURL:    DefaultBaseURL,
+		httpClient: http.DefaultClient,
+		clock:      clock.New(),
+	}
+
+	if config.BaseURL != "" {
+		c.baseURL = strings.TrimSuffix(config.BaseURL, "/")
+	}
+
+	// apply the options
+	for _, opt := range opts {
+		opt.Apply(c)
+	}
+	return c
+}
+
+// Query sends the messages to Gemini and returns a response per candidate
+func (c *client) Query(ctx context.Context, requests []*llm.Request, responseType llm.ResponseType) ([]*llm.Response, error) {
+	req, err := c.generateRequest(requests, responseType)
+	if err != nil {
+		return nil, err
+	}
+	return c.generate(ctx, req)
+}
+
+// QueryStream sends the messages to Gemini and streams the response as it is generated.
+// Only the opening of the stream is retried. The Timeout of the Config does not apply to the stream
+// which is bounded by ctx.
+func (c *client) QueryStream(ctx context.Context, requests []*llm.Request, responseType llm.ResponseType) (<-chan *llm.StreamDelta, error) {
+	req, err := c.generateRequest(requests, responseType)
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/models/%s:streamGenerateContent?alt=sse", c.baseURL, c.config.Model)
+	var response *http.Response
+	operation := func() error {
+		var err error
+		response, err = transport.Post(ctx, c.httpClient, url, c.headers(), req)
+		return err
+	}
+
+	if err := transport.Retry(c.clock, c.config.MaxRetries, operation); err != nil {
+		return nil, err
+	}
+
+	out := make(chan *llm.StreamDelta, 16)
+	go func() {
+		defer close(out)
+		defer response.Body.Close()
+		pump(ctx, transport.NewEventReader(response.Body), out)
+	}()
+	return out, nil
+}
+
+// VisionQuery sends the text and image messages to Gemini as a single user message and returns its responses
+func (c *client) VisionQuery(ctx context.Context, requests ...*llm.VisionRequest) ([]*llm.Response, error) {
+	parts := make([]part, 0, len(requests))
+	for _, request := range requests {
+		switch {
+		case request.ImageURL != "":
+			parts = append(parts, part{FileData: &fileData{MimeType: transport.URLMediaType(request), FileURI: request.ImageURL}})
+		case request.HasImage():
+			mediaType, data, err := transport.EncodeImage(request)
+			if err != nil {
+				return nil, err
+			}
+			parts = append(parts, part{InlineData: &inlineData{MimeType: mediaType, Data: data}})
+		default:
+			parts = append(parts, part{Text: request.Content})
+		}
+	}
+
+	return c.generate(ctx, &generateRequest{
+		Contents:         []content{{Role: "user", Parts: parts}},
+		GenerationConfig: &generationConfig{Temperature: c.temperature},
+	})
+}
+
+// Embeddings returns the embedding vector of every input, in the order of the inputs.
+// The vectors are computed by the EmbeddingModel of the Config.
+func (c *client) Embeddings(ctx context.Context, inputs []string) ([][]float32, error) {
+	return c.Embed(ctx, inputs, "")
+}
+
+// Embed returns the embedding vector of every input, in the order of the inputs, computed by the given model.
+// When model is empty the EmbeddingModel of the Config is used. The inputs are sent in batches of
+// maxEmbeddingBatchSize inputs.
+func (c *client) Embed(ctx context.Context, inputs []string, model string) ([][]float32, error) {
+	if model == "" {
+		model = c.config.EmbeddingModel
+	}
+
+	if model == "" {
+		model = DefaultEmbeddingModel
+	}
+
+	embeddings := make([][]float32, 0, len(inputs))
+	for start := 0; start < len(inputs); start += maxEmbeddingBatchSize {
+		batch, err := c.embedBatch(ctx, model, inputs[start:min(start+maxEmbeddingBatchSize, len(inputs))])
+		if err != nil {
+			return nil, err
+		}
+		embeddings = append(embeddings, batch...)
+	}
+	return embeddings, nil
+}
+
+// embedBatch returns the embedding vectors of a batch of inputs
+func (c *client) embedBatch(ctx context.Context, model string, inputs []string) ([][]float32, error) {
+	req := &embedRequest{Requests: make([]embedContentRequest, 0, len(inputs))}
+	for _, input := range inputs {
+		req.Requests = append(req.Requests, embedContentRequest{
+			Model:   "models/" + model,
+			Content: content{Parts: []part{{Text: input}}},
+		})
+	}
+
+	var resp embedResponse
+	url := fmt.Sprintf("%s/models/%s:batchEmbedContents", c.baseURL, model)
+	if err := c.post(ctx, url, req, &resp); err != nil {
+		return nil, err
+	}
+
+	if len(resp.Embeddings) != len(inputs) {
+		return nil, errors.New("malformed embeddings response from gemini")
+	}
+
+	embeddings := make([][]float32, len(resp.Embeddings))
+	for i, embedding := range resp.Embeddings {
+		embeddings[i] = embedding.Values
+	}
+	return embeddings, nil
+}
+
+// generate sends the generate request and converts its candidates
+func (c *client) generate(ctx context.Context, req *generateRequest) ([]*llm.Response, error) {
+	var resp generateResponse
+	url := fmt.Sprintf("%s/models/%s:generateContent", c.baseURL, c.config.Model)
+	if err := c.post(ctx, url, req, &resp); err != nil {
+		return nil, err
+	}
+
+	// when we have no candidates
+	if len(resp.Candidates) == 0 {
+		return nil, errors.New("malformed llm response from gemini")
+	}
+
+	var usage usageMetadata
+	if resp.UsageMetadata != nil {
+		usage = *resp.UsageMetadata
+	}
+
+	responses := make([]*llm.Response, len(resp.Candidates))
+	for i, candidate := range resp.Candidates {
+		responses[i] = &llm.Response{
+			Content:          candidate.text(),
+			PromptTokens:     usage.PromptTokenCount,
+			CompletionTokens: usage.CandidatesTokenCount,
+			TotalTokens:      usage.TotalTokenCount,
+		}
+	}
+	return responses, nil
+}
+
+// post sends the payload to the given url with retries and decodes the response into out
+func (c *client) post(ctx context.Context, url string, payload, out any) error {
+	operation := func() error {
+		ctx := ctx
+		if c.config.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, c.config.Timeout)
+			defer cancel()
+		}
+		return transport.PostJSON(ctx, c.httpClient, url, c.headers(), payload, out)
+	}
+	return transport.Retry(c.clock, c.config.MaxRetries, operation)
+}
+
+// headers returns the headers of the API requests
+func (c *client) headers() map[string]string {
+	return map[string]string{"x-goog-api-key": c.config.Token}
+}
+
+// generateRequest converts the messages to a generate request.
+// The system messages are gathered in the system instruction.
+func (c *client) generateRequest(requests []*llm.Request, responseType llm.ResponseType) (*generateRequest, error) {
+	var system []part
+	contents := make([]content, 0, len(requests))
+	for _, request := range requests {
+		switch request.Type {
+		case llm.SystemMessage:
+			system = append(system, part{Text: request.Content})
+		case llm.UserMessage:
+			contents = append(contents, content{Role: "user", Parts: []part{{Text: request.Content}}})
+		case llm.AssistantMessage:
+			contents = append(contents, content{Role: "model", Parts: []part{{Text: request.Content}}})
+		default:
+			return nil, fmt.Errorf("unsupported request type: %d", request.Type)
+		}
+	}
+
+	req := &generateRequest{
+		Contents:         contents,
+		GenerationConfig: &generationConfig{Temperature: c.temperature},
+	}
+
+	if len(system) > 0 {
+		req.SystemInstruction = &content{Parts: system}
+	}
+
+	switch responseType {
+	case llm.JSONResponseType:
+		req.GenerationConfig.ResponseMimeType = "application/json"
+	case llm.TextResponseType:
+		req.GenerationConfig.ResponseMimeType = "text/plain"
+	}
+	return req, nil
+}
+
+// pump forwards the chunks read from the stream to out until the stream is over or ctx is done.
+// The last delta sent holds the token usage or the error that ended the stream.
+func pump(ctx context.Context, reader *transport.EventReader, out chan<- *llm.StreamDelta) {
+	send := func(delta *llm.StreamDelta) bool {
+		select {
+		case out <- delta:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	fail := func(err error) {
+		// report the cancellation rather than the transport error it caused
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		send(&llm.StreamDelta{Err: err})
+	}
+
+	var usage *llm.Usage
+	for {
+		event, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			send(&llm.StreamDelta{Usage: usage})
+			return
+		}
+
+		if err != nil {
+			fail(err)
+			return
+		}
+
+		var chunk generateResponse
+		if err := json.Unmarshal([]byte(event.Data), &chunk); err != nil {
+			fail(err)
+			return
+		}
+
+		if chunk.UsageMetadata != nil {
+			usage = &llm.Usage{
+				PromptTokens:     chunk.UsageMetadata.PromptTokenCount,
+				CompletionTokens: chunk.UsageMetadata.CandidatesTokenCount,
+				TotalTokens:      chunk.UsageMetadata.TotalTokenCount,
+			}
+		}
+
+		for _, candidate := range chunk.Candidates {
+			text := candidate.text()
+			if text == "" && candidate.FinishReason == "" {
+				continue
+			}
+
+			if !send(&llm.StreamDelta{
+				Index:        candidate.Index,
+				Content:      text,
+				FinishReason: candidate.FinishReason,
+			}) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,209 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"image"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tochemey/gopack/llm"
+	"github.com/tochemey/gopack/llm/internal/transport"
+)
+
+// newServer creates a test server checking the requests sent to the given path and answering with the handler
+func newServer(t *testing.T, path string, handler func(w http.ResponseWriter, body []byte)) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, path, r.URL.Path)
+		assert.Equal(t, "secret", r.Header.Get("x-goog-api-key"))
+
+		body, _ := io.ReadAll(r.Body)
+		handler(w, body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestQuery(t *testing.T) {
+	server := newServer(t, "/models/gemini:generateContent", func(w http.ResponseWriter, body []byte) {
+		var req generateRequest
+		require.NoError(t, json.Unmarshal(body, &req))
+		require.NotNil(t, req.SystemInstruction)
+		assert.Equal(t, "be brief", req.SystemInstruction.Parts[0].Text)
+		assert.Equal(t, "application/json", req.GenerationConfig.ResponseMimeType)
+		require.Len(t, req.Contents, 2)
+		assert.Equal(t, "user", req.Contents[0].Role)
+		assert.Equal(t, "model", req.Contents[1].Role)
+
+		_, _ = w.Write([]byte(`{"candidates":[{"content":{"parts":[{"text":"{\"answer\":"},{"text":"42}"}]},"finishReason":"STOP"}],"usageMetadata":{"promptTokenCount":10,"candidatesTokenCount":5,"totalTokenCount":15}}`))
+	})
+
+	client := NewClient(&Config{Token: "secret", Model: "gemini", BaseURL: server.URL})
+	responses, err := client.Query(context.Background(), []*llm.Request{
+		{Type: llm.SystemMessage, Content: "be brief"},
+		{Type: llm.UserMessage, Content: "hello"},
+		{Type: llm.AssistantMessage, Content: "hi"},
+	}, llm.JSONResponseType)
+	require.NoError(t, err)
+	require.Len(t, responses, 1)
+	assert.Equal(t, &llm.Response{Content: `{"answer":42}`, PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15}, responses[0])
+}
+
+func TestQueryStream(t *testing.T) {
+	server := newServer(t, "/models/gemini:streamGenerateContent", func(w http.ResponseWriter, _ []byte) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("data: {\"candidates\":[{\"content\":{\"parts\":[{\"text\":\"Hel\"}]}}]}\n\n" +
+			"data: {\"candidates\":[{\"content\":{\"parts\":[{\"text\":\"lo\"}]},\"finishReason\":\"STOP\"}],\"usageMetadata\":{\"promptTokenCount\":10,\"candidatesTokenCount\":2,\"totalTokenCount\":12}}\n\n"))
+	})
+
+	client := NewClient(&Config{Token: "secret", Model: "gemini", BaseURL: server.URL})
+	deltas, err := client.QueryStream(context.Background(), []*llm.Request{{Type: llm.UserMessage, Content: "hello"}}, llm.TextResponseType)
+	require.NoError(t, err)
+
+	var received []*llm.StreamDelta
+	for delta := range deltas {
+		received = append(received, delta)
+	}
+
+	assert.Equal(t, []*llm.StreamDelta{
+		{Content: "Hel"},
+		{Content: "lo", FinishReason: "STOP"},
+		{Usage: &llm.Usage{PromptTokens: 10, CompletionTokens: 2, TotalTokens: 12}},
+	}, received)
+}
+
+func TestVisionQuery(t *testing.T) {
+	server := newServer(t, "/models/gemini:generateContent", func(w http.ResponseWriter, body []byte) {
+		var req generateRequest
+		require.NoError(t, json.Unmarshal(body, &req))
+		require.Len(t, req.Contents, 1)
+		parts := req.Contents[0].Parts
+		require.Len(t, parts, 2)
+		assert.Equal(t, "describe the image", parts[0].Text)
+		require.NotNil(t, parts[1].InlineData)
+		assert.Equal(t, transport.JPEGMediaType, parts[1].InlineData.MimeType)
+
+		_, _ = w.Write([]byte(`{"candidates":[{"content":{"parts":[{"text":"a black square"}]}}]}`))
+	})
+
+	client := NewClient(&Config{Token: "secret", Model: "gemini", BaseURL: server.URL})
+	responses, err := client.VisionQuery(context.Background(),
+		&llm.VisionRequest{Content: "describe the image"},
+		&llm.VisionRequest{Image: image.NewRGBA(image.Rect(0, 0, 4, 4))},
+	)
+	require.NoError(t, err)
+	require.Len(t, responses, 1)
+	assert.Equal(t, "a black square", responses[0].Content)
+
+	t.Run("with image urls and encoded images", func(t *testing.T) {
+		server := newServer(t, "/models/gemini:generateContent", func(w http.ResponseWriter, body []byte) {
+			var req generateRequest
+			require.NoError(t, json.Unmarshal(body, &req))
+			parts := req.Contents[0].Parts
+			require.Len(t, parts, 3)
+			assert.Equal(t, &fileData{MimeType: "image/png", FileURI: "gs://bucket/cat.png"}, parts[0].FileData)
+			assert.Equal(t, "and", parts[1].Text)
+			assert.Equal(t, &inlineData{MimeType: "image/webp", Data: "UklGRg=="}, parts[2].InlineData)
+
+			_, _ = w.Write([]byte(`{"candidates":[{"content":{"parts":[{"text":"two cats"}]}}]}`))
+		})
+
+		client := NewClient(&Config{Token: "secret", Model: "gemini", BaseURL: server.URL})
+		responses, err := client.VisionQuery(context.Background(),
+			&llm.VisionRequest{ImageURL: "gs://bucket/cat.png"},
+			&llm.VisionRequest{Content: "and"},
+			&llm.VisionRequest{ImageData: []byte("RIFF"), MediaType: "image/webp"},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, "two cats", responses[0].Content)
+	})
+}
+
+func TestEmbeddings(t *testing.T) {
+	t.Run("with success", func(t *testing.T) {
+		server := newServer(t, "/models/"+DefaultEmbeddingModel+":batchEmbedContents", func(w http.ResponseWriter, body []byte) {
+			var req embedRequest
+			require.NoError(t, json.Unmarshal(body, &req))
+			require.Len(t, req.Requests, 2)
+			assert.Equal(t, "models/"+DefaultEmbeddingModel, req.Requests[0].Model)
+			assert.Equal(t, "world", req.Requests[1].Content.Parts[0].Text)
+
+			_, _ = w.Write([]byte(`{"embeddings":[{"values":[0.1,0.2]},{"values":[0.3,0.4]}]}`))
+		})
+
+		client := NewClient(&Config{Token: "secret", Model: "gemini", BaseURL: server.URL})
+		embeddings, err := client.Embeddings(context.Background(), []string{"hello", "world"})
+		require.NoError(t, err)
+		assert.Equal(t, [][]float32{{0.1, 0.2}, {0.3, 0.4}}, embeddings)
+	})
+	t.Run("with batches", func(t *testing.T) {
+		requests := 0
+		server := newServer(t, "/models/custom-embedder:batchEmbedContents", func(w http.ResponseWriter, body []byte) {
+			requests++
+			var req embedRequest
+			require.NoError(t, json.Unmarshal(body, &req))
+			assert.LessOrEqual(t, len(req.Requests), maxEmbeddingBatchSize)
+
+			resp := embedResponse{Embeddings: make([]struct {
+				Values []float32 `json:"values"`
+			}, len(req.Requests))}
+			for i, request := range req.Requests {
+				resp.Embeddings[i].Values = []float32{float32(len(request.Content.Parts[0].Text))}
+			}
+			_ = json.NewEncoder(w).Encode(resp)
+		})
+
+		inputs := make([]string, maxEmbeddingBatchSize+1)
+		for i := range inputs {
+			inputs[i] = strings.Repeat("a", i+1)
+		}
+
+		client := NewClient(&Config{Token: "secret", Model: "gemini", BaseURL: server.URL})
+		embeddings, err := client.Embed(context.Background(), inputs, "custom-embedder")
+		require.NoError(t, err)
+		require.Len(t, embeddings, len(inputs))
+		for i, embedding := range embeddings {
+			assert.Equal(t, []float32{float32(i + 1)}, embedding)
+		}
+		assert.Equal(t, 2, requests)
+	})
+	t.Run("with malformed response", func(t *testing.T) {
+		server := newServer(t, "/models/"+DefaultEmbeddingModel+":batchEmbedContents", func(w http.ResponseWriter, _ []byte) {
+			_, _ = w.Write([]byte(`{"embeddings":[]}`))
+		})
+
+		client := NewClient(&Config{Token: "secret", Model: "gemini", BaseURL: server.URL})
+		_, err := client.Embeddings(context.Background(), []string{"hello"})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,90 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package transport
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/tochemey/gopack/llm"
+)
+
+// JPEGMediaType is the media type of the images encoded by EncodeJPEG
+const JPEGMediaType = "image/jpeg"
+
+// EncodeJPEG encodes the image as a base64 JPEG
+func EncodeJPEG(img image.Image) (string, error) {
+	buff := new(bytes.Buffer)
+	if err := jpeg.Encode(buff, img, &jpeg.Options{Quality: 100}); err != nil {
+		return "", fmt.Errorf("image failed to convert: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(buff.Bytes()), nil
+}
+
+// EncodeImage returns the media type and the base64 data of the in-memory image or the encoded image of
+// the request. The in-memory images are JPEG encoded while the encoded ones are sent as they are.
+func EncodeImage(request *llm.VisionRequest) (mediaType, data string, err error) {
+	switch {
+	case request.Image != nil:
+		data, err := EncodeJPEG(request.Image)
+		return JPEGMediaType, data, err
+	case len(request.ImageData) > 0:
+		mediaType := request.MediaType
+		if mediaType == "" {
+			mediaType = http.DetectContentType(request.ImageData)
+		}
+
+		if !strings.HasPrefix(mediaType, "image/") {
+			return "", "", fmt.Errorf("unsupported image media type: %s", mediaType)
+		}
+		return mediaType, base64.StdEncoding.EncodeToString(request.ImageData), nil
+	default:
+		return "", "", errors.New("the request has no image data")
+	}
+}
+
+// URLMediaType returns the media type of the image url of the request, guessed from the url extension
+// when not set. It falls back to JPEGMediaType.
+func URLMediaType(request *llm.VisionRequest) string {
+	if request.MediaType != "" {
+		return request.MediaType
+	}
+
+	if parsed, err := url.Parse(request.ImageURL); err == nil {
+		if mediaType := mime.TypeByExtension(strings.ToLower(path.Ext(parsed.Path))); strings.HasPrefix(mediaType, "image/") {
+			return mediaType
+		}
+	}
+	return JPEGMediaType
+}
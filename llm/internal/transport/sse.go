@@ -0,0 +1,93 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package transport
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// Event is a server-sent event
+type Event struct {
+	// Name is the event type. It is empty when the event does not set it.
+	Name string
+	// Data is the event payload
+	Data string
+}
+
+// EventReader reads the events of a server-sent events stream
+type EventReader struct {
+	scanner *bufio.Scanner
+}
+
+// NewEventReader creates an EventReader reading the given stream
+func NewEventReader(r io.Reader) *EventReader {
+	scanner := bufio.NewScanner(r)
+	// the chunks of a generation can be large
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	return &EventReader{scanner: scanner}
+}
+
+// Next returns the next event with some data. It returns io.EOF once the stream is over.
+func (r *EventReader) Next() (Event, error) {
+	var (
+		event Event
+		data  []string
+	)
+
+	for r.scanner.Scan() {
+		line := r.scanner.Text()
+		if line == "" {
+			// a blank line dispatches the event
+			if len(data) > 0 {
+				event.Data = strings.Join(data, "\n")
+				return event, nil
+			}
+			event = Event{}
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event.Name = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return Event{}, err
+	}
+
+	// dispatch the last event when the stream does not end with a blank line
+	if len(data) > 0 {
+		event.Data = strings.Join(data, "\n")
+		return event, nil
+	}
+	return Event{}, io.EOF
+}
@@ -0,0 +1,142 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+// Package transport holds the HTTP plumbing shared by the LLM providers calling REST APIs
+package transport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+
+	"github.com/tochemey/gopack/clock"
+)
+
+// StatusError is returned when the API answers with an unexpected status code
+type StatusError struct {
+	// StatusCode is the HTTP status code
+	StatusCode int
+	// Body is the response body
+	Body string
+}
+
+// Error returns the error message
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status code %d: %s", e.StatusCode, e.Body)
+}
+
+// retryable returns true when the request can be sent again
+func (e *StatusError) retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests ||
+		e.StatusCode == http.StatusRequestTimeout ||
+		e.StatusCode >= http.StatusInternalServerError
+}
+
+// Post sends the JSON payload to the given url and returns the response whose status code is 200.
+// Any other status code is returned as a StatusError, marked as permanent when it is not worth retrying.
+// The caller must close the response body.
+func Post(ctx context.Context, client *http.Client, url string, headers map[string]string, payload any) (*http.Response, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, backoff.Permanent(err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, backoff.Permanent(err)
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		request.Header.Set(key, value)
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		defer response.Body.Close()
+		content, _ := io.ReadAll(io.LimitReader(response.Body, 4096))
+		statusErr := &StatusError{StatusCode: response.StatusCode, Body: string(bytes.TrimSpace(content))}
+		if !statusErr.retryable() {
+			return nil, backoff.Permanent(statusErr)
+		}
+		return nil, statusErr
+	}
+	return response, nil
+}
+
+// PostJSON sends the JSON payload to the given url and decodes the JSON response into out
+func PostJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, payload, out any) error {
+	response, err := Post(ctx, client, url, headers, payload)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	return json.NewDecoder(response.Body).Decode(out)
+}
+
+// Retry runs the operation until it succeeds, returns a permanent error
+// or the maximum number of retries is reached
+func Retry(clock clock.Clock, maxRetries int, operation backoff.Operation) error {
+	exponential := backoff.NewExponentialBackOff()
+	exponential.Clock = clock
+	policy := backoff.WithMaxRetries(exponential, uint64(max(maxRetries, 0)))
+	return backoff.RetryNotifyWithTimer(operation, policy, nil, &timer{clock: clock})
+}
+
+// timer adapts a clock.Clock to the backoff.Timer interface
+type timer struct {
+	clock clock.Clock
+	timer clock.Timer
+}
+
+// Start starts the timer to fire after the given duration
+func (t *timer) Start(duration time.Duration) {
+	if t.timer == nil {
+		t.timer = t.clock.NewTimer(duration)
+		return
+	}
+	t.timer.Reset(duration)
+}
+
+// Stop is called when the timer is not used anymore and resources may be freed
+func (t *timer) Stop() {
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+}
+
+// C returns the timer channel which receives the current time when the timer fires
+func (t *timer) C() <-chan time.Time {
+	return t.timer.C()
+}
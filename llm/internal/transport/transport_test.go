@@ -0,0 +1,161 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package transport
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"image"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tochemey/gopack/clock"
+	"github.com/tochemey/gopack/llm"
+)
+
+func TestEventReader(t *testing.T) {
+	stream := "event: message_start\ndata: {\"a\":1}\n\n" +
+		": comment\n\n" +
+		"data: line 1\ndata: line 2\n\n" +
+		"data: last"
+
+	reader := NewEventReader(strings.NewReader(stream))
+
+	event, err := reader.Next()
+	require.NoError(t, err)
+	assert.Equal(t, Event{Name: "message_start", Data: `{"a":1}`}, event)
+
+	event, err = reader.Next()
+	require.NoError(t, err)
+	assert.Equal(t, Event{Data: "line 1\nline 2"}, event)
+
+	event, err = reader.Next()
+	require.NoError(t, err)
+	assert.Equal(t, Event{Data: "last"}, event)
+
+	_, err = reader.Next()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestPostJSON(t *testing.T) {
+	t.Run("with success", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			assert.Equal(t, "secret", r.Header.Get("x-api-key"))
+			body, _ := io.ReadAll(r.Body)
+			assert.JSONEq(t, `{"input":"ping"}`, string(body))
+			_, _ = w.Write([]byte(`{"output":"pong"}`))
+		}))
+		defer server.Close()
+
+		var out struct {
+			Output string `json:"output"`
+		}
+		err := PostJSON(context.Background(), server.Client(), server.URL, map[string]string{"x-api-key": "secret"}, map[string]string{"input": "ping"}, &out)
+		require.NoError(t, err)
+		assert.Equal(t, "pong", out.Output)
+	})
+	t.Run("with retries", func(t *testing.T) {
+		calls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			calls++
+			if calls < 3 {
+				http.Error(w, "overloaded", http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = w.Write([]byte(`{}`))
+		}))
+		defer server.Close()
+
+		err := Retry(clock.New(), 3, func() error {
+			return PostJSON(context.Background(), server.Client(), server.URL, nil, struct{}{}, &struct{}{})
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, calls)
+	})
+	t.Run("with permanent error", func(t *testing.T) {
+		calls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			calls++
+			http.Error(w, "invalid api key", http.StatusUnauthorized)
+		}))
+		defer server.Close()
+
+		err := Retry(clock.New(), 3, func() error {
+			return PostJSON(context.Background(), server.Client(), server.URL, nil, struct{}{}, &struct{}{})
+		})
+		require.Error(t, err)
+
+		var statusErr *StatusError
+		require.True(t, errors.As(err, &statusErr))
+		assert.Equal(t, http.StatusUnauthorized, statusErr.StatusCode)
+		assert.Equal(t, "invalid api key", statusErr.Body)
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestEncodeImage(t *testing.T) {
+	t.Run("with an in-memory image", func(t *testing.T) {
+		mediaType, data, err := EncodeImage(&llm.VisionRequest{Image: image.NewRGBA(image.Rect(0, 0, 4, 4))})
+		require.NoError(t, err)
+		assert.Equal(t, JPEGMediaType, mediaType)
+		assert.NotEmpty(t, data)
+	})
+	t.Run("with encoded data", func(t *testing.T) {
+		buff := new(bytes.Buffer)
+		require.NoError(t, png.Encode(buff, image.NewRGBA(image.Rect(0, 0, 4, 4))))
+
+		mediaType, data, err := EncodeImage(&llm.VisionRequest{ImageData: buff.Bytes()})
+		require.NoError(t, err)
+		assert.Equal(t, "image/png", mediaType)
+		assert.Equal(t, base64.StdEncoding.EncodeToString(buff.Bytes()), data)
+
+		mediaType, _, err = EncodeImage(&llm.VisionRequest{ImageData: []byte("RIFF"), MediaType: "image/webp"})
+		require.NoError(t, err)
+		assert.Equal(t, "image/webp", mediaType)
+	})
+	t.Run("with invalid data", func(t *testing.T) {
+		_, _, err := EncodeImage(&llm.VisionRequest{ImageData: []byte("not an image")})
+		assert.Error(t, err)
+
+		_, _, err = EncodeImage(&llm.VisionRequest{Content: "text"})
+		assert.Error(t, err)
+	})
+}
+
+func TestURLMediaType(t *testing.T) {
+	assert.Equal(t, "image/png", URLMediaType(&llm.VisionRequest{ImageURL: "https://example.com/cat.PNG?size=large"}))
+	assert.Equal(t, "image/webp", URLMediaType(&llm.VisionRequest{ImageURL: "https://example.com/cat", MediaType: "image/webp"}))
+	assert.Equal(t, JPEGMediaType, URLMediaType(&llm.VisionRequest{ImageURL: "https://example.com/cat"}))
+}
@@ -0,0 +1,142 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package llm
+
+import "image"
+
+// RequestType defines the query message type
+type RequestType int
+
+const (
+	// UserMessage defines a user message
+	UserMessage RequestType = iota
+	// SystemMessage defines a system message
+	SystemMessage
+	// AssistantMessage defines an assistant message
+	AssistantMessage
+	// ToolMessage defines a message holding the result of a tool call
+	ToolMessage
+)
+
+// ResponseType defines the query response type
+type ResponseType int
+
+const (
+	// JSONResponseType defines the JSON response type
+	JSONResponseType ResponseType = iota
+	// TextResponseType defines the TEXT response type
+	TextResponseType
+)
+
+// Request defines the query message sent to the model
+type Request struct {
+	// Type specifies the message type
+	Type RequestType
+	// Content specifies the message content
+	Content string
+	// ToolCalls specifies the tool calls requested by the model in an AssistantMessage
+	ToolCalls []*ToolCall
+	// ToolCallID specifies the tool call a ToolMessage answers
+	ToolCallID string
+}
+
+// ToolCall defines a tool call requested by the model
+type ToolCall struct {
+	// ID specifies the tool call identifier
+	ID string
+	// Name specifies the name of the tool to run
+	Name string
+	// Arguments specifies the tool arguments in JSON format
+	Arguments string
+}
+
+// ImageDetail defines the level of detail the model processes an image with
+type ImageDetail string
+
+const (
+	// ImageDetailAuto lets the model choose the level of detail
+	ImageDetailAuto ImageDetail = "auto"
+	// ImageDetailLow processes a low resolution version of the image, using fewer tokens
+	ImageDetailLow ImageDetail = "low"
+	// ImageDetailHigh processes the image in high resolution
+	ImageDetailHigh ImageDetail = "high"
+)
+
+// VisionRequest defines an image message request sent to the model.
+// A request holds either a text, in Content, or an image, set by Image, ImageURL or ImageData.
+// The requests of a vision query are sent as a single message, in order, so that texts and images can be interleaved.
+type VisionRequest struct {
+	// Type specifies the message type
+	Type RequestType
+	// Content specifies the message content
+	Content string
+	// Image specifies an in-memory image. It is sent JPEG encoded.
+	Image image.Image
+	// ImageURL specifies the url of an image fetched by the provider
+	ImageURL string
+	// ImageData specifies an encoded image, such as PNG, JPEG, GIF or WebP, sent without re-encoding
+	ImageData []byte
+	// MediaType specifies the media type of ImageData or ImageURL. It is detected when empty.
+	MediaType string
+	// Detail specifies the level of detail of the image for the providers supporting it
+	Detail ImageDetail
+}
+
+// HasImage returns true when the request holds an image
+func (r *VisionRequest) HasImage() bool {
+	return r.Image != nil || r.ImageURL != "" || len(r.ImageData) > 0
+}
+
+// Response defines the model response
+type Response struct {
+	// Content specifies the response content
+	Content string
+	// ToolCalls specifies the tool calls requested by the model instead of a final answer
+	ToolCalls        []*ToolCall
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+}
+
+// Usage defines the tokens used by a query
+type Usage struct {
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+}
+
+// StreamDelta defines a partial response streamed by the model
+type StreamDelta struct {
+	// Index specifies the index of the choice the content belongs to
+	Index int
+	// Content specifies the chunk of the response content
+	Content string
+	// FinishReason specifies why the generation of the choice stopped. It is set on the last chunk of the choice.
+	FinishReason string
+	// Usage specifies the tokens used by the whole query. It is only set on the final delta.
+	Usage *Usage
+	// Err specifies the error that ended the stream. It is only set on the final delta.
+	Err error
+}
@@ -0,0 +1,74 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package llm
+
+import "context"
+
+// Querier sends messages to a model and returns its responses. Every Client is a Querier.
+type Querier interface {
+	// Query sends the messages to the model and returns its responses
+	Query(ctx context.Context, requests []*Request, responseType ResponseType) (responses []*Response, err error)
+}
+
+var _ Querier = QuerierFunc(nil)
+
+// QuerierFunc implements the Querier interface.
+type QuerierFunc func(ctx context.Context, requests []*Request, responseType ResponseType) ([]*Response, error)
+
+func (f QuerierFunc) Query(ctx context.Context, requests []*Request, responseType ResponseType) ([]*Response, error) {
+	return f(ctx, requests, responseType)
+}
+
+// Middleware wraps a Querier to act on the queries and their responses without modifying the client
+type Middleware func(next Querier) Querier
+
+// Chain wraps the querier with the middlewares. The first middleware is the outermost one:
+// it sees the queries first and the responses last.
+func Chain(querier Querier, middlewares ...Middleware) Querier {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		querier = middlewares[i](querier)
+	}
+	return querier
+}
+
+// Wrap returns a Client whose queries go through the middlewares, see Chain.
+// The other operations are forwarded to the client as they are.
+func Wrap(client Client, middlewares ...Middleware) Client {
+	return &wrappedClient{
+		Client:  client,
+		querier: Chain(client, middlewares...),
+	}
+}
+
+// wrappedClient is a Client whose queries go through a chain of middlewares
+type wrappedClient struct {
+	Client
+	querier Querier
+}
+
+// Query sends the messages through the middlewares
+func (c *wrappedClient) Query(ctx context.Context, requests []*Request, responseType ResponseType) ([]*Response, error) {
+	return c.querier.Query(ctx, requests, responseType)
+}
@@ -0,0 +1,195 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package middleware
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/tochemey/gopack/clock"
+	"github.com/tochemey/gopack/llm"
+)
+
+// CacheStore stores the responses of the queries by key
+type CacheStore interface {
+	// Get returns the responses stored under the key and whether they were found
+	Get(ctx context.Context, key string) (responses []*llm.Response, found bool, err error)
+	// Set stores the responses under the key
+	Set(ctx context.Context, key string, responses []*llm.Response) error
+}
+
+// Cache returns the stored responses of the queries already answered and stores the responses
+// of the others, keyed by PromptKey. The params, such as the model and its settings, are part of the key
+// so that a store can be shared by differently configured clients.
+// The store errors do not fail the queries which then reach the model.
+func Cache(store CacheStore, params ...any) llm.Middleware {
+	return func(next llm.Querier) llm.Querier {
+		return llm.QuerierFunc(func(ctx context.Context, requests []*llm.Request, responseType llm.ResponseType) ([]*llm.Response, error) {
+			key := PromptKey(requests, responseType, params...)
+			if responses, found, err := store.Get(ctx, key); err == nil && found {
+				return responses, nil
+			}
+
+			responses, err := next.Query(ctx, requests, responseType)
+			if err != nil {
+				return nil, err
+			}
+
+			_ = store.Set(ctx, key, responses)
+			return responses, nil
+		})
+	}
+}
+
+// PromptKey returns the hash of the messages of a query, its response type and the given params.
+// The params must be JSON encodable.
+func PromptKey(requests []*llm.Request, responseType llm.ResponseType, params ...any) string {
+	hash := sha256.New()
+	encoder := json.NewEncoder(hash)
+	// the requests are plain data that always encode
+	_ = encoder.Encode(responseType)
+	_ = encoder.Encode(requests)
+	for _, param := range params {
+		_ = encoder.Encode(param)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// MemoryCache is an in-memory CacheStore. It evicts the least recently used responses
+// beyond its capacity and the responses older than its time to live.
+type MemoryCache struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	clock    clock.Clock
+	entries  map[string]*list.Element
+	order    *list.List
+}
+
+// enforce compilation error
+var _ CacheStore = (*MemoryCache)(nil)
+
+// cacheEntry is an entry of the MemoryCache
+type cacheEntry struct {
+	key       string
+	responses []*llm.Response
+	expiresAt time.Time
+}
+
+// NewMemoryCache creates an instance of MemoryCache holding up to capacity queries for the ttl duration.
+// A zero capacity or ttl means no limit.
+func NewMemoryCache(capacity int, ttl time.Duration) *MemoryCache {
+	return NewMemoryCacheWithClock(capacity, ttl, clock.New())
+}
+
+// NewMemoryCacheWithClock creates an instance of MemoryCache using the given clock to expire the responses
+func NewMemoryCacheWithClock(capacity int, ttl time.Duration, clock clock.Clock) *MemoryCache {
+	return &MemoryCache{
+		capacity: capacity,
+		ttl:      ttl,
+		clock:    clock,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Get returns the responses stored under the key
+func (c *MemoryCache) Get(_ context.Context, key string) ([]*llm.Response, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	entry := element.Value.(*cacheEntry)
+	if !entry.expiresAt.IsZero() && !c.clock.Now().Before(entry.expiresAt) {
+		c.remove(element)
+		return nil, false, nil
+	}
+
+	c.order.MoveToFront(element)
+	return copyResponses(entry.responses), true, nil
+}
+
+// Set stores the responses under the key and evicts the least recently used responses beyond the capacity
+func (c *MemoryCache) Set(_ context.Context, key string, responses []*llm.Response) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expiresAt time.Time
+	if c.ttl > 0 {
+		expiresAt = c.clock.Now().Add(c.ttl)
+	}
+
+	entry := &cacheEntry{key: key, responses: copyResponses(responses), expiresAt: expiresAt}
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return nil
+	}
+
+	c.entries[key] = c.order.PushFront(entry)
+	if c.capacity > 0 && c.order.Len() > c.capacity {
+		c.remove(c.order.Back())
+	}
+	return nil
+}
+
+// Len returns the number of queries cached, the expired ones included until they are evicted
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// remove removes the entry of the element
+func (c *MemoryCache) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*cacheEntry).key)
+}
+
+// copyResponses returns a deep copy of the responses so that the cached ones cannot be altered
+func copyResponses(responses []*llm.Response) []*llm.Response {
+	out := make([]*llm.Response, len(responses))
+	for i, response := range responses {
+		clone := *response
+		if response.ToolCalls != nil {
+			clone.ToolCalls = make([]*llm.ToolCall, len(response.ToolCalls))
+			for j, call := range response.ToolCalls {
+				callClone := *call
+				clone.ToolCalls[j] = &callClone
+			}
+		}
+		out[i] = &clone
+	}
+	return out
+}
@@ -0,0 +1,101 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/tochemey/gopack/llm"
+)
+
+// ErrContentBlocked is returned when a guardrail blocks a query or its response
+var ErrContentBlocked = errors.New("content blocked by a guardrail")
+
+// Guardrail checks a content and returns an error to block it
+type Guardrail func(ctx context.Context, content string) error
+
+// BlockPatterns returns a Guardrail blocking the contents matching any of the patterns
+func BlockPatterns(patterns ...*regexp.Regexp) Guardrail {
+	return func(_ context.Context, content string) error {
+		for _, pattern := range patterns {
+			if pattern.MatchString(content) {
+				return fmt.Errorf("%w: matches %q", ErrContentBlocked, pattern.String())
+			}
+		}
+		return nil
+	}
+}
+
+// InputGuardrails checks the content of the user messages of a query with the guardrails
+// before the query is sent to the model. A blocked query fails with the error of the guardrail.
+func InputGuardrails(guardrails ...Guardrail) llm.Middleware {
+	return func(next llm.Querier) llm.Querier {
+		return llm.QuerierFunc(func(ctx context.Context, requests []*llm.Request, responseType llm.ResponseType) ([]*llm.Response, error) {
+			for _, request := range requests {
+				if request.Type != llm.UserMessage {
+					continue
+				}
+
+				if err := check(ctx, guardrails, request.Content); err != nil {
+					return nil, err
+				}
+			}
+			return next.Query(ctx, requests, responseType)
+		})
+	}
+}
+
+// OutputGuardrails checks the content of the responses of the model with the guardrails.
+// A blocked response fails the query with the error of the guardrail.
+func OutputGuardrails(guardrails ...Guardrail) llm.Middleware {
+	return func(next llm.Querier) llm.Querier {
+		return llm.QuerierFunc(func(ctx context.Context, requests []*llm.Request, responseType llm.ResponseType) ([]*llm.Response, error) {
+			responses, err := next.Query(ctx, requests, responseType)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, response := range responses {
+				if err := check(ctx, guardrails, response.Content); err != nil {
+					return nil, err
+				}
+			}
+			return responses, nil
+		})
+	}
+}
+
+// check runs the guardrails on the content
+func check(ctx context.Context, guardrails []Guardrail, content string) error {
+	for _, guardrail := range guardrails {
+		if err := guardrail(ctx, content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+// Package middleware provides the llm.Middleware logging, caching and guarding the queries
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/tochemey/gopack/clock"
+	"github.com/tochemey/gopack/llm"
+	"github.com/tochemey/gopack/log"
+)
+
+// Redactor masks the sensitive data of a content before it is logged
+type Redactor func(content string) string
+
+// RedactPatterns returns a Redactor replacing the matches of the patterns
+func RedactPatterns(patterns ...*regexp.Regexp) Redactor {
+	return func(content string) string {
+		for _, pattern := range patterns {
+			content = pattern.ReplaceAllString(content, log.Redacted)
+		}
+		return content
+	}
+}
+
+// DefaultRedactor masks the email addresses, the bearer tokens and the API keys
+var DefaultRedactor = RedactPatterns(log.EmailPattern, log.BearerTokenPattern, log.APIKeyPattern)
+
+// Logging logs the queries and their responses at the debug level and the failed queries at the error level.
+// The contents are masked by the redactor, DefaultRedactor when nil.
+func Logging(logger log.Logger, redactor Redactor) llm.Middleware {
+	return LoggingWithClock(logger, redactor, clock.New())
+}
+
+// LoggingWithClock creates the Logging middleware measuring the latency of the queries with the given clock
+func LoggingWithClock(logger log.Logger, redactor Redactor, clock clock.Clock) llm.Middleware {
+	if redactor == nil {
+		redactor = DefaultRedactor
+	}
+
+	return func(next llm.Querier) llm.Querier {
+		return llm.QuerierFunc(func(ctx context.Context, requests []*llm.Request, responseType llm.ResponseType) ([]*llm.Response, error) {
+			logger := logger.WithContext(ctx)
+			logger.Debugf("llm query: %s", formatRequests(requests, redactor))
+
+			startedAt := clock.Now()
+			responses, err := next.Query(ctx, requests, responseType)
+			latency := clock.Since(startedAt)
+			if err != nil {
+				logger.Errorf("llm query failed after %s: %s", latency, redactor(err.Error()))
+				return nil, err
+			}
+
+			logger.Debugf("llm response after %s: %s", latency, formatResponses(responses, redactor))
+			return responses, nil
+		})
+	}
+}
+
+// formatRequests formats the messages of a query for the logs
+func formatRequests(requests []*llm.Request, redactor Redactor) string {
+	messages := make([]string, len(requests))
+	for i, request := range requests {
+		messages[i] = fmt.Sprintf("[%s] %s", requestRole(request.Type), redactor(request.Content))
+	}
+	return strings.Join(messages, " ")
+}
+
+// formatResponses formats the responses of a query for the logs
+func formatResponses(responses []*llm.Response, redactor Redactor) string {
+	messages := make([]string, len(responses))
+	for i, response := range responses {
+		messages[i] = fmt.Sprintf("[%d tokens] %s", response.TotalTokens, redactor(response.Content))
+	}
+	return strings.Join(messages, " ")
+}
+
+// requestRole returns the role of a message type
+func requestRole(kind llm.RequestType) string {
+	switch kind {
+	case llm.SystemMessage:
+		return "system"
+	case llm.UserMessage:
+		return "user"
+	case llm.AssistantMessage:
+		return "assistant"
+	case llm.ToolMessage:
+		return "tool"
+	default:
+		return "unknown"
+	}
+}
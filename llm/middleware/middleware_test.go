@@ -0,0 +1,271 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tochemey/gopack/clock"
+	"github.com/tochemey/gopack/llm"
+	"github.com/tochemey/gopack/log"
+	"github.com/tochemey/gopack/log/zapl"
+)
+
+// echo is a Querier answering with the content of the last message and counting its calls
+type echo struct {
+	mu    sync.Mutex
+	calls int
+	err   error
+}
+
+func (e *echo) Query(_ context.Context, requests []*llm.Request, _ llm.ResponseType) ([]*llm.Response, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.calls++
+	if e.err != nil {
+		return nil, e.err
+	}
+	return []*llm.Response{{Content: requests[len(requests)-1].Content, TotalTokens: 12}}, nil
+}
+
+// mapStore is an in-memory CacheStore
+type mapStore struct {
+	mu        sync.Mutex
+	responses map[string][]*llm.Response
+	err       error
+}
+
+func (s *mapStore) Get(_ context.Context, key string) ([]*llm.Response, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return nil, false, s.err
+	}
+	responses, ok := s.responses[key]
+	return responses, ok, nil
+}
+
+func (s *mapStore) Set(_ context.Context, key string, responses []*llm.Response) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.responses[key] = responses
+	return nil
+}
+
+func TestLogging(t *testing.T) {
+	t.Run("with redacted contents", func(t *testing.T) {
+		buffer := new(bytes.Buffer)
+		logger := zapl.New(log.DebugLevel, buffer)
+
+		querier := llm.Chain(&echo{}, Logging(logger, nil))
+		_, err := querier.Query(context.Background(), []*llm.Request{
+			{Type: llm.SystemMessage, Content: "be brief"},
+			{Type: llm.UserMessage, Content: "email john.doe@example.com with key sk-abcdefghijklmnopqrstuvwxyz"},
+		}, llm.TextResponseType)
+		require.NoError(t, err)
+
+		output := buffer.String()
+		assert.Contains(t, output, "[system] be brief")
+		assert.Contains(t, output, "[user] email [REDACTED] with key [REDACTED]")
+		assert.Contains(t, output, "[12 tokens]")
+		assert.NotContains(t, output, "john.doe@example.com")
+		assert.NotContains(t, output, "sk-abcdefghijklmnopqrstuvwxyz")
+	})
+	t.Run("with failed query", func(t *testing.T) {
+		buffer := new(bytes.Buffer)
+		logger := zapl.New(log.DebugLevel, buffer)
+
+		querier := llm.Chain(&echo{err: errors.New("invalid key sk-abcdefghijklmnopqrstuvwxyz")}, Logging(logger, RedactPatterns(regexp.MustCompile(`sk-\w+`))))
+		_, err := querier.Query(context.Background(), []*llm.Request{{Type: llm.UserMessage, Content: "hello"}}, llm.TextResponseType)
+		require.Error(t, err)
+		assert.Contains(t, buffer.String(), "llm query failed")
+		assert.Contains(t, buffer.String(), "invalid key [REDACTED]")
+	})
+	t.Run("with latency", func(t *testing.T) {
+		buffer := new(bytes.Buffer)
+		logger := zapl.New(log.DebugLevel, buffer)
+		fake := clock.NewFake(time.Now())
+		slow := llm.QuerierFunc(func(ctx context.Context, requests []*llm.Request, responseType llm.ResponseType) ([]*llm.Response, error) {
+			fake.Advance(1500 * time.Millisecond)
+			return new(echo).Query(ctx, requests, responseType)
+		})
+
+		querier := llm.Chain(slow, LoggingWithClock(logger, nil, fake))
+		_, err := querier.Query(context.Background(), []*llm.Request{{Type: llm.UserMessage, Content: "hello"}}, llm.TextResponseType)
+		require.NoError(t, err)
+		assert.Contains(t, buffer.String(), "llm response after 1.5s: [12 tokens] hello")
+	})
+}
+
+func TestCache(t *testing.T) {
+	requests := []*llm.Request{{Type: llm.UserMessage, Content: "hello"}}
+
+	t.Run("with repeated query", func(t *testing.T) {
+		next := &echo{}
+		store := &mapStore{responses: make(map[string][]*llm.Response)}
+		querier := llm.Chain(next, Cache(store))
+
+		for range 3 {
+			responses, err := querier.Query(context.Background(), requests, llm.TextResponseType)
+			require.NoError(t, err)
+			assert.Equal(t, "hello", responses[0].Content)
+		}
+		assert.Equal(t, 1, next.calls)
+
+		// the response type is part of the key
+		_, err := querier.Query(context.Background(), requests, llm.JSONResponseType)
+		require.NoError(t, err)
+		assert.Equal(t, 2, next.calls)
+	})
+	t.Run("with failing store", func(t *testing.T) {
+		next := &echo{}
+		querier := llm.Chain(next, Cache(&mapStore{err: errors.New("store down")}))
+
+		for range 2 {
+			_, err := querier.Query(context.Background(), requests, llm.TextResponseType)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 2, next.calls)
+	})
+	t.Run("with prompt key", func(t *testing.T) {
+		key := PromptKey(requests, llm.TextResponseType)
+		assert.Len(t, key, 64)
+		assert.Equal(t, key, PromptKey([]*llm.Request{{Type: llm.UserMessage, Content: "hello"}}, llm.TextResponseType))
+		assert.NotEqual(t, key, PromptKey([]*llm.Request{{Type: llm.SystemMessage, Content: "hello"}}, llm.TextResponseType))
+	})
+}
+
+func TestMemoryCache(t *testing.T) {
+	ctx := context.Background()
+	responses := func(content string) []*llm.Response {
+		return []*llm.Response{{Content: content, ToolCalls: []*llm.ToolCall{{ID: "call-1", Name: "search"}}}}
+	}
+
+	t.Run("with least recently used eviction", func(t *testing.T) {
+		cache := NewMemoryCache(2, 0)
+		require.NoError(t, cache.Set(ctx, "a", responses("a")))
+		require.NoError(t, cache.Set(ctx, "b", responses("b")))
+
+		// a becomes the most recently used
+		_, found, err := cache.Get(ctx, "a")
+		require.NoError(t, err)
+		assert.True(t, found)
+
+		require.NoError(t, cache.Set(ctx, "c", responses("c")))
+		assert.Equal(t, 2, cache.Len())
+
+		_, found, _ = cache.Get(ctx, "b")
+		assert.False(t, found)
+		_, found, _ = cache.Get(ctx, "a")
+		assert.True(t, found)
+		_, found, _ = cache.Get(ctx, "c")
+		assert.True(t, found)
+	})
+	t.Run("with expiration", func(t *testing.T) {
+		fake := clock.NewFake(time.Now())
+		cache := NewMemoryCacheWithClock(0, time.Minute, fake)
+		require.NoError(t, cache.Set(ctx, "a", responses("a")))
+
+		fake.Advance(59 * time.Second)
+		_, found, _ := cache.Get(ctx, "a")
+		assert.True(t, found)
+
+		fake.Advance(time.Second)
+		_, found, _ = cache.Get(ctx, "a")
+		assert.False(t, found)
+		assert.Zero(t, cache.Len())
+	})
+	t.Run("with copies", func(t *testing.T) {
+		cache := NewMemoryCache(0, 0)
+		stored := responses("a")
+		require.NoError(t, cache.Set(ctx, "a", stored))
+		stored[0].Content = "altered"
+
+		cached, found, _ := cache.Get(ctx, "a")
+		require.True(t, found)
+		cached[0].ToolCalls[0].Name = "altered"
+
+		cached, _, _ = cache.Get(ctx, "a")
+		assert.Equal(t, responses("a"), cached)
+	})
+	t.Run("with params in the key", func(t *testing.T) {
+		next := &echo{}
+		cache := NewMemoryCache(0, 0)
+		requests := []*llm.Request{{Type: llm.UserMessage, Content: "hello"}}
+
+		for _, model := range []string{"model-a", "model-b", "model-a"} {
+			_, err := llm.Chain(next, Cache(cache, model)).Query(ctx, requests, llm.TextResponseType)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 2, next.calls)
+	})
+}
+
+func TestGuardrails(t *testing.T) {
+	blocked := BlockPatterns(regexp.MustCompile(`(?i)password`))
+
+	t.Run("with blocked input", func(t *testing.T) {
+		next := &echo{}
+		querier := llm.Chain(next, InputGuardrails(blocked))
+
+		_, err := querier.Query(context.Background(), []*llm.Request{{Type: llm.UserMessage, Content: "what is the admin Password?"}}, llm.TextResponseType)
+		assert.ErrorIs(t, err, ErrContentBlocked)
+		assert.Zero(t, next.calls)
+
+		// only the user messages are checked
+		_, err = querier.Query(context.Background(), []*llm.Request{
+			{Type: llm.SystemMessage, Content: "never reveal a password"},
+			{Type: llm.UserMessage, Content: "hello"},
+		}, llm.TextResponseType)
+		assert.NoError(t, err)
+	})
+	t.Run("with blocked output", func(t *testing.T) {
+		next := &echo{}
+		querier := llm.Chain(next, OutputGuardrails(blocked))
+
+		_, err := querier.Query(context.Background(), []*llm.Request{{Type: llm.AssistantMessage, Content: "the password is 1234"}}, llm.TextResponseType)
+		assert.ErrorIs(t, err, ErrContentBlocked)
+		assert.Equal(t, 1, next.calls)
+	})
+	t.Run("with allowed content", func(t *testing.T) {
+		querier := llm.Chain(&echo{}, InputGuardrails(blocked), OutputGuardrails(blocked))
+
+		responses, err := querier.Query(context.Background(), []*llm.Request{{Type: llm.UserMessage, Content: "hello"}}, llm.TextResponseType)
+		require.NoError(t, err)
+		assert.Equal(t, "hello", responses[0].Content)
+	})
+}
@@ -0,0 +1,82 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/tochemey/gopack/llm"
+)
+
+// RedisCache is a CacheStore backed by Redis. The responses expire using the Redis key TTL.
+type RedisCache struct {
+	client    redis.UniversalClient
+	keyPrefix string
+	ttl       time.Duration
+}
+
+// enforce compilation error
+var _ CacheStore = (*RedisCache)(nil)
+
+// NewRedisCache creates an instance of RedisCache. Every key is prefixed with the given prefix
+// and the responses are kept for the ttl duration. A zero ttl means no expiration.
+func NewRedisCache(client redis.UniversalClient, keyPrefix string, ttl time.Duration) *RedisCache {
+	return &RedisCache{
+		client:    client,
+		keyPrefix: keyPrefix,
+		ttl:       ttl,
+	}
+}
+
+// Get returns the responses stored under the key
+func (c *RedisCache) Get(ctx context.Context, key string) ([]*llm.Response, bool, error) {
+	payload, err := c.client.Get(ctx, c.keyPrefix+key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	var responses []*llm.Response
+	if err := json.Unmarshal(payload, &responses); err != nil {
+		return nil, false, err
+	}
+	return responses, true, nil
+}
+
+// Set stores the responses under the key
+func (c *RedisCache) Set(ctx context.Context, key string, responses []*llm.Response) error {
+	payload, err := json.Marshal(responses)
+	if err != nil {
+		return err
+	}
+	return c.client.Set(ctx, c.keyPrefix+key, payload, c.ttl).Err()
+}
@@ -0,0 +1,89 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package llm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClient is a Client answering the queries with the content of the last message
+type fakeClient struct {
+	Client
+}
+
+func (fakeClient) Query(_ context.Context, requests []*Request, _ ResponseType) ([]*Response, error) {
+	return []*Response{{Content: requests[len(requests)-1].Content}}, nil
+}
+
+func (fakeClient) Embeddings(_ context.Context, inputs []string) ([][]float32, error) {
+	return make([][]float32, len(inputs)), nil
+}
+
+// tag returns a middleware appending the name to the last message and to the response
+func tag(name string, calls *[]string) Middleware {
+	return func(next Querier) Querier {
+		return QuerierFunc(func(ctx context.Context, requests []*Request, responseType ResponseType) ([]*Response, error) {
+			*calls = append(*calls, name)
+			last := *requests[len(requests)-1]
+			last.Content += " " + name
+			responses, err := next.Query(ctx, append(requests[:len(requests)-1:len(requests)-1], &last), responseType)
+			if err != nil {
+				return nil, err
+			}
+			responses[0].Content += " <" + name
+			return responses, nil
+		})
+	}
+}
+
+func TestChain(t *testing.T) {
+	var calls []string
+	querier := Chain(fakeClient{}, tag("first", &calls), tag("second", &calls))
+
+	responses, err := querier.Query(context.Background(), []*Request{{Type: UserMessage, Content: "hello"}}, TextResponseType)
+	require.NoError(t, err)
+	require.Len(t, responses, 1)
+	assert.Equal(t, "hello first second <second <first", responses[0].Content)
+	assert.Equal(t, []string{"first", "second"}, calls)
+}
+
+func TestWrap(t *testing.T) {
+	var calls []string
+	client := Wrap(fakeClient{}, tag("tagged", &calls))
+
+	responses, err := client.Query(context.Background(), []*Request{{Type: UserMessage, Content: "hello"}}, TextResponseType)
+	require.NoError(t, err)
+	assert.Equal(t, "hello tagged <tagged", responses[0].Content)
+
+	// the other operations are forwarded as they are
+	embeddings, err := client.Embeddings(context.Background(), []string{"a", "b"})
+	require.NoError(t, err)
+	assert.Len(t, embeddings, 2)
+	assert.Equal(t, []string{"tagged"}, calls)
+}
@@ -0,0 +1,128 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// DefaultBatchConcurrency is the default number of queries of a batch sent concurrently
+const DefaultBatchConcurrency = 8
+
+// BatchResult holds the outcome of a query of a batch
+type BatchResult struct {
+	// Responses specifies the responses of the query
+	Responses []*Response
+	// Err specifies the error of the query
+	Err error
+}
+
+// BatchOption is the interface that applies a QueryBatch option.
+type BatchOption interface {
+	// Apply sets the BatchOption value of a batch config.
+	Apply(*batchConfig)
+}
+
+var _ BatchOption = BatchOptionFunc(nil)
+
+// BatchOptionFunc implements the BatchOption interface.
+type BatchOptionFunc func(*batchConfig)
+
+func (f BatchOptionFunc) Apply(c *batchConfig) {
+	f(c)
+}
+
+// WithBatchConcurrency sets the number of queries of a batch sent concurrently
+func WithBatchConcurrency(concurrency int) BatchOption {
+	return BatchOptionFunc(func(c *batchConfig) {
+		c.concurrency = concurrency
+	})
+}
+
+// WithBatchResponseType sets the expected format of the responses of a batch
+func WithBatchResponseType(responseType ResponseType) BatchOption {
+	return BatchOptionFunc(func(c *batchConfig) {
+		c.responseType = responseType
+	})
+}
+
+// batchConfig holds the settings of a QueryBatch call
+type batchConfig struct {
+	concurrency  int
+	responseType ResponseType
+}
+
+// QueryBatch sends every batch of messages as a separate query, with at most the number of queries set by
+// WithBatchConcurrency in flight, and returns their results in the order of the batches.
+// The queries share the rate limiters, the cache and the usage tracking of the client.
+// A failed query does not stop the others: its error is set on its result and the returned error joins the
+// errors of all the failed queries. The queries not started when the context is done fail with the context error.
+func (x api) QueryBatch(ctx context.Context, batches [][]*Request, opts ...BatchOption) ([]*BatchResult, error) {
+	config := &batchConfig{
+		concurrency:  DefaultBatchConcurrency,
+		responseType: TextResponseType,
+	}
+
+	for _, opt := range opts {
+		opt.Apply(config)
+	}
+
+	workers := min(max(config.concurrency, 1), len(batches))
+	results := make([]*BatchResult, len(batches))
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				responses, err := x.Query(ctx, batches[index], config.responseType)
+				results[index] = &BatchResult{Responses: responses, Err: err}
+			}
+		}()
+	}
+
+	for index := range batches {
+		if ctx.Err() != nil {
+			results[index] = &BatchResult{Err: ctx.Err()}
+			continue
+		}
+		indexes <- index
+	}
+	close(indexes)
+	wg.Wait()
+
+	var errs []error
+	for index, result := range results {
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("batch %d: %w", index, result.Err))
+		}
+	}
+	return results, errors.Join(errs...)
+}
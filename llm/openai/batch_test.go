@@ -0,0 +1,139 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newEchoServer creates a test server answering the chat completions with the content of the last message.
+// The messages with the content "fail" are rejected. It records the highest number of requests in flight.
+func newEchoServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	var inflight, highest atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := inflight.Add(1)
+		defer inflight.Add(-1)
+		for {
+			previous := highest.Load()
+			if current <= previous || highest.CompareAndSwap(previous, current) {
+				break
+			}
+		}
+
+		var req chatCompletionRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		content := req.Messages[len(req.Messages)-1].Content
+
+		// leave the time for the other requests to come in
+		time.Sleep(20 * time.Millisecond)
+
+		w.Header().Set("Content-Type", "application/json")
+		if content == "fail" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":{"message":"invalid request","type":"invalid_request_error"}}`))
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(openai.ChatCompletionResponse{
+			Model: "gpt-4o",
+			Choices: []openai.ChatCompletionChoice{{
+				Message: openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: content},
+			}},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server, &highest
+}
+
+func TestQueryBatch(t *testing.T) {
+	t.Run("with results in order", func(t *testing.T) {
+		server, highest := newEchoServer(t)
+		x := newTestAPI(server)
+
+		batches := make([][]*Request, 10)
+		for i := range batches {
+			batches[i] = []*Request{{Type: UserMessage, Content: fmt.Sprintf("item %d", i)}}
+		}
+
+		results, err := x.QueryBatch(context.Background(), batches, WithBatchConcurrency(3))
+		require.NoError(t, err)
+		require.Len(t, results, len(batches))
+		for i, result := range results {
+			require.NoError(t, result.Err)
+			assert.Equal(t, fmt.Sprintf("item %d", i), result.Responses[0].Content)
+		}
+
+		assert.LessOrEqual(t, highest.Load(), int32(3))
+		assert.Greater(t, highest.Load(), int32(1))
+		assert.EqualValues(t, 10, x.Usage().Calls)
+	})
+	t.Run("with failed items", func(t *testing.T) {
+		server, _ := newEchoServer(t)
+		x := newTestAPI(server)
+
+		results, err := x.QueryBatch(context.Background(), [][]*Request{
+			{{Type: UserMessage, Content: "first"}},
+			{{Type: UserMessage, Content: "fail"}},
+			{{Type: UserMessage, Content: "third"}},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "batch 1:")
+		assert.NotContains(t, err.Error(), "batch 0:")
+
+		require.Len(t, results, 3)
+		assert.Equal(t, "first", results[0].Responses[0].Content)
+		assert.Error(t, results[1].Err)
+		assert.Equal(t, "third", results[2].Responses[0].Content)
+	})
+	t.Run("with a canceled context", func(t *testing.T) {
+		server, _ := newEchoServer(t)
+		x := newTestAPI(server)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		results, err := x.QueryBatch(ctx, [][]*Request{{{Type: UserMessage, Content: "hello"}}})
+		assert.ErrorIs(t, err, context.Canceled)
+		require.Len(t, results, 1)
+		assert.ErrorIs(t, results[0].Err, context.Canceled)
+	})
+	t.Run("with no batches", func(t *testing.T) {
+		server, _ := newEchoServer(t)
+		results, err := newTestAPI(server).QueryBatch(context.Background(), nil)
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+}
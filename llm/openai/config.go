@@ -26,6 +26,20 @@ package openai
 
 import "time"
 
+const (
+	// DefaultTokensPerMinute is the default number of tokens per minute allowed by the rate limiter
+	DefaultTokensPerMinute = 1000000
+	// DefaultResponseTokens is the default number of response tokens estimated for a query by the rate limiter
+	DefaultResponseTokens = 100
+	// DefaultVisionResponseTokens is the default number of response tokens estimated for a vision query
+	// by the rate limiter
+	DefaultVisionResponseTokens = 400
+
+	// visionContextTokens is the context size from which the tokens of a vision query are taken
+	// to set its maximum number of generated tokens
+	visionContextTokens = 4096
+)
+
 // Config defines the openai configuration
 type Config struct {
 	// Token defines the OpenAI token
@@ -41,4 +55,10 @@ type Config struct {
 	// Organization defines the OpenAI organization.
 	// This needs to be set on the OpenAI dashboard
 	Organization string
+	// EmbeddingModel defines the model computing the embeddings.
+	// It defaults to DefaultEmbeddingModel.
+	EmbeddingModel string
+	// BaseURL defines the url of the OpenAI API or of any OpenAI compatible API.
+	// It defaults to the OpenAI API url.
+	BaseURL string
 }
@@ -0,0 +1,246 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/pkoukk/tiktoken-go"
+	"github.com/sashabaranov/go-openai"
+)
+
+const (
+	// DefaultEmbeddingModel is the default model computing the embeddings
+	DefaultEmbeddingModel = string(openai.SmallEmbedding3)
+	// DefaultEmbeddingBatchSize is the default maximum number of inputs sent per embeddings request
+	DefaultEmbeddingBatchSize = 2048
+
+	// maxEmbeddingInputTokens is the maximum number of tokens of an embeddings input
+	maxEmbeddingInputTokens = 8191
+	// maxEmbeddingBatchTokens is the maximum number of tokens of an embeddings request
+	maxEmbeddingBatchTokens = 300000
+)
+
+// encoder splits a text into tokens and back
+type encoder interface {
+	Encode(text string) []int
+	Decode(tokens []int) string
+}
+
+// tiktokenEncoder implements the encoder interface with tiktoken
+type tiktokenEncoder struct {
+	tkm *tiktoken.Tiktoken
+}
+
+func (e tiktokenEncoder) Encode(text string) []int {
+	return e.tkm.Encode(text, nil, nil)
+}
+
+func (e tiktokenEncoder) Decode(tokens []int) string {
+	return e.tkm.Decode(tokens)
+}
+
+// newEncoder returns the encoder of the given model.
+// The unknown models, served by OpenAI compatible APIs, fall back to the cl100k_base encoding.
+func newEncoder(model string) (encoder, error) {
+	tkm, err := tiktoken.EncodingForModel(model)
+	if err != nil {
+		if tkm, err = tiktoken.GetEncoding(tiktoken.MODEL_CL100K_BASE); err != nil {
+			return nil, fmt.Errorf("encoding for model: %v", err)
+		}
+	}
+	return tiktokenEncoder{tkm: tkm}, nil
+}
+
+// chunk is a piece of an embeddings input fitting in the model context
+type chunk struct {
+	input  int
+	text   string
+	tokens int
+}
+
+// Embeddings returns the embedding vector of every input, in the order of the inputs.
+// The vectors are computed by the EmbeddingModel of the Config.
+func (x api) Embeddings(ctx context.Context, inputs []string) (embeddings [][]float32, err error) {
+	return x.Embed(ctx, inputs, "")
+}
+
+// Embed returns the embedding vector of every input, in the order of the inputs, computed by the given model.
+// When model is empty the EmbeddingModel of the Config is used.
+func (x api) Embed(ctx context.Context, inputs []string, model string) (embeddings [][]float32, err error) {
+	if model == "" {
+		model = x.config.EmbeddingModel
+	}
+
+	if model == "" {
+		model = DefaultEmbeddingModel
+	}
+
+	if len(inputs) == 0 {
+		return [][]float32{}, nil
+	}
+
+	enc, err := x.encoder(model)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := splitInputs(enc, inputs, maxEmbeddingInputTokens)
+	vectors := make([][]float32, len(chunks))
+	for start := 0; start < len(chunks); {
+		end, tokens := start, 0
+		for end < len(chunks) && end-start < x.embeddingBatchSize {
+			if end > start && tokens+chunks[end].tokens > maxEmbeddingBatchTokens {
+				break
+			}
+			tokens += chunks[end].tokens
+			end++
+		}
+
+		if err := x.embedBatch(ctx, model, chunks[start:end], tokens, vectors[start:end]); err != nil {
+			return nil, err
+		}
+		start = end
+	}
+
+	return combineChunks(len(inputs), chunks, vectors), nil
+}
+
+// embedBatch computes the vectors of the batch of chunks into out
+func (x api) embedBatch(ctx context.Context, model string, batch []chunk, tokens int, out [][]float32) error {
+	if err := x.wait(ctx, tokens); err != nil {
+		return err
+	}
+
+	req := openai.EmbeddingRequestStrings{
+		Input: make([]string, len(batch)),
+		Model: openai.EmbeddingModel(model),
+	}
+
+	for i, chunk := range batch {
+		req.Input[i] = chunk.text
+	}
+
+	var resp openai.EmbeddingResponse
+	// wrap in a function so we can backoff
+	operation := func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, x.config.Timeout)
+		defer cancel()
+		var err error
+		resp, err = x.remote.CreateEmbeddings(ctx, req)
+		return err
+	}
+
+	// implements backoff
+	startedAt := x.clock.Now()
+	err := x.retry(ctx, operation)
+	x.recordUsage(ctx, OperationEmbeddings, model, string(resp.Model), openai.Usage{
+		PromptTokens: resp.Usage.PromptTokens,
+		TotalTokens:  resp.Usage.TotalTokens,
+	}, x.clock.Since(startedAt), err)
+	if err != nil {
+		return err
+	}
+
+	if len(resp.Data) != len(batch) {
+		return errors.New("malformed embeddings response from openai")
+	}
+
+	for _, data := range resp.Data {
+		if data.Index < 0 || data.Index >= len(batch) {
+			return errors.New("malformed embeddings response from openai")
+		}
+		out[data.Index] = data.Embedding
+	}
+	return nil
+}
+
+// splitInputs splits the inputs into chunks of at most maxTokens tokens
+func splitInputs(enc encoder, inputs []string, maxTokens int) []chunk {
+	chunks := make([]chunk, 0, len(inputs))
+	for i, input := range inputs {
+		tokens := enc.Encode(input)
+		if len(tokens) <= maxTokens {
+			chunks = append(chunks, chunk{input: i, text: input, tokens: len(tokens)})
+			continue
+		}
+
+		for start := 0; start < len(tokens); start += maxTokens {
+			end := min(start+maxTokens, len(tokens))
+			chunks = append(chunks, chunk{input: i, text: enc.Decode(tokens[start:end]), tokens: end - start})
+		}
+	}
+	return chunks
+}
+
+// combineChunks returns the vector of every input. The vectors of the chunks of a split input are averaged,
+// weighted by their number of tokens, and normalized to unit length like the vectors returned by the API.
+func combineChunks(size int, chunks []chunk, vectors [][]float32) [][]float32 {
+	embeddings := make([][]float32, size)
+	sums := make([][]float64, size)
+	counts := make([]int, size)
+	for i, chunk := range chunks {
+		counts[chunk.input]++
+		if counts[chunk.input] == 1 {
+			embeddings[chunk.input] = vectors[i]
+		}
+
+		if sums[chunk.input] == nil {
+			sums[chunk.input] = make([]float64, len(vectors[i]))
+		}
+
+		weight := float64(chunk.tokens)
+		for j, value := range vectors[i] {
+			if j < len(sums[chunk.input]) {
+				sums[chunk.input][j] += weight * float64(value)
+			}
+		}
+	}
+
+	for input, count := range counts {
+		// the vector of an input sent as a whole is returned as is
+		if count < 2 {
+			continue
+		}
+
+		var norm float64
+		for _, value := range sums[input] {
+			norm += value * value
+		}
+		norm = math.Sqrt(norm)
+
+		embedding := make([]float32, len(sums[input]))
+		for j, value := range sums[input] {
+			if norm > 0 {
+				embedding[j] = float32(value / norm)
+			}
+		}
+		embeddings[input] = embedding
+	}
+	return embeddings
+}
@@ -0,0 +1,159 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runeEncoder is an encoder turning every rune into a token
+type runeEncoder struct{}
+
+func (runeEncoder) Encode(text string) []int {
+	tokens := make([]int, 0, len(text))
+	for _, r := range text {
+		tokens = append(tokens, int(r))
+	}
+	return tokens
+}
+
+func (runeEncoder) Decode(tokens []int) string {
+	runes := make([]rune, len(tokens))
+	for i, token := range tokens {
+		runes[i] = rune(token)
+	}
+	return string(runes)
+}
+
+// newEmbeddingsServer creates a test server answering every input with the vector computed by embed
+func newEmbeddingsServer(t *testing.T, requests *atomic.Int32, model string, embed func(input string) []float32) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/embeddings", r.URL.Path)
+		requests.Add(1)
+
+		var req struct {
+			Input []string `json:"input"`
+			Model string   `json:"model"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, model, req.Model)
+
+		data := make([]map[string]any, len(req.Input))
+		for i, input := range req.Input {
+			data[i] = map[string]any{"object": "embedding", "index": i, "embedding": embed(input)}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"object": "list", "data": data})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newTestAPI creates an api calling the given server with the rune encoder
+func newTestAPI(server *httptest.Server, opts ...Option) *api {
+	x := NewAPI(&Config{Token: "secret", Model: "gpt-4o", Timeout: time.Second, BaseURL: server.URL}, opts...).(*api)
+	x.encoder = func(string) (encoder, error) { return runeEncoder{}, nil }
+	return x
+}
+
+func TestEmbed(t *testing.T) {
+	t.Run("with batches", func(t *testing.T) {
+		requests := new(atomic.Int32)
+		server := newEmbeddingsServer(t, requests, DefaultEmbeddingModel, func(input string) []float32 {
+			return []float32{float32(len(input))}
+		})
+
+		x := newTestAPI(server, WithEmbeddingBatchSize(2))
+		embeddings, err := x.Embeddings(context.Background(), []string{"a", "bb", "ccc", "dddd", "eeeee"})
+		require.NoError(t, err)
+		assert.Equal(t, [][]float32{{1}, {2}, {3}, {4}, {5}}, embeddings)
+		assert.EqualValues(t, 3, requests.Load())
+	})
+	t.Run("with given model", func(t *testing.T) {
+		requests := new(atomic.Int32)
+		server := newEmbeddingsServer(t, requests, "custom-embedder", func(string) []float32 {
+			return []float32{1}
+		})
+
+		x := newTestAPI(server)
+		embeddings, err := x.Embed(context.Background(), []string{"a"}, "custom-embedder")
+		require.NoError(t, err)
+		assert.Equal(t, [][]float32{{1}}, embeddings)
+	})
+	t.Run("with long input", func(t *testing.T) {
+		requests := new(atomic.Int32)
+		server := newEmbeddingsServer(t, requests, DefaultEmbeddingModel, func(input string) []float32 {
+			if len(input) == maxEmbeddingInputTokens {
+				return []float32{1, 0}
+			}
+			return []float32{0, 1}
+		})
+
+		x := newTestAPI(server)
+		embeddings, err := x.Embeddings(context.Background(), []string{"short", strings.Repeat("a", maxEmbeddingInputTokens+809)})
+		require.NoError(t, err)
+		require.Len(t, embeddings, 2)
+		assert.Equal(t, []float32{0, 1}, embeddings[0])
+
+		// the chunks vectors are averaged by their number of tokens and normalized
+		norm := math.Hypot(maxEmbeddingInputTokens, 809)
+		require.Len(t, embeddings[1], 2)
+		assert.InDelta(t, maxEmbeddingInputTokens/norm, embeddings[1][0], 1e-6)
+		assert.InDelta(t, 809/norm, embeddings[1][1], 1e-6)
+		assert.EqualValues(t, 1, requests.Load())
+	})
+	t.Run("with no inputs", func(t *testing.T) {
+		requests := new(atomic.Int32)
+		server := newEmbeddingsServer(t, requests, DefaultEmbeddingModel, func(string) []float32 { return nil })
+
+		x := newTestAPI(server)
+		embeddings, err := x.Embeddings(context.Background(), nil)
+		require.NoError(t, err)
+		assert.Empty(t, embeddings)
+		assert.Zero(t, requests.Load())
+	})
+}
+
+func TestSplitInputs(t *testing.T) {
+	chunks := splitInputs(runeEncoder{}, []string{"abc", "abcdefg"}, 3)
+	assert.Equal(t, []chunk{
+		{input: 0, text: "abc", tokens: 3},
+		{input: 1, text: "abc", tokens: 3},
+		{input: 1, text: "def", tokens: 3},
+		{input: 1, text: "g", tokens: 1},
+	}, chunks)
+}
@@ -24,53 +24,46 @@
 
 package openai
 
-import "image"
+import "github.com/tochemey/gopack/llm"
 
 // RequestType defines the query message type
-type RequestType int
+type RequestType = llm.RequestType
 
 const (
 	// UserMessage defines a user message when calling the OpenAI apis
-	UserMessage RequestType = iota
+	UserMessage = llm.UserMessage
 	// SystemMessage defines a system message when calling the OpenAI apis
-	SystemMessage
+	SystemMessage = llm.SystemMessage
 	// AssistantMessage defines an assistant message when calling the OpenAI apis
-	AssistantMessage
+	AssistantMessage = llm.AssistantMessage
+	// ToolMessage defines a message holding the result of a tool call when calling the OpenAI apis
+	ToolMessage = llm.ToolMessage
 )
 
 // ResponseType defines the query response type
-type ResponseType int
+type ResponseType = llm.ResponseType
 
 const (
 	// JSONResponseType defines the OpenAI query JSON response type
-	JSONResponseType ResponseType = iota
+	JSONResponseType = llm.JSONResponseType
 	// TextResponseType defines the OpenAI query TEXT response type
-	TextResponseType
+	TextResponseType = llm.TextResponseType
 )
 
 // Request defines the query message sent to OpenAI
-type Request struct {
-	// Type specifies the message type
-	Type RequestType
-	// Content specifies the message content
-	Content string
-}
+type Request = llm.Request
+
+// ToolCall defines a tool call requested by the model
+type ToolCall = llm.ToolCall
 
 // VisionRequest defines an image message request sent to OpenAI
-type VisionRequest struct {
-	// Type specifies the message type
-	Type RequestType
-	// Content specifies the message content
-	Content string
-	// Image specifies the image content
-	Image image.Image
-}
+type VisionRequest = llm.VisionRequest
 
 // Response defines the OpenAI response
-type Response struct {
-	// Content specifies the response content
-	Content          string
-	PromptTokens     int
-	CompletionTokens int
-	TotalTokens      int
-}
+type Response = llm.Response
+
+// Usage defines the tokens used by a query
+type Usage = llm.Usage
+
+// StreamDelta defines a partial response streamed by OpenAI
+type StreamDelta = llm.StreamDelta
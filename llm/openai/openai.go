@@ -28,10 +28,17 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"time"
 
-	"github.com/cenkalti/backoff/v4"
 	openai "github.com/sashabaranov/go-openai"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/time/rate"
+
+	"github.com/tochemey/gopack/clock"
+	"github.com/tochemey/gopack/llm"
+	"github.com/tochemey/gopack/llm/middleware"
 )
 
 // API defines the OpenAI LLM integration
@@ -88,6 +95,40 @@ type API interface {
 	//       fmt.Println("Response:", response.Content)
 	//   }
 	Query(ctx context.Context, requests []*Request, responseType ResponseType) (responses []*Response, err error)
+	// QueryStream sends messages to OpenAI APIs and streams the response as it is generated.
+	//
+	// The returned channel receives the chunks of content of every choice followed by a final delta
+	// holding either the token usage of the query or the error that ended the stream. The channel is
+	// closed once the stream is over. The caller must drain the channel or cancel ctx.
+	//
+	// Only the opening of the stream is retried. The Timeout of the Config does not apply to the stream
+	// which is bounded by ctx, so that long generations are not cut short.
+	QueryStream(ctx context.Context, requests []*Request, responseType ResponseType) (deltas <-chan *StreamDelta, err error)
+	// QueryWithTools sends messages to OpenAI APIs along with the tools declared with WithTools and runs
+	// the tool calls requested by the model with the executor, feeding their results back to the model
+	// until it produces a final answer.
+	//
+	// It returns the final responses and the conversation, the given requests included, that led to them.
+	// The tool errors are reported to the model as the result of the call.
+	QueryWithTools(ctx context.Context, requests []*Request, responseType ResponseType, executor ToolExecutor) (responses []*Response, conversation []*Request, err error)
+	// QuerySchema sends messages to OpenAI APIs asking for a response conforming to the JSON schema with the
+	// structured outputs response format, validates the response and unmarshals it into out.
+	//
+	// A response that does not conform to the schema is sent back to the model along with a corrective
+	// system prompt up to the number of times set with WithMaxSchemaRetries. See QueryStructured for a
+	// typed version.
+	QuerySchema(ctx context.Context, requests []*Request, schema *llm.Schema, out any) (err error)
+	// Usage returns the usage of the calls made so far: the number of calls, the tokens, the cost
+	// computed with the price table and the latency, in total and per model.
+	// The usage of every call is also sent to the recorder set with WithUsageRecorder.
+	Usage() UsageSummary
+	// QueryBatch sends every batch of messages as a separate query, with a bounded number of queries in flight,
+	// and returns their results in the order of the batches. The queries share the rate limiters of the client.
+	// The error of a failed query is set on its result and the returned error joins the errors of all of them.
+	QueryBatch(ctx context.Context, batches [][]*Request, opts ...BatchOption) (results []*BatchResult, err error)
+	// CountTokens returns the number of prompt tokens of the messages counted with the tokenizer of the model.
+	// It implements llm.TokenCounter to manage the context window of an llm.Conversation.
+	CountTokens(requests []*Request) (int, error)
 	// VisionQuery sends image query requests to OpenAI and retrieves responses.
 	//
 	// This function interacts with OpenAI APIs to handle image-related requests
@@ -129,6 +170,16 @@ type API interface {
 	//   - For large or complex image queries, ensure the client application can handle
 	//     the potentially high payload size of the responses.
 	VisionQuery(ctx context.Context, messages ...*VisionRequest) (responses []*Response, err error)
+	// Embeddings returns the embedding vector of every input, in the order of the inputs.
+	// The vectors are computed by the EmbeddingModel of the Config.
+	Embeddings(ctx context.Context, inputs []string) (embeddings [][]float32, err error)
+	// Embed returns the embedding vector of every input, in the order of the inputs, computed by the given model.
+	// When model is empty the EmbeddingModel of the Config is used.
+	//
+	// The inputs are sent in batches of at most the size set with WithEmbeddingBatchSize, each going through
+	// the same rate limiting and retries as Query. An input longer than the model context is split into chunks
+	// whose vectors are averaged, weighted by their number of tokens, into the vector of the input.
+	Embed(ctx context.Context, inputs []string, model string) (embeddings [][]float32, err error)
 }
 
 type api struct {
@@ -138,27 +189,61 @@ type api struct {
 	frequency   float32 // frequency penalty
 	presence    float32 // presence penalty
 	rateLimit   *rate.Limiter
+	requestRate *rate.Limiter
 	httpClient  *http.Client
+	clock       clock.Clock
+
+	tools             []llm.Tool
+	maxToolIterations int
+	maxSchemaRetries  int
+
+	prices        PriceTable
+	usageRecorder UsageRecorder
+	meterProvider metric.MeterProvider
+	usage         *usageTracker
+
+	tracerProvider trace.TracerProvider
+	tracer         trace.Tracer
+
+	cache middleware.CacheStore
+
+	embeddingBatchSize int
+	encoder            func(model string) (encoder, error)
+
+	retryPolicy retryPolicy
+
+	tokensPerMinute   int
+	requestsPerMinute int
+	responseTokens    int
+	maxTokens         int
 }
 
 // enforce compilation error
-var _ API = (*api)(nil)
+var (
+	_ API              = (*api)(nil)
+	_ llm.Client       = (*api)(nil)
+	_ llm.TokenCounter = (*api)(nil)
+)
 
 // NewAPI creates an instance of the Open API wrapper
 func NewAPI(config *Config, opts ...Option) API {
-	// TODO: add this configuration
-	// 90k tokens per minute, halved as to not deplete other resources
-	// tpm := 45000
-	tpm := 1000000
-	tokensPerSecond := tpm / 60
-
 	api := &api{
 		config:      config,
 		temperature: 0,
 		frequency:   0,
 		presence:    0,
-		rateLimit:   rate.NewLimiter(rate.Limit(tokensPerSecond), tpm),
 		httpClient:  http.DefaultClient,
+		clock:       clock.New(),
+
+		maxToolIterations: DefaultMaxToolIterations,
+		maxSchemaRetries:  DefaultMaxSchemaRetries,
+
+		embeddingBatchSize: DefaultEmbeddingBatchSize,
+		encoder:            newEncoder,
+		tokensPerMinute:    DefaultTokensPerMinute,
+		prices:             DefaultPriceTable(),
+		retryPolicy:        defaultRetryPolicy(),
+		tracerProvider:     otel.GetTracerProvider(),
 	}
 
 	// apply the options
@@ -166,13 +251,35 @@ func NewAPI(config *Config, opts ...Option) API {
 		opt.Apply(api)
 	}
 
+	// create the rate limiters unless a custom one is set
+	if api.rateLimit == nil {
+		api.rateLimit = rate.NewLimiter(rate.Limit(float64(api.tokensPerMinute)/60), api.tokensPerMinute)
+	}
+
+	// the usage metrics are only exported when a meter provider is set
+	var metrics *usageMetrics
+	if api.meterProvider != nil {
+		metrics, _ = newUsageMetrics(api.meterProvider.Meter(instrumentationName))
+	}
+	api.usage = newUsageTracker(api.prices, api.usageRecorder, metrics)
+	api.tracer = api.tracerProvider.Tracer(instrumentationName)
+
+	api.requestRate = rate.NewLimiter(rate.Inf, 0)
+	if api.requestsPerMinute > 0 {
+		api.requestRate = rate.NewLimiter(rate.Limit(float64(api.requestsPerMinute)/60), api.requestsPerMinute)
+	}
+
 	// create the remote openai configuration
 	cfg := openai.DefaultConfig(config.Token)
-	cfg.HTTPClient = api.httpClient
+	cfg.HTTPClient = retryAfterDoer{client: api.httpClient}
 	if config.Organization != "" {
 		cfg.OrgID = config.Organization
 	}
 
+	if config.BaseURL != "" {
+		cfg.BaseURL = config.BaseURL
+	}
+
 	api.remote = openai.NewClientWithConfig(cfg)
 	return api
 }
@@ -230,26 +337,248 @@ func NewAPI(config *Config, opts ...Option) API {
 //	    fmt.Println("Response:", response.Content)
 //	}
 func (x api) Query(ctx context.Context, requests []*Request, responseType ResponseType) (responses []*Response, err error) {
+	if x.cache == nil {
+		return x.query(ctx, requests, responseType)
+	}
+
+	// the cache errors do not fail the queries
+	key := middleware.PromptKey(requests, responseType, x.cacheParams())
+	if responses, found, err := x.cache.Get(ctx, key); err == nil && found {
+		return responses, nil
+	}
+
+	responses, err = x.query(ctx, requests, responseType)
+	if err != nil {
+		return nil, err
+	}
+
+	_ = x.cache.Set(ctx, key, responses)
+	return responses, nil
+}
+
+// query sends the messages to OpenAI APIs and returns the responses
+func (x api) query(ctx context.Context, requests []*Request, responseType ResponseType) (responses []*Response, err error) {
+	req, err := x.chatRequest(ctx, requests, responseType)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(x.tools) > 0 {
+		req.Tools = toOpenAITools(x.tools)
+	}
+
+	resp, err := x.complete(ctx, OperationQuery, req)
+	if err != nil {
+		return nil, err
+	}
+
+	responses = make([]*Response, len(resp.Choices))
+	for i, choice := range resp.Choices {
+		responses[i] = &Response{
+			Content:          choice.Message.Content,
+			ToolCalls:        fromOpenAIToolCalls(choice.Message.ToolCalls),
+			PromptTokens:     resp.Usage.PromptTokens,
+			CompletionTokens: resp.Usage.CompletionTokens,
+			TotalTokens:      resp.Usage.TotalTokens,
+		}
+	}
+
+	return responses, nil
+}
+
+// QueryStream sends messages to OpenAI APIs and streams the response as it is generated.
+//
+// The returned channel receives the chunks of content of every choice followed by a final delta
+// holding either the token usage of the query or the error that ended the stream. The channel is
+// closed once the stream is over. The caller must drain the channel or cancel ctx.
+//
+// Only the opening of the stream is retried. The Timeout of the Config does not apply to the stream
+// which is bounded by ctx, so that long generations are not cut short.
+//
+// Example:
+//
+//	deltas, err := api.QueryStream(ctx, []*Request{{Type: UserMessage, Content: "Hello, OpenAI!"}}, TextResponseType)
+//	if err != nil {
+//	    log.Fatalf("Query failed: %v", err)
+//	}
+//
+//	for delta := range deltas {
+//	    if delta.Err != nil {
+//	        log.Fatalf("Stream failed: %v", delta.Err)
+//	    }
+//	    fmt.Print(delta.Content)
+//	}
+func (x api) QueryStream(ctx context.Context, requests []*Request, responseType ResponseType) (deltas <-chan *StreamDelta, err error) {
+	req, err := x.chatRequest(ctx, requests, responseType)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Stream = true
+	req.StreamOptions = &openai.StreamOptions{IncludeUsage: true}
+
+	startedAt := x.clock.Now()
+	var stream *openai.ChatCompletionStream
+	// wrap in a function so we can backoff
+	operation := func(ctx context.Context) error {
+		var err error
+		stream, err = x.remote.CreateChatCompletionStream(ctx, req)
+		return err
+	}
+
+	// implements backoff
+	if err := x.retry(ctx, operation); err != nil {
+		x.recordUsage(ctx, OperationStream, req.Model, "", openai.Usage{}, x.clock.Since(startedAt), err)
+		return nil, err
+	}
+
+	out := make(chan *StreamDelta, 16)
+	go func() {
+		defer close(out)
+		defer stream.Close()
+		usage, err := pump(ctx, stream, out)
+		x.recordUsage(ctx, OperationStream, req.Model, "", toOpenAIUsage(usage), x.clock.Since(startedAt), err)
+	}()
+	return out, nil
+}
+
+// cacheParams returns the settings of the queries that are part of the cache keys
+func (x api) cacheParams() any {
+	tools := make([]string, len(x.tools))
+	for i, tool := range x.tools {
+		tools[i] = tool.Name()
+	}
+
+	return struct {
+		Model       string   `json:"model"`
+		Temperature float32  `json:"temperature"`
+		Frequency   float32  `json:"frequency"`
+		Presence    float32  `json:"presence"`
+		MaxTokens   int      `json:"max_tokens"`
+		Tools       []string `json:"tools"`
+	}{
+		Model:       x.config.Model,
+		Temperature: x.temperature,
+		Frequency:   x.frequency,
+		Presence:    x.presence,
+		MaxTokens:   x.maxTokens,
+		Tools:       tools,
+	}
+}
+
+// complete sends the chat completion request and returns its response which has at least a choice.
+// The usage of the call is recorded under the given operation and the call is traced with a span.
+func (x api) complete(ctx context.Context, operation string, req openai.ChatCompletionRequest) (resp openai.ChatCompletionResponse, err error) {
+	ctx, span := x.startSpan(ctx, operation, req)
+	startedAt := x.clock.Now()
+	attempts := 0
+	defer func() {
+		x.recordUsage(ctx, operation, req.Model, resp.Model, resp.Usage, x.clock.Since(startedAt), err)
+		endSpan(span, resp, attempts, err)
+	}()
+
+	// wrap in a function so we can backoff
+	call := func(ctx context.Context) error {
+		attempts++
+		ctx, cancel := context.WithTimeout(ctx, x.config.Timeout)
+		defer cancel()
+		var err error
+		resp, err = x.remote.CreateChatCompletion(ctx, req)
+		return err
+	}
+
+	// implements backoff
+	if err := x.retry(ctx, call); err != nil {
+		return openai.ChatCompletionResponse{}, err
+	}
+
+	// when we have no choices
+	if len(resp.Choices) == 0 {
+		return resp, errors.New("malformed llm response from openai")
+	}
+	return resp, nil
+}
+
+// Usage returns the usage of the calls made so far
+func (x api) Usage() UsageSummary {
+	return x.usage.snapshot()
+}
+
+// CountTokens returns the number of prompt tokens of the messages
+func (x api) CountTokens(requests []*Request) (int, error) {
+	_, tokens, err := x.toMessages(requests)
+	return tokens, err
+}
+
+// toMessages converts the messages to openai chat completion messages and counts their tokens
+func (x api) toMessages(requests []*Request) ([]openai.ChatCompletionMessage, int, error) {
 	msgs := make([]openai.ChatCompletionMessage, 0, len(requests))
 	for _, message := range requests {
 		msg, err := toChatCompletionMessage(message)
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 		msgs = append(msgs, msg)
 	}
 
-	tokens, err := tokensCount(msgs, x.config.Model)
+	enc, err := x.encoder(x.config.Model)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
+	}
+
+	tokens, err := tokensCount(enc, msgs, x.config.Model)
+	if err != nil {
+		return nil, 0, err
 	}
+	return msgs, tokens, nil
+}
 
-	// estimating 100 tokens of response
-	// TODO: make this configurable
-	tokens += 100
+// recordUsage records the usage of a call. The model that served the call is preferred to the requested one.
+func (x api) recordUsage(ctx context.Context, operation, requested, served string, usage openai.Usage, latency time.Duration, err error) {
+	model := served
+	if model == "" {
+		model = requested
+	}
 
-	if err := x.rateLimit.WaitN(ctx, tokens); err != nil {
-		return nil, err
+	x.usage.record(ctx, &UsageRecord{
+		Operation:        operation,
+		Model:            model,
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		TotalTokens:      usage.TotalTokens,
+		Latency:          latency,
+		Err:              err,
+	})
+}
+
+// wait blocks until the rate limiters allow a request of the given number of tokens
+func (x api) wait(ctx context.Context, tokens int) error {
+	if err := x.requestRate.Wait(ctx); err != nil {
+		return err
+	}
+	return x.rateLimit.WaitN(ctx, tokens)
+}
+
+// estimatedResponseTokens returns the number of tokens of the response to account for in the rate limiter
+func (x api) estimatedResponseTokens(defaultTokens int) int {
+	if x.responseTokens > 0 {
+		return x.responseTokens
+	}
+	return defaultTokens
+}
+
+// chatRequest converts the messages to a chat completion request and waits for the rate limiter
+func (x api) chatRequest(ctx context.Context, requests []*Request, responseType ResponseType) (openai.ChatCompletionRequest, error) {
+	msgs, tokens, err := x.toMessages(requests)
+	if err != nil {
+		return openai.ChatCompletionRequest{}, err
+	}
+
+	// estimate the tokens of the response
+	tokens += x.estimatedResponseTokens(DefaultResponseTokens)
+
+	if err := x.wait(ctx, tokens); err != nil {
+		return openai.ChatCompletionRequest{}, err
 	}
 
 	// create request
@@ -259,6 +588,7 @@ func (x api) Query(ctx context.Context, requests []*Request, responseType Respon
 		Temperature:      x.temperature,
 		PresencePenalty:  x.presence,
 		FrequencyPenalty: x.frequency,
+		MaxTokens:        x.maxTokens,
 	}
 
 	switch {
@@ -271,61 +601,7 @@ func (x api) Query(ctx context.Context, requests []*Request, responseType Respon
 			Type: openai.ChatCompletionResponseFormatTypeText,
 		}
 	}
-
-	var resp openai.ChatCompletionResponse
-	// wrap in a function so we can backoff
-	operation := func() error {
-		ctx, cancel := context.WithTimeout(ctx, x.config.Timeout)
-		var err error
-		resp, err = x.remote.CreateChatCompletion(ctx, req)
-		defer cancel()
-		if err != nil {
-			e := &openai.APIError{}
-			switch {
-			case errors.As(err, &e):
-				switch e.HTTPStatusCode {
-				case http.StatusUnauthorized:
-					// invalid auth or key (do not retry)
-					return &backoff.PermanentError{Err: err}
-				case http.StatusTooManyRequests:
-					// rate limiting or engine overload (wait and retry)
-					return err
-				case http.StatusInternalServerError:
-					// openai server error (retry)
-					return err
-				default:
-					// return &backoff.PermanentError{Err: err}
-					return err
-				}
-			default:
-				return err
-			}
-		}
-		return nil
-	}
-
-	// implements backoff
-	opt := backoff.WithMaxRetries(backoff.NewExponentialBackOff(), uint64(x.config.MaxRetries))
-	if err := backoff.Retry(operation, opt); err != nil {
-		return nil, err
-	}
-
-	// when we have no choices
-	if len(resp.Choices) == 0 {
-		return nil, errors.New("malformed llm response from openai")
-	}
-
-	responses = make([]*Response, len(resp.Choices))
-	for i, choice := range resp.Choices {
-		responses[i] = &Response{
-			Content:          choice.Message.Content,
-			PromptTokens:     resp.Usage.PromptTokens,
-			CompletionTokens: resp.Usage.CompletionTokens,
-			TotalTokens:      resp.Usage.TotalTokens,
-		}
-	}
-
-	return responses, nil
+	return req, nil
 }
 
 // VisionQuery sends image query requests to OpenAI and retrieves responses.
@@ -374,17 +650,28 @@ func (x api) VisionQuery(ctx context.Context, requests ...*VisionRequest) (respo
 		return nil, err
 	}
 
-	tokens, err := tokensCount(convertedMessages, x.config.Model)
+	enc, err := x.encoder(x.config.Model)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := tokensCount(enc, convertedMessages, x.config.Model)
 	if err != nil {
 		return nil, err
 	}
 
-	// estimating 100 tokens of response
-	tokens += 400
-	if err := x.rateLimit.WaitN(ctx, tokens); err != nil {
+	// estimate the tokens of the response
+	tokens += x.estimatedResponseTokens(DefaultVisionResponseTokens)
+	if err := x.wait(ctx, tokens); err != nil {
 		return nil, err
 	}
 
+	// the context size minus the estimated amount unless a maximum is set
+	maxTokens := visionContextTokens - tokens
+	if x.maxTokens > 0 {
+		maxTokens = x.maxTokens
+	}
+
 	// random seed
 	seed := 8006
 	// create request
@@ -394,55 +681,15 @@ func (x api) VisionQuery(ctx context.Context, requests ...*VisionRequest) (respo
 		Temperature:      x.temperature,
 		PresencePenalty:  x.presence,
 		FrequencyPenalty: x.frequency,
-		// 4096 is the max tokens so take that minus the estimated amount
-		MaxTokens: 4096 - tokens,
-		Seed:      &seed,
-	}
-
-	var resp openai.ChatCompletionResponse
-	// wrap in a function so we can backoff
-	operation := func() error {
-		ctx, cancel := context.WithTimeout(ctx, x.config.Timeout)
-		var err error
-		resp, err = x.remote.CreateChatCompletion(ctx, req)
-		cancel()
-		if err != nil {
-			e := &openai.APIError{}
-			if errors.As(err, &e) {
-				switch e.HTTPStatusCode {
-				case http.StatusUnauthorized:
-					// invalid auth or key (do not retry)
-					return &backoff.PermanentError{Err: err}
-				case http.StatusTooManyRequests:
-					// rate limiting or engine overload (wait and retry)
-					return err
-				case http.StatusInternalServerError:
-					// openai server error (retry)
-					return err
-				default:
-					// return &backoff.PermanentError{Err: err}
-					return err
-				}
-			} else {
-				// it means this is not an openai error
-				// return &backoff.PermanentError{Err: err}
-				return err
-			}
-		}
-		return nil
+		MaxTokens:        maxTokens,
+		Seed:             &seed,
 	}
 
-	// implements backoff
-	opt := backoff.WithMaxRetries(backoff.NewExponentialBackOff(), uint64(x.config.MaxRetries))
-	if err := backoff.Retry(operation, opt); err != nil {
+	resp, err := x.complete(ctx, OperationVision, req)
+	if err != nil {
 		return nil, err
 	}
 
-	// when we have no choices
-	if len(resp.Choices) == 0 {
-		return nil, errors.New("malformed llm response from openai")
-	}
-
 	responses = make([]*Response, len(resp.Choices))
 	for i, choice := range resp.Choices {
 		responses[i] = &Response{
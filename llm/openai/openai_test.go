@@ -0,0 +1,176 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package openai
+
+import (
+	"context"
+	"image"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+
+	"github.com/tochemey/gopack/llm"
+	"github.com/tochemey/gopack/llm/middleware"
+)
+
+func TestRateLimits(t *testing.T) {
+	t.Run("with defaults", func(t *testing.T) {
+		x := NewAPI(&Config{Token: "secret"}).(*api)
+		assert.Equal(t, rate.Limit(float64(DefaultTokensPerMinute)/60), x.rateLimit.Limit())
+		assert.Equal(t, DefaultTokensPerMinute, x.rateLimit.Burst())
+		assert.Equal(t, rate.Inf, x.requestRate.Limit())
+		assert.Equal(t, DefaultResponseTokens, x.estimatedResponseTokens(DefaultResponseTokens))
+		assert.Zero(t, x.maxTokens)
+	})
+	t.Run("with options", func(t *testing.T) {
+		x := NewAPI(&Config{Token: "secret"},
+			WithTokensPerMinute(6000),
+			WithRequestsPerMinute(120),
+			WithResponseTokens(250),
+			WithMaxTokens(1024),
+		).(*api)
+		assert.Equal(t, rate.Limit(100), x.rateLimit.Limit())
+		assert.Equal(t, 6000, x.rateLimit.Burst())
+		assert.Equal(t, rate.Limit(2), x.requestRate.Limit())
+		assert.Equal(t, 120, x.requestRate.Burst())
+		assert.Equal(t, 250, x.estimatedResponseTokens(DefaultVisionResponseTokens))
+		assert.Equal(t, 1024, x.maxTokens)
+	})
+	t.Run("with custom rate limiter", func(t *testing.T) {
+		limiter := rate.NewLimiter(10, 10)
+		x := NewAPI(&Config{Token: "secret"}, WithRateLimiter(limiter), WithTokensPerMinute(6000)).(*api)
+		assert.Same(t, limiter, x.rateLimit)
+	})
+	t.Run("with requests per minute", func(t *testing.T) {
+		requests := new(atomic.Int32)
+		server := newEmbeddingsServer(t, requests, DefaultEmbeddingModel, func(string) []float32 {
+			return []float32{1}
+		})
+
+		x := newTestAPI(server, WithRequestsPerMinute(1))
+		_, err := x.Embeddings(context.Background(), []string{"a"})
+		require.NoError(t, err)
+
+		// the next request is allowed in a minute
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		_, err = x.Embeddings(ctx, []string{"b"})
+		assert.Error(t, err)
+		assert.EqualValues(t, 1, requests.Load())
+	})
+	t.Run("with tokens per minute", func(t *testing.T) {
+		requests := new(atomic.Int32)
+		server := newEmbeddingsServer(t, requests, DefaultEmbeddingModel, func(string) []float32 {
+			return []float32{1}
+		})
+
+		x := newTestAPI(server, WithTokensPerMinute(10))
+		_, err := x.Embeddings(context.Background(), []string{"abcdefgh"})
+		require.NoError(t, err)
+
+		// the tokens are replenished at 10 per minute
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		_, err = x.Embeddings(ctx, []string{"abcdefgh"})
+		assert.Error(t, err)
+		assert.EqualValues(t, 1, requests.Load())
+	})
+}
+
+func TestQueryCache(t *testing.T) {
+	requests := []*Request{{Type: UserMessage, Content: "hello"}}
+	cache := middleware.NewMemoryCache(10, time.Minute)
+
+	server, received := newChatServer(t, openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: "hi"})
+	x := newTestAPI(server, WithCache(cache))
+
+	for range 3 {
+		responses, err := x.Query(context.Background(), requests, TextResponseType)
+		require.NoError(t, err)
+		require.Len(t, responses, 1)
+		assert.Equal(t, "hi", responses[0].Content)
+	}
+	assert.Len(t, received(), 1)
+	assert.EqualValues(t, 1, x.Usage().Calls)
+
+	// the settings of the client are part of the key
+	other := newTestAPI(server, WithCache(cache), WithTemperature(0.7))
+	_, err := other.Query(context.Background(), requests, TextResponseType)
+	require.NoError(t, err)
+	assert.Len(t, received(), 2)
+	assert.Equal(t, 2, cache.Len())
+}
+
+func TestTransformImageRequests(t *testing.T) {
+	messages, err := transformImageRequests([]*VisionRequest{
+		{Content: "compare"},
+		{ImageURL: "https://example.com/cat.png", Detail: llm.ImageDetailLow},
+		{Content: "with"},
+		{ImageData: []byte("RIFF"), MediaType: "image/webp", Detail: llm.ImageDetailHigh},
+		{Image: image.NewRGBA(image.Rect(0, 0, 4, 4))},
+	})
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+
+	parts := messages[0].MultiContent
+	require.Len(t, parts, 5)
+	assert.Equal(t, openai.ChatMessagePart{Type: openai.ChatMessagePartTypeText, Text: "compare"}, parts[0])
+	assert.Equal(t, &openai.ChatMessageImageURL{URL: "https://example.com/cat.png", Detail: openai.ImageURLDetailLow}, parts[1].ImageURL)
+	assert.Equal(t, "with", parts[2].Text)
+	assert.Equal(t, &openai.ChatMessageImageURL{URL: "data:image/webp;base64,UklGRg==", Detail: openai.ImageURLDetailHigh}, parts[3].ImageURL)
+	assert.True(t, strings.HasPrefix(parts[4].ImageURL.URL, "data:image/jpeg;base64,"))
+	assert.Empty(t, parts[4].ImageURL.Detail)
+
+	_, err = transformImageRequests([]*VisionRequest{{ImageData: []byte("not an image")}})
+	assert.Error(t, err)
+}
+
+func TestCountTokens(t *testing.T) {
+	x := newTestAPI(httptest.NewServer(http.NotFoundHandler()))
+	var counter llm.TokenCounter = x
+
+	// 3 tokens of reply priming plus 3 tokens of framing, the role and the content of every message
+	tokens, err := counter.CountTokens([]*Request{{Type: UserMessage, Content: "hello"}})
+	require.NoError(t, err)
+	assert.Equal(t, 3+3+len("user")+len("hello"), tokens)
+
+	conversation := llm.NewConversation(x, llm.WithContextWindow(40), llm.WithReservedTokens(0))
+	conversation.Add(
+		&Request{Type: UserMessage, Content: "hello"},
+		&Request{Type: AssistantMessage, Content: "hello"},
+		&Request{Type: UserMessage, Content: "how are you?"},
+	)
+	messages, err := conversation.Fit(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []*Request{{Type: UserMessage, Content: "how are you?"}}, messages)
+}
@@ -24,7 +24,18 @@
 
 package openai
 
-import "net/http"
+import (
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/time/rate"
+
+	"github.com/tochemey/gopack/clock"
+	"github.com/tochemey/gopack/llm"
+	"github.com/tochemey/gopack/llm/middleware"
+)
 
 // Option is the interface that applies a configuration option.
 type Option interface {
@@ -68,3 +79,163 @@ func WithHTTPClient(httpClient *http.Client) Option {
 		c.httpClient = httpClient
 	})
 }
+
+// WithRetryInitialInterval sets the delay before the first retry of a failed call
+func WithRetryInitialInterval(interval time.Duration) Option {
+	return OptionFunc(func(c *api) {
+		c.retryPolicy.initialInterval = interval
+	})
+}
+
+// WithRetryMaxInterval caps the delay between two retries, before the jitter is applied
+func WithRetryMaxInterval(interval time.Duration) Option {
+	return OptionFunc(func(c *api) {
+		c.retryPolicy.maxInterval = interval
+	})
+}
+
+// WithRetryMaxElapsedTime sets the time after which a failed call is not retried anymore.
+// Zero retries until the MaxRetries of the Config are reached.
+func WithRetryMaxElapsedTime(elapsed time.Duration) Option {
+	return OptionFunc(func(c *api) {
+		c.retryPolicy.maxElapsedTime = elapsed
+	})
+}
+
+// WithRetryJitter sets the randomization factor of the delays between retries.
+// A delay d becomes a random delay in [d - jitter * d, d + jitter * d]. Zero disables the jitter.
+func WithRetryJitter(jitter float64) Option {
+	return OptionFunc(func(c *api) {
+		c.retryPolicy.jitter = jitter
+	})
+}
+
+// WithRetryPredicate sets the predicate telling which failed calls are retried. See RetryOnStatus.
+func WithRetryPredicate(predicate RetryPredicate) Option {
+	return OptionFunc(func(c *api) {
+		c.retryPolicy.predicate = predicate
+	})
+}
+
+// WithClock sets the clock used to wait between retries
+func WithClock(clock clock.Clock) Option {
+	return OptionFunc(func(c *api) {
+		c.clock = clock
+	})
+}
+
+// WithTools declares the tools the model can call in Query and QueryWithTools
+func WithTools(tools ...llm.Tool) Option {
+	return OptionFunc(func(c *api) {
+		c.tools = append(c.tools, tools...)
+	})
+}
+
+// WithTokensPerMinute sets the number of tokens per minute allowed by the rate limiter.
+// The default is DefaultTokensPerMinute. It is ignored when a rate limiter is set with WithRateLimiter.
+func WithTokensPerMinute(tpm int) Option {
+	return OptionFunc(func(c *api) {
+		if tpm > 0 {
+			c.tokensPerMinute = tpm
+		}
+	})
+}
+
+// WithRequestsPerMinute sets the number of requests per minute sent to the OpenAI apis.
+// The requests are not limited by default.
+func WithRequestsPerMinute(rpm int) Option {
+	return OptionFunc(func(c *api) {
+		c.requestsPerMinute = rpm
+	})
+}
+
+// WithRateLimiter sets a custom tokens rate limiter. Every query waits for its estimated
+// number of tokens, prompt and response, to be available.
+func WithRateLimiter(limiter *rate.Limiter) Option {
+	return OptionFunc(func(c *api) {
+		c.rateLimit = limiter
+	})
+}
+
+// WithResponseTokens sets the number of response tokens estimated per query by the rate limiter.
+// The defaults are DefaultResponseTokens and DefaultVisionResponseTokens for the vision queries.
+func WithResponseTokens(tokens int) Option {
+	return OptionFunc(func(c *api) {
+		c.responseTokens = tokens
+	})
+}
+
+// WithMaxTokens sets the maximum number of tokens generated per query.
+// By default the queries are not limited and the vision queries are limited to
+// their 4096 tokens context minus the estimated prompt and response tokens.
+func WithMaxTokens(tokens int) Option {
+	return OptionFunc(func(c *api) {
+		c.maxTokens = tokens
+	})
+}
+
+// WithUsageRecorder sets the recorder capturing the usage of every call
+func WithUsageRecorder(recorder UsageRecorder) Option {
+	return OptionFunc(func(c *api) {
+		c.usageRecorder = recorder
+	})
+}
+
+// WithPriceTable sets the prices used to compute the cost of the calls. The default is DefaultPriceTable.
+func WithPriceTable(prices PriceTable) Option {
+	return OptionFunc(func(c *api) {
+		c.prices = prices
+	})
+}
+
+// WithMeterProvider exports the usage of the calls as otel metrics with the given provider
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return OptionFunc(func(c *api) {
+		c.meterProvider = provider
+	})
+}
+
+// WithTracerProvider sets the tracer provider creating the spans of the chat completions.
+// The default is the global tracer provider.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return OptionFunc(func(c *api) {
+		c.tracerProvider = provider
+	})
+}
+
+// WithCache sets the store caching the responses of Query. The repeated queries, same model, messages and
+// settings, are answered from the store without calling the OpenAI apis. See middleware.NewMemoryCache
+// and middleware.NewRedisCache.
+func WithCache(store middleware.CacheStore) Option {
+	return OptionFunc(func(c *api) {
+		c.cache = store
+	})
+}
+
+// WithEmbeddingBatchSize sets the maximum number of inputs sent per embeddings request.
+// The default is DefaultEmbeddingBatchSize.
+func WithEmbeddingBatchSize(size int) Option {
+	return OptionFunc(func(c *api) {
+		if size > 0 {
+			c.embeddingBatchSize = size
+		}
+	})
+}
+
+// WithMaxSchemaRetries sets the number of times QuerySchema and QueryStructured send an invalid response
+// back to the model to be fixed. The default is DefaultMaxSchemaRetries and zero disables the retries.
+func WithMaxSchemaRetries(retries int) Option {
+	return OptionFunc(func(c *api) {
+		if retries >= 0 {
+			c.maxSchemaRetries = retries
+		}
+	})
+}
+
+// WithMaxToolIterations sets the maximum number of round trips QueryWithTools makes with the model
+// before giving up on a final answer. The default is DefaultMaxToolIterations.
+func WithMaxToolIterations(iterations int) Option {
+	return OptionFunc(func(c *api) {
+		c.maxToolIterations = iterations
+	})
+}
@@ -0,0 +1,202 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package openai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/sashabaranov/go-openai"
+
+	"github.com/tochemey/gopack/clock"
+)
+
+// the default retry policy, the one of the exponential backoff
+const (
+	DefaultRetryInitialInterval = backoff.DefaultInitialInterval
+	DefaultRetryMaxInterval     = backoff.DefaultMaxInterval
+	DefaultRetryMaxElapsedTime  = backoff.DefaultMaxElapsedTime
+	DefaultRetryJitter          = backoff.DefaultRandomizationFactor
+)
+
+// RetryPredicate tells whether a failed call is worth retrying. The statusCode is the HTTP status code of the
+// response, zero when the call failed before getting one.
+type RetryPredicate func(statusCode int, err error) bool
+
+// DefaultRetryPredicate retries every failure but the authentication ones
+func DefaultRetryPredicate(statusCode int, _ error) bool {
+	return statusCode != http.StatusUnauthorized
+}
+
+// RetryOnStatus returns a RetryPredicate retrying the calls failing without a response and the ones failing
+// with one of the given status codes
+func RetryOnStatus(statusCodes ...int) RetryPredicate {
+	return func(statusCode int, _ error) bool {
+		return statusCode == 0 || slices.Contains(statusCodes, statusCode)
+	}
+}
+
+// retryPolicy holds the settings of the retries of the calls
+type retryPolicy struct {
+	initialInterval time.Duration
+	maxInterval     time.Duration
+	maxElapsedTime  time.Duration
+	jitter          float64
+	predicate       RetryPredicate
+}
+
+// defaultRetryPolicy returns the default retry policy
+func defaultRetryPolicy() retryPolicy {
+	return retryPolicy{
+		initialInterval: DefaultRetryInitialInterval,
+		maxInterval:     DefaultRetryMaxInterval,
+		maxElapsedTime:  DefaultRetryMaxElapsedTime,
+		jitter:          DefaultRetryJitter,
+		predicate:       DefaultRetryPredicate,
+	}
+}
+
+// retry runs the operation until it succeeds, fails with an error the retry predicate rejects, the context is done
+// or the retry policy gives up. The rate limited calls are retried after the delay of their Retry-After header.
+func (x api) retry(ctx context.Context, operation func(ctx context.Context) error) error {
+	exponential := backoff.NewExponentialBackOff(
+		backoff.WithInitialInterval(x.retryPolicy.initialInterval),
+		backoff.WithMaxInterval(x.retryPolicy.maxInterval),
+		backoff.WithMaxElapsedTime(x.retryPolicy.maxElapsedTime),
+		backoff.WithRandomizationFactor(x.retryPolicy.jitter),
+		backoff.WithClockProvider(x.clock),
+	)
+
+	policy := &retryAfterBackOff{
+		BackOff: backoff.WithMaxRetries(exponential, uint64(x.config.MaxRetries)),
+		clock:   x.clock,
+	}
+
+	attempt := func() error {
+		hint := new(retryAfter)
+		err := operation(context.WithValue(ctx, retryAfterKey{}, hint))
+		policy.header = hint.header
+		return x.toBackoffError(ctx, err)
+	}
+	return backoff.RetryNotifyWithTimer(attempt, policy, nil, &backoffTimer{clock: x.clock})
+}
+
+// toBackoffError marks the errors that must not be retried as permanent
+func (x api) toBackoffError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	// the caller gave up
+	if ctx.Err() != nil {
+		return backoff.Permanent(err)
+	}
+
+	if !x.retryPolicy.predicate(statusCode(err), err) {
+		return backoff.Permanent(err)
+	}
+	return err
+}
+
+// statusCode returns the HTTP status code of a failed call, zero when the call failed without a response
+func statusCode(err error) int {
+	apiErr := &openai.APIError{}
+	if errors.As(err, &apiErr) {
+		return apiErr.HTTPStatusCode
+	}
+
+	requestErr := &openai.RequestError{}
+	if errors.As(err, &requestErr) {
+		return requestErr.HTTPStatusCode
+	}
+	return 0
+}
+
+// retryAfterKey is the context key of the retryAfter of an attempt
+type retryAfterKey struct{}
+
+// retryAfter holds the Retry-After header of a rate limited attempt
+type retryAfter struct {
+	header string
+}
+
+// retryAfterDoer is the HTTP client of the remote calls. It keeps the Retry-After header of the rate limited
+// responses in the retryAfter of the request context.
+type retryAfterDoer struct {
+	client *http.Client
+}
+
+func (d retryAfterDoer) Do(req *http.Request) (*http.Response, error) {
+	resp, err := d.client.Do(req)
+	if err != nil || resp.StatusCode != http.StatusTooManyRequests {
+		return resp, err
+	}
+
+	if hint, ok := req.Context().Value(retryAfterKey{}).(*retryAfter); ok {
+		hint.header = resp.Header.Get("Retry-After")
+	}
+	return resp, nil
+}
+
+// retryAfterBackOff waits for the delay of the Retry-After header of the last attempt, when set,
+// instead of the delay of the wrapped backoff which still decides when to stop
+type retryAfterBackOff struct {
+	backoff.BackOff
+	clock  clock.Clock
+	header string
+}
+
+func (b *retryAfterBackOff) NextBackOff() time.Duration {
+	next := b.BackOff.NextBackOff()
+	if next == backoff.Stop {
+		return next
+	}
+
+	if delay, ok := parseRetryAfter(b.header, b.clock.Now()); ok {
+		return delay
+	}
+	return next
+}
+
+// parseRetryAfter parses a Retry-After header holding either a number of seconds or an HTTP date
+func parseRetryAfter(header string, now time.Time) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0), true
+	}
+
+	if date, err := http.ParseTime(header); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+	return 0, false
+}
@@ -0,0 +1,150 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tochemey/gopack/clock"
+)
+
+// newRetryServer returns a server failing the first call with the given status code and headers
+func newRetryServer(calls *atomic.Int32, statusCode int, headers map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if calls.Add(1) == 1 {
+			for key, value := range headers {
+				w.Header().Set(key, value)
+			}
+			w.WriteHeader(statusCode)
+			_, _ = w.Write([]byte(`{"error":{"message":"failed","type":"server_error"}}`))
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(openai.ChatCompletionResponse{
+			Choices: []openai.ChatCompletionChoice{{
+				Message:      openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: "hi"},
+				FinishReason: openai.FinishReasonStop,
+			}},
+		})
+	}))
+}
+
+func TestRetry(t *testing.T) {
+	t.Run("with defaults", func(t *testing.T) {
+		x := NewAPI(&Config{Token: "secret", Model: "gpt-4o"}).(*api)
+		assert.Equal(t, DefaultRetryInitialInterval, x.retryPolicy.initialInterval)
+		assert.Equal(t, DefaultRetryMaxInterval, x.retryPolicy.maxInterval)
+		assert.Equal(t, DefaultRetryMaxElapsedTime, x.retryPolicy.maxElapsedTime)
+		assert.Equal(t, DefaultRetryJitter, x.retryPolicy.jitter)
+		assert.True(t, x.retryPolicy.predicate(http.StatusTooManyRequests, nil))
+		assert.False(t, x.retryPolicy.predicate(http.StatusUnauthorized, nil))
+	})
+	t.Run("with options", func(t *testing.T) {
+		x := NewAPI(&Config{Token: "secret", Model: "gpt-4o"},
+			WithRetryInitialInterval(time.Second),
+			WithRetryMaxInterval(time.Minute),
+			WithRetryMaxElapsedTime(time.Hour),
+			WithRetryJitter(0),
+			WithRetryPredicate(RetryOnStatus(http.StatusTooManyRequests)),
+		).(*api)
+		assert.Equal(t, time.Second, x.retryPolicy.initialInterval)
+		assert.Equal(t, time.Minute, x.retryPolicy.maxInterval)
+		assert.Equal(t, time.Hour, x.retryPolicy.maxElapsedTime)
+		assert.Zero(t, x.retryPolicy.jitter)
+		assert.True(t, x.retryPolicy.predicate(http.StatusTooManyRequests, nil))
+		assert.True(t, x.retryPolicy.predicate(0, nil))
+		assert.False(t, x.retryPolicy.predicate(http.StatusInternalServerError, nil))
+	})
+	t.Run("with a rejected status code", func(t *testing.T) {
+		calls := new(atomic.Int32)
+		server := newRetryServer(calls, http.StatusInternalServerError, nil)
+		defer server.Close()
+
+		x := newTestAPI(server, WithRetryPredicate(RetryOnStatus(http.StatusTooManyRequests)))
+		x.config.MaxRetries = 3
+
+		_, err := x.Query(context.Background(), []*Request{{Type: UserMessage, Content: "hello"}}, TextResponseType)
+		require.Error(t, err)
+		assert.EqualValues(t, 1, calls.Load())
+	})
+	t.Run("with a Retry-After header", func(t *testing.T) {
+		calls := new(atomic.Int32)
+		server := newRetryServer(calls, http.StatusTooManyRequests, map[string]string{"Retry-After": "30"})
+		defer server.Close()
+
+		fake := clock.NewFake(time.Now())
+		x := newTestAPI(server, WithClock(fake), WithRetryInitialInterval(time.Millisecond), WithRetryJitter(0))
+		x.config.MaxRetries = 1
+
+		done := make(chan error, 1)
+		go func() {
+			_, err := x.Query(context.Background(), []*Request{{Type: UserMessage, Content: "hello"}}, TextResponseType)
+			done <- err
+		}()
+
+		require.Eventually(t, func() bool { return fake.Waiters() == 1 }, time.Second, time.Millisecond)
+		// the delay of the exponential backoff is not enough
+		fake.Advance(time.Second)
+		assert.EqualValues(t, 1, calls.Load())
+		assert.Equal(t, 1, fake.Waiters())
+
+		fake.Advance(29 * time.Second)
+		require.NoError(t, <-done)
+		assert.EqualValues(t, 2, calls.Load())
+	})
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	delay, ok := parseRetryAfter("12", now)
+	require.True(t, ok)
+	assert.Equal(t, 12*time.Second, delay)
+
+	delay, ok = parseRetryAfter(now.Add(time.Minute).Format(http.TimeFormat), now)
+	require.True(t, ok)
+	assert.Equal(t, time.Minute, delay)
+
+	delay, ok = parseRetryAfter(now.Add(-time.Minute).Format(http.TimeFormat), now)
+	require.True(t, ok)
+	assert.Zero(t, delay)
+
+	_, ok = parseRetryAfter("", now)
+	assert.False(t, ok)
+
+	_, ok = parseRetryAfter("soon", now)
+	assert.False(t, ok)
+}
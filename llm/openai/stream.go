@@ -0,0 +1,103 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package openai
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// toOpenAIUsage converts the usage of a stream
+func toOpenAIUsage(usage *Usage) openai.Usage {
+	if usage == nil {
+		return openai.Usage{}
+	}
+	return openai.Usage{
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		TotalTokens:      usage.TotalTokens,
+	}
+}
+
+// streamReader reads the chunks of a chat completion stream
+type streamReader interface {
+	Recv() (openai.ChatCompletionStreamResponse, error)
+}
+
+// pump forwards the chunks read from the stream to out until the stream is over or ctx is done.
+// The last delta sent holds the token usage or the error that ended the stream, which are also returned.
+func pump(ctx context.Context, stream streamReader, out chan<- *StreamDelta) (*Usage, error) {
+	send := func(delta *StreamDelta) bool {
+		select {
+		case out <- delta:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	var usage *Usage
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			send(&StreamDelta{Usage: usage})
+			return usage, nil
+		}
+
+		if err != nil {
+			// report the cancellation rather than the transport error it caused
+			if ctx.Err() != nil {
+				err = ctx.Err()
+			}
+			send(&StreamDelta{Err: err})
+			return usage, err
+		}
+
+		if chunk.Usage != nil {
+			usage = &Usage{
+				PromptTokens:     chunk.Usage.PromptTokens,
+				CompletionTokens: chunk.Usage.CompletionTokens,
+				TotalTokens:      chunk.Usage.TotalTokens,
+			}
+		}
+
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content == "" && choice.FinishReason == "" {
+				continue
+			}
+
+			if !send(&StreamDelta{
+				Index:        choice.Index,
+				Content:      choice.Delta.Content,
+				FinishReason: string(choice.FinishReason),
+			}) {
+				return usage, ctx.Err()
+			}
+		}
+	}
+}
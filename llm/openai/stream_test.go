@@ -0,0 +1,120 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package openai
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStream replays the given chunks and then returns err
+type fakeStream struct {
+	chunks []openai.ChatCompletionStreamResponse
+	err    error
+}
+
+func (s *fakeStream) Recv() (openai.ChatCompletionStreamResponse, error) {
+	if len(s.chunks) == 0 {
+		return openai.ChatCompletionStreamResponse{}, s.err
+	}
+	chunk := s.chunks[0]
+	s.chunks = s.chunks[1:]
+	return chunk, nil
+}
+
+// contentChunk creates a chunk holding the given content of the first choice
+func contentChunk(content string, finishReason openai.FinishReason) openai.ChatCompletionStreamResponse {
+	return openai.ChatCompletionStreamResponse{
+		Choices: []openai.ChatCompletionStreamChoice{
+			{Delta: openai.ChatCompletionStreamChoiceDelta{Content: content}, FinishReason: finishReason},
+		},
+	}
+}
+
+// collect runs pump and returns the deltas sent
+func collect(ctx context.Context, stream streamReader) []*StreamDelta {
+	out := make(chan *StreamDelta, 16)
+	go func() {
+		defer close(out)
+		pump(ctx, stream, out)
+	}()
+
+	var deltas []*StreamDelta
+	for delta := range out {
+		deltas = append(deltas, delta)
+	}
+	return deltas
+}
+
+func TestPump(t *testing.T) {
+	t.Run("with completed stream", func(t *testing.T) {
+		stream := &fakeStream{
+			chunks: []openai.ChatCompletionStreamResponse{
+				contentChunk("Hello", ""),
+				contentChunk(", world", ""),
+				contentChunk("", openai.FinishReasonStop),
+				{Usage: &openai.Usage{PromptTokens: 10, CompletionTokens: 3, TotalTokens: 13}},
+			},
+			err: io.EOF,
+		}
+
+		deltas := collect(context.Background(), stream)
+		require.Len(t, deltas, 4)
+		assert.Equal(t, "Hello", deltas[0].Content)
+		assert.Equal(t, ", world", deltas[1].Content)
+		assert.Equal(t, "stop", deltas[2].FinishReason)
+		assert.NoError(t, deltas[3].Err)
+		assert.Equal(t, &Usage{PromptTokens: 10, CompletionTokens: 3, TotalTokens: 13}, deltas[3].Usage)
+	})
+	t.Run("with failed stream", func(t *testing.T) {
+		stream := &fakeStream{
+			chunks: []openai.ChatCompletionStreamResponse{contentChunk("Hello", "")},
+			err:    errors.New("connection reset"),
+		}
+
+		deltas := collect(context.Background(), stream)
+		require.Len(t, deltas, 2)
+		assert.Equal(t, "Hello", deltas[0].Content)
+		assert.EqualError(t, deltas[1].Err, "connection reset")
+		assert.Nil(t, deltas[1].Usage)
+	})
+	t.Run("with canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		stream := &fakeStream{err: errors.New("context canceled while reading")}
+		deltas := collect(ctx, stream)
+		// the final delta may be dropped since nobody is listening anymore
+		for _, delta := range deltas {
+			assert.ErrorIs(t, delta.Err, context.Canceled)
+		}
+	})
+}
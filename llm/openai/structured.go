@@ -0,0 +1,113 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/sashabaranov/go-openai"
+
+	"github.com/tochemey/gopack/llm"
+)
+
+// DefaultMaxSchemaRetries is the default number of times an invalid structured response
+// is sent back to the model to be fixed
+const DefaultMaxSchemaRetries = 2
+
+// correctivePrompt asks the model to fix its invalid structured response
+const correctivePrompt = "Your previous response is invalid: %v. " +
+	"Respond again with only a JSON document conforming to the %q JSON schema."
+
+// QueryStructured sends the messages to OpenAI asking for a response conforming to the JSON schema and
+// unmarshals it into T. When schema is nil the schema of T is used, see llm.SchemaOf.
+//
+// The response format is the structured outputs JSON schema. A response that does not conform to the
+// schema is sent back to the model along with a corrective system prompt up to the number of times
+// set with WithMaxSchemaRetries.
+func QueryStructured[T any](ctx context.Context, api API, requests []*Request, schema *llm.Schema) (T, error) {
+	var out T
+	if schema == nil {
+		schema = llm.SchemaOf[T]()
+	}
+
+	err := api.QuerySchema(ctx, requests, schema, &out)
+	return out, err
+}
+
+// QuerySchema sends the messages to OpenAI asking for a response conforming to the JSON schema,
+// validates the response and unmarshals it into out.
+func (x api) QuerySchema(ctx context.Context, requests []*Request, schema *llm.Schema, out any) error {
+	if schema == nil || schema.Definition == nil {
+		return errors.New("the json schema is not set")
+	}
+
+	conversation := append([]*Request(nil), requests...)
+	for attempt := 0; ; attempt++ {
+		req, err := x.chatRequest(ctx, conversation, JSONResponseType)
+		if err != nil {
+			return err
+		}
+
+		req.ResponseFormat = &openai.ChatCompletionResponseFormat{
+			Type: openai.ChatCompletionResponseFormatTypeJSONSchema,
+			JSONSchema: &openai.ChatCompletionResponseFormatJSONSchema{
+				Name:        schema.Name,
+				Description: schema.Description,
+				Schema:      schema.Definition,
+				Strict:      schema.Strict,
+			},
+		}
+
+		resp, err := x.complete(ctx, OperationStructured, req)
+		if err != nil {
+			return err
+		}
+
+		message := resp.Choices[0].Message
+		// the refusals are not worth retrying
+		if message.Refusal != "" {
+			return fmt.Errorf("openai refused to answer: %s", message.Refusal)
+		}
+
+		err = llm.ValidateJSON(schema.Definition, []byte(message.Content))
+		if err == nil {
+			if err = json.Unmarshal([]byte(message.Content), out); err == nil {
+				return nil
+			}
+		}
+
+		if attempt >= x.maxSchemaRetries {
+			return fmt.Errorf("invalid structured response after %d attempts: %w", attempt+1, err)
+		}
+
+		conversation = append(conversation,
+			&Request{Type: AssistantMessage, Content: message.Content},
+			&Request{Type: SystemMessage, Content: fmt.Sprintf(correctivePrompt, err, schema.Name)},
+		)
+	}
+}
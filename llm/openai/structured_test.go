@@ -0,0 +1,181 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tochemey/gopack/llm"
+)
+
+type answer struct {
+	City       string `json:"city"`
+	Population int    `json:"population"`
+}
+
+// chatCompletionRequest holds the fields of the chat completion requests checked by the tests
+type chatCompletionRequest struct {
+	Messages       []openai.ChatCompletionMessage `json:"messages"`
+	ResponseFormat *struct {
+		Type       openai.ChatCompletionResponseFormatType `json:"type"`
+		JSONSchema *struct {
+			Name   string          `json:"name"`
+			Schema json.RawMessage `json:"schema"`
+			Strict bool            `json:"strict"`
+		} `json:"json_schema"`
+	} `json:"response_format"`
+}
+
+// newChatServer creates a test server answering the chat completions with the given messages in turn
+// and recording the requests it receives
+func newChatServer(t *testing.T, messages ...openai.ChatCompletionMessage) (*httptest.Server, func() []chatCompletionRequest) {
+	var (
+		mu       sync.Mutex
+		requests []chatCompletionRequest
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/chat/completions", r.URL.Path)
+
+		var req chatCompletionRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		mu.Lock()
+		requests = append(requests, req)
+		message := messages[min(len(requests), len(messages))-1]
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(openai.ChatCompletionResponse{
+			Model:   "gpt-4o-2024-08-06",
+			Choices: []openai.ChatCompletionChoice{{Message: message}},
+			Usage:   openai.Usage{PromptTokens: 1000, CompletionTokens: 500, TotalTokens: 1500},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []chatCompletionRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]chatCompletionRequest(nil), requests...)
+	}
+}
+
+func TestQueryStructured(t *testing.T) {
+	requests := []*Request{{Type: UserMessage, Content: "What is the capital of France?"}}
+
+	t.Run("with valid response", func(t *testing.T) {
+		server, received := newChatServer(t, openai.ChatCompletionMessage{
+			Role:    openai.ChatMessageRoleAssistant,
+			Content: `{"city":"Paris","population":2100000}`,
+		})
+
+		x := newTestAPI(server)
+		out, err := QueryStructured[answer](context.Background(), x, requests, nil)
+		require.NoError(t, err)
+		assert.Equal(t, answer{City: "Paris", Population: 2100000}, out)
+
+		sent := received()
+		require.Len(t, sent, 1)
+		require.NotNil(t, sent[0].ResponseFormat)
+		assert.Equal(t, openai.ChatCompletionResponseFormatTypeJSONSchema, sent[0].ResponseFormat.Type)
+		require.NotNil(t, sent[0].ResponseFormat.JSONSchema)
+		assert.Equal(t, "answer", sent[0].ResponseFormat.JSONSchema.Name)
+		assert.Contains(t, string(sent[0].ResponseFormat.JSONSchema.Schema), `"population"`)
+	})
+	t.Run("with corrected response", func(t *testing.T) {
+		server, received := newChatServer(t,
+			openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: `{"city":"Paris"}`},
+			openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: `{"city":"Paris","population":2100000}`},
+		)
+
+		x := newTestAPI(server)
+		out, err := QueryStructured[answer](context.Background(), x, requests, nil)
+		require.NoError(t, err)
+		assert.Equal(t, answer{City: "Paris", Population: 2100000}, out)
+
+		sent := received()
+		require.Len(t, sent, 2)
+		messages := sent[1].Messages
+		require.Len(t, messages, 3)
+		assert.Equal(t, openai.ChatMessageRoleAssistant, messages[1].Role)
+		assert.Equal(t, `{"city":"Paris"}`, messages[1].Content)
+		assert.Equal(t, openai.ChatMessageRoleSystem, messages[2].Role)
+		assert.Contains(t, messages[2].Content, `missing required property "population"`)
+	})
+	t.Run("with retries exhausted", func(t *testing.T) {
+		server, received := newChatServer(t, openai.ChatCompletionMessage{
+			Role:    openai.ChatMessageRoleAssistant,
+			Content: `not json`,
+		})
+
+		x := newTestAPI(server, WithMaxSchemaRetries(1))
+		_, err := QueryStructured[answer](context.Background(), x, requests, nil)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, llm.ErrSchemaMismatch)
+		assert.Contains(t, err.Error(), "after 2 attempts")
+		assert.Len(t, received(), 2)
+	})
+	t.Run("with refusal", func(t *testing.T) {
+		server, received := newChatServer(t, openai.ChatCompletionMessage{
+			Role:    openai.ChatMessageRoleAssistant,
+			Refusal: "I cannot help with that",
+		})
+
+		x := newTestAPI(server)
+		_, err := QueryStructured[answer](context.Background(), x, requests, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "I cannot help with that")
+		assert.Len(t, received(), 1)
+	})
+	t.Run("with custom schema", func(t *testing.T) {
+		server, received := newChatServer(t, openai.ChatCompletionMessage{
+			Role:    openai.ChatMessageRoleAssistant,
+			Content: `{"city":"Paris","population":2100000}`,
+		})
+
+		schema := llm.SchemaOf[answer]()
+		schema.Name = "capital"
+		schema.Strict = true
+
+		x := newTestAPI(server)
+		_, err := QueryStructured[answer](context.Background(), x, requests, schema)
+		require.NoError(t, err)
+
+		sent := received()
+		require.Len(t, sent, 1)
+		assert.Equal(t, "capital", sent[0].ResponseFormat.JSONSchema.Name)
+		assert.True(t, sent[0].ResponseFormat.JSONSchema.Strict)
+	})
+}
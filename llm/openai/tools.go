@@ -0,0 +1,112 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package openai
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tochemey/gopack/llm"
+)
+
+// DefaultMaxToolIterations is the default maximum number of round trips QueryWithTools makes with the model
+const DefaultMaxToolIterations = 10
+
+// ToolExecutor runs the tool calls requested by the model
+type ToolExecutor interface {
+	// Execute runs the tool call and returns its result
+	Execute(ctx context.Context, call *ToolCall) (string, error)
+}
+
+var _ ToolExecutor = ToolExecutorFunc(nil)
+
+// ToolExecutorFunc implements the ToolExecutor interface
+type ToolExecutorFunc func(ctx context.Context, call *ToolCall) (string, error)
+
+// Execute runs the tool call
+func (f ToolExecutorFunc) Execute(ctx context.Context, call *ToolCall) (string, error) {
+	return f(ctx, call)
+}
+
+// NewToolBoxExecutor creates a ToolExecutor running the tools of the given toolbox
+func NewToolBoxExecutor(toolbox *llm.ToolBox) ToolExecutor {
+	return ToolExecutorFunc(func(ctx context.Context, call *ToolCall) (string, error) {
+		tool, ok := toolbox.Get(call.Name)
+		if !ok {
+			return "", fmt.Errorf("unknown tool: %s", call.Name)
+		}
+		return tool.Run(ctx, call.Arguments)
+	})
+}
+
+// QueryWithTools sends messages to OpenAI APIs along with the tools declared with WithTools and runs
+// the tool calls requested by the model with the executor, feeding their results back to the model
+// until it produces a final answer.
+//
+// It returns the final responses and the conversation, the given requests included, that led to them.
+// The tool errors are reported to the model as the result of the call.
+func (x api) QueryWithTools(ctx context.Context, requests []*Request, responseType ResponseType, executor ToolExecutor) (responses []*Response, conversation []*Request, err error) {
+	query := func(ctx context.Context, requests []*Request) ([]*Response, error) {
+		return x.Query(ctx, requests, responseType)
+	}
+	return runTools(ctx, requests, query, executor, x.maxToolIterations)
+}
+
+// runTools queries the model and runs the tool calls of the first response until the model produces a final answer
+func runTools(ctx context.Context, requests []*Request, query func(context.Context, []*Request) ([]*Response, error), executor ToolExecutor, maxIterations int) ([]*Response, []*Request, error) {
+	conversation := append([]*Request(nil), requests...)
+	for range maxIterations {
+		responses, err := query(ctx, conversation)
+		if err != nil {
+			return nil, conversation, err
+		}
+
+		calls := responses[0].ToolCalls
+		if len(calls) == 0 {
+			return responses, conversation, nil
+		}
+
+		conversation = append(conversation, &Request{
+			Type:      AssistantMessage,
+			Content:   responses[0].Content,
+			ToolCalls: calls,
+		})
+
+		for _, call := range calls {
+			result, err := executor.Execute(ctx, call)
+			if err != nil {
+				// let the model know the call failed
+				result = fmt.Sprintf("error: %v", err)
+			}
+
+			conversation = append(conversation, &Request{
+				Type:       ToolMessage,
+				Content:    result,
+				ToolCallID: call.ID,
+			})
+		}
+	}
+	return nil, conversation, fmt.Errorf("no final answer after %d tool iterations", maxIterations)
+}
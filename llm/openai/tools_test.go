@@ -0,0 +1,128 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package openai
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/invopop/jsonschema"
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tochemey/gopack/llm"
+)
+
+// weatherTool is a tool returning a fixed forecast
+type weatherTool struct{}
+
+func (weatherTool) Name() string        { return "weather" }
+func (weatherTool) Description() string { return "returns the weather of a city" }
+func (weatherTool) Arguments() *jsonschema.Schema {
+	return &jsonschema.Schema{Type: "object"}
+}
+func (weatherTool) Run(_ context.Context, arguments string) (string, error) {
+	return "sunny in " + arguments, nil
+}
+
+func TestRunTools(t *testing.T) {
+	ctx := context.Background()
+	executor := NewToolBoxExecutor(func() *llm.ToolBox {
+		toolbox := new(llm.ToolBox)
+		toolbox.Add(weatherTool{})
+		return toolbox
+	}())
+
+	t.Run("with final answer", func(t *testing.T) {
+		var received [][]*Request
+		query := func(_ context.Context, requests []*Request) ([]*Response, error) {
+			received = append(received, requests)
+			if len(received) == 1 {
+				return []*Response{{ToolCalls: []*ToolCall{
+					{ID: "call-1", Name: "weather", Arguments: "Paris"},
+					{ID: "call-2", Name: "unknown", Arguments: "{}"},
+				}}}, nil
+			}
+			return []*Response{{Content: "It is sunny"}}, nil
+		}
+
+		requests := []*Request{{Type: UserMessage, Content: "What is the weather in Paris?"}}
+		responses, conversation, err := runTools(ctx, requests, query, executor, DefaultMaxToolIterations)
+		require.NoError(t, err)
+		require.Len(t, responses, 1)
+		assert.Equal(t, "It is sunny", responses[0].Content)
+
+		require.Len(t, conversation, 4)
+		assert.Equal(t, AssistantMessage, conversation[1].Type)
+		assert.Len(t, conversation[1].ToolCalls, 2)
+		assert.Equal(t, &Request{Type: ToolMessage, Content: "sunny in Paris", ToolCallID: "call-1"}, conversation[2])
+		assert.Equal(t, &Request{Type: ToolMessage, Content: "error: unknown tool: unknown", ToolCallID: "call-2"}, conversation[3])
+		assert.Equal(t, conversation, received[1])
+		// the given requests are left untouched
+		assert.Len(t, requests, 1)
+	})
+	t.Run("with too many iterations", func(t *testing.T) {
+		query := func(context.Context, []*Request) ([]*Response, error) {
+			return []*Response{{ToolCalls: []*ToolCall{{ID: "call-1", Name: "weather", Arguments: "Paris"}}}}, nil
+		}
+
+		_, conversation, err := runTools(ctx, nil, query, executor, 2)
+		assert.EqualError(t, err, "no final answer after 2 tool iterations")
+		assert.Len(t, conversation, 4)
+	})
+	t.Run("with query failure", func(t *testing.T) {
+		query := func(context.Context, []*Request) ([]*Response, error) {
+			return nil, errors.New("unavailable")
+		}
+
+		_, _, err := runTools(ctx, nil, query, executor, DefaultMaxToolIterations)
+		assert.EqualError(t, err, "unavailable")
+	})
+}
+
+func TestToolMessages(t *testing.T) {
+	message, err := toChatCompletionMessage(&Request{
+		Type:      AssistantMessage,
+		ToolCalls: []*ToolCall{{ID: "call-1", Name: "weather", Arguments: "Paris"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, openai.ChatMessageRoleAssistant, message.Role)
+	require.Len(t, message.ToolCalls, 1)
+	assert.Equal(t, openai.ToolTypeFunction, message.ToolCalls[0].Type)
+	assert.Equal(t, "weather", message.ToolCalls[0].Function.Name)
+	assert.Equal(t, []*ToolCall{{ID: "call-1", Name: "weather", Arguments: "Paris"}}, fromOpenAIToolCalls(message.ToolCalls))
+
+	message, err = toChatCompletionMessage(&Request{Type: ToolMessage, Content: "sunny", ToolCallID: "call-1"})
+	require.NoError(t, err)
+	assert.Equal(t, openai.ChatMessageRoleTool, message.Role)
+	assert.Equal(t, "call-1", message.ToolCallID)
+
+	tools := toOpenAITools([]llm.Tool{weatherTool{}})
+	require.Len(t, tools, 1)
+	assert.Equal(t, "weather", tools[0].Function.Name)
+	assert.Equal(t, "returns the weather of a city", tools[0].Function.Description)
+}
@@ -0,0 +1,85 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package openai
+
+import (
+	"context"
+
+	"github.com/sashabaranov/go-openai"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.27.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// attemptsKey is the span attribute holding the number of attempts of a call, retries included
+const attemptsKey = attribute.Key("llm.attempts")
+
+// startSpan starts the client span of a chat completion, named and described following the gen-ai
+// semantic conventions
+func (x api) startSpan(ctx context.Context, operation string, req openai.ChatCompletionRequest) (context.Context, trace.Span) {
+	attributes := []attribute.KeyValue{
+		semconv.GenAIOperationNameChat,
+		semconv.GenAISystemOpenai,
+		semconv.GenAIRequestModel(req.Model),
+		semconv.GenAIRequestTemperature(float64(req.Temperature)),
+		semconv.GenAIRequestFrequencyPenalty(float64(req.FrequencyPenalty)),
+		semconv.GenAIRequestPresencePenalty(float64(req.PresencePenalty)),
+		operationKey.String(operation),
+	}
+
+	if req.MaxTokens > 0 {
+		attributes = append(attributes, semconv.GenAIRequestMaxTokens(req.MaxTokens))
+	}
+
+	return x.tracer.Start(ctx, "chat "+req.Model,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attributes...))
+}
+
+// endSpan sets the outcome of the chat completion on its span and ends it
+func endSpan(span trace.Span, resp openai.ChatCompletionResponse, attempts int, err error) {
+	defer span.End()
+
+	span.SetAttributes(attemptsKey.Int(attempts))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return
+	}
+
+	finishReasons := make([]string, len(resp.Choices))
+	for i, choice := range resp.Choices {
+		finishReasons[i] = string(choice.FinishReason)
+	}
+
+	span.SetAttributes(
+		semconv.GenAIResponseID(resp.ID),
+		semconv.GenAIResponseModel(resp.Model),
+		semconv.GenAIResponseFinishReasons(finishReasons...),
+		semconv.GenAIUsageInputTokens(resp.Usage.PromptTokens),
+		semconv.GenAIUsageOutputTokens(resp.Usage.CompletionTokens),
+	)
+}
@@ -0,0 +1,126 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// spanAttributes returns the attributes of the span by key
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	out := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		out[kv.Key] = kv.Value
+	}
+	return out
+}
+
+func TestTracing(t *testing.T) {
+	t.Run("with retries", func(t *testing.T) {
+		calls := new(atomic.Int32)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			// the first call fails and is retried
+			if calls.Add(1) == 1 {
+				w.WriteHeader(http.StatusInternalServerError)
+				_, _ = w.Write([]byte(`{"error":{"message":"overloaded","type":"server_error"}}`))
+				return
+			}
+
+			_ = json.NewEncoder(w).Encode(openai.ChatCompletionResponse{
+				ID:    "chatcmpl-1",
+				Model: "gpt-4o-2024-08-06",
+				Choices: []openai.ChatCompletionChoice{{
+					Message:      openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: "hi"},
+					FinishReason: openai.FinishReasonStop,
+				}},
+				Usage: openai.Usage{PromptTokens: 12, CompletionTokens: 3, TotalTokens: 15},
+			})
+		}))
+		defer server.Close()
+
+		recorder := tracetest.NewSpanRecorder()
+		x := newTestAPI(server, WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))), WithMaxTokens(50))
+		x.config.MaxRetries = 1
+
+		_, err := x.Query(context.Background(), []*Request{{Type: UserMessage, Content: "hello"}}, TextResponseType)
+		require.NoError(t, err)
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 1)
+		span := spans[0]
+		assert.Equal(t, "chat gpt-4o", span.Name())
+		assert.Equal(t, trace.SpanKindClient, span.SpanKind())
+		assert.Equal(t, codes.Unset, span.Status().Code)
+
+		attributes := spanAttributes(span)
+		assert.Equal(t, "chat", attributes["gen_ai.operation.name"].AsString())
+		assert.Equal(t, "openai", attributes["gen_ai.system"].AsString())
+		assert.Equal(t, "gpt-4o", attributes["gen_ai.request.model"].AsString())
+		assert.EqualValues(t, 50, attributes["gen_ai.request.max_tokens"].AsInt64())
+		assert.Equal(t, "gpt-4o-2024-08-06", attributes["gen_ai.response.model"].AsString())
+		assert.Equal(t, "chatcmpl-1", attributes["gen_ai.response.id"].AsString())
+		assert.Equal(t, []string{"stop"}, attributes["gen_ai.response.finish_reasons"].AsStringSlice())
+		assert.EqualValues(t, 12, attributes["gen_ai.usage.input_tokens"].AsInt64())
+		assert.EqualValues(t, 3, attributes["gen_ai.usage.output_tokens"].AsInt64())
+		assert.EqualValues(t, 2, attributes["llm.attempts"].AsInt64())
+		assert.Equal(t, OperationQuery, attributes["llm.operation"].AsString())
+	})
+	t.Run("with a failure", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":{"message":"invalid api key","type":"invalid_request_error"}}`))
+		}))
+		defer server.Close()
+
+		recorder := tracetest.NewSpanRecorder()
+		x := newTestAPI(server, WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))))
+
+		_, err := x.Query(context.Background(), []*Request{{Type: UserMessage, Content: "hello"}}, TextResponseType)
+		require.Error(t, err)
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 1)
+		assert.Equal(t, codes.Error, spans[0].Status().Code)
+		require.Len(t, spans[0].Events(), 1)
+		assert.Equal(t, "exception", spans[0].Events()[0].Name)
+		assert.EqualValues(t, 1, spanAttributes(spans[0])["llm.attempts"].AsInt64())
+	})
+}
@@ -0,0 +1,271 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package openai
+
+import (
+	"context"
+	"maps"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/multierr"
+)
+
+// instrumentationName is the name of the meter and the tracer of the OpenAI client
+const instrumentationName = "github.com/tochemey/gopack/llm/openai"
+
+// the operations whose usage is recorded
+const (
+	OperationQuery      = "query"
+	OperationStream     = "stream"
+	OperationVision     = "vision"
+	OperationStructured = "structured"
+	OperationEmbeddings = "embeddings"
+)
+
+// the attributes of the usage metrics
+const (
+	modelKey     = attribute.Key("llm.model")
+	operationKey = attribute.Key("llm.operation")
+	statusKey    = attribute.Key("llm.status")
+	tokenTypeKey = attribute.Key("llm.token.type")
+)
+
+// UsageRecord holds the usage of an OpenAI API call
+type UsageRecord struct {
+	// Operation is the operation of the call, see the Operation constants
+	Operation string
+	// Model is the model that served the call
+	Model string
+	// PromptTokens is the number of tokens of the prompt
+	PromptTokens int
+	// CompletionTokens is the number of tokens generated
+	CompletionTokens int
+	// TotalTokens is the number of tokens of the call
+	TotalTokens int
+	// Latency is the duration of the call, retries included
+	Latency time.Duration
+	// Cost is the cost of the call computed with the price table
+	Cost float64
+	// Err is the error the call failed with
+	Err error
+}
+
+// UsageRecorder captures the usage of every OpenAI API call
+type UsageRecorder interface {
+	// RecordUsage records the usage of a call. It must not block.
+	RecordUsage(ctx context.Context, record *UsageRecord)
+}
+
+var _ UsageRecorder = UsageRecorderFunc(nil)
+
+// UsageRecorderFunc implements the UsageRecorder interface.
+type UsageRecorderFunc func(ctx context.Context, record *UsageRecord)
+
+func (f UsageRecorderFunc) RecordUsage(ctx context.Context, record *UsageRecord) {
+	f(ctx, record)
+}
+
+// Price holds the prices of a model in USD per million tokens
+type Price struct {
+	// Prompt is the price of a million prompt tokens
+	Prompt float64
+	// Completion is the price of a million generated tokens
+	Completion float64
+}
+
+// PriceTable holds the prices of the models. A model is priced by the longest
+// model name of the table it starts with so that the model versions share the price of their model.
+type PriceTable map[string]Price
+
+// DefaultPriceTable returns the public prices of the common OpenAI models
+// at the time of writing. Use WithPriceTable to set up to date prices.
+func DefaultPriceTable() PriceTable {
+	return PriceTable{
+		"gpt-4o":                 {Prompt: 2.50, Completion: 10},
+		"gpt-4o-mini":            {Prompt: 0.15, Completion: 0.60},
+		"gpt-4-turbo":            {Prompt: 10, Completion: 30},
+		"gpt-4":                  {Prompt: 30, Completion: 60},
+		"gpt-3.5-turbo":          {Prompt: 0.50, Completion: 1.50},
+		"text-embedding-3-small": {Prompt: 0.02},
+		"text-embedding-3-large": {Prompt: 0.13},
+		"text-embedding-ada-002": {Prompt: 0.10},
+	}
+}
+
+// Cost returns the cost of the tokens of the given model. It is zero when the model is not priced.
+func (t PriceTable) Cost(model string, promptTokens, completionTokens int) float64 {
+	var (
+		price   Price
+		matched string
+	)
+
+	for name, candidate := range t {
+		if strings.HasPrefix(model, name) && len(name) > len(matched) {
+			price, matched = candidate, name
+		}
+	}
+	return (float64(promptTokens)*price.Prompt + float64(completionTokens)*price.Completion) / 1_000_000
+}
+
+// UsageTotals holds the usage of a set of calls
+type UsageTotals struct {
+	// Calls is the number of calls
+	Calls int64
+	// Errors is the number of calls that failed
+	Errors int64
+	// PromptTokens is the number of tokens of the prompts
+	PromptTokens int64
+	// CompletionTokens is the number of tokens generated
+	CompletionTokens int64
+	// TotalTokens is the number of tokens
+	TotalTokens int64
+	// Cost is the cost of the calls
+	Cost float64
+	// Latency is the cumulated latency of the calls
+	Latency time.Duration
+}
+
+// add adds the usage of a call
+func (t *UsageTotals) add(record *UsageRecord) {
+	t.Calls++
+	if record.Err != nil {
+		t.Errors++
+	}
+	t.PromptTokens += int64(record.PromptTokens)
+	t.CompletionTokens += int64(record.CompletionTokens)
+	t.TotalTokens += int64(record.TotalTokens)
+	t.Cost += record.Cost
+	t.Latency += record.Latency
+}
+
+// UsageSummary holds the usage of the calls made by an API
+type UsageSummary struct {
+	UsageTotals
+	// Models holds the usage per model
+	Models map[string]UsageTotals
+}
+
+// usageTracker aggregates the usage of the calls and forwards it to the recorder and the metrics
+type usageTracker struct {
+	mu       sync.Mutex
+	summary  UsageSummary
+	prices   PriceTable
+	recorder UsageRecorder
+	metrics  *usageMetrics
+}
+
+// newUsageTracker creates an instance of usageTracker. The metrics are optional.
+func newUsageTracker(prices PriceTable, recorder UsageRecorder, metrics *usageMetrics) *usageTracker {
+	return &usageTracker{
+		summary:  UsageSummary{Models: make(map[string]UsageTotals)},
+		prices:   prices,
+		recorder: recorder,
+		metrics:  metrics,
+	}
+}
+
+// record prices the call and records its usage
+func (t *usageTracker) record(ctx context.Context, record *UsageRecord) {
+	record.Cost = t.prices.Cost(record.Model, record.PromptTokens, record.CompletionTokens)
+
+	t.mu.Lock()
+	t.summary.add(record)
+	models := t.summary.Models[record.Model]
+	models.add(record)
+	t.summary.Models[record.Model] = models
+	t.mu.Unlock()
+
+	if t.metrics != nil {
+		t.metrics.record(ctx, record)
+	}
+
+	if t.recorder != nil {
+		t.recorder.RecordUsage(ctx, record)
+	}
+}
+
+// snapshot returns a copy of the usage summary
+func (t *usageTracker) snapshot() UsageSummary {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return UsageSummary{
+		UsageTotals: t.summary.UsageTotals,
+		Models:      maps.Clone(t.summary.Models),
+	}
+}
+
+// usageMetrics holds the instruments recording the usage of the calls
+type usageMetrics struct {
+	requests metric.Int64Counter
+	tokens   metric.Int64Counter
+	cost     metric.Float64Counter
+	duration metric.Float64Histogram
+}
+
+// newUsageMetrics creates the usage instruments with the given meter
+func newUsageMetrics(meter metric.Meter) (*usageMetrics, error) {
+	requests, requestsErr := meter.Int64Counter("llm.requests",
+		metric.WithDescription("The number of calls to the LLM apis"),
+		metric.WithUnit("{request}"))
+	tokens, tokensErr := meter.Int64Counter("llm.tokens",
+		metric.WithDescription("The number of tokens used by the calls to the LLM apis"),
+		metric.WithUnit("{token}"))
+	cost, costErr := meter.Float64Counter("llm.cost",
+		metric.WithDescription("The cost of the calls to the LLM apis"),
+		metric.WithUnit("{USD}"))
+	duration, durationErr := meter.Float64Histogram("llm.duration",
+		metric.WithDescription("The duration of the calls to the LLM apis, retries included"),
+		metric.WithUnit("s"))
+
+	if err := multierr.Combine(requestsErr, tokensErr, costErr, durationErr); err != nil {
+		return nil, err
+	}
+
+	return &usageMetrics{
+		requests: requests,
+		tokens:   tokens,
+		cost:     cost,
+		duration: duration,
+	}, nil
+}
+
+// record records the usage of a call
+func (m *usageMetrics) record(ctx context.Context, record *UsageRecord) {
+	status := "ok"
+	if record.Err != nil {
+		status = "error"
+	}
+
+	attributes := []attribute.KeyValue{modelKey.String(record.Model), operationKey.String(record.Operation)}
+	m.requests.Add(ctx, 1, metric.WithAttributes(append(attributes, statusKey.String(status))...))
+	m.duration.Record(ctx, record.Latency.Seconds(), metric.WithAttributes(attributes...))
+	m.tokens.Add(ctx, int64(record.PromptTokens), metric.WithAttributes(append(attributes, tokenTypeKey.String("prompt"))...))
+	m.tokens.Add(ctx, int64(record.CompletionTokens), metric.WithAttributes(append(attributes, tokenTypeKey.String("completion"))...))
+	m.cost.Add(ctx, record.Cost, metric.WithAttributes(attributes...))
+}
@@ -0,0 +1,164 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package openai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestPriceTable(t *testing.T) {
+	prices := DefaultPriceTable()
+	assert.InDelta(t, 2.5+10, prices.Cost("gpt-4o", 1_000_000, 1_000_000), 1e-9)
+	// the versions are priced as their model, the longest model name winning
+	assert.InDelta(t, 0.15, prices.Cost("gpt-4o-mini-2024-07-18", 1_000_000, 0), 1e-9)
+	assert.InDelta(t, 0.0025, prices.Cost("gpt-4o-2024-08-06", 1000, 0), 1e-9)
+	assert.Zero(t, prices.Cost("unknown-model", 1000, 1000))
+}
+
+func TestUsage(t *testing.T) {
+	requests := []*Request{{Type: UserMessage, Content: "hello"}}
+
+	t.Run("with recorder and summary", func(t *testing.T) {
+		server, _ := newChatServer(t, openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: "hi"})
+
+		var (
+			mu      sync.Mutex
+			records []*UsageRecord
+		)
+		recorder := UsageRecorderFunc(func(_ context.Context, record *UsageRecord) {
+			mu.Lock()
+			defer mu.Unlock()
+			records = append(records, record)
+		})
+
+		x := newTestAPI(server, WithUsageRecorder(recorder))
+		for range 2 {
+			_, err := x.Query(context.Background(), requests, TextResponseType)
+			require.NoError(t, err)
+		}
+
+		mu.Lock()
+		require.Len(t, records, 2)
+		record := records[0]
+		mu.Unlock()
+		assert.Equal(t, OperationQuery, record.Operation)
+		assert.Equal(t, "gpt-4o-2024-08-06", record.Model)
+		assert.Equal(t, 1000, record.PromptTokens)
+		assert.Equal(t, 500, record.CompletionTokens)
+		assert.Equal(t, 1500, record.TotalTokens)
+		assert.InDelta(t, 0.0075, record.Cost, 1e-9)
+		assert.NoError(t, record.Err)
+
+		summary := x.Usage()
+		assert.EqualValues(t, 2, summary.Calls)
+		assert.EqualValues(t, 3000, summary.TotalTokens)
+		assert.InDelta(t, 0.015, summary.Cost, 1e-9)
+		require.Contains(t, summary.Models, "gpt-4o-2024-08-06")
+		assert.EqualValues(t, 2, summary.Models["gpt-4o-2024-08-06"].Calls)
+	})
+	t.Run("with custom prices", func(t *testing.T) {
+		server, _ := newChatServer(t, openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: "hi"})
+
+		x := newTestAPI(server, WithPriceTable(PriceTable{"gpt-4o": {Prompt: 1, Completion: 2}}))
+		_, err := x.Query(context.Background(), requests, TextResponseType)
+		require.NoError(t, err)
+		assert.InDelta(t, 0.002, x.Usage().Cost, 1e-9)
+	})
+	t.Run("with failed call", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":{"message":"invalid api key"}}`))
+		}))
+		defer server.Close()
+
+		x := newTestAPI(server)
+		_, err := x.Query(context.Background(), requests, TextResponseType)
+		require.Error(t, err)
+
+		summary := x.Usage()
+		assert.EqualValues(t, 1, summary.Calls)
+		assert.EqualValues(t, 1, summary.Errors)
+		assert.EqualValues(t, 1, summary.Models["gpt-4o"].Errors)
+	})
+	t.Run("with embeddings", func(t *testing.T) {
+		server := newEmbeddingsServer(t, new(atomic.Int32), DefaultEmbeddingModel, func(string) []float32 {
+			return []float32{1}
+		})
+
+		x := newTestAPI(server, WithEmbeddingBatchSize(1))
+		_, err := x.Embeddings(context.Background(), []string{"a", "b"})
+		require.NoError(t, err)
+
+		summary := x.Usage()
+		assert.EqualValues(t, 2, summary.Calls)
+		assert.EqualValues(t, 2, summary.Models[DefaultEmbeddingModel].Calls)
+	})
+	t.Run("with metrics", func(t *testing.T) {
+		server, _ := newChatServer(t, openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: "hi"})
+
+		reader := sdkmetric.NewManualReader()
+		provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+		x := newTestAPI(server, WithMeterProvider(provider))
+		_, err := x.Query(context.Background(), requests, TextResponseType)
+		require.NoError(t, err)
+
+		var data metricdata.ResourceMetrics
+		require.NoError(t, reader.Collect(context.Background(), &data))
+		require.Len(t, data.ScopeMetrics, 1)
+
+		metrics := make(map[string]metricdata.Aggregation)
+		for _, m := range data.ScopeMetrics[0].Metrics {
+			metrics[m.Name] = m.Data
+		}
+
+		require.Contains(t, metrics, "llm.requests")
+		requestsSum := metrics["llm.requests"].(metricdata.Sum[int64])
+		require.Len(t, requestsSum.DataPoints, 1)
+		assert.EqualValues(t, 1, requestsSum.DataPoints[0].Value)
+
+		require.Contains(t, metrics, "llm.tokens")
+		var tokens int64
+		for _, point := range metrics["llm.tokens"].(metricdata.Sum[int64]).DataPoints {
+			tokens += point.Value
+		}
+		assert.EqualValues(t, 1500, tokens)
+
+		require.Contains(t, metrics, "llm.cost")
+		require.Contains(t, metrics, "llm.duration")
+	})
+}
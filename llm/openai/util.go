@@ -25,15 +25,15 @@
 package openai
 
 import (
-	"bytes"
-	"encoding/base64"
 	"fmt"
-	"image"
-	"image/jpeg"
 	"strings"
+	"time"
 
-	"github.com/pkoukk/tiktoken-go"
 	"github.com/sashabaranov/go-openai"
+
+	"github.com/tochemey/gopack/clock"
+	"github.com/tochemey/gopack/llm"
+	"github.com/tochemey/gopack/llm/internal/transport"
 )
 
 func transformImageRequests(imageRequests []*VisionRequest) ([]openai.ChatCompletionMessage, error) {
@@ -45,15 +45,24 @@ func transformImageRequests(imageRequests []*VisionRequest) ([]openai.ChatComple
 
 	for _, msg := range imageRequests {
 		switch {
-		case msg.Image != nil:
-			imgInput, err := toString(msg.Image)
+		case msg.ImageURL != "":
+			out.MultiContent = append(out.MultiContent, openai.ChatMessagePart{
+				Type: openai.ChatMessagePartTypeImageURL,
+				ImageURL: &openai.ChatMessageImageURL{
+					URL:    msg.ImageURL,
+					Detail: openai.ImageURLDetail(msg.Detail),
+				},
+			})
+		case msg.HasImage():
+			mediaType, data, err := transport.EncodeImage(msg)
 			if err != nil {
 				return []openai.ChatCompletionMessage{out}, fmt.Errorf("image failed to convert: %v", err)
 			}
 			out.MultiContent = append(out.MultiContent, openai.ChatMessagePart{
 				Type: openai.ChatMessagePartTypeImageURL,
 				ImageURL: &openai.ChatMessageImageURL{
-					URL: imgInput,
+					URL:    fmt.Sprintf("data:%s;base64,%s", mediaType, data),
+					Detail: openai.ImageURLDetail(msg.Detail),
 				},
 			})
 		default:
@@ -68,20 +77,6 @@ func transformImageRequests(imageRequests []*VisionRequest) ([]openai.ChatComple
 	return arr, nil
 }
 
-// toString converts an image to string
-func toString(image image.Image) (string, error) {
-	buff := new(bytes.Buffer)
-	if err := jpeg.Encode(buff, image, &jpeg.Options{
-		Quality: 100,
-	}); err != nil {
-		return "", fmt.Errorf("encoding for model: %w", err)
-	}
-
-	encoded := base64.StdEncoding.EncodeToString(buff.Bytes())
-	encodedURL := fmt.Sprintf("data:image/jpeg;base64,%s", encoded)
-	return encodedURL, nil
-}
-
 // toChatCompletionMessage converts a message to an openai chat completion message
 func toChatCompletionMessage(query *Request) (openai.ChatCompletionMessage, error) {
 	message := openai.ChatCompletionMessage{
@@ -92,6 +87,19 @@ func toChatCompletionMessage(query *Request) (openai.ChatCompletionMessage, erro
 		message.Role = openai.ChatMessageRoleSystem
 	case AssistantMessage:
 		message.Role = openai.ChatMessageRoleAssistant
+		for _, call := range query.ToolCalls {
+			message.ToolCalls = append(message.ToolCalls, openai.ToolCall{
+				ID:   call.ID,
+				Type: openai.ToolTypeFunction,
+				Function: openai.FunctionCall{
+					Name:      call.Name,
+					Arguments: call.Arguments,
+				},
+			})
+		}
+	case ToolMessage:
+		message.Role = openai.ChatMessageRoleTool
+		message.ToolCallID = query.ToolCallID
 	case UserMessage:
 		message.Role = openai.ChatMessageRoleUser
 	default:
@@ -100,15 +108,42 @@ func toChatCompletionMessage(query *Request) (openai.ChatCompletionMessage, erro
 	return message, nil
 }
 
-// tokensCount estimates the number of tokens for a given array of messages
-// https://github.com/pkoukk/tiktoken-go#counting-tokens-for-chat-api-calls
-func tokensCount(messages []openai.ChatCompletionMessage, model string) (numTokens int, err error) {
-	tkm, err := tiktoken.EncodingForModel(model)
-	if err != nil {
-		err = fmt.Errorf("encoding for model: %v", err)
-		return
+// toOpenAITools converts the tools to openai function tools
+func toOpenAITools(tools []llm.Tool) []openai.Tool {
+	out := make([]openai.Tool, 0, len(tools))
+	for _, tool := range tools {
+		out = append(out, openai.Tool{
+			Type: openai.ToolTypeFunction,
+			Function: &openai.FunctionDefinition{
+				Name:        tool.Name(),
+				Description: tool.Description(),
+				Parameters:  tool.Arguments(),
+			},
+		})
 	}
+	return out
+}
 
+// fromOpenAIToolCalls converts the tool calls of an openai message
+func fromOpenAIToolCalls(calls []openai.ToolCall) []*ToolCall {
+	if len(calls) == 0 {
+		return nil
+	}
+
+	out := make([]*ToolCall, 0, len(calls))
+	for _, call := range calls {
+		out = append(out, &ToolCall{
+			ID:        call.ID,
+			Name:      call.Function.Name,
+			Arguments: call.Function.Arguments,
+		})
+	}
+	return out
+}
+
+// tokensCount estimates the number of tokens for a given array of messages
+// https://github.com/pkoukk/tiktoken-go#counting-tokens-for-chat-api-calls
+func tokensCount(enc encoder, messages []openai.ChatCompletionMessage, model string) (numTokens int, err error) {
 	var tokensPerMessage, tokensPerName int
 	switch model {
 	case openai.GPT3Dot5Turbo0613,
@@ -131,9 +166,9 @@ func tokensCount(messages []openai.ChatCompletionMessage, model string) (numToke
 	default:
 		switch {
 		case strings.Contains(model, openai.GPT3Dot5Turbo):
-			return tokensCount(messages, openai.GPT3Dot5Turbo0613)
+			return tokensCount(enc, messages, openai.GPT3Dot5Turbo0613)
 		case strings.Contains(model, openai.GPT4):
-			return tokensCount(messages, openai.GPT40613)
+			return tokensCount(enc, messages, openai.GPT40613)
 		default:
 			err = fmt.Errorf("num_tokens_from_messages() is not implemented for model %s. See https://github.com/openai/openai-python/blob/main/chatml.md for information on how messages are converted to tokens", model)
 			return
@@ -142,9 +177,9 @@ func tokensCount(messages []openai.ChatCompletionMessage, model string) (numToke
 
 	for _, message := range messages {
 		numTokens += tokensPerMessage
-		numTokens += len(tkm.Encode(message.Content, nil, nil))
-		numTokens += len(tkm.Encode(message.Role, nil, nil))
-		numTokens += len(tkm.Encode(message.Name, nil, nil))
+		numTokens += len(enc.Encode(message.Content))
+		numTokens += len(enc.Encode(message.Role))
+		numTokens += len(enc.Encode(message.Name))
 		if message.Name != "" {
 			numTokens += tokensPerName
 		}
@@ -152,3 +187,30 @@ func tokensCount(messages []openai.ChatCompletionMessage, model string) (numToke
 	numTokens += 3 // every reply is primed with <|start|>assistant<|message|>
 	return numTokens, nil
 }
+
+// backoffTimer adapts a clock.Clock to the backoff.Timer interface
+type backoffTimer struct {
+	clock clock.Clock
+	timer clock.Timer
+}
+
+// Start starts the timer to fire after the given duration
+func (t *backoffTimer) Start(duration time.Duration) {
+	if t.timer == nil {
+		t.timer = t.clock.NewTimer(duration)
+		return
+	}
+	t.timer.Reset(duration)
+}
+
+// Stop is called when the timer is not used anymore and resources may be freed
+func (t *backoffTimer) Stop() {
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+}
+
+// C returns the timer channel which receives the current time when the timer fires
+func (t *backoffTimer) C() <-chan time.Time {
+	return t.timer.C()
+}
@@ -0,0 +1,115 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+// Package provider creates the llm.Client of the configured LLM provider
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/tochemey/gopack/llm"
+	"github.com/tochemey/gopack/llm/anthropic"
+	"github.com/tochemey/gopack/llm/gemini"
+	"github.com/tochemey/gopack/llm/openai"
+)
+
+// Name defines the name of an LLM provider
+type Name string
+
+const (
+	// OpenAI is the OpenAI provider
+	OpenAI Name = "openai"
+	// Anthropic is the Anthropic Claude provider
+	Anthropic Name = "anthropic"
+	// Gemini is the Google Gemini provider
+	Gemini Name = "gemini"
+)
+
+// Config defines the configuration of the LLM client.
+// The settings a provider does not support are ignored.
+type Config struct {
+	// Provider defines the LLM provider
+	Provider Name
+	// Token defines the API key of the provider
+	Token string
+	// Model defines the model
+	Model string
+	// EmbeddingModel defines the model computing the embeddings (OpenAI and Gemini)
+	EmbeddingModel string
+	// Timeout defines the timeout used when calling the provider apis
+	Timeout time.Duration
+	// MaxRetries defines the maximum of retries when calling the provider apis
+	MaxRetries int
+	// MaxTokens defines the maximum number of tokens generated per query (Anthropic)
+	MaxTokens int
+	// BaseURL defines the url of the provider API. It defaults to the provider url.
+	BaseURL string
+	// Organization defines the OpenAI organization (OpenAI)
+	Organization string
+	// HTTPClient defines the HTTP client calling the provider apis. It defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// New creates the llm.Client of the configured provider
+func New(config *Config) (llm.Client, error) {
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	switch config.Provider {
+	case OpenAI:
+		return openai.NewAPI(&openai.Config{
+			Token:          config.Token,
+			Model:          config.Model,
+			Timeout:        config.Timeout,
+			MaxRetries:     config.MaxRetries,
+			Organization:   config.Organization,
+			EmbeddingModel: config.EmbeddingModel,
+			BaseURL:        config.BaseURL,
+		}, openai.WithHTTPClient(httpClient)), nil
+	case Anthropic:
+		return anthropic.NewClient(&anthropic.Config{
+			Token:      config.Token,
+			Model:      config.Model,
+			Timeout:    config.Timeout,
+			MaxRetries: config.MaxRetries,
+			MaxTokens:  config.MaxTokens,
+			BaseURL:    config.BaseURL,
+		}, anthropic.WithHTTPClient(httpClient)), nil
+	case Gemini:
+		return gemini.NewClient(&gemini.Config{
+			Token:          config.Token,
+			Model:          config.Model,
+			EmbeddingModel: config.EmbeddingModel,
+			Timeout:        config.Timeout,
+			MaxRetries:     config.MaxRetries,
+			BaseURL:        config.BaseURL,
+		}, gemini.WithHTTPClient(httpClient)), nil
+	default:
+		return nil, fmt.Errorf("unsupported llm provider: %q", config.Provider)
+	}
+}
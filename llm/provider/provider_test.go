@@ -0,0 +1,79 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tochemey/gopack/llm"
+	"github.com/tochemey/gopack/llm/openai"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("with openai", func(t *testing.T) {
+		client, err := New(&Config{Provider: OpenAI, Token: "secret", Model: "gpt-4o"})
+		require.NoError(t, err)
+		assert.Implements(t, (*openai.API)(nil), client)
+	})
+	t.Run("with gemini", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/models/embedder:batchEmbedContents", r.URL.Path)
+			_, _ = w.Write([]byte(`{"embeddings":[{"values":[0.5]}]}`))
+		}))
+		defer server.Close()
+
+		client, err := New(&Config{
+			Provider:       Gemini,
+			Token:          "secret",
+			Model:          "model",
+			EmbeddingModel: "embedder",
+			BaseURL:        server.URL,
+			HTTPClient:     server.Client(),
+		})
+		require.NoError(t, err)
+
+		embeddings, err := client.Embeddings(context.Background(), []string{"hello"})
+		require.NoError(t, err)
+		assert.Equal(t, [][]float32{{0.5}}, embeddings)
+	})
+	t.Run("with anthropic", func(t *testing.T) {
+		client, err := New(&Config{Provider: Anthropic, Token: "secret", Model: "model"})
+		require.NoError(t, err)
+
+		_, err = client.Embeddings(context.Background(), []string{"hello"})
+		assert.ErrorIs(t, err, llm.ErrNotSupported)
+	})
+	t.Run("with unsupported provider", func(t *testing.T) {
+		client, err := New(&Config{Provider: "unknown"})
+		assert.Error(t, err)
+		assert.Nil(t, client)
+	})
+}
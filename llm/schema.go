@@ -0,0 +1,347 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package llm
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/invopop/jsonschema"
+)
+
+// ErrSchemaMismatch is returned when a JSON response does not conform to its schema
+var ErrSchemaMismatch = errors.New("response does not conform to the schema")
+
+// schemaNameRegexp matches the characters not allowed in a schema name
+var schemaNameRegexp = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// Schema defines the JSON schema a structured response must conform to
+type Schema struct {
+	// Name is the name of the schema
+	Name string
+	// Description describes the expected response
+	Description string
+	// Definition is the JSON schema of the response
+	Definition *jsonschema.Schema
+	// Strict asks the provider to strictly follow the schema when it supports it.
+	// Strict schemas usually require every property to be required and no additional properties.
+	Strict bool
+}
+
+// SchemaOf creates the Schema of the type T with its definitions inlined.
+// The fields without the omitempty json tag are required and no additional properties are allowed.
+func SchemaOf[T any]() *Schema {
+	reflector := &jsonschema.Reflector{DoNotReference: true, Anonymous: true}
+	definition := reflector.ReflectFromType(reflect.TypeFor[T]())
+	// the providers do not expect the meta-data of the document
+	definition.Version = ""
+
+	name := schemaNameRegexp.ReplaceAllString(reflect.TypeFor[T]().Name(), "_")
+	if name == "" {
+		name = "response"
+	}
+	return &Schema{Name: name, Definition: definition}
+}
+
+// ValidateJSON checks that the JSON content conforms to the schema.
+// The returned error wraps ErrSchemaMismatch and describes the first violation found.
+func ValidateJSON(schema *jsonschema.Schema, content []byte) error {
+	var data any
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	if err := decoder.Decode(&data); err != nil {
+		return fmt.Errorf("%w: invalid JSON: %v", ErrSchemaMismatch, err)
+	}
+
+	if decoder.More() {
+		return fmt.Errorf("%w: invalid JSON: unexpected data after the top-level value", ErrSchemaMismatch)
+	}
+
+	if err := (&validator{root: schema}).validate(schema, data, "$"); err != nil {
+		return fmt.Errorf("%w: %v", ErrSchemaMismatch, err)
+	}
+	return nil
+}
+
+// validator validates a JSON document against a schema and its definitions
+type validator struct {
+	root *jsonschema.Schema
+}
+
+// validate checks the data at the given path against the schema
+func (v *validator) validate(schema *jsonschema.Schema, data any, path string) error {
+	switch {
+	case schema == nil || isBooleanSchema(schema, true):
+		return nil
+	case isBooleanSchema(schema, false):
+		return fmt.Errorf("%s: unexpected value", path)
+	}
+
+	if schema.Ref != "" {
+		resolved, err := v.resolve(schema.Ref)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if err := v.validate(resolved, data, path); err != nil {
+			return err
+		}
+	}
+
+	for _, sub := range schema.AllOf {
+		if err := v.validate(sub, data, path); err != nil {
+			return err
+		}
+	}
+
+	if len(schema.AnyOf) > 0 && v.matches(schema.AnyOf, data, path) == 0 {
+		return fmt.Errorf("%s: does not match any of the allowed schemas", path)
+	}
+
+	if len(schema.OneOf) > 0 && v.matches(schema.OneOf, data, path) != 1 {
+		return fmt.Errorf("%s: does not match exactly one of the allowed schemas", path)
+	}
+
+	if schema.Const != nil && !jsonEqual(schema.Const, data) {
+		return fmt.Errorf("%s: expected %v", path, schema.Const)
+	}
+
+	if len(schema.Enum) > 0 && !containsJSON(schema.Enum, data) {
+		return fmt.Errorf("%s: %v is not one of %v", path, data, schema.Enum)
+	}
+
+	if schema.Type != "" && !hasType(schema.Type, data) {
+		return fmt.Errorf("%s: expected %s but got %s", path, schema.Type, typeOf(data))
+	}
+
+	switch value := data.(type) {
+	case map[string]any:
+		return v.validateObject(schema, value, path)
+	case []any:
+		return v.validateArray(schema, value, path)
+	case string:
+		return validateString(schema, value, path)
+	case json.Number:
+		return validateNumber(schema, value, path)
+	}
+	return nil
+}
+
+// matches returns the number of schemas the data conforms to
+func (v *validator) matches(schemas []*jsonschema.Schema, data any, path string) int {
+	count := 0
+	for _, sub := range schemas {
+		if v.validate(sub, data, path) == nil {
+			count++
+		}
+	}
+	return count
+}
+
+// validateObject checks the properties of an object
+func (v *validator) validateObject(schema *jsonschema.Schema, object map[string]any, path string) error {
+	for _, name := range schema.Required {
+		if _, ok := object[name]; !ok {
+			return fmt.Errorf("%s: missing required property %q", path, name)
+		}
+	}
+
+	for name, value := range object {
+		var property *jsonschema.Schema
+		if schema.Properties != nil {
+			property, _ = schema.Properties.Get(name)
+		}
+
+		if property == nil {
+			if schema.AdditionalProperties == nil {
+				continue
+			}
+			property = schema.AdditionalProperties
+			if isBooleanSchema(property, false) {
+				return fmt.Errorf("%s: unexpected property %q", path, name)
+			}
+		}
+
+		if err := v.validate(property, value, path+"."+name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateArray checks the items of an array
+func (v *validator) validateArray(schema *jsonschema.Schema, array []any, path string) error {
+	if schema.MinItems != nil && uint64(len(array)) < *schema.MinItems {
+		return fmt.Errorf("%s: expected at least %d items", path, *schema.MinItems)
+	}
+
+	if schema.MaxItems != nil && uint64(len(array)) > *schema.MaxItems {
+		return fmt.Errorf("%s: expected at most %d items", path, *schema.MaxItems)
+	}
+
+	for i, item := range array {
+		if err := v.validate(schema.Items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolve returns the schema of a local reference
+func (v *validator) resolve(ref string) (*jsonschema.Schema, error) {
+	if ref == "#" {
+		return v.root, nil
+	}
+
+	for _, prefix := range []string{"#/$defs/", "#/definitions/"} {
+		if name, ok := strings.CutPrefix(ref, prefix); ok {
+			if schema, ok := v.root.Definitions[name]; ok {
+				return schema, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("unresolved reference %q", ref)
+}
+
+// validateString checks the length and the pattern of a string
+func validateString(schema *jsonschema.Schema, value, path string) error {
+	length := uint64(utf8.RuneCountInString(value))
+	if schema.MinLength != nil && length < *schema.MinLength {
+		return fmt.Errorf("%s: expected at least %d characters", path, *schema.MinLength)
+	}
+
+	if schema.MaxLength != nil && length > *schema.MaxLength {
+		return fmt.Errorf("%s: expected at most %d characters", path, *schema.MaxLength)
+	}
+
+	if schema.Pattern != "" {
+		pattern, err := regexp.Compile(schema.Pattern)
+		if err != nil {
+			return fmt.Errorf("%s: invalid pattern %q: %v", path, schema.Pattern, err)
+		}
+		if !pattern.MatchString(value) {
+			return fmt.Errorf("%s: does not match the pattern %q", path, schema.Pattern)
+		}
+	}
+	return nil
+}
+
+// validateNumber checks the bounds of a number
+func validateNumber(schema *jsonschema.Schema, value json.Number, path string) error {
+	number, err := value.Float64()
+	if err != nil {
+		return fmt.Errorf("%s: invalid number %s", path, value)
+	}
+
+	if bound, err := schema.Minimum.Float64(); err == nil && number < bound {
+		return fmt.Errorf("%s: expected a minimum of %s", path, schema.Minimum)
+	}
+
+	if bound, err := schema.Maximum.Float64(); err == nil && number > bound {
+		return fmt.Errorf("%s: expected a maximum of %s", path, schema.Maximum)
+	}
+	return nil
+}
+
+// hasType returns true when the data is of the given JSON type
+func hasType(kind string, data any) bool {
+	switch kind {
+	case "integer":
+		number, ok := data.(json.Number)
+		if !ok {
+			return false
+		}
+		value, err := number.Float64()
+		return err == nil && value == math.Trunc(value)
+	case "number":
+		_, ok := data.(json.Number)
+		return ok
+	default:
+		return typeOf(data) == kind
+	}
+}
+
+// typeOf returns the JSON type of the data
+func typeOf(data any) string {
+	switch data.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", data)
+	}
+}
+
+// isBooleanSchema returns true when the schema is the boolean schema of the given value
+func isBooleanSchema(schema *jsonschema.Schema, value bool) bool {
+	// the boolean of a schema is unexported but readable
+	field := reflect.ValueOf(schema).Elem().FieldByName("boolean")
+	return field.IsValid() && !field.IsNil() && field.Elem().Bool() == value
+}
+
+// containsJSON returns true when the data equals one of the values
+func containsJSON(values []any, data any) bool {
+	for _, value := range values {
+		if jsonEqual(value, data) {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonEqual returns true when both values have the same JSON representation
+func jsonEqual(a, b any) bool {
+	left, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+
+	right, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+
+	// decode both sides the same way to ignore the formatting of the numbers
+	var x, y any
+	if json.Unmarshal(left, &x) != nil || json.Unmarshal(right, &y) != nil {
+		return false
+	}
+	return reflect.DeepEqual(x, y)
+}
@@ -0,0 +1,135 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package llm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type address struct {
+	City    string `json:"city"`
+	ZipCode string `json:"zip_code,omitempty" jsonschema:"pattern=^[0-9]{5}$"`
+}
+
+type person struct {
+	Name      string    `json:"name" jsonschema:"minLength=1"`
+	Age       int       `json:"age" jsonschema:"minimum=0"`
+	Role      string    `json:"role" jsonschema:"enum=admin,enum=member"`
+	Addresses []address `json:"addresses"`
+	Nickname  *string   `json:"nickname,omitempty"`
+}
+
+func TestSchemaOf(t *testing.T) {
+	schema := SchemaOf[person]()
+	assert.Equal(t, "person", schema.Name)
+	require.NotNil(t, schema.Definition)
+	assert.Empty(t, schema.Definition.Version)
+	assert.Equal(t, "object", schema.Definition.Type)
+	assert.ElementsMatch(t, []string{"name", "age", "role", "addresses"}, schema.Definition.Required)
+
+	assert.Equal(t, "response", SchemaOf[map[string]any]().Name)
+}
+
+func TestValidateJSON(t *testing.T) {
+	schema := SchemaOf[person]().Definition
+
+	testCases := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{
+			name:    "valid",
+			content: `{"name":"John","age":42,"role":"admin","addresses":[{"city":"Paris","zip_code":"75001"}]}`,
+		},
+		{
+			name:    "invalid JSON",
+			content: `{"name":`,
+			err:     "invalid JSON",
+		},
+		{
+			name:    "trailing data",
+			content: `{"name":"John","age":42,"role":"admin","addresses":[]} {}`,
+			err:     "unexpected data after the top-level value",
+		},
+		{
+			name:    "missing property",
+			content: `{"name":"John","age":42,"role":"admin"}`,
+			err:     `$: missing required property "addresses"`,
+		},
+		{
+			name:    "additional property",
+			content: `{"name":"John","age":42,"role":"admin","addresses":[],"email":"john@example.com"}`,
+			err:     `$: unexpected property "email"`,
+		},
+		{
+			name:    "wrong type",
+			content: `{"name":"John","age":"42","role":"admin","addresses":[]}`,
+			err:     "$.age: expected integer but got string",
+		},
+		{
+			name:    "not an integer",
+			content: `{"name":"John","age":4.2,"role":"admin","addresses":[]}`,
+			err:     "$.age: expected integer but got number",
+		},
+		{
+			name:    "below minimum",
+			content: `{"name":"John","age":-1,"role":"admin","addresses":[]}`,
+			err:     "$.age: expected a minimum of 0",
+		},
+		{
+			name:    "not in enum",
+			content: `{"name":"John","age":42,"role":"owner","addresses":[]}`,
+			err:     "$.role: owner is not one of [admin member]",
+		},
+		{
+			name:    "too short",
+			content: `{"name":"","age":42,"role":"admin","addresses":[]}`,
+			err:     "$.name: expected at least 1 characters",
+		},
+		{
+			name:    "nested violation",
+			content: `{"name":"John","age":42,"role":"admin","addresses":[{"city":"Paris"},{"city":"Lyon","zip_code":"690"}]}`,
+			err:     `$.addresses[1].zip_code: does not match the pattern "^[0-9]{5}$"`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := ValidateJSON(schema, []byte(testCase.content))
+			if testCase.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			assert.ErrorIs(t, err, ErrSchemaMismatch)
+			assert.Contains(t, err.Error(), testCase.err)
+		})
+	}
+}
@@ -0,0 +1,310 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package testkit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/tochemey/gopack/llm"
+	"github.com/tochemey/gopack/llm/openai"
+)
+
+// MockModel is the model reported in the usage of the MockAPI
+const MockModel = "mock"
+
+// Call defines a call made to the MockAPI
+type Call struct {
+	// Method specifies the name of the method called
+	Method string
+	// Requests specifies the messages of a query
+	Requests []*llm.Request
+	// VisionRequests specifies the messages of a vision query
+	VisionRequests []*llm.VisionRequest
+	// Inputs specifies the inputs of an embeddings call
+	Inputs []string
+}
+
+// MockAPI is an in-memory implementation of openai.API answering with scripted replies.
+//
+// The replies are consumed in order by every call, the embeddings calls only using the error of their reply.
+// Without a scripted reply the queries echo the content of the last message and the embeddings are the Vector
+// of every input. A query made with QueryBatch or QueryWithTools consumes one reply per round trip.
+type MockAPI struct {
+	mu      sync.Mutex
+	replies []Reply
+	calls   []*Call
+	usage   openai.UsageSummary
+}
+
+// enforce compilation error
+var _ openai.API = (*MockAPI)(nil)
+
+// NewMockAPI creates a MockAPI answering with the given replies
+func NewMockAPI(replies ...Reply) *MockAPI {
+	return &MockAPI{
+		replies: replies,
+		usage:   openai.UsageSummary{Models: make(map[string]openai.UsageTotals)},
+	}
+}
+
+// Enqueue appends replies to the script
+func (m *MockAPI) Enqueue(replies ...Reply) {
+	m.mu.Lock()
+	m.replies = append(m.replies, replies...)
+	m.mu.Unlock()
+}
+
+// Calls returns the calls made so far
+func (m *MockAPI) Calls() []*Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*Call(nil), m.calls...)
+}
+
+// Query answers with the next reply
+func (m *MockAPI) Query(ctx context.Context, requests []*llm.Request, _ llm.ResponseType) ([]*llm.Response, error) {
+	reply, err := m.next(ctx, &Call{Method: "Query", Requests: requests}, echo(requests))
+	if err != nil {
+		return nil, err
+	}
+	return toResponses(reply), nil
+}
+
+// QueryStream streams the chunks of the next reply
+func (m *MockAPI) QueryStream(ctx context.Context, requests []*llm.Request, _ llm.ResponseType) (<-chan *llm.StreamDelta, error) {
+	reply, err := m.next(ctx, &Call{Method: "QueryStream", Requests: requests}, echo(requests))
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := reply.chunks()
+	out := make(chan *llm.StreamDelta, len(chunks)+1)
+	for i, content := range chunks {
+		delta := &llm.StreamDelta{Content: content}
+		if i == len(chunks)-1 {
+			delta.FinishReason = "stop"
+		}
+		out <- delta
+	}
+
+	usage := reply.Usage
+	out <- &llm.StreamDelta{Usage: &usage}
+	close(out)
+	return out, nil
+}
+
+// QueryWithTools answers with the next replies, running the tool calls they request with the executor
+// until a reply holds no tool call
+func (m *MockAPI) QueryWithTools(ctx context.Context, requests []*llm.Request, responseType llm.ResponseType, executor openai.ToolExecutor) ([]*llm.Response, []*llm.Request, error) {
+	conversation := append([]*llm.Request(nil), requests...)
+	for range openai.DefaultMaxToolIterations {
+		responses, err := m.Query(ctx, conversation, responseType)
+		if err != nil {
+			return nil, conversation, err
+		}
+
+		calls := responses[0].ToolCalls
+		if len(calls) == 0 {
+			return responses, conversation, nil
+		}
+
+		conversation = append(conversation, &llm.Request{
+			Type:      llm.AssistantMessage,
+			Content:   responses[0].Content,
+			ToolCalls: calls,
+		})
+
+		for _, call := range calls {
+			result, err := executor.Execute(ctx, call)
+			if err != nil {
+				result = fmt.Sprintf("error: %v", err)
+			}
+
+			conversation = append(conversation, &llm.Request{
+				Type:       llm.ToolMessage,
+				Content:    result,
+				ToolCallID: call.ID,
+			})
+		}
+	}
+	return nil, conversation, fmt.Errorf("no final answer after %d tool iterations", openai.DefaultMaxToolIterations)
+}
+
+// QuerySchema validates the content of the next reply against the schema and decodes it into out
+func (m *MockAPI) QuerySchema(ctx context.Context, requests []*llm.Request, schema *llm.Schema, out any) error {
+	if schema == nil || schema.Definition == nil {
+		return errors.New("the schema definition is required")
+	}
+
+	reply, err := m.next(ctx, &Call{Method: "QuerySchema", Requests: requests}, echo(requests))
+	if err != nil {
+		return err
+	}
+
+	if err := llm.ValidateJSON(schema.Definition, []byte(reply.Content)); err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(reply.Content), out)
+}
+
+// Usage returns the calls made so far and the tokens of their replies
+func (m *MockAPI) Usage() openai.UsageSummary {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	summary := openai.UsageSummary{UsageTotals: m.usage.UsageTotals, Models: make(map[string]openai.UsageTotals, len(m.usage.Models))}
+	for model, totals := range m.usage.Models {
+		summary.Models[model] = totals
+	}
+	return summary
+}
+
+// QueryBatch queries every batch in turn and returns their results in order.
+// The options are ignored and the calls are recorded as Query calls.
+func (m *MockAPI) QueryBatch(ctx context.Context, batches [][]*llm.Request, _ ...openai.BatchOption) ([]*openai.BatchResult, error) {
+	results := make([]*openai.BatchResult, len(batches))
+	var errs []error
+	for index, batch := range batches {
+		responses, err := m.Query(ctx, batch, llm.TextResponseType)
+		results[index] = &openai.BatchResult{Responses: responses, Err: err}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("batch %d: %w", index, err))
+		}
+	}
+	return results, errors.Join(errs...)
+}
+
+// CountTokens estimates the tokens of the messages with llm.ApproximateTokens
+func (m *MockAPI) CountTokens(requests []*llm.Request) (int, error) {
+	return llm.ApproximateTokens.CountTokens(requests)
+}
+
+// VisionQuery answers with the next reply
+func (m *MockAPI) VisionQuery(ctx context.Context, requests ...*llm.VisionRequest) ([]*llm.Response, error) {
+	var fallback Reply
+	for _, request := range requests {
+		if !request.HasImage() {
+			fallback = Text(request.Content)
+		}
+	}
+
+	reply, err := m.next(ctx, &Call{Method: "VisionQuery", VisionRequests: requests}, fallback)
+	if err != nil {
+		return nil, err
+	}
+	return toResponses(reply), nil
+}
+
+// Embeddings returns the Vector of every input
+func (m *MockAPI) Embeddings(ctx context.Context, inputs []string) ([][]float32, error) {
+	return m.embed(ctx, "Embeddings", inputs)
+}
+
+// Embed returns the Vector of every input whatever the model
+func (m *MockAPI) Embed(ctx context.Context, inputs []string, _ string) ([][]float32, error) {
+	return m.embed(ctx, "Embed", inputs)
+}
+
+// embed records the embeddings call and returns the Vector of every input
+func (m *MockAPI) embed(ctx context.Context, method string, inputs []string) ([][]float32, error) {
+	if _, err := m.next(ctx, &Call{Method: method, Inputs: inputs}, Reply{}); err != nil {
+		return nil, err
+	}
+
+	embeddings := make([][]float32, len(inputs))
+	for i, input := range inputs {
+		embeddings[i] = Vector(input)
+	}
+	return embeddings, nil
+}
+
+// next records the call and returns the next reply, or the fallback when none is scripted.
+// It waits for the delay of the reply and returns its error when it failed.
+func (m *MockAPI) next(ctx context.Context, call *Call, fallback Reply) (Reply, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, call)
+	reply := fallback
+	if len(m.replies) > 0 {
+		reply = m.replies[0]
+		m.replies = m.replies[1:]
+	}
+	m.mu.Unlock()
+
+	err := sleep(ctx, reply.Delay)
+	if err == nil && reply.failed() {
+		err = reply.error()
+	}
+
+	m.mu.Lock()
+	m.usage.UsageTotals = addUsage(m.usage.UsageTotals, reply, err)
+	m.usage.Models[MockModel] = addUsage(m.usage.Models[MockModel], reply, err)
+	m.mu.Unlock()
+	return reply, err
+}
+
+// addUsage adds the usage of a call answered with the reply to the totals
+func addUsage(totals openai.UsageTotals, reply Reply, err error) openai.UsageTotals {
+	totals.Calls++
+	if err != nil {
+		totals.Errors++
+	}
+	totals.PromptTokens += int64(reply.Usage.PromptTokens)
+	totals.CompletionTokens += int64(reply.Usage.CompletionTokens)
+	totals.TotalTokens += int64(reply.Usage.TotalTokens)
+	totals.Latency += reply.Delay
+	return totals
+}
+
+// sleep waits for the given duration unless the context is done first
+func sleep(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// toResponses converts the reply to the responses of a query
+func toResponses(reply Reply) []*llm.Response {
+	return []*llm.Response{{
+		Content:          reply.Content,
+		ToolCalls:        reply.ToolCalls,
+		PromptTokens:     reply.Usage.PromptTokens,
+		CompletionTokens: reply.Usage.CompletionTokens,
+		TotalTokens:      reply.Usage.TotalTokens,
+	}}
+}
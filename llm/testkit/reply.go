@@ -0,0 +1,140 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+// Package testkit provides deterministic doubles of the OpenAI backend to unit test the code using the llm packages
+// without network calls: a fake OpenAI-compatible HTTP server to point the real client at and an in-memory MockAPI
+// implementing openai.API. Both answer with scripted replies consumed in order.
+package testkit
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/tochemey/gopack/llm"
+)
+
+// EmbeddingDimensions is the number of dimensions of the embedding vectors returned by the doubles
+const EmbeddingDimensions = 8
+
+// Reply defines the scripted answer to a call
+type Reply struct {
+	// Content specifies the content of the response
+	Content string
+	// ToolCalls specifies the tool calls requested instead of a final answer
+	ToolCalls []*llm.ToolCall
+	// Chunks specifies the chunks of a streamed response. The Content is streamed as a single chunk when empty.
+	Chunks []string
+	// Usage specifies the tokens reported for the call
+	Usage llm.Usage
+	// Status specifies the HTTP status code of a failed call
+	Status int
+	// Message specifies the error message of a failed call
+	Message string
+	// RetryAfter specifies the Retry-After header of a failed call
+	RetryAfter time.Duration
+	// Delay specifies how long to wait before answering
+	Delay time.Duration
+	// Err specifies the error returned by the MockAPI. The Status is used by the Server.
+	Err error
+}
+
+// Text returns a reply with the given content
+func Text(content string) Reply {
+	return Reply{Content: content}
+}
+
+// ToolCalls returns a reply requesting the given tool calls
+func ToolCalls(calls ...*llm.ToolCall) Reply {
+	return Reply{ToolCalls: calls}
+}
+
+// Stream returns a reply streamed in the given chunks
+func Stream(chunks ...string) Reply {
+	return Reply{Chunks: chunks}
+}
+
+// Error returns a reply failing with the given HTTP status code and message
+func Error(status int, message string) Reply {
+	return Reply{Status: status, Message: message}
+}
+
+// RateLimited returns a reply failing with a 429 status code asking to retry after the given duration
+func RateLimited(retryAfter time.Duration) Reply {
+	return Reply{Status: http.StatusTooManyRequests, Message: "rate limit exceeded", RetryAfter: retryAfter}
+}
+
+// ServerError returns a reply failing with a 500 status code
+func ServerError() Reply {
+	return Error(http.StatusInternalServerError, "internal server error")
+}
+
+// failed returns true when the reply is an error
+func (r Reply) failed() bool {
+	return r.Err != nil || r.Status >= http.StatusBadRequest
+}
+
+// error returns the error of a failed reply
+func (r Reply) error() error {
+	if r.Err != nil {
+		return r.Err
+	}
+	return fmt.Errorf("status code %d: %s", r.Status, r.Message)
+}
+
+// chunks returns the chunks of the reply when streamed
+func (r Reply) chunks() []string {
+	if len(r.Chunks) == 0 {
+		return []string{r.Content}
+	}
+	return r.Chunks
+}
+
+// echo returns the reply used when no reply is scripted: the content of the last message
+func echo(requests []*llm.Request) Reply {
+	if len(requests) == 0 {
+		return Reply{}
+	}
+	return Text(requests[len(requests)-1].Content)
+}
+
+// Vector returns the deterministic unit embedding vector of the input
+func Vector(input string) []float32 {
+	vector := make([]float32, EmbeddingDimensions)
+	var norm float64
+	for i := range vector {
+		hash := fnv.New32a()
+		_, _ = fmt.Fprintf(hash, "%d:%s", i, input)
+		vector[i] = float32(hash.Sum32())/math.MaxUint32*2 - 1
+		norm += float64(vector[i]) * float64(vector[i])
+	}
+
+	norm = math.Sqrt(norm)
+	for i := range vector {
+		vector[i] = float32(float64(vector[i]) / norm)
+	}
+	return vector
+}
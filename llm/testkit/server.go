@@ -0,0 +1,298 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package testkit
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+
+	"github.com/tochemey/gopack/llm"
+)
+
+// ServerRequest defines a request received by the Server
+type ServerRequest struct {
+	// Path specifies the path of the endpoint called
+	Path string
+	// Model specifies the requested model
+	Model string
+	// Stream specifies whether a streamed response was requested
+	Stream bool
+	// Messages specifies the messages of a chat completion
+	Messages []*llm.Request
+	// Inputs specifies the inputs of an embeddings request
+	Inputs []string
+}
+
+// Server is a fake OpenAI-compatible HTTP server serving the chat completions, streamed or not, and the
+// embeddings endpoints. Point the client at it by setting its URL as the base url of the openai.Config.
+//
+// The scripted replies are consumed in order by the requests of both endpoints, the embeddings requests only
+// using the error of their reply. Without a scripted reply the chat completions echo the content of the last
+// message and the embeddings are the Vector of every input.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	replies  []Reply
+	requests []*ServerRequest
+}
+
+// NewServer creates and starts a Server answering with the given replies. Close it once done.
+func NewServer(replies ...Reply) *Server {
+	server := &Server{replies: replies}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /chat/completions", server.chatCompletions)
+	mux.HandleFunc("POST /embeddings", server.embeddings)
+	server.Server = httptest.NewServer(mux)
+	return server
+}
+
+// Enqueue appends replies to the script
+func (s *Server) Enqueue(replies ...Reply) {
+	s.mu.Lock()
+	s.replies = append(s.replies, replies...)
+	s.mu.Unlock()
+}
+
+// Requests returns the requests received so far
+func (s *Server) Requests() []*ServerRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*ServerRequest(nil), s.requests...)
+}
+
+// next records the request and returns the next scripted reply, if any
+func (s *Server) next(request *ServerRequest) (Reply, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, request)
+	if len(s.replies) == 0 {
+		return Reply{}, false
+	}
+
+	reply := s.replies[0]
+	s.replies = s.replies[1:]
+	return reply, true
+}
+
+// chatRequest holds the fields of the chat completion requests used by the Server
+type chatRequest struct {
+	Model    string                         `json:"model"`
+	Messages []openai.ChatCompletionMessage `json:"messages"`
+	Stream   bool                           `json:"stream"`
+}
+
+func (s *Server) chatCompletions(w http.ResponseWriter, r *http.Request) {
+	var req chatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, Error(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	request := &ServerRequest{Path: r.URL.Path, Model: req.Model, Stream: req.Stream, Messages: toRequests(req.Messages)}
+	reply, ok := s.next(request)
+	if !ok {
+		reply = echo(request.Messages)
+	}
+
+	time.Sleep(reply.Delay)
+	if reply.Status >= http.StatusBadRequest {
+		writeError(w, reply)
+		return
+	}
+
+	if req.Stream {
+		writeStream(w, req.Model, reply)
+		return
+	}
+
+	message := openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: reply.Content}
+	finishReason := openai.FinishReasonStop
+	for _, call := range reply.ToolCalls {
+		message.ToolCalls = append(message.ToolCalls, openai.ToolCall{
+			ID:       call.ID,
+			Type:     openai.ToolTypeFunction,
+			Function: openai.FunctionCall{Name: call.Name, Arguments: call.Arguments},
+		})
+		finishReason = openai.FinishReasonToolCalls
+	}
+
+	writeJSON(w, openai.ChatCompletionResponse{
+		ID:      "chatcmpl-testkit",
+		Object:  "chat.completion",
+		Model:   req.Model,
+		Choices: []openai.ChatCompletionChoice{{Message: message, FinishReason: finishReason}},
+		Usage:   toOpenAIUsage(reply.Usage),
+	})
+}
+
+// embeddingsRequest holds the fields of the embeddings requests used by the Server
+type embeddingsRequest struct {
+	Model string          `json:"model"`
+	Input json.RawMessage `json:"input"`
+}
+
+func (s *Server) embeddings(w http.ResponseWriter, r *http.Request) {
+	var req embeddingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, Error(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	// the input is either a string or a list of strings
+	var inputs []string
+	if err := json.Unmarshal(req.Input, &inputs); err != nil {
+		var input string
+		if err := json.Unmarshal(req.Input, &input); err != nil {
+			writeError(w, Error(http.StatusBadRequest, "invalid input"))
+			return
+		}
+		inputs = []string{input}
+	}
+
+	reply, _ := s.next(&ServerRequest{Path: r.URL.Path, Model: req.Model, Inputs: inputs})
+	time.Sleep(reply.Delay)
+	if reply.Status >= http.StatusBadRequest {
+		writeError(w, reply)
+		return
+	}
+
+	response := openai.EmbeddingResponse{
+		Object: "list",
+		Model:  openai.EmbeddingModel(req.Model),
+		Usage:  toOpenAIUsage(reply.Usage),
+	}
+	for i, input := range inputs {
+		response.Data = append(response.Data, openai.Embedding{Object: "embedding", Embedding: Vector(input), Index: i})
+	}
+	writeJSON(w, response)
+}
+
+// writeStream writes the reply as server-sent chat completion chunks followed by the usage chunk
+func writeStream(w http.ResponseWriter, model string, reply Reply) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	flusher, _ := w.(http.Flusher)
+	write := func(chunk openai.ChatCompletionStreamResponse) {
+		chunk.ID, chunk.Object, chunk.Model = "chatcmpl-testkit", "chat.completion.chunk", model
+		data, _ := json.Marshal(chunk)
+		_, _ = fmt.Fprintf(w, "data: %s\n\n", data)
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	for _, content := range reply.chunks() {
+		write(openai.ChatCompletionStreamResponse{
+			Choices: []openai.ChatCompletionStreamChoice{{Delta: openai.ChatCompletionStreamChoiceDelta{Content: content}}},
+		})
+	}
+
+	write(openai.ChatCompletionStreamResponse{
+		Choices: []openai.ChatCompletionStreamChoice{{FinishReason: openai.FinishReasonStop}},
+	})
+
+	usage := toOpenAIUsage(reply.Usage)
+	write(openai.ChatCompletionStreamResponse{Choices: []openai.ChatCompletionStreamChoice{}, Usage: &usage})
+	_, _ = fmt.Fprint(w, "data: [DONE]\n\n")
+}
+
+// writeError writes the error of a failed reply in the OpenAI format
+func writeError(w http.ResponseWriter, reply Reply) {
+	if reply.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(reply.RetryAfter.Seconds())))
+	}
+
+	errorType := "invalid_request_error"
+	switch {
+	case reply.Status == http.StatusTooManyRequests:
+		errorType = "rate_limit_exceeded"
+	case reply.Status >= http.StatusInternalServerError:
+		errorType = "server_error"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(reply.Status)
+	_ = json.NewEncoder(w).Encode(openai.ErrorResponse{
+		Error: &openai.APIError{Message: reply.Message, Type: errorType, Code: errorType},
+	})
+}
+
+// writeJSON writes the response as JSON
+func writeJSON(w http.ResponseWriter, response any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// toRequests converts the chat completion messages to llm requests
+func toRequests(messages []openai.ChatCompletionMessage) []*llm.Request {
+	requests := make([]*llm.Request, 0, len(messages))
+	for _, message := range messages {
+		request := &llm.Request{Content: message.Content, ToolCallID: message.ToolCallID}
+		switch message.Role {
+		case openai.ChatMessageRoleSystem:
+			request.Type = llm.SystemMessage
+		case openai.ChatMessageRoleAssistant:
+			request.Type = llm.AssistantMessage
+		case openai.ChatMessageRoleTool:
+			request.Type = llm.ToolMessage
+		default:
+			request.Type = llm.UserMessage
+		}
+
+		// the vision messages carry their texts in parts
+		for _, part := range message.MultiContent {
+			if part.Type == openai.ChatMessagePartTypeText {
+				request.Content += part.Text
+			}
+		}
+
+		for _, call := range message.ToolCalls {
+			request.ToolCalls = append(request.ToolCalls, &llm.ToolCall{
+				ID:        call.ID,
+				Name:      call.Function.Name,
+				Arguments: call.Function.Arguments,
+			})
+		}
+		requests = append(requests, request)
+	}
+	return requests
+}
+
+// toOpenAIUsage converts the usage of a reply
+func toOpenAIUsage(usage llm.Usage) openai.Usage {
+	return openai.Usage{
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		TotalTokens:      usage.TotalTokens,
+	}
+}
@@ -0,0 +1,286 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package testkit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	goopenai "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tochemey/gopack/llm"
+	"github.com/tochemey/gopack/llm/openai"
+)
+
+// newClient creates a go-openai client calling the server
+func newClient(server *Server) *goopenai.Client {
+	config := goopenai.DefaultConfig("secret")
+	config.BaseURL = server.URL
+	return goopenai.NewClientWithConfig(config)
+}
+
+// chat returns a chat completion request with a single user message
+func chat(content string) goopenai.ChatCompletionRequest {
+	return goopenai.ChatCompletionRequest{
+		Model:    "gpt-4o",
+		Messages: []goopenai.ChatCompletionMessage{{Role: goopenai.ChatMessageRoleUser, Content: content}},
+	}
+}
+
+func TestServer(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("with canned responses", func(t *testing.T) {
+		server := NewServer(Reply{Content: "hi", Usage: llm.Usage{PromptTokens: 3, CompletionTokens: 1, TotalTokens: 4}})
+		defer server.Close()
+		client := newClient(server)
+
+		response, err := client.CreateChatCompletion(ctx, chat("hello"))
+		require.NoError(t, err)
+		assert.Equal(t, "hi", response.Choices[0].Message.Content)
+		assert.Equal(t, 4, response.Usage.TotalTokens)
+
+		// without a scripted reply the last message is echoed
+		response, err = client.CreateChatCompletion(ctx, chat("again"))
+		require.NoError(t, err)
+		assert.Equal(t, "again", response.Choices[0].Message.Content)
+
+		requests := server.Requests()
+		require.Len(t, requests, 2)
+		assert.Equal(t, "/chat/completions", requests[0].Path)
+		assert.Equal(t, "gpt-4o", requests[0].Model)
+		assert.Equal(t, []*llm.Request{{Type: llm.UserMessage, Content: "hello"}}, requests[0].Messages)
+	})
+	t.Run("with tool calls", func(t *testing.T) {
+		server := NewServer(ToolCalls(&llm.ToolCall{ID: "call", Name: "weather", Arguments: `{"city":"Accra"}`}))
+		defer server.Close()
+
+		response, err := newClient(server).CreateChatCompletion(ctx, chat("weather?"))
+		require.NoError(t, err)
+		assert.Equal(t, goopenai.FinishReasonToolCalls, response.Choices[0].FinishReason)
+		require.Len(t, response.Choices[0].Message.ToolCalls, 1)
+		assert.Equal(t, "weather", response.Choices[0].Message.ToolCalls[0].Function.Name)
+	})
+	t.Run("with streamed chunks", func(t *testing.T) {
+		server := NewServer(Reply{Chunks: []string{"Hel", "lo"}, Usage: llm.Usage{TotalTokens: 7}})
+		defer server.Close()
+
+		request := chat("hello")
+		request.Stream = true
+		request.StreamOptions = &goopenai.StreamOptions{IncludeUsage: true}
+		stream, err := newClient(server).CreateChatCompletionStream(ctx, request)
+		require.NoError(t, err)
+		defer stream.Close()
+
+		var (
+			content string
+			usage   *goopenai.Usage
+		)
+		for {
+			chunk, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err)
+			for _, choice := range chunk.Choices {
+				content += choice.Delta.Content
+			}
+			if chunk.Usage != nil {
+				usage = chunk.Usage
+			}
+		}
+
+		assert.Equal(t, "Hello", content)
+		require.NotNil(t, usage)
+		assert.Equal(t, 7, usage.TotalTokens)
+		assert.True(t, server.Requests()[0].Stream)
+	})
+	t.Run("with injected errors", func(t *testing.T) {
+		server := NewServer(RateLimited(2*time.Second), ServerError())
+		defer server.Close()
+		client := newClient(server)
+
+		_, err := client.CreateChatCompletion(ctx, chat("hello"))
+		var apiErr *goopenai.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusTooManyRequests, apiErr.HTTPStatusCode)
+		assert.Equal(t, "rate limit exceeded", apiErr.Message)
+
+		_, err = client.CreateChatCompletionStream(ctx, chat("hello"))
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusInternalServerError, apiErr.HTTPStatusCode)
+	})
+	t.Run("with embeddings", func(t *testing.T) {
+		server := NewServer()
+		defer server.Close()
+
+		response, err := newClient(server).CreateEmbeddings(ctx, goopenai.EmbeddingRequestStrings{
+			Input: []string{"hello", "world"},
+			Model: goopenai.SmallEmbedding3,
+		})
+		require.NoError(t, err)
+		require.Len(t, response.Data, 2)
+		assert.Equal(t, Vector("world"), response.Data[1].Embedding)
+		assert.Equal(t, []string{"hello", "world"}, server.Requests()[0].Inputs)
+	})
+}
+
+func TestVector(t *testing.T) {
+	assert.Equal(t, Vector("hello"), Vector("hello"))
+	assert.NotEqual(t, Vector("hello"), Vector("world"))
+	assert.Len(t, Vector("hello"), EmbeddingDimensions)
+
+	var norm float32
+	for _, value := range Vector("hello") {
+		norm += value * value
+	}
+	assert.InDelta(t, 1, norm, 1e-5)
+}
+
+func TestMockAPI(t *testing.T) {
+	ctx := context.Background()
+	requests := []*llm.Request{{Type: llm.UserMessage, Content: "hello"}}
+
+	t.Run("with scripted replies", func(t *testing.T) {
+		mock := NewMockAPI(Reply{Content: "hi", Usage: llm.Usage{PromptTokens: 3, CompletionTokens: 1, TotalTokens: 4}})
+
+		responses, err := mock.Query(ctx, requests, llm.TextResponseType)
+		require.NoError(t, err)
+		assert.Equal(t, "hi", responses[0].Content)
+
+		responses, err = mock.Query(ctx, requests, llm.TextResponseType)
+		require.NoError(t, err)
+		assert.Equal(t, "hello", responses[0].Content)
+
+		mock.Enqueue(Error(http.StatusBadRequest, "invalid request"))
+		_, err = mock.Query(ctx, requests, llm.TextResponseType)
+		assert.EqualError(t, err, "status code 400: invalid request")
+
+		assert.Len(t, mock.Calls(), 3)
+		assert.Equal(t, "Query", mock.Calls()[0].Method)
+		assert.Equal(t, requests, mock.Calls()[0].Requests)
+
+		usage := mock.Usage()
+		assert.EqualValues(t, 3, usage.Calls)
+		assert.EqualValues(t, 1, usage.Errors)
+		assert.EqualValues(t, 4, usage.Models[MockModel].TotalTokens)
+	})
+	t.Run("with streamed chunks", func(t *testing.T) {
+		mock := NewMockAPI(Stream("Hel", "lo"), Reply{Err: errors.New("boom")})
+
+		deltas, err := mock.QueryStream(ctx, requests, llm.TextResponseType)
+		require.NoError(t, err)
+
+		var received []*llm.StreamDelta
+		for delta := range deltas {
+			received = append(received, delta)
+		}
+		assert.Equal(t, []*llm.StreamDelta{
+			{Content: "Hel"},
+			{Content: "lo", FinishReason: "stop"},
+			{Usage: &llm.Usage{}},
+		}, received)
+
+		_, err = mock.QueryStream(ctx, requests, llm.TextResponseType)
+		assert.EqualError(t, err, "boom")
+	})
+	t.Run("with tool calls", func(t *testing.T) {
+		mock := NewMockAPI(ToolCalls(&llm.ToolCall{ID: "call", Name: "weather"}), Text("sunny in Accra"))
+		executor := openai.ToolExecutorFunc(func(_ context.Context, call *llm.ToolCall) (string, error) {
+			return "sunny", nil
+		})
+
+		responses, conversation, err := mock.QueryWithTools(ctx, requests, llm.TextResponseType, executor)
+		require.NoError(t, err)
+		assert.Equal(t, "sunny in Accra", responses[0].Content)
+		require.Len(t, conversation, 3)
+		assert.Equal(t, &llm.Request{Type: llm.ToolMessage, Content: "sunny", ToolCallID: "call"}, conversation[2])
+	})
+	t.Run("with structured responses", func(t *testing.T) {
+		type answer struct {
+			City string `json:"city"`
+		}
+
+		mock := NewMockAPI(Text(`{"city":"Accra"}`), Text(`{"town":"Accra"}`))
+		out, err := openai.QueryStructured[answer](ctx, mock, requests, llm.SchemaOf[answer]())
+		require.NoError(t, err)
+		assert.Equal(t, "Accra", out.City)
+
+		_, err = openai.QueryStructured[answer](ctx, mock, requests, llm.SchemaOf[answer]())
+		assert.ErrorIs(t, err, llm.ErrSchemaMismatch)
+	})
+	t.Run("with batches", func(t *testing.T) {
+		mock := NewMockAPI(Text("first"), ServerError())
+		results, err := mock.QueryBatch(ctx, [][]*llm.Request{requests, requests, requests})
+		assert.ErrorContains(t, err, "batch 1:")
+		require.Len(t, results, 3)
+		assert.Equal(t, "first", results[0].Responses[0].Content)
+		assert.Error(t, results[1].Err)
+		assert.Equal(t, "hello", results[2].Responses[0].Content)
+	})
+	t.Run("with vision queries", func(t *testing.T) {
+		mock := NewMockAPI()
+		responses, err := mock.VisionQuery(ctx,
+			&llm.VisionRequest{Content: "describe"},
+			&llm.VisionRequest{ImageURL: "https://example.com/cat.png"},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, "describe", responses[0].Content)
+		assert.Len(t, mock.Calls()[0].VisionRequests, 2)
+	})
+	t.Run("with embeddings", func(t *testing.T) {
+		mock := NewMockAPI(Reply{}, RateLimited(time.Second))
+
+		embeddings, err := mock.Embeddings(ctx, []string{"hello", "world"})
+		require.NoError(t, err)
+		assert.Equal(t, [][]float32{Vector("hello"), Vector("world")}, embeddings)
+
+		_, err = mock.Embed(ctx, []string{"hello"}, "model")
+		assert.Error(t, err)
+		assert.Equal(t, []string{"hello"}, mock.Calls()[1].Inputs)
+	})
+	t.Run("with a delay", func(t *testing.T) {
+		mock := NewMockAPI(Reply{Content: "late", Delay: time.Second})
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+
+		_, err := mock.Query(ctx, requests, llm.TextResponseType)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+	t.Run("with a conversation", func(t *testing.T) {
+		mock := NewMockAPI(Text("hi"))
+		conversation := llm.NewConversation(mock)
+
+		_, err := conversation.Query(ctx, mock, llm.TextResponseType, requests...)
+		require.NoError(t, err)
+		assert.Len(t, conversation.Messages(), 2)
+	})
+}
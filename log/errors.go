@@ -0,0 +1,93 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package log
+
+import (
+	"errors"
+	"fmt"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+// the error chain fields names
+const (
+	FieldErrorCauses = "error_causes"
+	FieldErrorStack  = "error_stack"
+)
+
+// stackTracer is implemented by the errors carrying a stack trace, e.g. the ones of github.com/pkg/errors
+type stackTracer interface {
+	StackTrace() pkgerrors.StackTrace
+}
+
+// ErrorFields returns the fields describing the chain of the given error: the message
+// of every wrapped or joined cause, and the stack trace of the innermost error carrying one
+func ErrorFields(err error) []Field {
+	if err == nil {
+		return nil
+	}
+
+	var (
+		causes []string
+		stack  pkgerrors.StackTrace
+	)
+	var walk func(err error)
+	walk = func(err error) {
+		if tracer, ok := err.(stackTracer); ok {
+			stack = tracer.StackTrace()
+		}
+
+		var wrapped []error
+		switch e := err.(type) {
+		case interface{ Unwrap() []error }:
+			wrapped = e.Unwrap()
+		default:
+			if cause := errors.Unwrap(err); cause != nil {
+				wrapped = []error{cause}
+			}
+		}
+
+		for _, cause := range wrapped {
+			if cause == nil {
+				continue
+			}
+			// skip the wrappers only adding a stack trace
+			if message := cause.Error(); message != err.Error() {
+				causes = append(causes, message)
+			}
+			walk(cause)
+		}
+	}
+	walk(err)
+
+	var fields []Field
+	if len(causes) > 0 {
+		fields = append(fields, Any(FieldErrorCauses, causes))
+	}
+	if stack != nil {
+		fields = append(fields, String(FieldErrorStack, fmt.Sprintf("%+v", stack)))
+	}
+	return fields
+}
@@ -0,0 +1,76 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package log
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	pkgerrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/multierr"
+)
+
+// fieldsByKey returns the values of the fields by key
+func fieldsByKey(fields []Field) map[string]any {
+	out := make(map[string]any, len(fields))
+	for _, field := range fields {
+		out[field.Key] = field.Value
+	}
+	return out
+}
+
+func TestErrorFields(t *testing.T) {
+	t.Run("With a nil error", func(t *testing.T) {
+		assert.Empty(t, ErrorFields(nil))
+	})
+	t.Run("With a plain error", func(t *testing.T) {
+		assert.Empty(t, ErrorFields(errors.New("failed")))
+	})
+	t.Run("With wrapped errors", func(t *testing.T) {
+		root := errors.New("connection refused")
+		err := fmt.Errorf("consume: %w", fmt.Errorf("dial: %w", root))
+
+		fields := fieldsByKey(ErrorFields(err))
+		assert.Equal(t, []string{"dial: connection refused", "connection refused"}, fields[FieldErrorCauses])
+		assert.NotContains(t, fields, FieldErrorStack)
+	})
+	t.Run("With joined errors", func(t *testing.T) {
+		err := multierr.Append(errors.New("first"), fmt.Errorf("second: %w", errors.New("cause")))
+
+		fields := fieldsByKey(ErrorFields(err))
+		assert.Equal(t, []string{"first", "second: cause", "cause"}, fields[FieldErrorCauses])
+	})
+	t.Run("With a stack trace", func(t *testing.T) {
+		err := pkgerrors.Wrap(pkgerrors.New("connection refused"), "consume")
+
+		fields := fieldsByKey(ErrorFields(err))
+		assert.Equal(t, []string{"connection refused"}, fields[FieldErrorCauses])
+		require.Contains(t, fields, FieldErrorStack)
+		assert.Contains(t, fields[FieldErrorStack], "TestErrorFields")
+	})
+}
@@ -57,6 +57,19 @@ type Logger interface {
 	Debug(...any)
 	// Debugf starts a new message with debug level.
 	Debugf(string, ...any)
+	// Debugw starts a new message with debug level and the given key-value pairs.
+	Debugw(msg string, keysAndValues ...any)
+	// Infow starts a new message with info level and the given key-value pairs.
+	Infow(msg string, keysAndValues ...any)
+	// Warnw starts a new message with warn level and the given key-value pairs.
+	Warnw(msg string, keysAndValues ...any)
+	// Errorw starts a new message with error level and the given key-value pairs.
+	Errorw(msg string, keysAndValues ...any)
+	// Errors starts a new message with error level holding the message of the given error,
+	// with the causes of its chain and its stack trace as structured fields. See ErrorFields.
+	Errors(err error)
+	// With returns a child logger adding the given fields to its messages
+	With(fields ...Field) Logger
 	// LogLevel returns the log level being used
 	LogLevel() Level
 	// WithContext returns a context logger
@@ -0,0 +1,233 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package log
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/tochemey/gopack/clock"
+)
+
+// RateLimitedLogger is a Logger writing at most a given number of logs per key
+// within every interval, the extra ones being dropped. This keeps hot error paths
+// from flooding the output.
+//
+// The key of a log is its level and format, or its message for the other methods, unless a fixed key is set with WithKey. The fatal and panic logs are never dropped.
+type RateLimitedLogger struct {
+	Logger
+	key     string
+	windows *rateWindows
+}
+
+// enforce compilation error
+var _ Logger = (*RateLimitedLogger)(nil)
+
+// NewRateLimitedLogger creates a RateLimitedLogger writing at most limit logs
+// per key and per interval to the given logger
+func NewRateLimitedLogger(logger Logger, limit int, interval time.Duration) *RateLimitedLogger {
+	return NewRateLimitedLoggerWithClock(logger, limit, interval, clock.New())
+}
+
+// NewRateLimitedLoggerWithClock creates a RateLimitedLogger measuring the intervals with the given clock
+func NewRateLimitedLoggerWithClock(logger Logger, limit int, interval time.Duration, clock clock.Clock) *RateLimitedLogger {
+	return &RateLimitedLogger{
+		Logger: logger,
+		windows: &rateWindows{
+			limit:    limit,
+			interval: interval,
+			clock:    clock,
+			windows:  make(map[string]*rateWindow),
+		},
+	}
+}
+
+// WithKey returns a Logger rate limiting all its logs under the given key.
+// The returned Logger shares the limits of the receiver.
+func (l *RateLimitedLogger) WithKey(key string) *RateLimitedLogger {
+	return &RateLimitedLogger{Logger: l.Logger, key: key, windows: l.windows}
+}
+
+// WithContext returns a context logger sharing the limits of the receiver
+func (l *RateLimitedLogger) WithContext(ctx context.Context) Logger {
+	return &RateLimitedLogger{Logger: l.Logger.WithContext(ctx), key: l.key, windows: l.windows}
+}
+
+// With returns a child logger adding the given fields to its messages and sharing the limits of the receiver
+func (l *RateLimitedLogger) With(fields ...Field) Logger {
+	return &RateLimitedLogger{Logger: l.Logger.With(fields...), key: l.key, windows: l.windows}
+}
+
+// Info starts a new message with info level.
+func (l *RateLimitedLogger) Info(v ...any) {
+	if l.allow(InfoLevel, fmt.Sprint(v...)) {
+		l.Logger.Info(v...)
+	}
+}
+
+// Infof starts a new message with info level.
+func (l *RateLimitedLogger) Infof(format string, v ...any) {
+	if l.allow(InfoLevel, format) {
+		l.Logger.Infof(format, v...)
+	}
+}
+
+// Warn starts a new message with warn level.
+func (l *RateLimitedLogger) Warn(v ...any) {
+	if l.allow(WarningLevel, fmt.Sprint(v...)) {
+		l.Logger.Warn(v...)
+	}
+}
+
+// Warnf starts a new message with warn level.
+func (l *RateLimitedLogger) Warnf(format string, v ...any) {
+	if l.allow(WarningLevel, format) {
+		l.Logger.Warnf(format, v...)
+	}
+}
+
+// Error starts a new message with error level.
+func (l *RateLimitedLogger) Error(v ...any) {
+	if l.allow(ErrorLevel, fmt.Sprint(v...)) {
+		l.Logger.Error(v...)
+	}
+}
+
+// Errorf starts a new message with error level.
+func (l *RateLimitedLogger) Errorf(format string, v ...any) {
+	if l.allow(ErrorLevel, format) {
+		l.Logger.Errorf(format, v...)
+	}
+}
+
+// Debug starts a new message with debug level.
+func (l *RateLimitedLogger) Debug(v ...any) {
+	if l.allow(DebugLevel, fmt.Sprint(v...)) {
+		l.Logger.Debug(v...)
+	}
+}
+
+// Debugf starts a new message with debug level.
+func (l *RateLimitedLogger) Debugf(format string, v ...any) {
+	if l.allow(DebugLevel, format) {
+		l.Logger.Debugf(format, v...)
+	}
+}
+
+// Debugw starts a new message with debug level and the given key-value pairs.
+func (l *RateLimitedLogger) Debugw(msg string, keysAndValues ...any) {
+	if l.allow(DebugLevel, msg) {
+		l.Logger.Debugw(msg, keysAndValues...)
+	}
+}
+
+// Infow starts a new message with info level and the given key-value pairs.
+func (l *RateLimitedLogger) Infow(msg string, keysAndValues ...any) {
+	if l.allow(InfoLevel, msg) {
+		l.Logger.Infow(msg, keysAndValues...)
+	}
+}
+
+// Warnw starts a new message with warn level and the given key-value pairs.
+func (l *RateLimitedLogger) Warnw(msg string, keysAndValues ...any) {
+	if l.allow(WarningLevel, msg) {
+		l.Logger.Warnw(msg, keysAndValues...)
+	}
+}
+
+// Errorw starts a new message with error level and the given key-value pairs.
+func (l *RateLimitedLogger) Errorw(msg string, keysAndValues ...any) {
+	if l.allow(ErrorLevel, msg) {
+		l.Logger.Errorw(msg, keysAndValues...)
+	}
+}
+
+// Errors starts a new message with error level describing the given error chain.
+func (l *RateLimitedLogger) Errors(err error) {
+	if err != nil && l.allow(ErrorLevel, err.Error()) {
+		l.Logger.Errors(err)
+	}
+}
+
+// allow tells whether the log of the given level and key can be written
+func (l *RateLimitedLogger) allow(level Level, key string) bool {
+	if l.key != "" {
+		return l.windows.allow(l.key)
+	}
+	return l.windows.allow(level.String() + ":" + key)
+}
+
+// rateWindows counts the logs written per key within the current interval
+type rateWindows struct {
+	mu        sync.Mutex
+	limit     int
+	interval  time.Duration
+	clock     clock.Clock
+	windows   map[string]*rateWindow
+	lastSweep time.Time
+}
+
+// rateWindow is the interval of a key
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+// allow tells whether a log with the given key can be written and counts it
+func (w *rateWindows) allow(key string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := w.clock.Now()
+	w.sweep(now)
+
+	window, ok := w.windows[key]
+	if !ok || now.Sub(window.start) >= w.interval {
+		window = &rateWindow{start: now}
+		w.windows[key] = window
+	}
+
+	if window.count >= w.limit {
+		return false
+	}
+	window.count++
+	return true
+}
+
+// sweep removes the windows of the keys not logged during the last interval
+func (w *rateWindows) sweep(now time.Time) {
+	if now.Sub(w.lastSweep) < w.interval {
+		return
+	}
+
+	w.lastSweep = now
+	for key, window := range w.windows {
+		if now.Sub(window.start) >= w.interval {
+			delete(w.windows, key)
+		}
+	}
+}
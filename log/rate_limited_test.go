@@ -0,0 +1,81 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package log_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tochemey/gopack/clock"
+	"github.com/tochemey/gopack/log"
+	"github.com/tochemey/gopack/log/zapl"
+)
+
+// countLines returns the number of logs written in the buffer
+func countLines(buffer *bytes.Buffer) int {
+	return strings.Count(buffer.String(), "\n")
+}
+
+func TestRateLimitedLogger(t *testing.T) {
+	t.Run("With the same message", func(t *testing.T) {
+		buffer := new(bytes.Buffer)
+		fake := clock.NewFake(time.Now())
+		logger := log.NewRateLimitedLoggerWithClock(zapl.New(log.DebugLevel, buffer), 2, time.Second, fake)
+
+		for range 5 {
+			logger.Errorf("consume failed: %d", 1)
+		}
+		assert.Equal(t, 2, countLines(buffer))
+
+		// other messages and levels have their own limit
+		logger.Error("another failure")
+		logger.Warnf("consume failed: %d", 1)
+		assert.Equal(t, 4, countLines(buffer))
+
+		// the next interval
+		fake.Advance(time.Second)
+		logger.Errorf("consume failed: %d", 1)
+		assert.Equal(t, 5, countLines(buffer))
+	})
+	t.Run("With a key", func(t *testing.T) {
+		buffer := new(bytes.Buffer)
+		fake := clock.NewFake(time.Now())
+		logger := log.NewRateLimitedLoggerWithClock(zapl.New(log.DebugLevel, buffer), 1, time.Second, fake).WithKey("consumer")
+
+		logger.Error("first failure")
+		logger.Warn("second failure")
+		logger.WithContext(context.Background()).Info("third failure")
+		assert.Equal(t, 1, countLines(buffer))
+	})
+	t.Run("With a panic", func(t *testing.T) {
+		logger := log.NewRateLimitedLogger(zapl.New(log.DebugLevel, new(bytes.Buffer)), 0, time.Second)
+		assert.Panics(t, func() { logger.Panic("always logged") })
+	})
+}
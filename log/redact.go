@@ -0,0 +1,47 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package log
+
+import "regexp"
+
+// Redacted replaces the sensitive data in the logs
+const Redacted = "[REDACTED]"
+
+var (
+	// SensitiveKeyPattern matches the keys holding passwords, secrets, tokens, API keys and credentials.
+	// The sensitive words must be delimited by the key boundaries, a separator or a camel case hump,
+	// so that keys such as max_tokens or bypass are not matched.
+	SensitiveKeyPattern = regexp.MustCompile(
+		`(?:^|[_.-])(?i:pass(?:word|wd)?|secret|token|authorization|api[_-]?key|credentials?)(?:$|[_.-])|` +
+			`[a-z0-9](?:Pass(?:word|wd)?|Secret|Token|Authorization|Api[_-]?[Kk]ey|APIKey|Credentials?)(?:$|[_.A-Z-])`)
+	// EmailPattern matches the email addresses
+	EmailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	// BearerTokenPattern matches the bearer tokens
+	BearerTokenPattern = regexp.MustCompile(`(?i)bearer\s+[A-Za-z0-9._~+/-]+=*`)
+	// JWTPattern matches the JSON Web Tokens
+	JWTPattern = regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\b`)
+	// APIKeyPattern matches the secret, publishable and restricted API keys, e.g. sk-...
+	APIKeyPattern = regexp.MustCompile(`\b(sk|pk|rk)-[A-Za-z0-9_-]{16,}\b`)
+)
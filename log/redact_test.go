@@ -0,0 +1,45 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package log
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSensitiveKeyPattern(t *testing.T) {
+	for _, key := range []string{
+		"password", "Password", "db_pass", "passwd", "client-secret", "access_token", "Authorization",
+		"api_key", "x-api-key", "apiKey", "userPassword", "accessToken", "credentials", "token.value",
+	} {
+		assert.Truef(t, SensitiveKeyPattern.MatchString(key), "expected %s to be sensitive", key)
+	}
+	for _, key := range []string{
+		"max_tokens", "prompt_tokens", "maxTokens", "bypass", "passenger", "tokenizer", "consumer", "secretary",
+	} {
+		assert.Falsef(t, SensitiveKeyPattern.MatchString(key), "expected %s not to be sensitive", key)
+	}
+}
@@ -28,6 +28,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"time"
 
@@ -44,7 +45,7 @@ import (
 var DefaultLogger = New(log.DebugLevel, os.Stdout, os.Stderr)
 
 // DiscardLogger is used not log anything
-var DiscardLogger = New(log.InfoLevel, io.Discard)
+var DiscardLogger = NewWithOptions(log.InfoLevel, WithWriters(io.Discard), WithoutGlobals())
 
 // Info logs to INFO level.
 func Info(v ...any) {
@@ -103,25 +104,35 @@ func WithContext(ctx context.Context) log.Logger {
 	return DefaultLogger.WithContext(ctx)
 }
 
+// SetLevel changes the log level of the DefaultLogger
+func SetLevel(level log.Level) {
+	DefaultLogger.SetLevel(level)
+}
+
 // Log implements Logger interface with the underlying zap as
 // the underlying logging library
 type Log struct {
 	*zap.Logger
+	level zap.AtomicLevel
 }
 
 // enforce compilation error
 var _ log.Logger = &Log{}
 
-// New creates an instance of Log
+// New creates an instance of Log writing to the given writers.
+// The created Log replaces the zap global loggers.
 func New(level log.Level, writers ...io.Writer) *Log {
+	return NewWithOptions(level, WithWriters(writers...))
+}
+
+// NewWithOptions creates an instance of Log with the given options
+func NewWithOptions(level log.Level, opts ...Option) *Log {
+	config := newConfig(opts...)
+	writers := config.writers
 	// create the zap Log configuration
 	cfg := zap.Config{
 		Development: false,
-		Sampling: &zap.SamplingConfig{
-			Initial:    100,
-			Thereafter: 100,
-		},
-		Encoding: "json",
+		Encoding:    "json",
 		// copied from "zap.NewProductionEncoderConfig" with some updates
 		EncoderConfig: zapcore.EncoderConfig{
 			TimeKey:       "ts",
@@ -144,60 +155,31 @@ func New(level log.Level, writers ...io.Writer) *Log {
 		OutputPaths:      []string{"stderr"},
 		ErrorOutputPaths: []string{"stderr"},
 	}
-	// create the zap log core
-	var core zapcore.Core
-
 	// create the list of writers
 	syncWriters := make([]zapcore.WriteSyncer, len(writers))
 	for i, writer := range writers {
 		syncWriters[i] = zapcore.AddSync(writer)
 	}
 
-	// set the log level
-	switch level {
-	case log.InfoLevel:
-		core = zapcore.NewCore(
-			zapcore.NewJSONEncoder(cfg.EncoderConfig),
-			zap.CombineWriteSyncers(syncWriters...),
-			zapcore.InfoLevel,
-		)
-	case log.DebugLevel:
-		core = zapcore.NewCore(
-			zapcore.NewJSONEncoder(cfg.EncoderConfig),
-			zap.CombineWriteSyncers(syncWriters...),
-			zapcore.DebugLevel,
-		)
-	case log.WarningLevel:
-		core = zapcore.NewCore(
-			zapcore.NewJSONEncoder(cfg.EncoderConfig),
-			zap.CombineWriteSyncers(syncWriters...),
-			zapcore.WarnLevel,
-		)
-	case log.ErrorLevel:
-		core = zapcore.NewCore(
-			zapcore.NewJSONEncoder(cfg.EncoderConfig),
-			zap.CombineWriteSyncers(syncWriters...),
-			zapcore.ErrorLevel,
-		)
-	case log.PanicLevel:
-		core = zapcore.NewCore(
-			zapcore.NewJSONEncoder(cfg.EncoderConfig),
-			zap.CombineWriteSyncers(syncWriters...),
-			zapcore.PanicLevel,
-		)
-	case log.FatalLevel:
-		core = zapcore.NewCore(
-			zapcore.NewJSONEncoder(cfg.EncoderConfig),
-			zap.CombineWriteSyncers(syncWriters...),
-			zapcore.FatalLevel,
-		)
-	default:
-		core = zapcore.NewCore(
-			zapcore.NewJSONEncoder(cfg.EncoderConfig),
-			zap.CombineWriteSyncers(syncWriters...),
-			zapcore.DebugLevel,
-		)
-	}
+	// create the zap log core with a level that can be changed at runtime
+	atomicLevel := zap.NewAtomicLevelAt(toZapLevel(level))
+	encoder := zapcore.NewJSONEncoder(cfg.EncoderConfig)
+	sink := zap.CombineWriteSyncers(syncWriters...)
+	core := newCore(func(enabler zapcore.LevelEnabler) zapcore.Core {
+		cores := []zapcore.Core{zapcore.NewCore(encoder, sink, enabler)}
+		// ship the logs via OTLP alongside the writers
+		if config.loggerProvider != nil {
+			cores = append(cores, newOTLPCore(config.loggerProvider, enabler))
+		}
+		// mask the sensitive data before it is encoded
+		if len(config.redactedKeys) > 0 || len(config.redactedValues) > 0 {
+			for i, core := range cores {
+				cores[i] = newRedactCore(core, config.redactedKeys, config.redactedValues)
+			}
+		}
+		return zapcore.NewTee(cores...)
+	}, atomicLevel, config.samplings)
+
 	// get the zap Log
 	zapLogger := zap.New(core,
 		zap.AddCaller(),
@@ -207,9 +189,54 @@ func New(level log.Level, writers ...io.Writer) *Log {
 		zap.AddStacktrace(zapcore.FatalLevel))
 
 	// set the global logger
-	zap.ReplaceGlobals(zapLogger)
+	if config.replaceGlobals {
+		zap.ReplaceGlobals(zapLogger)
+	}
 	// create the instance of Log and returns it
-	return &Log{zapLogger}
+	return &Log{Logger: zapLogger, level: atomicLevel}
+}
+
+// newCore creates the zap core writing the logs enabled at the given level with the cores
+// created by newIOCore. The levels with a sampling get their own sampled core.
+func newCore(newIOCore func(zapcore.LevelEnabler) zapcore.Core, level zapcore.LevelEnabler, samplings map[log.Level]sampling) zapcore.Core {
+	if len(samplings) == 0 {
+		return newIOCore(level)
+	}
+
+	sampled := make(map[zapcore.Level]bool, len(samplings))
+	cores := make([]zapcore.Core, 0, len(samplings)+1)
+	for logLevel, sampling := range samplings {
+		samplingLevel := toZapLevel(logLevel)
+		sampled[samplingLevel] = true
+		core := newIOCore(zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
+			return lvl == samplingLevel && level.Enabled(lvl)
+		}))
+		cores = append(cores, zapcore.NewSamplerWithOptions(core, sampling.tick, sampling.initial, sampling.thereafter))
+	}
+
+	// the levels without sampling
+	cores = append(cores, newIOCore(zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
+		return !sampled[lvl] && level.Enabled(lvl)
+	})))
+	return zapcore.NewTee(cores...)
+}
+
+// toZapLevel returns the zap level of the given log level
+func toZapLevel(level log.Level) zapcore.Level {
+	switch level {
+	case log.InfoLevel:
+		return zapcore.InfoLevel
+	case log.WarningLevel:
+		return zapcore.WarnLevel
+	case log.ErrorLevel:
+		return zapcore.ErrorLevel
+	case log.PanicLevel:
+		return zapcore.PanicLevel
+	case log.FatalLevel:
+		return zapcore.FatalLevel
+	default:
+		return zapcore.DebugLevel
+	}
 }
 
 // Debug starts a message with debug level
@@ -276,6 +303,55 @@ func (l *Log) Infof(format string, v ...any) {
 	l.Logger.Sugar().Info(fmt.Sprintf(format, v...))
 }
 
+// Debugw starts a message with debug level and the given key-value pairs
+func (l *Log) Debugw(msg string, keysAndValues ...any) {
+	l.Logger.Sugar().Debugw(msg, keysAndValues...)
+}
+
+// Infow starts a message with info level and the given key-value pairs
+func (l *Log) Infow(msg string, keysAndValues ...any) {
+	l.Logger.Sugar().Infow(msg, keysAndValues...)
+}
+
+// Warnw starts a message with warn level and the given key-value pairs
+func (l *Log) Warnw(msg string, keysAndValues ...any) {
+	l.Logger.Sugar().Warnw(msg, keysAndValues...)
+}
+
+// Errorw starts a message with error level and the given key-value pairs
+func (l *Log) Errorw(msg string, keysAndValues ...any) {
+	l.Logger.Sugar().Errorw(msg, keysAndValues...)
+}
+
+// Errors starts a message with error level holding the message of the given error,
+// with the causes of its chain and its stack trace as structured fields
+func (l *Log) Errors(err error) {
+	if err == nil {
+		return
+	}
+	l.Logger.Error(err.Error(), toZapFields(log.ErrorFields(err))...)
+}
+
+// With returns a child Logger adding the given fields to its messages
+func (l *Log) With(fields ...log.Field) log.Logger {
+	if len(fields) == 0 {
+		return l
+	}
+
+	child := *l
+	child.Logger = l.Logger.With(toZapFields(fields)...)
+	return &child
+}
+
+// toZapFields converts the fields into zap fields
+func toZapFields(fields []log.Field) []zap.Field {
+	zapFields := make([]zap.Field, len(fields))
+	for i, field := range fields {
+		zapFields[i] = zap.Any(field.Key, field.Value)
+	}
+	return zapFields
+}
+
 // LogLevel returns the log level that is used
 func (l *Log) LogLevel() log.Level {
 	switch l.Level() {
@@ -296,9 +372,22 @@ func (l *Log) LogLevel() log.Level {
 	}
 }
 
-// WithContext returns the Logger associated with the ctx.
-// This will set the traceid, requestid and spanid in case there are
-// in the context
+// SetLevel changes the log level at runtime.
+// The change applies to the loggers created from this Log with WithContext.
+func (l *Log) SetLevel(level log.Level) {
+	l.level.SetLevel(toZapLevel(level))
+}
+
+// LevelHandler returns an HTTP handler reporting the log level on GET requests
+// and changing it on PUT requests, e.g. with the {"level":"debug"} JSON body.
+// It lets operators change the verbosity of a running service.
+func (l *Log) LevelHandler() http.Handler {
+	return l.level
+}
+
+// WithContext returns a child Logger carrying the traceid, requestid and spanid
+// found in the context. The receiver is left untouched so that concurrent
+// requests do not share fields.
 func (l *Log) WithContext(ctx context.Context) log.Logger {
 	// define the zap core fields
 	var fields []zap.Field
@@ -309,20 +398,19 @@ func (l *Log) WithContext(ctx context.Context) log.Logger {
 		fields = append(fields, zap.String("request_id", requestID))
 	}
 	// set the span and trace id when defined
-	if otSpan := trace.SpanFromContext(ctx); otSpan != nil {
-		// get the trace id
-		traceID := otSpan.SpanContext().TraceID().String()
-		// grab the span id
-		spanID := otSpan.SpanContext().SpanID().String()
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
 		fields = append(fields,
-			zap.String("trace_id", traceID),
-			zap.String("span_id", spanID),
+			zap.String("trace_id", spanContext.TraceID().String()),
+			zap.String("span_id", spanContext.SpanID().String()),
 		)
 	}
 
-	// set the fields when set
-	if len(fields) > 0 {
-		l.Logger.With(fields...)
+	// nothing to add
+	if len(fields) == 0 {
+		return l
 	}
-	return l
+
+	child := *l
+	child.Logger = l.Logger.With(fields...)
+	return &child
 }
@@ -26,14 +26,27 @@ package zapl
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/logtest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
 
 	"github.com/tochemey/gopack/log"
+	"github.com/tochemey/gopack/requestid"
 )
 
 func TestDebug(t *testing.T) {
@@ -379,6 +392,59 @@ func TestPanic(t *testing.T) {
 	})
 }
 
+func TestWithContext(t *testing.T) {
+	t.Run("With a request id", func(t *testing.T) {
+		buffer := new(bytes.Buffer)
+		logger := New(log.DebugLevel, buffer)
+
+		ctx := requestid.Context(context.Background())
+		child := logger.WithContext(ctx)
+		require.NotSame(t, logger, child)
+
+		child.Info("with context")
+		fields := make(map[string]any)
+		require.NoError(t, json.Unmarshal(buffer.Bytes(), &fields))
+		assert.Equal(t, requestid.FromContext(ctx), fields["request_id"])
+
+		// the parent logger does not carry the request id
+		buffer.Reset()
+		logger.Info("without context")
+		fields = make(map[string]any)
+		require.NoError(t, json.Unmarshal(buffer.Bytes(), &fields))
+		assert.NotContains(t, fields, "request_id")
+	})
+	t.Run("With a span", func(t *testing.T) {
+		buffer := new(bytes.Buffer)
+		logger := New(log.DebugLevel, buffer)
+
+		spanContext := trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID: trace.TraceID{1},
+			SpanID:  trace.SpanID{2},
+		})
+		logger.WithContext(trace.ContextWithSpanContext(context.Background(), spanContext)).Info("with span")
+		fields := make(map[string]any)
+		require.NoError(t, json.Unmarshal(buffer.Bytes(), &fields))
+		assert.Equal(t, spanContext.TraceID().String(), fields["trace_id"])
+		assert.Equal(t, spanContext.SpanID().String(), fields["span_id"])
+	})
+	t.Run("With an empty context", func(t *testing.T) {
+		logger := New(log.DebugLevel, io.Discard)
+		assert.Same(t, logger, logger.WithContext(context.Background()))
+	})
+}
+
+func TestNewWithOptions(t *testing.T) {
+	global := zap.L()
+	buffer := new(bytes.Buffer)
+	logger := NewWithOptions(log.InfoLevel, WithWriters(buffer), WithoutGlobals())
+	assert.Same(t, global, zap.L())
+
+	logger.Info("test info")
+	actual, err := extractMessage(buffer.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, "test info", actual)
+}
+
 func extractMessage(bytes []byte) (string, error) {
 	// a map container to decode the JSON structure into
 	c := make(map[string]json.RawMessage)
@@ -412,3 +478,187 @@ func extractLevel(bytes []byte) (string, error) {
 
 	return "", nil
 }
+
+func TestSampling(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	logger := NewWithOptions(log.DebugLevel, WithWriters(buffer), WithoutGlobals(), WithSampling(log.ErrorLevel, time.Minute, 2, 0))
+
+	for range 5 {
+		logger.Error("consume failed")
+		logger.Info("consumed")
+	}
+	assert.Equal(t, 2, strings.Count(buffer.String(), "consume failed"))
+	assert.Equal(t, 5, strings.Count(buffer.String(), "consumed"))
+	assert.Equal(t, log.DebugLevel, logger.LogLevel())
+}
+
+func TestSetLevel(t *testing.T) {
+	t.Run("With SetLevel", func(t *testing.T) {
+		buffer := new(bytes.Buffer)
+		logger := New(log.InfoLevel, buffer)
+		child := logger.WithContext(requestid.Context(context.Background()))
+
+		logger.Debug("hidden")
+		require.Empty(t, buffer.String())
+
+		logger.SetLevel(log.DebugLevel)
+		require.Equal(t, log.DebugLevel, logger.LogLevel())
+		require.Equal(t, log.DebugLevel, child.LogLevel())
+		child.Debug("shown")
+		actual, err := extractMessage(buffer.Bytes())
+		require.NoError(t, err)
+		require.Equal(t, "shown", actual)
+	})
+	t.Run("With the level handler", func(t *testing.T) {
+		logger := New(log.InfoLevel, io.Discard)
+		handler := logger.LevelHandler()
+
+		response := httptest.NewRecorder()
+		handler.ServeHTTP(response, httptest.NewRequest(http.MethodPut, "/log/level", strings.NewReader(`{"level":"error"}`)))
+		require.Equal(t, http.StatusOK, response.Code)
+		require.Equal(t, log.ErrorLevel, logger.LogLevel())
+
+		response = httptest.NewRecorder()
+		handler.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/log/level", nil))
+		require.Equal(t, http.StatusOK, response.Code)
+		assert.JSONEq(t, `{"level":"error"}`, response.Body.String())
+	})
+}
+
+func TestStructuredFields(t *testing.T) {
+	t.Run("With fields", func(t *testing.T) {
+		buffer := new(bytes.Buffer)
+		logger := New(log.DebugLevel, buffer)
+
+		logger.With(log.String("consumer", "orders"), log.Int("attempt", 2)).Info("consumed")
+		fields := make(map[string]any)
+		require.NoError(t, json.Unmarshal(buffer.Bytes(), &fields))
+		assert.Equal(t, "consumed", fields["msg"])
+		assert.Equal(t, "orders", fields["consumer"])
+		assert.EqualValues(t, 2, fields["attempt"])
+
+		// the parent logger does not carry the fields
+		buffer.Reset()
+		logger.Info("consumed")
+		fields = make(map[string]any)
+		require.NoError(t, json.Unmarshal(buffer.Bytes(), &fields))
+		assert.NotContains(t, fields, "consumer")
+	})
+	t.Run("With key-value pairs", func(t *testing.T) {
+		buffer := new(bytes.Buffer)
+		logger := New(log.DebugLevel, buffer)
+
+		logger.Errorw("consume failed", "consumer", "orders", "duration", time.Second)
+		fields := make(map[string]any)
+		require.NoError(t, json.Unmarshal(buffer.Bytes(), &fields))
+		assert.Equal(t, "consume failed", fields["msg"])
+		assert.Equal(t, log.ErrorLevel.String(), fields["level"])
+		assert.Equal(t, "orders", fields["consumer"])
+		assert.Equal(t, "1s", fields["duration"])
+	})
+}
+
+func TestRedaction(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	logger := NewWithOptions(log.DebugLevel,
+		WithWriters(buffer),
+		WithoutGlobals(),
+		WithRedactedKeys(DefaultRedactedKeys...),
+		WithRedactedValues(DefaultRedactedValues...),
+		WithSampling(log.ErrorLevel, time.Minute, 1, 0),
+	)
+
+	logger.With(log.String("password", "hunter2")).Infow("signed up john@example.com",
+		"api_key", "abc",
+		"max_tokens", 256,
+		"order", "1234 5678 9012 3456",
+		"error", errors.New("invalid token Bearer abc.def"),
+		"attempt", 2,
+	)
+	fields := make(map[string]any)
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &fields))
+	assert.Equal(t, "signed up [REDACTED]", fields["msg"])
+	assert.Equal(t, log.Redacted, fields["password"])
+	assert.Equal(t, log.Redacted, fields["api_key"])
+	assert.EqualValues(t, 256, fields["max_tokens"])
+	assert.Equal(t, "1234 5678 9012 3456", fields["order"])
+	assert.Equal(t, "invalid token [REDACTED]", fields["error"])
+	assert.EqualValues(t, 2, fields["attempt"])
+
+	// the redacted logs are still sampled
+	buffer.Reset()
+	logger.Error("failed for john@example.com")
+	logger.Error("failed for john@example.com")
+	assert.Equal(t, 1, strings.Count(buffer.String(), "failed for [REDACTED]"))
+}
+
+func TestRedactionWithNilStringer(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	logger := NewWithOptions(log.DebugLevel,
+		WithWriters(buffer),
+		WithoutGlobals(),
+		WithRedactedValues(DefaultRedactedValues...),
+	)
+
+	var stringer *nilStringer
+	require.NotPanics(t, func() {
+		logger.Infow("typed nil", "stringer", stringer)
+	})
+	fields := make(map[string]any)
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &fields))
+	assert.Equal(t, "<nil>", fields["stringer"])
+}
+
+// nilStringer is a fmt.Stringer panicking on a nil receiver
+type nilStringer struct {
+	name string
+}
+
+func (s *nilStringer) String() string {
+	return s.name
+}
+
+func TestOTLP(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	recorder := logtest.NewRecorder()
+	logger := NewWithOptions(log.InfoLevel,
+		WithWriters(buffer),
+		WithoutGlobals(),
+		WithOTLP(recorder),
+		WithRedactedValues(DefaultRedactedValues...),
+	)
+
+	logger.Debug("hidden")
+	logger.Infow("signed up john@example.com", "consumer", "orders")
+
+	// the logs are written alongside
+	actual, err := extractMessage(buffer.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, "signed up [REDACTED]", actual)
+
+	var records []logtest.EmittedRecord
+	for _, scope := range recorder.Result() {
+		if scope.Name == instrumentationName {
+			records = append(records, scope.Records...)
+		}
+	}
+	require.Len(t, records, 1)
+	assert.Equal(t, "signed up [REDACTED]", records[0].Body().AsString())
+	assert.Equal(t, otellog.SeverityInfo, records[0].Severity())
+}
+
+func TestErrors(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	logger := New(log.DebugLevel, buffer)
+
+	logger.Errors(nil)
+	require.Empty(t, buffer.String())
+
+	logger.Errors(fmt.Errorf("consume: %w", errors.New("connection refused")))
+	fields := make(map[string]any)
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &fields))
+	assert.Equal(t, "consume: connection refused", fields["msg"])
+	assert.Equal(t, log.ErrorLevel.String(), fields["level"])
+	assert.Equal(t, []any{"connection refused"}, fields[log.FieldErrorCauses])
+	assert.Contains(t, fields["caller"], "log_test.go")
+}
@@ -0,0 +1,133 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package zapl
+
+import (
+	"io"
+	"os"
+	"regexp"
+	"time"
+
+	otellog "go.opentelemetry.io/otel/log"
+
+	"github.com/tochemey/gopack/log"
+)
+
+// config holds the settings of a Log
+type config struct {
+	writers        []io.Writer
+	replaceGlobals bool
+	samplings      map[log.Level]sampling
+	redactedKeys   []*regexp.Regexp
+	redactedValues []*regexp.Regexp
+	loggerProvider otellog.LoggerProvider
+}
+
+// sampling holds the sampling settings of a log level
+type sampling struct {
+	tick       time.Duration
+	initial    int
+	thereafter int
+}
+
+// newConfig creates the default config and applies the given options
+func newConfig(opts ...Option) *config {
+	cfg := &config{
+		writers:        []io.Writer{os.Stdout},
+		replaceGlobals: true,
+	}
+	for _, opt := range opts {
+		opt.Apply(cfg)
+	}
+	return cfg
+}
+
+// Option is the interface that applies a configuration option.
+type Option interface {
+	// Apply sets the Option value of a config.
+	Apply(*config)
+}
+
+var _ Option = OptionFunc(nil)
+
+// OptionFunc implements the Option interface.
+type OptionFunc func(*config)
+
+// Apply applies the option
+func (f OptionFunc) Apply(c *config) {
+	f(c)
+}
+
+// WithWriters sets the writers the Log writes to. It defaults to os.Stdout
+func WithWriters(writers ...io.Writer) Option {
+	return OptionFunc(func(c *config) {
+		c.writers = writers
+	})
+}
+
+// WithoutGlobals prevents the Log from replacing the zap global loggers,
+// which New does by default
+func WithoutGlobals() Option {
+	return OptionFunc(func(c *config) {
+		c.replaceGlobals = false
+	})
+}
+
+// WithSampling samples the logs of the given level. Within every tick, the first initial logs
+// with the same message are written, then only every thereafter-th one. The levels without
+// sampling are not sampled.
+func WithSampling(level log.Level, tick time.Duration, initial, thereafter int) Option {
+	return OptionFunc(func(c *config) {
+		if c.samplings == nil {
+			c.samplings = make(map[log.Level]sampling)
+		}
+		c.samplings[level] = sampling{tick: tick, initial: initial, thereafter: thereafter}
+	})
+}
+
+// WithRedactedKeys masks the value of the fields whose key matches one of the patterns,
+// e.g. DefaultRedactedKeys
+func WithRedactedKeys(patterns ...*regexp.Regexp) Option {
+	return OptionFunc(func(c *config) {
+		c.redactedKeys = append(c.redactedKeys, patterns...)
+	})
+}
+
+// WithRedactedValues masks the matches of the patterns in the messages and the string fields,
+// e.g. DefaultRedactedValues
+func WithRedactedValues(patterns ...*regexp.Regexp) Option {
+	return OptionFunc(func(c *config) {
+		c.redactedValues = append(c.redactedValues, patterns...)
+	})
+}
+
+// WithOTLP ships the logs to the given open telemetry logger provider alongside the writers,
+// e.g. the LoggerProvider of the otel/log Provider. The shipped logs are sampled and redacted
+// like the written ones.
+func WithOTLP(provider otellog.LoggerProvider) Option {
+	return OptionFunc(func(c *config) {
+		c.loggerProvider = provider
+	})
+}
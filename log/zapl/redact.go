@@ -0,0 +1,143 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package zapl
+
+import (
+	"fmt"
+	"regexp"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/tochemey/gopack/log"
+)
+
+// DefaultRedactedKeys matches the keys of the fields holding passwords, secrets, tokens and API keys
+var DefaultRedactedKeys = []*regexp.Regexp{
+	log.SensitiveKeyPattern,
+}
+
+// DefaultRedactedValues matches the email addresses, the bearer tokens, the JWTs and the API keys
+var DefaultRedactedValues = []*regexp.Regexp{
+	log.EmailPattern,
+	log.BearerTokenPattern,
+	log.JWTPattern,
+	log.APIKeyPattern,
+}
+
+// redactCore is a zap core masking the sensitive data of the logs before they are encoded
+type redactCore struct {
+	zapcore.Core
+	keys   []*regexp.Regexp
+	values []*regexp.Regexp
+}
+
+// enforce compilation error
+var _ zapcore.Core = (*redactCore)(nil)
+
+// newRedactCore wraps the core to mask the fields whose key matches one of the keys patterns
+// and the matches of the values patterns in the messages and the string fields
+func newRedactCore(core zapcore.Core, keys, values []*regexp.Regexp) zapcore.Core {
+	return &redactCore{Core: core, keys: keys, values: values}
+}
+
+// With adds the redacted fields to the core
+func (c *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactCore{Core: c.Core.With(c.redactFields(fields)), keys: c.keys, values: c.values}
+}
+
+// Check adds the core to the checked entry when the entry is enabled
+func (c *redactCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// Write redacts the entry and its fields before writing them
+func (c *redactCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	entry.Message = c.redact(entry.Message)
+	return c.Core.Write(entry, c.redactFields(fields))
+}
+
+// redactFields returns a copy of the fields with the sensitive data masked
+func (c *redactCore) redactFields(fields []zapcore.Field) []zapcore.Field {
+	redactedFields := make([]zapcore.Field, len(fields))
+	for i, field := range fields {
+		redactedFields[i] = c.redactField(field)
+	}
+	return redactedFields
+}
+
+// redactField masks the field when its key is sensitive or its value holds sensitive data
+func (c *redactCore) redactField(field zapcore.Field) zapcore.Field {
+	for _, key := range c.keys {
+		if key.MatchString(field.Key) {
+			return zap.String(field.Key, log.Redacted)
+		}
+	}
+
+	var value string
+	switch field.Type {
+	case zapcore.StringType:
+		value = field.String
+	case zapcore.ErrorType:
+		var ok bool
+		if value, ok = safeString(field.Interface.(error).Error); !ok {
+			return field
+		}
+	case zapcore.StringerType:
+		var ok bool
+		if value, ok = safeString(field.Interface.(fmt.Stringer).String); !ok {
+			return field
+		}
+	default:
+		return field
+	}
+
+	if redactedValue := c.redact(value); redactedValue != value {
+		return zap.String(field.Key, redactedValue)
+	}
+	return field
+}
+
+// safeString returns the value of the given String or Error method. It returns false when the method panics,
+// e.g. on a nil pointer receiver, and lets the zap encoder report the panic as it does for the unredacted fields.
+func safeString(fn func() string) (value string, ok bool) {
+	defer func() {
+		if recover() != nil {
+			value, ok = "", false
+		}
+	}()
+	return fn(), true
+}
+
+// redact replaces the matches of the values patterns in the content
+func (c *redactCore) redact(content string) string {
+	for _, pattern := range c.values {
+		content = pattern.ReplaceAllString(content, log.Redacted)
+	}
+	return content
+}
@@ -0,0 +1,183 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"net"
+	"strconv"
+
+	"github.com/XSAM/otelsql"
+	"github.com/georgysavva/scany/v2/sqlscan"
+	"github.com/go-sql-driver/mysql"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	semconv "go.opentelemetry.io/otel/semconv/v1.20.0"
+)
+
+// MySQL will be implemented by concrete RDBMS store
+type MySQL interface {
+	// Connect connects to the underlying database
+	Connect(ctx context.Context) error
+	// Disconnect closes the underlying opened underlying connection database
+	Disconnect(ctx context.Context) error
+	// Select fetches a single row from the database and automatically scanned it into the dst.
+	// It returns an error in case of failure. When there is no record no errors is return.
+	Select(ctx context.Context, dst any, query string, args ...any) error
+	// SelectAll fetches a set of rows as defined by the query and scanned those record in the dst.
+	// It returns nil when there is no records to fetch.
+	SelectAll(ctx context.Context, dst any, query string, args ...any) error
+	// Exec executes an SQL statement against the database and returns the appropriate result or an error.
+	Exec(ctx context.Context, query string, args ...any) (sql.Result, error)
+	// BeginTx helps start an SQL transaction. The return transaction object is expected to be used in
+	// the subsequent queries following the BeginTx.
+	BeginTx(ctx context.Context, txOptions *sql.TxOptions) (*sql.Tx, error)
+}
+
+// mysqlStore helps interact with the MySQL database
+type mysqlStore struct {
+	dsn          string
+	dbConnection *sql.DB
+	config       *Config
+}
+
+var _ MySQL = (*mysqlStore)(nil)
+
+const mysqlDriver = "mysql"
+const instrumentationName = "github.com.tochemey.gopack.mysql"
+
+// New returns a store connecting to the given MySQL database.
+func New(config *Config) MySQL {
+	store := new(mysqlStore)
+	store.config = config
+	store.dsn = createDSN(config.DBHost, config.DBPort, config.DBName, config.DBUser, config.DBPassword)
+	return store
+}
+
+// Connect will connect to our MySQL database
+func (m *mysqlStore) Connect(ctx context.Context) error {
+	// Register an OTel driver
+	driverName, err := otelsql.Register(mysqlDriver, otelsql.WithAttributes(semconv.DBSystemMySQL))
+	if err != nil {
+		return errors.Wrap(err, "failed to hook the tracer to the database driver")
+	}
+
+	// open the connection and connect to the database
+	db, err := sql.Open(driverName, m.dsn)
+	if err != nil {
+		return errors.Wrap(err, "failed to open connection")
+	}
+
+	// let us test the connection
+	err = db.PingContext(ctx)
+	if err != nil {
+		_ = db.Close()
+		return errors.Wrap(err, "failed to ping database connection")
+	}
+
+	// set connection setting
+	db.SetMaxOpenConns(m.config.MaxOpenConnections)
+	db.SetMaxIdleConns(m.config.MaxIdleConnections)
+	db.SetConnMaxLifetime(m.config.ConnectionMaxLifetime)
+
+	// set the db handle
+	m.dbConnection = db
+	return nil
+}
+
+// createDSN will create the MySQL data source name from the supplied connection details.
+// The DATE and DATETIME columns are scanned into time.Time.
+func createDSN(host string, port int, name, user, password string) string {
+	config := mysql.NewConfig()
+	config.User = user
+	config.Passwd = password
+	config.Net = "tcp"
+	config.Addr = net.JoinHostPort(host, strconv.Itoa(port))
+	config.DBName = name
+	config.ParseTime = true
+	return config.FormatDSN()
+}
+
+// Exec executes a sql query without returning rows against the database
+func (m *mysqlStore) Exec(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	// Create a span
+	tracer := otel.GetTracerProvider()
+	spanCtx, span := tracer.Tracer(instrumentationName).Start(ctx, "Exec")
+	defer span.End()
+	return m.dbConnection.ExecContext(spanCtx, query, args...)
+}
+
+// BeginTx starts a new database transaction
+func (m *mysqlStore) BeginTx(ctx context.Context, txOptions *sql.TxOptions) (*sql.Tx, error) {
+	// Create a span
+	tracer := otel.GetTracerProvider()
+	spanCtx, span := tracer.Tracer(instrumentationName).Start(ctx, "BeginTx")
+	defer span.End()
+	return m.dbConnection.BeginTx(spanCtx, txOptions)
+}
+
+// SelectAll fetches rows
+func (m *mysqlStore) SelectAll(ctx context.Context, dst any, query string, args ...any) error {
+	// Create a span
+	tracer := otel.GetTracerProvider()
+	spanCtx, span := tracer.Tracer(instrumentationName).Start(ctx, "SelectAll")
+	defer span.End()
+	err := sqlscan.Select(spanCtx, m.dbConnection, dst, query, args...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// Select fetches only one row
+func (m *mysqlStore) Select(ctx context.Context, dst any, query string, args ...any) error {
+	// Create a span
+	tracer := otel.GetTracerProvider()
+	spanCtx, span := tracer.Tracer(instrumentationName).Start(ctx, "Select")
+	defer span.End()
+	err := sqlscan.Get(spanCtx, m.dbConnection, dst, query, args...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// Disconnect the database connection.
+func (m *mysqlStore) Disconnect(ctx context.Context) error {
+	tracer := otel.GetTracerProvider()
+	_, span := tracer.Tracer(instrumentationName).Start(ctx, "Disconnect")
+	defer span.End()
+	if m.dbConnection == nil {
+		return nil
+	}
+	return m.dbConnection.Close()
+}
@@ -0,0 +1,171 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package mysql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// account is a test struct
+type account struct {
+	AccountID   string
+	AccountName string
+}
+
+// MySQLTestSuite will run the MySQL tests
+type MySQLTestSuite struct {
+	suite.Suite
+	container *TestContainer
+}
+
+// SetupSuite starts the MySQL database engine and set the container
+// host and port to use in the tests
+func (s *MySQLTestSuite) SetupSuite() {
+	s.container = NewTestContainer("testdb", "test", "test")
+}
+
+func (s *MySQLTestSuite) TearDownSuite() {
+	s.container.Cleanup()
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestMySQLTestSuite(t *testing.T) {
+	suite.Run(t, new(MySQLTestSuite))
+}
+
+func TestCreateDSN(t *testing.T) {
+	dsn := createDSN("localhost", 3306, "testdb", "test", "secret")
+	assert.Equal(t, "test:secret@tcp(localhost:3306)/testdb?parseTime=true", dsn)
+}
+
+func (s *MySQLTestSuite) TestConnect() {
+	s.Run("with valid connection settings", func() {
+		ctx := context.TODO()
+		db := s.container.GetTestDB()
+		s.Assert().NoError(db.Connect(ctx))
+		s.Assert().NoError(db.Disconnect(ctx))
+	})
+
+	s.Run("with invalid database name", func() {
+		ctx := context.TODO()
+		db := New(&Config{
+			DBUser:     "test",
+			DBName:     "wrong-name",
+			DBPassword: "test",
+			DBHost:     s.container.Host(),
+			DBPort:     s.container.Port(),
+		})
+		s.Assert().Error(db.Connect(ctx))
+	})
+
+	s.Run("with invalid database password", func() {
+		ctx := context.TODO()
+		db := New(&Config{
+			DBUser:     "test",
+			DBName:     "testdb",
+			DBPassword: "invalid-db-pass",
+			DBHost:     s.container.Host(),
+			DBPort:     s.container.Port(),
+		})
+		s.Assert().Error(db.Connect(ctx))
+	})
+}
+
+func (s *MySQLTestSuite) TestSelect() {
+	ctx := context.TODO()
+	db := s.container.GetTestDB()
+	s.Require().NoError(db.Connect(ctx))
+	s.Require().NoError(db.DropTable(ctx, "accounts"))
+	s.Require().NoError(createTable(ctx, db))
+	s.Require().NoError(db.TableExists(ctx, "accounts"))
+
+	inserted := &account{AccountID: uuid.New().String(), AccountName: "some-account"}
+	s.Require().NoError(insertInto(ctx, db, inserted))
+
+	s.Run("with a single row", func() {
+		selected := new(account)
+		err := db.Select(ctx, selected, `SELECT account_id, account_name FROM accounts WHERE account_id = ?;`, inserted.AccountID)
+		s.Assert().NoError(err)
+		s.Assert().Equal(inserted, selected)
+	})
+
+	s.Run("with no row", func() {
+		selected := new(account)
+		err := db.Select(ctx, selected, `SELECT account_id, account_name FROM accounts WHERE account_id = ?;`, "unknown")
+		s.Assert().NoError(err)
+		s.Assert().Empty(selected.AccountID)
+	})
+
+	s.Run("with all rows", func() {
+		s.Require().NoError(insertInto(ctx, db, &account{AccountID: uuid.New().String(), AccountName: "other-account"}))
+		var accounts []*account
+		err := db.SelectAll(ctx, &accounts, `SELECT account_id, account_name FROM accounts;`)
+		s.Assert().NoError(err)
+		s.Assert().Len(accounts, 2)
+
+		count, err := db.Count(ctx, "accounts")
+		s.Assert().NoError(err)
+		s.Assert().Equal(2, count)
+	})
+
+	s.Assert().NoError(db.DropTable(ctx, "accounts"))
+	s.Assert().NoError(db.Disconnect(ctx))
+}
+
+func (s *MySQLTestSuite) TestClose() {
+	ctx := context.TODO()
+	db := s.container.GetTestDB()
+	s.Require().NoError(db.Connect(ctx))
+	s.Require().NoError(db.Disconnect(ctx))
+
+	// let us execute a query against a closed connection
+	_, err := db.Exec(ctx, "SELECT 1;")
+	s.Assert().EqualError(err, "sql: database is closed")
+}
+
+func createTable(ctx context.Context, db MySQL) error {
+	const schemaDDL = `
+		CREATE TABLE IF NOT EXISTS accounts
+		(
+			account_id		CHAR(36) NOT NULL,
+			account_name	VARCHAR(255) NOT NULL,
+			PRIMARY KEY (account_id)
+		);
+	`
+	_, err := db.Exec(ctx, schemaDDL)
+	return err
+}
+
+func insertInto(ctx context.Context, db MySQL, account *account) error {
+	const insertSQL = `INSERT INTO accounts(account_id, account_name) VALUES(?, ?);`
+	_, err := db.Exec(ctx, insertSQL, account.AccountID, account.AccountName)
+	return err
+}
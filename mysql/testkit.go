@@ -0,0 +1,199 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/ory/dockertest"
+	"github.com/ory/dockertest/docker"
+)
+
+// TestContainer helps creates a MySQL docker container to
+// run unit tests
+type TestContainer struct {
+	host string
+	port int
+
+	resource *dockertest.Resource
+	pool     *dockertest.Pool
+
+	// connection credentials
+	dbUser string
+	dbName string
+	dbPass string
+}
+
+// NewTestContainer create a MySQL test container useful for unit and integration tests
+// This function will exit when there is an error.Call this function inside your SetupTest to create the container before each test.
+func NewTestContainer(dbName, dbUser, dbPassword string) *TestContainer {
+	// create the docker pool
+	pool, err := dockertest.NewPool("")
+	if err != nil {
+		log.Fatalf("Could not connect to docker: %s", err)
+	}
+	// pulls an image, creates a container based on it and runs it
+	resource, err := pool.RunWithOptions(&dockertest.RunOptions{
+		Repository: "mysql",
+		Tag:        "8.0",
+		Env: []string{
+			fmt.Sprintf("MYSQL_ROOT_PASSWORD=%s", dbPassword),
+			fmt.Sprintf("MYSQL_USER=%s", dbUser),
+			fmt.Sprintf("MYSQL_PASSWORD=%s", dbPassword),
+			fmt.Sprintf("MYSQL_DATABASE=%s", dbName),
+		},
+	}, func(config *docker.HostConfig) {
+		// set AutoRemove to true so that stopped container goes away by itself
+		config.AutoRemove = true
+		config.RestartPolicy = docker.RestartPolicy{Name: "no"}
+	})
+	// handle the error
+	if err != nil {
+		log.Fatalf("Could not start resource: %s", err)
+	}
+	// get the host and port of the database connection
+	hostAndPort := resource.GetHostPort("3306/tcp")
+	host, port, err := splitHostAndPort(hostAndPort)
+	if err != nil {
+		log.Fatalf("Unable to get database host and port: %s", err)
+	}
+	dsn := createDSN(host, port, dbName, dbUser, dbPassword)
+	log.Println("Connecting to database on address: ", hostAndPort)
+	// Tell docker to hard kill the container in 180 seconds
+	_ = resource.Expire(180)
+	// exponential backoff-retry, because the application in the container might not be ready to accept connections yet
+	pool.MaxWait = 180 * time.Second
+	if err = pool.Retry(func() error {
+		db, err := sql.Open(mysqlDriver, dsn)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		return db.Ping()
+	}); err != nil {
+		log.Fatalf("Could not connect to docker: %s", err)
+	}
+	// create an instance of TestContainer
+	container := new(TestContainer)
+	container.pool = pool
+	container.resource = resource
+	container.dbName = dbName
+	container.dbUser = dbUser
+	container.dbPass = dbPassword
+	container.host = host
+	container.port = port
+	return container
+}
+
+// GetTestDB returns a MySQL TestDB that can be used in the tests
+// to perform some database queries
+func (c TestContainer) GetTestDB() *TestDB {
+	return &TestDB{
+		New(&Config{
+			DBUser:     c.dbUser,
+			DBName:     c.dbName,
+			DBPassword: c.dbPass,
+			DBHost:     c.host,
+			DBPort:     c.port,
+		}),
+	}
+}
+
+// Host return the host of the test container
+func (c TestContainer) Host() string {
+	return c.host
+}
+
+// Port return the port of the test container
+func (c TestContainer) Port() int {
+	return c.port
+}
+
+// Cleanup frees the resource by removing a container and linked volumes from docker.
+// Call this function inside your TearDownSuite to clean-up resources after each test
+func (c TestContainer) Cleanup() {
+	if err := c.pool.Purge(c.resource); err != nil {
+		log.Fatalf("Could not purge resource: %s", err)
+	}
+}
+
+// TestDB is used in test to perform
+// some database queries
+type TestDB struct {
+	MySQL
+}
+
+// DropTable utility function to drop a database table
+func (c TestDB) DropTable(ctx context.Context, tableName string) error {
+	var dropSQL = fmt.Sprintf("DROP TABLE IF EXISTS %s;", tableName)
+	_, err := c.Exec(ctx, dropSQL)
+	return err
+}
+
+// TableExists utility function to help check the existence of table in MySQL
+// It returns an error when the table does not exist
+func (c TestDB) TableExists(ctx context.Context, tableName string) error {
+	var count int
+	const stmt = "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?;"
+	if err := c.Select(ctx, &count, stmt, tableName); err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return fmt.Errorf("table %s does not exist", tableName)
+	}
+	return nil
+}
+
+// Count utility function to help count the number of rows in a MySQL table.
+// It returns -1 when there is an error
+func (c TestDB) Count(ctx context.Context, tableName string) (int, error) {
+	var count int
+	if err := c.Select(ctx, &count, fmt.Sprintf("SELECT COUNT(*) FROM %s", tableName)); err != nil {
+		return -1, err
+	}
+	return count, nil
+}
+
+// splitHostAndPort helps get the host address and port of and address
+func splitHostAndPort(hostAndPort string) (string, int, error) {
+	host, port, err := net.SplitHostPort(hostAndPort)
+	if err != nil {
+		return "", -1, err
+	}
+
+	portValue, err := strconv.Atoi(port)
+	if err != nil {
+		return "", -1, err
+	}
+
+	return host, portValue, nil
+}
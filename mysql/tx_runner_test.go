@@ -0,0 +1,86 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package mysql
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+type accountInsertBuilder struct {
+	account *account
+}
+
+func (b *accountInsertBuilder) BuildQuery() (sqlStatement string, args []any, err error) {
+	return `INSERT INTO accounts(account_id, account_name) VALUES(?, ?);`, []any{b.account.AccountID, b.account.AccountName}, nil
+}
+
+type failureBuilder struct{}
+
+func (b *failureBuilder) BuildQuery() (sqlStatement string, args []any, err error) {
+	return "", nil, errors.New("failed to build query")
+}
+
+func (s *MySQLTestSuite) TestTxRunner() {
+	ctx := context.TODO()
+	db := s.container.GetTestDB()
+	s.Require().NoError(db.Connect(ctx))
+	s.Require().NoError(db.DropTable(ctx, "accounts"))
+	s.Require().NoError(createTable(ctx, db))
+
+	s.Run("happy path", func() {
+		txRunner, err := NewTxRunner(ctx, db)
+		s.Require().NoError(err)
+		err = txRunner.
+			AddQueryBuilder(&accountInsertBuilder{&account{AccountID: uuid.New().String(), AccountName: "first"}}).
+			AddQueryBuilder(&accountInsertBuilder{&account{AccountID: uuid.New().String(), AccountName: "second"}}).
+			Execute()
+		s.Assert().NoError(err)
+
+		count, err := db.Count(ctx, "accounts")
+		s.Assert().NoError(err)
+		s.Assert().Equal(2, count)
+	})
+
+	s.Run("with a builder error", func() {
+		txRunner, err := NewTxRunner(ctx, db)
+		s.Require().NoError(err)
+		err = txRunner.
+			AddQueryBuilder(&accountInsertBuilder{&account{AccountID: uuid.New().String(), AccountName: "third"}}).
+			AddQueryBuilder(new(failureBuilder)).
+			Execute()
+		s.Assert().EqualError(err, "failed to build query")
+
+		// the transaction is rolled back
+		count, err := db.Count(ctx, "accounts")
+		s.Assert().NoError(err)
+		s.Assert().Equal(2, count)
+	})
+
+	s.Assert().NoError(db.DropTable(ctx, "accounts"))
+	s.Assert().NoError(db.Disconnect(ctx))
+}
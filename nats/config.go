@@ -0,0 +1,81 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package nats
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+)
+
+const (
+	// DefaultConsumersCount is the default number of workers handling the messages
+	DefaultConsumersCount = 1
+	// DefaultMaxAckPending is the default maximum number of messages delivered and not yet acknowledged
+	DefaultMaxAckPending = 1000
+	// DefaultAckWait is the default duration the server waits for an acknowledgement before redelivering a message
+	DefaultAckWait = 30 * time.Second
+)
+
+// SubscriberConfig defines the Subscriber settings
+type SubscriberConfig struct {
+	Stream         string        // Stream is the name of the stream to consume. The stream must exist.
+	Durable        string        // Durable is the name of the durable consumer. An ephemeral consumer is created when it is empty.
+	FilterSubject  string        // FilterSubject restricts the consumed messages to the given subject. It can contain wildcards.
+	ConsumersCount int           // ConsumersCount is the number of workers handling the messages. It defaults to DefaultConsumersCount
+	MaxAckPending  int           // MaxAckPending is the maximum number of messages delivered and not yet acknowledged. It defaults to DefaultMaxAckPending
+	AckWait        time.Duration // AckWait is the duration the server waits for an acknowledgement before redelivering a message. It defaults to DefaultAckWait
+	MaxDeliver     int           // MaxDeliver is the maximum number of deliveries of a message. It is unlimited when zero.
+}
+
+// Validate checks the config and sets the default values
+func (c *SubscriberConfig) Validate() error {
+	var err error
+	if c.Stream == "" {
+		err = multierr.Append(err, errors.New("the stream is required"))
+	}
+
+	if c.ConsumersCount < 0 || c.MaxAckPending < 0 || c.AckWait < 0 || c.MaxDeliver < 0 {
+		err = multierr.Append(err, errors.New("the consumers count, max ack pending, ack wait and max deliver must not be negative"))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if c.ConsumersCount == 0 {
+		c.ConsumersCount = DefaultConsumersCount
+	}
+
+	if c.MaxAckPending == 0 {
+		c.MaxAckPending = DefaultMaxAckPending
+	}
+
+	if c.AckWait == 0 {
+		c.AckWait = DefaultAckWait
+	}
+	return nil
+}
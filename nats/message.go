@@ -0,0 +1,81 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package nats
+
+import (
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+
+	"github.com/tochemey/gopack/broker"
+)
+
+// KeyHeader is the header carrying the message ordering key
+const KeyHeader = "Gopack-Ordering-Key"
+
+// Message is a message published to or received from a JetStream subject.
+// JetStream drops a message published with an ID already seen within the stream duplicates window.
+type Message = broker.Message
+
+// SubscriptionHandler handles the messages received by the Subscriber.
+// The message is acknowledged when the handler returns nil and redelivered otherwise.
+type SubscriptionHandler = broker.SubscriptionHandler
+
+// toMsg converts the message into a NATS message published on the given subject
+func toMsg(subject string, m *Message) *natsgo.Msg {
+	msg := natsgo.NewMsg(subject)
+	msg.Data = m.Payload
+	for name, value := range m.Attributes {
+		msg.Header.Set(name, value)
+	}
+
+	if m.ID != "" {
+		msg.Header.Set(jetstream.MsgIDHeader, m.ID)
+	}
+
+	if m.Key != "" {
+		msg.Header.Set(KeyHeader, m.Key)
+	}
+	return msg
+}
+
+// fromMsg converts the received JetStream message into a Message
+func fromMsg(msg jetstream.Msg) *Message {
+	message := &Message{Payload: msg.Data()}
+	for name := range msg.Headers() {
+		value := msg.Headers().Get(name)
+		switch name {
+		case jetstream.MsgIDHeader:
+			message.ID = value
+		case KeyHeader:
+			message.Key = value
+		default:
+			if message.Attributes == nil {
+				message.Attributes = make(map[string]string)
+			}
+			message.Attributes[name] = value
+		}
+	}
+	return message
+}
@@ -0,0 +1,219 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package nats
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestSubscriberConfig(t *testing.T) {
+	t.Run("with defaults", func(t *testing.T) {
+		config := &SubscriberConfig{Stream: "orders"}
+		require.NoError(t, config.Validate())
+		assert.Equal(t, DefaultConsumersCount, config.ConsumersCount)
+		assert.Equal(t, DefaultMaxAckPending, config.MaxAckPending)
+		assert.Equal(t, DefaultAckWait, config.AckWait)
+	})
+
+	t.Run("with invalid settings", func(t *testing.T) {
+		config := &SubscriberConfig{ConsumersCount: -1}
+		assert.EqualError(t, config.Validate(),
+			"the stream is required; the consumers count, max ack pending, ack wait and max deliver must not be negative")
+	})
+}
+
+type natsSuite struct {
+	suite.Suite
+	server *TestServer
+	conn   *natsgo.Conn
+}
+
+// SetupSuite starts the embedded NATS server
+func (s *natsSuite) SetupSuite() {
+	s.server = NewTestServer()
+	conn, err := s.server.Connect()
+	s.Require().NoError(err)
+	s.conn = conn
+}
+
+func (s *natsSuite) TearDownSuite() {
+	s.conn.Close()
+	s.server.Cleanup()
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestNatsSuite(t *testing.T) {
+	suite.Run(t, new(natsSuite))
+}
+
+// collector records the handled messages
+type collector struct {
+	mu       sync.Mutex
+	messages []*Message
+}
+
+func (c *collector) handle(_ context.Context, message *Message) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.messages = append(c.messages, message)
+	return nil
+}
+
+func (c *collector) count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.messages)
+}
+
+// consume runs the subscriber in the background and returns the function stopping it
+func (s *natsSuite) consume(subscriber Subscriber, handler SubscriptionHandler) func() {
+	ctx, cancel := context.WithCancel(context.TODO())
+	done := make(chan error, 1)
+	go func() {
+		done <- subscriber.Consume(ctx, handler)
+	}()
+	return func() {
+		cancel()
+		s.Assert().NoError(<-done)
+	}
+}
+
+func (s *natsSuite) TestPublishAndConsume() {
+	ctx := context.TODO()
+	s.Require().NoError(s.server.CreateStream(ctx, "ORDERS", "orders.>"))
+
+	publisher, err := NewPublisher(s.conn)
+	s.Require().NoError(err)
+
+	var messages []*Message
+	for i := 0; i < 30; i++ {
+		messages = append(messages, &Message{
+			ID:         fmt.Sprintf("order-%d", i),
+			Key:        fmt.Sprintf("customer-%d", i%3),
+			Payload:    []byte(fmt.Sprintf("%d", i)),
+			Attributes: map[string]string{"Source": "test"},
+		})
+	}
+	s.Require().NoError(publisher.Publish(ctx, "orders.created", messages...))
+	// the duplicates are dropped by the stream
+	s.Require().NoError(publisher.Publish(ctx, "orders.created", &Message{ID: "order-0", Payload: []byte("duplicate")}))
+
+	subscriber, err := NewSubscriber(s.conn, &SubscriberConfig{
+		Stream:         "ORDERS",
+		FilterSubject:  "orders.created",
+		ConsumersCount: 4,
+	})
+	s.Require().NoError(err)
+
+	received := new(collector)
+	stop := s.consume(subscriber, received.handle)
+	s.Assert().Eventually(func() bool { return received.count() == 30 }, 5*time.Second, 10*time.Millisecond)
+	stop()
+
+	// the messages sharing a key are handled in order
+	last := make(map[string]int)
+	for _, message := range received.messages {
+		s.Assert().Equal("test", message.Attributes["Source"])
+		var position int
+		_, err := fmt.Sscanf(string(message.Payload), "%d", &position)
+		s.Require().NoError(err)
+		if previous, ok := last[message.Key]; ok {
+			s.Assert().Greater(position, previous)
+		}
+		last[message.Key] = position
+	}
+	s.Assert().Len(last, 3)
+}
+
+func (s *natsSuite) TestRedelivery() {
+	ctx := context.TODO()
+	s.Require().NoError(s.server.CreateStream(ctx, "PAYMENTS", "payments.>"))
+
+	publisher, err := NewPublisher(s.conn)
+	s.Require().NoError(err)
+	s.Require().NoError(publisher.Publish(ctx, "payments.captured", &Message{ID: "payment-1", Payload: []byte("10")}))
+
+	subscriber, err := NewSubscriber(s.conn, &SubscriberConfig{Stream: "PAYMENTS"})
+	s.Require().NoError(err)
+
+	mu := sync.Mutex{}
+	attempts := 0
+	stop := s.consume(subscriber, func(_ context.Context, message *Message) error {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		switch attempts {
+		case 1:
+			return errors.New("unavailable")
+		case 2:
+			panic("boom")
+		}
+		return nil
+	})
+	s.Assert().Eventually(func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return attempts == 3
+	}, 5*time.Second, 10*time.Millisecond)
+	stop()
+}
+
+func (s *natsSuite) TestDurableConsumer() {
+	ctx := context.TODO()
+	s.Require().NoError(s.server.CreateStream(ctx, "INVOICES", "invoices.>"))
+
+	publisher, err := NewPublisher(s.conn)
+	s.Require().NoError(err)
+	s.Require().NoError(publisher.Publish(ctx, "invoices.issued", &Message{Payload: []byte("first")}))
+
+	config := &SubscriberConfig{Stream: "INVOICES", Durable: "billing"}
+	subscriber, err := NewSubscriber(s.conn, config)
+	s.Require().NoError(err)
+
+	received := new(collector)
+	stop := s.consume(subscriber, received.handle)
+	s.Assert().Eventually(func() bool { return received.count() == 1 }, 5*time.Second, 10*time.Millisecond)
+	stop()
+
+	// the durable consumer resumes after the acknowledged messages
+	s.Require().NoError(publisher.Publish(ctx, "invoices.issued", &Message{Payload: []byte("second")}))
+	stop = s.consume(subscriber, received.handle)
+	s.Assert().Eventually(func() bool { return received.count() == 2 }, 5*time.Second, 10*time.Millisecond)
+	stop()
+
+	s.Assert().Equal([]byte("first"), received.messages[0].Payload)
+	s.Assert().Equal([]byte("second"), received.messages[1].Payload)
+}
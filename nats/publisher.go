@@ -0,0 +1,82 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package nats
+
+import (
+	"context"
+
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+
+	"github.com/tochemey/gopack/broker"
+)
+
+// instrumentationName is the name of the tracer
+const instrumentationName = "github.com/tochemey/gopack/nats"
+
+// Publisher publishes messages to JetStream subjects
+type Publisher interface {
+	// Publish publishes the messages in the given order to the subject. It returns once the stream
+	// capturing the subject has acknowledged every message or at the first failure.
+	Publish(ctx context.Context, subject string, messages ...*Message) error
+}
+
+// publisher implements Publisher
+type publisher struct {
+	js jetstream.JetStream
+}
+
+// enforce compilation error
+var (
+	_ Publisher        = (*publisher)(nil)
+	_ broker.Publisher = (*publisher)(nil)
+)
+
+// NewPublisher creates an instance of Publisher using the given connection
+func NewPublisher(conn *natsgo.Conn) (Publisher, error) {
+	js, err := jetstream.New(conn)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the JetStream context")
+	}
+	return &publisher{js: js}, nil
+}
+
+// Publish publishes the messages to the subject
+func (p *publisher) Publish(ctx context.Context, subject string, messages ...*Message) error {
+	// Create a span
+	tracer := otel.GetTracerProvider()
+	spanCtx, span := tracer.Tracer(instrumentationName).Start(ctx, "Publish")
+	defer span.End()
+
+	for _, message := range messages {
+		if _, err := p.js.PublishMsg(spanCtx, toMsg(subject, message)); err != nil {
+			span.RecordError(err)
+			return errors.Wrapf(err, "failed to publish the message to (%s)", subject)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package nats
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sync"
+
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+
+	"github.com/tochemey/gopack/broker"
+)
+
+// Subscriber consumes the messages of a JetStream stream
+type Subscriber interface {
+	// Consume handles the messages of the stream with the given handler until the context is done.
+	// The messages sharing a Key are handled sequentially by the same worker while the other messages are
+	// handled in parallel by ConsumersCount workers. A failed message is redelivered after the subsequent
+	// messages of its key unless MaxAckPending is 1.
+	Consume(ctx context.Context, handler SubscriptionHandler) error
+}
+
+// subscriber implements Subscriber
+type subscriber struct {
+	js     jetstream.JetStream
+	config *SubscriberConfig
+}
+
+// enforce compilation error
+var (
+	_ Subscriber        = (*subscriber)(nil)
+	_ broker.Subscriber = (*subscriber)(nil)
+)
+
+// NewSubscriber creates an instance of Subscriber using the given connection
+func NewSubscriber(conn *natsgo.Conn, config *SubscriberConfig) (Subscriber, error) {
+	if err := config.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid subscriber config")
+	}
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the JetStream context")
+	}
+	return &subscriber{js: js, config: config}, nil
+}
+
+// Consume handles the messages of the stream until the context is done
+func (s *subscriber) Consume(ctx context.Context, handler SubscriptionHandler) error {
+	consumer, err := s.js.CreateOrUpdateConsumer(ctx, s.config.Stream, jetstream.ConsumerConfig{
+		Durable:       s.config.Durable,
+		FilterSubject: s.config.FilterSubject,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       s.config.AckWait,
+		MaxDeliver:    s.config.MaxDeliver,
+		MaxAckPending: s.config.MaxAckPending,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to create the consumer of the stream (%s)", s.config.Stream)
+	}
+
+	iterator, err := consumer.Messages()
+	if err != nil {
+		return errors.Wrapf(err, "failed to consume the stream (%s)", s.config.Stream)
+	}
+
+	// stop the iterator when the context is done
+	stop := context.AfterFunc(ctx, iterator.Stop)
+	defer stop()
+
+	// the in-flight messages are handled before returning
+	handlerCtx := context.WithoutCancel(ctx)
+	workers := make([]chan jetstream.Msg, s.config.ConsumersCount)
+	wg := sync.WaitGroup{}
+	for i := range workers {
+		workers[i] = make(chan jetstream.Msg)
+		wg.Add(1)
+		go func(messages <-chan jetstream.Msg) {
+			defer wg.Done()
+			for msg := range messages {
+				s.handle(handlerCtx, handler, msg)
+			}
+		}(workers[i])
+	}
+
+	next := 0
+	for {
+		msg, err := iterator.Next()
+		if err != nil {
+			if errors.Is(err, jetstream.ErrMsgIteratorClosed) {
+				break
+			}
+			continue
+		}
+
+		worker := next % len(workers)
+		if key := msg.Headers().Get(KeyHeader); key != "" {
+			hash := fnv.New32a()
+			_, _ = hash.Write([]byte(key))
+			worker = int(hash.Sum32() % uint32(len(workers)))
+		} else {
+			next++
+		}
+		workers[worker] <- msg
+	}
+
+	for _, messages := range workers {
+		close(messages)
+	}
+	wg.Wait()
+	return nil
+}
+
+// handle runs the handler and acknowledges the message when it succeeds
+func (s *subscriber) handle(ctx context.Context, handler SubscriptionHandler, msg jetstream.Msg) {
+	// Create a span
+	tracer := otel.GetTracerProvider()
+	spanCtx, span := tracer.Tracer(instrumentationName).Start(ctx, "Handle")
+	defer span.End()
+
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("handler panicked: %v", r)
+			}
+		}()
+		return handler(spanCtx, fromMsg(msg))
+	}()
+
+	if err != nil {
+		span.RecordError(err)
+		_ = msg.Nak()
+		return
+	}
+	_ = msg.Ack()
+}
@@ -0,0 +1,110 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package nats
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	natsserver "github.com/nats-io/nats-server/v2/server"
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// TestServer is an embedded NATS server with JetStream enabled to run unit tests
+type TestServer struct {
+	server   *natsserver.Server
+	storeDir string
+}
+
+// NewTestServer starts an embedded NATS server listening on a random port.
+// This function will exit when there is an error. Call Cleanup to stop the server.
+func NewTestServer() *TestServer {
+	storeDir, err := os.MkdirTemp("", "nats-jetstream-")
+	if err != nil {
+		log.Fatalf("Could not create the JetStream store directory: %s", err)
+	}
+
+	server, err := natsserver.NewServer(&natsserver.Options{
+		Host:      "127.0.0.1",
+		Port:      natsserver.RANDOM_PORT,
+		JetStream: true,
+		StoreDir:  storeDir,
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	if err != nil {
+		log.Fatalf("Could not create the NATS server: %s", err)
+	}
+
+	go server.Start()
+	if !server.ReadyForConnections(10 * time.Second) {
+		log.Fatalf("NATS server not ready for connections")
+	}
+
+	return &TestServer{
+		server:   server,
+		storeDir: storeDir,
+	}
+}
+
+// URL returns the client URL of the server
+func (s *TestServer) URL() string {
+	return s.server.ClientURL()
+}
+
+// Connect connects to the server
+func (s *TestServer) Connect() (*natsgo.Conn, error) {
+	return natsgo.Connect(s.URL())
+}
+
+// CreateStream creates the stream capturing the given subjects
+func (s *TestServer) CreateStream(ctx context.Context, name string, subjects ...string) error {
+	conn, err := s.Connect()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		return err
+	}
+
+	_, err = js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:     name,
+		Subjects: subjects,
+	})
+	return err
+}
+
+// Cleanup stops the server and removes its data
+func (s *TestServer) Cleanup() {
+	s.server.Shutdown()
+	s.server.WaitForShutdown()
+	_ = os.RemoveAll(s.storeDir)
+}
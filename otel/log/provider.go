@@ -0,0 +1,107 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package log
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.20.0"
+)
+
+// Provider is a wrapper around the open telemetry logger provider
+// It helps initialize an OTLP exporter, and configures the corresponding logger provider
+// so that the logs are shipped to the same backend as the traces and the metrics
+type Provider struct {
+	serviceName      string
+	serviceVersion   string
+	exporterEndpoint string
+
+	loggerProvider *sdklog.LoggerProvider
+}
+
+// NewProvider creates a new instance of Provider
+func NewProvider(exporterEndPoint, serviceName string, opts ...Option) *Provider {
+	provider := &Provider{
+		serviceName:      serviceName,
+		exporterEndpoint: exporterEndPoint,
+	}
+	for _, opt := range opts {
+		opt.Apply(provider)
+	}
+	return provider
+}
+
+// Start initializes an OTLP exporter, and configures the corresponding logger provider
+func (p *Provider) Start(ctx context.Context) error {
+	// the service name used to display the logs in backends
+	attributes := []attribute.KeyValue{semconv.ServiceNameKey.String(p.serviceName)}
+	if p.serviceVersion != "" {
+		attributes = append(attributes, semconv.ServiceVersionKey.String(p.serviceVersion))
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithHost(),
+		resource.WithProcess(),
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(attributes...),
+	)
+	if err != nil {
+		return err
+	}
+
+	// Set up a log exporter
+	logExporter, err := otlploggrpc.New(ctx,
+		otlploggrpc.WithInsecure(),
+		otlploggrpc.WithEndpoint(p.exporterEndpoint),
+	)
+	if err != nil {
+		return err
+	}
+
+	// Register the log exporter with a Provider, using a batch
+	// processor to aggregate the logs before export.
+	p.loggerProvider = sdklog.NewLoggerProvider(
+		sdklog.WithResource(res),
+		sdklog.WithProcessor(sdklog.NewBatchProcessor(logExporter)),
+	)
+	global.SetLoggerProvider(p.loggerProvider)
+	return nil
+}
+
+// LoggerProvider returns the logger provider set by Start, e.g. to pass to zapl.WithOTLP
+func (p *Provider) LoggerProvider() otellog.LoggerProvider {
+	return p.loggerProvider
+}
+
+// Stop will flush any remaining logs and shut down the exporter.
+func (p *Provider) Stop(ctx context.Context) error {
+	return p.loggerProvider.Shutdown(ctx)
+}
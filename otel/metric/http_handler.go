@@ -0,0 +1,231 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package metric
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/felixge/httpsnoop"
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelmetric "go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.uber.org/multierr"
+)
+
+// instrumentationName is the name of the meter of the HTTP middleware
+const instrumentationName = "github.com/tochemey/gopack/otel/metric"
+
+// httpMiddlewareConfig is used to configure the HTTP middleware.
+type httpMiddlewareConfig struct {
+	MeterProvider otelmetric.MeterProvider
+}
+
+// HTTPMiddlewareOption specifies instrumentation configuration options.
+type HTTPMiddlewareOption interface {
+	apply(*httpMiddlewareConfig)
+}
+
+type optionFunc func(*httpMiddlewareConfig)
+
+func (o optionFunc) apply(c *httpMiddlewareConfig) {
+	o(c)
+}
+
+// WithMeterProvider specifies a meter provider to use for creating the instruments.
+// If none is specified, the global provider is used.
+func WithMeterProvider(provider otelmetric.MeterProvider) HTTPMiddlewareOption {
+	return optionFunc(func(cfg *httpMiddlewareConfig) {
+		cfg.MeterProvider = provider
+	})
+}
+
+// httpMetrics holds the instruments recording the requests served
+type httpMetrics struct {
+	requests     otelmetric.Int64Counter
+	duration     otelmetric.Float64Histogram
+	active       otelmetric.Int64UpDownCounter
+	responseSize otelmetric.Int64Histogram
+}
+
+// newHTTPMetrics creates the HTTP server instruments with the given meter
+func newHTTPMetrics(meter otelmetric.Meter) (*httpMetrics, error) {
+	requests, requestsErr := meter.Int64Counter("http.server.request.count",
+		otelmetric.WithDescription("Number of HTTP server requests."),
+		otelmetric.WithUnit("{request}"))
+	duration, durationErr := meter.Float64Histogram(semconv.HTTPServerRequestDurationName,
+		otelmetric.WithDescription(semconv.HTTPServerRequestDurationDescription),
+		otelmetric.WithUnit(semconv.HTTPServerRequestDurationUnit))
+	active, activeErr := meter.Int64UpDownCounter(semconv.HTTPServerActiveRequestsName,
+		otelmetric.WithDescription(semconv.HTTPServerActiveRequestsDescription),
+		otelmetric.WithUnit(semconv.HTTPServerActiveRequestsUnit))
+	responseSize, responseSizeErr := meter.Int64Histogram(semconv.HTTPServerResponseBodySizeName,
+		otelmetric.WithDescription(semconv.HTTPServerResponseBodySizeDescription),
+		otelmetric.WithUnit(semconv.HTTPServerResponseBodySizeUnit))
+
+	if err := multierr.Combine(requestsErr, durationErr, activeErr, responseSizeErr); err != nil {
+		return nil, err
+	}
+
+	return &httpMetrics{
+		requests:     requests,
+		duration:     duration,
+		active:       active,
+		responseSize: responseSize,
+	}, nil
+}
+
+// Middleware sets up a handler recording the request count, the duration, the in-flight requests
+// and the response size of the incoming requests, partitioned by route pattern, method and status.
+func Middleware(opts ...HTTPMiddlewareOption) func(next http.Handler) http.Handler {
+	cfg := httpMiddlewareConfig{}
+	for _, opt := range opts {
+		opt.apply(&cfg)
+	}
+	if cfg.MeterProvider == nil {
+		cfg.MeterProvider = otel.GetMeterProvider()
+	}
+
+	metrics, err := newHTTPMetrics(cfg.MeterProvider.Meter(instrumentationName))
+	if err != nil {
+		// fall back to instruments recording nothing
+		otel.Handle(err)
+		metrics, _ = newHTTPMetrics(noop.NewMeterProvider().Meter(instrumentationName))
+	}
+
+	return func(handler http.Handler) http.Handler {
+		return metricsWrapper{
+			metrics: metrics,
+			handler: handler,
+		}
+	}
+}
+
+type metricsWrapper struct {
+	metrics *httpMetrics
+	handler http.Handler
+}
+
+// ServeHTTP implements the http.Handler interface. It does the actual
+// recording of the request.
+func (mw metricsWrapper) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	start := time.Now()
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	// the route and the status are not known yet while the request is in flight
+	method := requestMethod(r.Method)
+	activeAttributes := otelmetric.WithAttributes(
+		method,
+		semconv.URLScheme(scheme),
+	)
+	mw.metrics.active.Add(ctx, 1, activeAttributes)
+	defer mw.metrics.active.Add(ctx, -1, activeAttributes)
+
+	status := http.StatusOK
+	var size int64
+	writer := httpsnoop.Wrap(w, httpsnoop.Hooks{
+		Write: func(next httpsnoop.WriteFunc) httpsnoop.WriteFunc {
+			return func(b []byte) (int, error) {
+				n, err := next(b)
+				size += int64(n)
+				return n, err
+			}
+		},
+		WriteHeader: func(next httpsnoop.WriteHeaderFunc) httpsnoop.WriteHeaderFunc {
+			written := false
+			return func(statusCode int) {
+				if !written {
+					written = true
+					status = statusCode
+				}
+				next(statusCode)
+			}
+		},
+		ReadFrom: func(next httpsnoop.ReadFromFunc) httpsnoop.ReadFromFunc {
+			return func(src io.Reader) (int64, error) {
+				n, err := next(src)
+				size += n
+				return n, err
+			}
+		},
+	})
+
+	mw.handler.ServeHTTP(writer, r)
+
+	attributes := []attribute.KeyValue{
+		method,
+		semconv.HTTPResponseStatusCode(status),
+		semconv.URLScheme(scheme),
+	}
+	if route := routePattern(r); route != "" {
+		attributes = append(attributes, semconv.HTTPRoute(route))
+	}
+
+	options := otelmetric.WithAttributes(attributes...)
+	mw.metrics.requests.Add(ctx, 1, options)
+	mw.metrics.duration.Record(ctx, time.Since(start).Seconds(), options)
+	mw.metrics.responseSize.Record(ctx, size, options)
+}
+
+// knownMethods are the HTTP methods recorded as is
+var knownMethods = map[string]attribute.KeyValue{
+	http.MethodConnect: semconv.HTTPRequestMethodConnect,
+	http.MethodDelete:  semconv.HTTPRequestMethodDelete,
+	http.MethodGet:     semconv.HTTPRequestMethodGet,
+	http.MethodHead:    semconv.HTTPRequestMethodHead,
+	http.MethodOptions: semconv.HTTPRequestMethodOptions,
+	http.MethodPatch:   semconv.HTTPRequestMethodPatch,
+	http.MethodPost:    semconv.HTTPRequestMethodPost,
+	http.MethodPut:     semconv.HTTPRequestMethodPut,
+	http.MethodTrace:   semconv.HTTPRequestMethodTrace,
+}
+
+// requestMethod returns the method attribute of the request.
+// The methods outside the standard set are recorded as _OTHER to keep the cardinality low.
+func requestMethod(method string) attribute.KeyValue {
+	if attr, ok := knownMethods[method]; ok {
+		return attr
+	}
+	return semconv.HTTPRequestMethodOther
+}
+
+// routePattern returns the chi route pattern matched by the request, e.g. /user/{id}.
+// It is empty when the request has not been routed by chi to keep the cardinality low.
+func routePattern(r *http.Request) string {
+	routeCtx := chi.RouteContext(r.Context())
+	if routeCtx == nil {
+		return ""
+	}
+	return routeCtx.RoutePattern()
+}
@@ -0,0 +1,155 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package metric
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestMiddleware(t *testing.T) {
+	reader := metric.NewManualReader()
+	provider := metric.NewMeterProvider(metric.WithReader(reader))
+
+	var active int64
+	router := chi.NewRouter()
+	router.Use(Middleware(WithMeterProvider(provider)))
+	router.Get("/user/{id}", func(w http.ResponseWriter, _ *http.Request) {
+		active = collect(t, reader)["http.server.active_requests"].(metricdata.Sum[int64]).DataPoints[0].Value
+		_, _ = w.Write([]byte("hello"))
+	})
+	router.Get("/book/{title}", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/user/123", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/user/456", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/book/foo", nil))
+
+	// the request was in flight while the handler ran
+	assert.EqualValues(t, 1, active)
+
+	metrics := collect(t, reader)
+	user := attribute.NewSet(
+		attribute.String("http.request.method", "GET"),
+		attribute.Int("http.response.status_code", http.StatusOK),
+		attribute.String("url.scheme", "http"),
+		attribute.String("http.route", "/user/{id}"),
+	)
+	book := attribute.NewSet(
+		attribute.String("http.request.method", "GET"),
+		attribute.Int("http.response.status_code", http.StatusNotFound),
+		attribute.String("url.scheme", "http"),
+		attribute.String("http.route", "/book/{title}"),
+	)
+
+	requests := metrics["http.server.request.count"].(metricdata.Sum[int64])
+	require.Len(t, requests.DataPoints, 2)
+	assert.EqualValues(t, 2, dataPoint(t, requests.DataPoints, user).Value)
+	assert.EqualValues(t, 1, dataPoint(t, requests.DataPoints, book).Value)
+
+	duration := metrics["http.server.request.duration"].(metricdata.Histogram[float64])
+	require.Len(t, duration.DataPoints, 2)
+
+	sizes := metrics["http.server.response.body.size"].(metricdata.Histogram[int64])
+	require.Len(t, sizes.DataPoints, 2)
+	for _, point := range sizes.DataPoints {
+		if point.Attributes.Equals(&user) {
+			assert.EqualValues(t, 10, point.Sum)
+			assert.EqualValues(t, 2, point.Count)
+		}
+	}
+
+	inFlight := metrics["http.server.active_requests"].(metricdata.Sum[int64])
+	require.Len(t, inFlight.DataPoints, 1)
+	assert.Zero(t, inFlight.DataPoints[0].Value)
+}
+
+func TestMiddlewareWithoutRoutePattern(t *testing.T) {
+	reader := metric.NewManualReader()
+	provider := metric.NewMeterProvider(metric.WithReader(reader))
+
+	handler := Middleware(WithMeterProvider(provider))(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/user/123", nil))
+
+	requests := collect(t, reader)["http.server.request.count"].(metricdata.Sum[int64])
+	require.Len(t, requests.DataPoints, 1)
+	_, ok := requests.DataPoints[0].Attributes.Value("http.route")
+	assert.False(t, ok)
+}
+
+func TestMiddlewareWithNonStandardMethod(t *testing.T) {
+	reader := metric.NewManualReader()
+	provider := metric.NewMeterProvider(metric.WithReader(reader))
+
+	handler := Middleware(WithMeterProvider(provider))(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("FOO", "/", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("BAR", "/", nil))
+
+	requests := collect(t, reader)["http.server.request.count"].(metricdata.Sum[int64])
+	require.Len(t, requests.DataPoints, 1)
+	method, ok := requests.DataPoints[0].Attributes.Value("http.request.method")
+	require.True(t, ok)
+	assert.Equal(t, "_OTHER", method.AsString())
+	assert.EqualValues(t, 2, requests.DataPoints[0].Value)
+}
+
+// collect returns the data of the metrics collected by the reader by their name
+func collect(t *testing.T, reader metric.Reader) map[string]metricdata.Aggregation {
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+
+	metrics := make(map[string]metricdata.Aggregation)
+	for _, scope := range rm.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			metrics[m.Name] = m.Data
+		}
+	}
+	return metrics
+}
+
+// dataPoint returns the data point with the given attributes
+func dataPoint[N int64 | float64](t *testing.T, points []metricdata.DataPoint[N], attributes attribute.Set) metricdata.DataPoint[N] {
+	for _, point := range points {
+		if point.Attributes.Equals(&attributes) {
+			return point
+		}
+	}
+	require.Failf(t, "data point not found", "attributes: %v", attributes.ToSlice())
+	return metricdata.DataPoint[N]{}
+}
@@ -0,0 +1,78 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package metric
+
+import (
+	"crypto/tls"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ProviderOption is the interface that applies a configuration option to a Provider.
+type ProviderOption interface {
+	// Apply sets the ProviderOption value of a Provider.
+	Apply(*Provider)
+}
+
+var _ ProviderOption = ProviderOptionFunc(nil)
+
+// ProviderOptionFunc implements the ProviderOption interface.
+type ProviderOptionFunc func(*Provider)
+
+// Apply applies the option
+func (f ProviderOptionFunc) Apply(p *Provider) {
+	f(p)
+}
+
+// WithTLS dials the OTLP endpoint with the given TLS configuration instead of insecurely
+func WithTLS(config *tls.Config) ProviderOption {
+	return ProviderOptionFunc(func(p *Provider) {
+		p.tlsConfig = config
+	})
+}
+
+// WithHeaders sets the headers sent with every export, e.g. the authentication headers of a hosted backend
+func WithHeaders(headers map[string]string) ProviderOption {
+	return ProviderOptionFunc(func(p *Provider) {
+		p.headers = headers
+	})
+}
+
+// WithHTTPExporter exports the metrics with OTLP over HTTP instead of gRPC, e.g. when only
+// the outbound HTTP traffic is allowed. The endpoint is then the host and port of the
+// collector HTTP receiver, e.g. localhost:4318.
+func WithHTTPExporter() ProviderOption {
+	return ProviderOptionFunc(func(p *Provider) {
+		p.httpExporter = true
+	})
+}
+
+// WithResourceAttributes adds the given attributes to the resource of the exported metrics,
+// e.g. the service version or the deployment environment
+func WithResourceAttributes(attributes ...attribute.KeyValue) ProviderOption {
+	return ProviderOptionFunc(func(p *Provider) {
+		p.attributes = append(p.attributes, attributes...)
+	})
+}
@@ -26,13 +26,17 @@ package metric
 
 import (
 	"context"
+	"crypto/tls"
 	"time"
 
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
 	"go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
 	semconv "go.opentelemetry.io/otel/semconv/v1.20.0"
+	"google.golang.org/grpc/credentials"
 )
 
 // Provider is a wrapper around the open telemetry  metric provider
@@ -40,17 +44,25 @@ type Provider struct {
 	serviceName      string
 	exporterEndpoint string
 	exportFrequency  time.Duration
+	tlsConfig        *tls.Config
+	headers          map[string]string
+	httpExporter     bool
+	attributes       []attribute.KeyValue
 
 	metricProvider *metric.MeterProvider
 }
 
 // NewProvider creates a new instance of TraceProvider
-func NewProvider(exporterEndPoint, serviceName string, exportFrequency time.Duration) *Provider {
-	return &Provider{
+func NewProvider(exporterEndPoint, serviceName string, exportFrequency time.Duration, opts ...ProviderOption) *Provider {
+	provider := &Provider{
 		serviceName:      serviceName,
 		exporterEndpoint: exporterEndPoint,
 		exportFrequency:  exportFrequency,
 	}
+	for _, opt := range opts {
+		opt.Apply(provider)
+	}
+	return provider
 }
 
 // Start initializes an OTLP exporter, and configures the corresponding metrics provider
@@ -63,16 +75,17 @@ func (p *Provider) Start(ctx context.Context) error {
 			// the service name used to display traces in backends
 			semconv.ServiceNameKey.String(p.serviceName),
 		),
+		resource.WithAttributes(p.attributes...),
 	)
 	if err != nil {
 		return err
 	}
 
-	// Set up a trace exporter
-	metricExporter, err := otlpmetricgrpc.New(ctx,
-		otlpmetricgrpc.WithInsecure(),
-		otlpmetricgrpc.WithEndpoint(p.exporterEndpoint),
-	)
+	// Set up a metric exporter
+	metricExporter, err := p.newExporter(ctx)
+	if err != nil {
+		return err
+	}
 
 	// set the metric provider
 	p.metricProvider = metric.NewMeterProvider(
@@ -86,6 +99,33 @@ func (p *Provider) Start(ctx context.Context) error {
 	return nil
 }
 
+// newExporter creates the OTLP exporter over gRPC or HTTP
+func (p *Provider) newExporter(ctx context.Context) (metric.Exporter, error) {
+	if p.httpExporter {
+		opts := []otlpmetrichttp.Option{otlpmetrichttp.WithEndpoint(p.exporterEndpoint)}
+		if p.tlsConfig != nil {
+			opts = append(opts, otlpmetrichttp.WithTLSClientConfig(p.tlsConfig))
+		} else {
+			opts = append(opts, otlpmetrichttp.WithInsecure())
+		}
+		if len(p.headers) > 0 {
+			opts = append(opts, otlpmetrichttp.WithHeaders(p.headers))
+		}
+		return otlpmetrichttp.New(ctx, opts...)
+	}
+
+	opts := []otlpmetricgrpc.Option{otlpmetricgrpc.WithEndpoint(p.exporterEndpoint)}
+	if p.tlsConfig != nil {
+		opts = append(opts, otlpmetricgrpc.WithTLSCredentials(credentials.NewTLS(p.tlsConfig)))
+	} else {
+		opts = append(opts, otlpmetricgrpc.WithInsecure())
+	}
+	if len(p.headers) > 0 {
+		opts = append(opts, otlpmetricgrpc.WithHeaders(p.headers))
+	}
+	return otlpmetricgrpc.New(ctx, opts...)
+}
+
 // Stop will flush any remaining metrics and shut down the exporter.
 func (p *Provider) Stop(ctx context.Context) error {
 	return p.metricProvider.Shutdown(ctx)
@@ -27,6 +27,9 @@ package metric
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -34,6 +37,7 @@ import (
 
 	"github.com/stretchr/testify/suite"
 	"github.com/travisjeffery/go-dynaport"
+	"go.opentelemetry.io/otel"
 )
 
 type ProviderTestSuite struct {
@@ -83,3 +87,30 @@ func (s *ProviderTestSuite) TestStartAndStop() {
 	err = p.Stop(ctx)
 	s.Assert().NoError(err)
 }
+
+func (s *ProviderTestSuite) TestStartAndStopWithHTTPExporter() {
+	ctx := context.TODO()
+	exports := new(atomic.Int32)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/metrics" && r.Header.Get("x-api-key") == "secret" {
+			exports.Add(1)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	p := NewProvider(server.Listener.Addr().String(), s.serviceName, time.Minute,
+		WithHTTPExporter(),
+		WithHeaders(map[string]string{"x-api-key": "secret"}),
+	)
+	s.Assert().True(p.httpExporter)
+
+	s.Require().NoError(p.Start(ctx))
+	counter, err := otel.Meter("test").Int64Counter("requests")
+	s.Require().NoError(err)
+	counter.Add(ctx, 1)
+
+	// the remaining metrics are exported on stop
+	s.Assert().NoError(p.Stop(ctx))
+	s.Assert().EqualValues(1, exports.Load())
+}
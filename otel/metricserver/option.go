@@ -0,0 +1,69 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package metricserver
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Option is the interface that applies a configuration option.
+type Option interface {
+	// Apply sets the Option value of a config.
+	Apply(*Server)
+}
+
+var _ Option = OptionFunc(nil)
+
+// OptionFunc implements the Option interface.
+type OptionFunc func(*Server)
+
+// Apply applies the option
+func (f OptionFunc) Apply(s *Server) {
+	f(s)
+}
+
+// WithGatherer sets the gatherer collecting the metrics to expose
+func WithGatherer(gatherer prometheus.Gatherer) Option {
+	return OptionFunc(func(s *Server) {
+		s.gatherer = gatherer
+	})
+}
+
+// WithPprof enables or disables the pprof endpoints. They are enabled by default.
+func WithPprof(enabled bool) Option {
+	return OptionFunc(func(s *Server) {
+		s.enablePprof = enabled
+	})
+}
+
+// WithHealthCheck serves the /healthz endpoint which responds 200 when the given check
+// succeeds and 503 otherwise. The endpoint is not served by default.
+func WithHealthCheck(check func(ctx context.Context) error) Option {
+	return OptionFunc(func(s *Server) {
+		s.healthCheck = check
+	})
+}
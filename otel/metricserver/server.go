@@ -0,0 +1,158 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+// Package metricserver serves the Prometheus metrics, the health and the pprof endpoints
+// on a dedicated admin port next to the main service server.
+package metricserver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	// MetricsPath is the path serving the Prometheus metrics
+	MetricsPath = "/metrics"
+	// PprofPath is the path prefix serving the pprof profiles
+	PprofPath = "/debug/pprof/"
+	// HealthzPath is the path serving the health check
+	HealthzPath = "/healthz"
+)
+
+// ErrAlreadyStarted is returned when the server is started more than once
+var ErrAlreadyStarted = errors.New("metric server is already started")
+
+// Server is the admin HTTP server exposing the metrics and the pprof endpoints
+type Server struct {
+	addr        string
+	gatherer    prometheus.Gatherer
+	enablePprof bool
+	healthCheck func(ctx context.Context) error
+
+	mu       sync.Mutex
+	server   *http.Server
+	listener net.Listener
+	errCh    chan error
+}
+
+// New creates an instance of Server listening on the given address.
+// By default, the metrics are gathered from prometheus.DefaultGatherer which is where the gRPC
+// metrics interceptors and the MeterProvider created by NewMeterProvider register their collectors.
+func New(addr string, opts ...Option) *Server {
+	server := &Server{
+		addr:        addr,
+		gatherer:    prometheus.DefaultGatherer,
+		enablePprof: true,
+	}
+	for _, opt := range opts {
+		opt.Apply(server)
+	}
+	return server
+}
+
+// Start starts listening on the admin address and serves the requests in a separate go-routine
+func (s *Server) Start(context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.server != nil {
+		return ErrAlreadyStarted
+	}
+
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+
+	s.listener = listener
+	s.server = &http.Server{Handler: s.Handler()}
+	s.errCh = make(chan error, 1)
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.errCh <- err
+		}
+		close(s.errCh)
+	}()
+	return nil
+}
+
+// Stop gracefully shuts down the server. It waits for the in-flight requests to complete
+// until the given context is done. It returns the serving error, if any.
+func (s *Server) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	server, errCh := s.server, s.errCh
+	s.server, s.listener = nil, nil
+	s.mu.Unlock()
+
+	if server == nil {
+		return nil
+	}
+
+	if err := server.Shutdown(ctx); err != nil {
+		return err
+	}
+	return <-errCh
+}
+
+// Addr returns the address the server is listening on.
+// This is useful when the server listens on a random port.
+func (s *Server) Addr() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.listener == nil {
+		return s.addr
+	}
+	return s.listener.Addr().String()
+}
+
+// Handler returns the HTTP handler serving the admin endpoints.
+// This is useful when one wants to mount the endpoints on an existing HTTP server.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle(MetricsPath, promhttp.HandlerFor(s.gatherer, promhttp.HandlerOpts{}))
+	if s.healthCheck != nil {
+		mux.HandleFunc(HealthzPath, func(w http.ResponseWriter, r *http.Request) {
+			if err := s.healthCheck(r.Context()); err != nil {
+				http.Error(w, err.Error(), http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		})
+	}
+	if s.enablePprof {
+		mux.HandleFunc(PprofPath, pprof.Index)
+		mux.HandleFunc(PprofPath+"cmdline", pprof.Cmdline)
+		mux.HandleFunc(PprofPath+"profile", pprof.Profile)
+		mux.HandleFunc(PprofPath+"symbol", pprof.Symbol)
+		mux.HandleFunc(PprofPath+"trace", pprof.Trace)
+	}
+	return mux
+}
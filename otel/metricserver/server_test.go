@@ -0,0 +1,129 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package metricserver
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+func TestServer(t *testing.T) {
+	t.Run("with metrics and pprof", func(t *testing.T) {
+		ctx := context.TODO()
+		registry := prometheus.NewRegistry()
+		counter := prometheus.NewCounter(prometheus.CounterOpts{Name: "test_requests_total", Help: "test counter"})
+		registry.MustRegister(counter)
+		counter.Inc()
+
+		server := New("127.0.0.1:0", WithGatherer(registry))
+		require.NoError(t, server.Start(ctx))
+		assert.ErrorIs(t, server.Start(ctx), ErrAlreadyStarted)
+
+		body, status := get(t, "http://"+server.Addr()+MetricsPath)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Contains(t, body, "test_requests_total 1")
+
+		_, status = get(t, "http://"+server.Addr()+PprofPath)
+		assert.Equal(t, http.StatusOK, status)
+
+		require.NoError(t, server.Stop(ctx))
+		_, err := http.Get("http://" + server.Addr() + MetricsPath) //nolint
+		assert.Error(t, err)
+	})
+	t.Run("with OpenTelemetry metrics", func(t *testing.T) {
+		ctx := context.TODO()
+		registry := prometheus.NewRegistry()
+		provider, err := NewMeterProvider(registry)
+		require.NoError(t, err)
+		defer func() { assert.NoError(t, provider.Shutdown(ctx)) }()
+
+		counter, err := provider.Meter("test").Int64Counter("jobs.runs")
+		require.NoError(t, err)
+		counter.Add(ctx, 2, metric.WithAttributes(attribute.String("job", "cleanup")))
+
+		server := New("127.0.0.1:0", WithGatherer(registry))
+		require.NoError(t, server.Start(ctx))
+
+		body, status := get(t, "http://"+server.Addr()+MetricsPath)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Contains(t, body, `jobs_runs_total{job="cleanup",otel_scope_name="test",otel_scope_version=""} 2`)
+		require.NoError(t, server.Stop(ctx))
+	})
+	t.Run("without pprof", func(t *testing.T) {
+		ctx := context.TODO()
+		server := New("127.0.0.1:0", WithPprof(false))
+		require.NoError(t, server.Start(ctx))
+
+		_, status := get(t, "http://"+server.Addr()+PprofPath)
+		assert.Equal(t, http.StatusNotFound, status)
+		require.NoError(t, server.Stop(ctx))
+	})
+	t.Run("with health check", func(t *testing.T) {
+		ctx := context.TODO()
+		var healthy atomic.Bool
+		server := New("127.0.0.1:0", WithHealthCheck(func(context.Context) error {
+			if !healthy.Load() {
+				return errors.New("not ready")
+			}
+			return nil
+		}))
+		require.NoError(t, server.Start(ctx))
+
+		body, status := get(t, "http://"+server.Addr()+HealthzPath)
+		assert.Equal(t, http.StatusServiceUnavailable, status)
+		assert.Contains(t, body, "not ready")
+
+		healthy.Store(true)
+		_, status = get(t, "http://"+server.Addr()+HealthzPath)
+		assert.Equal(t, http.StatusOK, status)
+		require.NoError(t, server.Stop(ctx))
+	})
+	t.Run("with invalid address", func(t *testing.T) {
+		server := New("invalid-address")
+		assert.Error(t, server.Start(context.TODO()))
+		assert.NoError(t, server.Stop(context.TODO()))
+	})
+}
+
+// get fetches the given url and returns the body and the status code
+func get(t *testing.T, url string) (string, int) {
+	t.Helper()
+	resp, err := http.Get(url) //nolint
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(body), resp.StatusCode
+}
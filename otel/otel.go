@@ -0,0 +1,163 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package otel
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.20.0"
+
+	"github.com/tochemey/gopack/otel/metric"
+	"github.com/tochemey/gopack/otel/trace"
+)
+
+// defaultMetricExportFrequency is the interval at which metrics are exported when not set
+const defaultMetricExportFrequency = 30 * time.Second
+
+var errMissingServiceName = errors.New("the service name is required")
+
+// Config holds the telemetry settings of a service.
+// An empty endpoint disables the corresponding signal.
+type Config struct {
+	// ServiceName is the name of the service reported with the traces and metrics
+	ServiceName string
+	// ServiceVersion is the version of the service
+	ServiceVersion string
+	// Environment is the deployment environment, e.g. production or staging
+	Environment string
+	// TraceEndpoint is the OTLP endpoint the traces are exported to
+	TraceEndpoint string
+	// MetricEndpoint is the OTLP endpoint the metrics are exported to
+	MetricEndpoint string
+	// MetricExportFrequency is the interval at which metrics are exported. It defaults to 30 seconds
+	MetricExportFrequency time.Duration
+	// HTTPExporter exports over OTLP/HTTP instead of OTLP/gRPC
+	HTTPExporter bool
+	// TLS dials the endpoints with the given TLS configuration instead of insecurely
+	TLS *tls.Config
+	// Headers are sent with every export, e.g. the authentication headers of a hosted backend
+	Headers map[string]string
+	// Sampler samples the traces. It defaults to sdktrace.AlwaysSample
+	Sampler sdktrace.Sampler
+}
+
+// Shutdown flushes any remaining telemetry and shuts down the exporters
+type Shutdown func(ctx context.Context) error
+
+// Setup initializes the trace provider, the metric provider and the propagators
+// of the service in one call. The returned Shutdown stops all of them.
+func Setup(ctx context.Context, config *Config) (Shutdown, error) {
+	if config.ServiceName == "" {
+		return nil, errMissingServiceName
+	}
+
+	var shutdowns []Shutdown
+	shutdown := func(ctx context.Context) error {
+		var err error
+		// stop the providers in the reverse order of their start
+		for i := len(shutdowns) - 1; i >= 0; i-- {
+			err = errors.Join(err, shutdowns[i](ctx))
+		}
+		return err
+	}
+
+	attributes := config.resourceAttributes()
+	if config.TraceEndpoint != "" {
+		provider := trace.NewProvider(config.TraceEndpoint, config.ServiceName, config.traceOptions(attributes)...)
+		if err := provider.Start(ctx); err != nil {
+			return nil, errors.Join(err, shutdown(ctx))
+		}
+		shutdowns = append(shutdowns, provider.Stop)
+	}
+
+	if config.MetricEndpoint != "" {
+		frequency := config.MetricExportFrequency
+		if frequency <= 0 {
+			frequency = defaultMetricExportFrequency
+		}
+
+		provider := metric.NewProvider(config.MetricEndpoint, config.ServiceName, frequency, config.metricOptions(attributes)...)
+		if err := provider.Start(ctx); err != nil {
+			return nil, errors.Join(err, shutdown(ctx))
+		}
+		shutdowns = append(shutdowns, provider.Stop)
+	}
+
+	// set global propagator to trace context and baggage (the default is no-op).
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return shutdown, nil
+}
+
+// resourceAttributes returns the resource attributes of the service other than its name
+func (c *Config) resourceAttributes() []attribute.KeyValue {
+	var attributes []attribute.KeyValue
+	if c.ServiceVersion != "" {
+		attributes = append(attributes, semconv.ServiceVersionKey.String(c.ServiceVersion))
+	}
+	if c.Environment != "" {
+		attributes = append(attributes, semconv.DeploymentEnvironmentKey.String(c.Environment))
+	}
+	return attributes
+}
+
+// traceOptions maps the configuration onto the trace provider options
+func (c *Config) traceOptions(attributes []attribute.KeyValue) []trace.ProviderOption {
+	opts := []trace.ProviderOption{trace.WithResourceAttributes(attributes...)}
+	if c.TLS != nil {
+		opts = append(opts, trace.WithTLS(c.TLS))
+	}
+	if len(c.Headers) > 0 {
+		opts = append(opts, trace.WithHeaders(c.Headers))
+	}
+	if c.Sampler != nil {
+		opts = append(opts, trace.WithSampler(c.Sampler))
+	}
+	if c.HTTPExporter {
+		opts = append(opts, trace.WithHTTPExporter())
+	}
+	return opts
+}
+
+// metricOptions maps the configuration onto the metric provider options
+func (c *Config) metricOptions(attributes []attribute.KeyValue) []metric.ProviderOption {
+	opts := []metric.ProviderOption{metric.WithResourceAttributes(attributes...)}
+	if c.TLS != nil {
+		opts = append(opts, metric.WithTLS(c.TLS))
+	}
+	if len(c.Headers) > 0 {
+		opts = append(opts, metric.WithHeaders(c.Headers))
+	}
+	if c.HTTPExporter {
+		opts = append(opts, metric.WithHTTPExporter())
+	}
+	return opts
+}
@@ -0,0 +1,88 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package otel
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/travisjeffery/go-dynaport"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.20.0"
+
+	"github.com/tochemey/gopack/otel/testkit"
+)
+
+func TestSetup(t *testing.T) {
+	t.Run("With traces and metrics", func(t *testing.T) {
+		ctx := context.Background()
+		endpoint := fmt.Sprintf(":%d", dynaport.Get(1)[0])
+		collector, err := testkit.StartOtelCollectorWithEndpoint(endpoint)
+		require.NoError(t, err)
+		defer func() { assert.NoError(t, collector.Stop()) }()
+
+		shutdown, err := Setup(ctx, &Config{
+			ServiceName:           "setup-test",
+			ServiceVersion:        "v1.0.0",
+			Environment:           "test",
+			TraceEndpoint:         endpoint,
+			MetricEndpoint:        endpoint,
+			MetricExportFrequency: time.Second,
+		})
+		require.NoError(t, err)
+		require.NotNil(t, shutdown)
+
+		fields := otel.GetTextMapPropagator().Fields()
+		assert.ElementsMatch(t, propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}).Fields(), fields)
+		assert.NoError(t, shutdown(ctx))
+	})
+	t.Run("With no endpoint", func(t *testing.T) {
+		ctx := context.Background()
+		shutdown, err := Setup(ctx, &Config{ServiceName: "setup-test"})
+		require.NoError(t, err)
+		assert.NoError(t, shutdown(ctx))
+	})
+	t.Run("With missing service name", func(t *testing.T) {
+		shutdown, err := Setup(context.Background(), &Config{TraceEndpoint: "localhost:4317"})
+		assert.ErrorIs(t, err, errMissingServiceName)
+		assert.Nil(t, shutdown)
+	})
+	t.Run("With resource attributes", func(t *testing.T) {
+		config := &Config{ServiceName: "setup-test", ServiceVersion: "v1.0.0", Environment: "staging"}
+		attributes := config.resourceAttributes()
+		assert.ElementsMatch(t, []attribute.KeyValue{
+			semconv.ServiceVersionKey.String("v1.0.0"),
+			semconv.DeploymentEnvironmentKey.String("staging"),
+		}, attributes)
+		assert.Len(t, config.traceOptions(attributes), 1)
+		assert.Len(t, config.metricOptions(attributes), 1)
+	})
+}
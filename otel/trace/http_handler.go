@@ -25,14 +25,20 @@
 package trace
 
 import (
+	"io"
+	"net"
 	"net/http"
+	"strconv"
 	"sync"
 
 	"github.com/felixge/httpsnoop"
+	"github.com/go-chi/chi/v5"
 	"go.opentelemetry.io/contrib"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/propagation"
-	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 	oteltrace "go.opentelemetry.io/otel/trace"
 )
 
@@ -113,6 +119,7 @@ type recordingResponseWriter struct {
 	writer  http.ResponseWriter
 	written bool
 	status  int
+	size    int64
 }
 
 var rrwPool = &sync.Pool{
@@ -125,6 +132,7 @@ func getRRW(writer http.ResponseWriter) *recordingResponseWriter {
 	rrw := rrwPool.Get().(*recordingResponseWriter)
 	rrw.written = false
 	rrw.status = 0
+	rrw.size = 0
 	rrw.writer = httpsnoop.Wrap(writer, httpsnoop.Hooks{
 		Write: func(next httpsnoop.WriteFunc) httpsnoop.WriteFunc {
 			return func(b []byte) (int, error) {
@@ -132,7 +140,9 @@ func getRRW(writer http.ResponseWriter) *recordingResponseWriter {
 					rrw.written = true
 					rrw.status = http.StatusOK
 				}
-				return next(b)
+				n, err := next(b)
+				rrw.size += int64(n)
+				return n, err
 			}
 		},
 		WriteHeader: func(next httpsnoop.WriteHeaderFunc) httpsnoop.WriteHeaderFunc {
@@ -144,6 +154,17 @@ func getRRW(writer http.ResponseWriter) *recordingResponseWriter {
 				next(statusCode)
 			}
 		},
+		ReadFrom: func(next httpsnoop.ReadFromFunc) httpsnoop.ReadFromFunc {
+			return func(src io.Reader) (int64, error) {
+				if !rrw.written {
+					rrw.written = true
+					rrw.status = http.StatusOK
+				}
+				n, err := next(src)
+				rrw.size += n
+				return n, err
+			}
+		},
 	})
 	return rrw
 }
@@ -153,26 +174,106 @@ func putRRW(rrw *recordingResponseWriter) {
 	rrwPool.Put(rrw)
 }
 
+// countingBody counts the bytes of the request body read by the handler
+type countingBody struct {
+	io.ReadCloser
+	size int64
+}
+
+// Read implements io.Reader
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.size += int64(n)
+	return n, err
+}
+
 // ServeHTTP implements the http.Handler interface. It does the actual
 // tracing of the request.
 func (tw traceWrapper) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	ctx := tw.propagators.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
-	ctx, span := tw.tracer.Start(ctx, "", oteltrace.WithSpanKind(oteltrace.SpanKindServer))
+	ctx, span := tw.tracer.Start(ctx, r.Method, oteltrace.WithSpanKind(oteltrace.SpanKindServer))
 	defer span.End()
 
 	r2 := r.WithContext(ctx)
+	var body *countingBody
+	if r.Body != nil && r.Body != http.NoBody {
+		body = &countingBody{ReadCloser: r.Body}
+		r2.Body = body
+	}
+
 	rrw := getRRW(w)
 	defer putRRW(rrw)
 	tw.handler.ServeHTTP(rrw.writer, r2)
 
-	routeStr := r.URL.String()
-	attrs := semconv.HTTPAttributesFromHTTPStatusCode(rrw.status)
-	attrs = append(attrs, semconv.NetAttributesFromHTTPRequest("tcp", r2)...)
-	attrs = append(attrs, semconv.EndUserAttributesFromHTTPRequest(r2)...)
-	attrs = append(attrs, semconv.HTTPServerAttributesFromHTTPRequest(tw.serverName, routeStr, r2)...)
+	status := rrw.status
+	if status == 0 {
+		// the handler did not write anything, net/http replies with 200
+		status = http.StatusOK
+	}
+
+	attrs := tw.attributes(r2, status)
+	attrs = append(attrs, semconv.HTTPResponseBodySize(int(rrw.size)))
+	if body != nil {
+		attrs = append(attrs, semconv.HTTPRequestBodySize(int(body.size)))
+	}
+
+	// name the span after the route pattern to keep its cardinality low
+	if route := routePattern(r2); route != "" {
+		attrs = append(attrs, semconv.HTTPRoute(route))
+		span.SetName(route)
+	}
 	span.SetAttributes(attrs...)
-	span.SetName(routeStr)
 
-	spanStatus, spanMessage := semconv.SpanStatusFromHTTPStatusCode(rrw.status)
-	span.SetStatus(spanStatus, spanMessage)
+	// only the server errors are span errors, the client ones are not the server faults
+	if status >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(status))
+	}
+}
+
+// attributes returns the semantic conventions attributes of the request
+func (tw traceWrapper) attributes(r *http.Request, status int) []attribute.KeyValue {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	attrs := []attribute.KeyValue{
+		semconv.HTTPRequestMethodKey.String(r.Method),
+		semconv.HTTPResponseStatusCode(status),
+		semconv.URLPath(r.URL.Path),
+		semconv.URLScheme(scheme),
+		semconv.NetworkProtocolVersion(strconv.Itoa(r.ProtoMajor) + "." + strconv.Itoa(r.ProtoMinor)),
+	}
+
+	serverName := tw.serverName
+	if serverName == "" {
+		serverName = r.Host
+	}
+	if host, port, err := net.SplitHostPort(serverName); err == nil {
+		attrs = append(attrs, semconv.ServerAddress(host))
+		if p, err := strconv.Atoi(port); err == nil {
+			attrs = append(attrs, semconv.ServerPort(p))
+		}
+	} else if serverName != "" {
+		attrs = append(attrs, semconv.ServerAddress(serverName))
+	}
+
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		attrs = append(attrs, semconv.ClientAddress(host))
+	}
+
+	if userAgent := r.UserAgent(); userAgent != "" {
+		attrs = append(attrs, semconv.UserAgentOriginal(userAgent))
+	}
+	return attrs
+}
+
+// routePattern returns the chi route pattern matched by the request, e.g. /user/{id}.
+// It is empty when the request has not been routed by chi.
+func routePattern(r *http.Request) string {
+	routeCtx := chi.RouteContext(r.Context())
+	if routeCtx == nil {
+		return ""
+	}
+	return routeCtx.RoutePattern()
 }
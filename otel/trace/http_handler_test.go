@@ -31,6 +31,7 @@ import (
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/go-chi/chi/v5"
@@ -38,6 +39,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/propagation"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/sdk/trace/tracetest"
@@ -198,25 +200,77 @@ func TestSDKIntegration(t *testing.T) {
 
 	require.Len(t, sr.Ended(), 2)
 	assertSpan(t, sr.Ended()[0],
-		"/user/123",
+		"/user/{id:[0-9]+}",
 		trace.SpanKindServer,
-		attribute.String("http.server_name", "foobar"),
-		attribute.Int("http.status_code", http.StatusOK),
-		attribute.String("http.method", "GET"),
-		attribute.String("http.target", "/user/123"),
-		attribute.String("http.route", "/user/123"),
+		attribute.String("server.address", "foobar"),
+		attribute.Int("http.response.status_code", http.StatusOK),
+		attribute.String("http.request.method", "GET"),
+		attribute.String("url.path", "/user/123"),
+		attribute.String("http.route", "/user/{id:[0-9]+}"),
 	)
 	assertSpan(t, sr.Ended()[1],
-		"/book/foo",
+		"/book/{title}",
 		trace.SpanKindServer,
-		attribute.String("http.server_name", "foobar"),
-		attribute.Int("http.status_code", http.StatusOK),
-		attribute.String("http.method", "GET"),
-		attribute.String("http.target", "/book/foo"),
-		attribute.String("http.route", "/book/foo"),
+		attribute.String("server.address", "foobar"),
+		attribute.Int("http.response.status_code", http.StatusOK),
+		attribute.String("http.request.method", "GET"),
+		attribute.String("url.path", "/book/foo"),
+		attribute.String("http.route", "/book/{title}"),
 	)
 }
 
+func TestBodySizesAndStatus(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider()
+	provider.RegisterSpanProcessor(sr)
+
+	router := chi.NewRouter()
+	router.Use(Middleware("foobar", WithTracerProvider(provider)))
+	router.Post("/echo", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write(append(body, body...))
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/echo", strings.NewReader("hello")))
+
+	require.Len(t, sr.Ended(), 1)
+	span := sr.Ended()[0]
+	assertSpan(t, span,
+		"/echo",
+		trace.SpanKindServer,
+		attribute.Int("http.response.status_code", http.StatusInternalServerError),
+		attribute.Int("http.request.body.size", 5),
+		attribute.Int("http.response.body.size", 10),
+	)
+	assert.Equal(t, codes.Error, span.Status().Code)
+}
+
+func TestWithoutRoutePattern(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider()
+	provider.RegisterSpanProcessor(sr)
+
+	handler := Middleware("", WithTracerProvider(provider))(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/user/123", nil))
+
+	require.Len(t, sr.Ended(), 1)
+	span := sr.Ended()[0]
+	// the method keeps the cardinality of the span names low when the route is unknown
+	assertSpan(t, span,
+		"GET",
+		trace.SpanKindServer,
+		attribute.String("server.address", "example.com"),
+		attribute.Int("http.response.status_code", http.StatusNotFound),
+	)
+	assert.Equal(t, codes.Unset, span.Status().Code)
+	for _, attr := range span.Attributes() {
+		assert.NotEqual(t, attribute.Key("http.route"), attr.Key)
+	}
+}
+
 func assertSpan(t *testing.T, span sdktrace.ReadOnlySpan, name string, kind trace.SpanKind, attrs ...attribute.KeyValue) { // nolint
 	assert.Equal(t, name, span.Name())
 	assert.Equal(t, trace.SpanKindServer, span.SpanKind())
@@ -26,13 +26,18 @@ package trace
 
 import (
 	"context"
+	"crypto/tls"
 
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.20.0"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/encoding/gzip"
 )
 
 // Provider is a wrapper around the open telemetry tracer.Provider
@@ -40,16 +45,28 @@ import (
 type Provider struct {
 	serviceName      string
 	exporterEndpoint string
+	tlsConfig        *tls.Config
+	headers          map[string]string
+	sampler          sdktrace.Sampler
+	batchOptions     []sdktrace.BatchSpanProcessorOption
+	compression      bool
+	httpExporter     bool
+	attributes       []attribute.KeyValue
 
 	tracerProvider *sdktrace.TracerProvider
 }
 
 // NewProvider creates a new instance of TraceProvider
-func NewProvider(exporterEndPoint, serviceName string) *Provider {
-	return &Provider{
+func NewProvider(exporterEndPoint, serviceName string, opts ...ProviderOption) *Provider {
+	provider := &Provider{
 		serviceName:      serviceName,
 		exporterEndpoint: exporterEndPoint,
+		sampler:          sdktrace.AlwaysSample(),
 	}
+	for _, opt := range opts {
+		opt.Apply(provider)
+	}
+	return provider
 }
 
 // Start initializes an OTLP exporter, and configures the corresponding trace provider
@@ -62,26 +79,23 @@ func (p *Provider) Start(ctx context.Context) error {
 			// the service name used to display traces in backends
 			semconv.ServiceNameKey.String(p.serviceName),
 		),
+		resource.WithAttributes(p.attributes...),
 	)
 	if err != nil {
 		return err
 	}
 
 	// Set up a trace exporter
-	traceExporter, err := otlptracegrpc.New(ctx,
-		otlptracegrpc.WithInsecure(),
-		otlptracegrpc.WithEndpoint(p.exporterEndpoint),
-	)
-
+	traceExporter, err := p.newExporter(ctx)
 	if err != nil {
 		return err
 	}
 
 	// Register the trace exporter with a Provider, using a batch
 	// span processor to aggregate spans before export.
-	bsp := sdktrace.NewBatchSpanProcessor(traceExporter)
+	bsp := sdktrace.NewBatchSpanProcessor(traceExporter, p.batchOptions...)
 	p.tracerProvider = sdktrace.NewTracerProvider(
-		sdktrace.WithSampler(sdktrace.AlwaysSample()),
+		sdktrace.WithSampler(p.sampler),
 		sdktrace.WithResource(res),
 		sdktrace.WithSpanProcessor(bsp),
 	)
@@ -93,6 +107,52 @@ func (p *Provider) Start(ctx context.Context) error {
 	return nil
 }
 
+// newExporter creates the OTLP exporter over gRPC or HTTP
+func (p *Provider) newExporter(ctx context.Context) (sdktrace.SpanExporter, error) {
+	if p.httpExporter {
+		return otlptracehttp.New(ctx, p.httpExporterOptions()...)
+	}
+	return otlptracegrpc.New(ctx, p.grpcExporterOptions()...)
+}
+
+// grpcExporterOptions returns the options of the OTLP gRPC exporter
+func (p *Provider) grpcExporterOptions() []otlptracegrpc.Option {
+	opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(p.exporterEndpoint)}
+	if p.tlsConfig != nil {
+		opts = append(opts, otlptracegrpc.WithTLSCredentials(credentials.NewTLS(p.tlsConfig)))
+	} else {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	}
+
+	if len(p.headers) > 0 {
+		opts = append(opts, otlptracegrpc.WithHeaders(p.headers))
+	}
+
+	if p.compression {
+		opts = append(opts, otlptracegrpc.WithCompressor(gzip.Name))
+	}
+	return opts
+}
+
+// httpExporterOptions returns the options of the OTLP HTTP exporter
+func (p *Provider) httpExporterOptions() []otlptracehttp.Option {
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(p.exporterEndpoint)}
+	if p.tlsConfig != nil {
+		opts = append(opts, otlptracehttp.WithTLSClientConfig(p.tlsConfig))
+	} else {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+
+	if len(p.headers) > 0 {
+		opts = append(opts, otlptracehttp.WithHeaders(p.headers))
+	}
+
+	if p.compression {
+		opts = append(opts, otlptracehttp.WithCompression(otlptracehttp.GzipCompression))
+	}
+	return opts
+}
+
 // Stop will flush any remaining spans and shut down the exporter.
 func (p *Provider) Stop(ctx context.Context) error {
 	return p.tracerProvider.Shutdown(ctx)
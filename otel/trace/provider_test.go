@@ -26,11 +26,16 @@ package trace
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 	"github.com/travisjeffery/go-dynaport"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 
 	"github.com/tochemey/gopack/otel/testkit"
 )
@@ -82,3 +87,52 @@ func (s *ProviderTestSuite) TestStartAndStop() {
 	err = p.Stop(ctx)
 	s.Assert().NoError(err)
 }
+
+func (s *ProviderTestSuite) TestNewTraceProviderWithOptions() {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	headers := map[string]string{"authorization": "Bearer token"}
+	p := NewProvider(s.collectorEndPoint, s.serviceName,
+		WithTLS(tlsConfig),
+		WithHeaders(headers),
+		WithSampleRatio(0.5),
+		WithBatchOptions(sdktrace.WithBatchTimeout(time.Second), sdktrace.WithMaxExportBatchSize(128)),
+		WithCompression(),
+	)
+	s.Assert().Same(tlsConfig, p.tlsConfig)
+	s.Assert().Equal(headers, p.headers)
+	s.Assert().Equal(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(0.5)).Description(), p.sampler.Description())
+	s.Assert().Len(p.batchOptions, 2)
+	s.Assert().True(p.compression)
+	// the endpoint, the TLS credentials, the headers and the compressor
+	s.Assert().Len(p.grpcExporterOptions(), 4)
+	s.Assert().Len(p.httpExporterOptions(), 4)
+}
+
+func (s *ProviderTestSuite) TestStartAndStopWithOptions() {
+	ctx := context.TODO()
+	p := NewProvider(s.collectorEndPoint, s.serviceName,
+		WithHeaders(map[string]string{"x-api-key": "secret"}),
+		WithSampleRatio(0.1),
+		WithBatchOptions(sdktrace.WithBatchTimeout(100*time.Millisecond)),
+		WithCompression(),
+	)
+
+	s.Assert().NoError(p.Start(ctx))
+	s.Assert().NoError(p.Stop(ctx))
+}
+
+func (s *ProviderTestSuite) TestStartAndStopWithHTTPExporter() {
+	ctx := context.TODO()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	p := NewProvider(server.Listener.Addr().String(), s.serviceName, WithHTTPExporter(), WithCompression())
+	s.Assert().True(p.httpExporter)
+
+	s.Assert().NoError(p.Start(ctx))
+	_, span := p.tracerProvider.Tracer("test").Start(ctx, "span")
+	span.End()
+	s.Assert().NoError(p.Stop(ctx))
+}
@@ -0,0 +1,102 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+// Package pipeline provides generic, channel-based stream processing stages
+// (Map, Filter, Batch, FanOut/FanIn, Buffer) that can be composed to build
+// ETL-like consumers. Every stage honors the pipeline context and the first
+// error raised by any stage cancels the whole pipeline.
+package pipeline
+
+import (
+	"context"
+	"sync"
+)
+
+// Pipeline holds the shared state of a set of connected stages.
+// It carries the cancellation context and records the first error
+// raised by any of the stages.
+type Pipeline struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	wg      sync.WaitGroup
+	errOnce sync.Once
+	err     error
+}
+
+// New creates an instance of Pipeline bound to the given context.
+// Cancelling the context stops all the stages of the pipeline.
+func New(ctx context.Context) *Pipeline {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Pipeline{
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Context returns the pipeline context
+func (p *Pipeline) Context() context.Context {
+	return p.ctx
+}
+
+// Wait blocks until all the stages of the pipeline have completed
+// and returns the first error raised by any of the stages, if any.
+func (p *Pipeline) Wait() error {
+	p.wg.Wait()
+	p.cancel()
+	return p.err
+}
+
+// Stop cancels the pipeline. Running stages stop as soon as possible.
+func (p *Pipeline) Stop() {
+	p.cancel()
+}
+
+// fail records the first error and cancels the pipeline
+func (p *Pipeline) fail(err error) {
+	p.errOnce.Do(func() {
+		p.err = err
+		p.cancel()
+	})
+}
+
+// spawn runs the given function in a tracked go-routine
+func (p *Pipeline) spawn(fn func()) {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		fn()
+	}()
+}
+
+// send pushes the value into the given channel unless the pipeline is done.
+// It returns false when the pipeline has been cancelled
+func send[T any](ctx context.Context, out chan<- T, value T) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case out <- value:
+		return true
+	}
+}
@@ -0,0 +1,185 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// collect gathers the items of the given channel
+func collect[T any](p *Pipeline, in <-chan T) *[]T {
+	var (
+		mu    sync.Mutex
+		items []T
+	)
+	ForEach(p, in, func(_ context.Context, item T) error {
+		mu.Lock()
+		items = append(items, item)
+		mu.Unlock()
+		return nil
+	})
+	return &items
+}
+
+func TestMap(t *testing.T) {
+	t.Run("with single worker", func(t *testing.T) {
+		p := New(context.Background())
+		doubled := Map(p, From(p, 1, 2, 3), func(_ context.Context, v int) (int, error) {
+			return v * 2, nil
+		})
+		items := collect(p, doubled)
+		require.NoError(t, p.Wait())
+		assert.Equal(t, []int{2, 4, 6}, *items)
+	})
+	t.Run("with multiple workers", func(t *testing.T) {
+		p := New(context.Background())
+		squared := Map(p, From(p, 1, 2, 3, 4, 5), func(_ context.Context, v int) (int, error) {
+			return v * v, nil
+		}, WithConcurrency(3), WithBufferSize(2))
+		items := collect(p, squared)
+		require.NoError(t, p.Wait())
+		sort.Ints(*items)
+		assert.Equal(t, []int{1, 4, 9, 16, 25}, *items)
+	})
+	t.Run("with error", func(t *testing.T) {
+		p := New(context.Background())
+		failure := errors.New("boom")
+		mapped := Map(p, From(p, 1, 2, 3), func(_ context.Context, v int) (int, error) {
+			if v == 2 {
+				return 0, failure
+			}
+			return v, nil
+		})
+		collect(p, mapped)
+		err := p.Wait()
+		require.Error(t, err)
+		assert.ErrorIs(t, err, failure)
+	})
+}
+
+func TestFromSeq(t *testing.T) {
+	p := New(context.Background())
+	seq := func(yield func(int) bool) {
+		for i := 1; i <= 3; i++ {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+	items := collect(p, FromSeq(p, seq))
+	require.NoError(t, p.Wait())
+	assert.Equal(t, []int{1, 2, 3}, *items)
+}
+
+func TestFilter(t *testing.T) {
+	p := New(context.Background())
+	even := Filter(p, From(p, 1, 2, 3, 4, 5, 6), func(_ context.Context, v int) (bool, error) {
+		return v%2 == 0, nil
+	})
+	items := collect(p, even)
+	require.NoError(t, p.Wait())
+	assert.Equal(t, []int{2, 4, 6}, *items)
+}
+
+func TestBatch(t *testing.T) {
+	t.Run("with full and partial batches", func(t *testing.T) {
+		p := New(context.Background())
+		batches := Batch(p, From(p, 1, 2, 3, 4, 5), 2, 0)
+		items := collect(p, batches)
+		require.NoError(t, p.Wait())
+		assert.Equal(t, [][]int{{1, 2}, {3, 4}, {5}}, *items)
+	})
+	t.Run("with max wait elapsed", func(t *testing.T) {
+		p := New(context.Background())
+		in := make(chan int)
+		batches := Batch(p, in, 10, 50*time.Millisecond)
+		go func() {
+			in <- 1
+			in <- 2
+			time.Sleep(200 * time.Millisecond)
+			in <- 3
+			close(in)
+		}()
+		items := collect(p, batches)
+		require.NoError(t, p.Wait())
+		assert.Equal(t, [][]int{{1, 2}, {3}}, *items)
+	})
+}
+
+func TestFanOutFanIn(t *testing.T) {
+	p := New(context.Background())
+	outs := FanOut(p, From(p, 1, 2, 3, 4, 5, 6, 7, 8), 3)
+	require.Len(t, outs, 3)
+	items := collect(p, FanIn(p, outs...))
+	require.NoError(t, p.Wait())
+	sort.Ints(*items)
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8}, *items)
+}
+
+func TestBuffer(t *testing.T) {
+	p := New(context.Background())
+	buffered := Buffer(p, From(p, "a", "b", "c"), 3)
+	items := collect(p, buffered)
+	require.NoError(t, p.Wait())
+	assert.Equal(t, []string{"a", "b", "c"}, *items)
+}
+
+func TestForEach(t *testing.T) {
+	t.Run("with error", func(t *testing.T) {
+		p := New(context.Background())
+		failure := errors.New("sink failure")
+		ForEach(p, From(p, 1, 2, 3), func(context.Context, int) error {
+			return failure
+		})
+		assert.ErrorIs(t, p.Wait(), failure)
+	})
+}
+
+func TestStop(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := New(ctx)
+	// an endless source
+	in := make(chan int)
+	go func() {
+		for i := 0; ; i++ {
+			select {
+			case <-p.Context().Done():
+				return
+			case in <- i:
+			}
+		}
+	}()
+	collect(p, Map(p, in, func(_ context.Context, v int) (int, error) { return v, nil }))
+	cancel()
+	assert.NoError(t, p.Wait())
+}
@@ -0,0 +1,319 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pipeline
+
+import (
+	"context"
+	"iter"
+	"sync"
+	"time"
+)
+
+// Option configures a pipeline stage
+type Option func(*stageConfig)
+
+// stageConfig holds a stage settings
+type stageConfig struct {
+	concurrency int
+	bufferSize  int
+}
+
+// newStageConfig creates a stage config and applies the given options
+func newStageConfig(opts ...Option) *stageConfig {
+	cfg := &stageConfig{
+		concurrency: 1,
+		bufferSize:  0,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// WithConcurrency sets the number of go-routines used to process the stage items.
+// When the concurrency is greater than one the order of the items is not preserved.
+func WithConcurrency(concurrency int) Option {
+	return func(cfg *stageConfig) {
+		if concurrency > 0 {
+			cfg.concurrency = concurrency
+		}
+	}
+}
+
+// WithBufferSize sets the capacity of the stage output channel
+func WithBufferSize(size int) Option {
+	return func(cfg *stageConfig) {
+		if size >= 0 {
+			cfg.bufferSize = size
+		}
+	}
+}
+
+// From creates a source stage emitting the given items
+func From[T any](p *Pipeline, items ...T) <-chan T {
+	out := make(chan T)
+	p.spawn(func() {
+		defer close(out)
+		for _, item := range items {
+			if !send(p.ctx, out, item) {
+				return
+			}
+		}
+	})
+	return out
+}
+
+// FromSeq creates a source stage emitting the items of the given iterator.
+// The iteration stops when the pipeline is cancelled.
+func FromSeq[T any](p *Pipeline, seq iter.Seq[T]) <-chan T {
+	out := make(chan T)
+	p.spawn(func() {
+		defer close(out)
+		for item := range seq {
+			if !send(p.ctx, out, item) {
+				return
+			}
+		}
+	})
+	return out
+}
+
+// Map creates a stage that transforms every item of the input channel using fn.
+// Any error returned by fn cancels the pipeline.
+func Map[In, Out any](p *Pipeline, in <-chan In, fn func(ctx context.Context, item In) (Out, error), opts ...Option) <-chan Out {
+	cfg := newStageConfig(opts...)
+	out := make(chan Out, cfg.bufferSize)
+	run(p, cfg.concurrency, func() {
+		for item := range receive(p.ctx, in) {
+			result, err := fn(p.ctx, item)
+			if err != nil {
+				p.fail(err)
+				return
+			}
+			if !send(p.ctx, out, result) {
+				return
+			}
+		}
+	}, func() { close(out) })
+	return out
+}
+
+// Filter creates a stage that only lets through the items for which fn returns true.
+// Any error returned by fn cancels the pipeline.
+func Filter[T any](p *Pipeline, in <-chan T, fn func(ctx context.Context, item T) (bool, error), opts ...Option) <-chan T {
+	cfg := newStageConfig(opts...)
+	out := make(chan T, cfg.bufferSize)
+	run(p, cfg.concurrency, func() {
+		for item := range receive(p.ctx, in) {
+			keep, err := fn(p.ctx, item)
+			if err != nil {
+				p.fail(err)
+				return
+			}
+			if keep && !send(p.ctx, out, item) {
+				return
+			}
+		}
+	}, func() { close(out) })
+	return out
+}
+
+// Batch creates a stage that groups the items of the input channel into slices of at most size items.
+// A partial batch is emitted when maxWait elapses after its first item has been received or when the
+// input channel is closed. A zero maxWait means batches are only emitted when full or at the end of the input.
+func Batch[T any](p *Pipeline, in <-chan T, size int, maxWait time.Duration) <-chan []T {
+	if size <= 0 {
+		size = 1
+	}
+
+	out := make(chan []T)
+	p.spawn(func() {
+		defer close(out)
+
+		batch := make([]T, 0, size)
+		// a nil channel blocks forever which disables the flush timer
+		var timer *time.Timer
+		var flush <-chan time.Time
+
+		emit := func() bool {
+			if timer != nil {
+				timer.Stop()
+				timer, flush = nil, nil
+			}
+			if len(batch) == 0 {
+				return true
+			}
+			ok := send(p.ctx, out, batch)
+			batch = make([]T, 0, size)
+			return ok
+		}
+
+		for {
+			select {
+			case <-p.ctx.Done():
+				return
+			case <-flush:
+				timer, flush = nil, nil
+				if !emit() {
+					return
+				}
+			case item, ok := <-in:
+				if !ok {
+					emit()
+					return
+				}
+
+				batch = append(batch, item)
+				if len(batch) == 1 && maxWait > 0 {
+					timer = time.NewTimer(maxWait)
+					flush = timer.C
+				}
+
+				if len(batch) >= size && !emit() {
+					return
+				}
+			}
+		}
+	})
+	return out
+}
+
+// FanOut distributes the items of the input channel across n output channels.
+// Each item is delivered to exactly one of the output channels.
+func FanOut[T any](p *Pipeline, in <-chan T, n int, opts ...Option) []<-chan T {
+	if n <= 0 {
+		n = 1
+	}
+
+	cfg := newStageConfig(opts...)
+	outs := make([]<-chan T, n)
+	for i := 0; i < n; i++ {
+		out := make(chan T, cfg.bufferSize)
+		outs[i] = out
+		p.spawn(func() {
+			defer close(out)
+			for item := range receive(p.ctx, in) {
+				if !send(p.ctx, out, item) {
+					return
+				}
+			}
+		})
+	}
+	return outs
+}
+
+// FanIn merges the given input channels into a single output channel.
+// The output channel is closed once all the input channels are closed.
+func FanIn[T any](p *Pipeline, ins ...<-chan T) <-chan T {
+	out := make(chan T)
+	wg := new(sync.WaitGroup)
+	wg.Add(len(ins))
+	for _, in := range ins {
+		p.spawn(func() {
+			defer wg.Done()
+			for item := range receive(p.ctx, in) {
+				if !send(p.ctx, out, item) {
+					return
+				}
+			}
+		})
+	}
+
+	p.spawn(func() {
+		wg.Wait()
+		close(out)
+	})
+	return out
+}
+
+// Buffer creates a stage backed by a channel of the given capacity.
+// It decouples a fast producer from a slower consumer.
+func Buffer[T any](p *Pipeline, in <-chan T, size int) <-chan T {
+	if size < 0 {
+		size = 0
+	}
+
+	out := make(chan T, size)
+	p.spawn(func() {
+		defer close(out)
+		for item := range receive(p.ctx, in) {
+			if !send(p.ctx, out, item) {
+				return
+			}
+		}
+	})
+	return out
+}
+
+// ForEach is a terminal stage that calls fn for every item of the input channel.
+// Any error returned by fn cancels the pipeline. Call Wait on the pipeline
+// to block until all items have been consumed.
+func ForEach[T any](p *Pipeline, in <-chan T, fn func(ctx context.Context, item T) error, opts ...Option) {
+	cfg := newStageConfig(opts...)
+	run(p, cfg.concurrency, func() {
+		for item := range receive(p.ctx, in) {
+			if err := fn(p.ctx, item); err != nil {
+				p.fail(err)
+				return
+			}
+		}
+	}, nil)
+}
+
+// run starts the given number of workers and calls done once all of them have returned
+func run(p *Pipeline, workers int, worker func(), done func()) {
+	wg := new(sync.WaitGroup)
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		p.spawn(func() {
+			defer wg.Done()
+			worker()
+		})
+	}
+
+	if done != nil {
+		p.spawn(func() {
+			wg.Wait()
+			done()
+		})
+	}
+}
+
+// receive returns an iterator over the input channel items.
+// The iteration stops when the channel is closed or the context is done
+func receive[T any](ctx context.Context, in <-chan T) func(yield func(T) bool) {
+	return func(yield func(T) bool) {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case item, ok := <-in:
+				if !ok || !yield(item) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,182 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+)
+
+const (
+	// DefaultBatchSize is the default number of rows inserted per statement by the BulkInserter
+	DefaultBatchSize = 500
+	// maxParameters is the maximum number of parameters of a Postgres statement
+	maxParameters = 65535
+)
+
+// BulkOption is the interface that applies a BulkInserter configuration option.
+type BulkOption interface {
+	// Apply sets the BulkOption value of a BulkInserter.
+	Apply(*BulkInserter)
+}
+
+var _ BulkOption = BulkOptionFunc(nil)
+
+// BulkOptionFunc implements the BulkOption interface.
+type BulkOptionFunc func(*BulkInserter)
+
+// Apply applies the option
+func (f BulkOptionFunc) Apply(b *BulkInserter) {
+	f(b)
+}
+
+// WithBatchSize sets the number of rows inserted per statement. It defaults to DefaultBatchSize.
+// The batch size is capped so that a statement does not exceed the Postgres parameters limit.
+func WithBatchSize(batchSize int) BulkOption {
+	return BulkOptionFunc(func(b *BulkInserter) {
+		b.batchSize = batchSize
+	})
+}
+
+// WithOnConflict sets the ON CONFLICT clause of the insert statements, e.g. "DO NOTHING"
+func WithOnConflict(clause string) BulkOption {
+	return BulkOptionFunc(func(b *BulkInserter) {
+		b.onConflict = clause
+	})
+}
+
+// BulkInserter buffers rows and inserts them in batches using multi-rows INSERT statements.
+// Call Flush once all the rows are added to insert the remaining ones.
+// Use CopyFrom instead when the rows do not need an ON CONFLICT clause since COPY is faster.
+// A BulkInserter is not safe for concurrent use.
+type BulkInserter struct {
+	db         Postgres
+	table      string
+	columns    []string
+	batchSize  int
+	onConflict string
+	rows       [][]any
+	inserted   int64
+}
+
+// NewBulkInserter creates a BulkInserter inserting rows of the given columns into the table
+func NewBulkInserter(db Postgres, table string, columns []string, opts ...BulkOption) *BulkInserter {
+	b := &BulkInserter{
+		db:        db,
+		table:     table,
+		columns:   columns,
+		batchSize: DefaultBatchSize,
+	}
+
+	for _, opt := range opts {
+		opt.Apply(b)
+	}
+
+	if len(columns) > 0 {
+		b.batchSize = min(b.batchSize, maxParameters/len(columns))
+	}
+	b.batchSize = max(b.batchSize, 1)
+	b.rows = make([][]any, 0, b.batchSize)
+	return b
+}
+
+// Add buffers a row with the values of the columns in the same order.
+// The buffered rows are inserted once the batch is full.
+func (b *BulkInserter) Add(ctx context.Context, values ...any) error {
+	if len(values) != len(b.columns) {
+		return errors.Errorf("expected %d values, got %d", len(b.columns), len(values))
+	}
+
+	b.rows = append(b.rows, values)
+	if len(b.rows) < b.batchSize {
+		return nil
+	}
+	return b.Flush(ctx)
+}
+
+// Flush inserts the buffered rows
+func (b *BulkInserter) Flush(ctx context.Context) error {
+	if len(b.rows) == 0 {
+		return nil
+	}
+
+	// Create a span
+	tracer := otel.GetTracerProvider()
+	spanCtx, span := tracer.Tracer(instrumentationName).Start(ctx, "BulkInsert")
+	defer span.End()
+
+	query, args := b.buildInsert()
+	result, err := b.db.Exec(spanCtx, query, args...)
+	if err != nil {
+		return errors.Wrap(err, "failed to insert the rows")
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to count the inserted rows")
+	}
+
+	b.inserted += affected
+	b.rows = b.rows[:0]
+	return nil
+}
+
+// Inserted returns the number of rows inserted so far.
+// Rows skipped by the ON CONFLICT clause are not counted.
+func (b *BulkInserter) Inserted() int64 {
+	return b.inserted
+}
+
+// buildInsert creates the insert statement of the buffered rows
+func (b *BulkInserter) buildInsert() (string, []any) {
+	var builder strings.Builder
+	args := make([]any, 0, len(b.rows)*len(b.columns))
+
+	fmt.Fprintf(&builder, "INSERT INTO %s (%s) VALUES ", b.table, strings.Join(b.columns, ", "))
+	for i, row := range b.rows {
+		if i > 0 {
+			builder.WriteString(", ")
+		}
+
+		builder.WriteString("(")
+		for j, value := range row {
+			if j > 0 {
+				builder.WriteString(", ")
+			}
+			args = append(args, value)
+			fmt.Fprintf(&builder, "$%d", len(args))
+		}
+		builder.WriteString(")")
+	}
+
+	if b.onConflict != "" {
+		fmt.Fprintf(&builder, " ON CONFLICT %s", b.onConflict)
+	}
+	return builder.String(), args
+}
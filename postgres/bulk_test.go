@@ -0,0 +1,86 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingDB records the executed statements
+type recordingDB struct {
+	Postgres
+	queries []string
+	args    [][]any
+}
+
+func (r *recordingDB) Exec(_ context.Context, query string, args ...any) (sql.Result, error) {
+	r.queries = append(r.queries, query)
+	r.args = append(r.args, args)
+	return driver.RowsAffected(len(args) / 2), nil
+}
+
+func TestBulkInserter(t *testing.T) {
+	ctx := context.TODO()
+	columns := []string{"account_id", "account_name"}
+
+	t.Run("with batches", func(t *testing.T) {
+		db := new(recordingDB)
+		inserter := NewBulkInserter(db, "accounts", columns, WithBatchSize(2), WithOnConflict("DO NOTHING"))
+
+		require.NoError(t, inserter.Add(ctx, "id-1", "first"))
+		assert.Empty(t, db.queries)
+		require.NoError(t, inserter.Add(ctx, "id-2", "second"))
+		require.NoError(t, inserter.Add(ctx, "id-3", "third"))
+		require.NoError(t, inserter.Flush(ctx))
+		// nothing left to flush
+		require.NoError(t, inserter.Flush(ctx))
+
+		assert.Equal(t, []string{
+			"INSERT INTO accounts (account_id, account_name) VALUES ($1, $2), ($3, $4) ON CONFLICT DO NOTHING",
+			"INSERT INTO accounts (account_id, account_name) VALUES ($1, $2) ON CONFLICT DO NOTHING",
+		}, db.queries)
+		assert.Equal(t, [][]any{{"id-1", "first", "id-2", "second"}, {"id-3", "third"}}, db.args)
+		assert.EqualValues(t, 3, inserter.Inserted())
+	})
+
+	t.Run("with invalid number of values", func(t *testing.T) {
+		inserter := NewBulkInserter(new(recordingDB), "accounts", columns)
+		assert.EqualError(t, inserter.Add(ctx, "id-1"), "expected 2 values, got 1")
+	})
+
+	t.Run("with batch size exceeding the parameters limit", func(t *testing.T) {
+		inserter := NewBulkInserter(new(recordingDB), "accounts", columns, WithBatchSize(100_000))
+		assert.Equal(t, 32767, inserter.batchSize)
+
+		inserter = NewBulkInserter(new(recordingDB), "accounts", columns, WithBatchSize(0))
+		assert.Equal(t, 1, inserter.batchSize)
+	})
+}
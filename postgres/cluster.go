@@ -0,0 +1,249 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+)
+
+// defaultLagCheckPeriod is the default duration between the replication lag checks
+const defaultLagCheckPeriod = 5 * time.Second
+
+// replicationLagSQL returns the replication lag of a replica in seconds.
+// The lag is zero when the replica has replayed all the received changes.
+const replicationLagSQL = `SELECT COALESCE(
+	CASE WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+	ELSE EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()) END, 0);`
+
+// replica is a read replica of the cluster
+type replica struct {
+	db      Postgres
+	lagging atomic.Bool
+}
+
+// cluster routes the queries fetching rows to the read replicas and
+// the statements and transactions to the primary database
+type cluster struct {
+	primary  Postgres
+	replicas []*replica
+	config   *Cluster
+	next     atomic.Uint64
+
+	stopLagCheck chan struct{}
+	lagCheck     sync.WaitGroup
+}
+
+var _ Postgres = (*cluster)(nil)
+
+// newCluster creates a cluster from the primary config and its replicas
+func newCluster(config *Config) *cluster {
+	replicas := make([]Postgres, 0, len(config.Cluster.Replicas))
+	for _, replicaConfig := range config.Cluster.Replicas {
+		replicas = append(replicas, newPostgres(replicaConfig))
+	}
+	return newClusterOf(newPostgres(config), replicas, config.Cluster)
+}
+
+// newClusterOf creates a cluster from the given databases
+func newClusterOf(primary Postgres, replicas []Postgres, config *Cluster) *cluster {
+	c := &cluster{
+		primary:  primary,
+		replicas: make([]*replica, 0, len(replicas)),
+		config:   config,
+	}
+	for _, db := range replicas {
+		c.replicas = append(c.replicas, &replica{db: db})
+	}
+	return c
+}
+
+// Connect connects to the primary database and the replicas
+func (c *cluster) Connect(ctx context.Context) error {
+	if err := c.primary.Connect(ctx); err != nil {
+		return err
+	}
+
+	for i, replica := range c.replicas {
+		if err := replica.db.Connect(ctx); err != nil {
+			// close the opened connections
+			for _, connected := range c.replicas[:i] {
+				_ = connected.db.Disconnect(ctx)
+			}
+			_ = c.primary.Disconnect(ctx)
+			return errors.Wrapf(err, "failed to connect to the replica (%d)", i)
+		}
+	}
+
+	c.startLagCheck()
+	return nil
+}
+
+// Disconnect closes the connections to the primary database and the replicas
+func (c *cluster) Disconnect(ctx context.Context) error {
+	c.stopLagChecks()
+	err := c.primary.Disconnect(ctx)
+	for _, replica := range c.replicas {
+		err = multierr.Append(err, replica.db.Disconnect(ctx))
+	}
+	return err
+}
+
+// Select fetches only one row from a replica
+func (c *cluster) Select(ctx context.Context, dst any, query string, args ...any) error {
+	return c.reader().Select(ctx, dst, query, args...)
+}
+
+// SelectAll fetches rows from a replica
+func (c *cluster) SelectAll(ctx context.Context, dst any, query string, args ...any) error {
+	return c.reader().SelectAll(ctx, dst, query, args...)
+}
+
+// SelectNamed fetches only one row from a replica using a named query
+func (c *cluster) SelectNamed(ctx context.Context, dst any, query string, arg any) error {
+	return c.reader().SelectNamed(ctx, dst, query, arg)
+}
+
+// SelectAllNamed fetches rows from a replica using a named query
+func (c *cluster) SelectAllNamed(ctx context.Context, dst any, query string, arg any) error {
+	return c.reader().SelectAllNamed(ctx, dst, query, arg)
+}
+
+// SelectStream streams rows from a replica
+func (c *cluster) SelectStream(ctx context.Context, query string, args ...any) (*RowStream, error) {
+	return c.reader().SelectStream(ctx, query, args...)
+}
+
+// Exec executes a sql query against the primary database
+func (c *cluster) Exec(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return c.primary.Exec(ctx, query, args...)
+}
+
+// ExecNamed executes a named sql query against the primary database
+func (c *cluster) ExecNamed(ctx context.Context, query string, arg any) (sql.Result, error) {
+	return c.primary.ExecNamed(ctx, query, arg)
+}
+
+// BeginTx starts a new transaction on the primary database
+func (c *cluster) BeginTx(ctx context.Context, txOptions *sql.TxOptions) (*sql.Tx, error) {
+	return c.primary.BeginTx(ctx, txOptions)
+}
+
+// WithinTx runs fn in a transaction on the primary database
+func (c *cluster) WithinTx(ctx context.Context, txOptions *sql.TxOptions, fn func(tx Tx) error, opts ...TxOption) error {
+	return c.primary.WithinTx(ctx, txOptions, fn, opts...)
+}
+
+// CopyFrom bulk loads the given rows into the primary database
+func (c *cluster) CopyFrom(ctx context.Context, table string, columns []string, rows [][]any) (int64, error) {
+	return c.primary.CopyFrom(ctx, table, columns, rows)
+}
+
+// Status returns the status of the primary database
+func (c *cluster) Status() Status {
+	return c.primary.Status()
+}
+
+// HealthEvents returns the health events of the primary database
+func (c *cluster) HealthEvents() <-chan Status {
+	return c.primary.HealthEvents()
+}
+
+// reader returns the next available replica in a round-robin fashion.
+// A replica is unavailable when it is lagging or its last health check failed.
+// It returns the primary database when no replica is available.
+func (c *cluster) reader() Postgres {
+	count := uint64(len(c.replicas))
+	start := c.next.Add(1) - 1
+	for i := range count {
+		replica := c.replicas[(start+i)%count]
+		if replica.lagging.Load() || !replica.db.Status().Healthy {
+			continue
+		}
+		return replica.db
+	}
+	return c.primary
+}
+
+// startLagCheck checks the replication lag of the replicas every LagCheckPeriod
+func (c *cluster) startLagCheck() {
+	if c.config.MaxReplicationLag <= 0 {
+		return
+	}
+
+	period := c.config.LagCheckPeriod
+	if period <= 0 {
+		period = defaultLagCheckPeriod
+	}
+
+	c.checkLag(period)
+	c.stopLagCheck = make(chan struct{})
+	c.lagCheck.Add(1)
+	go func(stop <-chan struct{}) {
+		defer c.lagCheck.Done()
+		ticker := time.NewTicker(period)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				c.checkLag(period)
+			}
+		}
+	}(c.stopLagCheck)
+}
+
+// stopLagChecks stops the replication lag checks and waits for the running one to complete
+func (c *cluster) stopLagChecks() {
+	if c.stopLagCheck == nil {
+		return
+	}
+	close(c.stopLagCheck)
+	c.stopLagCheck = nil
+	c.lagCheck.Wait()
+}
+
+// checkLag flags the replicas whose replication lag exceeds MaxReplicationLag.
+// A replica whose lag cannot be fetched is flagged as well.
+func (c *cluster) checkLag(timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	for _, replica := range c.replicas {
+		var seconds float64
+		if err := replica.db.Select(ctx, &seconds, replicationLagSQL); err != nil {
+			replica.lagging.Store(true)
+			continue
+		}
+		lag := time.Duration(seconds * float64(time.Second))
+		replica.lagging.Store(lag > c.config.MaxReplicationLag)
+	}
+}
@@ -0,0 +1,149 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// routedDB records the queries routed to it
+type routedDB struct {
+	Postgres
+	name      string
+	lag       time.Duration
+	unhealthy atomic.Bool
+	selects   atomic.Int64
+	execs     atomic.Int64
+}
+
+func (r *routedDB) Connect(context.Context) error {
+	return nil
+}
+
+func (r *routedDB) Disconnect(context.Context) error {
+	return nil
+}
+
+func (r *routedDB) Select(_ context.Context, dst any, query string, _ ...any) error {
+	if query == replicationLagSQL {
+		if r.lag < 0 {
+			return errors.New("connection refused")
+		}
+		*(dst.(*float64)) = r.lag.Seconds()
+		return nil
+	}
+	r.selects.Add(1)
+	return nil
+}
+
+func (r *routedDB) Exec(context.Context, string, ...any) (sql.Result, error) {
+	r.execs.Add(1)
+	return driver.RowsAffected(1), nil
+}
+
+func (r *routedDB) Status() Status {
+	return Status{Healthy: !r.unhealthy.Load()}
+}
+
+func TestCluster(t *testing.T) {
+	ctx := context.TODO()
+
+	t.Run("with round robin reads", func(t *testing.T) {
+		primary := &routedDB{name: "primary"}
+		first := &routedDB{name: "first"}
+		second := &routedDB{name: "second"}
+		db := newClusterOf(primary, []Postgres{first, second}, &Cluster{})
+		require.NoError(t, db.Connect(ctx))
+
+		for range 4 {
+			require.NoError(t, db.Select(ctx, new(account), "SELECT 1"))
+		}
+		_, err := db.Exec(ctx, "DELETE FROM accounts")
+		require.NoError(t, err)
+
+		assert.EqualValues(t, 2, first.selects.Load())
+		assert.EqualValues(t, 2, second.selects.Load())
+		assert.Zero(t, primary.selects.Load())
+		assert.EqualValues(t, 1, primary.execs.Load())
+		assert.Zero(t, first.execs.Load()+second.execs.Load())
+		require.NoError(t, db.Disconnect(ctx))
+	})
+
+	t.Run("with unhealthy replica", func(t *testing.T) {
+		primary := &routedDB{name: "primary"}
+		first := &routedDB{name: "first"}
+		second := &routedDB{name: "second"}
+		second.unhealthy.Store(true)
+		db := newClusterOf(primary, []Postgres{first, second}, &Cluster{})
+
+		for range 4 {
+			require.NoError(t, db.Select(ctx, new(account), "SELECT 1"))
+		}
+		assert.EqualValues(t, 4, first.selects.Load())
+
+		// the primary serves the reads when no replica is available
+		first.unhealthy.Store(true)
+		require.NoError(t, db.Select(ctx, new(account), "SELECT 1"))
+		assert.EqualValues(t, 1, primary.selects.Load())
+	})
+
+	t.Run("with lagging replica", func(t *testing.T) {
+		primary := &routedDB{name: "primary"}
+		first := &routedDB{name: "first", lag: time.Minute}
+		second := &routedDB{name: "second", lag: time.Second}
+		third := &routedDB{name: "third", lag: -1}
+		db := newClusterOf(primary, []Postgres{first, second, third}, &Cluster{
+			MaxReplicationLag: 10 * time.Second,
+			LagCheckPeriod:    time.Hour,
+		})
+		require.NoError(t, db.Connect(ctx))
+
+		for range 3 {
+			require.NoError(t, db.Select(ctx, new(account), "SELECT 1"))
+		}
+		assert.Zero(t, first.selects.Load())
+		assert.EqualValues(t, 3, second.selects.Load())
+		assert.Zero(t, third.selects.Load())
+		require.NoError(t, db.Disconnect(ctx))
+		assert.Nil(t, db.stopLagCheck)
+	})
+
+	t.Run("with New", func(t *testing.T) {
+		db := New(&Config{Cluster: &Cluster{Replicas: []*Config{{DBHost: "replica"}}}})
+		require.IsType(t, new(cluster), db)
+		assert.Len(t, db.(*cluster).replicas, 1)
+
+		assert.IsType(t, new(postgres), New(&Config{Cluster: &Cluster{}}))
+	})
+}
@@ -24,7 +24,22 @@
 
 package postgres
 
-import "time"
+import (
+	"time"
+
+	"github.com/tochemey/gopack/log"
+)
+
+// Driver defines the driver used to connect to the Postgres database
+type Driver string
+
+const (
+	// PQDriver connects using database/sql and lib/pq. This is the default driver.
+	PQDriver Driver = "pq"
+	// PgxPoolDriver connects using a pgxpool connection pool with prepared statements caching.
+	// The pool is sized using MaxConnections and MinConnections.
+	PgxPoolDriver Driver = "pgxpool"
+)
 
 type Config struct {
 	DBHost                string        // DBHost represents the database host
@@ -36,4 +51,45 @@ type Config struct {
 	MaxOpenConnections    int           // MaxOpenConnections represents the number of open connections in the pool
 	MaxIdleConnections    int           // MaxIdleConnections represents the number of idle connections in the pool
 	ConnectionMaxLifetime time.Duration // ConnectionMaxLifetime represents the connection max life time
+	Driver                Driver        // Driver represents the database driver. It defaults to PQDriver
+	MaxConnections        int32         // MaxConnections represents the maximum size of the pgxpool connection pool
+	MinConnections        int32         // MinConnections represents the minimum size of the pgxpool connection pool
+	HealthCheckPeriod     time.Duration // HealthCheckPeriod represents the duration between the database health checks. Zero disables them
+	Cluster               *Cluster      // Cluster represents the read replicas of the database. Nil when there is none
+	QueryTimeout          time.Duration // QueryTimeout represents the default timeout of the queries. Zero disables it
+	SlowQueryThreshold    time.Duration // SlowQueryThreshold represents the duration above which a query is logged. Zero disables it
+	Logger                log.Logger    // Logger logs the slow queries. It defaults to zapl.DefaultLogger
+	RedactQueryArgs       bool          // RedactQueryArgs hides the queries arguments in the slow queries logs
+	SSLMode               SSLMode       // SSLMode represents the TLS mode of the connections. It defaults to SSLModeDisable
+	SSLRootCert           string        // SSLRootCert is the path of the certificate authorities file used to verify the server certificate
+	SSLCert               string        // SSLCert is the path of the client certificate file
+	SSLKey                string        // SSLKey is the path of the client private key file
+	ChannelBinding        string        // ChannelBinding represents the SCRAM channel binding mode: "disable" or "prefer"
+}
+
+// SSLMode defines the TLS mode of the connections to the database
+type SSLMode string
+
+const (
+	// SSLModeDisable connects without TLS
+	SSLModeDisable SSLMode = "disable"
+	// SSLModeAllow connects without TLS and falls back to TLS. It is only supported by the PgxPoolDriver.
+	SSLModeAllow SSLMode = "allow"
+	// SSLModePrefer connects with TLS and falls back to no TLS. It is only supported by the PgxPoolDriver.
+	SSLModePrefer SSLMode = "prefer"
+	// SSLModeRequire connects with TLS without verifying the server certificate
+	SSLModeRequire SSLMode = "require"
+	// SSLModeVerifyCA connects with TLS and verifies that the server certificate is signed by a trusted authority
+	SSLModeVerifyCA SSLMode = "verify-ca"
+	// SSLModeVerifyFull connects with TLS and verifies the server certificate and host name
+	SSLModeVerifyFull SSLMode = "verify-full"
+)
+
+// Cluster defines the read replicas of a primary database.
+// The queries fetching rows are routed to the replicas in a round-robin fashion while the
+// statements and the transactions are routed to the primary database.
+type Cluster struct {
+	Replicas          []*Config     // Replicas represents the read replicas connection settings
+	MaxReplicationLag time.Duration // MaxReplicationLag is the lag above which a replica is skipped. Zero disables the lag check
+	LagCheckPeriod    time.Duration // LagCheckPeriod is the duration between the replication lag checks. It defaults to 5s
 }
@@ -0,0 +1,98 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+)
+
+// CopyFrom bulk loads the given rows into the table using the Postgres COPY protocol.
+// Every row must have the values of the given columns in the same order.
+// The rows are loaded in a single transaction that is rolled back on failure.
+// It returns the number of rows copied.
+func CopyFrom(ctx context.Context, db Postgres, table string, columns []string, rows [][]any) (int64, error) {
+	// Create a span
+	tracer := otel.GetTracerProvider()
+	spanCtx, span := tracer.Tracer(instrumentationName).Start(ctx, "CopyFrom")
+	defer span.End()
+
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	// the pgx driver does not support COPY through database/sql
+	if pool := poolOf(db); pool != nil {
+		return copyFromPool(spanCtx, pool, table, columns, rows)
+	}
+
+	tx, err := db.BeginTx(spanCtx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to begin the copy transaction")
+	}
+
+	if err := copyRows(spanCtx, tx, table, columns, rows); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return 0, errors.Wrap(err, rollbackErr.Error())
+		}
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, errors.Wrap(err, "failed to commit the copy transaction")
+	}
+	return int64(len(rows)), nil
+}
+
+// CopyFrom bulk loads the given rows into the table using the Postgres COPY protocol
+func (p *postgres) CopyFrom(ctx context.Context, table string, columns []string, rows [][]any) (int64, error) {
+	return CopyFrom(ctx, p, table, columns, rows)
+}
+
+// copyRows streams the rows to the database using a COPY statement
+func copyRows(ctx context.Context, tx *sql.Tx, table string, columns []string, rows [][]any) error {
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn(table, columns...))
+	if err != nil {
+		return errors.Wrap(err, "failed to prepare the copy statement")
+	}
+
+	for _, row := range rows {
+		if _, err := stmt.ExecContext(ctx, row...); err != nil {
+			_ = stmt.Close()
+			return errors.Wrap(err, "failed to copy the row")
+		}
+	}
+
+	// an empty exec flushes the buffered rows
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		_ = stmt.Close()
+		return errors.Wrap(err, "failed to flush the copied rows")
+	}
+	return stmt.Close()
+}
@@ -0,0 +1,131 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// healthEventsBuffer is the capacity of the health events channel
+const healthEventsBuffer = 16
+
+// Status is the health of the database connection
+type Status struct {
+	// Healthy is true when the last health check succeeded
+	Healthy bool
+	// CheckedAt is the time of the last health check
+	CheckedAt time.Time
+	// Err is the error of the last health check when it failed
+	Err error
+	// Stats holds the connection pool statistics such as the open, idle connections and the wait count
+	Stats sql.DBStats
+}
+
+// Status returns the last health check result along with the connection pool statistics
+func (p *postgres) Status() Status {
+	p.statusLock.RLock()
+	status := p.status
+	p.statusLock.RUnlock()
+
+	if p.dbConnection != nil {
+		status.Stats = p.dbConnection.Stats()
+	}
+	return status
+}
+
+// HealthEvents returns the channel notified when the database becomes unreachable or recovers.
+// Events are dropped when the channel is full.
+func (p *postgres) HealthEvents() <-chan Status {
+	return p.events
+}
+
+// startHealthCheck pings the database every HealthCheckPeriod until the connection is closed.
+// The connection pool reconnects on the next ping once the database is reachable again.
+func (p *postgres) startHealthCheck() {
+	period := p.config.HealthCheckPeriod
+	if period <= 0 {
+		return
+	}
+
+	p.stopHealth = make(chan struct{})
+	p.healthCheck.Add(1)
+	go func(stop <-chan struct{}) {
+		defer p.healthCheck.Done()
+		ticker := time.NewTicker(period)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				p.checkHealth(period)
+			}
+		}
+	}(p.stopHealth)
+}
+
+// stopHealthCheck stops the health checks and waits for the running one to complete
+func (p *postgres) stopHealthCheck() {
+	if p.stopHealth == nil {
+		return
+	}
+	close(p.stopHealth)
+	p.stopHealth = nil
+	p.healthCheck.Wait()
+}
+
+// checkHealth pings the database and records the result
+func (p *postgres) checkHealth(timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	p.setStatus(p.dbConnection.PingContext(ctx))
+}
+
+// setStatus records the health check result and notifies the change of health
+func (p *postgres) setStatus(err error) {
+	p.statusLock.Lock()
+	first := p.status.CheckedAt.IsZero()
+	changed := p.status.Healthy != (err == nil)
+	p.status = Status{
+		Healthy:   err == nil,
+		CheckedAt: time.Now(),
+		Err:       err,
+	}
+	status := p.status
+	p.statusLock.Unlock()
+
+	// the initial connection is not a change of health
+	if first || !changed {
+		return
+	}
+
+	status.Stats = p.dbConnection.Stats()
+	select {
+	case p.events <- status:
+	default:
+	}
+}
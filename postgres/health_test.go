@@ -0,0 +1,117 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeConnector creates connections failing when the database is down
+type fakeConnector struct {
+	down *atomic.Bool
+}
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) {
+	if c.down.Load() {
+		return nil, errors.New("connection refused")
+	}
+	return &fakeConn{down: c.down}, nil
+}
+
+func (c fakeConnector) Driver() driver.Driver {
+	return nil
+}
+
+// fakeConn is a connection that can only be pinged
+type fakeConn struct {
+	down *atomic.Bool
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("not supported")
+}
+
+func (c *fakeConn) Ping(context.Context) error {
+	if c.down.Load() {
+		return driver.ErrBadConn
+	}
+	return nil
+}
+
+func TestHealthCheck(t *testing.T) {
+	down := new(atomic.Bool)
+	p := newPostgres(&Config{HealthCheckPeriod: 10 * time.Millisecond})
+	p.dbConnection = sql.OpenDB(fakeConnector{down: down})
+	p.setStatus(nil)
+	p.startHealthCheck()
+
+	status := p.Status()
+	assert.True(t, status.Healthy)
+	assert.NoError(t, status.Err)
+	assert.False(t, status.CheckedAt.IsZero())
+
+	// the database becomes unreachable
+	down.Store(true)
+	select {
+	case event := <-p.HealthEvents():
+		assert.False(t, event.Healthy)
+		assert.EqualError(t, event.Err, "connection refused")
+	case <-time.After(time.Second):
+		require.Fail(t, "no health event received")
+	}
+	assert.False(t, p.Status().Healthy)
+
+	// the database recovers
+	down.Store(false)
+	select {
+	case event := <-p.HealthEvents():
+		assert.True(t, event.Healthy)
+		assert.NoError(t, event.Err)
+		assert.Equal(t, 1, event.Stats.OpenConnections)
+	case <-time.After(time.Second):
+		require.Fail(t, "no health event received")
+	}
+
+	require.NoError(t, p.Disconnect(context.Background()))
+	assert.Nil(t, p.stopHealth)
+	assert.Zero(t, p.Status().Stats.OpenConnections)
+}
@@ -0,0 +1,309 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+// Package migrate applies versioned SQL migrations to a Postgres database.
+//
+// The migrations are read from the root directory of a fs.FS, usually an embed.FS.
+// Every migration is made of an up file and an optional down file named as follows:
+//
+//	<version>_<name>.up.sql
+//	<version>_<name>.down.sql
+//
+// The versions are positive integers applied in ascending order. The applied versions are
+// recorded in the schema_migrations table.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/tochemey/gopack/postgres"
+)
+
+// migrationFile matches the migration files names
+var migrationFile = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// Migration is a versioned database migration
+type Migration struct {
+	// Version is the migration version
+	Version uint64
+	// Name is the migration name
+	Name string
+	// Up is the SQL script applying the migration
+	Up string
+	// Down is the SQL script reverting the migration. It is empty when the migration cannot be reverted.
+	Down string
+}
+
+// Migrator applies the migrations to the database.
+// Every run happens in a single transaction holding an advisory lock so that
+// concurrent runners wait for each other and a failed run leaves the database untouched.
+// Therefore, the migrations cannot contain statements that cannot run in a transaction such as
+// CREATE INDEX CONCURRENTLY.
+type Migrator struct {
+	db         postgres.Postgres
+	migrations []*Migration
+	tableName  string
+	lockID     int64
+	customLock bool
+}
+
+// New creates a Migrator reading the migrations from the root directory of fsys.
+// Use fs.Sub to read the migrations from a sub directory.
+func New(db postgres.Postgres, fsys fs.FS, opts ...Option) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Migrator{
+		db:         db,
+		migrations: migrations,
+		tableName:  DefaultTableName,
+	}
+
+	for _, opt := range opts {
+		opt.Apply(m)
+	}
+
+	if !m.customLock {
+		hash := fnv.New64a()
+		_, _ = hash.Write([]byte(m.tableName))
+		m.lockID = int64(hash.Sum64())
+	}
+	return m, nil
+}
+
+// Load reads the migrations from the root directory of fsys sorted by version.
+// Files not matching the migration file names are ignored.
+func Load(fsys fs.FS) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the migrations directory")
+	}
+
+	byVersion := make(map[uint64]*Migration)
+	for _, entry := range entries {
+		matches := migrationFile.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || matches == nil {
+			continue
+		}
+
+		version, err := strconv.ParseUint(matches[1], 10, 64)
+		if err != nil || version == 0 {
+			return nil, errors.Errorf("invalid migration version (%s)", entry.Name())
+		}
+
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read the migration (%s)", entry.Name())
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: matches[2]}
+			byVersion[version] = migration
+		}
+
+		if migration.Name != matches[2] {
+			return nil, errors.Errorf("duplicate migration version (%d)", version)
+		}
+
+		script := &migration.Up
+		if matches[3] == "down" {
+			script = &migration.Down
+		}
+
+		if *script != "" {
+			return nil, errors.Errorf("duplicate migration (%s)", entry.Name())
+		}
+		*script = string(content)
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, errors.Errorf("missing up migration for version (%d)", migration.Version)
+		}
+		migrations = append(migrations, migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// Migrations returns the migrations sorted by version
+func (m *Migrator) Migrations() []*Migration {
+	return m.migrations
+}
+
+// Up applies the pending migrations in ascending order of version.
+// It returns the number of applied migrations.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	applied := 0
+	err := m.run(ctx, func(tx *sql.Tx, versions map[uint64]bool) error {
+		for _, migration := range m.migrations {
+			if versions[migration.Version] {
+				continue
+			}
+
+			if _, err := tx.ExecContext(ctx, migration.Up); err != nil {
+				return errors.Wrapf(err, "failed to apply the migration (%d_%s)", migration.Version, migration.Name)
+			}
+
+			insertSQL := fmt.Sprintf("INSERT INTO %s (version, name) VALUES ($1, $2)", m.tableName)
+			if _, err := tx.ExecContext(ctx, insertSQL, int64(migration.Version), migration.Name); err != nil {
+				return errors.Wrapf(err, "failed to record the migration (%d_%s)", migration.Version, migration.Name)
+			}
+			applied++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return applied, nil
+}
+
+// Down reverts the given number of the latest applied migrations in descending order of version.
+// It returns the number of reverted migrations.
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	reverted := 0
+	err := m.run(ctx, func(tx *sql.Tx, versions map[uint64]bool) error {
+		for i := len(m.migrations) - 1; i >= 0 && reverted < steps; i-- {
+			migration := m.migrations[i]
+			if !versions[migration.Version] {
+				continue
+			}
+
+			if migration.Down == "" {
+				return errors.Errorf("missing down migration for version (%d)", migration.Version)
+			}
+
+			if _, err := tx.ExecContext(ctx, migration.Down); err != nil {
+				return errors.Wrapf(err, "failed to revert the migration (%d_%s)", migration.Version, migration.Name)
+			}
+
+			deleteSQL := fmt.Sprintf("DELETE FROM %s WHERE version = $1", m.tableName)
+			if _, err := tx.ExecContext(ctx, deleteSQL, int64(migration.Version)); err != nil {
+				return errors.Wrapf(err, "failed to remove the migration (%d_%s)", migration.Version, migration.Name)
+			}
+			reverted++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return reverted, nil
+}
+
+// Version returns the latest applied migration version. It returns zero when no migration has been applied.
+func (m *Migrator) Version(ctx context.Context) (uint64, error) {
+	var version uint64
+	err := m.run(ctx, func(tx *sql.Tx, versions map[uint64]bool) error {
+		for applied := range versions {
+			version = max(version, applied)
+		}
+		return nil
+	})
+	return version, err
+}
+
+// run executes fn in a transaction holding the advisory lock with the applied versions
+func (m *Migrator) run(ctx context.Context, fn func(tx *sql.Tx, versions map[uint64]bool) error) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin the migration transaction")
+	}
+
+	if err := m.runTx(ctx, tx, fn); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return errors.Wrap(err, rollbackErr.Error())
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "failed to commit the migration transaction")
+	}
+	return nil
+}
+
+// runTx acquires the advisory lock, creates the migrations table and reads the applied versions before calling fn
+func (m *Migrator) runTx(ctx context.Context, tx *sql.Tx, fn func(tx *sql.Tx, versions map[uint64]bool) error) error {
+	// the lock is released at the end of the transaction
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", m.lockID); err != nil {
+		return errors.Wrap(err, "failed to acquire the migration lock")
+	}
+
+	createSQL := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s
+		(
+			version		BIGINT PRIMARY KEY,
+			name		TEXT NOT NULL,
+			applied_at	TIMESTAMPTZ NOT NULL DEFAULT now()
+		)`, m.tableName)
+	if _, err := tx.ExecContext(ctx, createSQL); err != nil {
+		return errors.Wrap(err, "failed to create the migrations table")
+	}
+
+	versions, err := m.appliedVersions(ctx, tx)
+	if err != nil {
+		return err
+	}
+	return fn(tx, versions)
+}
+
+// appliedVersions returns the versions of the applied migrations
+func (m *Migrator) appliedVersions(ctx context.Context, tx *sql.Tx) (map[uint64]bool, error) {
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf("SELECT version FROM %s", m.tableName))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch the applied migrations")
+	}
+	defer rows.Close()
+
+	versions := make(map[uint64]bool)
+	for rows.Next() {
+		var version int64
+		if err := rows.Scan(&version); err != nil {
+			return nil, errors.Wrap(err, "failed to fetch the applied migrations")
+		}
+		versions[uint64(version)] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to fetch the applied migrations")
+	}
+	return versions, nil
+}
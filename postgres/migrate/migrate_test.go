@@ -0,0 +1,226 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package migrate
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tochemey/gopack/postgres"
+)
+
+// migrations is the test migrations directory
+var migrations = fstest.MapFS{
+	"1_create_accounts.up.sql":      {Data: []byte(`CREATE TABLE accounts (account_id UUID PRIMARY KEY);`)},
+	"1_create_accounts.down.sql":    {Data: []byte(`DROP TABLE accounts;`)},
+	"2_add_account_name.up.sql":     {Data: []byte(`ALTER TABLE accounts ADD COLUMN account_name VARCHAR(255);`)},
+	"2_add_account_name.down.sql":   {Data: []byte(`ALTER TABLE accounts DROP COLUMN account_name;`)},
+	"10_create_orders.up.sql":       {Data: []byte(`CREATE TABLE orders (order_id UUID PRIMARY KEY);`)},
+	"10_create_orders.down.sql":     {Data: []byte(`DROP TABLE orders;`)},
+	"README.md":                     {Data: []byte(`migrations`)},
+	"archive/3_ignored.up.sql":      {Data: []byte(`SELECT 1;`)},
+	"archive/3_ignored.down.sql":    {Data: []byte(`SELECT 1;`)},
+	"archive/README.md":             {Data: []byte(`archived migrations`)},
+	"archive/nested/4_too.up.sql":   {Data: []byte(`SELECT 1;`)},
+	"archive/nested/4_too.down.sql": {Data: []byte(`SELECT 1;`)},
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("with valid migrations", func(t *testing.T) {
+		loaded, err := Load(migrations)
+		require.NoError(t, err)
+		require.Len(t, loaded, 3)
+		assert.EqualValues(t, 1, loaded[0].Version)
+		assert.Equal(t, "create_accounts", loaded[0].Name)
+		assert.Equal(t, `DROP TABLE accounts;`, loaded[0].Down)
+		assert.EqualValues(t, 2, loaded[1].Version)
+		assert.EqualValues(t, 10, loaded[2].Version)
+		assert.Equal(t, `CREATE TABLE orders (order_id UUID PRIMARY KEY);`, loaded[2].Up)
+	})
+
+	t.Run("without down migration", func(t *testing.T) {
+		loaded, err := Load(fstest.MapFS{"1_init.up.sql": {Data: []byte(`SELECT 1;`)}})
+		require.NoError(t, err)
+		require.Len(t, loaded, 1)
+		assert.Empty(t, loaded[0].Down)
+	})
+
+	t.Run("without up migration", func(t *testing.T) {
+		_, err := Load(fstest.MapFS{"1_init.down.sql": {Data: []byte(`SELECT 1;`)}})
+		assert.EqualError(t, err, "missing up migration for version (1)")
+	})
+
+	t.Run("with duplicate version", func(t *testing.T) {
+		_, err := Load(fstest.MapFS{
+			"1_init.up.sql":   {Data: []byte(`SELECT 1;`)},
+			"01_other.up.sql": {Data: []byte(`SELECT 2;`)},
+		})
+		assert.EqualError(t, err, "duplicate migration version (1)")
+	})
+
+	t.Run("with zero version", func(t *testing.T) {
+		_, err := Load(fstest.MapFS{"0_init.up.sql": {Data: []byte(`SELECT 1;`)}})
+		assert.EqualError(t, err, "invalid migration version (0_init.up.sql)")
+	})
+}
+
+type migrateSuite struct {
+	suite.Suite
+	container *postgres.TestContainer
+}
+
+// SetupSuite starts the Postgres database engine and set the container
+// host and port to use in the tests
+func (s *migrateSuite) SetupSuite() {
+	s.container = postgres.NewTestContainer("testdb", "test", "test")
+}
+
+func (s *migrateSuite) TearDownSuite() {
+	s.container.Cleanup()
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestMigrateSuite(t *testing.T) {
+	suite.Run(t, new(migrateSuite))
+}
+
+func (s *migrateSuite) TestUpAndDown() {
+	ctx := context.TODO()
+	db := s.container.GetTestDB()
+	s.Require().NoError(db.Connect(ctx))
+	defer func() {
+		s.Assert().NoError(db.Disconnect(ctx))
+	}()
+
+	migrator, err := New(db, migrations)
+	s.Require().NoError(err)
+
+	version, err := migrator.Version(ctx)
+	s.Require().NoError(err)
+	s.Assert().Zero(version)
+
+	applied, err := migrator.Up(ctx)
+	s.Require().NoError(err)
+	s.Assert().Equal(3, applied)
+	s.Assert().NoError(db.TableExists(ctx, "orders"))
+	_, err = db.Exec(ctx, `INSERT INTO accounts (account_id, account_name) VALUES ($1, 'some-account')`, uuid.New().String())
+	s.Assert().NoError(err)
+
+	// the applied migrations are skipped
+	applied, err = migrator.Up(ctx)
+	s.Require().NoError(err)
+	s.Assert().Zero(applied)
+
+	version, err = migrator.Version(ctx)
+	s.Require().NoError(err)
+	s.Assert().EqualValues(10, version)
+
+	reverted, err := migrator.Down(ctx, 2)
+	s.Require().NoError(err)
+	s.Assert().Equal(2, reverted)
+
+	version, err = migrator.Version(ctx)
+	s.Require().NoError(err)
+	s.Assert().EqualValues(1, version)
+
+	reverted, err = migrator.Down(ctx, 5)
+	s.Require().NoError(err)
+	s.Assert().Equal(1, reverted)
+
+	count, err := db.Count(ctx, DefaultTableName)
+	s.Require().NoError(err)
+	s.Assert().Zero(count)
+}
+
+func (s *migrateSuite) TestFailedMigration() {
+	ctx := context.TODO()
+	db := s.container.GetTestDB()
+	s.Require().NoError(db.Connect(ctx))
+	defer func() {
+		s.Assert().NoError(db.Disconnect(ctx))
+	}()
+
+	migrator, err := New(db, fstest.MapFS{
+		"1_create_cars.up.sql": {Data: []byte(`CREATE TABLE cars (car_id UUID PRIMARY KEY);`)},
+		"2_invalid.up.sql":     {Data: []byte(`CREATE TABLE;`)},
+	}, WithTableName("failed_migrations"))
+	s.Require().NoError(err)
+
+	_, err = migrator.Up(ctx)
+	s.Assert().ErrorContains(err, "failed to apply the migration (2_invalid)")
+
+	// the whole run is rolled back
+	version, err := migrator.Version(ctx)
+	s.Require().NoError(err)
+	s.Assert().Zero(version)
+	s.Assert().NoError(db.DropTable(ctx, "cars"))
+}
+
+func (s *migrateSuite) TestConcurrentRunners() {
+	ctx := context.TODO()
+	db := s.container.GetTestDB()
+	s.Require().NoError(db.Connect(ctx))
+	defer func() {
+		s.Assert().NoError(db.DropTable(ctx, "concurrent_migrations"))
+		s.Assert().NoError(db.DropTable(ctx, "products"))
+		s.Assert().NoError(db.Disconnect(ctx))
+	}()
+
+	fsys := fstest.MapFS{
+		"1_create_products.up.sql": {Data: []byte(`CREATE TABLE products (product_id UUID PRIMARY KEY);`)},
+	}
+
+	const runners = 5
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		applied int
+	)
+	for range runners {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			migrator, err := New(db, fsys, WithTableName("concurrent_migrations"))
+			s.Assert().NoError(err)
+			count, err := migrator.Up(ctx)
+			s.Assert().NoError(err)
+			mu.Lock()
+			applied += count
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	// the migration is applied only once
+	s.Assert().Equal(1, applied)
+}
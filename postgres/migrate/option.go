@@ -0,0 +1,61 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package migrate
+
+// DefaultTableName is the default table recording the applied migrations
+const DefaultTableName = "schema_migrations"
+
+// Option is the interface that applies a configuration option.
+type Option interface {
+	// Apply sets the Option value of a Migrator.
+	Apply(*Migrator)
+}
+
+var _ Option = OptionFunc(nil)
+
+// OptionFunc implements the Option interface.
+type OptionFunc func(*Migrator)
+
+// Apply applies the option
+func (f OptionFunc) Apply(m *Migrator) {
+	f(m)
+}
+
+// WithTableName sets the table recording the applied migrations.
+// The table name can be qualified with its schema. It defaults to DefaultTableName.
+func WithTableName(tableName string) Option {
+	return OptionFunc(func(m *Migrator) {
+		m.tableName = tableName
+	})
+}
+
+// WithLockID sets the key of the advisory lock preventing concurrent runs.
+// It defaults to a key derived from the table name.
+func WithLockID(lockID int64) Option {
+	return OptionFunc(func(m *Migrator) {
+		m.lockID = lockID
+		m.customLock = true
+	})
+}
@@ -0,0 +1,209 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package postgres
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// BindNamed converts a query with named parameters such as :name into a positional query using $1, $2...
+// and returns the arguments in the order of their placeholders.
+// The arguments are read from arg which is either a map[string]any or a struct, or a pointer to a struct,
+// whose fields are named after their db tag or their snake cased name. A parameter used several times
+// is bound to the same placeholder. Postgres casts such as ::text and quoted literals are left untouched.
+func BindNamed(query string, arg any) (string, []any, error) {
+	values, err := namedValues(arg)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var (
+		builder   strings.Builder
+		args      []any
+		positions = make(map[string]int)
+	)
+
+	for i := 0; i < len(query); i++ {
+		char := query[i]
+		switch {
+		case char == '\'' || char == '"':
+			// copy the quoted literal or identifier as is
+			end := strings.IndexByte(query[i+1:], char)
+			if end < 0 {
+				builder.WriteString(query[i:])
+				i = len(query)
+				continue
+			}
+			builder.WriteString(query[i : i+end+2])
+			i += end + 1
+		case char == ':' && i+1 < len(query) && query[i+1] == ':':
+			// postgres cast
+			builder.WriteString("::")
+			i++
+		case char == ':' && i+1 < len(query) && isNameChar(rune(query[i+1])):
+			end := i + 1
+			for end < len(query) && isNameChar(rune(query[end])) {
+				end++
+			}
+
+			name := query[i+1 : end]
+			position, ok := positions[name]
+			if !ok {
+				value, found := values(name)
+				if !found {
+					return "", nil, fmt.Errorf("missing named parameter (%s)", name)
+				}
+				args = append(args, value)
+				position = len(args)
+				positions[name] = position
+			}
+
+			builder.WriteString("$" + strconv.Itoa(position))
+			i = end - 1
+		default:
+			builder.WriteByte(char)
+		}
+	}
+	return builder.String(), args, nil
+}
+
+// NamedQuery returns a QueryBuilder of the given named query to use with the TxRunner.
+// See BindNamed.
+func NamedQuery(query string, arg any) QueryBuilder {
+	return &namedQuery{query: query, arg: arg}
+}
+
+// namedQuery implements QueryBuilder for a named query
+type namedQuery struct {
+	query string
+	arg   any
+}
+
+// BuildQuery binds the named parameters
+func (q *namedQuery) BuildQuery() (string, []any, error) {
+	return BindNamed(q.query, q.arg)
+}
+
+// namedValues returns a lookup function of the named values of the given argument
+func namedValues(arg any) (func(name string) (any, bool), error) {
+	if values, ok := arg.(map[string]any); ok {
+		return func(name string) (any, bool) {
+			value, found := values[name]
+			return value, found
+		}, nil
+	}
+
+	value := reflect.ValueOf(arg)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil, fmt.Errorf("named argument is nil")
+		}
+		value = value.Elem()
+	}
+
+	if value.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("unsupported named argument type (%T): must be a struct or a map[string]any", arg)
+	}
+
+	fields := make(map[string]any)
+	collectFields(value, fields)
+	return func(name string) (any, bool) {
+		value, found := fields[name]
+		return value, found
+	}, nil
+}
+
+// collectFields collects the exported fields of the given struct value by column name.
+// The fields of the embedded structs are promoted.
+func collectFields(value reflect.Value, fields map[string]any) {
+	valueType := value.Type()
+	for i := 0; i < valueType.NumField(); i++ {
+		field := valueType.Field(i)
+		// the exported fields of an unexported embedded struct are promoted
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+
+		tag := field.Tag.Get("db")
+		if tag == "-" {
+			continue
+		}
+
+		if field.Anonymous && tag == "" {
+			embedded := value.Field(i)
+			if embedded.Kind() == reflect.Pointer {
+				// the fields of an unexported embedded pointer cannot be read
+				if embedded.IsNil() || !field.IsExported() {
+					continue
+				}
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				collectFields(embedded, fields)
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		name := tag
+		if name == "" {
+			name = toSnakeCase(field.Name)
+		}
+		if _, exists := fields[name]; !exists {
+			fields[name] = value.Field(i).Interface()
+		}
+	}
+}
+
+// isNameChar checks whether the given character can be part of a parameter name
+func isNameChar(char rune) bool {
+	return char == '_' || unicode.IsLetter(char) || unicode.IsDigit(char)
+}
+
+// toSnakeCase converts a Go field name into its snake cased column name, e.g. UserID to user_id
+func toSnakeCase(name string) string {
+	runes := []rune(name)
+	var builder strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			previousLower := i > 0 && !unicode.IsUpper(runes[i-1])
+			nextLower := i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if previousLower || nextLower {
+				builder.WriteByte('_')
+			}
+			builder.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
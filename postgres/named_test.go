@@ -0,0 +1,97 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// auditFields is embedded in the named test struct
+type auditFields struct {
+	CreatedAt time.Time
+}
+
+// namedAccount is a named query test struct
+type namedAccount struct {
+	auditFields
+	AccountID   string
+	Name        string `db:"account_name"`
+	Secret      string `db:"-"`
+	HTTPAddress string
+}
+
+func TestBindNamed(t *testing.T) {
+	createdAt := time.Now()
+	arg := &namedAccount{
+		auditFields: auditFields{CreatedAt: createdAt},
+		AccountID:   "id-1",
+		Name:        "account",
+		Secret:      "secret",
+		HTTPAddress: "http://localhost",
+	}
+
+	t.Run("with struct argument", func(t *testing.T) {
+		query, args, err := BindNamed(
+			`UPDATE accounts SET account_name = :account_name, created_at = :created_at, address = :http_address WHERE account_id = :account_id OR parent_id = :account_id`,
+			arg)
+		require.NoError(t, err)
+		assert.Equal(t, `UPDATE accounts SET account_name = $1, created_at = $2, address = $3 WHERE account_id = $4 OR parent_id = $4`, query)
+		assert.Equal(t, []any{"account", createdAt, "http://localhost", "id-1"}, args)
+	})
+	t.Run("with map argument", func(t *testing.T) {
+		query, args, err := BindNamed(`SELECT * FROM accounts WHERE account_id = :id`, map[string]any{"id": 42})
+		require.NoError(t, err)
+		assert.Equal(t, `SELECT * FROM accounts WHERE account_id = $1`, query)
+		assert.Equal(t, []any{42}, args)
+	})
+	t.Run("with casts and literals", func(t *testing.T) {
+		query, args, err := BindNamed(`SELECT ':not_a_param', "col:umn", :id::text FROM accounts`, map[string]any{"id": 1})
+		require.NoError(t, err)
+		assert.Equal(t, `SELECT ':not_a_param', "col:umn", $1::text FROM accounts`, query)
+		assert.Equal(t, []any{1}, args)
+	})
+	t.Run("with missing parameter", func(t *testing.T) {
+		_, _, err := BindNamed(`SELECT * FROM accounts WHERE secret = :secret`, arg)
+		assert.EqualError(t, err, "missing named parameter (secret)")
+	})
+	t.Run("with invalid argument", func(t *testing.T) {
+		_, _, err := BindNamed(`SELECT 1`, 42)
+		assert.Error(t, err)
+
+		var nilAccount *namedAccount
+		_, _, err = BindNamed(`SELECT 1`, nilAccount)
+		assert.Error(t, err)
+	})
+	t.Run("with query builder", func(t *testing.T) {
+		query, args, err := NamedQuery(`DELETE FROM accounts WHERE account_id = :account_id`, arg).BuildQuery()
+		require.NoError(t, err)
+		assert.Equal(t, `DELETE FROM accounts WHERE account_id = $1`, query)
+		assert.Equal(t, []any{"id-1"}, args)
+	})
+}
@@ -0,0 +1,114 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package outbox
+
+import (
+	"time"
+
+	"github.com/tochemey/gopack/log"
+	"github.com/tochemey/gopack/log/zapl"
+)
+
+const (
+	// DefaultTableName is the default outbox table
+	DefaultTableName = "outbox"
+	// DefaultBatchSize is the default number of events relayed per poll
+	DefaultBatchSize = 100
+	// DefaultPollInterval is the default delay between two polls of the outbox
+	DefaultPollInterval = time.Second
+)
+
+// config holds the outbox settings shared by the Writer and the Relay
+type config struct {
+	tableName    string
+	batchSize    int
+	pollInterval time.Duration
+	logger       log.Logger
+}
+
+// newConfig creates the default config and applies the given options
+func newConfig(opts ...Option) *config {
+	cfg := &config{
+		tableName:    DefaultTableName,
+		batchSize:    DefaultBatchSize,
+		pollInterval: DefaultPollInterval,
+		logger:       zapl.DefaultLogger,
+	}
+	for _, opt := range opts {
+		opt.Apply(cfg)
+	}
+	return cfg
+}
+
+// Option is the interface that applies a configuration option.
+type Option interface {
+	// Apply sets the Option value of a config.
+	Apply(*config)
+}
+
+var _ Option = OptionFunc(nil)
+
+// OptionFunc implements the Option interface.
+type OptionFunc func(*config)
+
+// Apply applies the option
+func (f OptionFunc) Apply(c *config) {
+	f(c)
+}
+
+// WithTableName sets the outbox table. The table name can be qualified with its schema.
+// It defaults to DefaultTableName. The Writer and the Relay must use the same table.
+func WithTableName(tableName string) Option {
+	return OptionFunc(func(c *config) {
+		c.tableName = tableName
+	})
+}
+
+// WithBatchSize sets the maximum number of events the Relay publishes per poll.
+// It defaults to DefaultBatchSize.
+func WithBatchSize(batchSize int) Option {
+	return OptionFunc(func(c *config) {
+		if batchSize > 0 {
+			c.batchSize = batchSize
+		}
+	})
+}
+
+// WithPollInterval sets the delay between two polls of the outbox when it has been drained.
+// It defaults to DefaultPollInterval.
+func WithPollInterval(interval time.Duration) Option {
+	return OptionFunc(func(c *config) {
+		if interval > 0 {
+			c.pollInterval = interval
+		}
+	})
+}
+
+// WithLogger sets the logger reporting the Relay failures. It defaults to zapl.DefaultLogger
+func WithLogger(logger log.Logger) Option {
+	return OptionFunc(func(c *config) {
+		c.logger = logger
+	})
+}
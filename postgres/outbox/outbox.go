@@ -0,0 +1,159 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/tochemey/gopack/postgres"
+)
+
+// TableSchema is the statement creating the outbox table and its index.
+// The %s placeholder is the table name.
+const TableSchema = `CREATE TABLE IF NOT EXISTS %[1]s (
+	seq BIGSERIAL,
+	event_id TEXT PRIMARY KEY,
+	topic TEXT NOT NULL,
+	ordering_key TEXT NOT NULL DEFAULT '',
+	payload BYTEA,
+	attributes JSONB,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	published_at TIMESTAMPTZ,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	last_error TEXT
+);
+CREATE INDEX IF NOT EXISTS %[2]s_pending_idx ON %[1]s (seq) WHERE published_at IS NULL;`
+
+// Event is a message recorded in the outbox and later published by the Relay
+type Event struct {
+	// ID uniquely identifies the event. It is the deduplication key: recording the same ID twice
+	// keeps the first event, and consumers should use it to drop the redelivered events.
+	// A random ID is set when it is empty.
+	ID string
+	// Topic is the destination of the event
+	Topic string
+	// Key is the optional ordering key. The events sharing a key are published in the order they were recorded.
+	Key string
+	// Payload is the event content
+	Payload []byte
+	// Attributes are the optional event metadata
+	Attributes map[string]string
+	// CreatedAt is the time the event was recorded. It is set by the database.
+	CreatedAt time.Time
+	// Attempts is the number of failed publications of the event
+	Attempts int
+}
+
+// Writer records events in the outbox as part of the caller database transaction,
+// so that the events are only published when the transaction commits.
+type Writer struct {
+	tableName string
+}
+
+// NewWriter creates an instance of Writer. Only the WithTableName option is relevant to the Writer.
+func NewWriter(opts ...Option) *Writer {
+	return &Writer{tableName: newConfig(opts...).tableName}
+}
+
+// CreateTable creates the outbox table when it does not exist
+func (w *Writer) CreateTable(ctx context.Context, db postgres.Postgres) error {
+	_, err := db.Exec(ctx, fmt.Sprintf(TableSchema, w.tableName, indexPrefix(w.tableName)))
+	return err
+}
+
+// Record returns the query builder inserting the events. Add it to a postgres.TxRunner
+// along with the business queries so that they are committed together.
+func (w *Writer) Record(events ...*Event) postgres.QueryBuilder {
+	return &recordBuilder{tableName: w.tableName, events: events}
+}
+
+// Write inserts the events using the transaction handed over by postgres.Postgres WithinTx
+func (w *Writer) Write(ctx context.Context, tx postgres.Tx, events ...*Event) error {
+	query, args, err := w.Record(events...).BuildQuery()
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, query, args...)
+	return err
+}
+
+// recordBuilder builds the statement inserting the events
+type recordBuilder struct {
+	tableName string
+	events    []*Event
+}
+
+// enforce compilation error
+var _ postgres.QueryBuilder = (*recordBuilder)(nil)
+
+// BuildQuery returns the insert statement. The events already recorded are ignored.
+func (b *recordBuilder) BuildQuery() (string, []any, error) {
+	if len(b.events) == 0 {
+		return "", nil, errors.New("no events to record")
+	}
+
+	const columns = 5
+	placeholders := make([]string, 0, len(b.events))
+	args := make([]any, 0, len(b.events)*columns)
+	for _, event := range b.events {
+		if event.Topic == "" {
+			return "", nil, errors.New("the event topic is required")
+		}
+
+		if event.ID == "" {
+			event.ID = uuid.NewString()
+		}
+
+		var attributes any
+		if len(event.Attributes) > 0 {
+			bytea, err := json.Marshal(event.Attributes)
+			if err != nil {
+				return "", nil, errors.Wrapf(err, "failed to encode the attributes of the event (%s)", event.ID)
+			}
+			attributes = string(bytea)
+		}
+
+		offset := len(args)
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d)",
+			offset+1, offset+2, offset+3, offset+4, offset+5))
+		args = append(args, event.ID, event.Topic, event.Key, event.Payload, attributes)
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (event_id, topic, ordering_key, payload, attributes) VALUES %s ON CONFLICT (event_id) DO NOTHING",
+		b.tableName, strings.Join(placeholders, ", "))
+	return query, args, nil
+}
+
+// indexPrefix derives the index name from the possibly schema qualified table name
+func indexPrefix(tableName string) string {
+	return strings.ReplaceAll(tableName, ".", "_")
+}
@@ -0,0 +1,240 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tochemey/gopack/broker"
+	"github.com/tochemey/gopack/log"
+	"github.com/tochemey/gopack/log/zapl"
+	"github.com/tochemey/gopack/postgres"
+)
+
+// fakeDB runs the WithinTx function against a fakeTx
+type fakeDB struct {
+	postgres.Postgres
+	tx *fakeTx
+}
+
+func (f *fakeDB) WithinTx(_ context.Context, _ *sql.TxOptions, fn func(tx postgres.Tx) error, _ ...postgres.TxOption) error {
+	return fn(f.tx)
+}
+
+// fakeTx returns the pending events and records the executed statements
+type fakeTx struct {
+	postgres.Tx
+	pending    []*pendingEvent
+	statements []string
+	args       [][]any
+}
+
+func (f *fakeTx) SelectAll(_ context.Context, dst any, _ string, _ ...any) error {
+	*(dst.(*[]*pendingEvent)) = f.pending
+	return nil
+}
+
+func (f *fakeTx) Exec(_ context.Context, query string, args ...any) (sql.Result, error) {
+	f.statements = append(f.statements, query)
+	f.args = append(f.args, args)
+	return driver.RowsAffected(1), nil
+}
+
+func TestRecord(t *testing.T) {
+	t.Run("with events", func(t *testing.T) {
+		writer := NewWriter(WithTableName("events.outbox"))
+		query, args, err := writer.Record(
+			&Event{ID: "event-1", Topic: "accounts", Key: "account-1", Payload: []byte("created")},
+			&Event{Topic: "accounts", Attributes: map[string]string{"source": "test"}},
+		).BuildQuery()
+		require.NoError(t, err)
+		assert.Equal(t, "INSERT INTO events.outbox (event_id, topic, ordering_key, payload, attributes) "+
+			"VALUES ($1, $2, $3, $4, $5), ($6, $7, $8, $9, $10) ON CONFLICT (event_id) DO NOTHING", query)
+		require.Len(t, args, 10)
+		assert.Equal(t, []any{"event-1", "accounts", "account-1", []byte("created"), nil}, args[:5])
+		// a random ID is set
+		assert.NotEmpty(t, args[5])
+		assert.Equal(t, `{"source":"test"}`, args[9])
+	})
+
+	t.Run("without events", func(t *testing.T) {
+		_, _, err := NewWriter().Record().BuildQuery()
+		assert.EqualError(t, err, "no events to record")
+	})
+
+	t.Run("without topic", func(t *testing.T) {
+		_, _, err := NewWriter().Record(&Event{ID: "event-1"}).BuildQuery()
+		assert.EqualError(t, err, "the event topic is required")
+	})
+}
+
+func TestRelayOnce(t *testing.T) {
+	ctx := context.TODO()
+	tx := &fakeTx{
+		pending: []*pendingEvent{
+			{EventID: "event-1", Topic: "accounts", OrderingKey: "account-1"},
+			{EventID: "event-2", Topic: "accounts", OrderingKey: "account-2"},
+			{EventID: "event-3", Topic: "accounts", OrderingKey: "account-1"},
+			{EventID: "event-4", Topic: "orders", Attributes: []byte(`{"source":"test"}`)},
+		},
+	}
+
+	var published []*Event
+	publisher := PublisherFunc(func(_ context.Context, event *Event) error {
+		if event.ID == "event-1" {
+			return errors.New("unavailable")
+		}
+		published = append(published, event)
+		return nil
+	})
+
+	relay := NewRelay(&fakeDB{tx: tx}, publisher, WithLogger(zapl.New(log.ErrorLevel, io.Discard)))
+	count, err := relay.RelayOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	// event-3 is blocked by the failure of event-1 which shares its ordering key
+	require.Len(t, published, 2)
+	assert.Equal(t, "event-2", published[0].ID)
+	assert.Equal(t, "event-4", published[1].ID)
+	assert.Equal(t, map[string]string{"source": "test"}, published[1].Attributes)
+
+	assert.Equal(t, []string{
+		"UPDATE outbox SET attempts = attempts + 1, last_error = $1 WHERE event_id = $2",
+		"UPDATE outbox SET published_at = NOW() WHERE event_id = ANY($1)",
+	}, tx.statements)
+	assert.Equal(t, []any{"unavailable", "event-1"}, tx.args[0])
+	assert.Equal(t, []any{pq.Array([]string{"event-2", "event-4"})}, tx.args[1])
+}
+
+func TestNewBrokerPublisher(t *testing.T) {
+	ctx := context.TODO()
+	memoryBroker := broker.NewMemoryBroker()
+	publisher := NewBrokerPublisher(memoryBroker)
+
+	event := &Event{ID: "event-1", Topic: "accounts", Key: "account-1", Payload: []byte("created"), Attributes: map[string]string{"source": "test"}}
+	require.NoError(t, publisher.Publish(ctx, event))
+	assert.Equal(t, []*broker.Message{{
+		ID:         "event-1",
+		Key:        "account-1",
+		Payload:    []byte("created"),
+		Attributes: map[string]string{"source": "test"},
+	}}, memoryBroker.Published("accounts"))
+}
+
+type outboxSuite struct {
+	suite.Suite
+	container *postgres.TestContainer
+}
+
+// SetupSuite starts the Postgres database engine and set the container
+// host and port to use in the tests
+func (s *outboxSuite) SetupSuite() {
+	s.container = postgres.NewTestContainer("testdb", "test", "test")
+}
+
+func (s *outboxSuite) TearDownSuite() {
+	s.container.Cleanup()
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestOutboxSuite(t *testing.T) {
+	suite.Run(t, new(outboxSuite))
+}
+
+func (s *outboxSuite) TestWriteAndRelay() {
+	ctx := context.TODO()
+	db := s.container.GetTestDB()
+	s.Require().NoError(db.Connect(ctx))
+	defer func() {
+		s.Assert().NoError(db.DropTable(ctx, DefaultTableName))
+		s.Assert().NoError(db.Disconnect(ctx))
+	}()
+
+	writer := NewWriter()
+	s.Require().NoError(writer.CreateTable(ctx, db))
+
+	// the events of a rolled back transaction are not recorded
+	err := db.WithinTx(ctx, nil, func(tx postgres.Tx) error {
+		s.Require().NoError(writer.Write(ctx, tx, &Event{ID: "event-0", Topic: "accounts"}))
+		return errors.New("rollback")
+	})
+	s.Require().Error(err)
+
+	runner, err := postgres.NewTxRunner(ctx, db)
+	s.Require().NoError(err)
+	s.Require().NoError(runner.AddQueryBuilder(writer.Record(
+		&Event{ID: "event-1", Topic: "accounts", Key: "account-1", Payload: []byte("created")},
+		&Event{ID: "event-2", Topic: "accounts", Key: "account-1", Payload: []byte("updated")},
+	)).Execute())
+
+	// recording the same event again is ignored
+	err = db.WithinTx(ctx, nil, func(tx postgres.Tx) error {
+		return writer.Write(ctx, tx, &Event{ID: "event-1", Topic: "accounts", Payload: []byte("duplicate")})
+	})
+	s.Require().NoError(err)
+
+	mu := sync.Mutex{}
+	var published []*Event
+	relay := NewRelay(db, PublisherFunc(func(_ context.Context, event *Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+		published = append(published, event)
+		return nil
+	}), WithPollInterval(10*time.Millisecond))
+
+	relay.Start(ctx)
+	s.Assert().Eventually(func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(published) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	s.Require().NoError(relay.Stop(ctx))
+
+	s.Assert().Equal("event-1", published[0].ID)
+	s.Assert().Equal([]byte("created"), published[0].Payload)
+	s.Assert().Equal("event-2", published[1].ID)
+
+	count, err := relay.RelayOnce(ctx)
+	s.Require().NoError(err)
+	s.Assert().Zero(count)
+
+	purged, err := relay.Purge(ctx, time.Now().Add(time.Minute))
+	s.Require().NoError(err)
+	s.Assert().EqualValues(2, purged)
+}
@@ -0,0 +1,273 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+
+	"github.com/tochemey/gopack/broker"
+	"github.com/tochemey/gopack/log"
+	"github.com/tochemey/gopack/postgres"
+)
+
+// instrumentationName is the name of the tracer
+const instrumentationName = "github.com/tochemey/gopack/postgres/outbox"
+
+// Publisher publishes the outbox events to the messaging system.
+// It should forward the event ID, for instance as a message attribute,
+// so that the consumers can drop the events delivered more than once.
+type Publisher interface {
+	// Publish publishes the event and returns once the messaging system has acknowledged it
+	Publish(ctx context.Context, event *Event) error
+}
+
+var _ Publisher = PublisherFunc(nil)
+
+// PublisherFunc implements the Publisher interface.
+type PublisherFunc func(ctx context.Context, event *Event) error
+
+// Publish publishes the event
+func (f PublisherFunc) Publish(ctx context.Context, event *Event) error {
+	return f(ctx, event)
+}
+
+// NewBrokerPublisher adapts the broker Publisher, such as the nats or aws/messaging publishers,
+// to the Publisher interface. The event ID is forwarded as the message ID.
+func NewBrokerPublisher(publisher broker.Publisher) Publisher {
+	return PublisherFunc(func(ctx context.Context, event *Event) error {
+		return publisher.Publish(ctx, event.Topic, &broker.Message{
+			ID:         event.ID,
+			Key:        event.Key,
+			Payload:    event.Payload,
+			Attributes: event.Attributes,
+		})
+	})
+}
+
+// Relay polls the outbox and publishes the pending events.
+//
+// The delivery is at-least-once: the events are locked, published and marked as published in one database
+// transaction. When that transaction fails after the publication, the events are published again by the next poll.
+// A failed event is retried on the next poll and blocks the subsequent events sharing its ordering key.
+// Several relays can poll the same outbox since the locked events are skipped. The events ordering
+// is then only guaranteed with a single relay.
+type Relay struct {
+	db        postgres.Postgres
+	publisher Publisher
+
+	tableName    string
+	batchSize    int
+	pollInterval time.Duration
+	logger       log.Logger
+
+	mu      sync.Mutex
+	cancel  context.CancelFunc
+	stopped chan struct{}
+}
+
+// pendingEvent maps a pending row of the outbox table
+type pendingEvent struct {
+	EventID     string
+	Topic       string
+	OrderingKey string
+	Payload     []byte
+	Attributes  []byte
+	CreatedAt   time.Time
+	Attempts    int
+}
+
+// NewRelay creates an instance of Relay publishing the events of the outbox with the given publisher
+func NewRelay(db postgres.Postgres, publisher Publisher, opts ...Option) *Relay {
+	cfg := newConfig(opts...)
+	return &Relay{
+		db:           db,
+		publisher:    publisher,
+		tableName:    cfg.tableName,
+		batchSize:    cfg.batchSize,
+		pollInterval: cfg.pollInterval,
+		logger:       cfg.logger,
+	}
+}
+
+// Start polls the outbox in the background until Stop is called or the given context is done.
+// Calling Start on a started Relay has no effect.
+func (r *Relay) Start(ctx context.Context) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cancel != nil {
+		return
+	}
+
+	ctx, r.cancel = context.WithCancel(ctx)
+	r.stopped = make(chan struct{})
+	go r.run(ctx, r.stopped)
+}
+
+// Stop stops the polling and waits for the in-flight batch to complete or the given context to be done
+func (r *Relay) Stop(ctx context.Context) error {
+	r.mu.Lock()
+	cancel, stopped := r.cancel, r.stopped
+	r.cancel, r.stopped = nil, nil
+	r.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+
+	cancel()
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RelayOnce publishes one batch of pending events and returns the number of events published
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	// Create a span
+	tracer := otel.GetTracerProvider()
+	spanCtx, span := tracer.Tracer(instrumentationName).Start(ctx, "RelayOnce")
+	defer span.End()
+
+	var published int
+	err := r.db.WithinTx(spanCtx, &sql.TxOptions{Isolation: sql.LevelReadCommitted}, func(tx postgres.Tx) error {
+		published = 0
+		query := fmt.Sprintf(`SELECT event_id, topic, ordering_key, payload, attributes, created_at, attempts
+		FROM %s WHERE published_at IS NULL ORDER BY seq LIMIT $1 FOR UPDATE SKIP LOCKED`, r.tableName)
+
+		var pending []*pendingEvent
+		if err := tx.SelectAll(spanCtx, &pending, query, r.batchSize); err != nil {
+			return errors.Wrap(err, "failed to fetch the pending events")
+		}
+
+		blocked := make(map[string]bool)
+		publishedIDs := make([]string, 0, len(pending))
+		for _, row := range pending {
+			if row.OrderingKey != "" && blocked[row.OrderingKey] {
+				continue
+			}
+
+			event, err := row.toEvent()
+			if err == nil {
+				err = r.publisher.Publish(spanCtx, event)
+			}
+
+			if err != nil {
+				r.logger.Errorf("failed to publish the outbox event (%s): %v", row.EventID, err)
+				if row.OrderingKey != "" {
+					blocked[row.OrderingKey] = true
+				}
+
+				statement := fmt.Sprintf("UPDATE %s SET attempts = attempts + 1, last_error = $1 WHERE event_id = $2", r.tableName)
+				if _, err := tx.Exec(spanCtx, statement, err.Error(), row.EventID); err != nil {
+					return errors.Wrapf(err, "failed to record the publication failure of the event (%s)", row.EventID)
+				}
+				continue
+			}
+			publishedIDs = append(publishedIDs, row.EventID)
+		}
+
+		if len(publishedIDs) == 0 {
+			return nil
+		}
+
+		statement := fmt.Sprintf("UPDATE %s SET published_at = NOW() WHERE event_id = ANY($1)", r.tableName)
+		if _, err := tx.Exec(spanCtx, statement, pq.Array(publishedIDs)); err != nil {
+			return errors.Wrap(err, "failed to mark the events as published")
+		}
+		published = len(publishedIDs)
+		return nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+	return published, nil
+}
+
+// Purge deletes the events published before the given time and returns the number of events deleted
+func (r *Relay) Purge(ctx context.Context, before time.Time) (int64, error) {
+	statement := fmt.Sprintf("DELETE FROM %s WHERE published_at IS NOT NULL AND published_at < $1", r.tableName)
+	result, err := r.db.Exec(ctx, statement, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// run polls the outbox until the context is done. A full batch is followed by an immediate poll.
+func (r *Relay) run(ctx context.Context, stopped chan struct{}) {
+	defer close(stopped)
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		published, err := r.RelayOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			r.logger.Errorf("failed to relay the outbox events: %v", err)
+		}
+
+		delay := r.pollInterval
+		if err == nil && published == r.batchSize {
+			delay = 0
+		}
+		timer.Reset(delay)
+	}
+}
+
+// toEvent converts the row into an Event
+func (row *pendingEvent) toEvent() (*Event, error) {
+	event := &Event{
+		ID:        row.EventID,
+		Topic:     row.Topic,
+		Key:       row.OrderingKey,
+		Payload:   row.Payload,
+		CreatedAt: row.CreatedAt,
+		Attempts:  row.Attempts,
+	}
+
+	if len(row.Attributes) > 0 {
+		if err := json.Unmarshal(row.Attributes, &event.Attributes); err != nil {
+			return nil, errors.Wrapf(err, "failed to decode the attributes of the event (%s)", row.EventID)
+		}
+	}
+	return event, nil
+}
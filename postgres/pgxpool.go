@@ -0,0 +1,135 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/XSAM/otelsql"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pkg/errors"
+	semconv "go.opentelemetry.io/otel/semconv/v1.20.0"
+)
+
+// newPoolConfig creates the pgxpool configuration from the given connection string and config.
+// Pool settings left at their zero value fall back to the pgxpool defaults.
+func newPoolConfig(connStr string, config *Config) (*pgxpool.Config, error) {
+	poolConfig, err := pgxpool.ParseConfig(connStr)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse the pool config")
+	}
+
+	if config.MaxConnections > 0 {
+		poolConfig.MaxConns = config.MaxConnections
+	}
+
+	if config.MinConnections > 0 {
+		poolConfig.MinConns = config.MinConnections
+	}
+
+	if poolConfig.MinConns > poolConfig.MaxConns {
+		return nil, errors.Errorf("min connections (%d) is greater than max connections (%d)", poolConfig.MinConns, poolConfig.MaxConns)
+	}
+
+	if config.HealthCheckPeriod > 0 {
+		poolConfig.HealthCheckPeriod = config.HealthCheckPeriod
+	}
+
+	if config.ConnectionMaxLifetime > 0 {
+		poolConfig.MaxConnLifetime = config.ConnectionMaxLifetime
+	}
+
+	// cache the prepared statements per connection
+	poolConfig.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeCacheStatement
+	return poolConfig, nil
+}
+
+// connectPool creates the pgxpool connection pool and the database handle on top of it
+func (p *postgres) connectPool(ctx context.Context) error {
+	poolConfig, err := newPoolConfig(p.connStr, p.config)
+	if err != nil {
+		return err
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		return errors.Wrap(err, "failed to create connection pool")
+	}
+
+	// let us test the connection
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return errors.Wrap(err, "failed to ping database connection")
+	}
+
+	// the idle connections are managed by the pool and not the database handle
+	db := otelsql.OpenDB(stdlib.GetPoolConnector(pool), otelsql.WithAttributes(semconv.DBSystemPostgreSQL))
+	db.SetMaxIdleConns(0)
+
+	p.pool = pool
+	p.dbConnection = db
+	return nil
+}
+
+// poolOf returns the pgxpool connection pool of the given store.
+// It returns nil when the store does not use the PgxPoolDriver.
+func poolOf(db Postgres) *pgxpool.Pool {
+	switch x := db.(type) {
+	case *postgres:
+		return x.pool
+	case *cluster:
+		return poolOf(x.primary)
+	case *TestDB:
+		return poolOf(x.Postgres)
+	case TestDB:
+		return poolOf(x.Postgres)
+	default:
+		return nil
+	}
+}
+
+// copyFromPool bulk loads the rows using the pgx COPY protocol
+func copyFromPool(ctx context.Context, pool *pgxpool.Pool, table string, columns []string, rows [][]any) (int64, error) {
+	// the table name can be qualified with its schema
+	count, err := pool.CopyFrom(ctx, pgx.Identifier(strings.Split(table, ".")), columns, pgx.CopyFromRows(rows))
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to copy the rows")
+	}
+	return count, nil
+}
+
+// closePool closes the database handle and the underlying pool
+func (p *postgres) closePool() error {
+	err := p.dbConnection.Close()
+	p.pool.Close()
+	if err != nil && !errors.Is(err, sql.ErrConnDone) {
+		return err
+	}
+	return nil
+}
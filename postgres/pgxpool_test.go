@@ -0,0 +1,68 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPoolConfig(t *testing.T) {
+	connStr := createConnectionString("localhost", 5432, "testdb", "test", "test", "public") + createSSLOptions(&Config{})
+
+	t.Run("with pool settings", func(t *testing.T) {
+		poolConfig, err := newPoolConfig(connStr, &Config{
+			MaxConnections:        10,
+			MinConnections:        2,
+			HealthCheckPeriod:     5 * time.Second,
+			ConnectionMaxLifetime: time.Minute,
+		})
+		require.NoError(t, err)
+		assert.EqualValues(t, 10, poolConfig.MaxConns)
+		assert.EqualValues(t, 2, poolConfig.MinConns)
+		assert.Equal(t, 5*time.Second, poolConfig.HealthCheckPeriod)
+		assert.Equal(t, time.Minute, poolConfig.MaxConnLifetime)
+		assert.Equal(t, pgx.QueryExecModeCacheStatement, poolConfig.ConnConfig.DefaultQueryExecMode)
+		assert.Equal(t, "public", poolConfig.ConnConfig.RuntimeParams["search_path"])
+		assert.Equal(t, "testdb", poolConfig.ConnConfig.Database)
+	})
+
+	t.Run("with default pool settings", func(t *testing.T) {
+		poolConfig, err := newPoolConfig(connStr, &Config{})
+		require.NoError(t, err)
+		assert.Positive(t, poolConfig.MaxConns)
+		assert.Zero(t, poolConfig.MinConns)
+		assert.Equal(t, time.Minute, poolConfig.HealthCheckPeriod)
+	})
+
+	t.Run("with min connections greater than max connections", func(t *testing.T) {
+		_, err := newPoolConfig(connStr, &Config{MaxConnections: 2, MinConnections: 4})
+		assert.EqualError(t, err, "min connections (4) is greater than max connections (2)")
+	})
+}
@@ -28,13 +28,18 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"sync"
 
 	"github.com/XSAM/otelsql"
 	"github.com/georgysavva/scany/v2/sqlscan"
+	"github.com/jackc/pgx/v5/pgxpool"
 	_ "github.com/lib/pq" //nolint
 	"github.com/pkg/errors"
 	"go.opentelemetry.io/otel"
 	semconv "go.opentelemetry.io/otel/semconv/v1.20.0"
+
+	"github.com/tochemey/gopack/log"
+	"github.com/tochemey/gopack/log/zapl"
 )
 
 // Postgres will be implemented by concrete RDBMS store
@@ -49,11 +54,33 @@ type Postgres interface {
 	// SelectAll fetches a set of rows as defined by the query and scanned those record in the dst.
 	// It returns nil when there is no records to fetch.
 	SelectAll(ctx context.Context, dst any, query string, args ...any) error
+	// SelectStream fetches the rows defined by the query one at a time instead of loading them all in memory.
+	// The returned RowStream must be closed. See also Stream.
+	SelectStream(ctx context.Context, query string, args ...any) (*RowStream, error)
 	// Exec executes an SQL statement against the database and returns the appropriate result or an error.
 	Exec(ctx context.Context, query string, args ...any) (sql.Result, error)
+	// SelectNamed is like Select with a query using named parameters such as :id whose values are read
+	// from arg, a struct with db tags or a map[string]any. See BindNamed.
+	SelectNamed(ctx context.Context, dst any, query string, arg any) error
+	// SelectAllNamed is like SelectAll with a query using named parameters. See BindNamed.
+	SelectAllNamed(ctx context.Context, dst any, query string, arg any) error
+	// ExecNamed is like Exec with a statement using named parameters. See BindNamed.
+	ExecNamed(ctx context.Context, query string, arg any) (sql.Result, error)
 	// BeginTx helps start an SQL transaction. The return transaction object is expected to be used in
 	// the subsequent queries following the BeginTx.
 	BeginTx(ctx context.Context, txOptions *sql.TxOptions) (*sql.Tx, error)
+	// CopyFrom bulk loads the given rows into the table using the Postgres COPY protocol.
+	// Every row must have the values of the given columns in the same order. It returns the number of rows copied.
+	CopyFrom(ctx context.Context, table string, columns []string, rows [][]any) (int64, error)
+	// Status returns the last health check result along with the connection pool statistics
+	Status() Status
+	// HealthEvents returns the channel notified when the database becomes unreachable or recovers.
+	// See Config.HealthCheckPeriod.
+	HealthEvents() <-chan Status
+	// WithinTx runs fn in a database transaction that is committed when fn returns nil and rolled back
+	// when fn returns an error or panics. With the WithTxRetry option the whole transaction is run again
+	// when it fails with a serialization failure (40001) or a deadlock (40P01). fn must then be safe to retry.
+	WithinTx(ctx context.Context, txOptions *sql.TxOptions, fn func(tx Tx) error, opts ...TxOption) error
 }
 
 // Postgres helps interact with the Postgres database
@@ -61,6 +88,16 @@ type postgres struct {
 	connStr      string
 	dbConnection *sql.DB
 	config       *Config
+	configErr    error
+	// pool is only set when connecting with the PgxPoolDriver
+	pool   *pgxpool.Pool
+	logger log.Logger
+
+	statusLock  sync.RWMutex
+	status      Status
+	events      chan Status
+	stopHealth  chan struct{}
+	healthCheck sync.WaitGroup
 }
 
 var _ Postgres = (*postgres)(nil)
@@ -69,15 +106,52 @@ const postgresDriver = "postgres"
 const instrumentationName = "github.com.tochemey.gopack.postgres"
 
 // New returns a store connecting to the given Postgres database.
+// When the config defines a Cluster, the queries fetching rows are routed to the read replicas.
 func New(config *Config) Postgres {
+	if config.Cluster != nil && len(config.Cluster.Replicas) > 0 {
+		return newCluster(config)
+	}
+	return newPostgres(config)
+}
+
+// newPostgres returns a store connecting to a single Postgres database
+func newPostgres(config *Config) *postgres {
 	postgres := new(postgres)
 	postgres.config = config
-	postgres.connStr = createConnectionString(config.DBHost, config.DBPort, config.DBName, config.DBUser, config.DBPassword, config.DBSchema)
+	postgres.logger = config.Logger
+	if postgres.logger == nil {
+		postgres.logger = zapl.DefaultLogger
+	}
+	postgres.events = make(chan Status, healthEventsBuffer)
+	postgres.connStr = createConnectionString(config.DBHost, config.DBPort, config.DBName, config.DBUser, config.DBPassword, config.DBSchema) +
+		createSSLOptions(config)
+	// the invalid settings are reported by Connect
+	postgres.configErr = config.Validate()
 	return postgres
 }
 
 // Connect will connect to our Postgres database
 func (p *postgres) Connect(ctx context.Context) error {
+	if p.configErr != nil {
+		return p.configErr
+	}
+
+	connect := p.connectPQ
+	if p.config.Driver == PgxPoolDriver {
+		connect = p.connectPool
+	}
+
+	if err := connect(ctx); err != nil {
+		return err
+	}
+
+	p.setStatus(nil)
+	p.startHealthCheck()
+	return nil
+}
+
+// connectPQ connects to the database using lib/pq
+func (p *postgres) connectPQ(ctx context.Context) error {
 	// Register an OTel driver
 	driverName, err := otelsql.Register(postgresDriver, otelsql.WithAttributes(semconv.DBSystemPostgreSQL))
 	if err != nil {
@@ -109,7 +183,7 @@ func (p *postgres) Connect(ctx context.Context) error {
 // createConnectionString will create the Postgres connection string from the
 // supplied connection details
 func createConnectionString(host string, port int, name, user string, password string, schema string) string {
-	info := fmt.Sprintf("host=%s port=%d user=%s dbname=%s sslmode=disable", host, port, user, name)
+	info := fmt.Sprintf("host=%s port=%d user=%s dbname=%s", host, port, user, name)
 	// The Postgres driver gets confused in cases where the user has no password
 	// set but a password is passed, so only set password if its non-empty
 	if password != "" {
@@ -129,7 +203,13 @@ func (p *postgres) Exec(ctx context.Context, query string, args ...interface{})
 	tracer := otel.GetTracerProvider()
 	spanCtx, span := tracer.Tracer(instrumentationName).Start(ctx, "Exec")
 	defer span.End()
-	return p.dbConnection.ExecContext(spanCtx, query, args...)
+
+	var result sql.Result
+	err := p.observe(spanCtx, query, args, func(ctx context.Context) (err error) {
+		result, err = p.dbConnection.ExecContext(ctx, query, args...)
+		return err
+	})
+	return result, err
 }
 
 // BeginTx starts a new database transaction
@@ -147,7 +227,9 @@ func (p *postgres) SelectAll(ctx context.Context, dst interface{}, query string,
 	tracer := otel.GetTracerProvider()
 	spanCtx, span := tracer.Tracer(instrumentationName).Start(ctx, "SelectAll")
 	defer span.End()
-	err := sqlscan.Select(spanCtx, p.dbConnection, dst, query, args...)
+	err := p.observe(spanCtx, query, args, func(ctx context.Context) error {
+		return sqlscan.Select(ctx, p.dbConnection, dst, query, args...)
+	})
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil
@@ -164,7 +246,9 @@ func (p *postgres) Select(ctx context.Context, dst interface{}, query string, ar
 	tracer := otel.GetTracerProvider()
 	spanCtx, span := tracer.Tracer(instrumentationName).Start(ctx, "Select")
 	defer span.End()
-	err := sqlscan.Get(spanCtx, p.dbConnection, dst, query, args...)
+	err := p.observe(spanCtx, query, args, func(ctx context.Context) error {
+		return sqlscan.Get(ctx, p.dbConnection, dst, query, args...)
+	})
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil
@@ -183,5 +267,37 @@ func (p *postgres) Disconnect(ctx context.Context) error {
 	if p.dbConnection == nil {
 		return nil
 	}
+
+	p.stopHealthCheck()
+	if p.pool != nil {
+		return p.closePool()
+	}
 	return p.dbConnection.Close()
 }
+
+// SelectNamed fetches only one row using a named query
+func (p *postgres) SelectNamed(ctx context.Context, dst any, query string, arg any) error {
+	query, args, err := BindNamed(query, arg)
+	if err != nil {
+		return err
+	}
+	return p.Select(ctx, dst, query, args...)
+}
+
+// SelectAllNamed fetches rows using a named query
+func (p *postgres) SelectAllNamed(ctx context.Context, dst any, query string, arg any) error {
+	query, args, err := BindNamed(query, arg)
+	if err != nil {
+		return err
+	}
+	return p.SelectAll(ctx, dst, query, args...)
+}
+
+// ExecNamed executes a named sql statement without returning rows against the database
+func (p *postgres) ExecNamed(ctx context.Context, query string, arg any) (sql.Result, error) {
+	query, args, err := BindNamed(query, arg)
+	if err != nil {
+		return nil, err
+	}
+	return p.Exec(ctx, query, args...)
+}
@@ -27,6 +27,7 @@ package postgres
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	_ "github.com/lib/pq"
@@ -268,6 +269,233 @@ func (s *PostgresTestSuite) TestSelectAll() {
 	})
 }
 
+func (s *PostgresTestSuite) TestSelectStream() {
+	ctx := context.TODO()
+	db := s.container.GetTestDB()
+	s.Require().NoError(db.Connect(ctx))
+	s.Require().NoError(db.DropTable(ctx, "accounts"))
+	s.Require().NoError(createTable(ctx, db))
+
+	inserter := NewBulkInserter(db, "accounts", []string{"account_id", "account_name"})
+	for range 100 {
+		s.Require().NoError(inserter.Add(ctx, uuid.New().String(), "some-account"))
+	}
+	s.Require().NoError(inserter.Flush(ctx))
+
+	s.Run("with row stream", func() {
+		stream, err := db.SelectStream(ctx, `SELECT account_id, account_name FROM accounts;`)
+		s.Require().NoError(err)
+		count := 0
+		for stream.Next() {
+			row := new(account)
+			s.Require().NoError(stream.Scan(row))
+			s.Assert().Equal("some-account", row.AccountName)
+			count++
+		}
+		s.Assert().NoError(stream.Err())
+		s.Assert().NoError(stream.Close())
+		s.Assert().Equal(100, count)
+	})
+
+	s.Run("with iterator", func() {
+		count := 0
+		for row, err := range Stream[*account](ctx, db, `SELECT account_id, account_name FROM accounts WHERE account_name = $1;`, "some-account") {
+			s.Require().NoError(err)
+			s.Assert().NotEmpty(row.AccountID)
+			count++
+			if count == 10 {
+				break
+			}
+		}
+		s.Assert().Equal(10, count)
+	})
+
+	s.Run("with canceled context", func() {
+		cancelCtx, cancel := context.WithCancel(ctx)
+		stream, err := db.SelectStream(cancelCtx, `SELECT generate_series(1, 10000000);`)
+		s.Require().NoError(err)
+		s.Require().True(stream.Next())
+		cancel()
+		count := 1
+		for stream.Next() {
+			count++
+		}
+		s.Assert().ErrorIs(stream.Err(), context.Canceled)
+		s.Assert().Less(count, 10000000)
+		s.Assert().NoError(stream.Close())
+	})
+
+	s.Assert().NoError(db.Disconnect(ctx))
+}
+
+func (s *PostgresTestSuite) TestCopyFrom() {
+	ctx := context.TODO()
+	db := s.container.GetTestDB()
+	err := db.Connect(ctx)
+	s.Assert().NoError(err)
+
+	columns := []string{"account_id", "account_name"}
+
+	s.Run("with valid rows", func() {
+		err = db.DropTable(ctx, "accounts")
+		s.Assert().NoError(err)
+		err = createTable(ctx, db)
+		s.Assert().NoError(err)
+
+		rows := [][]any{
+			{uuid.New().String(), "first-account"},
+			{uuid.New().String(), "second-account"},
+		}
+		copied, err := CopyFrom(ctx, db, "accounts", columns, rows)
+		s.Assert().NoError(err)
+		s.Assert().EqualValues(2, copied)
+
+		count, err := db.Count(ctx, "accounts")
+		s.Assert().NoError(err)
+		s.Assert().Equal(2, count)
+	})
+
+	s.Run("with invalid rows", func() {
+		err = db.DropTable(ctx, "accounts")
+		s.Assert().NoError(err)
+		err = createTable(ctx, db)
+		s.Assert().NoError(err)
+
+		rows := [][]any{
+			{uuid.New().String(), "valid-account"},
+			{"not-a-uuid", "invalid-account"},
+		}
+		copied, err := CopyFrom(ctx, db, "accounts", columns, rows)
+		s.Assert().Error(err)
+		s.Assert().Zero(copied)
+
+		// the transaction is rolled back
+		count, err := db.Count(ctx, "accounts")
+		s.Assert().NoError(err)
+		s.Assert().Zero(count)
+	})
+}
+
+func (s *PostgresTestSuite) TestBulkInsert() {
+	ctx := context.TODO()
+	db := s.container.GetTestDB()
+	s.Require().NoError(db.Connect(ctx))
+	s.Require().NoError(db.DropTable(ctx, "accounts"))
+	s.Require().NoError(createTable(ctx, db))
+
+	columns := []string{"account_id", "account_name"}
+	copied, err := db.CopyFrom(ctx, "accounts", columns, [][]any{
+		{uuid.New().String(), "first-account"},
+		{uuid.New().String(), "second-account"},
+	})
+	s.Require().NoError(err)
+	s.Assert().EqualValues(2, copied)
+
+	inserter := NewBulkInserter(db, "accounts", columns, WithBatchSize(100))
+	for range 250 {
+		s.Require().NoError(inserter.Add(ctx, uuid.New().String(), "some-account"))
+	}
+	s.Require().NoError(inserter.Flush(ctx))
+	s.Assert().EqualValues(250, inserter.Inserted())
+
+	count, err := db.Count(ctx, "accounts")
+	s.Require().NoError(err)
+	s.Assert().Equal(252, count)
+	s.Assert().NoError(db.Disconnect(ctx))
+}
+
+func (s *PostgresTestSuite) TestNamed() {
+	ctx := context.TODO()
+	db := s.container.GetTestDB()
+	s.Require().NoError(db.Connect(ctx))
+	s.Require().NoError(db.DropTable(ctx, "accounts"))
+	s.Require().NoError(createTable(ctx, db))
+
+	inserted := &account{
+		AccountID:   uuid.New().String(),
+		AccountName: "some-account",
+	}
+	_, err := db.ExecNamed(ctx, `INSERT INTO accounts(account_id, account_name) VALUES(:account_id, :account_name);`, inserted)
+	s.Require().NoError(err)
+
+	// the named queries can be run in a transaction
+	runner, err := NewTxRunner(ctx, db)
+	s.Require().NoError(err)
+	err = runner.
+		AddQueryBuilder(NamedQuery(`INSERT INTO accounts(account_id, account_name) VALUES(:id, :name);`,
+			map[string]any{"id": uuid.New().String(), "name": "other-account"})).
+		Execute()
+	s.Require().NoError(err)
+
+	selected := new(account)
+	err = db.SelectNamed(ctx, selected, `SELECT account_id, account_name FROM accounts WHERE account_id = :account_id;`, inserted)
+	s.Require().NoError(err)
+	s.Assert().Equal(inserted, selected)
+
+	var all []*account
+	err = db.SelectAllNamed(ctx, &all, `SELECT account_id, account_name FROM accounts WHERE account_name LIKE :pattern;`,
+		map[string]any{"pattern": "%-account"})
+	s.Require().NoError(err)
+	s.Assert().Len(all, 2)
+
+	_, err = db.ExecNamed(ctx, `DELETE FROM accounts WHERE account_id = :unknown;`, inserted)
+	s.Assert().EqualError(err, "missing named parameter (unknown)")
+	s.Assert().NoError(db.Disconnect(ctx))
+}
+
+func (s *PostgresTestSuite) TestPgxPool() {
+	ctx := context.TODO()
+	db := &TestDB{New(&Config{
+		DBUser:            "test",
+		DBName:            "testdb",
+		DBPassword:        "test",
+		DBSchema:          s.container.Schema(),
+		DBHost:            s.container.Host(),
+		DBPort:            s.container.Port(),
+		Driver:            PgxPoolDriver,
+		MaxConnections:    4,
+		MinConnections:    1,
+		HealthCheckPeriod: time.Second,
+	})}
+	s.Require().NoError(db.Connect(ctx))
+	s.Require().NoError(db.DropTable(ctx, "accounts"))
+	s.Require().NoError(createTable(ctx, db))
+
+	inserted := &account{
+		AccountID:   uuid.New().String(),
+		AccountName: "some-account",
+	}
+	s.Require().NoError(insertInto(ctx, db, inserted))
+
+	selected := new(account)
+	err := db.Select(ctx, selected, `SELECT account_id, account_name FROM accounts WHERE account_id = $1;`, inserted.AccountID)
+	s.Require().NoError(err)
+	s.Assert().Equal(inserted, selected)
+
+	columns := []string{"account_id", "account_name"}
+	rows := [][]any{
+		{uuid.New().String(), "first-account"},
+		{uuid.New().String(), "second-account"},
+	}
+	copied, err := CopyFrom(ctx, db, "accounts", columns, rows)
+	s.Require().NoError(err)
+	s.Assert().EqualValues(2, copied)
+
+	runner, err := NewTxRunner(ctx, db)
+	s.Require().NoError(err)
+	err = runner.
+		AddQueryBuilder(NamedQuery(`DELETE FROM accounts WHERE account_id = :id;`, map[string]any{"id": inserted.AccountID})).
+		Execute()
+	s.Require().NoError(err)
+
+	count, err := db.Count(ctx, "accounts")
+	s.Require().NoError(err)
+	s.Assert().Equal(2, count)
+
+	s.Require().NoError(db.Disconnect(ctx))
+	s.Assert().Error(db.TableExists(ctx, "accounts"))
+}
+
 func (s *PostgresTestSuite) TestClose() {
 	ctx := context.TODO()
 	db := s.container.GetTestDB()
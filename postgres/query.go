@@ -0,0 +1,77 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tochemey/gopack/log"
+)
+
+// queryTimeoutKey is the context key of the query timeout
+type queryTimeoutKey struct{}
+
+// WithQueryTimeout returns a copy of ctx overriding the Config.QueryTimeout of the queries run with it.
+// A zero timeout disables the query timeout.
+func WithQueryTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, queryTimeoutKey{}, timeout)
+}
+
+// queryTimeout returns the timeout of the query run with ctx
+func (p *postgres) queryTimeout(ctx context.Context) time.Duration {
+	if timeout, ok := ctx.Value(queryTimeoutKey{}).(time.Duration); ok {
+		return timeout
+	}
+	return p.config.QueryTimeout
+}
+
+// observe runs the query with its timeout and logs it when it exceeds the Config.SlowQueryThreshold
+func (p *postgres) observe(ctx context.Context, query string, args []any, run func(ctx context.Context) error) error {
+	if timeout := p.queryTimeout(ctx); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	err := run(ctx)
+	duration := time.Since(start)
+
+	if threshold := p.config.SlowQueryThreshold; threshold > 0 && duration > threshold {
+		p.logger.WithContext(ctx).Warnf("slow query statement=%q args=%q duration=%q", strings.TrimSpace(query), p.formatArgs(args), duration.String())
+	}
+	return err
+}
+
+// formatArgs formats the query arguments for the slow queries logs
+func (p *postgres) formatArgs(args []any) string {
+	if p.config.RedactQueryArgs && len(args) > 0 {
+		return log.Redacted
+	}
+	return fmt.Sprint(args)
+}
@@ -0,0 +1,117 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tochemey/gopack/log"
+)
+
+// recordingLogger records the warnings
+type recordingLogger struct {
+	log.Logger
+	mu       sync.Mutex
+	warnings []string
+}
+
+func (l *recordingLogger) Warnf(format string, args ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.warnings = append(l.warnings, fmt.Sprintf(format, args...))
+}
+
+func (l *recordingLogger) WithContext(context.Context) log.Logger {
+	return l
+}
+
+func TestObserve(t *testing.T) {
+	ctx := context.TODO()
+
+	t.Run("with slow query", func(t *testing.T) {
+		logger := new(recordingLogger)
+		p := newPostgres(&Config{SlowQueryThreshold: 10 * time.Millisecond, Logger: logger})
+
+		err := p.observe(ctx, " SELECT * FROM accounts WHERE account_id = $1 ", []any{"id-1"}, func(context.Context) error {
+			time.Sleep(20 * time.Millisecond)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Len(t, logger.warnings, 1)
+		assert.Contains(t, logger.warnings[0], `slow query statement="SELECT * FROM accounts WHERE account_id = $1" args="[id-1]" duration=`)
+
+		// fast queries are not logged
+		require.NoError(t, p.observe(ctx, "SELECT 1", nil, func(context.Context) error { return nil }))
+		assert.Len(t, logger.warnings, 1)
+	})
+
+	t.Run("with redacted args", func(t *testing.T) {
+		logger := new(recordingLogger)
+		p := newPostgres(&Config{SlowQueryThreshold: time.Nanosecond, Logger: logger, RedactQueryArgs: true})
+
+		err := p.observe(ctx, "SELECT * FROM users WHERE password = $1", []any{"secret"}, func(context.Context) error {
+			time.Sleep(time.Millisecond)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Len(t, logger.warnings, 1)
+		assert.Contains(t, logger.warnings[0], `args="[REDACTED]"`)
+		assert.NotContains(t, logger.warnings[0], "secret")
+	})
+
+	t.Run("with query timeout", func(t *testing.T) {
+		p := newPostgres(&Config{QueryTimeout: 10 * time.Millisecond})
+		err := p.observe(ctx, "SELECT pg_sleep(1)", nil, func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("with query timeout override", func(t *testing.T) {
+		p := newPostgres(&Config{QueryTimeout: time.Millisecond})
+		err := p.observe(WithQueryTimeout(ctx, 0), "SELECT 1", nil, func(ctx context.Context) error {
+			_, ok := ctx.Deadline()
+			assert.False(t, ok)
+			return nil
+		})
+		require.NoError(t, err)
+
+		err = p.observe(WithQueryTimeout(ctx, time.Hour), "SELECT 1", nil, func(ctx context.Context) error {
+			deadline, ok := ctx.Deadline()
+			assert.True(t, ok)
+			assert.WithinDuration(t, time.Now().Add(time.Hour), deadline, time.Minute)
+			return nil
+		})
+		require.NoError(t, err)
+	})
+}
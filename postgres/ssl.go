@@ -0,0 +1,108 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package postgres
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// createSSLOptions creates the TLS options of the connection string
+func createSSLOptions(config *Config) string {
+	mode := config.SSLMode
+	if mode == "" {
+		mode = SSLModeDisable
+	}
+
+	info := fmt.Sprintf(" sslmode=%s", mode)
+	if config.SSLRootCert != "" {
+		info += fmt.Sprintf(" sslrootcert=%s", quoteOption(config.SSLRootCert))
+	}
+
+	if config.SSLCert != "" {
+		info += fmt.Sprintf(" sslcert=%s", quoteOption(config.SSLCert))
+	}
+
+	if config.SSLKey != "" {
+		info += fmt.Sprintf(" sslkey=%s", quoteOption(config.SSLKey))
+	}
+	return info
+}
+
+// quoteOption quotes a connection string value containing spaces or quotes
+func quoteOption(value string) string {
+	if !strings.ContainsAny(value, ` '\`) {
+		return value
+	}
+	replacer := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+	return "'" + replacer.Replace(value) + "'"
+}
+
+// validateSSL validates the TLS settings
+func validateSSL(config *Config) error {
+	switch config.SSLMode {
+	case "", SSLModeDisable, SSLModeRequire, SSLModeVerifyCA, SSLModeVerifyFull:
+	case SSLModeAllow, SSLModePrefer:
+		if config.Driver != PgxPoolDriver {
+			return errors.Errorf("sslmode %s is only supported by the pgxpool driver", config.SSLMode)
+		}
+	default:
+		return errors.Errorf("invalid sslmode (%s)", config.SSLMode)
+	}
+
+	switch config.ChannelBinding {
+	case "", "disable", "prefer":
+	case "require":
+		// none of the drivers supports the SCRAM-SHA-256-PLUS authentication
+		return errors.New("channel binding require is not supported")
+	default:
+		return errors.Errorf("invalid channel binding (%s)", config.ChannelBinding)
+	}
+
+	if (config.SSLCert == "") != (config.SSLKey == "") {
+		return errors.New("the client certificate and key must be set together")
+	}
+
+	if config.SSLMode == "" || config.SSLMode == SSLModeDisable {
+		if config.SSLRootCert != "" || config.SSLCert != "" {
+			return errors.New("the certificates are set while sslmode is disable")
+		}
+		return nil
+	}
+
+	for _, file := range []string{config.SSLRootCert, config.SSLCert, config.SSLKey} {
+		if file == "" {
+			continue
+		}
+
+		if _, err := os.Stat(file); err != nil {
+			return errors.Wrapf(err, "invalid certificate file (%s)", file)
+		}
+	}
+	return nil
+}
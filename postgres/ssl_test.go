@@ -0,0 +1,92 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package postgres
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateSSLOptions(t *testing.T) {
+	t.Run("with default", func(t *testing.T) {
+		assert.Equal(t, " sslmode=disable", createSSLOptions(&Config{}))
+	})
+
+	t.Run("with certificates", func(t *testing.T) {
+		options := createSSLOptions(&Config{
+			SSLMode:     SSLModeVerifyFull,
+			SSLRootCert: "/certs/root.crt",
+			SSLCert:     "/certs/my client.crt",
+			SSLKey:      `/certs/it's.key`,
+		})
+		assert.Equal(t, ` sslmode=verify-full sslrootcert=/certs/root.crt sslcert='/certs/my client.crt' sslkey='/certs/it\'s.key'`, options)
+	})
+}
+
+func TestValidateSSL(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "client.crt")
+	keyFile := filepath.Join(dir, "client.key")
+	for _, file := range []string{certFile, keyFile} {
+		assert.NoError(t, os.WriteFile(file, []byte("content"), 0o600))
+	}
+
+	testCases := []struct {
+		name   string
+		config *Config
+		err    string
+	}{
+		{name: "with default", config: &Config{}},
+		{name: "with verify full", config: &Config{SSLMode: SSLModeVerifyFull, SSLRootCert: certFile, SSLCert: certFile, SSLKey: keyFile, ChannelBinding: "prefer"}},
+		{name: "with prefer and pgxpool", config: &Config{SSLMode: SSLModePrefer, Driver: PgxPoolDriver}},
+		{name: "with prefer and lib/pq", config: &Config{SSLMode: SSLModePrefer}, err: "sslmode prefer is only supported by the pgxpool driver"},
+		{name: "with invalid mode", config: &Config{SSLMode: "strict"}, err: "invalid sslmode (strict)"},
+		{name: "with channel binding require", config: &Config{SSLMode: SSLModeRequire, ChannelBinding: "require"}, err: "channel binding require is not supported"},
+		{name: "with invalid channel binding", config: &Config{ChannelBinding: "always"}, err: "invalid channel binding (always)"},
+		{name: "with certificate without key", config: &Config{SSLMode: SSLModeRequire, SSLCert: certFile}, err: "the client certificate and key must be set together"},
+		{name: "with certificates and disable", config: &Config{SSLRootCert: certFile}, err: "the certificates are set while sslmode is disable"},
+		{name: "with missing certificate", config: &Config{SSLMode: SSLModeVerifyCA, SSLRootCert: filepath.Join(dir, "missing.crt")}, err: "invalid certificate file"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateSSL(tc.config)
+			if tc.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.err)
+		})
+	}
+
+	t.Run("with invalid settings reported by Connect", func(t *testing.T) {
+		db := New(&Config{DBHost: "localhost", DBPort: 5432, DBName: "testdb", DBUser: "test", SSLMode: "strict"})
+		assert.EqualError(t, db.Connect(context.TODO()), "invalid sslmode (strict)")
+	})
+}
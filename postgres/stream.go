@@ -0,0 +1,137 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"iter"
+
+	"github.com/georgysavva/scany/v2/sqlscan"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RowStream iterates over the rows of a query one at a time.
+// The stream stops when the query context is canceled. It is not safe for concurrent use.
+//
+//	stream, err := db.SelectStream(ctx, "SELECT account_id, account_name FROM accounts")
+//	if err != nil {
+//		return err
+//	}
+//	defer stream.Close()
+//	for stream.Next() {
+//		account := new(Account)
+//		if err := stream.Scan(account); err != nil {
+//			return err
+//		}
+//	}
+//	return stream.Err()
+type RowStream struct {
+	rows    *sql.Rows
+	scanner *sqlscan.RowScanner
+	span    trace.Span
+}
+
+// SelectStream streams the rows defined by the query.
+// The query timeout does not apply since the stream duration depends on its consumer.
+func (p *postgres) SelectStream(ctx context.Context, query string, args ...any) (*RowStream, error) {
+	// Create a span ending when the stream is closed
+	tracer := otel.GetTracerProvider()
+	spanCtx, span := tracer.Tracer(instrumentationName).Start(ctx, "SelectStream")
+
+	rows, err := p.dbConnection.QueryContext(spanCtx, query, args...)
+	if err != nil {
+		span.End()
+		return nil, err
+	}
+
+	return &RowStream{
+		rows:    rows,
+		scanner: sqlscan.NewRowScanner(rows),
+		span:    span,
+	}, nil
+}
+
+// Next prepares the next row to be scanned. It returns false when there are no more rows
+// or an error occurred, see Err. The stream is closed once Next returns false.
+func (s *RowStream) Next() bool {
+	return s.rows.Next()
+}
+
+// Scan scans the current row into dst, a pointer to a struct, a map or a single value
+func (s *RowStream) Scan(dst any) error {
+	return s.scanner.Scan(dst)
+}
+
+// Err returns the error that stopped the stream, if any
+func (s *RowStream) Err() error {
+	return s.rows.Err()
+}
+
+// Close closes the stream. It is safe to call Close several times.
+func (s *RowStream) Close() error {
+	err := s.rows.Close()
+	s.span.End()
+	return err
+}
+
+// Stream fetches the rows defined by the query one at a time and scans them into values of type T.
+// The iteration stops at the first error, which is yielded along with the zero value of T.
+// Breaking out of the loop closes the underlying stream.
+//
+//	for account, err := range postgres.Stream[Account](ctx, db, "SELECT account_id, account_name FROM accounts") {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+func Stream[T any](ctx context.Context, db Postgres, query string, args ...any) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		stream, err := db.SelectStream(ctx, query, args...)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		defer stream.Close()
+
+		for stream.Next() {
+			var value T
+			if err := stream.Scan(&value); err != nil {
+				yield(zero, err)
+				return
+			}
+
+			if !yield(value, nil) {
+				return
+			}
+		}
+
+		if err := stream.Err(); err != nil {
+			yield(zero, err)
+		}
+	}
+}
@@ -52,11 +52,19 @@ type TestContainer struct {
 	dbUser string
 	dbName string
 	dbPass string
+
+	// shared is true for the containers returned by SharedTestContainer
+	shared bool
 }
 
 // NewTestContainer create a Postgres test container useful for unit and integration tests
 // This function will exit when there is an error.Call this function inside your SetupTest to create the container before each test.
 func NewTestContainer(dbName, dbUser, dbPassword string) *TestContainer {
+	return startTestContainer("postgres", "11", dbName, dbUser, dbPassword, 120)
+}
+
+// startTestContainer starts a Postgres docker container hard killed after the given number of seconds
+func startTestContainer(repository, tag, dbName, dbUser, dbPassword string, expiry uint) *TestContainer {
 	// create the docker pool
 	pool, err := dockertest.NewPool("")
 	if err != nil {
@@ -64,8 +72,8 @@ func NewTestContainer(dbName, dbUser, dbPassword string) *TestContainer {
 	}
 	// pulls an image, creates a container based on it and runs it
 	resource, err := pool.RunWithOptions(&dockertest.RunOptions{
-		Repository: "postgres",
-		Tag:        "11",
+		Repository: repository,
+		Tag:        tag,
 		Env: []string{
 			fmt.Sprintf("POSTGRES_PASSWORD=%s", dbPassword),
 			fmt.Sprintf("POSTGRES_USER=%s", dbUser),
@@ -88,8 +96,8 @@ func NewTestContainer(dbName, dbUser, dbPassword string) *TestContainer {
 	hostAndPort := resource.GetHostPort("5432/tcp")
 	databaseURL := fmt.Sprintf("postgres://%s:%s@%s/%s?sslmode=disable", dbUser, dbPassword, hostAndPort, dbName)
 	log.Println("Connecting to database on url: ", databaseURL)
-	// Tell docker to hard kill the container in 120 seconds
-	_ = resource.Expire(120)
+	// Tell docker to hard kill the container
+	_ = resource.Expire(expiry)
 	// exponential backoff-retry, because the application in the container might not be ready to accept connections yet
 	pool.MaxWait = 120 * time.Second
 	if err = pool.Retry(func() error {
@@ -150,8 +158,13 @@ func (c TestContainer) Schema() string {
 }
 
 // Cleanup frees the resource by removing a container and linked volumes from docker.
-// Call this function inside your TearDownSuite to clean-up resources after each test
+// Call this function inside your TearDownSuite to clean-up resources after each test.
+// It does nothing for a shared test container, see PurgeSharedTestContainers.
 func (c TestContainer) Cleanup() {
+	if c.shared {
+		return
+	}
+
 	if err := c.pool.Purge(c.resource); err != nil {
 		log.Fatalf("Could not purge resource: %s", err)
 	}
@@ -0,0 +1,209 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package postgres
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// fixtureOrder matches the optional ordering prefix of the fixture files names, e.g. 01_accounts.csv
+var fixtureOrder = regexp.MustCompile(`^\d+_`)
+
+// Snapshot holds the rows of a table keyed by column name
+type Snapshot []map[string]any
+
+// TableDiff holds the rows added and removed from a table since its snapshot.
+// An updated row is reported as removed with its former values and added with its new ones.
+type TableDiff struct {
+	Added   Snapshot
+	Removed Snapshot
+}
+
+// Empty returns true when the table did not change since its snapshot
+func (d *TableDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0
+}
+
+// LoadFixtures loads the fixture files of the root directory of fsys in the lexical order of their names.
+// The .sql files are executed as they are and the .csv files are copied into the table named after the file
+// without its optional ordering prefix, e.g. 01_accounts.csv is copied into the accounts table.
+// The first line of a CSV file holds the columns names and its empty fields are loaded as NULL.
+// Other files are ignored.
+func (c TestDB) LoadFixtures(ctx context.Context, fsys fs.FS) error {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return errors.Wrap(err, "failed to read the fixtures directory")
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() {
+			continue
+		}
+
+		switch path.Ext(name) {
+		case ".sql":
+			content, err := fs.ReadFile(fsys, name)
+			if err != nil {
+				return errors.Wrapf(err, "failed to read the fixture (%s)", name)
+			}
+
+			if _, err := c.Exec(ctx, string(content)); err != nil {
+				return errors.Wrapf(err, "failed to load the fixture (%s)", name)
+			}
+		case ".csv":
+			if err := c.loadCSV(ctx, fsys, name); err != nil {
+				return errors.Wrapf(err, "failed to load the fixture (%s)", name)
+			}
+		}
+	}
+	return nil
+}
+
+// loadCSV copies the rows of a CSV fixture into its table
+func (c TestDB) loadCSV(ctx context.Context, fsys fs.FS, name string) error {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	columns, err := reader.Read()
+	if err != nil {
+		return errors.Wrap(err, "failed to read the columns")
+	}
+
+	var rows [][]any
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		row := make([]any, len(record))
+		for i, value := range record {
+			if value != "" {
+				row[i] = value
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	table := fixtureOrder.ReplaceAllString(strings.TrimSuffix(name, ".csv"), "")
+	_, err = CopyFrom(ctx, c, table, columns, rows)
+	return err
+}
+
+// TruncateTables empties the given tables and restarts their identity columns sequences
+func (c TestDB) TruncateTables(ctx context.Context, tableNames ...string) error {
+	if len(tableNames) == 0 {
+		return nil
+	}
+
+	truncateSQL := fmt.Sprintf("TRUNCATE TABLE %s RESTART IDENTITY CASCADE;", strings.Join(tableNames, ", "))
+	_, err := c.Exec(ctx, truncateSQL)
+	return err
+}
+
+// SnapshotTable returns the rows of the given table.
+// The text values are returned as string whatever the driver.
+func (c TestDB) SnapshotTable(ctx context.Context, tableName string) (Snapshot, error) {
+	var rows []map[string]any
+	if err := c.SelectAll(ctx, &rows, fmt.Sprintf("SELECT * FROM %s", tableName)); err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		for column, value := range row {
+			if bytes, ok := value.([]byte); ok {
+				row[column] = string(bytes)
+			}
+		}
+	}
+	return rows, nil
+}
+
+// DiffTable returns the rows added and removed from the given table since its snapshot
+func (c TestDB) DiffTable(ctx context.Context, tableName string, snapshot Snapshot) (*TableDiff, error) {
+	current, err := c.SnapshotTable(ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	// count the snapshot rows since a table can hold identical rows
+	before := make(map[string]int, len(snapshot))
+	for _, row := range snapshot {
+		before[rowKey(row)]++
+	}
+
+	diff := new(TableDiff)
+	for _, row := range current {
+		key := rowKey(row)
+		if before[key] > 0 {
+			before[key]--
+			continue
+		}
+		diff.Added = append(diff.Added, row)
+	}
+
+	for _, row := range snapshot {
+		key := rowKey(row)
+		if before[key] > 0 {
+			before[key]--
+			diff.Removed = append(diff.Removed, row)
+		}
+	}
+	return diff, nil
+}
+
+// rowKey returns a key identifying the values of a row
+func rowKey(row map[string]any) string {
+	columns := make([]string, 0, len(row))
+	for column := range row {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	var builder strings.Builder
+	for _, column := range columns {
+		fmt.Fprintf(&builder, "%q=%#v;", column, row[column])
+	}
+	return builder.String()
+}
@@ -0,0 +1,204 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package postgres
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"testing"
+)
+
+const (
+	// TestDSNEnv is the environment variable holding the URL of an externally provided Postgres server
+	// used by SharedTestContainer instead of starting a docker container, e.g. a CI service container.
+	TestDSNEnv = "POSTGRES_TEST_DSN"
+	// sharedContainerExpiry is the number of seconds after which docker hard kills a shared container
+	sharedContainerExpiry = 1800
+)
+
+var (
+	sharedLock       sync.Mutex
+	sharedContainers = make(map[string]*TestContainer)
+)
+
+// sharedConfig holds the SharedTestContainer settings
+type sharedConfig struct {
+	repository string
+	tag        string
+	dsn        string
+}
+
+// SharedOption is the interface that applies a SharedTestContainer configuration option.
+type SharedOption interface {
+	// Apply sets the SharedOption value of a sharedConfig.
+	Apply(*sharedConfig)
+}
+
+var _ SharedOption = SharedOptionFunc(nil)
+
+// SharedOptionFunc implements the SharedOption interface.
+type SharedOptionFunc func(*sharedConfig)
+
+// Apply applies the option
+func (f SharedOptionFunc) Apply(c *sharedConfig) {
+	f(c)
+}
+
+// WithImage sets the docker image of the shared container. It defaults to postgres:11.
+func WithImage(repository, tag string) SharedOption {
+	return SharedOptionFunc(func(c *sharedConfig) {
+		c.repository = repository
+		c.tag = tag
+	})
+}
+
+// WithExternalDSN uses the Postgres server of the given URL instead of starting a docker container.
+// The URL user must be allowed to create databases. It defaults to the TestDSNEnv environment variable.
+func WithExternalDSN(dsn string) SharedOption {
+	return SharedOptionFunc(func(c *sharedConfig) {
+		c.dsn = dsn
+	})
+}
+
+// SharedTestContainer returns the test container shared by all the suites of the test binary.
+// A single container is started per docker image, or none when an external server is provided.
+// Use NewTestDatabase to isolate the tests in their own database.
+// Call PurgeSharedTestContainers in TestMain to remove the containers once all the tests have run,
+// otherwise docker kills them after 30 minutes.
+// This function will exit when there is an error.
+func SharedTestContainer(opts ...SharedOption) *TestContainer {
+	cfg := &sharedConfig{
+		repository: "postgres",
+		tag:        "11",
+		dsn:        os.Getenv(TestDSNEnv),
+	}
+	for _, opt := range opts {
+		opt.Apply(cfg)
+	}
+
+	key := cfg.repository + ":" + cfg.tag
+	if cfg.dsn != "" {
+		key = cfg.dsn
+	}
+
+	sharedLock.Lock()
+	defer sharedLock.Unlock()
+	if container, ok := sharedContainers[key]; ok {
+		return container
+	}
+
+	var container *TestContainer
+	if cfg.dsn != "" {
+		config, err := ParseURL(cfg.dsn)
+		if err != nil {
+			log.Fatalf("Invalid external database url: %s", err)
+		}
+		container = &TestContainer{
+			host:   config.DBHost,
+			port:   config.DBPort,
+			schema: "public",
+			dbUser: config.DBUser,
+			dbName: config.DBName,
+			dbPass: config.DBPassword,
+		}
+	} else {
+		container = startTestContainer(cfg.repository, cfg.tag, "testdb", "test", "test", sharedContainerExpiry)
+	}
+
+	container.shared = true
+	sharedContainers[key] = container
+	return container
+}
+
+// PurgeSharedTestContainers removes the docker containers started by SharedTestContainer
+func PurgeSharedTestContainers() {
+	sharedLock.Lock()
+	defer sharedLock.Unlock()
+	for key, container := range sharedContainers {
+		if container.resource != nil {
+			if err := container.pool.Purge(container.resource); err != nil {
+				log.Printf("Could not purge resource: %s", err)
+			}
+		}
+		delete(sharedContainers, key)
+	}
+}
+
+// NewTestDatabase creates a database dedicated to the calling test and returns a TestDB connecting to it.
+// The database is dropped when the test and its subtests complete.
+func (c TestContainer) NewTestDatabase(t testing.TB) *TestDB {
+	t.Helper()
+	ctx := context.Background()
+
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		t.Fatalf("failed to generate the test database name: %v", err)
+	}
+	dbName := "test_" + hex.EncodeToString(suffix)
+
+	admin := c.GetTestDB()
+	if err := admin.Connect(ctx); err != nil {
+		t.Fatalf("failed to connect to the test server: %v", err)
+	}
+
+	if _, err := admin.Exec(ctx, fmt.Sprintf("CREATE DATABASE %s", dbName)); err != nil {
+		_ = admin.Disconnect(ctx)
+		t.Fatalf("failed to create the test database: %v", err)
+	}
+
+	db := &TestDB{
+		New(&Config{
+			DBUser:     c.dbUser,
+			DBName:     dbName,
+			DBPassword: c.dbPass,
+			DBSchema:   c.schema,
+			DBHost:     c.host,
+			DBPort:     c.port,
+		}),
+	}
+
+	t.Cleanup(func() {
+		_ = db.Disconnect(ctx)
+		defer func() {
+			_ = admin.Disconnect(ctx)
+		}()
+
+		// close the connections left open before dropping the database
+		const terminateSQL = `SELECT pg_terminate_backend(pid) FROM pg_stat_activity WHERE datname = $1 AND pid <> pg_backend_pid();`
+		if _, err := admin.Exec(ctx, terminateSQL, dbName); err != nil {
+			t.Errorf("failed to close the test database connections: %v", err)
+		}
+
+		if _, err := admin.Exec(ctx, fmt.Sprintf("DROP DATABASE IF EXISTS %s", dbName)); err != nil {
+			t.Errorf("failed to drop the test database: %v", err)
+		}
+	})
+	return db
+}
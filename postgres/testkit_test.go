@@ -27,6 +27,7 @@ package postgres
 import (
 	"context"
 	"testing"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/suite"
 )
@@ -213,3 +214,60 @@ func (s *testkitSuite) TestCount() {
 	err = db.Disconnect(ctx)
 	s.Assert().NoError(err)
 }
+
+func (s *testkitSuite) TestFixtures() {
+	ctx := context.TODO()
+	db := s.container.GetTestDB()
+	s.Require().NoError(db.Connect(ctx))
+	defer func() {
+		s.Assert().NoError(db.DropTable(ctx, "fruits"))
+		s.Assert().NoError(db.Disconnect(ctx))
+	}()
+
+	fixtures := fstest.MapFS{
+		"00_schema.sql": {Data: []byte(`
+			CREATE TABLE IF NOT EXISTS fruits (id SERIAL PRIMARY KEY, name VARCHAR(10) NOT NULL, taste VARCHAR(10));
+			INSERT INTO fruits (name, taste) VALUES ('mango', 'sweet');`)},
+		"01_fruits.csv": {Data: []byte("name,taste\nlemon,sour\nbanana,\n")},
+		"README.md":     {Data: []byte("fixtures")},
+	}
+	s.Require().NoError(db.LoadFixtures(ctx, fixtures))
+
+	snapshot, err := db.SnapshotTable(ctx, "fruits")
+	s.Require().NoError(err)
+	s.Assert().ElementsMatch(Snapshot{
+		{"id": int64(1), "name": "mango", "taste": "sweet"},
+		{"id": int64(2), "name": "lemon", "taste": "sour"},
+		{"id": int64(3), "name": "banana", "taste": nil},
+	}, snapshot)
+
+	_, err = db.Exec(ctx, `UPDATE fruits SET taste = 'sweet' WHERE name = 'banana'`)
+	s.Require().NoError(err)
+	_, err = db.Exec(ctx, `INSERT INTO fruits (name) VALUES ('kiwi')`)
+	s.Require().NoError(err)
+
+	diff, err := db.DiffTable(ctx, "fruits", snapshot)
+	s.Require().NoError(err)
+	s.Assert().False(diff.Empty())
+	s.Assert().ElementsMatch(Snapshot{
+		{"id": int64(3), "name": "banana", "taste": "sweet"},
+		{"id": int64(4), "name": "kiwi", "taste": nil},
+	}, diff.Added)
+	s.Assert().Equal(Snapshot{{"id": int64(3), "name": "banana", "taste": nil}}, diff.Removed)
+
+	// the identity sequence restarts
+	s.Require().NoError(db.TruncateTables(ctx, "fruits"))
+	count, err := db.Count(ctx, "fruits")
+	s.Require().NoError(err)
+	s.Assert().Zero(count)
+
+	_, err = db.Exec(ctx, `INSERT INTO fruits (name) VALUES ('kiwi')`)
+	s.Require().NoError(err)
+	snapshot, err = db.SnapshotTable(ctx, "fruits")
+	s.Require().NoError(err)
+	s.Assert().Equal(Snapshot{{"id": int64(1), "name": "kiwi", "taste": nil}}, snapshot)
+
+	diff, err = db.DiffTable(ctx, "fruits", snapshot)
+	s.Require().NoError(err)
+	s.Assert().True(diff.Empty())
+}
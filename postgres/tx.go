@@ -0,0 +1,252 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/georgysavva/scany/v2/sqlscan"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+)
+
+const (
+	// serializationFailure is the SQLSTATE of a serialization failure
+	serializationFailure = "40001"
+	// deadlockDetected is the SQLSTATE of a deadlock
+	deadlockDetected = "40P01"
+)
+
+// Tx is a database transaction handed over to the WithinTx function.
+// The queries run through it are part of the transaction.
+type Tx interface {
+	// Select fetches a single row and scans it into the dst. When there is no record no errors is return.
+	Select(ctx context.Context, dst any, query string, args ...any) error
+	// SelectAll fetches a set of rows and scans them into the dst. It returns nil when there is no records to fetch.
+	SelectAll(ctx context.Context, dst any, query string, args ...any) error
+	// Exec executes an SQL statement and returns the appropriate result or an error.
+	Exec(ctx context.Context, query string, args ...any) (sql.Result, error)
+	// SelectNamed is like Select with a query using named parameters. See BindNamed.
+	SelectNamed(ctx context.Context, dst any, query string, arg any) error
+	// SelectAllNamed is like SelectAll with a query using named parameters. See BindNamed.
+	SelectAllNamed(ctx context.Context, dst any, query string, arg any) error
+	// ExecNamed is like Exec with a statement using named parameters. See BindNamed.
+	ExecNamed(ctx context.Context, query string, arg any) (sql.Result, error)
+	// SQLTx returns the underlying database transaction
+	SQLTx() *sql.Tx
+}
+
+// txConfig holds the WithinTx settings
+type txConfig struct {
+	maxRetries uint64
+	newBackOff func() backoff.BackOff
+}
+
+// newTxConfig creates the default config and applies the given options
+func newTxConfig(opts ...TxOption) *txConfig {
+	cfg := &txConfig{
+		newBackOff: func() backoff.BackOff {
+			bo := backoff.NewExponentialBackOff()
+			bo.InitialInterval = 50 * time.Millisecond
+			bo.MaxInterval = time.Second
+			return bo
+		},
+	}
+	for _, opt := range opts {
+		opt.Apply(cfg)
+	}
+	return cfg
+}
+
+// TxOption is the interface that applies a WithinTx configuration option.
+type TxOption interface {
+	// Apply sets the TxOption value of a txConfig.
+	Apply(*txConfig)
+}
+
+var _ TxOption = TxOptionFunc(nil)
+
+// TxOptionFunc implements the TxOption interface.
+type TxOptionFunc func(*txConfig)
+
+// Apply applies the option
+func (f TxOptionFunc) Apply(c *txConfig) {
+	f(c)
+}
+
+// WithTxRetry retries the whole transaction up to maxRetries times when it fails
+// with a serialization failure or a deadlock. By default, the transaction is not retried.
+func WithTxRetry(maxRetries uint64) TxOption {
+	return TxOptionFunc(func(c *txConfig) {
+		c.maxRetries = maxRetries
+	})
+}
+
+// WithTxBackOff sets the strategy computing the delay between the transaction retries.
+// newBackOff is called once per WithinTx call. The default strategy is an exponential backoff.
+func WithTxBackOff(newBackOff func() backoff.BackOff) TxOption {
+	return TxOptionFunc(func(c *txConfig) {
+		c.newBackOff = newBackOff
+	})
+}
+
+// IsRetryable returns true when the given error is a serialization failure
+// or a deadlock reported by the database. Such transactions can be safely retried.
+func IsRetryable(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return isRetryableCode(string(pqErr.Code))
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return isRetryableCode(pgErr.Code)
+	}
+	return false
+}
+
+// isRetryableCode checks the SQLSTATE code of a retryable error
+func isRetryableCode(code string) bool {
+	return code == serializationFailure || code == deadlockDetected
+}
+
+// WithinTx runs fn in a database transaction
+func (p *postgres) WithinTx(ctx context.Context, txOptions *sql.TxOptions, fn func(tx Tx) error, opts ...TxOption) error {
+	// Create a span
+	tracer := otel.GetTracerProvider()
+	spanCtx, span := tracer.Tracer(instrumentationName).Start(ctx, "WithinTx")
+	defer span.End()
+
+	cfg := newTxConfig(opts...)
+	if cfg.maxRetries == 0 {
+		return p.runTx(spanCtx, txOptions, fn)
+	}
+
+	operation := func() error {
+		err := p.runTx(spanCtx, txOptions, fn)
+		if err != nil && !IsRetryable(err) {
+			return backoff.Permanent(err)
+		}
+		return err
+	}
+
+	bo := backoff.WithMaxRetries(cfg.newBackOff(), cfg.maxRetries)
+	return backoff.Retry(operation, backoff.WithContext(bo, spanCtx))
+}
+
+// runTx runs fn in a single database transaction.
+// The transaction is rolled back when fn returns an error or panics.
+func (p *postgres) runTx(ctx context.Context, txOptions *sql.TxOptions, fn func(tx Tx) error) (err error) {
+	sqlTx, err := p.BeginTx(ctx, txOptions)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin the transaction")
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			_ = sqlTx.Rollback()
+			panic(r)
+		}
+	}()
+
+	if err := fn(&tx{sqlTx}); err != nil {
+		if rollbackErr := sqlTx.Rollback(); rollbackErr != nil {
+			return errors.Wrap(err, rollbackErr.Error())
+		}
+		return err
+	}
+
+	if err := sqlTx.Commit(); err != nil {
+		return errors.Wrap(err, "failed to commit the transaction")
+	}
+	return nil
+}
+
+// tx implements the Tx interface
+type tx struct {
+	tx *sql.Tx
+}
+
+var _ Tx = (*tx)(nil)
+
+// Select fetches only one row
+func (t *tx) Select(ctx context.Context, dst any, query string, args ...any) error {
+	err := sqlscan.Get(ctx, t.tx, dst, query, args...)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	return nil
+}
+
+// SelectAll fetches rows
+func (t *tx) SelectAll(ctx context.Context, dst any, query string, args ...any) error {
+	err := sqlscan.Select(ctx, t.tx, dst, query, args...)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	return nil
+}
+
+// Exec executes a sql query without returning rows
+func (t *tx) Exec(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return t.tx.ExecContext(ctx, query, args...)
+}
+
+// SelectNamed fetches only one row using a named query
+func (t *tx) SelectNamed(ctx context.Context, dst any, query string, arg any) error {
+	query, args, err := BindNamed(query, arg)
+	if err != nil {
+		return err
+	}
+	return t.Select(ctx, dst, query, args...)
+}
+
+// SelectAllNamed fetches rows using a named query
+func (t *tx) SelectAllNamed(ctx context.Context, dst any, query string, arg any) error {
+	query, args, err := BindNamed(query, arg)
+	if err != nil {
+		return err
+	}
+	return t.SelectAll(ctx, dst, query, args...)
+}
+
+// ExecNamed executes a named sql query without returning rows
+func (t *tx) ExecNamed(ctx context.Context, query string, arg any) (sql.Result, error) {
+	query, args, err := BindNamed(query, arg)
+	if err != nil {
+		return nil, err
+	}
+	return t.Exec(ctx, query, args...)
+}
+
+// SQLTx returns the underlying database transaction
+func (t *tx) SQLTx() *sql.Tx {
+	return t.tx
+}
@@ -27,7 +27,10 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"reflect"
 
+	"github.com/georgysavva/scany/v2/sqlscan"
+	"github.com/lib/pq"
 	"github.com/pkg/errors"
 )
 
@@ -47,6 +50,10 @@ type TxRunner struct {
 	builders []QueryBuilder
 
 	ctx context.Context
+
+	// savepoint and err are only set for the nested runners
+	savepoint string
+	err       error
 }
 
 // NewTxRunner creates an instance of TxRunner
@@ -76,48 +83,164 @@ func (r *TxRunner) AddQueryBuilder(v QueryBuilder) *TxRunner {
 	return r
 }
 
+// AddQueryBuilderWithScan adds a QueryBuilder whose statement returns rows, e.g. with a RETURNING clause.
+// The returned rows are scanned into dst during the transaction. dst is a pointer to a slice to scan all
+// the returned rows or a pointer to a struct or a value to scan the first one. dst is left untouched
+// when no row is returned.
+func (r *TxRunner) AddQueryBuilderWithScan(v QueryBuilder, dst any) *TxRunner {
+	return r.AddQueryBuilder(&scanBuilder{QueryBuilder: v, dst: dst})
+}
+
+// Savepoint adds the creation of a savepoint with the given name at this position of the transaction
+func (r *TxRunner) Savepoint(name string) *TxRunner {
+	return r.AddQueryBuilder(statement("SAVEPOINT " + pq.QuoteIdentifier(name)))
+}
+
+// RollbackTo adds the rollback to the savepoint with the given name at this position of the transaction.
+// The queries executed after the savepoint creation are discarded while the transaction carries on.
+func (r *TxRunner) RollbackTo(name string) *TxRunner {
+	return r.AddQueryBuilder(statement("ROLLBACK TO SAVEPOINT " + pq.QuoteIdentifier(name)))
+}
+
+// Nest creates a nested TxRunner whose builders are executed at this position of the transaction
+// within a savepoint with the given name. When one of the nested builders fails, the transaction
+// is rolled back to the savepoint only and the execution carries on with the next builders.
+// The nested failure is then returned by the nested runner Err. Nested runners can be nested as well.
+// The nested runner is executed by its parent and cannot be executed on its own.
+func (r *TxRunner) Nest(name string) *TxRunner {
+	nested := &TxRunner{
+		tx:        r.tx,
+		ctx:       r.ctx,
+		builders:  make([]QueryBuilder, 0),
+		savepoint: name,
+	}
+	r.builders = append(r.builders, &nestedRunner{nested})
+	return nested
+}
+
+// Err returns the failure of a nested runner rolled back to its savepoint.
+// It returns nil when the nested runner succeeded or has not been executed yet.
+func (r *TxRunner) Err() error {
+	return r.err
+}
+
 // Execute executes the database queries returns resulting error(s).
 // In case of errors the underlying database transaction is rolled back.
 // When there is no errors the underlying database transaction is committed
 func (r *TxRunner) Execute() error {
-	// create a type to hold the query and arguments
-	type queryArgs struct {
-		statement string
-		args      []any
+	if r.savepoint != "" {
+		return errors.New("a nested runner is executed by its parent")
 	}
 
-	// let us build the query and args
-	queries := make([]queryArgs, 0, len(r.builders))
+	if err := r.execute(); err != nil {
+		// rollback the transaction
+		if rollbackErr := r.tx.Rollback(); rollbackErr != nil {
+			return errors.Wrap(err, rollbackErr.Error())
+		}
+		return err
+	}
+
+	// commit the database transaction
+	return r.tx.Commit()
+}
+
+// execute builds the queries and executes them in the order the builders have been added.
+// The nested runners queries are built when they are executed.
+func (r *TxRunner) execute() error {
+	// let us build the queries
+	queries := make([]func() error, 0, len(r.builders))
 	for _, builder := range r.builders {
+		if nested, ok := builder.(*nestedRunner); ok {
+			queries = append(queries, nested.executeNested)
+			continue
+		}
+
 		// build the query
 		query, args, err := builder.BuildQuery()
 		if err != nil {
-			// rollback the transaction
-			if rollbackErr := r.tx.Rollback(); rollbackErr != nil {
-				return errors.Wrap(err, rollbackErr.Error())
-			}
-
 			return err
 		}
 
-		// add to the queries
-		queries = append(queries, queryArgs{
-			statement: query,
-			args:      args,
+		if scan, ok := builder.(*scanBuilder); ok {
+			queries = append(queries, func() error {
+				return scan.scan(r.ctx, r.tx, query, args)
+			})
+			continue
+		}
+
+		queries = append(queries, func() error {
+			_, err := r.tx.ExecContext(r.ctx, query, args...)
+			return err
 		})
 	}
 
 	for _, query := range queries {
-		if _, execErr := r.tx.ExecContext(r.ctx, query.statement, query.args...); execErr != nil {
-			// rollback the transaction
-			if rollbackErr := r.tx.Rollback(); rollbackErr != nil {
-				return errors.Wrap(execErr, rollbackErr.Error())
-			}
-
-			return execErr
+		if err := query(); err != nil {
+			return err
 		}
 	}
+	return nil
+}
 
-	// commit the database transaction
-	return r.tx.Commit()
+// executeNested executes the nested runner within its savepoint.
+// It only returns an error when the savepoint handling fails.
+func (r *TxRunner) executeNested() error {
+	savepoint := pq.QuoteIdentifier(r.savepoint)
+	if _, err := r.tx.ExecContext(r.ctx, "SAVEPOINT "+savepoint); err != nil {
+		return err
+	}
+
+	if err := r.execute(); err != nil {
+		r.err = err
+		_, rollbackErr := r.tx.ExecContext(r.ctx, "ROLLBACK TO SAVEPOINT "+savepoint)
+		return rollbackErr
+	}
+
+	r.err = nil
+	_, err := r.tx.ExecContext(r.ctx, "RELEASE SAVEPOINT "+savepoint)
+	return err
+}
+
+// statement is a QueryBuilder of a statement without arguments
+type statement string
+
+// BuildQuery returns the statement
+func (s statement) BuildQuery() (string, []any, error) {
+	return string(s), nil, nil
+}
+
+// scanBuilder is a QueryBuilder whose returned rows are scanned into dst
+type scanBuilder struct {
+	QueryBuilder
+	dst any
+}
+
+// scan executes the query and scans the returned rows into dst
+func (s *scanBuilder) scan(ctx context.Context, tx *sql.Tx, query string, args []any) error {
+	value := reflect.ValueOf(s.dst)
+	if value.Kind() != reflect.Pointer || value.IsNil() {
+		return errors.Errorf("scan destination must be a non-nil pointer, got %T", s.dst)
+	}
+
+	var err error
+	if value.Elem().Kind() == reflect.Slice && value.Elem().Type().Elem().Kind() != reflect.Uint8 {
+		err = sqlscan.Select(ctx, tx, s.dst, query, args...)
+	} else {
+		err = sqlscan.Get(ctx, tx, s.dst, query, args...)
+	}
+
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	return nil
+}
+
+// nestedRunner adds a nested TxRunner to the builders of its parent
+type nestedRunner struct {
+	*TxRunner
+}
+
+// BuildQuery is not used since the nested runner builds its own queries when executed
+func (n *nestedRunner) BuildQuery() (string, []any, error) {
+	return "", nil, errors.New("a nested runner builds its own queries")
 }
@@ -27,8 +27,10 @@ package postgres
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -173,6 +175,157 @@ func (s *txRunnerSuite) TestExecute() {
 	})
 }
 
+func (s *txRunnerSuite) TestSavepoints() {
+	ctx := context.TODO()
+	db := s.container.GetTestDB()
+	s.Require().NoError(db.Connect(ctx))
+	defer func() {
+		s.Assert().NoError(db.DropTable(ctx, "mangoes"))
+		s.Assert().NoError(db.DropTable(ctx, "cars"))
+		s.Assert().NoError(db.Disconnect(ctx))
+	}()
+
+	reset := func() {
+		s.Require().NoError(db.DropTable(ctx, "mangoes"))
+		s.Require().NoError(db.DropTable(ctx, "cars"))
+		_, err := db.Exec(ctx, `create table mangoes(id integer, taste varchar(10));`)
+		s.Require().NoError(err)
+		_, err = db.Exec(ctx, `create table cars(id integer, color varchar(10));`)
+		s.Require().NoError(err)
+	}
+
+	s.Run("with rollback to savepoint", func() {
+		reset()
+		txRunner, err := NewTxRunner(ctx, db)
+		s.Require().NoError(err)
+
+		err = txRunner.
+			AddQueryBuilder(new(mangoesInsertBuilder)).
+			Savepoint("before_cars").
+			AddQueryBuilder(new(carsInsertBuilder)).
+			RollbackTo("before_cars").
+			Execute()
+		s.Require().NoError(err)
+
+		count, err := db.Count(ctx, "mangoes")
+		s.Require().NoError(err)
+		s.Assert().Equal(1, count)
+
+		count, err = db.Count(ctx, "cars")
+		s.Require().NoError(err)
+		s.Assert().Zero(count)
+	})
+
+	s.Run("with a failed nested runner", func() {
+		reset()
+		txRunner, err := NewTxRunner(ctx, db)
+		s.Require().NoError(err)
+
+		txRunner.AddQueryBuilder(new(mangoesInsertBuilder))
+		failed := txRunner.Nest("failed").
+			AddQueryBuilder(new(carsInsertBuilder)).
+			AddQueryBuilder(new(errorSQLBuilder))
+		succeeded := txRunner.Nest("succeeded").
+			AddQueryBuilder(new(carsInsertBuilder))
+		txRunner.AddQueryBuilder(new(mangoesInsertBuilder))
+
+		s.Require().NoError(txRunner.Execute())
+		s.Assert().EqualError(failed.Err(), `pq: syntax error at or near "table"`)
+		s.Assert().NoError(succeeded.Err())
+
+		// only the failed nested runner is rolled back
+		count, err := db.Count(ctx, "mangoes")
+		s.Require().NoError(err)
+		s.Assert().Equal(2, count)
+
+		count, err = db.Count(ctx, "cars")
+		s.Require().NoError(err)
+		s.Assert().Equal(1, count)
+	})
+
+	s.Run("with a nested runner builder error", func() {
+		reset()
+		txRunner, err := NewTxRunner(ctx, db)
+		s.Require().NoError(err)
+
+		nested := txRunner.Nest("nested")
+		nested.Nest("inner").AddQueryBuilder(new(carsInsertBuilder))
+		nested.AddQueryBuilder(new(failureBuilder))
+		txRunner.AddQueryBuilder(new(mangoesInsertBuilder))
+
+		s.Require().NoError(txRunner.Execute())
+		s.Assert().EqualError(nested.Err(), "failed to build query")
+
+		count, err := db.Count(ctx, "mangoes")
+		s.Require().NoError(err)
+		s.Assert().Equal(1, count)
+	})
+
+	s.Run("with a failure after the nested runner", func() {
+		reset()
+		txRunner, err := NewTxRunner(ctx, db)
+		s.Require().NoError(err)
+
+		nested := txRunner.Nest("nested").AddQueryBuilder(new(carsInsertBuilder))
+		txRunner.AddQueryBuilder(new(errorSQLBuilder))
+
+		// executing a nested runner on its own is not allowed
+		s.Assert().EqualError(nested.Execute(), "a nested runner is executed by its parent")
+		s.Assert().Error(txRunner.Execute())
+
+		// the whole transaction is rolled back
+		count, err := db.Count(ctx, "cars")
+		s.Require().NoError(err)
+		s.Assert().Zero(count)
+	})
+}
+
+func (s *txRunnerSuite) TestScan() {
+	ctx := context.TODO()
+	db := s.container.GetTestDB()
+	s.Require().NoError(db.Connect(ctx))
+	defer func() {
+		s.Assert().NoError(db.DropTable(ctx, "mangoes"))
+		s.Assert().NoError(db.Disconnect(ctx))
+	}()
+
+	_, err := db.Exec(ctx, `create table mangoes(id serial primary key, taste varchar(10), created_at timestamptz default now());`)
+	s.Require().NoError(err)
+
+	type mango struct {
+		ID        int
+		Taste     string
+		CreatedAt time.Time
+	}
+
+	inserted := new(mango)
+	var ids []int
+	var count int
+	txRunner, err := NewTxRunner(ctx, db)
+	s.Require().NoError(err)
+	err = txRunner.
+		AddQueryBuilderWithScan(NamedQuery(`insert into mangoes(taste) values(:taste) returning id, taste, created_at;`,
+			map[string]any{"taste": "succulent"}), inserted).
+		AddQueryBuilderWithScan(NamedQuery(`insert into mangoes(taste) values(:first), (:second) returning id;`,
+			map[string]any{"first": "sweet", "second": "sour"}), &ids).
+		AddQueryBuilderWithScan(NamedQuery(`select count(*) from mangoes;`, map[string]any{}), &count).
+		Execute()
+	s.Require().NoError(err)
+
+	s.Assert().Equal(1, inserted.ID)
+	s.Assert().Equal("succulent", inserted.Taste)
+	s.Assert().False(inserted.CreatedAt.IsZero())
+	s.Assert().Equal([]int{2, 3}, ids)
+	// the scanned rows include the ones inserted earlier in the same transaction
+	s.Assert().Equal(3, count)
+}
+
+func TestScanBuilder(t *testing.T) {
+	builder := &scanBuilder{QueryBuilder: statement("select 1;"), dst: account{}}
+	err := builder.scan(context.TODO(), nil, "select 1;", nil)
+	assert.EqualError(t, err, "scan destination must be a non-nil pointer, got postgres.account")
+}
+
 type mangoesInsertBuilder struct{}
 
 func (i *mangoesInsertBuilder) BuildQuery() (sqlStatement string, args []any, err error) {
@@ -0,0 +1,180 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestIsRetryable(t *testing.T) {
+	t.Run("with serialization failure", func(t *testing.T) {
+		assert.True(t, IsRetryable(&pq.Error{Code: "40001"}))
+		assert.True(t, IsRetryable(&pgconn.PgError{Code: "40001"}))
+	})
+	t.Run("with deadlock", func(t *testing.T) {
+		assert.True(t, IsRetryable(errors.Wrap(&pq.Error{Code: "40P01"}, "failed to commit the transaction")))
+		assert.True(t, IsRetryable(&pgconn.PgError{Code: "40P01"}))
+	})
+	t.Run("with other errors", func(t *testing.T) {
+		assert.False(t, IsRetryable(&pq.Error{Code: "23505"}))
+		assert.False(t, IsRetryable(errors.New("some error")))
+		assert.False(t, IsRetryable(nil))
+	})
+}
+
+type withinTxSuite struct {
+	suite.Suite
+	container *TestContainer
+}
+
+// SetupSuite starts the Postgres database engine and set the container
+// host and port to use in the tests
+func (s *withinTxSuite) SetupSuite() {
+	s.container = NewTestContainer("testdb", "test", "test")
+}
+
+func (s *withinTxSuite) TearDownSuite() {
+	s.container.Cleanup()
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestWithinTxSuite(t *testing.T) {
+	suite.Run(t, new(withinTxSuite))
+}
+
+func (s *withinTxSuite) TestWithinTx() {
+	ctx := context.TODO()
+	db := s.container.GetTestDB()
+	s.Require().NoError(db.Connect(ctx))
+	defer func() {
+		s.Assert().NoError(db.Disconnect(ctx))
+	}()
+
+	s.Run("with commit", func() {
+		s.Require().NoError(db.DropTable(ctx, "accounts"))
+		s.Require().NoError(createTable(ctx, db))
+
+		inserted := &account{AccountID: uuid.New().String(), AccountName: "some-account"}
+		err := db.WithinTx(ctx, nil, func(tx Tx) error {
+			if _, err := tx.ExecNamed(ctx, `INSERT INTO accounts(account_id, account_name) VALUES(:account_id, :account_name);`, inserted); err != nil {
+				return err
+			}
+			selected := new(account)
+			if err := tx.Select(ctx, selected, `SELECT account_id, account_name FROM accounts WHERE account_id = $1;`, inserted.AccountID); err != nil {
+				return err
+			}
+			s.Assert().Equal(inserted, selected)
+			return nil
+		})
+		s.Require().NoError(err)
+
+		count, err := db.Count(ctx, "accounts")
+		s.Require().NoError(err)
+		s.Assert().Equal(1, count)
+	})
+
+	s.Run("with rollback", func() {
+		s.Require().NoError(db.DropTable(ctx, "accounts"))
+		s.Require().NoError(createTable(ctx, db))
+
+		err := db.WithinTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable}, func(tx Tx) error {
+			if _, err := tx.Exec(ctx, `INSERT INTO accounts(account_id, account_name) VALUES($1, $2);`, uuid.New().String(), "some-account"); err != nil {
+				return err
+			}
+			return errors.New("some error")
+		})
+		s.Assert().EqualError(err, "some error")
+
+		count, err := db.Count(ctx, "accounts")
+		s.Require().NoError(err)
+		s.Assert().Zero(count)
+	})
+
+	s.Run("with rollback on panic", func() {
+		s.Require().NoError(db.DropTable(ctx, "accounts"))
+		s.Require().NoError(createTable(ctx, db))
+
+		s.Assert().Panics(func() {
+			_ = db.WithinTx(ctx, nil, func(tx Tx) error {
+				if _, err := tx.Exec(ctx, `INSERT INTO accounts(account_id, account_name) VALUES($1, $2);`, uuid.New().String(), "some-account"); err != nil {
+					return err
+				}
+				panic("boom")
+			})
+		})
+
+		count, err := db.Count(ctx, "accounts")
+		s.Require().NoError(err)
+		s.Assert().Zero(count)
+	})
+
+	s.Run("with retry on serialization failure", func() {
+		s.Require().NoError(db.DropTable(ctx, "accounts"))
+		s.Require().NoError(createTable(ctx, db))
+
+		attempts := 0
+		err := db.WithinTx(ctx, nil, func(tx Tx) error {
+			attempts++
+			if _, err := tx.Exec(ctx, `INSERT INTO accounts(account_id, account_name) VALUES($1, $2);`, uuid.New().String(), "some-account"); err != nil {
+				return err
+			}
+			if attempts < 3 {
+				return &pq.Error{Code: "40001"}
+			}
+			return nil
+		}, WithTxRetry(3), WithTxBackOff(func() backoff.BackOff {
+			return backoff.NewConstantBackOff(10 * time.Millisecond)
+		}))
+		s.Require().NoError(err)
+		s.Assert().Equal(3, attempts)
+
+		// only the last attempt is committed
+		count, err := db.Count(ctx, "accounts")
+		s.Require().NoError(err)
+		s.Assert().Equal(1, count)
+	})
+
+	s.Run("without retry on other errors", func() {
+		attempts := 0
+		err := db.WithinTx(ctx, nil, func(tx Tx) error {
+			attempts++
+			return errors.New("some error")
+		}, WithTxRetry(3))
+		s.Assert().EqualError(err, "some error")
+		s.Assert().Equal(1, attempts)
+	})
+}
@@ -0,0 +1,104 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package requestid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// XRequestIDHeader is the HTTP header carrying the request ID
+	XRequestIDHeader = "X-Request-ID"
+	// maxRequestIDLength is the maximum length of an incoming request ID
+	maxRequestIDLength = 128
+)
+
+// Middleware is an HTTP middleware that reads the request ID from the X-Request-ID header,
+// or generates one when it is missing or invalid, sets it into the request context
+// with XRequestIDKey and echoes it in the response X-Request-ID header.
+// It can be used with net/http as well as with routers like chi.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(XRequestIDHeader)
+		if !isValid(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		w.Header().Set(XRequestIDHeader, requestID)
+		ctx := context.WithValue(r.Context(), XRequestIDKey{}, requestID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// isValid checks whether the given request ID is not empty, not too long
+// and only made of printable ASCII characters
+func isValid(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(requestID); i++ {
+		if requestID[i] < 0x21 || requestID[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// Transport is an http.RoundTripper that injects the request ID of the request context
+// into the outgoing X-Request-ID header. A request ID already set in the header is preserved.
+type Transport struct {
+	// Base is the underlying RoundTripper. http.DefaultTransport is used when nil
+	Base http.RoundTripper
+}
+
+// enforce compilation error
+var _ http.RoundTripper = (*Transport)(nil)
+
+// NewTransport wraps the given RoundTripper with the request ID propagation.
+// http.DefaultTransport is used when base is nil.
+func NewTransport(base http.RoundTripper) *Transport {
+	return &Transport{Base: base}
+}
+
+// RoundTrip executes a single HTTP transaction with the request ID header set
+func (t *Transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	requestID := FromContext(r.Context())
+	if requestID == "" || r.Header.Get(XRequestIDHeader) != "" {
+		return base.RoundTrip(r)
+	}
+
+	// a RoundTripper must not modify the given request
+	r = r.Clone(r.Context())
+	r.Header.Set(XRequestIDHeader, requestID)
+	return base.RoundTrip(r)
+}
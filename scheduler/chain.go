@@ -0,0 +1,231 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package scheduler
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ErrDependencyCycle is returned when the dependencies of a job form a cycle
+var ErrDependencyCycle = errors.New("dependency cycle")
+
+// ChainStatus describes a job and the jobs depending on it, directly or not
+type ChainStatus struct {
+	// Jobs lists the job and its dependents in topological order: a job is listed after its upstream jobs
+	Jobs []JobInfo
+	// Status is RunStatusFailed when the last run of a job of the chain failed, RunStatusSucceeded when
+	// the last run of every job succeeded and RunStatusNone otherwise
+	Status RunStatus
+}
+
+// ScheduleAfter adds a job run each time all its upstream jobs have succeeded since its last run.
+// The upstream jobs can be added later on, but the dependencies must not form a cycle.
+// Only the scheduled runs of the upstream jobs trigger the job, the runs started with RunNow do not.
+func (s *JobsScheduler) ScheduleAfter(ctx context.Context, job Job, upstreams []string, opts ...JobOption) error {
+	if len(upstreams) == 0 {
+		return errors.New("the upstream jobs are required")
+	}
+
+	dependsOn := slices.Clone(upstreams)
+	slices.Sort(dependsOn)
+	dependsOn = slices.Compact(dependsOn)
+
+	jobID := job.ID()
+	s.mu.Lock()
+	err := s.checkDependencies(jobID, dependsOn)
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	entry := &scheduledJob{
+		job:       job,
+		trigger:   TriggerDependency,
+		dependsOn: dependsOn,
+		options:   newJobOptions(opts...),
+		history:   newRunHistory(s.historySize),
+		ctx:       ctx,
+		succeeded: make(map[string]bool),
+	}
+	if err := s.restore(ctx, entry); err != nil {
+		return err
+	}
+
+	// the jobs may have changed while the state was restored
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.checkDependencies(jobID, dependsOn); err != nil {
+		return err
+	}
+
+	s.jobs[jobID] = entry
+	return nil
+}
+
+// checkDependencies returns an error when the job is already added or when its dependencies form a cycle.
+// The caller must hold the lock.
+func (s *JobsScheduler) checkDependencies(jobID string, dependsOn []string) error {
+	if err := s.checkNotAdded(jobID); err != nil {
+		return err
+	}
+
+	if cycle := s.findCycle(jobID, dependsOn); cycle != nil {
+		return errors.Wrapf(ErrDependencyCycle, "job (%s): %s", jobID, strings.Join(cycle, " -> "))
+	}
+	return nil
+}
+
+// Chain returns the status of the job and of the jobs depending on it, directly or not
+func (s *JobsScheduler) Chain(ctx context.Context, jobID string) (*ChainStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	root, err := s.lookup(jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	// collect the jobs reachable from the root
+	chain := map[string]*scheduledJob{jobID: root}
+	queue := []string{jobID}
+	for len(queue) > 0 {
+		for _, dependent := range s.dependents(queue[0]) {
+			if _, ok := chain[dependent.job.ID()]; !ok {
+				chain[dependent.job.ID()] = dependent
+				queue = append(queue, dependent.job.ID())
+			}
+		}
+		queue = queue[1:]
+	}
+
+	// sort them topologically, breaking the ties by ID
+	pending := make(map[string]int, len(chain))
+	for id, entry := range chain {
+		for _, upstream := range entry.dependsOn {
+			if _, ok := chain[upstream]; ok && id != jobID {
+				pending[id]++
+			}
+		}
+	}
+
+	status := &ChainStatus{Status: RunStatusSucceeded}
+	ready := []string{jobID}
+	for len(ready) > 0 {
+		sort.Strings(ready)
+		id := ready[0]
+		ready = ready[1:]
+
+		info := chain[id].info(s.started)
+		status.Jobs = append(status.Jobs, info)
+		switch {
+		case info.LastStatus == RunStatusFailed:
+			status.Status = RunStatusFailed
+		case info.LastStatus == RunStatusNone && status.Status != RunStatusFailed:
+			status.Status = RunStatusNone
+		}
+
+		for _, dependent := range s.dependents(id) {
+			if pending[dependent.job.ID()]--; pending[dependent.job.ID()] == 0 {
+				ready = append(ready, dependent.job.ID())
+			}
+		}
+	}
+	return status, nil
+}
+
+// dependents returns the jobs depending directly on the given job sorted by ID. The caller must hold the lock.
+func (s *JobsScheduler) dependents(jobID string) []*scheduledJob {
+	var dependents []*scheduledJob
+	for _, entry := range s.jobs {
+		if slices.Contains(entry.dependsOn, jobID) {
+			dependents = append(dependents, entry)
+		}
+	}
+
+	sort.Slice(dependents, func(i, j int) bool {
+		return dependents[i].job.ID() < dependents[j].job.ID()
+	})
+	return dependents
+}
+
+// findCycle returns the path leading from the job back to itself through its upstream jobs,
+// or nil when the dependencies do not form a cycle. The caller must hold the lock.
+func (s *JobsScheduler) findCycle(jobID string, upstreams []string) []string {
+	visited := make(map[string]bool)
+	var visit func(id string, path []string) []string
+	visit = func(id string, path []string) []string {
+		path = append(path, id)
+		if id == jobID {
+			return path
+		}
+
+		if visited[id] {
+			return nil
+		}
+		visited[id] = true
+
+		if entry, ok := s.jobs[id]; ok {
+			for _, upstream := range entry.dependsOn {
+				if cycle := visit(upstream, path); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		return nil
+	}
+
+	for _, upstream := range upstreams {
+		if cycle := visit(upstream, []string{jobID}); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// notifyDependents records the successful run of the job and triggers the dependents
+// whose upstream jobs have all succeeded
+func (s *JobsScheduler) notifyDependents(entry *scheduledJob) {
+	jobID := entry.job.ID()
+
+	s.mu.Lock()
+	var ready []*scheduledJob
+	for _, dependent := range s.dependents(jobID) {
+		dependent.succeeded[jobID] = true
+		if len(dependent.succeeded) == len(dependent.dependsOn) {
+			dependent.succeeded = make(map[string]bool)
+			ready = append(ready, dependent)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, dependent := range ready {
+		go s.runJob(dependent.ctx, dependent)
+	}
+}
@@ -0,0 +1,80 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package scheduler
+
+import "time"
+
+// DefaultHistorySize is the default number of runs kept in the history of a job
+const DefaultHistorySize = 10
+
+// JobRun describes a run of a job
+type JobRun struct {
+	// StartedAt is the start time of the run
+	StartedAt time.Time
+	// Duration is the duration of the run, retries included
+	Duration time.Duration
+	// Attempts is the number of times the job was run, the first attempt included
+	Attempts int
+	// Status is the outcome of the run
+	Status RunStatus
+	// Error is the error of the last attempt when the run failed
+	Error error
+}
+
+// runHistory is a ring buffer holding the most recent runs of a job
+type runHistory struct {
+	runs []JobRun
+	next int
+	full bool
+}
+
+// newRunHistory creates a runHistory holding at most size runs
+func newRunHistory(size int) *runHistory {
+	return &runHistory{runs: make([]JobRun, max(size, 0))}
+}
+
+// add records the given run, overwriting the oldest one when the buffer is full
+func (h *runHistory) add(run JobRun) {
+	if len(h.runs) == 0 {
+		return
+	}
+
+	h.runs[h.next] = run
+	h.next = (h.next + 1) % len(h.runs)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// list returns a copy of the recorded runs from the oldest to the most recent
+func (h *runHistory) list() []JobRun {
+	if !h.full {
+		return append([]JobRun(nil), h.runs[:h.next]...)
+	}
+
+	runs := make([]JobRun, 0, len(h.runs))
+	runs = append(runs, h.runs[h.next:]...)
+	return append(runs, h.runs[:h.next]...)
+}
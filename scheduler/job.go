@@ -0,0 +1,312 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-co-op/gocron"
+	"github.com/robfig/cron/v3"
+
+	"github.com/tochemey/gopack/clock"
+)
+
+// RunStatus is the outcome of the last run of a job
+type RunStatus int
+
+const (
+	// RunStatusNone means the job has not run yet
+	RunStatusNone RunStatus = iota
+	// RunStatusSucceeded means the last run of the job succeeded
+	RunStatusSucceeded
+	// RunStatusFailed means the last run of the job failed
+	RunStatusFailed
+)
+
+// String returns the text representation of the status
+func (s RunStatus) String() string {
+	switch s {
+	case RunStatusSucceeded:
+		return "succeeded"
+	case RunStatusFailed:
+		return "failed"
+	default:
+		return "none"
+	}
+}
+
+// Trigger defines when a job runs
+type Trigger int
+
+const (
+	// TriggerCron runs the job according to a cron expression. See JobsScheduler.AddJob
+	TriggerCron Trigger = iota
+	// TriggerOnce runs the job once at a given time. See JobsScheduler.ScheduleOnce
+	TriggerOnce
+	// TriggerFixedDelay runs the job repeatedly with a fixed delay between the end of a run
+	// and the start of the next one. See JobsScheduler.ScheduleWithFixedDelay
+	TriggerFixedDelay
+	// TriggerDependency runs the job once all its upstream jobs have succeeded. See JobsScheduler.ScheduleAfter
+	TriggerDependency
+)
+
+// String returns the text representation of the trigger
+func (t Trigger) String() string {
+	switch t {
+	case TriggerOnce:
+		return "once"
+	case TriggerFixedDelay:
+		return "fixed-delay"
+	case TriggerDependency:
+		return "dependency"
+	default:
+		return "cron"
+	}
+}
+
+// JobInfo describes a scheduled job
+type JobInfo struct {
+	// ID is the job unique identifier
+	ID string
+	// Trigger defines when the job runs
+	Trigger Trigger
+	// CronExpression is the schedule of the TriggerCron jobs
+	CronExpression string
+	// Location is the timezone evaluating the cron expression of the TriggerCron jobs
+	Location *time.Location
+	// Delay is the delay between the runs of the TriggerFixedDelay jobs
+	Delay time.Duration
+	// DependsOn lists the upstream jobs of the TriggerDependency jobs
+	DependsOn []string
+	// NextRun is the time of the next run. It is zero when the scheduler is not started, the job is paused
+	// or the TriggerOnce job has already run.
+	NextRun time.Time
+	// LastRun is the start time of the last run. It is zero when the job has not run yet.
+	LastRun time.Time
+	// LastStatus is the outcome of the last run
+	LastStatus RunStatus
+	// LastError is the error of the last run when it failed
+	LastError error
+	// Paused is true when the job runs are skipped
+	Paused bool
+}
+
+// scheduledJob holds a job added to the scheduler along with its state
+type scheduledJob struct {
+	job            Job
+	trigger        Trigger
+	cronExpression string
+	location       *time.Location
+	schedule       cron.Schedule
+	delay          time.Duration
+	dependsOn      []string
+	options        *JobOptions
+	gocronJob      *gocron.Job
+
+	// ctx is the context given when scheduling the job
+	ctx context.Context
+	// catchUp is true when a run missed while the service was down is due. It is guarded by the scheduler lock.
+	catchUp bool
+	// succeeded holds the upstream jobs of a TriggerDependency job that have succeeded since its last run.
+	// It is guarded by the scheduler lock.
+	succeeded map[string]bool
+	// nextFire, timer, generation and done are only used by the TriggerOnce and TriggerFixedDelay jobs
+	// and are guarded by the scheduler lock. The generation discards the timers stopped too late.
+	nextFire   time.Time
+	timer      clock.Timer
+	generation int
+	done       bool
+
+	mu         sync.Mutex
+	paused     bool
+	lastRun    time.Time
+	lastStatus RunStatus
+	lastError  error
+	history    *runHistory
+
+	// cancelRun and runDone track the scheduled run in progress when the concurrency policy is not ConcurrencyAllow
+	cancelRun context.CancelFunc
+	runDone   chan struct{}
+}
+
+// isPaused returns true when the job runs are skipped
+func (j *scheduledJob) isPaused() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.paused
+}
+
+// setPaused pauses or resumes the job
+func (j *scheduledJob) setPaused(paused bool) {
+	j.mu.Lock()
+	j.paused = paused
+	j.mu.Unlock()
+}
+
+// recordRun records the outcome of a run and adds it to the history
+func (j *scheduledJob) recordRun(startedAt time.Time, duration time.Duration, attempts int, err error) JobRun {
+	run := JobRun{
+		StartedAt: startedAt,
+		Duration:  duration,
+		Attempts:  attempts,
+		Status:    RunStatusSucceeded,
+		Error:     err,
+	}
+	if err != nil {
+		run.Status = RunStatusFailed
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.lastRun = startedAt
+	j.lastError = err
+	j.lastStatus = run.Status
+	j.history.add(run)
+	return run
+}
+
+// beginRun starts a scheduled run according to the concurrency policy of the job.
+// It returns false when the run is skipped, otherwise the context of the run and the function ending it.
+func (j *scheduledJob) beginRun(ctx context.Context) (context.Context, func(), bool) {
+	policy := j.options.Concurrency
+	if policy == ConcurrencyAllow {
+		return ctx, func() {}, true
+	}
+
+	j.mu.Lock()
+	for j.runDone != nil {
+		if policy == ConcurrencyForbid {
+			j.mu.Unlock()
+			return nil, nil, false
+		}
+
+		// replace the run in progress
+		cancel, done := j.cancelRun, j.runDone
+		j.mu.Unlock()
+		cancel()
+		<-done
+		j.mu.Lock()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	j.cancelRun, j.runDone = cancel, done
+	j.mu.Unlock()
+
+	end := func() {
+		j.mu.Lock()
+		j.cancelRun, j.runDone = nil, nil
+		j.mu.Unlock()
+		cancel()
+		close(done)
+	}
+	return ctx, end, true
+}
+
+// state returns the state of the job persisted in a JobStore. The caller must hold the scheduler lock.
+func (j *scheduledJob) state(now time.Time) JobState {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	state := JobState{
+		ID:             j.job.ID(),
+		Trigger:        j.trigger,
+		CronExpression: j.cronExpression,
+		Delay:          j.delay,
+		LastRun:        j.lastRun,
+		Paused:         j.paused,
+	}
+
+	switch {
+	case j.trigger == TriggerCron:
+		state.Location = j.location.String()
+		state.NextRun = j.schedule.Next(now)
+	case !j.done:
+		state.NextRun = j.nextFire
+	}
+	return state
+}
+
+// restore applies the persisted state of the job according to its misfire policy.
+// The state is ignored when the definition of the job has changed. The job must not be added yet.
+func (j *scheduledJob) restore(stored *JobState, now time.Time) {
+	if !stored.sameDefinition(j.state(now)) {
+		return
+	}
+
+	j.mu.Lock()
+	j.paused = stored.Paused
+	j.lastRun = stored.LastRun
+	j.mu.Unlock()
+
+	missed := !stored.NextRun.IsZero() && stored.NextRun.Before(now)
+	runMissed := missed && j.options.Misfire == MisfireRunOnce
+	switch j.trigger {
+	case TriggerCron:
+		j.catchUp = runMissed
+	case TriggerOnce:
+		// the job has already run or its run is skipped
+		j.done = stored.NextRun.IsZero() || (missed && !runMissed)
+	case TriggerFixedDelay:
+		if !stored.NextRun.IsZero() && (!missed || runMissed) {
+			j.nextFire = stored.NextRun
+		}
+	}
+}
+
+// runs returns the history of the job
+func (j *scheduledJob) runs() []JobRun {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.history.list()
+}
+
+// info returns the description of the job. The caller must hold the scheduler lock.
+func (j *scheduledJob) info(started bool) JobInfo {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	info := JobInfo{
+		ID:             j.job.ID(),
+		Trigger:        j.trigger,
+		CronExpression: j.cronExpression,
+		Location:       j.location,
+		Delay:          j.delay,
+		DependsOn:      append([]string(nil), j.dependsOn...),
+		LastRun:        j.lastRun,
+		LastStatus:     j.lastStatus,
+		LastError:      j.lastError,
+		Paused:         j.paused,
+	}
+
+	switch {
+	case j.paused:
+	case j.trigger == TriggerCron:
+		info.NextRun = j.gocronJob.NextRun()
+	case started && !j.done:
+		info.NextRun = j.nextFire
+	}
+	return info
+}
@@ -0,0 +1,177 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+)
+
+// ConcurrencyPolicy defines what happens when a scheduled run of a job is due while its previous run is still executing
+type ConcurrencyPolicy int
+
+const (
+	// ConcurrencyForbid skips the new run. This is the default policy.
+	ConcurrencyForbid ConcurrencyPolicy = iota
+	// ConcurrencyAllow starts the new run alongside the previous one
+	ConcurrencyAllow
+	// ConcurrencyReplace cancels the context of the previous run, waits for it to return and then starts the new run
+	ConcurrencyReplace
+)
+
+// String returns the text representation of the policy
+func (p ConcurrencyPolicy) String() string {
+	switch p {
+	case ConcurrencyAllow:
+		return "allow"
+	case ConcurrencyReplace:
+		return "replace"
+	default:
+		return "forbid"
+	}
+}
+
+// MisfirePolicy defines what happens to the runs of a job missed while the service was down.
+// It applies to the jobs whose state is restored from a JobStore.
+type MisfirePolicy int
+
+const (
+	// MisfireSkip skips the missed runs and resumes the schedule. This is the default policy.
+	MisfireSkip MisfirePolicy = iota
+	// MisfireRunOnce runs the job once as soon as the scheduler is started, whatever the number of missed runs,
+	// and then resumes the schedule
+	MisfireRunOnce
+)
+
+// String returns the text representation of the policy
+func (p MisfirePolicy) String() string {
+	if p == MisfireRunOnce {
+		return "run-once"
+	}
+	return "skip"
+}
+
+// JobOptions defines how a job is run
+type JobOptions struct {
+	// MaxRetries is the number of times a failed run is retried. By default, a failed run is not retried.
+	MaxRetries uint64
+	// BackOff creates the strategy computing the delay between the retries of a run.
+	// It is called once per run. The default strategy is an exponential backoff.
+	BackOff func() backoff.BackOff
+	// Timeout bounds the duration of every attempt of a run. There is no timeout by default.
+	Timeout time.Duration
+	// OnError is called with the error of a scheduled run once its retries are exhausted.
+	// The errors are otherwise only reported by ListJobs.
+	OnError func(ctx context.Context, jobID string, err error)
+	// Concurrency defines what happens when a scheduled run is due while the previous one is still executing.
+	// The runs started with RunNow are not subject to it.
+	Concurrency ConcurrencyPolicy
+	// Misfire defines what happens to the runs missed while the service was down. It requires a JobStore.
+	Misfire MisfirePolicy
+	// Jitter is the upper bound of the random delay added before every scheduled run. There is no jitter by default.
+	Jitter time.Duration
+}
+
+// newJobOptions creates the default job options and applies the given options
+func newJobOptions(opts ...JobOption) *JobOptions {
+	options := &JobOptions{
+		BackOff: func() backoff.BackOff {
+			return backoff.NewExponentialBackOff()
+		},
+	}
+	for _, opt := range opts {
+		opt.Apply(options)
+	}
+	return options
+}
+
+// JobOption is the interface that applies a job option.
+type JobOption interface {
+	// Apply sets the JobOption value of a JobOptions.
+	Apply(*JobOptions)
+}
+
+var _ JobOption = JobOptionFunc(nil)
+
+// JobOptionFunc implements the JobOption interface.
+type JobOptionFunc func(*JobOptions)
+
+// Apply applies the option
+func (f JobOptionFunc) Apply(o *JobOptions) {
+	f(o)
+}
+
+// WithRetry retries a failed run up to maxRetries times
+func WithRetry(maxRetries uint64) JobOption {
+	return JobOptionFunc(func(o *JobOptions) {
+		o.MaxRetries = maxRetries
+	})
+}
+
+// WithBackOff sets the strategy computing the delay between the retries of a run.
+// newBackOff is called once per run.
+func WithBackOff(newBackOff func() backoff.BackOff) JobOption {
+	return JobOptionFunc(func(o *JobOptions) {
+		o.BackOff = newBackOff
+	})
+}
+
+// WithTimeout bounds the duration of every attempt of a run
+func WithTimeout(timeout time.Duration) JobOption {
+	return JobOptionFunc(func(o *JobOptions) {
+		o.Timeout = timeout
+	})
+}
+
+// WithOnError sets the callback receiving the error of a scheduled run once its retries are exhausted
+func WithOnError(onError func(ctx context.Context, jobID string, err error)) JobOption {
+	return JobOptionFunc(func(o *JobOptions) {
+		o.OnError = onError
+	})
+}
+
+// WithConcurrencyPolicy sets what happens when a scheduled run is due while the previous one is still executing
+func WithConcurrencyPolicy(policy ConcurrencyPolicy) JobOption {
+	return JobOptionFunc(func(o *JobOptions) {
+		o.Concurrency = policy
+	})
+}
+
+// WithMisfirePolicy sets what happens to the runs missed while the service was down. It requires a JobStore.
+func WithMisfirePolicy(policy MisfirePolicy) JobOption {
+	return JobOptionFunc(func(o *JobOptions) {
+		o.Misfire = policy
+	})
+}
+
+// WithJitter delays every scheduled run by a random duration up to jitter so that the jobs sharing
+// the same schedule do not all start at once
+func WithJitter(jitter time.Duration) JobOption {
+	return JobOptionFunc(func(o *JobOptions) {
+		o.Jitter = jitter
+	})
+}
@@ -0,0 +1,102 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/multierr"
+)
+
+// instrumentationName is the name of the tracer and the meter of the scheduler
+const instrumentationName = "github.com/tochemey/gopack/scheduler"
+
+// the attributes of the job metrics and spans
+const (
+	jobIDKey      = attribute.Key("job.id")
+	jobTriggerKey = attribute.Key("job.trigger")
+	jobStatusKey  = attribute.Key("job.status")
+	skipReasonKey = attribute.Key("job.skip_reason")
+)
+
+// the reasons a scheduled run is skipped
+const (
+	skipReasonPaused  = "paused"
+	skipReasonLocked  = "locked"
+	skipReasonOverlap = "overlap"
+)
+
+// jobMetrics holds the instruments recording the runs of the jobs
+type jobMetrics struct {
+	runs     metric.Int64Counter
+	duration metric.Float64Histogram
+	skipped  metric.Int64Counter
+}
+
+// newJobMetrics creates the job instruments with the given meter
+func newJobMetrics(meter metric.Meter) (*jobMetrics, error) {
+	runs, runsErr := meter.Int64Counter("scheduler.job.runs",
+		metric.WithDescription("The number of runs of the jobs"),
+		metric.WithUnit("{run}"))
+	duration, durationErr := meter.Float64Histogram("scheduler.job.duration",
+		metric.WithDescription("The duration of the runs of the jobs, retries included"),
+		metric.WithUnit("s"))
+	skipped, skippedErr := meter.Int64Counter("scheduler.job.skipped",
+		metric.WithDescription("The number of scheduled runs of the jobs that were skipped"),
+		metric.WithUnit("{run}"))
+
+	if err := multierr.Combine(runsErr, durationErr, skippedErr); err != nil {
+		return nil, err
+	}
+
+	return &jobMetrics{
+		runs:     runs,
+		duration: duration,
+		skipped:  skipped,
+	}, nil
+}
+
+// recordRun records the outcome and the duration of a run
+func (m *jobMetrics) recordRun(ctx context.Context, entry *scheduledJob, duration time.Duration, status RunStatus) {
+	attributes := metric.WithAttributes(
+		jobIDKey.String(entry.job.ID()),
+		jobTriggerKey.String(entry.trigger.String()),
+		jobStatusKey.String(status.String()),
+	)
+	m.runs.Add(ctx, 1, attributes)
+	m.duration.Record(ctx, duration.Seconds(), attributes)
+}
+
+// recordSkip records a scheduled run skipped for the given reason
+func (m *jobMetrics) recordSkip(ctx context.Context, entry *scheduledJob, reason string) {
+	m.skipped.Add(ctx, 1, metric.WithAttributes(
+		jobIDKey.String(entry.job.ID()),
+		jobTriggerKey.String(entry.trigger.String()),
+		skipReasonKey.String(reason),
+	))
+}
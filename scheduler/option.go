@@ -0,0 +1,134 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package scheduler
+
+import (
+	"time"
+
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/tochemey/gopack/clock"
+)
+
+// Option is the interface that applies a configuration option.
+type Option interface {
+	// Apply sets the Option value of a config.
+	Apply(*JobsScheduler)
+}
+
+var _ Option = OptionFunc(nil)
+
+// OptionFunc implements the Option interface.
+type OptionFunc func(*JobsScheduler)
+
+// Apply applies the option
+func (f OptionFunc) Apply(s *JobsScheduler) {
+	f(s)
+}
+
+// WithClock sets the clock used by the scheduler to compute the jobs next run.
+func WithClock(clock clock.Clock) Option {
+	return OptionFunc(func(s *JobsScheduler) {
+		s.clock = clock
+		s.scheduler.CustomTime(&timeWrapper{clock: clock})
+	})
+}
+
+// WithDefaultTimezone sets the timezone evaluating the cron expressions of the jobs added with AddJob.
+// The default is UTC.
+func WithDefaultTimezone(loc *time.Location) Option {
+	return OptionFunc(func(s *JobsScheduler) {
+		s.location = loc
+	})
+}
+
+// WithJobStore sets the JobStore persisting the state of the jobs. The jobs added to the scheduler
+// resume the schedule saved by a previous instance of the service, according to their MisfirePolicy.
+func WithJobStore(store JobStore) Option {
+	return OptionFunc(func(s *JobsScheduler) {
+		s.store = store
+	})
+}
+
+// WithSpread delays the scheduled runs of every job by a stable offset within the given window.
+// The offset is derived from the job ID, which spreads the jobs sharing the same schedule over the window.
+// It adds up to the jitter of the job.
+func WithSpread(window time.Duration) Option {
+	return OptionFunc(func(s *JobsScheduler) {
+		s.spread = window
+	})
+}
+
+// WithJobLocker sets the JobLocker acquired before every scheduled run of a job.
+// It lets several instances schedule the same jobs while each run happens on only one of them.
+// The runs started with RunNow do not acquire the lock.
+func WithJobLocker(locker JobLocker) Option {
+	return OptionFunc(func(s *JobsScheduler) {
+		s.locker = locker
+	})
+}
+
+// WithHistorySize sets the number of runs kept in the history of every job.
+// The default is DefaultHistorySize and zero disables the history.
+func WithHistorySize(size int) Option {
+	return OptionFunc(func(s *JobsScheduler) {
+		s.historySize = size
+	})
+}
+
+// WithMeterProvider sets the meter provider recording the job metrics. The default is the global meter provider.
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return OptionFunc(func(s *JobsScheduler) {
+		s.meterProvider = provider
+	})
+}
+
+// WithTracerProvider sets the tracer provider creating the job run spans. The default is the global tracer provider.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return OptionFunc(func(s *JobsScheduler) {
+		s.tracerProvider = provider
+	})
+}
+
+// timeWrapper adapts a clock.Clock to the gocron TimeWrapper interface
+type timeWrapper struct {
+	clock clock.Clock
+}
+
+// Now returns the current time in the given location
+func (t *timeWrapper) Now(location *time.Location) time.Time {
+	return t.clock.Now().In(location)
+}
+
+// Unix returns the local Time corresponding to the given Unix time
+func (t *timeWrapper) Unix(sec int64, nsec int64) time.Time {
+	return time.Unix(sec, nsec)
+}
+
+// Sleep pauses the current go-routine for at least the duration d
+func (t *timeWrapper) Sleep(d time.Duration) {
+	t.clock.Sleep(d)
+}
@@ -0,0 +1,213 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/tochemey/gopack/postgres"
+)
+
+// postgresLockPrefix namespaces the advisory lock keys of the jobs
+const postgresLockPrefix = "gopack.scheduler/"
+
+// PostgresJobLocker is a JobLocker backed by postgres transaction-level advisory locks.
+// The lock of a run is held by an open transaction, hence a connection of the pool, until it is released.
+type PostgresJobLocker struct {
+	db              postgres.Postgres
+	minLockDuration time.Duration
+}
+
+// enforce compilation error
+var _ JobLocker = (*PostgresJobLocker)(nil)
+
+// NewPostgresJobLocker creates an instance of PostgresJobLocker using the given connected database.
+// The lock of a run is held for at least minLockDuration, even when the run completes earlier,
+// which keeps the instances whose clock lags behind from running the same tick again.
+// minLockDuration should be shorter than the interval of the jobs.
+func NewPostgresJobLocker(db postgres.Postgres, minLockDuration time.Duration) *PostgresJobLocker {
+	return &PostgresJobLocker{
+		db:              db,
+		minLockDuration: minLockDuration,
+	}
+}
+
+// TryLock tries to acquire the advisory lock of the given job.
+// The lock outlives the cancellation of ctx and is only released by the returned unlock function.
+func (l *PostgresJobLocker) TryLock(ctx context.Context, jobID string) (func(), bool, error) {
+	// the transaction would be rolled back, releasing the lock, as soon as the run context is cancelled
+	lockCtx := context.WithoutCancel(ctx)
+	tx, err := l.db.BeginTx(lockCtx, nil)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var acquired bool
+	if err := tx.QueryRowContext(lockCtx, "SELECT pg_try_advisory_xact_lock($1)", postgresLockKey(jobID)).Scan(&acquired); err != nil {
+		_ = tx.Rollback()
+		return nil, false, err
+	}
+
+	if !acquired {
+		_ = tx.Rollback()
+		return nil, false, nil
+	}
+
+	acquiredAt := time.Now()
+	var once sync.Once
+	unlock := func() {
+		once.Do(func() {
+			// ending the transaction releases the lock
+			remaining := l.minLockDuration - time.Since(acquiredAt)
+			if remaining <= 0 {
+				_ = tx.Rollback()
+				return
+			}
+			time.AfterFunc(remaining, func() { _ = tx.Rollback() })
+		})
+	}
+	return unlock, true, nil
+}
+
+// postgresLockKey returns the advisory lock key of the given job
+func postgresLockKey(jobID string) int64 {
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(postgresLockPrefix + jobID))
+	return int64(hash.Sum64())
+}
+
+// PostgresJobStoreSchema is the statement creating the table used by the PostgresJobStore.
+// The %s placeholder is the table name.
+const PostgresJobStoreSchema = `CREATE TABLE IF NOT EXISTS %s (
+	job_id TEXT PRIMARY KEY,
+	job_trigger SMALLINT NOT NULL,
+	cron_expression TEXT NOT NULL,
+	location TEXT NOT NULL,
+	delay_nanos BIGINT NOT NULL,
+	next_run TIMESTAMPTZ,
+	last_run TIMESTAMPTZ,
+	paused BOOLEAN NOT NULL
+);`
+
+// PostgresJobStore is a JobStore backed by a postgres table
+type PostgresJobStore struct {
+	db        postgres.Postgres
+	tableName string
+}
+
+// enforce compilation error
+var _ JobStore = (*PostgresJobStore)(nil)
+
+// postgresJobState maps a row of the job store table
+type postgresJobState struct {
+	JobID          string
+	JobTrigger     int
+	CronExpression string
+	Location       string
+	DelayNanos     int64
+	NextRun        sql.NullTime
+	LastRun        sql.NullTime
+	Paused         bool
+}
+
+// NewPostgresJobStore creates an instance of PostgresJobStore using the given connected database
+// and table name. The table can be created with CreateTable.
+func NewPostgresJobStore(db postgres.Postgres, tableName string) *PostgresJobStore {
+	return &PostgresJobStore{
+		db:        db,
+		tableName: tableName,
+	}
+}
+
+// CreateTable creates the job store table when it does not exist
+func (s *PostgresJobStore) CreateTable(ctx context.Context) error {
+	_, err := s.db.Exec(ctx, fmt.Sprintf(PostgresJobStoreSchema, s.tableName))
+	return err
+}
+
+// Save creates or replaces the state of a job
+func (s *PostgresJobStore) Save(ctx context.Context, state JobState) error {
+	statement := fmt.Sprintf(`INSERT INTO %s (job_id, job_trigger, cron_expression, location, delay_nanos, next_run, last_run, paused)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	ON CONFLICT (job_id) DO UPDATE
+	SET job_trigger = EXCLUDED.job_trigger, cron_expression = EXCLUDED.cron_expression, location = EXCLUDED.location,
+	delay_nanos = EXCLUDED.delay_nanos, next_run = EXCLUDED.next_run, last_run = EXCLUDED.last_run, paused = EXCLUDED.paused`, s.tableName)
+
+	_, err := s.db.Exec(ctx, statement,
+		state.ID,
+		int(state.Trigger),
+		state.CronExpression,
+		state.Location,
+		int64(state.Delay),
+		nullTime(state.NextRun),
+		nullTime(state.LastRun),
+		state.Paused,
+	)
+	return err
+}
+
+// Get returns the state of the given job or ErrJobStateNotFound
+func (s *PostgresJobStore) Get(ctx context.Context, jobID string) (*JobState, error) {
+	statement := fmt.Sprintf(`SELECT job_id, job_trigger, cron_expression, location, delay_nanos, next_run, last_run, paused
+	FROM %s WHERE job_id = $1`, s.tableName)
+
+	row := new(postgresJobState)
+	if err := s.db.Select(ctx, row, statement, jobID); err != nil {
+		return nil, err
+	}
+
+	// Select does not return an error when there is no row
+	if row.JobID == "" {
+		return nil, ErrJobStateNotFound
+	}
+
+	return &JobState{
+		ID:             row.JobID,
+		Trigger:        Trigger(row.JobTrigger),
+		CronExpression: row.CronExpression,
+		Location:       row.Location,
+		Delay:          time.Duration(row.DelayNanos),
+		NextRun:        row.NextRun.Time,
+		LastRun:        row.LastRun.Time,
+		Paused:         row.Paused,
+	}, nil
+}
+
+// Delete removes the state of the given job
+func (s *PostgresJobStore) Delete(ctx context.Context, jobID string) error {
+	statement := fmt.Sprintf("DELETE FROM %s WHERE job_id = $1", s.tableName)
+	_, err := s.db.Exec(ctx, statement, jobID)
+	return err
+}
+
+// nullTime maps the zero time to NULL
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t.UTC(), Valid: !t.IsZero()}
+}
@@ -0,0 +1,206 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tochemey/gopack/postgres"
+)
+
+type postgresTestSuite struct {
+	suite.Suite
+	container *postgres.TestContainer
+}
+
+// SetupSuite starts the Postgres database engine and set the container
+// host and port to use in the tests
+func (s *postgresTestSuite) SetupSuite() {
+	s.container = postgres.NewTestContainer("testdb", "test", "test")
+}
+
+func (s *postgresTestSuite) TearDownSuite() {
+	s.container.Cleanup()
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestPostgresTestSuite(t *testing.T) {
+	suite.Run(t, new(postgresTestSuite))
+}
+
+func (s *postgresTestSuite) TestJobLocker() {
+	s.Run("with mutual exclusion", func() {
+		ctx := context.TODO()
+		first := s.container.GetTestDB()
+		second := s.container.GetTestDB()
+		s.Require().NoError(first.Connect(ctx))
+		s.Require().NoError(second.Connect(ctx))
+
+		firstLocker := NewPostgresJobLocker(first, 0)
+		secondLocker := NewPostgresJobLocker(second, 0)
+
+		// the lock outlives the cancellation of the run context
+		runCtx, cancel := context.WithCancel(ctx)
+		unlock, acquired, err := firstLocker.TryLock(runCtx, "job")
+		s.Require().NoError(err)
+		s.Require().True(acquired)
+		cancel()
+
+		_, acquired, err = secondLocker.TryLock(ctx, "job")
+		s.Require().NoError(err)
+		s.Assert().False(acquired)
+
+		// the other jobs are not locked
+		otherUnlock, acquired, err := secondLocker.TryLock(ctx, "other-job")
+		s.Require().NoError(err)
+		s.Require().True(acquired)
+		otherUnlock()
+
+		unlock()
+		secondUnlock, acquired, err := secondLocker.TryLock(ctx, "job")
+		s.Require().NoError(err)
+		s.Require().True(acquired)
+		secondUnlock()
+
+		s.Assert().NoError(first.Disconnect(ctx))
+		s.Assert().NoError(second.Disconnect(ctx))
+	})
+	s.Run("with minimum lock duration", func() {
+		ctx := context.TODO()
+		first := s.container.GetTestDB()
+		second := s.container.GetTestDB()
+		s.Require().NoError(first.Connect(ctx))
+		s.Require().NoError(second.Connect(ctx))
+
+		minLockDuration := 500 * time.Millisecond
+		firstLocker := NewPostgresJobLocker(first, minLockDuration)
+		secondLocker := NewPostgresJobLocker(second, 0)
+
+		unlock, acquired, err := firstLocker.TryLock(ctx, "job")
+		s.Require().NoError(err)
+		s.Require().True(acquired)
+		acquiredAt := time.Now()
+		unlock()
+
+		// the lock is held until the minimum lock duration elapses
+		_, acquired, err = secondLocker.TryLock(ctx, "job")
+		s.Require().NoError(err)
+		s.Assert().False(acquired)
+
+		var secondUnlock func()
+		s.Require().Eventually(func() bool {
+			secondUnlock, acquired, err = secondLocker.TryLock(ctx, "job")
+			return err == nil && acquired
+		}, 5*time.Second, 50*time.Millisecond)
+		s.Assert().GreaterOrEqual(time.Since(acquiredAt), minLockDuration)
+		secondUnlock()
+
+		s.Assert().NoError(first.Disconnect(ctx))
+		s.Assert().NoError(second.Disconnect(ctx))
+	})
+}
+
+func (s *postgresTestSuite) TestJobStore() {
+	ctx := context.TODO()
+	db := s.container.GetTestDB()
+	s.Require().NoError(db.Connect(ctx))
+	store := NewPostgresJobStore(db, "scheduler_jobs")
+	s.Require().NoError(store.CreateTable(ctx))
+
+	s.Run("with save and get", func() {
+		nextRun := time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)
+		lastRun := nextRun.Add(-time.Hour)
+		state := JobState{
+			ID:             "cron-job",
+			Trigger:        TriggerCron,
+			CronExpression: "0 * * * *",
+			Location:       "Europe/Paris",
+			NextRun:        nextRun,
+			LastRun:        lastRun,
+		}
+		s.Require().NoError(store.Save(ctx, state))
+
+		saved, err := store.Get(ctx, "cron-job")
+		s.Require().NoError(err)
+		s.Assert().Equal("cron-job", saved.ID)
+		s.Assert().Equal(TriggerCron, saved.Trigger)
+		s.Assert().Equal("0 * * * *", saved.CronExpression)
+		s.Assert().Equal("Europe/Paris", saved.Location)
+		s.Assert().True(nextRun.Equal(saved.NextRun))
+		s.Assert().True(lastRun.Equal(saved.LastRun))
+		s.Assert().False(saved.Paused)
+
+		// saving again replaces the state
+		state.Paused = true
+		state.LastRun = nextRun
+		s.Require().NoError(store.Save(ctx, state))
+		saved, err = store.Get(ctx, "cron-job")
+		s.Require().NoError(err)
+		s.Assert().True(saved.Paused)
+		s.Assert().True(nextRun.Equal(saved.LastRun))
+	})
+	s.Run("with zero run times", func() {
+		state := JobState{
+			ID:      "once-job",
+			Trigger: TriggerOnce,
+			Delay:   time.Minute,
+		}
+		s.Require().NoError(store.Save(ctx, state))
+
+		// the zero times are stored as NULL and read back as zero times
+		var nullTimes bool
+		s.Require().NoError(db.Select(ctx, &nullTimes,
+			"SELECT next_run IS NULL AND last_run IS NULL FROM scheduler_jobs WHERE job_id = $1", "once-job"))
+		s.Assert().True(nullTimes)
+
+		saved, err := store.Get(ctx, "once-job")
+		s.Require().NoError(err)
+		s.Assert().Equal(TriggerOnce, saved.Trigger)
+		s.Assert().Equal(time.Minute, saved.Delay)
+		s.Assert().True(saved.NextRun.IsZero())
+		s.Assert().True(saved.LastRun.IsZero())
+	})
+	s.Run("with missing job", func() {
+		_, err := store.Get(ctx, "missing-job")
+		s.Assert().ErrorIs(err, ErrJobStateNotFound)
+	})
+	s.Run("with delete", func() {
+		s.Require().NoError(store.Save(ctx, JobState{ID: "deleted-job", Trigger: TriggerFixedDelay, Delay: time.Second}))
+		s.Require().NoError(store.Delete(ctx, "deleted-job"))
+
+		_, err := store.Get(ctx, "deleted-job")
+		s.Assert().ErrorIs(err, ErrJobStateNotFound)
+		// deleting a missing job is not an error
+		s.Assert().NoError(store.Delete(ctx, "deleted-job"))
+	})
+
+	s.Assert().NoError(db.DropTable(ctx, "scheduler_jobs"))
+	s.Assert().NoError(db.Disconnect(ctx))
+}
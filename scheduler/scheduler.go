@@ -27,16 +27,28 @@ package scheduler
 import (
 	"context"
 	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
 	"os"
 	"os/signal"
+	"sort"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
 
+	"github.com/cenkalti/backoff/v4"
 	"github.com/go-co-op/gocron"
 	"github.com/pkg/errors"
 	"github.com/robfig/cron/v3"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/tochemey/gopack/clock"
 )
 
 // the cronAgent expression parser
@@ -65,14 +77,55 @@ type Scheduler interface {
 	//   - Standard crontab specs, e.g. "* * * * ?"
 	//   - With optional second field, e.g. "* * * * * ?"
 	//   - Descriptors, e.g. "@midnight", "@every 1h30m"
-	AddJob(ctx context.Context, cronExpression string, job Job) error
+	// The JobOption settings such as the retries apply to every run of the job.
+	// The cron expression is evaluated in the default timezone of the scheduler.
+	AddJob(ctx context.Context, cronExpression string, job Job, opts ...JobOption) error
+	// ScheduleInLocation adds a new job runner whose cron expression is evaluated in the given timezone.
+	ScheduleInLocation(ctx context.Context, cronExpression string, loc *time.Location, job Job, opts ...JobOption) error
+	// ScheduleOnce adds a job run once at the given time, or as soon as the scheduler is started when that time has passed.
+	ScheduleOnce(ctx context.Context, at time.Time, job Job, opts ...JobOption) error
+	// ScheduleWithFixedDelay adds a job run repeatedly with the given delay between the end of a run and the start of the next one.
+	// The first run happens once the delay has elapsed.
+	ScheduleWithFixedDelay(ctx context.Context, delay time.Duration, job Job, opts ...JobOption) error
+	// RunNow runs the job immediately in the calling go-routine, even when it is paused, and returns the error of its last attempt
+	RunNow(ctx context.Context, jobID string) error
+	// Unschedule removes the job from the scheduler. A running job is not interrupted.
+	Unschedule(ctx context.Context, jobID string) error
+	// Pause skips the runs of the job until it is resumed
+	Pause(ctx context.Context, jobID string) error
+	// Resume resumes the runs of a paused job
+	Resume(ctx context.Context, jobID string) error
+	// ScheduleAfter adds a job run each time all its upstream jobs have succeeded
+	ScheduleAfter(ctx context.Context, job Job, upstreams []string, opts ...JobOption) error
+	// Chain returns the status of the job and of the jobs depending on it
+	Chain(ctx context.Context, jobID string) (*ChainStatus, error)
+	// ListJobs returns the scheduled jobs sorted by ID
+	ListJobs(ctx context.Context) []JobInfo
+	// History returns the most recent runs of the job from the oldest to the most recent
+	History(ctx context.Context, jobID string) ([]JobRun, error)
 }
 
+// ErrJobNotFound is returned when the job is not scheduled
+var ErrJobNotFound = errors.New("job not found")
+
 // JobsScheduler implements Scheduler
 type JobsScheduler struct {
 	mu        sync.Mutex
 	scheduler *gocron.Scheduler
-	jobs      map[string]Job
+	jobs      map[string]*scheduledJob
+	clock     clock.Clock
+	locker    JobLocker
+	store     JobStore
+	started   bool
+	location  *time.Location
+	spread    time.Duration
+	random    func(n int64) int64
+
+	historySize    int
+	meterProvider  metric.MeterProvider
+	tracerProvider trace.TracerProvider
+	tracer         trace.Tracer
+	metrics        *jobMetrics
 }
 
 // enforce a compilation error
@@ -83,12 +136,35 @@ var _ Scheduler = &JobsScheduler{}
 //   - Standard crontab specs, e.g. "* * * * ?"
 //   - With optional second field, e.g. "* * * * * ?"
 //   - Descriptors, e.g. "@midnight", "@every 1h30m"
-func NewJobsScheduler() *JobsScheduler {
-	return &JobsScheduler{
+func NewJobsScheduler(opts ...Option) *JobsScheduler {
+	scheduler := &JobsScheduler{
 		mu:        sync.Mutex{},
 		scheduler: gocron.NewScheduler(time.UTC),
-		jobs:      make(map[string]Job),
+		jobs:      make(map[string]*scheduledJob),
+		clock:     clock.New(),
+		location:  time.UTC,
+		random:    rand.Int64N,
+
+		historySize:    DefaultHistorySize,
+		meterProvider:  otel.GetMeterProvider(),
+		tracerProvider: otel.GetTracerProvider(),
+	}
+
+	// apply the options
+	for _, opt := range opts {
+		opt.Apply(scheduler)
+	}
+
+	scheduler.tracer = scheduler.tracerProvider.Tracer(instrumentationName)
+	metrics, err := newJobMetrics(scheduler.meterProvider.Meter(instrumentationName))
+	if err != nil {
+		// fall back to instruments recording nothing
+		otel.Handle(err)
+		metrics, _ = newJobMetrics(noop.NewMeterProvider().Meter(instrumentationName))
 	}
+	scheduler.metrics = metrics
+
+	return scheduler
 }
 
 // Start starts the scheduler and run all the jobs in their separate go-routine
@@ -105,6 +181,14 @@ func (s *JobsScheduler) Start(ctx context.Context) {
 
 	// start the cron jobs
 	s.scheduler.StartAsync()
+
+	// arm the timers of the other jobs
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.started = true
+	for _, entry := range s.jobs {
+		s.arm(entry)
+	}
 }
 
 // Stop shutdowns the Scheduler gracefully
@@ -116,39 +200,81 @@ func (s *JobsScheduler) Stop(ctx context.Context) error {
 
 	// stop the scheduler
 	s.scheduler.Stop()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.started = false
+	for _, entry := range s.jobs {
+		s.disarm(entry)
+	}
 	return nil
 }
 
 // AddJob adds new Job to the scheduler. If the job already exists rejects the request.
-func (s *JobsScheduler) AddJob(ctx context.Context, cronExpression string, job Job) error {
-	// acquire the lock
-	s.mu.Lock()
-	// release lock when done
-	defer s.mu.Unlock()
+// The cron expression is evaluated in the timezone set with WithDefaultTimezone, UTC by default,
+// unless it starts with a CRON_TZ= or TZ= prefix.
+func (s *JobsScheduler) AddJob(ctx context.Context, cronExpression string, job Job, opts ...JobOption) error {
+	return s.ScheduleInLocation(ctx, cronExpression, s.location, job, opts...)
+}
+
+// ScheduleInLocation adds new Job to the scheduler whose cron expression is evaluated in the given timezone,
+// regardless of the timezone of the server. If the job already exists rejects the request.
+// A CRON_TZ= or TZ= prefix of the cron expression takes precedence over the given timezone.
+func (s *JobsScheduler) ScheduleInLocation(ctx context.Context, cronExpression string, loc *time.Location, job Job, opts ...JobOption) error {
+	if loc == nil {
+		return errors.New("the location is required")
+	}
+
+	// pin the cron expression to the timezone
+	spec := cronExpression
+	if !hasTimezone(cronExpression) {
+		spec = fmt.Sprintf("CRON_TZ=%s %s", loc.String(), cronExpression)
+	}
 
 	// validate the cron expression
-	if _, err := cronExpressionParser.Parse(cronExpression); err != nil {
+	schedule, err := cronExpressionParser.Parse(spec)
+	if err != nil {
 		// return error
 		return err
 	}
 
-	// check whether the job has been not been added already
-	if _, ok := s.jobs[job.ID()]; ok {
-		return fmt.Errorf("job (%s) is already added", job.ID())
+	// the timezone prefix of the expression takes precedence
+	if specSchedule, ok := schedule.(*cron.SpecSchedule); ok {
+		loc = specSchedule.Location
 	}
 
 	// add the cron job
-	_, err := s.scheduler.
-		CronWithSeconds(cronExpression).
+	entry := &scheduledJob{
+		job:            job,
+		trigger:        TriggerCron,
+		cronExpression: cronExpression,
+		location:       loc,
+		schedule:       schedule,
+		options:        newJobOptions(opts...),
+		history:        newRunHistory(s.historySize),
+		ctx:            ctx,
+	}
+	if err := s.restore(ctx, entry); err != nil {
+		return err
+	}
+
+	// acquire the lock
+	s.mu.Lock()
+	// release lock when done
+	defer s.mu.Unlock()
+
+	// check whether the job has been not been added in the meantime
+	if err := s.checkNotAdded(job.ID()); err != nil {
+		return err
+	}
+
+	gocronJob, err := s.scheduler.
+		CronWithSeconds(spec).
 		Name(job.ID()).
 		Tag(job.ID()).
-		SingletonMode().Do(func() {
-		// hook the job execution
-		if err := job.Run(ctx); err != nil {
-			// hook a recovery mechanism to the scheduler to handle the panic
-			panic(errors.Wrapf(err, "job (%s) failed to run", job.ID()))
-		}
-	})
+		Do(func() {
+			s.runJob(ctx, entry)
+		})
 
 	// handle the error
 	if err != nil {
@@ -157,10 +283,434 @@ func (s *JobsScheduler) AddJob(ctx context.Context, cronExpression string, job J
 	}
 
 	// let us add the job
-	s.jobs[job.ID()] = job
+	entry.gocronJob = gocronJob
+	s.jobs[job.ID()] = entry
+	s.arm(entry)
+	return nil
+}
+
+// ScheduleOnce adds a job run once at the given time, or as soon as the scheduler is started when that time has passed.
+// The job is listed until it is unscheduled.
+func (s *JobsScheduler) ScheduleOnce(ctx context.Context, at time.Time, job Job, opts ...JobOption) error {
+	return s.addTimedJob(ctx, &scheduledJob{
+		job:      job,
+		trigger:  TriggerOnce,
+		options:  newJobOptions(opts...),
+		ctx:      ctx,
+		nextFire: at,
+	})
+}
+
+// ScheduleWithFixedDelay adds a job run repeatedly with the given delay between the end of a run and
+// the start of the next one. Unlike a cron schedule, the runs never overlap and a slow run delays the next ones.
+// The first run happens once the delay has elapsed.
+func (s *JobsScheduler) ScheduleWithFixedDelay(ctx context.Context, delay time.Duration, job Job, opts ...JobOption) error {
+	if delay <= 0 {
+		return errors.New("the delay must be positive")
+	}
+
+	return s.addTimedJob(ctx, &scheduledJob{
+		job:      job,
+		trigger:  TriggerFixedDelay,
+		options:  newJobOptions(opts...),
+		delay:    delay,
+		ctx:      ctx,
+		nextFire: s.clock.Now().Add(delay),
+	})
+}
+
+// RunNow runs the job immediately in the calling go-routine, even when it is paused, and returns the error
+// of its last attempt. The OnError callback is not called. The run does not change the schedule of the job
+// and may overlap a scheduled run.
+func (s *JobsScheduler) RunNow(ctx context.Context, jobID string) error {
+	s.mu.Lock()
+	entry, err := s.lookup(jobID)
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return s.execute(ctx, entry)
+}
+
+// Unschedule removes the job from the scheduler. A running job is not interrupted.
+func (s *JobsScheduler) Unschedule(ctx context.Context, jobID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, err := s.lookup(jobID)
+	if err != nil {
+		return err
+	}
+
+	if s.store != nil {
+		if err := s.store.Delete(ctx, jobID); err != nil {
+			return errors.Wrapf(err, "unable to delete the state of job (%s)", jobID)
+		}
+	}
+
+	if entry.gocronJob != nil {
+		s.scheduler.RemoveByReference(entry.gocronJob)
+	}
+	s.disarm(entry)
+	delete(s.jobs, jobID)
+	return nil
+}
+
+// Pause skips the runs of the job until it is resumed. A running job is not interrupted.
+func (s *JobsScheduler) Pause(ctx context.Context, jobID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, err := s.lookup(jobID)
+	if err != nil {
+		return err
+	}
+
+	entry.setPaused(true)
+	return s.persist(ctx, entry.state(s.clock.Now()))
+}
+
+// Resume resumes the runs of a paused job
+func (s *JobsScheduler) Resume(ctx context.Context, jobID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, err := s.lookup(jobID)
+	if err != nil {
+		return err
+	}
+
+	entry.setPaused(false)
+	return s.persist(ctx, entry.state(s.clock.Now()))
+}
+
+// ListJobs returns the scheduled jobs sorted by ID
+func (s *JobsScheduler) ListJobs(ctx context.Context) []JobInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	infos := make([]JobInfo, 0, len(s.jobs))
+	for _, entry := range s.jobs {
+		infos = append(infos, entry.info(s.started))
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].ID < infos[j].ID
+	})
+	return infos
+}
+
+// History returns the most recent runs of the job from the oldest to the most recent.
+// The number of runs kept is set with WithHistorySize.
+func (s *JobsScheduler) History(ctx context.Context, jobID string) ([]JobRun, error) {
+	s.mu.Lock()
+	entry, err := s.lookup(jobID)
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return entry.runs(), nil
+}
+
+// hasTimezone returns true when the cron expression starts with a timezone prefix
+func hasTimezone(cronExpression string) bool {
+	return strings.HasPrefix(cronExpression, "CRON_TZ=") || strings.HasPrefix(cronExpression, "TZ=")
+}
+
+// lookup returns the scheduled job with the given ID. The caller must hold the lock.
+func (s *JobsScheduler) lookup(jobID string) (*scheduledJob, error) {
+	entry, ok := s.jobs[jobID]
+	if !ok {
+		return nil, errors.Wrapf(ErrJobNotFound, "job (%s)", jobID)
+	}
+	return entry, nil
+}
+
+// checkNotAdded returns an error when a job with the given ID is already added. The caller must hold the lock.
+func (s *JobsScheduler) checkNotAdded(jobID string) error {
+	if _, ok := s.jobs[jobID]; ok {
+		return fmt.Errorf("job (%s) is already added", jobID)
+	}
 	return nil
 }
 
+// addTimedJob adds a TriggerOnce or TriggerFixedDelay job and arms its timer when the scheduler is started
+func (s *JobsScheduler) addTimedJob(ctx context.Context, entry *scheduledJob) error {
+	entry.history = newRunHistory(s.historySize)
+	if err := s.restore(ctx, entry); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobID := entry.job.ID()
+	if err := s.checkNotAdded(jobID); err != nil {
+		return err
+	}
+
+	s.jobs[jobID] = entry
+	s.arm(entry)
+	return nil
+}
+
+// arm starts the timer of a TriggerOnce or TriggerFixedDelay job and runs the missed run of a job
+// restored from the JobStore. The caller must hold the lock.
+func (s *JobsScheduler) arm(entry *scheduledJob) {
+	if !s.started {
+		return
+	}
+
+	if entry.catchUp {
+		entry.catchUp = false
+		go s.runJob(entry.ctx, entry)
+	}
+
+	if (entry.trigger != TriggerOnce && entry.trigger != TriggerFixedDelay) || entry.done || entry.timer != nil {
+		return
+	}
+
+	entry.generation++
+	generation := entry.generation
+	delay := max(entry.nextFire.Sub(s.clock.Now()), 0)
+	entry.timer = s.clock.AfterFunc(delay, func() {
+		s.fire(entry, generation)
+	})
+}
+
+// disarm stops the timer of a TriggerOnce or TriggerFixedDelay job. The caller must hold the lock.
+func (s *JobsScheduler) disarm(entry *scheduledJob) {
+	entry.generation++
+	if entry.timer != nil {
+		entry.timer.Stop()
+		entry.timer = nil
+	}
+}
+
+// fire runs a TriggerOnce or TriggerFixedDelay job and schedules its next run
+func (s *JobsScheduler) fire(entry *scheduledJob, generation int) {
+	s.mu.Lock()
+	stale := entry.generation != generation
+	s.mu.Unlock()
+	if stale {
+		return
+	}
+
+	s.executeScheduled(entry.ctx, entry)
+
+	s.mu.Lock()
+	// the job has been unscheduled or the scheduler stopped during the run
+	if entry.generation != generation {
+		s.mu.Unlock()
+		return
+	}
+	entry.timer = nil
+
+	if entry.trigger == TriggerOnce {
+		entry.done = true
+	} else {
+		entry.nextFire = s.clock.Now().Add(entry.delay)
+		s.arm(entry)
+	}
+	s.mu.Unlock()
+
+	s.persistRun(entry.ctx, entry)
+}
+
+// execute runs the job, retrying the failed attempts according to the job options, and records the outcome of the run
+// in the job history, the metrics and a span
+func (s *JobsScheduler) execute(ctx context.Context, entry *scheduledJob) error {
+	ctx, span := s.tracer.Start(ctx, "Run", trace.WithAttributes(
+		jobIDKey.String(entry.job.ID()),
+		jobTriggerKey.String(entry.trigger.String()),
+	))
+	defer span.End()
+
+	startedAt := s.clock.Now()
+	options := entry.options
+	policy := options.BackOff()
+	policy.Reset()
+
+	var err error
+	attempts := 0
+	for {
+		attempts++
+		if err = s.attempt(ctx, entry); err == nil || uint64(attempts) > options.MaxRetries {
+			break
+		}
+
+		// stop retrying when the policy gives up or the context is done
+		delay := policy.NextBackOff()
+		if delay == backoff.Stop || !s.wait(ctx, delay) {
+			break
+		}
+	}
+
+	run := entry.recordRun(startedAt, s.clock.Since(startedAt), attempts, err)
+	s.metrics.recordRun(ctx, entry, run.Duration, run.Status)
+	span.SetAttributes(jobStatusKey.String(run.Status.String()), attribute.Int("job.attempts", attempts))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
+
+// wait pauses for the given delay and returns false when the context is done first
+func (s *JobsScheduler) wait(ctx context.Context, delay time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-s.clock.After(delay):
+		return true
+	}
+}
+
+// attempt runs the job once within the job timeout and turns its panic into an error
+func (s *JobsScheduler) attempt(ctx context.Context, entry *scheduledJob) (err error) {
+	if timeout := entry.options.Timeout; timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job (%s) panicked: %v", entry.job.ID(), r)
+		}
+	}()
+	return entry.job.Run(ctx)
+}
+
+// executeScheduled executes a scheduled run and hands over its error to the OnError callback.
+// The run starts after the spread offset and the jitter of the job. It is skipped when the job is paused,
+// its concurrency policy forbids overlapping the run in progress or another instance holds the lock of the job.
+func (s *JobsScheduler) executeScheduled(ctx context.Context, entry *scheduledJob) {
+	if delay := s.startDelay(entry); delay > 0 && !s.wait(ctx, delay) {
+		return
+	}
+
+	if entry.isPaused() {
+		s.metrics.recordSkip(ctx, entry, skipReasonPaused)
+		return
+	}
+
+	runCtx, endRun, ok := entry.beginRun(ctx)
+	if !ok {
+		s.metrics.recordSkip(ctx, entry, skipReasonOverlap)
+		return
+	}
+	defer endRun()
+	ctx = runCtx
+
+	if s.locker != nil {
+		unlock, acquired, err := s.locker.TryLock(ctx, entry.job.ID())
+		if err != nil {
+			s.notifyError(ctx, entry, errors.Wrapf(err, "unable to acquire the lock of job (%s)", entry.job.ID()))
+			return
+		}
+
+		if !acquired {
+			s.metrics.recordSkip(ctx, entry, skipReasonLocked)
+			return
+		}
+		defer unlock()
+	}
+
+	if err := s.execute(ctx, entry); err != nil {
+		s.notifyError(ctx, entry, err)
+		return
+	}
+	s.notifyDependents(entry)
+}
+
+// startDelay returns the delay of a scheduled run of the job, made of the spread offset and the jitter
+func (s *JobsScheduler) startDelay(entry *scheduledJob) time.Duration {
+	var delay time.Duration
+	if s.spread > 0 {
+		hash := fnv.New64a()
+		_, _ = hash.Write([]byte(entry.job.ID()))
+		delay = time.Duration(hash.Sum64() % uint64(s.spread))
+	}
+
+	if jitter := entry.options.Jitter; jitter > 0 {
+		delay += time.Duration(s.random(int64(jitter)))
+	}
+	return delay
+}
+
+// notifyError hands over the error of a scheduled run to the OnError callback of the job
+func (s *JobsScheduler) notifyError(ctx context.Context, entry *scheduledJob, err error) {
+	if entry.options.OnError != nil {
+		entry.options.OnError(ctx, entry.job.ID(), err)
+	}
+}
+
+// runJob is the function run by gocron for the TriggerCron jobs
+func (s *JobsScheduler) runJob(ctx context.Context, entry *scheduledJob) {
+	s.executeScheduled(ctx, entry)
+	s.persistRun(ctx, entry)
+}
+
+// restore applies the state of the job saved in the JobStore and saves its current state.
+// It is called before the job is added, without holding the lock, so that the JobStore calls
+// do not block the scheduler. An already added job is not restored.
+func (s *JobsScheduler) restore(ctx context.Context, entry *scheduledJob) error {
+	if s.store == nil {
+		return nil
+	}
+
+	jobID := entry.job.ID()
+	s.mu.Lock()
+	err := s.checkNotAdded(jobID)
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	stored, err := s.store.Get(ctx, jobID)
+	switch {
+	case errors.Is(err, ErrJobStateNotFound):
+	case err != nil:
+		return errors.Wrapf(err, "unable to load the state of job (%s)", jobID)
+	default:
+		entry.restore(stored, s.clock.Now())
+	}
+	return s.persist(ctx, entry.state(s.clock.Now()))
+}
+
+// persist saves the state of a job when a JobStore is set
+func (s *JobsScheduler) persist(ctx context.Context, state JobState) error {
+	if s.store == nil {
+		return nil
+	}
+
+	if err := s.store.Save(ctx, state); err != nil {
+		return errors.Wrapf(err, "unable to save the state of job (%s)", state.ID)
+	}
+	return nil
+}
+
+// persistRun saves the state of a job after a scheduled run and hands over the error to the OnError callback
+func (s *JobsScheduler) persistRun(ctx context.Context, entry *scheduledJob) {
+	if s.store == nil {
+		return
+	}
+
+	s.mu.Lock()
+	// the state of an unscheduled job is not saved again
+	if s.jobs[entry.job.ID()] != entry {
+		s.mu.Unlock()
+		return
+	}
+	state := entry.state(s.clock.Now())
+	s.mu.Unlock()
+
+	if err := s.persist(context.WithoutCancel(ctx), state); err != nil {
+		s.notifyError(ctx, entry, err)
+	}
+}
+
 // Run runs the scheduler by executing all jobs that have been added to it.
 func (s *JobsScheduler) Run(ctx context.Context) {
 	// start the jobs scheduler
@@ -27,10 +27,19 @@ package scheduler
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/cenkalti/backoff/v4"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/suite"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/tochemey/gopack/clock"
 )
 
 type testJob struct {
@@ -72,6 +81,39 @@ func (j *fastJob) Run(context.Context) error {
 	return nil
 }
 
+type countingJob struct {
+	id   string
+	runs atomic.Int32
+	err  error
+}
+
+func (j *countingJob) ID() string {
+	return j.id
+}
+
+func (j *countingJob) Run(context.Context) error {
+	j.runs.Add(1)
+	return j.err
+}
+
+// flakyJob fails the given number of runs before succeeding
+type flakyJob struct {
+	id       string
+	failures int32
+	runs     atomic.Int32
+}
+
+func (j *flakyJob) ID() string {
+	return j.id
+}
+
+func (j *flakyJob) Run(context.Context) error {
+	if j.runs.Add(1) <= j.failures {
+		return errors.New("unavailable")
+	}
+	return nil
+}
+
 type schedulerTestSuite struct {
 	suite.Suite
 }
@@ -303,6 +345,857 @@ func (s *schedulerTestSuite) TestAddJob() {
 	})
 }
 
+func (s *schedulerTestSuite) TestManageJobs() {
+	ctx := context.TODO()
+	const expr = "* * * * * ?"
+	scheduler := NewJobsScheduler()
+
+	paused := &countingJob{id: "Job-A"}
+	failing := &countingJob{id: "Job-B", err: errors.New("unavailable")}
+	s.Require().NoError(scheduler.AddJob(ctx, expr, failing))
+	s.Require().NoError(scheduler.AddJob(ctx, expr, paused))
+	s.Require().NoError(scheduler.Pause(ctx, paused.ID()))
+
+	scheduler.Start(ctx)
+	defer func() {
+		_ = scheduler.Stop(ctx)
+	}()
+
+	s.Assert().Eventually(func() bool { return failing.runs.Load() > 0 }, 2*oneSecond, 10*time.Millisecond)
+	s.Assert().Zero(paused.runs.Load())
+
+	jobs := scheduler.ListJobs(ctx)
+	s.Require().Len(jobs, 2)
+	s.Assert().Equal("Job-A", jobs[0].ID)
+	s.Assert().True(jobs[0].Paused)
+	s.Assert().True(jobs[0].NextRun.IsZero())
+	s.Assert().Equal(RunStatusNone, jobs[0].LastStatus)
+	s.Assert().Equal("Job-B", jobs[1].ID)
+	s.Assert().Equal(expr, jobs[1].CronExpression)
+	s.Assert().False(jobs[1].NextRun.IsZero())
+	s.Assert().False(jobs[1].LastRun.IsZero())
+	s.Assert().Equal(RunStatusFailed, jobs[1].LastStatus)
+	s.Assert().EqualError(jobs[1].LastError, "unavailable")
+
+	s.Require().NoError(scheduler.Resume(ctx, paused.ID()))
+	s.Assert().Eventually(func() bool { return paused.runs.Load() > 0 }, 2*oneSecond, 10*time.Millisecond)
+	s.Assert().Equal(RunStatusSucceeded, scheduler.ListJobs(ctx)[0].LastStatus)
+
+	s.Require().NoError(scheduler.Unschedule(ctx, failing.ID()))
+	s.Assert().Len(scheduler.ListJobs(ctx), 1)
+	// the job can be added again once unscheduled
+	s.Require().NoError(scheduler.AddJob(ctx, expr, &countingJob{id: "Job-B"}))
+
+	err := scheduler.Unschedule(ctx, "Job-X")
+	s.Assert().ErrorIs(err, ErrJobNotFound)
+	s.Assert().EqualError(err, "job (Job-X): job not found")
+	s.Assert().ErrorIs(scheduler.Pause(ctx, "Job-X"), ErrJobNotFound)
+	s.Assert().ErrorIs(scheduler.Resume(ctx, "Job-X"), ErrJobNotFound)
+}
+
+func (s *schedulerTestSuite) TestScheduleInLocation() {
+	ctx := context.TODO()
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	s.Require().NoError(err)
+	newYork, err := time.LoadLocation("America/New_York")
+	s.Require().NoError(err)
+	now := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	s.Run("with location", func() {
+		scheduler := NewJobsScheduler(WithClock(clock.NewFake(now)))
+		s.Require().NoError(scheduler.ScheduleInLocation(ctx, "0 0 9 * * *", berlin, &countingJob{id: "Job-X"}))
+		scheduler.Start(ctx)
+		defer func() {
+			_ = scheduler.Stop(ctx)
+		}()
+
+		info := scheduler.ListJobs(ctx)[0]
+		s.Assert().Equal("0 0 9 * * *", info.CronExpression)
+		s.Assert().Equal(berlin, info.Location)
+		s.Assert().True(time.Date(2024, time.March, 2, 8, 0, 0, 0, time.UTC).Equal(info.NextRun), info.NextRun)
+	})
+	s.Run("with default timezone", func() {
+		scheduler := NewJobsScheduler(WithClock(clock.NewFake(now)), WithDefaultTimezone(berlin))
+		s.Require().NoError(scheduler.AddJob(ctx, "0 0 9 * * *", &countingJob{id: "Job-X"}))
+		scheduler.Start(ctx)
+		defer func() {
+			_ = scheduler.Stop(ctx)
+		}()
+
+		info := scheduler.ListJobs(ctx)[0]
+		s.Assert().Equal(berlin, info.Location)
+		s.Assert().True(time.Date(2024, time.March, 2, 8, 0, 0, 0, time.UTC).Equal(info.NextRun), info.NextRun)
+	})
+	s.Run("with timezone prefix", func() {
+		scheduler := NewJobsScheduler(WithClock(clock.NewFake(now)))
+		s.Require().NoError(scheduler.ScheduleInLocation(ctx, "CRON_TZ=America/New_York 0 0 9 * * *", berlin, &countingJob{id: "Job-X"}))
+		scheduler.Start(ctx)
+		defer func() {
+			_ = scheduler.Stop(ctx)
+		}()
+
+		info := scheduler.ListJobs(ctx)[0]
+		s.Assert().Equal(newYork.String(), info.Location.String())
+		s.Assert().True(time.Date(2024, time.March, 1, 14, 0, 0, 0, time.UTC).Equal(info.NextRun), info.NextRun)
+	})
+	s.Run("without location", func() {
+		err := NewJobsScheduler().ScheduleInLocation(ctx, "0 0 9 * * *", nil, &countingJob{id: "Job-X"})
+		s.Assert().EqualError(err, "the location is required")
+	})
+}
+
+func (s *schedulerTestSuite) TestScheduleOnce() {
+	s.Run("with future time", func() {
+		ctx := context.TODO()
+		now := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+		fake := clock.NewFake(now)
+		scheduler := NewJobsScheduler(WithClock(fake))
+
+		job := &countingJob{id: "Job-X"}
+		s.Require().NoError(scheduler.ScheduleOnce(ctx, now.Add(time.Minute), job))
+		s.Assert().Error(scheduler.ScheduleOnce(ctx, now, job))
+
+		scheduler.Start(ctx)
+		defer func() {
+			_ = scheduler.Stop(ctx)
+		}()
+
+		info := scheduler.ListJobs(ctx)[0]
+		s.Assert().Equal(TriggerOnce, info.Trigger)
+		s.Assert().Equal(now.Add(time.Minute), info.NextRun)
+
+		fake.Advance(30 * time.Second)
+		s.Assert().Zero(job.runs.Load())
+		fake.Advance(30 * time.Second)
+		s.Assert().Eventually(func() bool { return job.runs.Load() == 1 }, time.Second, 10*time.Millisecond)
+		s.Assert().Eventually(func() bool { return scheduler.ListJobs(ctx)[0].NextRun.IsZero() }, time.Second, 10*time.Millisecond)
+
+		fake.Advance(time.Hour)
+		s.Assert().Never(func() bool { return job.runs.Load() > 1 }, 100*time.Millisecond, 10*time.Millisecond)
+	})
+	s.Run("with past time", func() {
+		ctx := context.TODO()
+		scheduler := NewJobsScheduler()
+		job := &countingJob{id: "Job-X"}
+		s.Require().NoError(scheduler.ScheduleOnce(ctx, time.Now().Add(-time.Hour), job))
+
+		// the job does not run before the scheduler is started
+		s.Assert().Never(func() bool { return job.runs.Load() > 0 }, 100*time.Millisecond, 10*time.Millisecond)
+
+		scheduler.Start(ctx)
+		defer func() {
+			_ = scheduler.Stop(ctx)
+		}()
+		s.Assert().Eventually(func() bool { return job.runs.Load() == 1 }, time.Second, 10*time.Millisecond)
+	})
+}
+
+func (s *schedulerTestSuite) TestScheduleWithFixedDelay() {
+	s.Run("with valid delay", func() {
+		ctx := context.TODO()
+		fake := clock.NewFake(time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC))
+		scheduler := NewJobsScheduler(WithClock(fake))
+
+		job := &countingJob{id: "Job-X"}
+		s.Require().NoError(scheduler.ScheduleWithFixedDelay(ctx, 10*time.Second, job))
+		scheduler.Start(ctx)
+
+		for runs := int32(1); runs <= 3; runs++ {
+			fake.BlockUntil(1)
+			fake.Advance(10 * time.Second)
+			s.Assert().Eventually(func() bool { return job.runs.Load() == runs }, time.Second, 10*time.Millisecond)
+		}
+
+		info := scheduler.ListJobs(ctx)[0]
+		s.Assert().Equal(TriggerFixedDelay, info.Trigger)
+		s.Assert().Equal(10*time.Second, info.Delay)
+
+		// the timers are stopped with the scheduler
+		fake.BlockUntil(1)
+		s.Require().NoError(scheduler.Stop(ctx))
+		fake.Advance(time.Minute)
+		s.Assert().Never(func() bool { return job.runs.Load() > 3 }, 100*time.Millisecond, 10*time.Millisecond)
+	})
+	s.Run("with invalid delay", func() {
+		scheduler := NewJobsScheduler()
+		err := scheduler.ScheduleWithFixedDelay(context.TODO(), 0, &countingJob{id: "Job-X"})
+		s.Assert().EqualError(err, "the delay must be positive")
+	})
+}
+
+func (s *schedulerTestSuite) TestRunNow() {
+	ctx := context.TODO()
+	scheduler := NewJobsScheduler()
+	job := &countingJob{id: "Job-X", err: errors.New("unavailable")}
+	s.Require().NoError(scheduler.AddJob(ctx, "@daily", job))
+	s.Require().NoError(scheduler.Pause(ctx, job.ID()))
+
+	s.Assert().EqualError(scheduler.RunNow(ctx, job.ID()), "unavailable")
+	s.Assert().EqualValues(1, job.runs.Load())
+	s.Assert().Equal(RunStatusFailed, scheduler.ListJobs(ctx)[0].LastStatus)
+	s.Assert().ErrorIs(scheduler.RunNow(ctx, "Job-Y"), ErrJobNotFound)
+}
+
+func (s *schedulerTestSuite) TestJobOptions() {
+	ctx := context.TODO()
+	noBackOff := WithBackOff(func() backoff.BackOff { return &backoff.ZeroBackOff{} })
+
+	s.Run("with retries", func() {
+		scheduler := NewJobsScheduler()
+		job := &flakyJob{id: "Job-X", failures: 2}
+		s.Require().NoError(scheduler.AddJob(ctx, "@daily", job, WithRetry(3), noBackOff))
+		s.Assert().NoError(scheduler.RunNow(ctx, job.ID()))
+		s.Assert().EqualValues(3, job.runs.Load())
+		s.Assert().Equal(RunStatusSucceeded, scheduler.ListJobs(ctx)[0].LastStatus)
+	})
+	s.Run("with retries exhausted", func() {
+		scheduler := NewJobsScheduler()
+		job := &flakyJob{id: "Job-X", failures: 5}
+		s.Require().NoError(scheduler.AddJob(ctx, "@daily", job, WithRetry(2), noBackOff))
+		s.Assert().EqualError(scheduler.RunNow(ctx, job.ID()), "unavailable")
+		s.Assert().EqualValues(3, job.runs.Load())
+	})
+	s.Run("with backoff", func() {
+		fake := clock.NewFake(time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC))
+		scheduler := NewJobsScheduler(WithClock(fake))
+		job := &flakyJob{id: "Job-X", failures: 1}
+		s.Require().NoError(scheduler.AddJob(ctx, "@daily", job, WithRetry(1),
+			WithBackOff(func() backoff.BackOff { return backoff.NewConstantBackOff(time.Minute) })))
+
+		done := make(chan error, 1)
+		go func() {
+			done <- scheduler.RunNow(ctx, job.ID())
+		}()
+
+		fake.BlockUntil(1)
+		s.Assert().EqualValues(1, job.runs.Load())
+		fake.Advance(time.Minute)
+		s.Assert().NoError(<-done)
+		s.Assert().EqualValues(2, job.runs.Load())
+	})
+	s.Run("with timeout", func() {
+		scheduler := NewJobsScheduler()
+		job := &blockingJob{id: "Job-X"}
+		s.Require().NoError(scheduler.AddJob(ctx, "@daily", job, WithTimeout(50*time.Millisecond)))
+		s.Assert().ErrorIs(scheduler.RunNow(ctx, job.ID()), context.DeadlineExceeded)
+	})
+	s.Run("with panic", func() {
+		scheduler := NewJobsScheduler()
+		s.Require().NoError(scheduler.AddJob(ctx, "@daily", &panickingJob{id: "Job-X"}))
+		s.Assert().EqualError(scheduler.RunNow(ctx, "Job-X"), "job (Job-X) panicked: boom")
+	})
+	s.Run("with error handler", func() {
+		scheduler := NewJobsScheduler()
+		failures := make(chan error, 1)
+		job := &flakyJob{id: "Job-X", failures: 5}
+		s.Require().NoError(scheduler.ScheduleOnce(ctx, time.Now(), job, WithRetry(1), noBackOff,
+			WithOnError(func(_ context.Context, jobID string, err error) {
+				failures <- errors.Wrap(err, jobID)
+			})))
+
+		scheduler.Start(ctx)
+		defer func() {
+			_ = scheduler.Stop(ctx)
+		}()
+
+		select {
+		case err := <-failures:
+			s.Assert().EqualError(err, "Job-X: unavailable")
+			s.Assert().EqualValues(2, job.runs.Load())
+		case <-time.After(time.Second):
+			s.T().Fatal("expected the error handler to be called")
+		}
+	})
+}
+
+func (s *schedulerTestSuite) TestJobLocker() {
+	ctx := context.TODO()
+
+	s.Run("with lock acquired", func() {
+		locker := &testLocker{acquired: true}
+		scheduler := NewJobsScheduler(WithJobLocker(locker))
+		job := &countingJob{id: "Job-X"}
+		s.Require().NoError(scheduler.ScheduleOnce(ctx, time.Now(), job))
+
+		scheduler.Start(ctx)
+		defer func() {
+			_ = scheduler.Stop(ctx)
+		}()
+
+		s.Assert().Eventually(func() bool { return locker.released.Load() == 1 }, time.Second, 10*time.Millisecond)
+		s.Assert().EqualValues(1, job.runs.Load())
+		s.Assert().Equal([]string{"Job-X"}, locker.jobIDs())
+	})
+	s.Run("with lock held by another instance", func() {
+		locker := &testLocker{}
+		scheduler := NewJobsScheduler(WithJobLocker(locker))
+		job := &countingJob{id: "Job-X"}
+		s.Require().NoError(scheduler.ScheduleOnce(ctx, time.Now(), job))
+
+		scheduler.Start(ctx)
+		defer func() {
+			_ = scheduler.Stop(ctx)
+		}()
+
+		s.Assert().Eventually(func() bool { return len(locker.jobIDs()) == 1 }, time.Second, 10*time.Millisecond)
+		s.Assert().Never(func() bool { return job.runs.Load() > 0 }, 100*time.Millisecond, 10*time.Millisecond)
+		s.Assert().Equal(RunStatusNone, scheduler.ListJobs(ctx)[0].LastStatus)
+	})
+	s.Run("with lock failure", func() {
+		locker := &testLocker{err: errors.New("connection refused")}
+		scheduler := NewJobsScheduler(WithJobLocker(locker))
+		failures := make(chan error, 1)
+		job := &countingJob{id: "Job-X"}
+		s.Require().NoError(scheduler.ScheduleOnce(ctx, time.Now(), job,
+			WithOnError(func(_ context.Context, _ string, err error) {
+				failures <- err
+			})))
+
+		scheduler.Start(ctx)
+		defer func() {
+			_ = scheduler.Stop(ctx)
+		}()
+
+		select {
+		case err := <-failures:
+			s.Assert().EqualError(err, "unable to acquire the lock of job (Job-X): connection refused")
+			s.Assert().Zero(job.runs.Load())
+		case <-time.After(time.Second):
+			s.T().Fatal("expected the error handler to be called")
+		}
+	})
+	s.Run("with RunNow", func() {
+		locker := &testLocker{}
+		scheduler := NewJobsScheduler(WithJobLocker(locker))
+		job := &countingJob{id: "Job-X"}
+		s.Require().NoError(scheduler.AddJob(ctx, "@daily", job))
+		s.Assert().NoError(scheduler.RunNow(ctx, job.ID()))
+		s.Assert().EqualValues(1, job.runs.Load())
+		s.Assert().Empty(locker.jobIDs())
+	})
+}
+
+func (s *schedulerTestSuite) TestHistory() {
+	ctx := context.TODO()
+
+	s.Run("with the most recent runs", func() {
+		scheduler := NewJobsScheduler(WithHistorySize(2))
+		job := &flakyJob{id: "Job-X", failures: 1}
+		s.Require().NoError(scheduler.AddJob(ctx, "@daily", job))
+
+		runs, err := scheduler.History(ctx, job.ID())
+		s.Require().NoError(err)
+		s.Assert().Empty(runs)
+
+		for range 3 {
+			_ = scheduler.RunNow(ctx, job.ID())
+		}
+
+		runs, err = scheduler.History(ctx, job.ID())
+		s.Require().NoError(err)
+		s.Require().Len(runs, 2)
+		for _, run := range runs {
+			s.Assert().Equal(RunStatusSucceeded, run.Status)
+			s.Assert().NoError(run.Error)
+			s.Assert().Equal(1, run.Attempts)
+		}
+		s.Assert().False(runs[1].StartedAt.Before(runs[0].StartedAt))
+	})
+	s.Run("with failed run", func() {
+		scheduler := NewJobsScheduler()
+		job := &flakyJob{id: "Job-X", failures: 5}
+		s.Require().NoError(scheduler.AddJob(ctx, "@daily", job, WithRetry(1),
+			WithBackOff(func() backoff.BackOff { return &backoff.ZeroBackOff{} })))
+		s.Assert().Error(scheduler.RunNow(ctx, job.ID()))
+
+		runs, err := scheduler.History(ctx, job.ID())
+		s.Require().NoError(err)
+		s.Require().Len(runs, 1)
+		s.Assert().Equal(RunStatusFailed, runs[0].Status)
+		s.Assert().EqualError(runs[0].Error, "unavailable")
+		s.Assert().Equal(2, runs[0].Attempts)
+	})
+	s.Run("with history disabled", func() {
+		scheduler := NewJobsScheduler(WithHistorySize(0))
+		job := &countingJob{id: "Job-X"}
+		s.Require().NoError(scheduler.ScheduleOnce(ctx, time.Now().Add(time.Hour), job))
+		s.Assert().NoError(scheduler.RunNow(ctx, job.ID()))
+
+		runs, err := scheduler.History(ctx, job.ID())
+		s.Require().NoError(err)
+		s.Assert().Empty(runs)
+	})
+	s.Run("with unknown job", func() {
+		_, err := NewJobsScheduler().History(ctx, "Job-X")
+		s.Assert().ErrorIs(err, ErrJobNotFound)
+	})
+}
+
+func (s *schedulerTestSuite) TestTelemetry() {
+	ctx := context.TODO()
+	reader := sdkmetric.NewManualReader()
+	recorder := tracetest.NewSpanRecorder()
+	scheduler := NewJobsScheduler(
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))),
+	)
+
+	job := &countingJob{id: "Job-X", err: errors.New("unavailable")}
+	s.Require().NoError(scheduler.AddJob(ctx, "@daily", job))
+	s.Assert().Error(scheduler.RunNow(ctx, job.ID()))
+
+	// a paused job skips its scheduled runs
+	s.Require().NoError(scheduler.Pause(ctx, job.ID()))
+	scheduler.runJob(ctx, scheduler.jobs[job.ID()])
+
+	var data metricdata.ResourceMetrics
+	s.Require().NoError(reader.Collect(ctx, &data))
+	s.Require().Len(data.ScopeMetrics, 1)
+
+	metrics := make(map[string]metricdata.Metrics)
+	for _, m := range data.ScopeMetrics[0].Metrics {
+		metrics[m.Name] = m
+	}
+
+	runs := metrics["scheduler.job.runs"].Data.(metricdata.Sum[int64]).DataPoints
+	s.Require().Len(runs, 1)
+	s.Assert().EqualValues(1, runs[0].Value)
+	status, _ := runs[0].Attributes.Value(jobStatusKey)
+	s.Assert().Equal("failed", status.AsString())
+
+	durations := metrics["scheduler.job.duration"].Data.(metricdata.Histogram[float64]).DataPoints
+	s.Require().Len(durations, 1)
+	s.Assert().EqualValues(1, durations[0].Count)
+
+	skipped := metrics["scheduler.job.skipped"].Data.(metricdata.Sum[int64]).DataPoints
+	s.Require().Len(skipped, 1)
+	reason, _ := skipped[0].Attributes.Value(skipReasonKey)
+	s.Assert().Equal(skipReasonPaused, reason.AsString())
+
+	spans := recorder.Ended()
+	s.Require().Len(spans, 1)
+	s.Assert().Equal("Run", spans[0].Name())
+	s.Assert().Equal("unavailable", spans[0].Status().Description)
+}
+
+func (s *schedulerTestSuite) TestConcurrencyPolicy() {
+	// start runs a scheduled run of the job in a separate go-routine
+	start := func(ctx context.Context, scheduler *JobsScheduler, entry *scheduledJob, wg *sync.WaitGroup) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			scheduler.executeScheduled(ctx, entry)
+		}()
+	}
+
+	s.Run("with forbid", func() {
+		ctx, cancel := context.WithCancel(context.TODO())
+		scheduler := NewJobsScheduler()
+		job := &blockingJob{id: "Job-X"}
+		s.Require().NoError(scheduler.AddJob(ctx, "@daily", job))
+		entry := scheduler.jobs[job.ID()]
+
+		wg := &sync.WaitGroup{}
+		start(ctx, scheduler, entry, wg)
+		s.Require().Eventually(func() bool { return job.runs.Load() == 1 }, time.Second, 10*time.Millisecond)
+
+		// the overlapping run is skipped
+		scheduler.executeScheduled(ctx, entry)
+		s.Assert().EqualValues(1, job.runs.Load())
+
+		cancel()
+		wg.Wait()
+
+		// the next run starts once the previous one is over
+		scheduler.executeScheduled(ctx, entry)
+		s.Assert().EqualValues(2, job.runs.Load())
+	})
+	s.Run("with allow", func() {
+		ctx, cancel := context.WithCancel(context.TODO())
+		scheduler := NewJobsScheduler()
+		job := &blockingJob{id: "Job-X"}
+		s.Require().NoError(scheduler.AddJob(ctx, "@daily", job, WithConcurrencyPolicy(ConcurrencyAllow)))
+		entry := scheduler.jobs[job.ID()]
+
+		wg := &sync.WaitGroup{}
+		start(ctx, scheduler, entry, wg)
+		start(ctx, scheduler, entry, wg)
+		s.Assert().Eventually(func() bool { return job.runs.Load() == 2 }, time.Second, 10*time.Millisecond)
+
+		cancel()
+		wg.Wait()
+	})
+	s.Run("with replace", func() {
+		ctx, cancel := context.WithCancel(context.TODO())
+		scheduler := NewJobsScheduler()
+		job := &blockingJob{id: "Job-X"}
+		s.Require().NoError(scheduler.AddJob(ctx, "@daily", job, WithConcurrencyPolicy(ConcurrencyReplace)))
+		entry := scheduler.jobs[job.ID()]
+
+		wg := &sync.WaitGroup{}
+		start(ctx, scheduler, entry, wg)
+		s.Require().Eventually(func() bool { return job.runs.Load() == 1 }, time.Second, 10*time.Millisecond)
+
+		// the new run cancels the previous one
+		start(ctx, scheduler, entry, wg)
+		s.Require().Eventually(func() bool { return job.runs.Load() == 2 }, time.Second, 10*time.Millisecond)
+
+		runs, err := scheduler.History(ctx, job.ID())
+		s.Require().NoError(err)
+		s.Require().Len(runs, 1)
+		s.Assert().ErrorIs(runs[0].Error, context.Canceled)
+
+		cancel()
+		wg.Wait()
+	})
+}
+
+func (s *schedulerTestSuite) TestJobStore() {
+	ctx := context.TODO()
+	now := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	const expr = "0 0 9 * * *"
+	cronState := JobState{
+		ID:             "Job-X",
+		Trigger:        TriggerCron,
+		CronExpression: expr,
+		Location:       "UTC",
+		NextRun:        now.Add(-3 * time.Hour),
+		LastRun:        now.Add(-27 * time.Hour),
+	}
+
+	s.Run("with missed cron run", func() {
+		store := NewMemoryJobStore()
+		s.Require().NoError(store.Save(ctx, cronState))
+
+		scheduler := NewJobsScheduler(WithClock(clock.NewFake(now)), WithJobStore(store))
+		job := &countingJob{id: "Job-X"}
+		s.Require().NoError(scheduler.AddJob(ctx, expr, job, WithMisfirePolicy(MisfireRunOnce)))
+		s.Assert().Equal(cronState.LastRun, scheduler.ListJobs(ctx)[0].LastRun)
+		s.Assert().Zero(job.runs.Load())
+
+		scheduler.Start(ctx)
+		defer func() {
+			_ = scheduler.Stop(ctx)
+		}()
+
+		s.Require().Eventually(func() bool {
+			state, err := store.Get(ctx, job.ID())
+			return err == nil && state.LastRun.Equal(now)
+		}, time.Second, 10*time.Millisecond)
+		s.Assert().EqualValues(1, job.runs.Load())
+
+		state, err := store.Get(ctx, job.ID())
+		s.Require().NoError(err)
+		s.Assert().True(time.Date(2024, time.March, 2, 9, 0, 0, 0, time.UTC).Equal(state.NextRun), state.NextRun)
+	})
+	s.Run("with skipped cron run", func() {
+		store := NewMemoryJobStore()
+		s.Require().NoError(store.Save(ctx, cronState))
+
+		scheduler := NewJobsScheduler(WithClock(clock.NewFake(now)), WithJobStore(store))
+		job := &countingJob{id: "Job-X"}
+		s.Require().NoError(scheduler.AddJob(ctx, expr, job))
+
+		scheduler.Start(ctx)
+		defer func() {
+			_ = scheduler.Stop(ctx)
+		}()
+
+		s.Assert().Never(func() bool { return job.runs.Load() > 0 }, 100*time.Millisecond, 10*time.Millisecond)
+		state, err := store.Get(ctx, job.ID())
+		s.Require().NoError(err)
+		s.Assert().True(time.Date(2024, time.March, 2, 9, 0, 0, 0, time.UTC).Equal(state.NextRun), state.NextRun)
+	})
+	s.Run("with fixed delay resumed", func() {
+		store := NewMemoryJobStore()
+		s.Require().NoError(store.Save(ctx, JobState{
+			ID:      "Job-X",
+			Trigger: TriggerFixedDelay,
+			Delay:   time.Minute,
+			NextRun: now.Add(5 * time.Second),
+		}))
+
+		fake := clock.NewFake(now)
+		scheduler := NewJobsScheduler(WithClock(fake), WithJobStore(store))
+		job := &countingJob{id: "Job-X"}
+		s.Require().NoError(scheduler.ScheduleWithFixedDelay(ctx, time.Minute, job))
+		scheduler.Start(ctx)
+		defer func() {
+			_ = scheduler.Stop(ctx)
+		}()
+
+		fake.BlockUntil(1)
+		fake.Advance(5 * time.Second)
+		s.Require().Eventually(func() bool {
+			state, err := store.Get(ctx, job.ID())
+			return err == nil && state.NextRun.Equal(now.Add(65*time.Second))
+		}, time.Second, 10*time.Millisecond)
+		s.Assert().EqualValues(1, job.runs.Load())
+	})
+	s.Run("with run once job already run", func() {
+		store := NewMemoryJobStore()
+		s.Require().NoError(store.Save(ctx, JobState{ID: "Job-X", Trigger: TriggerOnce, LastRun: now.Add(-time.Hour)}))
+
+		scheduler := NewJobsScheduler(WithClock(clock.NewFake(now)), WithJobStore(store))
+		job := &countingJob{id: "Job-X"}
+		s.Require().NoError(scheduler.ScheduleOnce(ctx, now.Add(-2*time.Hour), job))
+		scheduler.Start(ctx)
+		defer func() {
+			_ = scheduler.Stop(ctx)
+		}()
+
+		s.Assert().Never(func() bool { return job.runs.Load() > 0 }, 100*time.Millisecond, 10*time.Millisecond)
+		s.Assert().True(scheduler.ListJobs(ctx)[0].NextRun.IsZero())
+	})
+	s.Run("with paused job", func() {
+		store := NewMemoryJobStore()
+		scheduler := NewJobsScheduler(WithClock(clock.NewFake(now)), WithJobStore(store))
+		s.Require().NoError(scheduler.AddJob(ctx, expr, &countingJob{id: "Job-X"}))
+		s.Require().NoError(scheduler.Pause(ctx, "Job-X"))
+
+		// a restarted service restores the paused job
+		restarted := NewJobsScheduler(WithClock(clock.NewFake(now)), WithJobStore(store))
+		s.Require().NoError(restarted.AddJob(ctx, expr, &countingJob{id: "Job-X"}))
+		s.Assert().True(restarted.ListJobs(ctx)[0].Paused)
+	})
+	s.Run("with changed definition", func() {
+		store := NewMemoryJobStore()
+		s.Require().NoError(store.Save(ctx, JobState{ID: "Job-X", Trigger: TriggerCron, CronExpression: "@hourly", Location: "UTC", Paused: true}))
+
+		scheduler := NewJobsScheduler(WithClock(clock.NewFake(now)), WithJobStore(store))
+		s.Require().NoError(scheduler.AddJob(ctx, expr, &countingJob{id: "Job-X"}))
+		s.Assert().False(scheduler.ListJobs(ctx)[0].Paused)
+
+		state, err := store.Get(ctx, "Job-X")
+		s.Require().NoError(err)
+		s.Assert().Equal(expr, state.CronExpression)
+		s.Assert().False(state.Paused)
+	})
+	s.Run("with slow job store", func() {
+		store := &blockingJobStore{MemoryJobStore: NewMemoryJobStore(), release: make(chan struct{})}
+		scheduler := NewJobsScheduler(WithJobStore(store))
+
+		added := make(chan error, 1)
+		go func() {
+			added <- scheduler.ScheduleWithFixedDelay(ctx, time.Minute, &countingJob{id: "Job-X"})
+		}()
+
+		// the scheduler is not locked while the state of the job is loaded
+		s.Require().Eventually(func() bool { return store.blocked.Load() }, time.Second, 10*time.Millisecond)
+		s.Assert().Empty(scheduler.ListJobs(ctx))
+
+		close(store.release)
+		s.Require().NoError(<-added)
+		s.Assert().Len(scheduler.ListJobs(ctx), 1)
+		s.Assert().Error(scheduler.ScheduleWithFixedDelay(ctx, time.Minute, &countingJob{id: "Job-X"}))
+	})
+	s.Run("with unscheduled job", func() {
+		store := NewMemoryJobStore()
+		scheduler := NewJobsScheduler(WithJobStore(store))
+		s.Require().NoError(scheduler.AddJob(ctx, expr, &countingJob{id: "Job-X"}))
+		s.Require().NoError(scheduler.Unschedule(ctx, "Job-X"))
+
+		_, err := store.Get(ctx, "Job-X")
+		s.Assert().ErrorIs(err, ErrJobStateNotFound)
+	})
+}
+
+func (s *schedulerTestSuite) TestJitterAndSpread() {
+	ctx := context.TODO()
+	now := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	s.Run("with jitter", func() {
+		fake := clock.NewFake(now)
+		scheduler := NewJobsScheduler(WithClock(fake))
+		scheduler.random = func(n int64) int64 { return n / 2 }
+		job := &countingJob{id: "Job-X"}
+		s.Require().NoError(scheduler.AddJob(ctx, "@daily", job, WithJitter(10*time.Second)))
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			scheduler.executeScheduled(ctx, scheduler.jobs[job.ID()])
+		}()
+
+		fake.BlockUntil(1)
+		s.Assert().Zero(job.runs.Load())
+		fake.Advance(5 * time.Second)
+		<-done
+		s.Assert().EqualValues(1, job.runs.Load())
+
+		// RunNow is not delayed
+		s.Assert().NoError(scheduler.RunNow(ctx, job.ID()))
+		s.Assert().EqualValues(2, job.runs.Load())
+	})
+	s.Run("with spread", func() {
+		fake := clock.NewFake(now)
+		scheduler := NewJobsScheduler(WithClock(fake), WithSpread(time.Minute))
+		jobA := &countingJob{id: "Job-A"}
+		jobB := &countingJob{id: "Job-B"}
+		s.Require().NoError(scheduler.AddJob(ctx, "@daily", jobA))
+		s.Require().NoError(scheduler.AddJob(ctx, "@daily", jobB))
+
+		offsetA := scheduler.startDelay(scheduler.jobs[jobA.ID()])
+		offsetB := scheduler.startDelay(scheduler.jobs[jobB.ID()])
+		s.Assert().Less(offsetA, time.Minute)
+		s.Assert().Less(offsetB, time.Minute)
+		s.Assert().NotEqual(offsetA, offsetB)
+		// the offset is stable
+		s.Assert().Equal(offsetA, scheduler.startDelay(scheduler.jobs[jobA.ID()]))
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			scheduler.executeScheduled(ctx, scheduler.jobs[jobA.ID()])
+		}()
+
+		fake.BlockUntil(1)
+		s.Assert().Zero(jobA.runs.Load())
+		fake.Advance(offsetA)
+		<-done
+		s.Assert().EqualValues(1, jobA.runs.Load())
+	})
+}
+
+func (s *schedulerTestSuite) TestScheduleAfter() {
+	ctx := context.TODO()
+
+	s.Run("with chain", func() {
+		scheduler := NewJobsScheduler()
+		jobA := &countingJob{id: "Job-A"}
+		jobB := &countingJob{id: "Job-B"}
+		jobC := &countingJob{id: "Job-C"}
+		jobD := &countingJob{id: "Job-D"}
+
+		// the upstream jobs can be added after their dependents
+		s.Require().NoError(scheduler.ScheduleAfter(ctx, jobB, []string{jobA.ID()}))
+		s.Require().NoError(scheduler.ScheduleAfter(ctx, jobC, []string{jobB.ID()}))
+		s.Require().NoError(scheduler.ScheduleAfter(ctx, jobD, []string{jobA.ID(), jobB.ID()}))
+		s.Require().NoError(scheduler.ScheduleOnce(ctx, time.Now(), jobA))
+
+		chain, err := scheduler.Chain(ctx, jobA.ID())
+		s.Require().NoError(err)
+		s.Assert().Equal(RunStatusNone, chain.Status)
+
+		scheduler.Start(ctx)
+		defer func() {
+			_ = scheduler.Stop(ctx)
+		}()
+
+		s.Require().Eventually(func() bool {
+			chain, err := scheduler.Chain(ctx, jobA.ID())
+			return err == nil && chain.Status == RunStatusSucceeded
+		}, time.Second, 10*time.Millisecond)
+
+		chain, err = scheduler.Chain(ctx, jobA.ID())
+		s.Require().NoError(err)
+		ids := make([]string, 0, len(chain.Jobs))
+		for _, info := range chain.Jobs {
+			ids = append(ids, info.ID)
+		}
+		s.Assert().Equal([]string{"Job-A", "Job-B", "Job-C", "Job-D"}, ids)
+		s.Assert().Equal(TriggerDependency, chain.Jobs[3].Trigger)
+		s.Assert().Equal([]string{"Job-A", "Job-B"}, chain.Jobs[3].DependsOn)
+
+		// the join runs once both upstream jobs have succeeded
+		s.Assert().Never(func() bool { return jobD.runs.Load() > 1 }, 100*time.Millisecond, 10*time.Millisecond)
+		s.Assert().EqualValues(1, jobC.runs.Load())
+	})
+	s.Run("with failed upstream", func() {
+		scheduler := NewJobsScheduler()
+		jobA := &countingJob{id: "Job-A", err: errors.New("unavailable")}
+		jobB := &countingJob{id: "Job-B"}
+		s.Require().NoError(scheduler.ScheduleOnce(ctx, time.Now(), jobA))
+		s.Require().NoError(scheduler.ScheduleAfter(ctx, jobB, []string{jobA.ID()}))
+
+		scheduler.Start(ctx)
+		defer func() {
+			_ = scheduler.Stop(ctx)
+		}()
+
+		s.Require().Eventually(func() bool {
+			chain, err := scheduler.Chain(ctx, jobA.ID())
+			return err == nil && chain.Status == RunStatusFailed
+		}, time.Second, 10*time.Millisecond)
+		s.Assert().Never(func() bool { return jobB.runs.Load() > 0 }, 100*time.Millisecond, 10*time.Millisecond)
+	})
+	s.Run("with cycle", func() {
+		scheduler := NewJobsScheduler()
+		s.Require().NoError(scheduler.ScheduleAfter(ctx, &countingJob{id: "Job-B"}, []string{"Job-A"}))
+		s.Require().NoError(scheduler.ScheduleAfter(ctx, &countingJob{id: "Job-C"}, []string{"Job-B"}))
+
+		err := scheduler.ScheduleAfter(ctx, &countingJob{id: "Job-A"}, []string{"Job-C"})
+		s.Assert().ErrorIs(err, ErrDependencyCycle)
+		s.Assert().EqualError(err, "job (Job-A): Job-A -> Job-C -> Job-B -> Job-A: dependency cycle")
+
+		err = scheduler.ScheduleAfter(ctx, &countingJob{id: "Job-D"}, []string{"Job-D"})
+		s.Assert().ErrorIs(err, ErrDependencyCycle)
+		s.Assert().Len(scheduler.ListJobs(ctx), 2)
+	})
+	s.Run("with invalid dependencies", func() {
+		scheduler := NewJobsScheduler()
+		err := scheduler.ScheduleAfter(ctx, &countingJob{id: "Job-A"}, nil)
+		s.Assert().EqualError(err, "the upstream jobs are required")
+
+		_, err = scheduler.Chain(ctx, "Job-A")
+		s.Assert().ErrorIs(err, ErrJobNotFound)
+	})
+}
+
+// testLocker is a JobLocker granting or refusing every lock
+type testLocker struct {
+	mu       sync.Mutex
+	acquired bool
+	err      error
+	locked   []string
+	released atomic.Int32
+}
+
+func (l *testLocker) TryLock(_ context.Context, jobID string) (func(), bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.locked = append(l.locked, jobID)
+	if l.err != nil || !l.acquired {
+		return nil, false, l.err
+	}
+	return func() { l.released.Add(1) }, true, nil
+}
+
+func (l *testLocker) jobIDs() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.locked...)
+}
+
+// blockingJob runs until its context is done
+type blockingJob struct {
+	id   string
+	runs atomic.Int32
+}
+
+func (j *blockingJob) ID() string {
+	return j.id
+}
+
+func (j *blockingJob) Run(ctx context.Context) error {
+	j.runs.Add(1)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// panickingJob panics when run
+type panickingJob struct {
+	id string
+}
+
+func (j *panickingJob) ID() string {
+	return j.id
+}
+
+func (j *panickingJob) Run(context.Context) error {
+	panic("boom")
+}
+
 // utility function
 func wait(wg *sync.WaitGroup) chan bool {
 	ch := make(chan bool)
@@ -312,3 +1205,16 @@ func wait(wg *sync.WaitGroup) chan bool {
 	}()
 	return ch
 }
+
+// blockingJobStore is a JobStore whose Get blocks until it is released
+type blockingJobStore struct {
+	*MemoryJobStore
+	blocked atomic.Bool
+	release chan struct{}
+}
+
+func (s *blockingJobStore) Get(ctx context.Context, jobID string) (*JobState, error) {
+	s.blocked.Store(true)
+	<-s.release
+	return s.MemoryJobStore.Get(ctx, jobID)
+}
@@ -0,0 +1,118 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrJobStateNotFound is returned by a JobStore when the state of a job has not been saved
+var ErrJobStateNotFound = errors.New("job state not found")
+
+// JobState is the persisted state of a scheduled job. The job itself is code and is added again
+// by the restarted service: its state is restored when the definition of the job has not changed.
+type JobState struct {
+	// ID is the job unique identifier
+	ID string
+	// Trigger defines when the job runs
+	Trigger Trigger
+	// CronExpression is the schedule of the TriggerCron jobs
+	CronExpression string
+	// Location is the name of the timezone evaluating the cron expression of the TriggerCron jobs
+	Location string
+	// Delay is the delay between the runs of the TriggerFixedDelay jobs
+	Delay time.Duration
+	// NextRun is the time of the next run. It is zero when the TriggerOnce job has already run.
+	NextRun time.Time
+	// LastRun is the start time of the last run. It is zero when the job has not run yet.
+	LastRun time.Time
+	// Paused is true when the job runs are skipped
+	Paused bool
+}
+
+// sameDefinition returns true when both states describe the same schedule
+func (s JobState) sameDefinition(other JobState) bool {
+	return s.Trigger == other.Trigger &&
+		s.CronExpression == other.CronExpression &&
+		s.Location == other.Location &&
+		s.Delay == other.Delay
+}
+
+// JobStore persists the state of the scheduled jobs so that a restarted service resumes their schedules
+type JobStore interface {
+	// Save creates or replaces the state of a job
+	Save(ctx context.Context, state JobState) error
+	// Get returns the state of the given job or ErrJobStateNotFound
+	Get(ctx context.Context, jobID string) (*JobState, error)
+	// Delete removes the state of the given job
+	Delete(ctx context.Context, jobID string) error
+}
+
+// MemoryJobStore is an in-memory JobStore. It is suitable for tests and does not survive a restart.
+type MemoryJobStore struct {
+	mu     sync.Mutex
+	states map[string]JobState
+}
+
+// enforce compilation error
+var _ JobStore = (*MemoryJobStore)(nil)
+
+// NewMemoryJobStore creates an instance of MemoryJobStore
+func NewMemoryJobStore() *MemoryJobStore {
+	return &MemoryJobStore{
+		states: make(map[string]JobState),
+	}
+}
+
+// Save creates or replaces the state of a job
+func (s *MemoryJobStore) Save(_ context.Context, state JobState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.states[state.ID] = state
+	return nil
+}
+
+// Get returns the state of the given job or ErrJobStateNotFound
+func (s *MemoryJobStore) Get(_ context.Context, jobID string) (*JobState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state, ok := s.states[jobID]
+	if !ok {
+		return nil, ErrJobStateNotFound
+	}
+	return &state, nil
+}
+
+// Delete removes the state of the given job
+func (s *MemoryJobStore) Delete(_ context.Context, jobID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.states, jobID)
+	return nil
+}
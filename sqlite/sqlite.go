@@ -0,0 +1,210 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package sqlite
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/XSAM/otelsql"
+	"github.com/georgysavva/scany/v2/sqlscan"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	semconv "go.opentelemetry.io/otel/semconv/v1.20.0"
+	_ "modernc.org/sqlite" //nolint
+)
+
+// SQLite will be implemented by concrete RDBMS store
+type SQLite interface {
+	// Connect connects to the underlying database
+	Connect(ctx context.Context) error
+	// Disconnect closes the underlying opened underlying connection database
+	Disconnect(ctx context.Context) error
+	// Select fetches a single row from the database and automatically scanned it into the dst.
+	// It returns an error in case of failure. When there is no record no errors is return.
+	Select(ctx context.Context, dst any, query string, args ...any) error
+	// SelectAll fetches a set of rows as defined by the query and scanned those record in the dst.
+	// It returns nil when there is no records to fetch.
+	SelectAll(ctx context.Context, dst any, query string, args ...any) error
+	// Exec executes an SQL statement against the database and returns the appropriate result or an error.
+	Exec(ctx context.Context, query string, args ...any) (sql.Result, error)
+	// BeginTx helps start an SQL transaction. The return transaction object is expected to be used in
+	// the subsequent queries following the BeginTx.
+	BeginTx(ctx context.Context, txOptions *sql.TxOptions) (*sql.Tx, error)
+}
+
+// sqliteStore helps interact with the SQLite database
+type sqliteStore struct {
+	dsn          string
+	dbConnection *sql.DB
+	config       *Config
+	// keepAlive holds a connection to the in-memory database
+	// so that it is not dropped when the pool closes its idle connections
+	keepAlive *sql.Conn
+}
+
+var _ SQLite = (*sqliteStore)(nil)
+
+const sqliteDriver = "sqlite"
+const instrumentationName = "github.com.tochemey.gopack.sqlite"
+
+// ErrInMemoryMaxOpenConnections is returned on Connect when an in-memory database is limited to a single open connection
+var ErrInMemoryMaxOpenConnections = errors.New("an in-memory database requires MaxOpenConnections to be 0 or at least 2")
+
+// New returns a store connecting to the given SQLite database.
+// The SQLite driver is written in pure Go and does not require cgo.
+// An in-memory database holds one of the pool connections, so MaxOpenConnections must then be 0 or at least 2,
+// otherwise Connect returns ErrInMemoryMaxOpenConnections.
+func New(config *Config) SQLite {
+	store := new(sqliteStore)
+	store.config = config
+	store.dsn = createDSN(config.DBPath)
+	return store
+}
+
+// Connect will connect to our SQLite database
+func (s *sqliteStore) Connect(ctx context.Context) error {
+	// the in-memory database connection kept alive would take the single pool slot
+	if s.config.DBPath == "" && s.config.MaxOpenConnections == 1 {
+		return ErrInMemoryMaxOpenConnections
+	}
+
+	// Register an OTel driver
+	driverName, err := otelsql.Register(sqliteDriver, otelsql.WithAttributes(semconv.DBSystemSqlite))
+	if err != nil {
+		return errors.Wrap(err, "failed to hook the tracer to the database driver")
+	}
+
+	// open the connection and connect to the database
+	db, err := sql.Open(driverName, s.dsn)
+	if err != nil {
+		return errors.Wrap(err, "failed to open connection")
+	}
+
+	// let us test the connection
+	err = db.PingContext(ctx)
+	if err != nil {
+		_ = db.Close()
+		return errors.Wrap(err, "failed to ping database connection")
+	}
+
+	// set connection setting
+	db.SetMaxOpenConns(s.config.MaxOpenConnections)
+	db.SetMaxIdleConns(s.config.MaxIdleConnections)
+	db.SetConnMaxLifetime(s.config.ConnectionMaxLifetime)
+
+	if s.config.DBPath == "" {
+		if s.keepAlive, err = db.Conn(ctx); err != nil {
+			_ = db.Close()
+			return errors.Wrap(err, "failed to open the in-memory database connection")
+		}
+	}
+
+	// set the db handle
+	s.dbConnection = db
+	return nil
+}
+
+// createDSN will create the SQLite data source name of the given database path.
+// Every in-memory database gets a unique name shared by the connections of the pool.
+// The in-memory database is dropped on Disconnect.
+func createDSN(path string) string {
+	pragmas := "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"
+	if path != "" {
+		return fmt.Sprintf("file:%s?%s", path, pragmas)
+	}
+
+	suffix := make([]byte, 8)
+	_, _ = rand.Read(suffix)
+	return fmt.Sprintf("file:memdb_%s?mode=memory&cache=shared&%s", hex.EncodeToString(suffix), pragmas)
+}
+
+// Exec executes a sql query without returning rows against the database
+func (s *sqliteStore) Exec(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	// Create a span
+	tracer := otel.GetTracerProvider()
+	spanCtx, span := tracer.Tracer(instrumentationName).Start(ctx, "Exec")
+	defer span.End()
+	return s.dbConnection.ExecContext(spanCtx, query, args...)
+}
+
+// BeginTx starts a new database transaction
+func (s *sqliteStore) BeginTx(ctx context.Context, txOptions *sql.TxOptions) (*sql.Tx, error) {
+	// Create a span
+	tracer := otel.GetTracerProvider()
+	spanCtx, span := tracer.Tracer(instrumentationName).Start(ctx, "BeginTx")
+	defer span.End()
+	return s.dbConnection.BeginTx(spanCtx, txOptions)
+}
+
+// SelectAll fetches rows
+func (s *sqliteStore) SelectAll(ctx context.Context, dst any, query string, args ...any) error {
+	// Create a span
+	tracer := otel.GetTracerProvider()
+	spanCtx, span := tracer.Tracer(instrumentationName).Start(ctx, "SelectAll")
+	defer span.End()
+	err := sqlscan.Select(spanCtx, s.dbConnection, dst, query, args...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// Select fetches only one row
+func (s *sqliteStore) Select(ctx context.Context, dst any, query string, args ...any) error {
+	// Create a span
+	tracer := otel.GetTracerProvider()
+	spanCtx, span := tracer.Tracer(instrumentationName).Start(ctx, "Select")
+	defer span.End()
+	err := sqlscan.Get(spanCtx, s.dbConnection, dst, query, args...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// Disconnect the database connection.
+func (s *sqliteStore) Disconnect(ctx context.Context) error {
+	tracer := otel.GetTracerProvider()
+	_, span := tracer.Tracer(instrumentationName).Start(ctx, "Disconnect")
+	defer span.End()
+	if s.dbConnection == nil {
+		return nil
+	}
+
+	if s.keepAlive != nil {
+		_ = s.keepAlive.Close()
+	}
+	return s.dbConnection.Close()
+}
@@ -0,0 +1,160 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// account is a test struct
+type account struct {
+	AccountID   string
+	AccountName string
+}
+
+func TestSQLite(t *testing.T) {
+	ctx := context.TODO()
+
+	t.Run("with queries", func(t *testing.T) {
+		db := NewTestDB()
+		require.NoError(t, db.Connect(ctx))
+		require.NoError(t, createTable(ctx, db))
+		require.NoError(t, db.TableExists(ctx, "accounts"))
+
+		inserted := &account{AccountID: uuid.New().String(), AccountName: "some-account"}
+		require.NoError(t, insertInto(ctx, db, inserted))
+
+		selected := new(account)
+		err := db.Select(ctx, selected, `SELECT account_id, account_name FROM accounts WHERE account_id = ?;`, inserted.AccountID)
+		require.NoError(t, err)
+		assert.Equal(t, inserted, selected)
+
+		// no error is returned when there is no record
+		selected = new(account)
+		err = db.Select(ctx, selected, `SELECT account_id, account_name FROM accounts WHERE account_id = ?;`, "unknown")
+		require.NoError(t, err)
+		assert.Empty(t, selected.AccountID)
+
+		require.NoError(t, insertInto(ctx, db, &account{AccountID: uuid.New().String(), AccountName: "other-account"}))
+		var accounts []*account
+		require.NoError(t, db.SelectAll(ctx, &accounts, `SELECT account_id, account_name FROM accounts;`))
+		assert.Len(t, accounts, 2)
+
+		require.NoError(t, db.DropTable(ctx, "accounts"))
+		assert.EqualError(t, db.TableExists(ctx, "accounts"), "table accounts does not exist")
+		require.NoError(t, db.Disconnect(ctx))
+	})
+
+	t.Run("with transactions", func(t *testing.T) {
+		db := NewTestDB()
+		require.NoError(t, db.Connect(ctx))
+		require.NoError(t, createTable(ctx, db))
+
+		tx, err := db.BeginTx(ctx, nil)
+		require.NoError(t, err)
+		_, err = tx.ExecContext(ctx, `INSERT INTO accounts(account_id, account_name) VALUES(?, ?);`, uuid.New().String(), "rolled-back")
+		require.NoError(t, err)
+		require.NoError(t, tx.Rollback())
+
+		tx, err = db.BeginTx(ctx, nil)
+		require.NoError(t, err)
+		_, err = tx.ExecContext(ctx, `INSERT INTO accounts(account_id, account_name) VALUES(?, ?);`, uuid.New().String(), "committed")
+		require.NoError(t, err)
+		require.NoError(t, tx.Commit())
+
+		count, err := db.Count(ctx, "accounts")
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+		require.NoError(t, db.Disconnect(ctx))
+	})
+
+	t.Run("with isolated in-memory databases", func(t *testing.T) {
+		first := NewTestDB()
+		second := NewTestDB()
+		require.NoError(t, first.Connect(ctx))
+		require.NoError(t, second.Connect(ctx))
+
+		require.NoError(t, createTable(ctx, first))
+		assert.Error(t, second.TableExists(ctx, "accounts"))
+
+		require.NoError(t, first.Disconnect(ctx))
+		require.NoError(t, second.Disconnect(ctx))
+	})
+
+	t.Run("with single connection in-memory database", func(t *testing.T) {
+		db := New(&Config{MaxOpenConnections: 1})
+		assert.ErrorIs(t, db.Connect(ctx), ErrInMemoryMaxOpenConnections)
+	})
+
+	t.Run("with database file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "test.db")
+		db := &TestDB{New(&Config{DBPath: path})}
+		require.NoError(t, db.Connect(ctx))
+		require.NoError(t, createTable(ctx, db))
+		require.NoError(t, insertInto(ctx, db, &account{AccountID: uuid.New().String(), AccountName: "some-account"}))
+		require.NoError(t, db.Disconnect(ctx))
+
+		// the data is persisted
+		db = &TestDB{New(&Config{DBPath: path})}
+		require.NoError(t, db.Connect(ctx))
+		count, err := db.Count(ctx, "accounts")
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+		require.NoError(t, db.Disconnect(ctx))
+	})
+
+	t.Run("with closed connection", func(t *testing.T) {
+		db := NewTestDB()
+		require.NoError(t, db.Connect(ctx))
+		require.NoError(t, db.Disconnect(ctx))
+
+		_, err := db.Exec(ctx, "SELECT 1;")
+		assert.EqualError(t, err, "sql: database is closed")
+	})
+}
+
+func createTable(ctx context.Context, db SQLite) error {
+	const schemaDDL = `
+		CREATE TABLE IF NOT EXISTS accounts
+		(
+			account_id		TEXT NOT NULL PRIMARY KEY,
+			account_name	TEXT NOT NULL
+		);
+	`
+	_, err := db.Exec(ctx, schemaDDL)
+	return err
+}
+
+func insertInto(ctx context.Context, db SQLite, account *account) error {
+	const insertSQL = `INSERT INTO accounts(account_id, account_name) VALUES(?, ?);`
+	_, err := db.Exec(ctx, insertSQL, account.AccountID, account.AccountName)
+	return err
+}
@@ -0,0 +1,75 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package sqlite
+
+import (
+	"context"
+	"fmt"
+)
+
+// TestDB is used in test to perform
+// some database queries
+type TestDB struct {
+	SQLite
+}
+
+// NewTestDB creates a TestDB backed by a new in-memory database.
+// Unlike the Postgres and MySQL testkits, it does not require docker.
+// Call Connect before running queries and Disconnect to drop the database.
+func NewTestDB() *TestDB {
+	return &TestDB{New(new(Config))}
+}
+
+// DropTable utility function to drop a database table
+func (c TestDB) DropTable(ctx context.Context, tableName string) error {
+	var dropSQL = fmt.Sprintf("DROP TABLE IF EXISTS %s;", tableName)
+	_, err := c.Exec(ctx, dropSQL)
+	return err
+}
+
+// TableExists utility function to help check the existence of table in SQLite
+// It returns an error when the table does not exist
+func (c TestDB) TableExists(ctx context.Context, tableName string) error {
+	var count int
+	const stmt = "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?;"
+	if err := c.Select(ctx, &count, stmt, tableName); err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return fmt.Errorf("table %s does not exist", tableName)
+	}
+	return nil
+}
+
+// Count utility function to help count the number of rows in a SQLite table.
+// It returns -1 when there is an error
+func (c TestDB) Count(ctx context.Context, tableName string) (int, error) {
+	var count int
+	if err := c.Select(ctx, &count, fmt.Sprintf("SELECT COUNT(*) FROM %s", tableName)); err != nil {
+		return -1, err
+	}
+	return count, nil
+}
@@ -0,0 +1,137 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package supervisor
+
+import (
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+
+	"github.com/tochemey/gopack/clock"
+)
+
+const (
+	// defaultInitialInterval is the default delay before the first restart
+	defaultInitialInterval = 100 * time.Millisecond
+	// defaultMaxInterval is the default maximum delay between restarts
+	defaultMaxInterval = 10 * time.Second
+	// defaultBackOffResetAfter is the default run duration after which the backoff is reset
+	defaultBackOffResetAfter = defaultMaxInterval
+)
+
+// workerConfig defines a worker supervision settings
+type workerConfig struct {
+	policy      RestartPolicy
+	maxRestarts int
+	newBackOff  func() backoff.BackOff
+	resetAfter  time.Duration
+	clock       clock.Clock
+}
+
+// newWorkerConfig creates the default worker config
+func newWorkerConfig() *workerConfig {
+	return &workerConfig{
+		policy:      RestartOnFailure,
+		maxRestarts: -1,
+		newBackOff: func() backoff.BackOff {
+			return NewExponentialBackOff(defaultInitialInterval, defaultMaxInterval)
+		},
+		resetAfter: defaultBackOffResetAfter,
+		clock:      clock.New(),
+	}
+}
+
+// Option is the interface that applies a worker configuration option.
+type Option interface {
+	// Apply sets the Option value of a config.
+	Apply(*workerConfig)
+}
+
+var _ Option = OptionFunc(nil)
+
+// OptionFunc implements the Option interface.
+type OptionFunc func(*workerConfig)
+
+// Apply applies the option
+func (f OptionFunc) Apply(c *workerConfig) {
+	f(c)
+}
+
+// WithRestartPolicy sets the worker restart policy.
+// The default policy is RestartOnFailure
+func WithRestartPolicy(policy RestartPolicy) Option {
+	return OptionFunc(func(c *workerConfig) {
+		c.policy = policy
+	})
+}
+
+// WithMaxRestarts sets the maximum number of restarts of the worker.
+// Once reached the worker is marked as failed. A negative value means unlimited restarts.
+func WithMaxRestarts(maxRestarts int) Option {
+	return OptionFunc(func(c *workerConfig) {
+		c.maxRestarts = maxRestarts
+	})
+}
+
+// WithBackOff sets the function creating the backoff used to compute the delay between restarts.
+// Every worker gets its own backoff. The worker is marked as failed when the backoff returns backoff.Stop
+func WithBackOff(newBackOff func() backoff.BackOff) Option {
+	return OptionFunc(func(c *workerConfig) {
+		c.newBackOff = newBackOff
+	})
+}
+
+// WithBackOffResetAfter sets the run duration after which the worker is considered stable
+// and its backoff is reset, so that its next restart happens after the initial delay.
+// The default duration is the default maximum delay between restarts.
+func WithBackOffResetAfter(duration time.Duration) Option {
+	return OptionFunc(func(c *workerConfig) {
+		c.resetAfter = duration
+	})
+}
+
+// WithClock sets the clock used to wait between restarts.
+// This is mainly useful in tests with a clock.Fake
+func WithClock(clock clock.Clock) Option {
+	return OptionFunc(func(c *workerConfig) {
+		c.clock = clock
+	})
+}
+
+// WithConstantBackOff restarts the worker after a fixed delay
+func WithConstantBackOff(delay time.Duration) Option {
+	return WithBackOff(func() backoff.BackOff {
+		return backoff.NewConstantBackOff(delay)
+	})
+}
+
+// NewExponentialBackOff creates an exponential backoff that never gives up
+func NewExponentialBackOff(initialInterval, maxInterval time.Duration) backoff.BackOff {
+	bo := backoff.NewExponentialBackOff()
+	bo.InitialInterval = initialInterval
+	bo.MaxInterval = maxInterval
+	bo.MaxElapsedTime = 0
+	return bo
+}
@@ -0,0 +1,380 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+// Package supervisor runs named long-running go-routines, captures their panics,
+// restarts them according to a restart policy and shuts them down collectively.
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+)
+
+var (
+	// ErrAlreadyStarted is returned when the supervisor is started more than once
+	ErrAlreadyStarted = errors.New("supervisor is already started")
+	// ErrNotStarted is returned when the supervisor is stopped before being started
+	ErrNotStarted = errors.New("supervisor is not started")
+)
+
+// Worker is a long-running function supervised by the Supervisor.
+// The worker is expected to return when the given context is done.
+type Worker func(ctx context.Context) error
+
+// RestartPolicy defines when a worker is restarted after it has returned
+type RestartPolicy int
+
+const (
+	// RestartOnFailure restarts the worker only when it returns an error or panics
+	RestartOnFailure RestartPolicy = iota
+	// RestartAlways restarts the worker whenever it returns
+	RestartAlways
+	// RestartNever never restarts the worker
+	RestartNever
+)
+
+// String returns the string representation of the restart policy
+func (p RestartPolicy) String() string {
+	switch p {
+	case RestartOnFailure:
+		return "on-failure"
+	case RestartAlways:
+		return "always"
+	case RestartNever:
+		return "never"
+	default:
+		return "unknown"
+	}
+}
+
+// State defines a worker state
+type State int
+
+const (
+	// StateIdle means the worker has not been started yet
+	StateIdle State = iota
+	// StateRunning means the worker is running
+	StateRunning
+	// StateRestarting means the worker is waiting to be restarted
+	StateRestarting
+	// StateStopped means the worker has returned and will not be restarted
+	StateStopped
+	// StateFailed means the worker has failed and will not be restarted
+	StateFailed
+)
+
+// String returns the string representation of the state
+func (s State) String() string {
+	switch s {
+	case StateIdle:
+		return "idle"
+	case StateRunning:
+		return "running"
+	case StateRestarting:
+		return "restarting"
+	case StateStopped:
+		return "stopped"
+	case StateFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// Status is a point-in-time view of a supervised worker
+type Status struct {
+	// Name is the worker name
+	Name string
+	// State is the worker current state
+	State State
+	// Restarts is the number of time the worker has been restarted
+	Restarts int
+	// LastError is the last error returned by the worker or the recovered panic
+	LastError error
+	// StartedAt is the time the worker has been last started
+	StartedAt time.Time
+}
+
+// PanicError wraps a panic recovered from a worker
+type PanicError struct {
+	// Value is the recovered panic value
+	Value any
+	// Stack is the stack trace captured when the panic was recovered
+	Stack []byte
+}
+
+// Error implements the error interface
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Supervisor manages a set of named workers
+type Supervisor struct {
+	mu      sync.RWMutex
+	workers map[string]*supervised
+	order   []string
+
+	started bool
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// supervised holds a worker and its supervision state
+type supervised struct {
+	name   string
+	worker Worker
+	config *workerConfig
+
+	mu     sync.RWMutex
+	status Status
+}
+
+// New creates an instance of Supervisor
+func New() *Supervisor {
+	return &Supervisor{
+		workers: make(map[string]*supervised),
+	}
+}
+
+// Add registers a named worker. The worker name must be unique.
+// When the supervisor is already started the worker is started immediately.
+func (s *Supervisor) Add(name string, worker Worker, opts ...Option) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.workers[name]; ok {
+		return fmt.Errorf("worker (%s) is already added", name)
+	}
+
+	config := newWorkerConfig()
+	for _, opt := range opts {
+		opt.Apply(config)
+	}
+
+	w := &supervised{
+		name:   name,
+		worker: worker,
+		config: config,
+		status: Status{Name: name, State: StateIdle},
+	}
+
+	s.workers[name] = w
+	s.order = append(s.order, name)
+	if s.started {
+		s.launch(s.ctx, w)
+	}
+	return nil
+}
+
+// Start starts all the registered workers in their separate go-routine.
+// The workers are stopped when the given context is done or Stop is called.
+func (s *Supervisor) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return ErrAlreadyStarted
+	}
+
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	s.started = true
+	for _, name := range s.order {
+		s.launch(s.ctx, s.workers[name])
+	}
+	return nil
+}
+
+// Stop cancels all the workers and waits for them to return.
+// It returns the context error when the given context is done before all the workers have returned.
+func (s *Supervisor) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.started {
+		s.mu.Unlock()
+		return ErrNotStarted
+	}
+	s.started = false
+	s.cancel()
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Status returns the status of the given worker
+func (s *Supervisor) Status(name string) (Status, bool) {
+	s.mu.RLock()
+	w, ok := s.workers[name]
+	s.mu.RUnlock()
+	if !ok {
+		return Status{}, false
+	}
+	return w.getStatus(), true
+}
+
+// Statuses returns the status of all the workers in their registration order
+func (s *Supervisor) Statuses() []Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	statuses := make([]Status, 0, len(s.order))
+	for _, name := range s.order {
+		statuses = append(statuses, s.workers[name].getStatus())
+	}
+	return statuses
+}
+
+// Healthy returns true when none of the workers has failed
+func (s *Supervisor) Healthy() bool {
+	for _, status := range s.Statuses() {
+		if status.State == StateFailed {
+			return false
+		}
+	}
+	return true
+}
+
+// launch runs the worker supervision loop in a separate go-routine
+func (s *Supervisor) launch(ctx context.Context, w *supervised) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		w.supervise(ctx)
+	}()
+}
+
+// supervise runs the worker and restarts it according to its restart policy
+func (w *supervised) supervise(ctx context.Context) {
+	bo := w.config.newBackOff()
+	bo.Reset()
+
+	for {
+		w.setRunning()
+		startedAt := w.config.clock.Now()
+		err := w.run(ctx)
+
+		// the supervisor is shutting down
+		if ctx.Err() != nil {
+			w.setState(StateStopped, err)
+			return
+		}
+
+		if !w.shouldRestart(err) {
+			if err != nil {
+				w.setState(StateFailed, err)
+				return
+			}
+			w.setState(StateStopped, nil)
+			return
+		}
+
+		// a worker that has been running long enough restarts after the initial delay
+		if w.config.clock.Since(startedAt) >= w.config.resetAfter {
+			bo.Reset()
+		}
+
+		delay := bo.NextBackOff()
+		if delay == backoff.Stop {
+			w.setState(StateFailed, err)
+			return
+		}
+
+		w.setState(StateRestarting, err)
+		timer := w.config.clock.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			w.setState(StateStopped, err)
+			return
+		case <-timer.C():
+		}
+
+		w.mu.Lock()
+		w.status.Restarts++
+		w.mu.Unlock()
+	}
+}
+
+// run executes the worker and converts a panic into an error
+func (w *supervised) run(ctx context.Context) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return w.worker(ctx)
+}
+
+// shouldRestart checks whether the worker should be restarted
+func (w *supervised) shouldRestart(err error) bool {
+	if w.config.maxRestarts >= 0 && w.getStatus().Restarts >= w.config.maxRestarts {
+		return false
+	}
+
+	switch w.config.policy {
+	case RestartAlways:
+		return true
+	case RestartOnFailure:
+		return err != nil
+	default:
+		return false
+	}
+}
+
+func (w *supervised) setRunning() {
+	w.mu.Lock()
+	w.status.State = StateRunning
+	w.status.StartedAt = w.config.clock.Now()
+	w.mu.Unlock()
+}
+
+func (w *supervised) setState(state State, err error) {
+	w.mu.Lock()
+	w.status.State = state
+	if err != nil {
+		w.status.LastError = err
+	}
+	w.mu.Unlock()
+}
+
+func (w *supervised) getStatus() Status {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.status
+}
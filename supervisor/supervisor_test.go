@@ -0,0 +1,314 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022-2025 Arsene Tochemey Gandote
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tochemey/gopack/clock"
+)
+
+type supervisorTestSuite struct {
+	suite.Suite
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestSupervisorTestSuite(t *testing.T) {
+	suite.Run(t, new(supervisorTestSuite))
+}
+
+func (s *supervisorTestSuite) TestAdd() {
+	s.Run("with duplicate worker", func() {
+		supervisor := New()
+		worker := func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		}
+		s.Require().NoError(supervisor.Add("worker", worker))
+		err := supervisor.Add("worker", worker)
+		s.Assert().EqualError(err, "worker (worker) is already added")
+	})
+	s.Run("with worker added after start", func() {
+		ctx := context.TODO()
+		supervisor := New()
+		s.Require().NoError(supervisor.Start(ctx))
+
+		started := make(chan struct{})
+		err := supervisor.Add("worker", func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			return nil
+		})
+		s.Require().NoError(err)
+
+		select {
+		case <-started:
+		case <-time.After(time.Second):
+			s.T().Fatal("expected worker to start")
+		}
+
+		s.Assert().NoError(supervisor.Stop(ctx))
+		status, ok := supervisor.Status("worker")
+		s.Require().True(ok)
+		s.Assert().Equal(StateStopped, status.State)
+	})
+}
+
+func (s *supervisorTestSuite) TestStartStop() {
+	s.Run("with start twice", func() {
+		ctx := context.TODO()
+		supervisor := New()
+		s.Require().NoError(supervisor.Start(ctx))
+		s.Assert().ErrorIs(supervisor.Start(ctx), ErrAlreadyStarted)
+		s.Assert().NoError(supervisor.Stop(ctx))
+	})
+	s.Run("with stop before start", func() {
+		supervisor := New()
+		s.Assert().ErrorIs(supervisor.Stop(context.TODO()), ErrNotStarted)
+	})
+	s.Run("with stop deadline exceeded", func() {
+		ctx := context.TODO()
+		supervisor := New()
+		release := make(chan struct{})
+		defer close(release)
+		s.Require().NoError(supervisor.Add("stubborn", func(context.Context) error {
+			<-release
+			return nil
+		}))
+		s.Require().NoError(supervisor.Start(ctx))
+
+		stopCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		s.Assert().ErrorIs(supervisor.Stop(stopCtx), context.DeadlineExceeded)
+	})
+}
+
+func (s *supervisorTestSuite) TestRestartPolicies() {
+	s.Run("with on-failure policy and panic", func() {
+		ctx := context.TODO()
+		supervisor := New()
+		var runs atomic.Int32
+		err := supervisor.Add("panicky", func(ctx context.Context) error {
+			if runs.Add(1) < 3 {
+				panic("boom")
+			}
+			<-ctx.Done()
+			return nil
+		}, WithConstantBackOff(10*time.Millisecond))
+		s.Require().NoError(err)
+		s.Require().NoError(supervisor.Start(ctx))
+
+		s.Eventually(func() bool {
+			status, _ := supervisor.Status("panicky")
+			return status.State == StateRunning && status.Restarts == 2
+		}, time.Second, 10*time.Millisecond)
+
+		status, _ := supervisor.Status("panicky")
+		var panicErr *PanicError
+		s.Assert().ErrorAs(status.LastError, &panicErr)
+		s.Assert().Equal("boom", panicErr.Value)
+		s.Assert().True(supervisor.Healthy())
+		s.Assert().NoError(supervisor.Stop(ctx))
+	})
+	s.Run("with on-failure policy and clean exit", func() {
+		ctx := context.TODO()
+		supervisor := New()
+		s.Require().NoError(supervisor.Add("oneshot", func(context.Context) error { return nil }))
+		s.Require().NoError(supervisor.Start(ctx))
+
+		s.Eventually(func() bool {
+			status, _ := supervisor.Status("oneshot")
+			return status.State == StateStopped
+		}, time.Second, 10*time.Millisecond)
+
+		status, _ := supervisor.Status("oneshot")
+		s.Assert().Zero(status.Restarts)
+		s.Assert().NoError(supervisor.Stop(ctx))
+	})
+	s.Run("with always policy", func() {
+		ctx := context.TODO()
+		supervisor := New()
+		var runs atomic.Int32
+		err := supervisor.Add("looper", func(context.Context) error {
+			runs.Add(1)
+			return nil
+		}, WithRestartPolicy(RestartAlways), WithConstantBackOff(5*time.Millisecond))
+		s.Require().NoError(err)
+		s.Require().NoError(supervisor.Start(ctx))
+
+		s.Eventually(func() bool { return runs.Load() >= 3 }, time.Second, 5*time.Millisecond)
+		s.Assert().NoError(supervisor.Stop(ctx))
+	})
+	s.Run("with max restarts reached", func() {
+		ctx := context.TODO()
+		supervisor := New()
+		failure := errors.New("failure")
+		err := supervisor.Add("failing", func(context.Context) error {
+			return failure
+		}, WithMaxRestarts(2), WithConstantBackOff(5*time.Millisecond))
+		s.Require().NoError(err)
+		s.Require().NoError(supervisor.Start(ctx))
+
+		s.Eventually(func() bool {
+			status, _ := supervisor.Status("failing")
+			return status.State == StateFailed
+		}, time.Second, 5*time.Millisecond)
+
+		status, _ := supervisor.Status("failing")
+		s.Assert().Equal(2, status.Restarts)
+		s.Assert().ErrorIs(status.LastError, failure)
+		s.Assert().False(supervisor.Healthy())
+		s.Assert().NoError(supervisor.Stop(ctx))
+	})
+	s.Run("with fake clock", func() {
+		ctx := context.TODO()
+		supervisor := New()
+		fake := clock.NewFake(time.Now())
+		var runs atomic.Int32
+		err := supervisor.Add("delayed", func(context.Context) error {
+			runs.Add(1)
+			return errors.New("failure")
+		}, WithConstantBackOff(time.Hour), WithClock(fake))
+		s.Require().NoError(err)
+		s.Require().NoError(supervisor.Start(ctx))
+
+		// the worker waits for the backoff delay before restarting
+		fake.BlockUntil(1)
+		s.Assert().EqualValues(1, runs.Load())
+		status, _ := supervisor.Status("delayed")
+		s.Assert().Equal(StateRestarting, status.State)
+
+		fake.Advance(time.Hour)
+		s.Eventually(func() bool { return runs.Load() == 2 }, time.Second, 5*time.Millisecond)
+		s.Assert().NoError(supervisor.Stop(ctx))
+	})
+	s.Run("with backoff reset after a stable run", func() {
+		ctx := context.TODO()
+		supervisor := New()
+		fake := clock.NewFake(time.Now())
+		bo := new(countingBackOff)
+		var runs atomic.Int32
+		err := supervisor.Add("stable", func(context.Context) error {
+			switch runs.Add(1) {
+			case 1:
+				return errors.New("failure")
+			case 2:
+				// the worker runs long enough to be considered stable before failing
+				fake.Advance(2 * time.Minute)
+				return errors.New("failure")
+			default:
+				return nil
+			}
+		}, WithBackOff(func() backoff.BackOff { return bo }), WithBackOffResetAfter(time.Minute), WithClock(fake))
+		s.Require().NoError(err)
+		s.Require().NoError(supervisor.Start(ctx))
+
+		fake.BlockUntil(1)
+		s.Assert().EqualValues(1, bo.resets.Load())
+		fake.Advance(time.Second)
+
+		// the backoff is reset after the stable run
+		fake.BlockUntil(1)
+		s.Assert().EqualValues(2, runs.Load())
+		s.Assert().EqualValues(2, bo.resets.Load())
+		fake.Advance(time.Second)
+		s.Eventually(func() bool { return runs.Load() == 3 }, time.Second, 5*time.Millisecond)
+		s.Assert().NoError(supervisor.Stop(ctx))
+	})
+	s.Run("with backoff per worker", func() {
+		ctx := context.TODO()
+		supervisor := New()
+		var created atomic.Int32
+		option := WithBackOff(func() backoff.BackOff {
+			created.Add(1)
+			return backoff.NewConstantBackOff(time.Millisecond)
+		})
+		worker := func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		}
+		s.Require().NoError(supervisor.Add("first", worker, option))
+		s.Require().NoError(supervisor.Add("second", worker, option))
+		s.Require().NoError(supervisor.Start(ctx))
+
+		s.Eventually(func() bool { return created.Load() == 2 }, time.Second, 5*time.Millisecond)
+		s.Assert().NoError(supervisor.Stop(ctx))
+	})
+	s.Run("with never policy", func() {
+		ctx := context.TODO()
+		supervisor := New()
+		err := supervisor.Add("never", func(context.Context) error {
+			return errors.New("failure")
+		}, WithRestartPolicy(RestartNever))
+		s.Require().NoError(err)
+		s.Require().NoError(supervisor.Start(ctx))
+
+		s.Eventually(func() bool {
+			status, _ := supervisor.Status("never")
+			return status.State == StateFailed
+		}, time.Second, 5*time.Millisecond)
+		s.Assert().NoError(supervisor.Stop(ctx))
+	})
+}
+
+func (s *supervisorTestSuite) TestStatuses() {
+	supervisor := New()
+	worker := func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	}
+	s.Require().NoError(supervisor.Add("first", worker))
+	s.Require().NoError(supervisor.Add("second", worker))
+
+	statuses := supervisor.Statuses()
+	s.Require().Len(statuses, 2)
+	s.Assert().Equal("first", statuses[0].Name)
+	s.Assert().Equal("second", statuses[1].Name)
+	s.Assert().Equal(StateIdle, statuses[0].State)
+
+	_, ok := supervisor.Status("unknown")
+	s.Assert().False(ok)
+}
+
+// countingBackOff is a constant backoff counting its resets
+type countingBackOff struct {
+	resets atomic.Int32
+}
+
+func (b *countingBackOff) NextBackOff() time.Duration {
+	return time.Second
+}
+
+func (b *countingBackOff) Reset() {
+	b.resets.Add(1)
+}